@@ -0,0 +1,123 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// appinfoPayload holds the content of the appinfo in parsing, the tokens
+// inside the appinfo except the code generation directives are encoded into
+// the buffer as they were read, and the depth is the number of the elements
+// opened in the appinfo.
+type appinfoPayload struct {
+	buffer  bytes.Buffer
+	encoder *xml.Encoder
+	depth   int
+}
+
+// newAppinfoPayload creates a new payload for the appinfo.
+func newAppinfoPayload() *appinfoPayload {
+	payload := &appinfoPayload{}
+	payload.encoder = xml.NewEncoder(&payload.buffer)
+	return payload
+}
+
+// encode writes the token into the payload.
+func (payload *appinfoPayload) encode(token xml.Token) {
+	if start, ok := token.(xml.StartElement); ok {
+		attrs := make([]xml.Attr, 0, len(start.Attr))
+		for _, attr := range start.Attr {
+			if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+				continue
+			}
+			attrs = append(attrs, attr)
+		}
+		start.Attr = attrs
+		token = start
+	}
+	_ = payload.encoder.EncodeToken(token)
+}
+
+// String returns the encoded payload without the leading and trailing white
+// spaces.
+func (payload *appinfoPayload) String() string {
+	_ = payload.encoder.Flush()
+	return strings.TrimSpace(payload.buffer.String())
+}
+
+// captureAppinfo records the payload of the appinfo for the declaration
+// which the appinfo belongs to.
+func (opt *Options) captureAppinfo() {
+	target, payload := opt.directiveTarget(), opt.payload.String()
+	opt.payload = nil
+	if target == "" || payload == "" {
+		return
+	}
+	if opt.appinfo == nil {
+		opt.appinfo = map[string][]string{}
+	}
+	opt.appinfo[target] = append(opt.appinfo[target], payload)
+}
+
+// markAppinfo attaches the appinfo payloads to the global declarations and
+// the elements and attributes declared in them.
+func (opt *Options) markAppinfo() {
+	if len(opt.appinfo) == 0 {
+		return
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			v.Appinfo = opt.appinfo[v.Name]
+		case *ComplexType:
+			v.Appinfo = opt.appinfo[v.Name]
+			opt.markElementAppinfo(v.Name, v.Elements)
+			opt.markAttributeAppinfo(v.Name, v.Attributes)
+		case *Element:
+			v.Appinfo = opt.appinfo[v.Name]
+		case *Attribute:
+			v.Appinfo = opt.appinfo[v.Name]
+		case *Group:
+			v.Appinfo = opt.appinfo[v.Name]
+			opt.markElementAppinfo(v.Name, v.Elements)
+		case *AttributeGroup:
+			v.Appinfo = opt.appinfo[v.Name]
+			opt.markAttributeAppinfo(v.Name, v.Attributes)
+		}
+	}
+}
+
+// markElementAppinfo attaches the appinfo payloads to the elements declared
+// in the given global declaration.
+func (opt *Options) markElementAppinfo(parent string, elements []Element) {
+	for i := range elements {
+		elements[i].Appinfo = opt.appinfo[parent+"/"+trimNSPrefix(elements[i].Name)]
+	}
+}
+
+// markAttributeAppinfo attaches the appinfo payloads to the attributes
+// declared in the given global declaration.
+func (opt *Options) markAttributeAppinfo(parent string, attributes []Attribute) {
+	for i := range attributes {
+		attributes[i].Appinfo = opt.appinfo[parent+"/"+trimNSPrefix(attributes[i].Name)]
+	}
+}
+
+// genAppinfo generates the comment lines carrying the appinfo payloads of
+// the type by given comment prefix.
+func genAppinfo(appinfo []string, prefix string) (comment string) {
+	for _, payload := range appinfo {
+		comment += fmt.Sprintf("%s\r\n%s appinfo: %s\r\n", prefix, prefix, strings.Replace(payload, "\n", fmt.Sprintf("\r\n%s ", prefix), -1))
+	}
+	return
+}
@@ -0,0 +1,213 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build js && wasm
+// +build js,wasm
+
+// xgen-wasm is the WebAssembly build of xgen, which runs in the browsers and
+// Node.js without a Go toolchain. Build it with:
+//
+//	$ GOOS=js GOARCH=wasm go build -o xgen.wasm ./cmd/xgen-wasm
+//
+// After the module is started with the wasm_exec.js shipped with Go, the
+// global xgen object provides the functions:
+//
+//	xgen.generate(files, language, options)
+//	xgen.parse(files, language)
+//
+// The files map the paths of the schema files to their contents. The generate
+// function returns { files, errors }, the files map the paths of the
+// generated files to the code. The parse function returns the intermediate
+// representation of each schema file in the same form. The unsupported
+// languages and the panics are reported in the errors as well. The options
+// could be package, skipWrappers, schemaOrder, embedSource, sourceLocation,
+// emitIR, fixtures, keepGoing, xsd11, optionalPointers, tags, splitFiles,
+// bigNumbers, deepCopy, stringer and decodeEach.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"syscall/js"
+
+	"github.com/xuri/xgen"
+)
+
+// options returns the options of the generation by given JavaScript object.
+func options(value js.Value) *xgen.Options {
+	opt := &xgen.Options{}
+	if value.Type() != js.TypeObject {
+		return opt
+	}
+	str := func(name string) string {
+		if v := value.Get(name); v.Type() == js.TypeString {
+			return v.String()
+		}
+		return ""
+	}
+	flag := func(name string) bool {
+		v := value.Get(name)
+		return v.Type() == js.TypeBoolean && v.Bool()
+	}
+	opt.Package = str("package")
+	opt.SkipWrappers = flag("skipWrappers")
+	opt.SchemaOrder = flag("schemaOrder")
+	opt.EmbedSource = flag("embedSource")
+	opt.SourceLocation = flag("sourceLocation")
+	opt.EmitIR = flag("emitIR")
+	opt.Fixtures = flag("fixtures")
+	opt.KeepGoing = flag("keepGoing")
+	opt.XSD11 = flag("xsd11")
+	opt.OptionalPointers = flag("optionalPointers")
+	opt.SplitFiles = flag("splitFiles")
+	opt.BigNumbers = flag("bigNumbers")
+	opt.DeepCopy = flag("deepCopy")
+	opt.Stringer = flag("stringer")
+	opt.DecodeEach = flag("decodeEach")
+	if tags := str("tags"); tags != "" {
+		opt.StructTags = strings.Split(tags, ",")
+	}
+	return opt
+}
+
+// run generates code in the language for the schema files by given JavaScript
+// object, and returns the generated files and the errors.
+func run(files js.Value, lang string, opt *xgen.Options) (outputs map[string][]byte, errs []interface{}) {
+	if !xgen.SupportLang[lang] {
+		return nil, []interface{}{"unsupport language " + lang}
+	}
+	sources := map[string][]byte{}
+	keys := js.Global().Get("Object").Call("keys", files)
+	for i := 0; i < keys.Length(); i++ {
+		name := keys.Index(i).String()
+		sources[name] = []byte(files.Get(name).String())
+	}
+	var paths []string
+	for path := range sources {
+		if hasExtension(path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	outputs = map[string][]byte{}
+	failures := &xgen.FailureLog{}
+	typeNamespaces := make(map[string]string)
+	for _, path := range paths {
+		parser := xgen.NewParser(&xgen.Options{
+			FilePath:            path,
+			Lang:                lang,
+			Package:             opt.Package,
+			TypeNamespaces:      typeNamespaces,
+			SkipWrappers:        opt.SkipWrappers,
+			SchemaOrder:         opt.SchemaOrder,
+			EmbedSource:         opt.EmbedSource,
+			SourceLocation:      opt.SourceLocation,
+			EmitIR:              opt.EmitIR,
+			Fixtures:            opt.Fixtures,
+			KeepGoing:           opt.KeepGoing,
+			XSD11:               opt.XSD11,
+			OptionalPointers:    opt.OptionalPointers,
+			StructTags:          opt.StructTags,
+			SplitFiles:          opt.SplitFiles,
+			BigNumbers:          opt.BigNumbers,
+			DeepCopy:            opt.DeepCopy,
+			Stringer:            opt.Stringer,
+			DecodeEach:          opt.DecodeEach,
+			Failures:            failures,
+			Sources:             sources,
+			Outputs:             outputs,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		})
+		if err := parser.Parse(); err != nil {
+			errs = append(errs, path+": "+err.Error())
+		}
+	}
+	for _, failure := range failures.Failures {
+		errs = append(errs, failure.String())
+	}
+	return
+}
+
+// hasExtension returns whether the path has the extension of the schema
+// files.
+func hasExtension(path string) bool {
+	for _, ext := range xgen.DefaultSchemaExtensions {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// result converts the generated files and the errors to the JavaScript
+// object, only the files with the suffix are returned if it's not empty.
+func result(outputs map[string][]byte, errs []interface{}, suffix string) js.Value {
+	files := map[string]interface{}{}
+	for path, data := range outputs {
+		if strings.HasSuffix(path, suffix) {
+			files[path] = string(data)
+		}
+	}
+	return js.ValueOf(map[string]interface{}{"files": files, "errors": errs})
+}
+
+// failed returns the JavaScript object of the result with no files and the
+// error.
+func failed(err string) js.Value {
+	return js.ValueOf(map[string]interface{}{"files": map[string]interface{}{}, "errors": []interface{}{err}})
+}
+
+// handler wraps the function as the JavaScript function, the panics in it are
+// recovered and returned as the errors of the result, so that the Go program
+// keeps running for the later calls.
+func handler(fn func(this js.Value, args []js.Value) interface{}) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) (ret interface{}) {
+		defer func() {
+			if r := recover(); r != nil {
+				ret = failed(fmt.Sprint(r))
+			}
+		}()
+		return fn(this, args)
+	})
+}
+
+// generate handles the xgen.generate(files, language, options) calls.
+func generate(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return failed("usage: xgen.generate(files, language, options)")
+	}
+	var value js.Value
+	if len(args) > 2 {
+		value = args[2]
+	}
+	outputs, errs := run(args[0], args[1].String(), options(value))
+	return result(outputs, errs, "")
+}
+
+// parse handles the xgen.parse(files, language) calls.
+func parse(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return failed("usage: xgen.parse(files, language)")
+	}
+	lang := "Go"
+	if len(args) > 1 && args[1].Type() == js.TypeString {
+		lang = args[1].String()
+	}
+	outputs, errs := run(args[0], lang, &xgen.Options{EmitIR: true})
+	return result(outputs, errs, ".ir.json")
+}
+
+func main() {
+	js.Global().Set("xgen", js.ValueOf(map[string]interface{}{
+		"generate": handler(generate),
+		"parse":    handler(parse),
+	}))
+	select {}
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	files := js.ValueOf(map[string]interface{}{
+		"order.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="common/line.xsd"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="line" type="lineType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`,
+		"common/line.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`,
+		"broken.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="ten"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+		"README.md": "# schemas",
+	})
+
+	ret := generate(js.Undefined(), []js.Value{files, js.ValueOf("Go"), js.ValueOf(map[string]interface{}{"package": "shop", "keepGoing": true})}).(js.Value)
+	outputs := ret.Get("files")
+	assert.Equal(t, 3, js.Global().Get("Object").Call("keys", outputs).Length())
+	assert.Contains(t, outputs.Get("order.xsd.go").String(), "package shop\n")
+	assert.Contains(t, outputs.Get("order.xsd.go").String(), "\tLine []*LineType `xml:\"line\"`\n")
+	assert.Contains(t, outputs.Get("common/line.xsd.go").String(), "type LineType struct {\n")
+	assert.Contains(t, outputs.Get("broken.xsd.go").String(), "type CodeType interface{}\n")
+	errs := ret.Get("errors")
+	assert.Equal(t, 1, errs.Length())
+	assert.Equal(t, `broken.xsd: codeType: strconv.Atoi: parsing "ten": invalid syntax`, errs.Index(0).String())
+
+	ret = parse(js.Undefined(), []js.Value{files}).(js.Value)
+	outputs = ret.Get("files")
+	assert.Contains(t, outputs.Get("order.xsd.ir.json").String(), `"orderType"`)
+	assert.True(t, outputs.Get("order.xsd.go").IsUndefined())
+	assert.Equal(t, 1, ret.Get("errors").Length())
+
+	ret = generate(js.Undefined(), []js.Value{files}).(js.Value)
+	assert.Equal(t, "usage: xgen.generate(files, language, options)", ret.Get("errors").Index(0).String())
+}
+
+func TestGenerateFailure(t *testing.T) {
+	files := js.ValueOf(map[string]interface{}{
+		"note.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="note" type="xs:string"/>
+</xs:schema>`,
+	})
+
+	fn := handler(generate)
+	defer fn.Release()
+	ret := fn.Invoke(files, "Cobol")
+	assert.Equal(t, 0, js.Global().Get("Object").Call("keys", ret.Get("files")).Length())
+	assert.Equal(t, 1, ret.Get("errors").Length())
+	assert.Equal(t, "unsupport language Cobol", ret.Get("errors").Index(0).String())
+
+	ret = fn.Invoke(js.Undefined(), "Go")
+	assert.Equal(t, 0, js.Global().Get("Object").Call("keys", ret.Get("files")).Length())
+	assert.Equal(t, 1, ret.Get("errors").Length())
+	assert.Equal(t, "JavaScript error: Cannot convert undefined or null to object", ret.Get("errors").Index(0).String())
+
+	ret = fn.Invoke(files, "Go")
+	assert.Contains(t, ret.Get("files").Get("note.xsd.go").String(), "type Note string\n")
+	assert.Equal(t, 0, ret.Get("errors").Length())
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/xgen"
+)
+
+// configFile is the name of the config file written by the init command.
+const configFile = "xgen.json"
+
+// prompter asks the questions of the init command and reads the answers.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prints the question with the default value, and returns the answer or
+// the default value if the answer is empty.
+func (p *prompter) ask(question, defaultValue string) string {
+	fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+	answer, _ := p.in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue
+	}
+	return answer
+}
+
+// confirm asks the yes or no question, and returns false by default.
+func (p *prompter) confirm(question string) bool {
+	answer := strings.ToLower(p.ask(question+" (y/N)", "N"))
+	return answer == "y" || answer == "yes"
+}
+
+// inspectSchemas returns the XSD files in the directory and the target
+// namespaces declared in them.
+func inspectSchemas(dir string) (schemas []string, namespaces []string, err error) {
+	var files []string
+	if files, err = xgen.GetFileList(dir); err != nil {
+		return
+	}
+	found := map[string]bool{}
+	for _, file := range files {
+		if !strings.EqualFold(filepath.Ext(file), ".xsd") {
+			continue
+		}
+		schemas = append(schemas, file)
+		var f *os.File
+		if f, err = os.Open(file); err != nil {
+			return
+		}
+		decoder := xml.NewDecoder(f)
+		for {
+			token, tokenErr := decoder.Token()
+			if tokenErr != nil {
+				break
+			}
+			if start, ok := token.(xml.StartElement); ok {
+				for _, attr := range start.Attr {
+					if attr.Name.Local == "targetNamespace" && !found[attr.Value] {
+						found[attr.Value] = true
+						namespaces = append(namespaces, attr.Value)
+					}
+				}
+				break
+			}
+		}
+		f.Close()
+	}
+	sort.Strings(namespaces)
+	return
+}
+
+// runInit inspects the schema directory, asks for the target languages and
+// options, and writes the config file and the output directory layout. The
+// existing config file is only overwritten with the -force flag.
+func runInit(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	force := flags.Bool("force", false, "Overwrite the existing config file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if _, err := os.Stat(configFile); err == nil && !*force {
+		return fmt.Errorf("%s already exists, run `xgen init -force` to overwrite it", configFile)
+	}
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	schemas, namespaces, err := inspectSchemas(dir)
+	if err != nil {
+		return err
+	}
+	if len(schemas) == 0 {
+		return fmt.Errorf("no XML schema definition found in %s", dir)
+	}
+	fmt.Fprintf(stdout, "found %d schema files with %d target namespaces in %s\r\n", len(schemas), len(namespaces), dir)
+	for _, ns := range namespaces {
+		fmt.Fprintf(stdout, "  %s\r\n", ns)
+	}
+
+	p := &prompter{in: bufio.NewReader(stdin), out: stdout}
+	cfg := Config{I: dir, FetchRetries: Cfg.FetchRetries}
+	var langs []string
+	for _, lang := range strings.Split(p.ask("Target languages (Go/C/Java/Rust/TypeScript/Ruby/Python/CSharp/Kotlin/Swift/PHP/Dart/Elixir/Haskell/OCaml/Zig/FSharp/ObjectiveC/Groovy/Lua/Perl/Crystal/Nim/Julia/VisualBasic/JSONSchema/Protobuf/Avro/GraphQL/OpenAPI/SQL/FlatBuffers/CapnProto/CUE/Markdown/DOT/XML)", "Go"), ",") {
+		lang = strings.TrimSpace(lang)
+		if !SupportLang[lang] {
+			return fmt.Errorf("unsupport language %s", lang)
+		}
+		langs = append(langs, lang)
+	}
+	cfg.Lang = strings.Join(langs, ",")
+	cfg.O = p.ask("Output directory", "xgen_out")
+	cfg.Pkg = p.ask("Package name", "schema")
+	if cfg.Naming.Type = p.ask("Naming convention of type names (PascalCase/camelCase/snake_case/SCREAMING_SNAKE_CASE)", ""); !xgen.IsValidNamingConvention(cfg.Naming.Type) {
+		return fmt.Errorf("unsupport naming convention %s", cfg.Naming.Type)
+	}
+	cfg.SkipWrappers = p.confirm("Skip wrapper types of global elements and attributes")
+	cfg.SchemaOrder = p.confirm("Emit types in schema document order")
+	if len(namespaces) > 1 {
+		if cfg.Collision = p.ask("Strategy for naming types collide across namespaces (prefix/uri/numeric)", ""); !xgen.IsValidCollisionStrategy(cfg.Collision) {
+			return fmt.Errorf("unsupport collision strategy %s", cfg.Collision)
+		}
+		for _, lang := range langs {
+			if lang == "Go" && p.confirm("Generate one Go package per target namespace") {
+				cfg.NSPackages = true
+				cfg.Module = p.ask("Import path of the output directory", "")
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(configFile, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	for _, lang := range langs {
+		output := cfg.O
+		if len(langs) > 1 {
+			output = filepath.Join(cfg.O, strings.ToLower(lang))
+		}
+		if err = xgen.PrepareOutputDir(output); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "wrote %s, run `xgen -c %s` to generate code\r\n", configFile, configFile)
+	return nil
+}
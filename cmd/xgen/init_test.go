@@ -0,0 +1,82 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	assert.EqualError(t, runInit(nil, strings.NewReader(""), ioutil.Discard), "no XML schema definition found in .")
+	assert.NoError(t, os.Mkdir("xsd", 0755))
+	for name, ns := range map[string]string{"order.xsd": "urn:order", "common.xsd": "urn:common", "line.xsd": "urn:order"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join("xsd", name), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="`+ns+`"/>`), 0644))
+	}
+	assert.NoError(t, ioutil.WriteFile(filepath.Join("xsd", "README.md"), nil, 0644))
+
+	// the languages, output, package, naming, wrappers, order, collision,
+	// namespace packages and module path are answered in order.
+	var stdout bytes.Buffer
+	answers := "Go, TypeScript\nout\napi\n\ny\n\nuri\ny\nexample.com/api\n"
+	assert.NoError(t, runInit([]string{"xsd"}, strings.NewReader(answers), &stdout))
+	assert.Contains(t, stdout.String(), "found 3 schema files with 2 target namespaces in xsd\r\n  urn:common\r\n  urn:order\r\n")
+
+	var cfg Config
+	assert.NoError(t, loadConfig(configFile, &cfg))
+	assert.Equal(t, "xsd", cfg.I)
+	assert.Equal(t, "out", cfg.O)
+	assert.Equal(t, "api", cfg.Pkg)
+	assert.Equal(t, "Go,TypeScript", cfg.Lang)
+	assert.True(t, cfg.SkipWrappers)
+	assert.False(t, cfg.SchemaOrder)
+	assert.Equal(t, "uri", cfg.Collision)
+	assert.True(t, cfg.NSPackages)
+	assert.Equal(t, "example.com/api", cfg.Module)
+	for _, output := range []string{"out/go", "out/typescript"} {
+		info, err := os.Stat(filepath.FromSlash(output))
+		assert.NoError(t, err)
+		assert.True(t, info.IsDir())
+	}
+
+	assert.EqualError(t, runInit([]string{"-force", "xsd"}, strings.NewReader("Cobol\n"), ioutil.Discard), "unsupport language Cobol")
+}
+
+func TestRunInitExistingConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	assert.NoError(t, ioutil.WriteFile("order.xsd", []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`), 0644))
+	assert.NoError(t, ioutil.WriteFile(configFile, []byte("{\"language\": \"Rust\"}\n"), 0644))
+	assert.EqualError(t, runInit(nil, strings.NewReader("Go\n"), ioutil.Discard), "xgen.json already exists, run `xgen init -force` to overwrite it")
+	data, err := ioutil.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"language\": \"Rust\"}\n", string(data))
+
+	assert.NoError(t, runInit([]string{"-force"}, strings.NewReader("Go\n"), ioutil.Discard))
+	var cfg Config
+	assert.NoError(t, loadConfig(configFile, &cfg))
+	assert.Equal(t, ".", cfg.I)
+	assert.Equal(t, "Go", cfg.Lang)
+}
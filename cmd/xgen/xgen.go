@@ -8,15 +8,174 @@
 // Usage:
 //
 //    $ xgen [<flag> ...] <XSD file or directory> ...
+//    $ xgen init [<XSD directory>]
+//        -c <path> Load options from the config file
 //        -i <path> Input file path or directory for the XML schema definition
 //        -o <path> Output file path or directory for the generated code
+//        -ext <exts> Extensions of the schema files in the input directory (default ".xsd,.wsdl")
+//        -depth <n> Depth limit of the sub-directories in the input directory
 //        -p        Specify the package name
-//        -l        Specify the language of generated code (Go/C/Java/Rust/TypeScript)
+//        -l        Specify the language of generated code (Go/C/Java/Rust/TypeScript/Ruby/Python/CSharp/Kotlin/Swift/PHP/Dart/Elixir/Haskell/OCaml/Zig/FSharp/ObjectiveC/Groovy/Lua/Perl/Crystal/Nim/Julia/VisualBasic/JSONSchema/Protobuf/Avro/GraphQL/OpenAPI/SQL/FlatBuffers/CapnProto/CUE/Markdown/DOT/XML)
+//        -cmake    Generate CMake project and test stubs for C code
+//        -zod      Generate zod validation schemas alongside TypeScript types
+//        -lxml     Generate lxml from_element and to_element helpers in Python
+//        -target-framework <tfm> Target framework of C# code, records are generated for net5.0 or later
+//        -optional-pointers Generate optional members as pointers with omitempty in Go
+//        -tags <tags> Struct tags alongside the XML tags in Go (json,yaml)
+//        -split-files Generate one file per type in Go
+//        -big-numbers Map the unbounded integers and decimals to arbitrary-precision types in Go
+//        -deep-copy Generate DeepCopy methods of the structs in Go
+//        -stringer Generate String methods rendering the structs as indented XML in Go
+//        -decode-each Generate DecodeEach helpers streaming the global elements in Go
+//        -type-case <case>  Naming convention of type names
+//        -field-case <case> Naming convention of field names
+//        -file-case <case>  Naming convention of file names
+//        -const-case <case> Naming convention of constants
+//        -tag-case <case>   Naming convention of the keys of the struct tags
+//        -escape <strategy> Strategy for escaping reserved words
+//        -escape-affix <affix> Affix for escaping reserved words
+//        -map <XSDType=Type[,Import]> Map schema type to an existing type
+//        -types <path> Override the built-in types by the JSON file
+//        -collision <strategy> Strategy for naming types collide across namespaces
+//        -skip-wrappers Skip wrapper types of global elements and attributes
+//        -schema-order Emit types in schema document order
+//        -root <{namespace}name> Generate only the root and its dependencies
+//        -ns-packages Generate one Go package per target namespace
+//        -module <path> Import path of the output directory for -ns-packages (default by go.mod)
+//        -embed-source Include the XSD declaration as a comment above each type
+//        -source-location Include the schema file and line as a comment above each type
+//        -deprecation-marker <marker> Marker of the deprecated annotations
+//        -emit-ir  Write the intermediate representation as JSON beside the code
+//        -fixtures Generate valid sample values of the simple types for tests
+//        -keep-going Generate placeholders for the failed declarations and continue
+//        -xsd11    Parse the schemas as XSD 1.1
+//        -schema-path <dir> Search path for the imported and included schemas
+//        -catalog <path> XML catalog for resolving the schema locations
+//        -schema-override <location=path> Resolve the schema location to the file
+//        -schema-cache <dir> Cache directory of the downloaded schemas
+//        -fetch    Download the remote schemas which couldn't be resolved locally
+//        -proxy <url> Proxy for downloading the remote schemas
+//        -fetch-timeout <duration> Timeout of downloading a remote schema (default 30s)
+//        -fetch-retries <n> Retries of downloading a remote schema (default 2)
+//        -fetch-max-size <bytes> Maximum size of a remote schema (default 16 MiB)
+//        -lock <path> Lockfile verifying and recording the remote schemas (e.g. "xgen.lock")
+//        -update-lock Record the changed remote schemas in the lockfile
 //        -h        Output this help and exit
 //        -v        Output version and exit
 //
-// If the path specified by the -i flag is a directory, all files in the
-// directory will be processed as XML schema definition.
+// The naming convention could be one of PascalCase, camelCase, snake_case and
+// SCREAMING_SNAKE_CASE, the language default is used if not specified.
+//
+// Identifiers collide with reserved words of the language are escaped by the
+// strategy specified with -escape (suffix/prefix/backtick) and -escape-affix.
+//
+// The -map flag could be specified multiple times, the mapped schema types
+// will not be generated, and references to them use the given type with the
+// import added to the generated code.
+//
+// Types defined with the same name in different namespaces are renamed by
+// the strategy specified with -collision (prefix/uri/numeric), the type in the
+// namespace parsed first keeps its name.
+//
+// The wrapper types generated for every global element and attribute could be
+// skipped by -skip-wrappers, references to them always use their types.
+//
+// Types are emitted in the order encountered by the parser by default, the
+// -schema-order flag emits them in the schema document order, the types are
+// still declared before use for the languages which require it (e.g. C).
+//
+// The -root flag could be specified multiple times, only the global elements
+// or types with the names and their dependencies are generated. The name could
+// be qualified with the namespace in the form of {namespace}name.
+//
+// With the -ns-packages flag, the Go code for each target namespace is
+// generated into the package named after the namespace URI under the output
+// directory, and the types in other namespaces are referenced with the
+// packages imported under the path specified by -module, which is derived from
+// the go.mod file of the output directory or its parents by default.
+//
+// The file specified by the -types flag overrides the types which the XSD
+// data types map to in each language, for example:
+//
+//    {
+//        "decimal": { "Go": "float64", "Java": "java.math.BigDecimal" }
+//    }
+//
+// The -emit-ir flag writes the intermediate representation consumed by the
+// code generators as JSON beside the generated code for external tooling.
+//
+// The -fixtures flag generates a valid sample value in the lexical form for
+// each simple type, which satisfies the facets of the type, into the
+// fixtures file beside the generated code for tests.
+//
+// With the -keep-going flag, the declarations which couldn't be parsed or
+// generated and the type references which couldn't be resolved are replaced
+// by the documented placeholders, the generation continues with the rest and
+// a summary of all failures is printed at the end of the run.
+//
+// With the -optional-pointers flag, the optional attributes are pointers in
+// the Go code like the optional elements, and both are tagged with omitempty,
+// so that the absent members are distinguished from the zero values.
+//
+// The -tags flag adds the json or yaml struct tags alongside the XML tags in
+// the Go code, so that the same models could be encoded in JSON and YAML. The
+// keys of the struct tags follow the -tag-case naming convention, and the
+// optional members are tagged with omitempty.
+//
+// With the -split-files flag, each type is written into its own file in the
+// package directory of the Go code instead of one file per schema, the files
+// are named by the -file-case naming convention, or in snake case by default.
+//
+// The -big-numbers flag maps the xs:integer and the integer types derived from
+// it without the fixed size to XsdInteger based on math/big.Int, and the
+// xs:decimal to XsdDecimal holding the lexical form of the number in the Go
+// code, instead of int and float64 which overflow or lose precision.
+//
+// The -deep-copy flag generates the DeepCopyInto and DeepCopy methods of each
+// struct in the Go code, which copy the pointers, slices and the values of the
+// interfaces as well, so that the copy of a decoded document could be mutated
+// safely.
+//
+// The -stringer flag generates the String method of each struct in the Go
+// code, which renders the struct as the indented XML by the XsdDump helper
+// declared in the xsddump.go, for logging and debugging the documents.
+//
+// The -decode-each flag generates the DecodeEach function of each global
+// element in the Go code, such as DecodeEachOrder(r io.Reader, fn
+// func(*Order) error) error, which decodes the elements one by one from the
+// tokens of the document, so that the large documents of the repeated records
+// could be processed without loading them entirely.
+//
+// The -xsd11 flag parses the schemas as XSD 1.1, which is also enabled by the
+// vc:minVersion="1.1" of the schema. The declarations are included by the
+// vc:minVersion and vc:maxVersion of them, the xs:assert and xs:alternative
+// are kept in the intermediate representation, and the assertions of the
+// complex types are checked by the generated ValidateAssertions methods in Go.
+//
+// The init command inspects the schema directory, asks for the target
+// languages and options, and writes the config file xgen.json which could be
+// loaded by the -c flag, the flags specified explicitly take precedence over
+// the config file. Multiple languages could be specified separated by comma,
+// the code of each language is generated into the sub-directory of the
+// output directory.
+//
+// The schema locations of the imports and includes are resolved by the
+// -schema-override flags, the -schema-path directories, the -catalog, the
+// -schema-cache directory and finally downloaded when -fetch is specified.
+// The settings for downloading from the hosts behind authenticated gateways
+// could be specified in the config file, for example:
+//
+//    "hosts": {
+//        "schemas.example.com": {
+//            "headers": { "X-Api-Key": "$SCHEMA_API_KEY" },
+//            "bearerToken": "${SCHEMA_TOKEN}",
+//            "certFile": "client.crt", "keyFile": "client.key"
+//        }
+//    }
+//
+// If the path specified by the -i flag is a directory, the files with the
+// extensions specified by -ext in the directory will be processed as XML
+// schema definition, the hidden and version control directories are skipped.
 //
 // The default package name and output directory are "schema" and "xgen_out".
 //
@@ -25,9 +184,16 @@
 package main
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/xuri/xgen"
 )
@@ -35,88 +201,506 @@ import (
 // Config holds user-defined overrides and filters that are used when
 // generating source code from an XSD document.
 type Config struct {
-	I       string
-	O       string
-	Pkg     string
-	Lang    string
-	Version string
+	I                 string                      `json:"input"`
+	O                 string                      `json:"output"`
+	Pkg               string                      `json:"package"`
+	Lang              string                      `json:"language"`
+	Extensions        string                      `json:"extensions,omitempty"`
+	Depth             int                         `json:"depth,omitempty"`
+	CMake             bool                        `json:"cmake,omitempty"`
+	Zod               bool                        `json:"zod,omitempty"`
+	Lxml              bool                        `json:"lxml,omitempty"`
+	TargetFramework   string                      `json:"targetFramework,omitempty"`
+	OptionalPointers  bool                        `json:"optionalPointers,omitempty"`
+	Tags              string                      `json:"tags,omitempty"`
+	SplitFiles        bool                        `json:"splitFiles,omitempty"`
+	BigNumbers        bool                        `json:"bigNumbers,omitempty"`
+	DeepCopy          bool                        `json:"deepCopy,omitempty"`
+	Stringer          bool                        `json:"stringer,omitempty"`
+	DecodeEach        bool                        `json:"decodeEach,omitempty"`
+	Naming            xgen.NamingConvention       `json:"naming"`
+	Escape            xgen.Escape                 `json:"escape"`
+	Mapping           typeMappingFlag             `json:"mapping,omitempty"`
+	Types             string                      `json:"types,omitempty"`
+	Collision         string                      `json:"collision,omitempty"`
+	SkipWrappers      bool                        `json:"skipWrappers,omitempty"`
+	SchemaOrder       bool                        `json:"schemaOrder,omitempty"`
+	Roots             rootsFlag                   `json:"roots,omitempty"`
+	NSPackages        bool                        `json:"nsPackages,omitempty"`
+	Module            string                      `json:"module,omitempty"`
+	EmbedSource       bool                        `json:"embedSource,omitempty"`
+	SourceLocation    bool                        `json:"sourceLocation,omitempty"`
+	DeprecationMarker string                      `json:"deprecationMarker,omitempty"`
+	EmitIR            bool                        `json:"emitIR,omitempty"`
+	Fixtures          bool                        `json:"fixtures,omitempty"`
+	KeepGoing         bool                        `json:"keepGoing,omitempty"`
+	XSD11             bool                        `json:"xsd11,omitempty"`
+	SchemaPaths       pathsFlag                   `json:"schemaPaths,omitempty"`
+	Catalog           string                      `json:"catalog,omitempty"`
+	SchemaOverrides   overridesFlag               `json:"schemaOverrides,omitempty"`
+	SchemaCache       string                      `json:"schemaCache,omitempty"`
+	Fetch             bool                        `json:"fetch,omitempty"`
+	Proxy             string                      `json:"proxy,omitempty"`
+	Hosts             map[string]*xgen.HostConfig `json:"hosts,omitempty"`
+	FetchTimeout      string                      `json:"fetchTimeout,omitempty"`
+	FetchRetries      int                         `json:"fetchRetries"`
+	FetchMaxSize      int64                       `json:"fetchMaxSize,omitempty"`
+	Lock              string                      `json:"lock,omitempty"`
+	UpdateLock        bool                        `json:"-"`
+	Version           string                      `json:"-"`
 }
 
 // Cfg are the default config for xgen. The default package name and output
 // directory are "schema" and "xgen_out".
 var Cfg = Config{
-	Pkg:     "schema",
-	Version: "0.1.0",
+	O:            "xgen_out",
+	Pkg:          "schema",
+	FetchRetries: 2,
+	Version:      "0.1.0",
 }
 
 // SupportLang defines supported language types.
-var SupportLang = map[string]bool{
-	"Go":         true,
-	"C":          true,
-	"Java":       true,
-	"Rust":       true,
-	"TypeScript": true,
-	"Ruby":       true,
+var SupportLang = xgen.SupportLang
+
+// typeMappingFlag holds the type mappings specified by the repeatable -map
+// flag.
+type typeMappingFlag map[string]xgen.TypeMapping
+
+// String returns the type mappings in the form of XSDType=Type[,Import].
+func (m typeMappingFlag) String() string {
+	var mappings []string
+	for name, mapping := range m {
+		value := name + "=" + mapping.Type
+		if mapping.Import != "" {
+			value += "," + mapping.Import
+		}
+		mappings = append(mappings, value)
+	}
+	sort.Strings(mappings)
+	return strings.Join(mappings, " ")
+}
+
+// Set parses and adds a type mapping.
+func (m typeMappingFlag) Set(value string) error {
+	name, mapping, err := xgen.ParseTypeMapping(value)
+	if err != nil {
+		return err
+	}
+	m[name] = mapping
+	return nil
+}
+
+// rootsFlag holds the root names specified by the repeatable -root flag.
+type rootsFlag []xml.Name
+
+// String returns the root names in the form of {namespace}name.
+func (r *rootsFlag) String() string {
+	var roots []string
+	for _, root := range *r {
+		if root.Space != "" {
+			roots = append(roots, fmt.Sprintf("{%s}%s", root.Space, root.Local))
+			continue
+		}
+		roots = append(roots, root.Local)
+	}
+	return strings.Join(roots, " ")
+}
+
+// Set parses and adds a root name.
+func (r *rootsFlag) Set(value string) error {
+	var root xml.Name
+	if strings.HasPrefix(value, "{") {
+		idx := strings.Index(value, "}")
+		if idx == -1 {
+			return fmt.Errorf("invalid root name %q, expected {namespace}name", value)
+		}
+		root.Space, value = value[1:idx], value[idx+1:]
+	}
+	if value == "" {
+		return fmt.Errorf("invalid root name %q, expected {namespace}name", value)
+	}
+	root.Local = value
+	*r = append(*r, root)
+	return nil
+}
+
+// pathsFlag holds the paths specified by the repeatable flag.
+type pathsFlag []string
+
+// String returns the paths separated by the path list separator.
+func (p *pathsFlag) String() string {
+	return strings.Join(*p, string(os.PathListSeparator))
+}
+
+// Set adds a path.
+func (p *pathsFlag) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// overridesFlag holds the schema locations overrides specified by the
+// repeatable -schema-override flag.
+type overridesFlag map[string]string
+
+// String returns the overrides in the form of location=path.
+func (o overridesFlag) String() string {
+	var overrides []string
+	for location, path := range o {
+		overrides = append(overrides, location+"="+path)
+	}
+	sort.Strings(overrides)
+	return strings.Join(overrides, " ")
+}
+
+// Set parses and adds an override.
+func (o overridesFlag) Set(value string) error {
+	idx := strings.LastIndex(value, "=")
+	if idx <= 0 || idx == len(value)-1 {
+		return fmt.Errorf("invalid schema override %q, expected location=path", value)
+	}
+	o[value[:idx]] = value[idx+1:]
+	return nil
 }
 
 // parseFlags parse flags of program.
 func parseFlags() *Config {
+	cfgPtr := flag.String("c", "", "Load options from the config file")
 	iPtr := flag.String("i", "", "Input file path or directory for the XML schema definition")
 	oPtr := flag.String("o", "xgen_out", "Output file path or directory for the generated code")
+	extPtr := flag.String("ext", "", "Extensions of the schema files in the input directory (default \".xsd,.wsdl\")")
+	depthPtr := flag.Int("depth", 0, "Depth limit of the sub-directories in the input directory")
 	pkgPtr := flag.String("p", "", "Specify the package name")
 	langPtr := flag.String("l", "", "Specify the language of generated code")
+	cmakePtr := flag.Bool("cmake", false, "Generate CMake project and test stubs for C code")
+	zodPtr := flag.Bool("zod", false, "Generate zod validation schemas alongside TypeScript types")
+	lxmlPtr := flag.Bool("lxml", false, "Generate lxml from_element and to_element helpers in Python")
+	targetFrameworkPtr := flag.String("target-framework", "", "Target framework of C# code, records are generated for net5.0 or later")
+	optionalPointersPtr := flag.Bool("optional-pointers", false, "Generate optional members as pointers with omitempty in Go")
+	tagsPtr := flag.String("tags", "", "Struct tags alongside the XML tags in Go (json,yaml)")
+	splitFilesPtr := flag.Bool("split-files", false, "Generate one file per type in Go")
+	bigNumbersPtr := flag.Bool("big-numbers", false, "Map the unbounded integers and decimals to arbitrary-precision types in Go")
+	deepCopyPtr := flag.Bool("deep-copy", false, "Generate DeepCopy methods of the structs in Go")
+	stringerPtr := flag.Bool("stringer", false, "Generate String methods rendering the structs as indented XML in Go")
+	decodeEachPtr := flag.Bool("decode-each", false, "Generate DecodeEach helpers streaming the global elements in Go")
+	typeCasePtr := flag.String("type-case", "", "Naming convention of type names")
+	fieldCasePtr := flag.String("field-case", "", "Naming convention of field names")
+	fileCasePtr := flag.String("file-case", "", "Naming convention of file names")
+	constCasePtr := flag.String("const-case", "", "Naming convention of constants")
+	tagCasePtr := flag.String("tag-case", "", "Naming convention of the keys of the struct tags")
+	escapePtr := flag.String("escape", "", "Strategy for escaping reserved words (suffix/prefix/backtick)")
+	escapeAffixPtr := flag.String("escape-affix", "", "Affix for escaping reserved words")
+	mapping := typeMappingFlag{}
+	flag.Var(mapping, "map", "Map schema type to an existing type (XSDType=Type[,Import])")
+	typesPtr := flag.String("types", "", "Override the built-in types by the JSON file")
+	collisionPtr := flag.String("collision", "", "Strategy for naming types collide across namespaces (prefix/uri/numeric)")
+	skipWrappersPtr := flag.Bool("skip-wrappers", false, "Skip wrapper types of global elements and attributes")
+	schemaOrderPtr := flag.Bool("schema-order", false, "Emit types in schema document order")
+	var roots rootsFlag
+	flag.Var(&roots, "root", "Generate only the root and its dependencies ({namespace}name)")
+	nsPackagesPtr := flag.Bool("ns-packages", false, "Generate one Go package per target namespace")
+	modulePtr := flag.String("module", "", "Import path of the output directory for -ns-packages (default by go.mod)")
+	embedSourcePtr := flag.Bool("embed-source", false, "Include the XSD declaration as a comment above each type")
+	sourceLocationPtr := flag.Bool("source-location", false, "Include the schema file and line as a comment above each type")
+	deprecationPtr := flag.String("deprecation-marker", "", "Marker of the deprecated annotations (default \"deprecated\")")
+	emitIRPtr := flag.Bool("emit-ir", false, "Write the intermediate representation as JSON beside the code")
+	fixturesPtr := flag.Bool("fixtures", false, "Generate valid sample values of the simple types for tests")
+	keepGoingPtr := flag.Bool("keep-going", false, "Generate placeholders for the failed declarations and continue")
+	xsd11Ptr := flag.Bool("xsd11", false, "Parse the schemas as XSD 1.1")
+	var schemaPaths pathsFlag
+	flag.Var(&schemaPaths, "schema-path", "Search path for the imported and included schemas")
+	catalogPtr := flag.String("catalog", "", "XML catalog for resolving the schema locations")
+	overrides := overridesFlag{}
+	flag.Var(overrides, "schema-override", "Resolve the schema location to the file (location=path)")
+	schemaCachePtr := flag.String("schema-cache", "", "Cache directory of the downloaded schemas")
+	fetchPtr := flag.Bool("fetch", false, "Download the remote schemas which couldn't be resolved locally")
+	proxyPtr := flag.String("proxy", "", "Proxy for downloading the remote schemas")
+	fetchTimeoutPtr := flag.String("fetch-timeout", "", "Timeout of downloading a remote schema (default 30s)")
+	fetchRetriesPtr := flag.Int("fetch-retries", 2, "Retries of downloading a remote schema")
+	fetchMaxSizePtr := flag.Int64("fetch-max-size", 0, "Maximum size of a remote schema in bytes (default 16 MiB)")
+	lockPtr := flag.String("lock", "", "Lockfile verifying and recording the remote schemas (e.g. \"xgen.lock\")")
+	updateLockPtr := flag.Bool("update-lock", false, "Record the changed remote schemas in the lockfile")
 	verPtr := flag.Bool("v", false, "Show version and exit")
 	helpPtr := flag.Bool("h", false, "Show this help and exit")
 	flag.Parse()
 	if *helpPtr {
-		fmt.Printf("xgen version: %s\r\nCopyright (c) 2020 - 2021 Ri Xu https://xuri.me All rights reserved.\r\n\r\nUsage:\r\n$ xgen [<flag> ...] <XSD file or directory> ...\n  -i <path>\tInput file path or directory for the XML schema definition\r\n  -o <path>\tOutput file path or directory for the generated code\r\n  -p     \tSpecify the package name\r\n  -l      \tSpecify the language of generated code (Go/C/Java/Rust/TypeScript)\r\n  -h     \tOutput this help and exit\r\n  -v     \tOutput version and exit\r\n", Cfg.Version)
+		fmt.Printf("xgen version: %s\r\nCopyright (c) 2020 - 2021 Ri Xu https://xuri.me All rights reserved.\r\n\r\nUsage:\r\n$ xgen [<flag> ...] <XSD file or directory> ...\n$ xgen init [<XSD directory>]\r\n  -c <path>\tLoad options from the config file\r\n  -i <path>\tInput file path or directory for the XML schema definition\r\n  -o <path>\tOutput file path or directory for the generated code\r\n  -ext <exts>\tExtensions of the schema files in the input directory (default \".xsd,.wsdl\")\r\n  -depth <n>\tDepth limit of the sub-directories in the input directory\r\n  -p     \tSpecify the package name\r\n  -l      \tSpecify the language of generated code (Go/C/Java/Rust/TypeScript/Ruby/Python/CSharp/Kotlin/Swift/PHP/Dart/Elixir/Haskell/OCaml/Zig/FSharp/ObjectiveC/Groovy/Lua/Perl/Crystal/Nim/Julia/VisualBasic/JSONSchema/Protobuf/Avro/GraphQL/OpenAPI/SQL/FlatBuffers/CapnProto/CUE/Markdown/DOT/XML)\r\n  -cmake  \tGenerate CMake project and test stubs for C code\r\n  -zod    \tGenerate zod validation schemas alongside TypeScript types\r\n  -lxml   \tGenerate lxml from_element and to_element helpers in Python\r\n  -target-framework <tfm>\tTarget framework of C# code, records are generated for net5.0 or later\r\n  -optional-pointers\tGenerate optional members as pointers with omitempty in Go\r\n  -tags <tags>\tStruct tags alongside the XML tags in Go (json,yaml)\r\n  -split-files\tGenerate one file per type in Go\r\n  -big-numbers\tMap the unbounded integers and decimals to arbitrary-precision types in Go\r\n  -deep-copy\tGenerate DeepCopy methods of the structs in Go\r\n  -stringer\tGenerate String methods rendering the structs as indented XML in Go\r\n  -decode-each\tGenerate DecodeEach helpers streaming the global elements in Go\r\n  -type-case <case>\tNaming convention of type names\r\n  -field-case <case>\tNaming convention of field names\r\n  -file-case <case>\tNaming convention of file names\r\n  -const-case <case>\tNaming convention of constants\r\n  -tag-case <case>\tNaming convention of the keys of the struct tags\r\n  -escape <strategy>\tStrategy for escaping reserved words (suffix/prefix/backtick)\r\n  -escape-affix <affix>\tAffix for escaping reserved words\r\n  -map <XSDType=Type[,Import]>\tMap schema type to an existing type\r\n  -types <path>\tOverride the built-in types by the JSON file\r\n  -collision <strategy>\tStrategy for naming types collide across namespaces (prefix/uri/numeric)\r\n  -skip-wrappers\tSkip wrapper types of global elements and attributes\r\n  -schema-order\tEmit types in schema document order\r\n  -root <{namespace}name>\tGenerate only the root and its dependencies\r\n  -ns-packages\tGenerate one Go package per target namespace\r\n  -module <path>\tImport path of the output directory for -ns-packages (default by go.mod)\r\n  -embed-source\tInclude the XSD declaration as a comment above each type\r\n  -source-location\tInclude the schema file and line as a comment above each type\r\n  -deprecation-marker <marker>\tMarker of the deprecated annotations\r\n  -emit-ir\tWrite the intermediate representation as JSON beside the code\r\n  -fixtures\tGenerate valid sample values of the simple types for tests\r\n  -keep-going\tGenerate placeholders for the failed declarations and continue\r\n  -xsd11 \tParse the schemas as XSD 1.1\r\n  -schema-path <dir>\tSearch path for the imported and included schemas\r\n  -catalog <path>\tXML catalog for resolving the schema locations\r\n  -schema-override <location=path>\tResolve the schema location to the file\r\n  -schema-cache <dir>\tCache directory of the downloaded schemas\r\n  -fetch \tDownload the remote schemas which couldn't be resolved locally\r\n  -proxy <url>\tProxy for downloading the remote schemas\r\n  -fetch-timeout <duration>\tTimeout of downloading a remote schema (default 30s)\r\n  -fetch-retries <n>\tRetries of downloading a remote schema (default 2)\r\n  -fetch-max-size <bytes>\tMaximum size of a remote schema (default 16 MiB)\r\n  -lock <path>\tLockfile verifying and recording the remote schemas (e.g. \"xgen.lock\")\r\n  -update-lock\tRecord the changed remote schemas in the lockfile\r\n  -h     \tOutput this help and exit\r\n  -v     \tOutput version and exit\r\n", Cfg.Version)
 		os.Exit(0)
 	}
 	if *verPtr {
 		fmt.Printf("xgen version: %s\r\n", Cfg.Version)
 		os.Exit(0)
 	}
-	if *iPtr == "" {
+	if *cfgPtr != "" {
+		if err := loadConfig(*cfgPtr, &Cfg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	// the flags specified explicitly take precedence over the config file.
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name, value := range map[string][2]*string{
+		"i":                  {&Cfg.I, iPtr},
+		"o":                  {&Cfg.O, oPtr},
+		"p":                  {&Cfg.Pkg, pkgPtr},
+		"l":                  {&Cfg.Lang, langPtr},
+		"type-case":          {&Cfg.Naming.Type, typeCasePtr},
+		"field-case":         {&Cfg.Naming.Field, fieldCasePtr},
+		"file-case":          {&Cfg.Naming.File, fileCasePtr},
+		"const-case":         {&Cfg.Naming.Constant, constCasePtr},
+		"tag-case":           {&Cfg.Naming.Tag, tagCasePtr},
+		"tags":               {&Cfg.Tags, tagsPtr},
+		"target-framework":   {&Cfg.TargetFramework, targetFrameworkPtr},
+		"escape":             {&Cfg.Escape.Strategy, escapePtr},
+		"escape-affix":       {&Cfg.Escape.Affix, escapeAffixPtr},
+		"types":              {&Cfg.Types, typesPtr},
+		"collision":          {&Cfg.Collision, collisionPtr},
+		"module":             {&Cfg.Module, modulePtr},
+		"deprecation-marker": {&Cfg.DeprecationMarker, deprecationPtr},
+		"catalog":            {&Cfg.Catalog, catalogPtr},
+		"schema-cache":       {&Cfg.SchemaCache, schemaCachePtr},
+		"proxy":              {&Cfg.Proxy, proxyPtr},
+		"fetch-timeout":      {&Cfg.FetchTimeout, fetchTimeoutPtr},
+		"ext":                {&Cfg.Extensions, extPtr},
+		"lock":               {&Cfg.Lock, lockPtr},
+	} {
+		if set[name] {
+			*value[0] = *value[1]
+		}
+	}
+	for name, value := range map[string][2]*bool{
+		"cmake":             {&Cfg.CMake, cmakePtr},
+		"zod":               {&Cfg.Zod, zodPtr},
+		"lxml":              {&Cfg.Lxml, lxmlPtr},
+		"optional-pointers": {&Cfg.OptionalPointers, optionalPointersPtr},
+		"split-files":       {&Cfg.SplitFiles, splitFilesPtr},
+		"big-numbers":       {&Cfg.BigNumbers, bigNumbersPtr},
+		"deep-copy":         {&Cfg.DeepCopy, deepCopyPtr},
+		"stringer":          {&Cfg.Stringer, stringerPtr},
+		"decode-each":       {&Cfg.DecodeEach, decodeEachPtr},
+		"skip-wrappers":     {&Cfg.SkipWrappers, skipWrappersPtr},
+		"schema-order":      {&Cfg.SchemaOrder, schemaOrderPtr},
+		"ns-packages":       {&Cfg.NSPackages, nsPackagesPtr},
+		"embed-source":      {&Cfg.EmbedSource, embedSourcePtr},
+		"source-location":   {&Cfg.SourceLocation, sourceLocationPtr},
+		"emit-ir":           {&Cfg.EmitIR, emitIRPtr},
+		"fixtures":          {&Cfg.Fixtures, fixturesPtr},
+		"keep-going":        {&Cfg.KeepGoing, keepGoingPtr},
+		"xsd11":             {&Cfg.XSD11, xsd11Ptr},
+		"fetch":             {&Cfg.Fetch, fetchPtr},
+		"update-lock":       {&Cfg.UpdateLock, updateLockPtr},
+	} {
+		if set[name] {
+			*value[0] = *value[1]
+		}
+	}
+	if Cfg.Mapping == nil {
+		Cfg.Mapping = typeMappingFlag{}
+	}
+	for name, typeMapping := range mapping {
+		Cfg.Mapping[name] = typeMapping
+	}
+	if set["depth"] {
+		Cfg.Depth = *depthPtr
+	}
+	if set["fetch-retries"] {
+		Cfg.FetchRetries = *fetchRetriesPtr
+	}
+	if set["fetch-max-size"] {
+		Cfg.FetchMaxSize = *fetchMaxSizePtr
+	}
+	Cfg.Roots = append(Cfg.Roots, roots...)
+	Cfg.SchemaPaths = append(Cfg.SchemaPaths, schemaPaths...)
+	if Cfg.SchemaOverrides == nil {
+		Cfg.SchemaOverrides = overridesFlag{}
+	}
+	for location, path := range overrides {
+		Cfg.SchemaOverrides[location] = path
+	}
+
+	if Cfg.I == "" {
 		fmt.Println("must specify input file path or directory for the XML schema definition")
 		os.Exit(1)
 	}
-	Cfg.I = *iPtr
-	if *langPtr == "" {
-		fmt.Println("must specify the language of generated code (Go/C/Java/Rust/TypeScript)")
+	if Cfg.Lang == "" {
+		fmt.Println("must specify the language of generated code (Go/C/Java/Rust/TypeScript/Ruby/Python/CSharp/Kotlin/Swift/PHP/Dart/Elixir/Haskell/OCaml/Zig/FSharp/ObjectiveC/Groovy/Lua/Perl/Crystal/Nim/Julia/VisualBasic/JSONSchema/Protobuf/Avro/GraphQL/OpenAPI/SQL/FlatBuffers/CapnProto/CUE/Markdown/DOT/XML)")
 		os.Exit(1)
 	}
-	Cfg.Lang = *langPtr
-	if *oPtr != "" {
-		Cfg.O = *oPtr
+	for _, lang := range strings.Split(Cfg.Lang, ",") {
+		if ok := SupportLang[lang]; !ok {
+			fmt.Println("unsupport language", lang)
+			os.Exit(1)
+		}
 	}
-	if ok := SupportLang[Cfg.Lang]; !ok {
-		fmt.Println("unsupport language", Cfg.Lang)
+	if Cfg.Pkg == "" {
+		Cfg.Pkg = "schema"
+	}
+	if !xgen.IsValidEscapeStrategy(Cfg.Escape.Strategy) {
+		fmt.Println("unsupport escaping strategy", Cfg.Escape.Strategy)
 		os.Exit(1)
 	}
-	if *pkgPtr != "" {
-		Cfg.Pkg = *pkgPtr
+	if Cfg.Types != "" {
+		if err := xgen.LoadBuildInTypes(Cfg.Types); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if !xgen.IsValidCollisionStrategy(Cfg.Collision) {
+		fmt.Println("unsupport collision strategy", Cfg.Collision)
+		os.Exit(1)
+	}
+	for _, convention := range []string{Cfg.Naming.Type, Cfg.Naming.Field, Cfg.Naming.File, Cfg.Naming.Constant, Cfg.Naming.Tag} {
+		if !xgen.IsValidNamingConvention(convention) {
+			fmt.Println("unsupport naming convention", convention)
+			os.Exit(1)
+		}
+	}
+	for _, tag := range structTags(Cfg.Tags) {
+		if !xgen.IsValidStructTag(tag) {
+			fmt.Println("unsupport struct tag", tag)
+			os.Exit(1)
+		}
 	}
 	return &Cfg
 }
 
-func main() {
-	cfg := parseFlags()
-	if err := xgen.PrepareOutputDir(cfg.O); err != nil {
+// structTags returns the struct tags by given comma-separated list.
+func structTags(tags string) []string {
+	if tags == "" {
+		return nil
+	}
+	return strings.Split(tags, ",")
+}
+
+// loadConfig reads the options from the JSON config file written by the
+// init command.
+func loadConfig(path string, cfg *Config) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cfg)
+}
+
+// reportEscaped prints the reserved words escaped in the code generated for
+// the given file.
+func reportEscaped(file string, escaped map[string]string) {
+	words := make([]string, 0, len(escaped))
+	for word := range escaped {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	for _, word := range words {
+		fmt.Printf("escaped reserved word %s as %s in %s\r\n", word, escaped[word], file)
+	}
+}
+
+// reportCycles prints the circular includes and imports of the schemas, which
+// are skipped.
+func reportCycles(cycles []string) {
+	for _, cycle := range cycles {
+		fmt.Printf("skipped circular include or import %s\r\n", cycle)
+	}
+}
+
+// newResolver creates the resolver chain for the schema locations by the
+// config, the locations are resolved by the overrides, the local search
+// paths, the catalog, the cache and the network in order. The remote schemas
+// resolved by the cache and the network are verified with the lockfile if
+// it's not nil.
+func newResolver(cfg *Config, lock *xgen.Lockfile) (xgen.SchemaResolver, error) {
+	locked := func(resolver xgen.SchemaResolver) xgen.SchemaResolver {
+		if lock == nil {
+			return resolver
+		}
+		return &xgen.LockedResolver{Resolver: resolver, Lock: lock}
+	}
+	chain := &xgen.ResolverChain{
+		Overrides: cfg.SchemaOverrides,
+		Resolvers: []xgen.SchemaResolver{&xgen.SearchPathResolver{Paths: cfg.SchemaPaths}},
+	}
+	if cfg.Catalog != "" {
+		catalog, err := xgen.LoadCatalog(cfg.Catalog)
+		if err != nil {
+			return nil, err
+		}
+		chain.Resolvers = append(chain.Resolvers, catalog)
+	}
+	chain.Resolvers = append(chain.Resolvers, locked(&xgen.CacheResolver{Dir: cfg.SchemaCache}))
+	if cfg.Fetch {
+		fetcher := &xgen.Fetcher{Proxy: cfg.Proxy, Hosts: cfg.Hosts, Retries: cfg.FetchRetries, MaxSize: cfg.FetchMaxSize}
+		if cfg.FetchTimeout != "" {
+			timeout, err := time.ParseDuration(cfg.FetchTimeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fetch timeout %s: %v", cfg.FetchTimeout, err)
+			}
+			fetcher.Timeout = timeout
+		}
+		chain.Resolvers = append(chain.Resolvers, locked(&xgen.NetworkResolver{CacheDir: cfg.SchemaCache, Fetcher: fetcher}))
+	}
+	return chain, nil
+}
+
+// generate generates code in the given language for all schema files by the
+// config, into the given output directory.
+func generate(cfg *Config, lang, output string, resolver xgen.SchemaResolver, failures *xgen.FailureLog) {
+	if err := xgen.PrepareOutputDir(output); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	files, err := xgen.GetFileList(cfg.I)
+	var extensions []string
+	if cfg.Extensions != "" {
+		extensions = strings.Split(cfg.Extensions, ",")
+	}
+	files, err := xgen.GetFileListWithOptions(cfg.I, xgen.FileListOptions{Extensions: extensions, MaxDepth: cfg.Depth})
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	modulePath := cfg.Module
+	if cfg.NSPackages && lang == "Go" && modulePath == "" {
+		if path, err := xgen.GoModulePath(output); err == nil {
+			modulePath = path
+		}
+	}
+	typeNamespaces := make(map[string]string)
 	for _, file := range files {
-		if err = xgen.NewParser(&xgen.Options{
+		parser := xgen.NewParser(&xgen.Options{
 			FilePath:            file,
 			InputDir:            cfg.I,
-			OutputDir:           cfg.O,
-			Lang:                cfg.Lang,
+			OutputDir:           output,
+			Lang:                lang,
 			Package:             cfg.Pkg,
+			CMake:               cfg.CMake,
+			Zod:                 cfg.Zod,
+			Lxml:                cfg.Lxml,
+			TargetFramework:     cfg.TargetFramework,
+			OptionalPointers:    cfg.OptionalPointers,
+			StructTags:          structTags(cfg.Tags),
+			SplitFiles:          cfg.SplitFiles,
+			BigNumbers:          cfg.BigNumbers,
+			DeepCopy:            cfg.DeepCopy,
+			Stringer:            cfg.Stringer,
+			DecodeEach:          cfg.DecodeEach,
+			Naming:              cfg.Naming,
+			Escape:              cfg.Escape,
+			TypeMapping:         cfg.Mapping,
+			Collision:           cfg.Collision,
+			TypeNamespaces:      typeNamespaces,
+			SkipWrappers:        cfg.SkipWrappers,
+			SchemaOrder:         cfg.SchemaOrder,
+			Roots:               cfg.Roots,
+			PackagePerNamespace: cfg.NSPackages,
+			ModulePath:          modulePath,
+			EmbedSource:         cfg.EmbedSource,
+			SourceLocation:      cfg.SourceLocation,
+			EmitIR:              cfg.EmitIR,
+			Fixtures:            cfg.Fixtures,
+			KeepGoing:           cfg.KeepGoing,
+			XSD11:               cfg.XSD11,
+			Failures:            failures,
+			DeprecationMarker:   cfg.DeprecationMarker,
+			Resolver:            resolver,
 			IncludeMap:          make(map[string]bool),
 			LocalNameNSMap:      make(map[string]string),
 			NSSchemaLocationMap: make(map[string]string),
@@ -124,10 +708,65 @@ func main() {
 			ParseFileMap:        make(map[string][]interface{}),
 			ProtoTree:           make([]interface{}, 0),
 			RemoteSchema:        make(map[string][]byte),
-		}).Parse(); err != nil {
+		})
+		if err = parser.Parse(); err != nil {
+			if cfg.KeepGoing {
+				failures.Add(file, "", err)
+				continue
+			}
 			fmt.Printf("process error on %s: %s\r\n", file, err.Error())
 			os.Exit(1)
 		}
+		reportEscaped(file, parser.Escaped)
+		reportCycles(parser.Cycles)
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := runInit(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	cfg := parseFlags()
+	// the lockfile is only verified and written if it's specified.
+	var lock *xgen.Lockfile
+	var err error
+	if cfg.Lock != "" {
+		if lock, err = xgen.LoadLockfile(cfg.Lock); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		lock.Update = cfg.UpdateLock
+	} else if cfg.UpdateLock {
+		fmt.Println("-update-lock requires -lock")
+		os.Exit(1)
+	}
+	resolver, err := newResolver(cfg, lock)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	failures := &xgen.FailureLog{}
+	langs := strings.Split(cfg.Lang, ",")
+	for _, lang := range langs {
+		output := cfg.O
+		if len(langs) > 1 {
+			output = filepath.Join(cfg.O, strings.ToLower(lang))
+		}
+		generate(cfg, lang, output, resolver, failures)
+	}
+	if lock != nil {
+		if err = lock.Save(cfg.Lock); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if summary := failures.Summary(); summary != "" {
+		fmt.Print(summary)
+		os.Exit(1)
 	}
 	fmt.Println("done")
 }
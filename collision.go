@@ -0,0 +1,157 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Strategies for naming the types which are defined with the same name in
+// different namespaces. The type defined in the namespace parsed first keeps
+// its name, the types in other namespaces will be renamed with the prefix
+// declared for the namespace in the schema, the prefix derived from the
+// namespace URI, or a numeric suffix.
+const (
+	CollisionPrefix  = "prefix"
+	CollisionURI     = "uri"
+	CollisionNumeric = "numeric"
+)
+
+// IsValidCollisionStrategy reports whether the given value is a supported
+// collision strategy, an empty value is valid and means no renaming.
+func IsValidCollisionStrategy(strategy string) bool {
+	switch strategy {
+	case "", CollisionPrefix, CollisionURI, CollisionNumeric:
+		return true
+	}
+	return false
+}
+
+// versionSegment matches the version segments in the namespace URI, such as
+// "v2", "1.0" or "2001".
+var versionSegment = regexp.MustCompile(`^[vV]?[0-9._]+$`)
+
+// nsFromURI derives the prefix for the namespace from the last non-version
+// segment of the namespace URI.
+func nsFromURI(ns string) string {
+	if idx := strings.Index(ns, "://"); idx != -1 {
+		ns = ns[idx+3:]
+	}
+	segments := strings.FieldsFunc(ns, func(r rune) bool {
+		return r == '/' || r == ':' || r == '#'
+	})
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !versionSegment.MatchString(segments[i]) {
+			return strings.NewReplacer(".", "", "-", "", "_", "").Replace(segments[i])
+		}
+	}
+	return "ns"
+}
+
+// nsPrefix returns the prefix declared for the namespace in the schema, and
+// fallback to the prefix derived from the namespace URI.
+func (opt *Options) nsPrefix(ns string) string {
+	if prefix := opt.declaredPrefix(ns); prefix != "" {
+		return prefix
+	}
+	return nsFromURI(ns)
+}
+
+// declaredPrefix returns the first prefix in alphabetical order bound to the
+// namespace in the schema, or empty string if no prefix is bound to it.
+func (opt *Options) declaredPrefix(ns string) string {
+	var prefixes []string
+	for prefix, uri := range opt.LocalNameNSMap {
+		if uri == ns && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return ""
+	}
+	sort.Strings(prefixes)
+	return prefixes[0]
+}
+
+// resolveCollisions claims the names of the global types in the proto tree
+// for the target namespace of the schema, and returns the new names for the
+// types which have been claimed by other namespaces in the same run.
+func (opt *Options) resolveCollisions() (collided map[string]string) {
+	if opt.Collision == "" || opt.TypeNamespaces == nil {
+		return
+	}
+	collided = map[string]string{}
+	for _, ele := range opt.ProtoTree {
+		var name string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name = v.Name
+		case *ComplexType:
+			name = v.Name
+		case *Group:
+			name = v.Name
+		case *AttributeGroup:
+			name = v.Name
+		case *Element:
+			name = v.Name
+		case *Attribute:
+			name = v.Name
+		}
+		if name == "" {
+			continue
+		}
+		ns, ok := opt.TypeNamespaces[name]
+		if !ok {
+			opt.TypeNamespaces[name] = opt.TargetNamespace
+			continue
+		}
+		if ns == opt.TargetNamespace {
+			continue
+		}
+		switch opt.Collision {
+		case CollisionPrefix:
+			collided[name] = fmt.Sprintf("%s:%s", opt.nsPrefix(opt.TargetNamespace), name)
+		case CollisionURI:
+			collided[name] = fmt.Sprintf("%s:%s", nsFromURI(opt.TargetNamespace), name)
+		case CollisionNumeric:
+			for i := 2; ; i++ {
+				renamed := fmt.Sprintf("%s%d", name, i)
+				owner, ok := opt.TypeNamespaces[renamed]
+				if !ok {
+					opt.TypeNamespaces[renamed] = opt.TargetNamespace
+				}
+				if !ok || owner == opt.TargetNamespace {
+					collided[name] = renamed
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+// renameTypes converts the new names of the collided or renamed types to the
+// type names of the target language, which will be used by typeName.
+func (gen *CodeGenerator) renameTypes(renames ...map[string]string) {
+	langTypeName := langTypeNames[gen.Lang]
+	if langTypeName == nil {
+		return
+	}
+	for _, renamed := range renames {
+		for name, newName := range renamed {
+			if gen.Renamed == nil {
+				gen.Renamed = map[string]string{}
+			}
+			gen.Renamed[langTypeName(name)] = langTypeName(newName)
+		}
+	}
+}
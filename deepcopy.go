@@ -0,0 +1,339 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// goTypeDecls holds the type declarations of the generated Go code and the
+// methods declared on them, which the deep copy methods and the String
+// methods are generated by.
+type goTypeDecls struct {
+	names    []string
+	declared map[string][]string
+	support  map[string]bool
+	types    map[string]ast.Expr
+	methods  map[string]map[string]bool
+	copiers  map[string]bool
+	requires map[string]bool
+}
+
+// newGoTypeDecls parses the declarations in the given files of the Go code,
+// the types declared in the support files are recorded as well.
+func newGoTypeDecls(paths []string, files map[string]string, support map[string]bool) (*goTypeDecls, error) {
+	decls := &goTypeDecls{
+		declared: map[string][]string{},
+		support:  map[string]bool{},
+		types:    map[string]ast.Expr{},
+		methods:  map[string]map[string]bool{},
+		copiers:  map[string]bool{},
+		requires: map[string]bool{},
+	}
+	for _, path := range paths {
+		file, err := parser.ParseFile(token.NewFileSet(), path, "package schema\n"+files[path], 0)
+		if err != nil {
+			return nil, err
+		}
+		decls.declared[path] = decls.add(file)
+		if support[path] {
+			for _, name := range decls.declared[path] {
+				decls.support[name] = true
+			}
+			decls.declared[path] = nil
+		}
+	}
+	return decls, nil
+}
+
+// genDeepCopy appends the DeepCopyInto and DeepCopy methods of the structs
+// declared in the given files of the Go code to the files, the types in the
+// support files are only referenced.
+func (d *goTypeDecls) genDeepCopy(paths []string, files map[string]string) {
+	for _, name := range d.names {
+		if d.methods[name]["DeepCopyInto"] || (!d.support[name] && d.isStruct(name)) {
+			d.copiers[name] = true
+		}
+	}
+	for _, path := range paths {
+		for _, name := range d.declared[path] {
+			if d.isStruct(name) {
+				files[path] += d.genMethods(name)
+			}
+		}
+	}
+}
+
+// add records the type declarations and the methods in the file, and returns
+// the names of the types declared in it.
+func (d *goTypeDecls) add(file *ast.File) (names []string) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec, ok := spec.(*ast.TypeSpec); ok {
+					names = append(names, spec.Name.Name)
+					d.types[spec.Name.Name] = spec.Type
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				if d.methods[ident.Name] == nil {
+					d.methods[ident.Name] = map[string]bool{}
+				}
+				d.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+	d.names = append(d.names, names...)
+	return
+}
+
+// isStruct reports whether the underlying type of the type by given name is
+// a struct.
+func (d *goTypeDecls) isStruct(name string) bool {
+	return d.structName(name) != ""
+}
+
+// structName returns the name of the type declaring the struct which the type
+// by given name is based on, or an empty string if it isn't a struct.
+func (d *goTypeDecls) structName(name string) string {
+	for i := 0; i <= len(d.types); i++ {
+		switch typ := d.types[name].(type) {
+		case *ast.StructType:
+			return name
+		case *ast.Ident:
+			name = typ.Name
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// needsCopy reports whether the values of the given type hold the
+// references, which are shared by the shallow copies.
+func (d *goTypeDecls) needsCopy(expr ast.Expr) bool {
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		if d.methods[typ.Name]["DeepCopyInto"] {
+			return true
+		}
+		underlying, ok := d.types[typ.Name]
+		if !ok || d.requires[typ.Name] {
+			return false
+		}
+		d.requires[typ.Name] = true
+		defer delete(d.requires, typ.Name)
+		return d.needsCopy(underlying)
+	case *ast.StructType:
+		for _, field := range typ.Fields.List {
+			if d.needsCopy(field.Type) {
+				return true
+			}
+		}
+	case *ast.SelectorExpr:
+		return d.copiers[types.ExprString(typ)]
+	case *ast.StarExpr, *ast.InterfaceType:
+		return true
+	case *ast.ArrayType:
+		return typ.Len == nil || d.needsCopy(typ.Elt)
+	case *ast.ParenExpr:
+		return d.needsCopy(typ.X)
+	}
+	return false
+}
+
+// implementers returns the types implementing the interface by given name,
+// which declare all the methods of the interface.
+func (d *goTypeDecls) implementers(name string) (names []string) {
+	iface, ok := d.types[name].(*ast.InterfaceType)
+	if !ok || len(iface.Methods.List) == 0 {
+		return
+	}
+	for _, typeName := range d.names {
+		implemented := true
+		for _, method := range iface.Methods.List {
+			for _, methodName := range method.Names {
+				implemented = implemented && d.methods[typeName][methodName.Name]
+			}
+		}
+		if implemented {
+			names = append(names, typeName)
+		}
+	}
+	return
+}
+
+// genMethods returns the DeepCopyInto and DeepCopy methods of the struct by
+// given name, the methods declared by the generator are kept.
+func (d *goTypeDecls) genMethods(name string) (methods string) {
+	if !d.methods[name]["DeepCopyInto"] {
+		var body string
+		switch typ := d.types[name].(type) {
+		case *ast.StructType:
+			body = "*out = *in\n"
+			for _, field := range typ.Fields.List {
+				for _, fieldName := range goFieldNames(field) {
+					body += d.genCopy("in."+fieldName, "out."+fieldName, field.Type)
+				}
+			}
+		case *ast.Ident:
+			body = "*out = *in\n"
+			if d.copiers[typ.Name] {
+				body = fmt.Sprintf("(*%[1]s)(in).DeepCopyInto((*%[1]s)(out))\n", typ.Name)
+			}
+		}
+		methods += fmt.Sprintf("\n// DeepCopyInto copies the %[1]s into out, which shares no references\n// with the receiver.\nfunc (in *%[1]s) DeepCopyInto(out *%[1]s) {\n%[2]s}\n", name, body)
+	}
+	if !d.methods[name]["DeepCopy"] {
+		methods += fmt.Sprintf("\n// DeepCopy returns a deep copy of the %[1]s, or nil if the receiver is nil.\nfunc (in *%[1]s) DeepCopy() *%[1]s {\n\tif in == nil {\n\t\treturn nil\n\t}\n\tout := new(%[1]s)\n\tin.DeepCopyInto(out)\n\treturn out\n}\n", name)
+	}
+	return
+}
+
+// goFieldNames returns the names of the struct field, which is the name of
+// the type for the embedded field.
+func goFieldNames(field *ast.Field) (names []string) {
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(names) != 0 {
+		return
+	}
+	typ := field.Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch typ := typ.(type) {
+	case *ast.Ident:
+		names = append(names, typ.Name)
+	case *ast.SelectorExpr:
+		names = append(names, typ.Sel.Name)
+	}
+	return
+}
+
+// genCopy returns the statements copying the references held by the value
+// of the given type from the in expression into the out expression, which
+// have been copied shallowly. The pointers of the nested values are named in
+// and out as well, as the deepcopy-gen of Kubernetes does.
+func (d *goTypeDecls) genCopy(in, out string, expr ast.Expr) string {
+	if !d.needsCopy(expr) {
+		return ""
+	}
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		if d.copiers[typ.Name] {
+			return fmt.Sprintf("%s.DeepCopyInto(%s)\n", goOperand(in), goAddress(out))
+		}
+		if _, ok := d.types[typ.Name].(*ast.InterfaceType); ok {
+			return d.genInterfaceCopy(in, out, typ.Name)
+		}
+		return d.genCopy(in, out, d.types[typ.Name])
+	case *ast.SelectorExpr:
+		// the struct declared in the package of the other namespace.
+		return fmt.Sprintf("%s.DeepCopyInto(%s)\n", goOperand(in), goAddress(out))
+	case *ast.ParenExpr:
+		return d.genCopy(in, out, typ.X)
+	case *ast.StarExpr:
+		code := fmt.Sprintf("if %s != nil {\n", in)
+		if in != "*in" {
+			code += fmt.Sprintf("in, out := %s, %s\n", goAddress(in), goAddress(out))
+		}
+		code += fmt.Sprintf("*out = new(%s)\n", types.ExprString(typ.X))
+		if d.isCopier(typ.X) {
+			return code + "(*in).DeepCopyInto(*out)\n}\n"
+		}
+		return code + "**out = **in\n" + d.genCopy("**in", "**out", typ.X) + "}\n"
+	case *ast.ArrayType:
+		if typ.Len != nil {
+			return ""
+		}
+		code := fmt.Sprintf("if %s != nil {\n", in)
+		if in != "*in" {
+			code += fmt.Sprintf("in, out := %s, %s\n", goAddress(in), goAddress(out))
+		}
+		code += fmt.Sprintf("*out = make([]%s, len(*in))\n", types.ExprString(typ.Elt))
+		if d.isCopier(typ.Elt) {
+			return code + "for i := range *in {\n(*in)[i].DeepCopyInto(&(*out)[i])\n}\n}\n"
+		}
+		if _, ok := typ.Elt.(*ast.StarExpr); !ok {
+			code += "copy(*out, *in)\n"
+		}
+		if elem := d.genCopy("(*in)[i]", "(*out)[i]", typ.Elt); elem != "" {
+			code += "for i := range *in {\n" + elem + "}\n"
+		}
+		return code + "}\n"
+	}
+	return ""
+}
+
+// isCopier reports whether the type by given expression declares the
+// DeepCopyInto method, which is the type declared in the Go code or the
+// struct declared in the package of the other namespace.
+func (d *goTypeDecls) isCopier(expr ast.Expr) bool {
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		return d.copiers[typ.Name]
+	case *ast.SelectorExpr:
+		return d.copiers[types.ExprString(typ)]
+	}
+	return false
+}
+
+// genInterfaceCopy returns the statements copying the value held by the
+// interface by given name, which is one of the types implementing it.
+func (d *goTypeDecls) genInterfaceCopy(in, out, name string) string {
+	var cases string
+	for _, implementer := range d.implementers(name) {
+		if d.copiers[implementer] {
+			cases += fmt.Sprintf("case *%[1]s:\n%[2]s = v.DeepCopy()\n", implementer, out)
+			if d.needsCopy(ast.NewIdent(implementer)) {
+				cases += fmt.Sprintf("case %[1]s:\n%[2]s = *v.DeepCopy()\n", implementer, out)
+			}
+			continue
+		}
+		cases += fmt.Sprintf("case *%[1]s:\nif v != nil {\nc := *v\n%[2]s = &c\n}\n", implementer, out)
+	}
+	if cases == "" {
+		return ""
+	}
+	return fmt.Sprintf("switch v := %s.(type) {\n%s}\n", goOperand(in), cases)
+}
+
+// goAddress returns the expression of the address of the given addressable
+// expression.
+func goAddress(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return expr[1:]
+	}
+	return "&" + expr
+}
+
+// goOperand returns the given expression as the operand of the selector or
+// the type assertion.
+func goOperand(expr string) string {
+	if strings.HasPrefix(expr, "*") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
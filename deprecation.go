@@ -0,0 +1,142 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultDeprecationMarker is the marker which the line of the annotation
+// starts with to deprecate the declaration, for example "Deprecated: use
+// NewOrderType instead." or "@deprecated". The marker is always recognized
+// in addition to the marker specified by the DeprecationMarker option.
+const DefaultDeprecationMarker = "deprecated"
+
+// defaultDeprecation is the deprecation message for the declarations which
+// are deprecated without an explanation.
+const defaultDeprecation = "this declaration is deprecated in the schema."
+
+// parseDeprecation returns the documentation without the deprecation line and
+// the explanation of the deprecation if the documentation contains a line
+// starts with "deprecated" or the given marker, the marker is matched
+// case-insensitively and could be preceded by "@".
+func parseDeprecation(doc, marker string) (rest, deprecated string) {
+	markers := []string{DefaultDeprecationMarker}
+	if marker != "" {
+		markers = append(markers, marker)
+	}
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		if text, ok := trimDeprecationMarker(line, markers); ok && deprecated == "" {
+			if deprecated = text; deprecated == "" {
+				deprecated = defaultDeprecation
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	rest = strings.TrimSpace(strings.Join(lines, "\n"))
+	return
+}
+
+// trimDeprecationMarker returns the explanation of the deprecation if the
+// line starts with any of the markers.
+func trimDeprecationMarker(line string, markers []string) (string, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "@")
+	for _, marker := range markers {
+		if len(line) < len(marker) || !strings.EqualFold(line[:len(marker)], marker) {
+			continue
+		}
+		if text := line[len(marker):]; text == "" || !unicode.IsLetter(rune(text[0])) {
+			return strings.TrimSpace(strings.TrimLeft(text, ":-. ")), true
+		}
+	}
+	return "", false
+}
+
+// markDeprecated marks the declarations in the proto tree as deprecated by
+// the annotations which contain the deprecation marker or the deprecated
+// directives.
+func (opt *Options) markDeprecated() {
+	for _, ele := range opt.ProtoTree {
+		var name string
+		var doc, deprecated *string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		case *ComplexType:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		case *Element:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		case *Attribute:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		case *Group:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		case *AttributeGroup:
+			name, doc, deprecated = v.Name, &v.Doc, &v.Deprecated
+		default:
+			continue
+		}
+		*doc, *deprecated = parseDeprecation(*doc, opt.DeprecationMarker)
+		if directive, ok := opt.Directives[name]; ok && directive.Deprecated != "" {
+			*deprecated = directive.Deprecated
+		}
+	}
+}
+
+// genDeprecated generates the deprecation comment or annotation of the type
+// in the target language by given explanation of the deprecation.
+func (gen *CodeGenerator) genDeprecated(deprecated string) string {
+	if deprecated == "" {
+		return ""
+	}
+	switch gen.Lang {
+	case "Go", "C", "ObjectiveC", "Protobuf", "FlatBuffers", "CUE":
+		return fmt.Sprintf("//\r\n// Deprecated: %s\r\n", deprecated)
+	case "TypeScript", "PHP":
+		return fmt.Sprintf("/** @deprecated %s */\r\n", deprecated)
+	case "Java", "Groovy":
+		return "@Deprecated\r\n"
+	case "Rust":
+		return fmt.Sprintf("#[deprecated(note = %q)]\r\n", deprecated)
+	case "Ruby":
+		return fmt.Sprintf("# @deprecated %s\r\n", deprecated)
+	case "Python", "Elixir", "Perl", "Crystal", "Nim", "Julia", "CapnProto":
+		return fmt.Sprintf("#\r\n# Deprecated: %s\r\n", deprecated)
+	case "CSharp", "FSharp":
+		return fmt.Sprintf("[Obsolete(%q)]\r\n", deprecated)
+	case "Kotlin":
+		return fmt.Sprintf("@Deprecated(%s)\r\n", templateQuote(deprecated))
+	case "Swift":
+		return fmt.Sprintf("@available(*, deprecated, message: %s)\r\n", swiftQuote(deprecated))
+	case "Dart":
+		return fmt.Sprintf("@Deprecated(%s)\r\n", templateQuote(deprecated))
+	case "Haskell":
+		return fmt.Sprintf("--\r\n-- Deprecated: %s\r\n", deprecated)
+	case "OCaml":
+		return fmt.Sprintf("(* Deprecated: %s *)\r\n", ocamlCommentReplacer.Replace(deprecated))
+	case "Zig":
+		return fmt.Sprintf("///\r\n/// Deprecated: %s\r\n", deprecated)
+	case "GraphQL":
+		return fmt.Sprintf("# Deprecated: %s\r\n", deprecated)
+	case "Lua":
+		return fmt.Sprintf("--\r\n-- Deprecated: %s\r\n", deprecated)
+	case "Markdown":
+		return fmt.Sprintf("**Deprecated:** %s\r\n\r\n", deprecated)
+	case "VisualBasic":
+		return fmt.Sprintf("<Obsolete(%s)>\r\n", vbQuote(deprecated))
+	case "SQL":
+		return fmt.Sprintf("--\r\n-- Deprecated: %s\r\n", deprecated)
+	case "XML":
+		return fmt.Sprintf("<!-- Deprecated: %s -->\r\n", xmlCommentReplacer.Replace(deprecated))
+	}
+	return ""
+}
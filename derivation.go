@@ -0,0 +1,150 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// getComplexType returns the complex type declared with the given name in the
+// proto tree, or nil if there isn't one.
+func (gen *CodeGenerator) getComplexType(name string) *ComplexType {
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*ComplexType); ok && v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// isAbstractType reports whether the complex type declared in the proto tree
+// by given name is abstract.
+func (gen *CodeGenerator) isAbstractType(name string) bool {
+	if complexType := gen.getComplexType(name); complexType != nil {
+		return complexType.Abstract
+	}
+	return false
+}
+
+// hasSimpleContent reports whether the complex type extends a built-in type,
+// the value of the type is the character data of the element.
+func (gen *CodeGenerator) hasSimpleContent(v *ComplexType) bool {
+	return v.Base != "" && gen.getComplexType(v.Base) == nil && isBuildInType(v.Base, gen.Lang)
+}
+
+// complexBase returns the name of the complex type which the complex type
+// extends, or empty string if it doesn't extend a complex type.
+func (gen *CodeGenerator) complexBase(v *ComplexType) string {
+	if gen.hasSimpleContent(v) {
+		return ""
+	}
+	return v.Base
+}
+
+// inheritanceLangs defines the languages whose generators express the
+// extension of the complex types by the inheritance or embedding, the complex
+// types are flattened by genDeclaration for the other languages and formats.
+var inheritanceLangs = map[string]bool{
+	"Go":         true,
+	"Java":       true,
+	"Ruby":       true,
+	"TypeScript": true,
+}
+
+// flattenComplexType returns the complex type with the members inherited
+// from the complex types it extends directly or indirectly, the members of
+// the base types come first. It's for the languages and formats without
+// inheritance, the complex type is returned as is if it doesn't extend a
+// complex type declared in the proto tree.
+func (gen *CodeGenerator) flattenComplexType(v *ComplexType) *ComplexType {
+	flattened := *v
+	seen := map[string]bool{v.Name: true}
+	for base := gen.complexBase(v); base != "" && !seen[base]; {
+		seen[base] = true
+		baseType := gen.getComplexType(base)
+		if baseType == nil {
+			break
+		}
+		flattened.AttributeGroup = append(append([]AttributeGroup{}, baseType.AttributeGroup...), flattened.AttributeGroup...)
+		flattened.Attributes = append(append([]Attribute{}, baseType.Attributes...), flattened.Attributes...)
+		flattened.Groups = append(append([]Group{}, baseType.Groups...), flattened.Groups...)
+		flattened.Elements = append(append([]Element{}, baseType.Elements...), flattened.Elements...)
+		base = gen.complexBase(baseType)
+	}
+	return &flattened
+}
+
+// derivedTypes returns the complex types derived from the complex type by
+// given name directly or indirectly, in the order of declaration.
+func (gen *CodeGenerator) derivedTypes(name string) (derived []*ComplexType) {
+	complexTypes := map[string]*ComplexType{}
+	for _, ele := range gen.ProtoTree {
+		if complexType, ok := ele.(*ComplexType); ok {
+			complexTypes[complexType.Name] = complexType
+		}
+	}
+	for _, ele := range gen.ProtoTree {
+		complexType, ok := ele.(*ComplexType)
+		if !ok {
+			continue
+		}
+		seen := map[string]bool{complexType.Name: true}
+		for base := complexType.Base; base != "" && !seen[base]; {
+			if base == name {
+				derived = append(derived, complexType)
+				break
+			}
+			seen[base] = true
+			if complexTypes[base] == nil {
+				break
+			}
+			base = complexTypes[base].Base
+		}
+	}
+	return
+}
+
+// isRecursiveType reports whether the value of the field type by given name
+// holds the type by given name, directly or through the complex types and
+// groups held by value. Such fields should be held by reference in the
+// languages whose structs are value types, otherwise the size of the struct
+// is infinite.
+func (gen *CodeGenerator) isRecursiveType(fieldType, typeName string) bool {
+	return gen.holdsType(trimNSPrefix(fieldType), typeName, map[string]bool{})
+}
+
+// holdsType reports whether the value of the complex type or group by given
+// name holds the type by given name, the visited types are skipped.
+func (gen *CodeGenerator) holdsType(name, typeName string, visited map[string]bool) bool {
+	if name == typeName {
+		return true
+	}
+	if visited[name] {
+		return false
+	}
+	visited[name] = true
+	var elements []Element
+	var groups []Group
+	if v := gen.getComplexType(name); v != nil {
+		v = gen.flattenComplexType(v)
+		elements, groups = v.Elements, v.Groups
+	}
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*Group); ok && v.Name == name && !v.Plural {
+			elements, groups = v.Elements, v.Groups
+		}
+	}
+	for _, element := range elements {
+		if !element.Plural && element.Choice == "" && gen.holdsType(trimNSPrefix(element.Type), typeName, visited) {
+			return true
+		}
+	}
+	for _, group := range groups {
+		if !group.Plural && gen.holdsType(trimNSPrefix(group.Ref), typeName, visited) {
+			return true
+		}
+	}
+	return false
+}
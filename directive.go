@@ -0,0 +1,233 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// DirectiveNamespace is the namespace of the code generation directives which
+// could be embedded in the appinfo annotations of the schema, for example:
+//
+//	<xs:complexType name="PurchaseOrderType">
+//	  <xs:annotation>
+//	    <xs:appinfo>
+//	      <xgen:name xmlns:xgen="https://github.com/xuri/xgen">Order</xgen:name>
+//	    </xs:appinfo>
+//	  </xs:annotation>
+//	  ...
+//	</xs:complexType>
+//
+// The xgen:name directive renames the type of global declaration, the
+// xgen:type directive maps the declaration to the given type of the target
+// language, the xgen:skip directive excludes the declaration from the
+// generated code, and the xgen:deprecated directive marks the type of global
+// declaration as deprecated with the optional explanation. The xgen:type and
+// xgen:skip directives also apply on the elements and attributes declared in
+// the complex types, attribute groups and groups.
+const DirectiveNamespace = "https://github.com/xuri/xgen"
+
+// Directive holds the code generation directives for a declaration.
+type Directive struct {
+	Name       string
+	Type       string
+	Skip       bool
+	Deprecated string
+}
+
+// declarationElements defines the schema elements which declare the
+// components that the directives apply to.
+var declarationElements = map[string]bool{
+	"element":        true,
+	"attribute":      true,
+	"simpleType":     true,
+	"complexType":    true,
+	"group":          true,
+	"attributeGroup": true,
+}
+
+// enterDeclaration records the name of the declaration, the name is empty
+// for anonymous types.
+func (opt *Options) enterDeclaration(ele xml.StartElement) {
+	if !declarationElements[ele.Name.Local] || ele.Name.Space == DirectiveNamespace {
+		return
+	}
+	var name string
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "name" || attr.Name.Local == "ref" {
+			name = trimNSPrefix(attr.Value)
+		}
+	}
+	opt.Declarations = append(opt.Declarations, name)
+}
+
+// leaveDeclaration drops the name of the declaration.
+func (opt *Options) leaveDeclaration(ele xml.EndElement) {
+	if !declarationElements[ele.Name.Local] || ele.Name.Space == DirectiveNamespace {
+		return
+	}
+	if l := len(opt.Declarations); l > 0 {
+		opt.Declarations = opt.Declarations[:l-1]
+	}
+}
+
+// directiveTarget returns the key of the declaration which the appinfo
+// belongs to. The key is the name for the global declarations, and the name
+// of global declaration and the local name joined with "/" for the local
+// elements and attributes.
+func (opt *Options) directiveTarget() string {
+	if len(opt.Declarations) == 0 {
+		return ""
+	}
+	for i := len(opt.Declarations) - 1; i > 0; i-- {
+		if opt.Declarations[i] != "" {
+			return opt.Declarations[0] + "/" + opt.Declarations[i]
+		}
+	}
+	return opt.Declarations[0]
+}
+
+// directive returns the directive for the declaration which the appinfo
+// belongs to.
+func (opt *Options) directive() *Directive {
+	target := opt.directiveTarget()
+	if target == "" {
+		return nil
+	}
+	if opt.Directives == nil {
+		opt.Directives = map[string]*Directive{}
+	}
+	if _, ok := opt.Directives[target]; !ok {
+		opt.Directives[target] = &Directive{}
+	}
+	return opt.Directives[target]
+}
+
+// onDirective handles the start elements of the directives.
+func (opt *Options) onDirective(ele xml.StartElement) {
+	opt.InDirective = ele.Name.Local
+	directive := opt.directive()
+	if directive == nil {
+		return
+	}
+	switch ele.Name.Local {
+	case "skip":
+		directive.Skip = true
+	case "deprecated":
+		directive.Deprecated = defaultDeprecation
+	}
+}
+
+// onDirectiveCharData handles the values of the directives.
+func (opt *Options) onDirectiveCharData(value string) {
+	directive := opt.directive()
+	if directive == nil {
+		return
+	}
+	switch opt.InDirective {
+	case "name":
+		directive.Name = value
+	case "type":
+		directive.Type = value
+	case "deprecated":
+		directive.Deprecated = value
+	}
+}
+
+// applyDirectives applies the directives on the proto tree, and returns the
+// new names for the renamed types and the type mappings merged with the
+// directives.
+func (opt *Options) applyDirectives() (renamed map[string]string, typeMapping map[string]TypeMapping) {
+	typeMapping = opt.TypeMapping
+	if len(opt.Directives) == 0 {
+		return
+	}
+	renamed, typeMapping = map[string]string{}, map[string]TypeMapping{}
+	for name, mapping := range opt.TypeMapping {
+		typeMapping[name] = mapping
+	}
+	for target, directive := range opt.Directives {
+		if strings.Contains(target, "/") {
+			continue
+		}
+		if directive.Name != "" {
+			renamed[target] = directive.Name
+		}
+		if directive.Type != "" {
+			typeMapping[target] = TypeMapping{Type: directive.Type}
+		}
+	}
+	protoTree := make([]interface{}, 0, len(opt.ProtoTree))
+	for _, ele := range opt.ProtoTree {
+		var name string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name = v.Name
+		case *ComplexType:
+			name = v.Name
+			v.Elements = opt.applyElementDirectives(name, v.Elements, typeMapping)
+			v.Attributes = opt.applyAttributeDirectives(name, v.Attributes, typeMapping)
+		case *Group:
+			name = v.Name
+			v.Elements = opt.applyElementDirectives(name, v.Elements, typeMapping)
+		case *AttributeGroup:
+			name = v.Name
+			v.Attributes = opt.applyAttributeDirectives(name, v.Attributes, typeMapping)
+		case *Element:
+			name = v.Name
+		case *Attribute:
+			name = v.Name
+		}
+		if directive, ok := opt.Directives[name]; ok && directive.Skip {
+			continue
+		}
+		protoTree = append(protoTree, ele)
+	}
+	opt.ProtoTree = protoTree
+	return
+}
+
+// applyElementDirectives applies the directives on the elements declared in
+// the given global declaration.
+func (opt *Options) applyElementDirectives(parent string, elements []Element, typeMapping map[string]TypeMapping) []Element {
+	applied := make([]Element, 0, len(elements))
+	for _, element := range elements {
+		target := parent + "/" + trimNSPrefix(element.Name)
+		if directive, ok := opt.Directives[target]; ok {
+			if directive.Skip {
+				continue
+			}
+			if directive.Type != "" {
+				element.Type, typeMapping[target] = target, TypeMapping{Type: directive.Type}
+			}
+		}
+		applied = append(applied, element)
+	}
+	return applied
+}
+
+// applyAttributeDirectives applies the directives on the attributes declared
+// in the given global declaration.
+func (opt *Options) applyAttributeDirectives(parent string, attributes []Attribute, typeMapping map[string]TypeMapping) []Attribute {
+	applied := make([]Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		target := parent + "/" + trimNSPrefix(attribute.Name)
+		if directive, ok := opt.Directives[target]; ok {
+			if directive.Skip {
+				continue
+			}
+			if directive.Type != "" {
+				attribute.Type, typeMapping[target] = target, TypeMapping{Type: directive.Type}
+			}
+		}
+		applied = append(applied, attribute)
+	}
+	return applied
+}
@@ -0,0 +1,33 @@
+package xgen
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDumpEnum(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="done"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="plainType">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="taskType">
+    <xs:sequence>
+      <xs:element name="status" type="statusType"/>
+      <xs:element name="history" type="statusType" maxOccurs="unbounded"/>
+      <xs:element name="next" type="statusType" minOccurs="0"/>
+      <xs:element name="plain" type="plainType"/>
+    </xs:sequence>
+    <xs:attribute name="state" type="statusType"/>
+  </xs:complexType>
+  <xs:element name="task" type="taskType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "task.xsd", schema, Options{})
+	fmt.Println(string(outputs["task.xsd.go"]))
+}
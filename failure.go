@@ -0,0 +1,242 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Failure holds the schema or generated file, the name of the declaration or
+// type reference failed to generate, and the error.
+type Failure struct {
+	File string
+	Name string
+	Err  error
+}
+
+// String returns the failure in the form of file: name: error.
+func (f Failure) String() string {
+	if f.Name == "" {
+		return fmt.Sprintf("%s: %v", f.File, f.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", f.File, f.Name, f.Err)
+}
+
+// FailureLog collects the failures in the keep going mode, in which the
+// declarations failed to generate are replaced by the placeholders and the
+// generation continues with the rest.
+type FailureLog struct {
+	Failures []Failure
+}
+
+// Add provides a function to record the failure of the declaration or type
+// reference by given name in the file.
+func (l *FailureLog) Add(file, name string, err error) {
+	l.Failures = append(l.Failures, Failure{File: file, Name: name, Err: err})
+}
+
+// Summary returns the summary of all failures, which is empty if there is no
+// failure.
+func (l *FailureLog) Summary() string {
+	if len(l.Failures) == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("%d failure(s), placeholders are generated for the failed declarations:\n", len(l.Failures))
+	for _, failure := range l.Failures {
+		summary += fmt.Sprintf("  %s\n", failure)
+	}
+	return summary
+}
+
+// placeholderFormats defines the placeholder declarations in each language,
+// the %s is replaced with the type name.
+var placeholderFormats = map[string]string{
+	"Go":          "type %s interface{}\n",
+	"TypeScript":  "export type %s = any;\n",
+	"C":           "typedef void *%s;\n",
+	"Java":        "public class %s {\n}\n",
+	"Rust":        "#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n}\n",
+	"Ruby":        "class %s\n\t\tinclude XmlMapper\n\tend\n",
+	"Python":      "%s: TypeAlias = Any\n",
+	"CSharp":      "public partial class %s\n{\n}\n",
+	"Kotlin":      "typealias %s = Any\n",
+	"Swift":       "public struct %s: Codable {}\n",
+	"PHP":         "class %s\n{\n}\n",
+	"Dart":        "class %[1]s {\n  %[1]s();\n\n  factory %[1]s.fromXml(XmlElement element) => %[1]s();\n\n  void buildXml(XmlBuilder builder) {}\n}\n",
+	"Elixir":      "defmodule %s do\n  @type t :: term()\n\n  def parse(node), do: node\nend\n",
+	"Haskell":     "data %[1]s = %[1]s deriving (Eq, Show)\n\nxp%[1]s :: Name -> PU [Node] %[1]s\nxp%[1]s name = xpWrap (const %[1]s) (const ((), ())) (xpElem name xpUnit xpUnit)\n",
+	"OCaml":       "and %s = Xml.xml\n",
+	"Zig":         "pub const %[1]s = struct {\n    pub fn parse(allocator: std.mem.Allocator, node: anytype) ParseError!%[1]s {\n        _ = allocator;\n        _ = node;\n        return .{};\n    }\n\n    pub fn free(self: %[1]s, allocator: std.mem.Allocator) void {\n        _ = self;\n        _ = allocator;\n    }\n};\n",
+	"FSharp":      "type %s = obj\n",
+	"ObjectiveC":  "@interface %s : NSObject\n\n- (nullable instancetype)initWithXMLNode:(XGXMLNode *)node;\n\n@end\n",
+	"Groovy":      "class %[1]s {\n    static %[1]s fromXml(GPathResult node) {\n        new %[1]s()\n    }\n}\n",
+	"Lua":         "M.%[1]s = {}\nM.%[1]s.__index = M.%[1]s\n\nfunction M.%[1]s.new(fields)\n  return setmetatable(fields or {}, M.%[1]s)\nend\n\nfunction M.%[1]s.from_node(node)\n  return M.%[1]s.new({ node = node })\nend\n",
+	"Perl":        "package %s;\n\nuse Moo;\n\nsub from_node {\n    my ($class, $node) = @_;\n    return $class->new;\n}\n",
+	"Crystal":     "struct %s\n    include XML::Serializable\n  end\n",
+	"Nim":         "  %s* = ref object\n",
+	"Julia":       "struct %[1]s <: Abstract%[1]s end\n\n%[1]s(node::EzXML.Node) = %[1]s()\n",
+	"VisualBasic": "Partial Public Class %s\nEnd Class\n",
+	"JSONSchema":  "{}",
+	"Protobuf":    "message %s {\n}\n",
+	"Avro":        "{}",
+	"GraphQL":     "scalar %s\n",
+	"OpenAPI":     "{}",
+	"SQL":         "CREATE TABLE %s (\n  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY\n);\n",
+	"FlatBuffers": "table %s {\n}\n",
+	"CapnProto":   "struct %s {\n}\n",
+	"CUE":         "#%s: _\n",
+	"Markdown":    "## %s\n",
+	"DOT":         "%s\n",
+}
+
+// failDeclaration records the failure of the declaration by given name in the
+// keep going mode, the declaration will be replaced by the placeholder. It
+// returns false if the keep going mode is off.
+func (opt *Options) failDeclaration(name string, err error) bool {
+	if !opt.KeepGoing {
+		return false
+	}
+	if opt.failed == nil {
+		opt.failed = map[string]string{}
+	}
+	if _, ok := opt.failed[trimNSPrefix(name)]; !ok {
+		opt.Failures.Add(opt.FilePath, name, err)
+		opt.failed[trimNSPrefix(name)] = err.Error()
+	}
+	return true
+}
+
+// declarationName returns the name of the declaration in the proto tree.
+func declarationName(ele interface{}) string {
+	if v := reflect.Indirect(reflect.ValueOf(ele)); v.Kind() == reflect.Struct {
+		if name := v.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String {
+			return name.String()
+		}
+	}
+	return ""
+}
+
+// genDeclaration generates code for the declaration by the function of the
+// language generator, and returns the error of the function. The complex
+// types are flattened for the languages without inheritance. In the keep
+// going mode, the declarations failed to parse or generate are replaced by
+// the placeholders, and the errors are recorded as the failures instead.
+func (gen *CodeGenerator) genDeclaration(ele interface{}, funcName string) (err error) {
+	name := declarationName(ele)
+	if v, ok := ele.(*ComplexType); ok && !inheritanceLangs[gen.Lang] {
+		ele = gen.flattenComplexType(v)
+	}
+	if reason, ok := gen.Failed[name]; ok {
+		if _, ok = gen.StructAST[name]; !ok {
+			gen.StructAST[name] = ""
+			gen.genPlaceholder(name, reason)
+		}
+		return
+	}
+	if gen.Failures == nil {
+		return callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+	}
+	field := gen.Field
+	fail := func(err error) {
+		gen.Failures.Add(gen.File, name, err)
+		gen.Field = field
+		gen.StructAST[name] = ""
+		gen.genPlaceholder(name, err.Error())
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			fail(fmt.Errorf("%v", r))
+		}
+	}()
+	if err := callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)}); err != nil {
+		fail(err)
+	}
+	return
+}
+
+// genPlaceholders generates the placeholders for the types referenced but
+// couldn't be resolved in the keep going mode.
+func (gen *CodeGenerator) genPlaceholders() {
+	declared := map[string]bool{}
+	for _, ele := range gen.ProtoTree {
+		declared[declarationName(ele)] = true
+	}
+	var names []string
+	for name := range gen.Failed {
+		if !declared[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		gen.genPlaceholder(name, gen.Failed[name])
+	}
+}
+
+// genPlaceholder generates the placeholder declaration documented with the
+// reason of the failure.
+func (gen *CodeGenerator) genPlaceholder(name, reason string) {
+	format, ok := placeholderFormats[gen.Lang]
+	if !ok {
+		return
+	}
+	prefix := "//"
+	if p, ok := commentPrefix[gen.Lang]; ok {
+		prefix = p
+	}
+	typeName := gen.typeName(langTypeNames[gen.Lang](name))
+	reason = strings.Replace(reason, "\n", " ", -1)
+	if gen.Lang == "JSONSchema" || gen.Lang == "OpenAPI" {
+		// There are no comments in JSON, the placeholder accepts any value
+		// with the reason in the comment keyword.
+		gen.genJSONSchemaDef(name, (&jsonSchema{}).set("$comment", fmt.Sprintf("%s is a placeholder, xgen failed to generate it: %s", typeName, reason)))
+		return
+	}
+	if gen.Lang == "Avro" {
+		gen.genAvroRecord(name, fmt.Sprintf("%s is a placeholder, xgen failed to generate it: %s", typeName, reason), &avroRecord{})
+		return
+	}
+	if gen.Lang == "DOT" {
+		gen.genDOTNode("Placeholder", name, &dotNode{})
+		return
+	}
+	if gen.Lang == "Markdown" {
+		gen.genMarkdownSection("ComplexType", name, "", "", "", fmt.Sprintf("Placeholder, xgen failed to generate it: %s", reason), "")
+		return
+	}
+	if gen.Lang == "OCaml" {
+		reason = ocamlCommentReplacer.Replace(reason) + " *)"
+	}
+	gen.Field += fmt.Sprintf("\n%s %s is a placeholder, xgen failed to generate it: %s\n", prefix, typeName, reason)
+	switch gen.Lang {
+	case "Ruby":
+		format = "\t" + format
+	case "Crystal":
+		format = "  " + format
+	case "Perl":
+		typeName = gen.perlPackage() + "::" + typeName
+	}
+	gen.Field += fmt.Sprintf(format, typeName)
+	switch gen.Lang {
+	case "OCaml":
+		gen.Converters += fmt.Sprintf(ocamlPlaceholderConverters, typeName)
+	case "ObjectiveC":
+		gen.Implementation += fmt.Sprintf(objcPlaceholderImplementation, typeName)
+	case "TypeScript":
+		gen.genZodSchema(typeName, "z.any()", false)
+	case "Julia":
+		gen.Forwards += fmt.Sprintf("abstract type Abstract%s end\n", typeName)
+	case "Nim":
+		gen.Forwards += fmt.Sprintf(nimFromXmlSignature, typeName) + "\n"
+		gen.Converters += fmt.Sprintf("\n%s =\n  %s()\n", fmt.Sprintf(nimFromXmlSignature, typeName), typeName)
+	}
+}
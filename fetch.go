@@ -0,0 +1,199 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultFetchTimeout is the timeout of the requests for fetching the
+	// schemas if the timeout of the fetcher isn't specified.
+	DefaultFetchTimeout = 30 * time.Second
+	// DefaultFetchMaxSize is the maximum size of the schemas in bytes if the
+	// maximum size of the fetcher isn't specified.
+	DefaultFetchMaxSize = 16 << 20
+	// DefaultRetryBackoff is the delay before the first retry if the backoff
+	// of the fetcher isn't specified, the delay doubles for each retry.
+	DefaultRetryBackoff = 500 * time.Millisecond
+)
+
+// HostConfig holds the settings for fetching the schemas from a host. The
+// header values, password and bearer token could reference the environment
+// variables in the form of $NAME or ${NAME}, so that the secrets are kept out
+// of the config file.
+type HostConfig struct {
+	Headers     map[string]string
+	Username    string
+	Password    string
+	BearerToken string
+	CertFile    string
+	KeyFile     string
+	CAFile      string
+	Proxy       string
+}
+
+// Fetcher fetches the remote schemas over HTTP(S). The proxy is taken from
+// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables unless the
+// Proxy is specified, and the Hosts hold the settings for the hosts by the
+// host name, or the host name with the port. The requests failed by the
+// network errors or the server errors are retried with the exponential
+// backoff for the times specified by Retries.
+type Fetcher struct {
+	Proxy        string
+	Hosts        map[string]*HostConfig
+	Timeout      time.Duration
+	Retries      int
+	RetryBackoff time.Duration
+	MaxSize      int64
+
+	clients map[string]*http.Client
+}
+
+// hostConfig returns the settings for the host of the URL.
+func (f *Fetcher) hostConfig(u *url.URL) *HostConfig {
+	if config, ok := f.Hosts[u.Host]; ok {
+		return config
+	}
+	if config, ok := f.Hosts[u.Hostname()]; ok {
+		return config
+	}
+	return &HostConfig{}
+}
+
+// client returns the HTTP client for the host of the URL, the clients are
+// reused for the requests to the same host.
+func (f *Fetcher) client(u *url.URL, config *HostConfig) (client *http.Client, err error) {
+	if client, ok := f.clients[u.Host]; ok {
+		return client, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	proxy := f.Proxy
+	if config.Proxy != "" {
+		proxy = config.Proxy
+	}
+	if proxy != "" {
+		var proxyURL *url.URL
+		if proxyURL, err = url.Parse(proxy); err != nil {
+			err = fmt.Errorf("invalid proxy %s: %v", proxy, err)
+			return
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if config.CertFile != "" || config.CAFile != "" {
+		transport.TLSClientConfig = &tls.Config{}
+		if config.CertFile != "" {
+			var cert tls.Certificate
+			if cert, err = tls.LoadX509KeyPair(config.CertFile, config.KeyFile); err != nil {
+				err = fmt.Errorf("load client certificate for %s: %v", u.Host, err)
+				return
+			}
+			transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+		if config.CAFile != "" {
+			var pem []byte
+			if pem, err = ioutil.ReadFile(config.CAFile); err != nil {
+				return
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				err = fmt.Errorf("no certificate found in %s", config.CAFile)
+				return
+			}
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+	timeout := f.Timeout
+	if timeout == 0 {
+		timeout = DefaultFetchTimeout
+	}
+	client = &http.Client{Transport: transport, Timeout: timeout}
+	if f.clients == nil {
+		f.clients = map[string]*http.Client{}
+	}
+	f.clients[u.Host] = client
+	return
+}
+
+// Fetch provides a function to download the schema by given URL, with the
+// proxy, headers, credentials and certificates configured for the host.
+func (f *Fetcher) Fetch(URL string) (body []byte, err error) {
+	var u *url.URL
+	if u, err = url.Parse(URL); err != nil {
+		return
+	}
+	config := f.hostConfig(u)
+	var client *http.Client
+	if client, err = f.client(u, config); err != nil {
+		return
+	}
+	backoff := f.RetryBackoff
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if body, retry, err = f.fetch(client, config, URL); err == nil || !retry || attempt >= f.Retries {
+			return
+		}
+		time.Sleep(backoff << uint(attempt))
+	}
+}
+
+// fetch sends the request for the schema, and returns whether the request
+// could be retried if it failed.
+func (f *Fetcher) fetch(client *http.Client, config *HostConfig, URL string) (body []byte, retry bool, err error) {
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodGet, URL, nil); err != nil {
+		return
+	}
+	for name, value := range config.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+	if config.Username != "" {
+		req.SetBasicAuth(os.ExpandEnv(config.Username), os.ExpandEnv(config.Password))
+	}
+	if config.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+os.ExpandEnv(config.BearerToken))
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		err = fmt.Errorf("unexpected status %s", resp.Status)
+		return
+	}
+	maxSize := f.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultFetchMaxSize
+	}
+	if resp.ContentLength > maxSize {
+		err = fmt.Errorf("schema size %d exceeds the limit of %d bytes", resp.ContentLength, maxSize)
+		return
+	}
+	if body, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1)); err != nil {
+		return nil, true, err
+	}
+	if int64(len(body)) > maxSize {
+		body, err = nil, fmt.Errorf("schema size exceeds the limit of %d bytes", maxSize)
+	}
+	return
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcherAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); ok {
+			_, _ = w.Write([]byte(username + ":" + password))
+			return
+		}
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Setenv("XGEN_TEST_SECRET", "secret"))
+	defer os.Unsetenv("XGEN_TEST_SECRET")
+
+	// the hosts are matched by the host name with the port first.
+	fetcher := &Fetcher{Hosts: map[string]*HostConfig{
+		u.Host:       {Headers: map[string]string{"X-Api-Key": "key"}, Username: "user", Password: "$XGEN_TEST_SECRET"},
+		u.Hostname(): {Headers: map[string]string{"X-Api-Key": "key"}, BearerToken: "${XGEN_TEST_SECRET}"},
+	}}
+	body, err := fetcher.Fetch(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "user:secret", string(body))
+
+	fetcher = &Fetcher{Hosts: map[string]*HostConfig{
+		u.Hostname(): {Headers: map[string]string{"X-Api-Key": "key"}, BearerToken: "${XGEN_TEST_SECRET}"},
+	}}
+	body, err = fetcher.Fetch(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer secret", string(body))
+
+	// the settings aren't sent to the other hosts.
+	fetcher = &Fetcher{Hosts: map[string]*HostConfig{
+		"example.com": {Headers: map[string]string{"X-Api-Key": "key"}},
+	}}
+	_, err = fetcher.Fetch(server.URL)
+	assert.EqualError(t, err, "unexpected status 403 Forbidden")
+}
+
+func TestFetcherRetry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/flaky.xsd":
+			if requests < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("<xs:schema/>"))
+		case "/large.xsd":
+			_, _ = w.Write([]byte(strings.Repeat(" ", 64) + "<xs:schema/>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// the server errors are retried with the backoff.
+	fetcher := &Fetcher{Retries: 2, RetryBackoff: time.Millisecond}
+	body, err := fetcher.Fetch(server.URL + "/flaky.xsd")
+	assert.NoError(t, err)
+	assert.Equal(t, "<xs:schema/>", string(body))
+	assert.Equal(t, 3, requests)
+
+	requests = 0
+	fetcher = &Fetcher{Retries: 1, RetryBackoff: time.Millisecond}
+	_, err = fetcher.Fetch(server.URL + "/flaky.xsd")
+	assert.EqualError(t, err, "unexpected status 503 Service Unavailable")
+	assert.Equal(t, 2, requests)
+
+	// the client errors aren't retried.
+	requests = 0
+	_, err = fetcher.Fetch(server.URL + "/missing.xsd")
+	assert.EqualError(t, err, "unexpected status 404 Not Found")
+	assert.Equal(t, 1, requests)
+
+	// the schemas larger than the maximum size are rejected.
+	fetcher = &Fetcher{MaxSize: 32}
+	body, err = fetcher.Fetch(server.URL + "/large.xsd")
+	assert.EqualError(t, err, "schema size 76 exceeds the limit of 32 bytes")
+	assert.Nil(t, body)
+}
@@ -0,0 +1,318 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fixtureFormat defines the file extension, the header, the declaration of
+// each sample value and the footer of the fixtures file in a language. The
+// {copyright}, {package}, {Package}, {name}, {type} and {value} in the formats
+// are replaced with the values, and {Package} is the package name with the
+// first letter in upper case. The names are converted to the naming
+// convention if it's specified. The value is quoted by the quote function, or
+// as a Go string literal if it's not specified.
+type fixtureFormat struct {
+	ext, header, line, footer string
+	naming                    string
+	quote                     func(value string) string
+}
+
+// fixtureFormats defines the formats of the fixtures file for each language.
+var fixtureFormats = map[string]fixtureFormat{
+	"Go": {
+		ext:    ".fixtures.go",
+		header: "{copyright}\n\npackage {package}\n\n// Valid sample values of the simple types in the lexical form for tests.\nconst (\n",
+		line:   "\t// {name} is a valid value of {type}.\n\t{name} = {value}\n",
+		footer: ")\n",
+	},
+	"TypeScript": {
+		ext:    ".fixtures.ts",
+		header: "{copyright}\n\n// Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n// {name} is a valid value of {type}.\nexport const {name} = {value};\n",
+	},
+	"C": {
+		ext:    ".fixtures.h",
+		header: "{copyright}\n\n// Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n// {name} is a valid value of {type}.\nstatic const char *const {name} = {value};\n",
+	},
+	"Java": {
+		ext:    ".fixtures.java",
+		header: "{copyright}\n\npackage {package};\n\n// Valid sample values of the simple types in the lexical form for tests.\npublic final class Fixtures {\n",
+		line:   "\t// {name} is a valid value of {type}.\n\tpublic static final String {name} = {value};\n",
+		footer: "}\n",
+	},
+	"Rust": {
+		ext:    ".fixtures.rs",
+		header: "{copyright}\n\n// Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n// {name} is a valid value of {type}.\npub const {name}: &str = {value};\n",
+		naming: ScreamingSnakeCase,
+	},
+	"Ruby": {
+		ext:    ".fixtures.rb",
+		header: "# frozen_string_literal: true\n\n# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\nmodule Ota\n",
+		line:   "\t# {name} is a valid value of {type}.\n\t{name} = {value}\n",
+		footer: "end\n",
+		naming: ScreamingSnakeCase,
+		quote:  singleQuote,
+	},
+	"Python": {
+		ext:    ".fixtures.py",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n# {name} is a valid value of {type}.\n{name} = {value}\n",
+		naming: ScreamingSnakeCase,
+	},
+	"CSharp": {
+		ext:    ".fixtures.cs",
+		header: "{copyright}\n\nnamespace {package}\n{\n\t// Valid sample values of the simple types in the lexical form for tests.\n\tpublic static class Fixtures\n\t{\n",
+		line:   "\t\t// {name} is a valid value of {type}.\n\t\tpublic const string {name} = {value};\n",
+		footer: "\t}\n}\n",
+	},
+	"Kotlin": {
+		ext:    ".fixtures.kt",
+		header: "{copyright}\n\npackage {package}\n\n// Valid sample values of the simple types in the lexical form for tests.\nobject Fixtures {\n",
+		line:   "\t// {name} is a valid value of {type}.\n\tconst val {name} = {value}\n",
+		footer: "}\n",
+		quote:  templateQuote,
+	},
+	"Swift": {
+		ext:    ".fixtures.swift",
+		header: "{copyright}\n\n// Valid sample values of the simple types in the lexical form for tests.\npublic enum Fixtures {\n",
+		line:   "\t// {name} is a valid value of {type}.\n\tpublic static let {name} = {value}\n",
+		footer: "}\n",
+		quote:  swiftQuote,
+	},
+	"PHP": {
+		ext:    ".fixtures.php",
+		header: "<?php\n\n{copyright}\n\nnamespace {package};\n\n// Valid sample values of the simple types in the lexical form for tests.\nfinal class Fixtures\n{\n",
+		line:   "\t// {name} is a valid value of {type}.\n\tpublic const {name} = {value};\n",
+		footer: "}\n",
+		quote:  singleQuote,
+	},
+	"Dart": {
+		ext:    ".fixtures.dart",
+		header: "{copyright}\n\n// Valid sample values of the simple types in the lexical form for tests.\nabstract final class Fixtures {\n",
+		line:   "  // {name} is a valid value of {type}.\n  static const {name} = {value};\n",
+		footer: "}\n",
+		quote:  templateQuote,
+	},
+	"Elixir": {
+		ext:    ".fixtures.ex",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\ndefmodule {Package}.Fixtures do\n  @moduledoc \"Valid sample values of the simple types in the lexical form for tests.\"\n",
+		line:   "\n  @doc \"{name} is a valid value of {type}.\"\n  def {name}, do: {value}\n",
+		footer: "end\n",
+		naming: SnakeCase,
+		quote:  elixirQuote,
+	},
+	"Haskell": {
+		ext:    ".fixtures.hs",
+		header: "-- Code generated by xgen. DO NOT EDIT.\n\n-- | Valid sample values of the simple types in the lexical form for tests.\nmodule {Package}.Fixtures where\n",
+		line:   "\n-- | {name} is a valid value of {type}.\n{name} :: String\n{name} = {value}\n",
+		naming: CamelCase,
+	},
+	"OCaml": {
+		ext:    ".fixtures.ml",
+		header: "(* Code generated by xgen. DO NOT EDIT. *)\n\n(* Valid sample values of the simple types in the lexical form for tests. *)\n",
+		line:   "\n(* {name} is a valid value of {type}. *)\nlet {name} = {value}\n",
+		naming: SnakeCase,
+		quote:  hexQuote,
+	},
+	"Zig": {
+		ext:    ".fixtures.zig",
+		header: "{copyright}\n\n//! Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n/// {name} is a valid value of {type}.\npub const {name} = {value};\n",
+		naming: SnakeCase,
+		quote:  hexQuote,
+	},
+	"FSharp": {
+		ext:    ".fixtures.fs",
+		header: "{copyright}\n\n/// Valid sample values of the simple types in the lexical form for tests.\nmodule {Package}.Fixtures\n",
+		line:   "\n/// {name} is a valid value of {type}.\n[<Literal>]\nlet {name} = {value}\n",
+		naming: PascalCase,
+	},
+	"ObjectiveC": {
+		ext:    ".fixtures.h",
+		header: "{copyright}\n\n#import <Foundation/Foundation.h>\n\n// Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n/// {name} is a valid value of {type}.\nstatic NSString *const {name} = @{value};\n",
+		naming: PascalCase,
+	},
+	"Groovy": {
+		ext:    ".fixtures.groovy",
+		header: "{copyright}\n\npackage {package}\n\n// Valid sample values of the simple types in the lexical form for tests.\nclass Fixtures {\n",
+		line:   "    // {name} is a valid value of {type}.\n    static final String {name} = {value}\n",
+		footer: "}\n",
+		naming: ScreamingSnakeCase,
+		quote:  templateQuote,
+	},
+	"Lua": {
+		ext:    ".fixtures.lua",
+		header: "-- Code generated by xgen. DO NOT EDIT.\n\n-- Valid sample values of the simple types in the lexical form for tests.\nlocal fixtures = {}\n",
+		line:   "\n-- {name} is a valid value of {type}.\nfixtures.{name} = {value}\n",
+		footer: "\nreturn fixtures\n",
+		naming: ScreamingSnakeCase,
+		quote:  hexQuote,
+	},
+	"Perl": {
+		ext:    ".fixtures.pm",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\npackage {Package}::Fixtures;\n\nuse strict;\nuse warnings;\n",
+		line:   "\n# {name} is a valid value of {type}.\nuse constant {name} => {value};\n",
+		footer: "\n1;\n",
+		naming: ScreamingSnakeCase,
+		quote:  singleQuote,
+	},
+	"Crystal": {
+		ext:    ".fixtures.cr",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\nmodule {Package}::Fixtures\n",
+		line:   "  # {name} is a valid value of {type}.\n  {name} = {value}\n",
+		footer: "end\n",
+		naming: ScreamingSnakeCase,
+		quote:  crystalQuote,
+	},
+	"Nim": {
+		ext:    ".fixtures.nim",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\n",
+		line:   "\n# {name} is a valid value of {type}.\nconst {name}* = {value}\n",
+		naming: CamelCase,
+		quote:  hexQuote,
+	},
+	"Julia": {
+		ext:    ".fixtures.jl",
+		header: "# Code generated by xgen. DO NOT EDIT.\n\n# Valid sample values of the simple types in the lexical form for tests.\nmodule Fixtures\n",
+		line:   "\n# {name} is a valid value of {type}.\nconst {name} = {value}\n",
+		footer: "\nend\n",
+		naming: ScreamingSnakeCase,
+		quote:  templateQuote,
+	},
+	"VisualBasic": {
+		ext:    ".fixtures.vb",
+		header: "' Code generated by xgen. DO NOT EDIT.\n\nNamespace {package}\n\t' Valid sample values of the simple types in the lexical form for tests.\n\tPublic Module Fixtures\n",
+		line:   "\t\t' {name} is a valid value of {type}.\n\t\tPublic Const {name} As String = {value}\n",
+		footer: "\tEnd Module\nEnd Namespace\n",
+		quote:  vbQuote,
+	},
+	"SQL": {
+		ext:    ".fixtures.sql",
+		header: "-- Code generated by xgen. DO NOT EDIT.\n\n-- Valid sample values of the simple types in the lexical form for tests.\nCREATE TABLE fixtures (\n  name TEXT PRIMARY KEY,\n  value TEXT NOT NULL\n);\n",
+		line:   "\n-- {name} is a valid value of {type}.\nINSERT INTO fixtures (name, value) VALUES ('{name}', {value});\n",
+		footer: "",
+		naming: SnakeCase,
+		quote:  sqlQuote,
+	},
+}
+
+// genFixtures generates the fixtures file with a valid sample value for each
+// simple type in the intermediate representation beside the generated code,
+// the simple types without any valid value are omitted.
+func (gen *CodeGenerator) genFixtures(ir *IR) error {
+	format, ok := fixtureFormats[gen.Lang]
+	if !ok {
+		return nil
+	}
+	samples, _ := ir.Samples()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	var source strings.Builder
+	source.WriteString(strings.NewReplacer("{copyright}", copyright, "{package}", packageName, "{Package}", MakeFirstUpperCase(packageName)).Replace(format.header))
+	declared := map[string]bool{}
+	for _, declaration := range ir.Declarations {
+		v := declaration.SimpleType
+		if v == nil || gen.isMappedType(v) {
+			continue
+		}
+		value, ok := samples[v.Name]
+		typeName := gen.typeName(langTypeNames[gen.Lang](v.Name))
+		if !ok || declared[typeName] {
+			continue
+		}
+		declared[typeName] = true
+		quoted := strconv.Quote(value)
+		name := ConvertCase("Sample"+MakeFirstUpperCase(typeName), format.naming)
+		if format.quote != nil {
+			quoted = format.quote(value)
+		}
+		source.WriteString(strings.NewReplacer("{name}", name, "{type}", typeName, "{value}", quoted).Replace(format.line))
+	}
+	source.WriteString(format.footer)
+	return gen.writeFile(gen.File+format.ext, []byte(source.String()))
+}
+
+// singleQuote returns the single-quoted string literal of the given value for
+// the languages which only escape the backslash and the single quote in it.
+func singleQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// templateQuote returns the double-quoted string literal of the given value
+// for the languages which support string templates, e.g. Kotlin, Dart and
+// Julia, the "$" in the value is escaped.
+func templateQuote(value string) string {
+	return strings.Replace(strconv.Quote(value), "$", `\$`, -1)
+}
+
+// hexQuote returns the double-quoted string literal of the given value for the
+// languages which escape the control characters by the hexadecimal escape
+// sequences only, e.g. OCaml and Zig.
+func hexQuote(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"', '\\':
+			quoted.WriteByte('\\')
+			quoted.WriteByte(c)
+		case '\n':
+			quoted.WriteString(`\n`)
+		case '\r':
+			quoted.WriteString(`\r`)
+		case '\t':
+			quoted.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&quoted, `\x%02x`, c)
+				continue
+			}
+			quoted.WriteByte(c)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// crystalQuote returns the double-quoted string literal of the given value for
+// Crystal, the string interpolation in the value is escaped.
+func crystalQuote(value string) string {
+	return strings.Replace(hexQuote(value), "#{", `\#{`, -1)
+}
+
+// vbQuote returns the string literal of the given value for Visual Basic, the
+// quotation marks are doubled, including the typographic ones which are also
+// the delimiters of the literal, and the control characters are concatenated
+// by the ChrW function since there are no escape sequences in the literal.
+func vbQuote(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\u201c' || r == '\u201d':
+			quoted.WriteRune(r)
+			quoted.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&quoted, `" & ChrW(%d) & "`, r)
+		default:
+			quoted.WriteRune(r)
+		}
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
@@ -0,0 +1,366 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var avroBuildInType = map[string]bool{
+	"array":            true,
+	"boolean":          true,
+	"bytes":            true,
+	"date":             true,
+	"double":           true,
+	"float":            true,
+	"int":              true,
+	"long":             true,
+	"string":           true,
+	"time-millis":      true,
+	"timestamp-millis": true,
+}
+
+// avroRef is the reference to the declaration by name in the Avro schemas,
+// which is replaced by the definition at the first use, and by the name of
+// the named types afterward.
+type avroRef string
+
+// GenAvro generate Apache Avro schema (.avsc) for XML schema definition
+// files. The complex types, groups and attribute groups are generated as
+// records, and the enumerations are generated as enums. The schema of the
+// global element is self-contained, each named type is defined at its first
+// use for the compatibility with the schema registry, and the schemas are
+// generated as a union if there is more than one global element. The records
+// are generated as the schemas if there is no global element.
+func (gen *CodeGenerator) GenAvro() error {
+	var roots []interface{}
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) {
+			continue
+		}
+		if v, ok := ele.(*Element); ok {
+			roots = append(roots, gen.genAvroType(v.Type, v.Plural))
+		}
+		if gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Avro%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	if len(roots) == 0 {
+		for _, ele := range gen.ProtoTree {
+			name := declarationName(ele)
+			if schema, ok := gen.Schemas[name].(*jsonSchema); ok && schema.values["type"] == "record" {
+				roots = append(roots, avroRef(name))
+			}
+		}
+	}
+	namespace := gen.Package
+	if namespace == "" {
+		namespace = "schema"
+	}
+	defined := map[string]bool{}
+	for i, root := range roots {
+		roots[i] = gen.resolveAvro(root, defined, namespace)
+	}
+	var schema interface{} = roots
+	if len(roots) == 1 {
+		schema = roots[0]
+	}
+	data, err := marshalJSON(schema)
+	if err != nil {
+		return err
+	}
+	var source bytes.Buffer
+	if err = json.Indent(&source, data, "", "  "); err != nil {
+		return err
+	}
+	source.WriteByte('\n')
+	return gen.writeFile(gen.File+".avsc", source.Bytes())
+}
+
+func genAvroFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genAvroName returns the valid name of the Avro record field or enum symbol
+// by given XML name, the characters which aren't allowed are replaced by the
+// underscores.
+func genAvroName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, trimNSPrefix(name))
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// genAvroBuildIn returns the schema of the built-in type, the date and time
+// types are annotated by the logical types.
+func genAvroBuildIn(name string) interface{} {
+	switch name {
+	case "array":
+		return (&jsonSchema{}).set("type", "array").set("items", "string")
+	case "date", "time-millis":
+		return (&jsonSchema{}).set("type", "int").set("logicalType", name)
+	case "timestamp-millis":
+		return (&jsonSchema{}).set("type", "long").set("logicalType", name)
+	}
+	return name
+}
+
+// genAvroType returns the schema of the type by given name, the declarations
+// are referred by the avroRef and the mapped types are referred by the names
+// in the type mapping.
+func (gen *CodeGenerator) genAvroType(name string, plural bool) (schema interface{}) {
+	name = trimNSPrefix(name)
+	if _, ok := avroBuildInType[name]; ok {
+		schema = genAvroBuildIn(name)
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		schema = mappedType
+	} else if name == "" {
+		schema = "string"
+	} else {
+		schema = avroRef(name)
+	}
+	if plural {
+		schema = (&jsonSchema{}).set("type", "array").set("items", schema)
+	}
+	return
+}
+
+// resolveAvro returns the copy of the schema in which the references are
+// replaced by the definitions at the first use, and by the names of the named
+// types afterward. The namespace is specified on the top-level named type.
+func (gen *CodeGenerator) resolveAvro(schema interface{}, defined map[string]bool, namespace string) interface{} {
+	switch v := schema.(type) {
+	case avroRef:
+		name := string(v)
+		definition, ok := gen.Schemas[name]
+		if !ok {
+			if target := gen.getBaseType(name); target != name {
+				return gen.resolveAvro(gen.genAvroType(target, false), defined, namespace)
+			}
+			return "string"
+		}
+		if named, ok := definition.(*jsonSchema); ok && named.values["name"] != nil {
+			if defined[name] {
+				return named.values["name"]
+			}
+			defined[name] = true
+		}
+		return gen.resolveAvro(definition, defined, namespace)
+	case *jsonSchema:
+		resolved := &jsonSchema{}
+		for _, key := range v.keys {
+			resolved.set(key, gen.resolveAvro(v.values[key], defined, ""))
+			if key == "name" && namespace != "" {
+				resolved.set("namespace", namespace)
+			}
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = gen.resolveAvro(item, defined, "")
+		}
+		return resolved
+	}
+	return schema
+}
+
+// genAvroDef records the definition of the declaration by given name.
+func (gen *CodeGenerator) genAvroDef(name string, schema interface{}) {
+	if gen.Schemas == nil {
+		gen.Schemas = map[string]interface{}{}
+	}
+	gen.Schemas[name] = schema
+	data, _ := marshalJSON(schema)
+	gen.StructAST[name] = string(data)
+}
+
+// avroRecord holds the fields of the record in generating.
+type avroRecord struct {
+	fields []interface{}
+	names  map[string]bool
+}
+
+// addField provides a function to add the field by given XML name and type,
+// the plural fields are arrays default to empty, and the optional fields are
+// unions with null default to null. The index of the field is appended to the
+// name of the field which collides with another, e.g. the attribute and
+// element with the same name.
+func (r *avroRecord) addField(name, doc string, fieldType interface{}, plural, optional bool) {
+	fieldName := genAvroName(name)
+	if r.names == nil {
+		r.names = map[string]bool{}
+	}
+	if r.names[fieldName] {
+		fieldName = fmt.Sprintf("%s_%d", fieldName, len(r.fields))
+	}
+	r.names[fieldName] = true
+	field := (&jsonSchema{}).set("name", fieldName)
+	if doc = strings.TrimSpace(doc); doc != "" {
+		field.set("doc", doc)
+	}
+	switch {
+	case plural:
+		field.set("type", (&jsonSchema{}).set("type", "array").set("items", fieldType)).set("default", []interface{}{})
+	case optional:
+		field.set("type", []interface{}{"null", fieldType}).set("default", nil)
+	default:
+		field.set("type", fieldType)
+	}
+	r.fields = append(r.fields, field)
+}
+
+// genAvroRecord generates the record by given declaration and fields.
+func (gen *CodeGenerator) genAvroRecord(name, doc string, record *avroRecord) {
+	schema := (&jsonSchema{}).set("type", "record").set("name", gen.typeName(genAvroFieldName(name)))
+	if doc = strings.TrimSpace(doc); doc != "" {
+		schema.set("doc", doc)
+	}
+	fields := record.fields
+	if fields == nil {
+		fields = []interface{}{}
+	}
+	gen.genAvroDef(name, schema.set("fields", fields))
+}
+
+// AvroSimpleType generates code for simple type XML schema in Apache Avro
+// schema. The lists are generated as arrays, the unions are generated as the
+// strings in the lexical form, and the other restrictions are generated as
+// the base types.
+func (gen *CodeGenerator) AvroSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	switch {
+	case v.List:
+		gen.genAvroDef(v.Name, gen.genAvroType(v.Base, true))
+	case v.Union:
+		gen.genAvroDef(v.Name, "string")
+	case len(v.Restriction.Enum) > 0:
+		var symbols []interface{}
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := genAvroName(enum)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			symbols = append(symbols, member)
+		}
+		schema := (&jsonSchema{}).set("type", "enum").set("name", gen.typeName(genAvroFieldName(v.Name)))
+		if doc := strings.TrimSpace(v.Doc); doc != "" {
+			schema.set("doc", doc)
+		}
+		gen.genAvroDef(v.Name, schema.set("symbols", symbols))
+	default:
+		gen.genAvroDef(v.Name, gen.genAvroType(v.Base, false))
+	}
+	return
+}
+
+// AvroComplexType generates code for complex type XML schema in Apache Avro
+// schema.
+func (gen *CodeGenerator) AvroComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	record := &avroRecord{}
+	for _, attrGroup := range v.AttributeGroup {
+		record.addField(attrGroup.Name, "", gen.genAvroType(attrGroup.Ref, false), false, false)
+	}
+	for _, attribute := range v.Attributes {
+		record.addField(attribute.Name, attribute.Doc, gen.genAvroType(attribute.Type, false), attribute.Plural, attribute.Optional)
+	}
+	for _, group := range v.Groups {
+		record.addField(group.Name, "", gen.genAvroType(group.Ref, false), group.Plural, false)
+	}
+	for _, element := range v.Elements {
+		record.addField(element.Name, element.Doc, gen.genAvroType(element.Type, false), element.Plural, element.Optional)
+	}
+	gen.genAvroRecord(v.Name, v.Doc, record)
+	return
+}
+
+// AvroGroup generates code for group XML schema in Apache Avro schema.
+func (gen *CodeGenerator) AvroGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	record := &avroRecord{}
+	for _, element := range v.Elements {
+		record.addField(element.Name, element.Doc, gen.genAvroType(element.Type, false), element.Plural, element.Optional)
+	}
+	for _, group := range v.Groups {
+		record.addField(group.Name, "", gen.genAvroType(group.Ref, false), group.Plural, false)
+	}
+	gen.genAvroRecord(v.Name, v.Doc, record)
+	return
+}
+
+// AvroAttributeGroup generates code for attribute group XML schema in Apache
+// Avro schema.
+func (gen *CodeGenerator) AvroAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	record := &avroRecord{}
+	for _, attribute := range v.Attributes {
+		record.addField(attribute.Name, attribute.Doc, gen.genAvroType(attribute.Type, false), attribute.Plural, attribute.Optional)
+	}
+	gen.genAvroRecord(v.Name, v.Doc, record)
+	return
+}
+
+// AvroElement generates code for element XML schema in Apache Avro schema.
+// The element refers to its type, unless the type has the same name.
+func (gen *CodeGenerator) AvroElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if !v.Plural && trimNSPrefix(v.Type) == v.Name {
+		return
+	}
+	gen.genAvroDef(v.Name, gen.genAvroType(v.Type, v.Plural))
+	return
+}
+
+// AvroAttribute generates code for attribute XML schema in Apache Avro
+// schema. The attribute refers to its type.
+func (gen *CodeGenerator) AvroAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if !v.Plural && trimNSPrefix(v.Type) == v.Name {
+		return
+	}
+	gen.genAvroDef(v.Name, gen.genAvroType(v.Type, v.Plural))
+	return
+}
@@ -11,6 +11,7 @@ package xgen
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 )
@@ -38,20 +39,103 @@ var cBuildInType = map[string]bool{
 // files.
 func (gen *CodeGenerator) GenC() error {
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
 			continue
 		}
 		funcName := fmt.Sprintf("C%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration(ele, funcName)
 	}
-	f, err := os.Create(gen.File + ".h")
-	if err != nil {
+	gen.genPlaceholders()
+	var includePackage string
+	for _, mapping := range gen.getImportMappings() {
+		includePackage += fmt.Sprintf("#include \"%s\"\n", mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("%s\n%s%s", copyright, includePackage, gen.Field))
+	if err := gen.writeFile(gen.File+".h", source); err != nil {
 		return err
 	}
-	defer f.Close()
-	source := []byte(fmt.Sprintf("%s\n%s", copyright, gen.Field))
-	f.Write(source)
-	return err
+	if gen.CMake {
+		return gen.genCMake()
+	}
+	return nil
+}
+
+var cCMakeLists = `# Scaffolded by xgen, which doesn't overwrite it once it exists.
+
+cmake_minimum_required(VERSION 3.12)
+project(%s C)
+
+enable_testing()
+find_package(LibXml2 REQUIRED)
+
+file(GLOB XGEN_TESTS ${CMAKE_CURRENT_SOURCE_DIR}/test_*.c)
+foreach(XGEN_TEST ${XGEN_TESTS})
+	get_filename_component(XGEN_TARGET ${XGEN_TEST} NAME_WE)
+	add_executable(${XGEN_TARGET} ${XGEN_TEST})
+	target_include_directories(${XGEN_TARGET} PRIVATE ${CMAKE_CURRENT_SOURCE_DIR})
+	target_link_libraries(${XGEN_TARGET} PRIVATE LibXml2::LibXml2)
+	add_test(NAME ${XGEN_TARGET} COMMAND ${XGEN_TARGET})
+endforeach()
+`
+
+var cTestStub = `// Scaffolded by xgen, which doesn't overwrite it once it exists.
+
+#include <assert.h>
+#include <stdbool.h>
+#include <libxml/parser.h>
+#include "%s"
+
+int main(void)
+{
+	%s root;
+	(void)root;
+	xmlInitParser();
+	// TODO: parse a sample document and assert on the fields of %s.
+	xmlCleanupParser();
+	return 0;
+}
+`
+
+// genCMake generates a CMakeLists.txt and a unit test stub for each root
+// element next to the C header, the tests are registered with CTest and
+// linked against libxml2. They are scaffolds filled in by hand, so the
+// existing ones are kept.
+func (gen *CodeGenerator) genCMake() error {
+	dir := filepath.Dir(gen.File)
+	projectName := gen.Package
+	if projectName == "" {
+		projectName = "schema"
+	}
+	if err := gen.writeScaffold(filepath.Join(dir, "CMakeLists.txt"), []byte(fmt.Sprintf(cCMakeLists, projectName))); err != nil {
+		return err
+	}
+	for _, ele := range gen.ProtoTree {
+		v, ok := ele.(*Element)
+		if !ok {
+			continue
+		}
+		typeName, rootType := gen.typeName(genCFieldName(v.Name)), gen.typeName(genCFieldName(v.Name))
+		if gen.SkipWrappers {
+			rootType = gen.genCFieldType(gen.getBaseType(v.Type))
+		}
+		stub := fmt.Sprintf(cTestStub, filepath.Base(gen.File)+".h", rootType, typeName)
+		if err := gen.writeScaffold(filepath.Join(dir, fmt.Sprintf("test_%s.c", ToSnakeCase(typeName))), []byte(stub)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeScaffold writes the file by given path unless it exists.
+func (gen *CodeGenerator) writeScaffold(path string, data []byte) error {
+	if gen.Outputs != nil {
+		if _, ok := gen.Outputs[filepath.ToSlash(path)]; ok {
+			return nil
+		}
+	} else if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return gen.writeFile(path, data)
 }
 
 func innerArray(dataType string) (string, bool) {
@@ -74,17 +158,20 @@ func genCFieldName(name string) (fieldName string) {
 	return
 }
 
-func genCFieldType(name string) string {
+func (gen *CodeGenerator) genCFieldType(name string) string {
 	if _, ok := cBuildInType[name]; ok {
 		return name
 	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
 	var fieldType string
 	for _, str := range strings.Split(name, ".") {
 		fieldType += MakeFirstUpperCase(str)
 	}
 	fieldType = MakeFirstUpperCase(strings.Replace(fieldType, "-", "", -1))
 	if fieldType != "" {
-		return fieldType
+		return gen.typeName(fieldType)
 	}
 	return "void"
 }
@@ -94,44 +181,41 @@ func genCFieldType(name string) string {
 func (gen *CodeGenerator) CSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf("%s %s[];\n", genCFieldType(fieldType), genCFieldName(v.Name))
+			fieldType := gen.genCFieldType(gen.getBaseType(v.Base))
+			content := fmt.Sprintf("%s %s[];\n", gen.genCFieldType(fieldType), gen.typeName(genCFieldName(v.Name)))
 			gen.StructAST[v.Name] = content
-			fieldName := genCFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%stypedef %s", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+			fieldName := gen.typeName(genCFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%stypedef %s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name])
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			content := "struct {\n"
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
+			for _, member := range v.MemberTypes {
 				var plural, fieldType string
 				var ok bool
-				if fieldType, ok = innerArray(genCFieldType(memberType)); ok {
+				if fieldType, ok = innerArray(gen.genCFieldType(member.Type)); ok {
 					plural = "[]"
 				}
-				content += fmt.Sprintf("\t%s %s%s;\n", fieldType, genCFieldName(memberName), plural)
+				content += fmt.Sprintf("\t%s %s%s;\n", fieldType, gen.fieldName(genCFieldName(member.Name)), plural)
 			}
 			content += "}"
 			gen.StructAST[v.Name] = content
-			fieldName := genCFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+			fieldName := gen.typeName(genCFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name], fieldName)
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))); ok {
+		if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(v.Base))); ok {
 			plural = "[]"
 		}
-		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name), plural)
-		fieldName := genCFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, gen.typeName(genCFieldName(v.Name)), plural)
+		fieldName := gen.typeName(genCFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name])
 	}
 	return
 }
@@ -142,8 +226,8 @@ func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := "struct {\n"
 		for _, attrGroup := range v.AttributeGroup {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t%s %s;\n", genCFieldType(fieldType), genCFieldName(attrGroup.Name))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t%s %s;\n", gen.genCFieldType(fieldType), gen.fieldName(genCFieldName(attrGroup.Name)))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -153,10 +237,10 @@ func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 			}
 			var plural, fieldType string
 			var ok bool
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
+			if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(attribute.Type))); ok {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %sAttr%s; // attr%s\n", fieldType, genCFieldName(attribute.Name), plural, optional)
+			content += fmt.Sprintf("\t%s %s%s; // attr%s\n", fieldType, gen.fieldName(genCFieldName(attribute.Name)+"Attr"), plural, optional)
 		}
 
 		for _, group := range v.Groups {
@@ -164,21 +248,21 @@ func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(group.Name), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(gen.getBaseType(group.Ref)), gen.fieldName(genCFieldName(group.Name)), plural)
 		}
 
 		for _, element := range v.Elements {
 			var plural, fieldType string
 			var ok bool
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))); ok || element.Plural {
+			if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(element.Type))); ok || element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", fieldType, genCFieldName(element.Name), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", fieldType, gen.fieldName(genCFieldName(element.Name)), plural)
 		}
 		content += "}"
 		gen.StructAST[v.Name] = content
-		fieldName := genCFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		fieldName := gen.typeName(genCFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name], fieldName)
 	}
 	return
 }
@@ -192,7 +276,7 @@ func (gen *CodeGenerator) CGroup(v *Group) {
 			if element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)), genCFieldName(element.Name), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(gen.getBaseType(element.Type)), gen.fieldName(genCFieldName(element.Name)), plural)
 		}
 
 		for _, group := range v.Groups {
@@ -200,13 +284,13 @@ func (gen *CodeGenerator) CGroup(v *Group) {
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(group.Name), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(gen.getBaseType(group.Ref)), gen.fieldName(genCFieldName(group.Name)), plural)
 		}
 
 		content += "}"
 		gen.StructAST[v.Name] = content
-		fieldName := genCFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		fieldName := gen.typeName(genCFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name], fieldName)
 	}
 	return
 }
@@ -222,15 +306,15 @@ func (gen *CodeGenerator) CAttributeGroup(v *AttributeGroup) {
 			if attribute.Optional {
 				optional = `, optional`
 			}
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
+			if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(attribute.Type))); ok {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %sAttr%s; // attr%s\n", fieldType, genCFieldName(attribute.Name), plural, optional)
+			content += fmt.Sprintf("\t%s %s%s; // attr%s\n", fieldType, gen.fieldName(genCFieldName(attribute.Name)+"Attr"), plural, optional)
 		}
 		content += "}"
 		gen.StructAST[v.Name] = content
-		fieldName := genCFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		fieldName := gen.typeName(genCFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name], fieldName)
 	}
 }
 
@@ -239,10 +323,10 @@ func (gen *CodeGenerator) CElement(v *Element) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
+		if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(v.Type))); ok || v.Plural {
 			plural = "[]"
 		}
-		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name), plural)
+		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, gen.typeName(genCFieldName(v.Name)), plural)
 		gen.Field += fmt.Sprintf("\ntypedef %s;\n", gen.StructAST[v.Name])
 	}
 }
@@ -252,11 +336,11 @@ func (gen *CodeGenerator) CAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
+		if fieldType, ok = innerArray(gen.genCFieldType(gen.getBaseType(v.Type))); ok || v.Plural {
 			plural = "[]"
 		}
-		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name), plural)
-		fieldName := genCFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, gen.typeName(genCFieldName(v.Name)), plural)
+		fieldName := gen.typeName(genCFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), gen.StructAST[v.Name])
 	}
 }
@@ -0,0 +1,340 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var csharpBuildInType = map[string]bool{
+	"bool":             true,
+	"byte":             true,
+	"byte[]":           true,
+	"DateTime":         true,
+	"decimal":          true,
+	"double":           true,
+	"float":            true,
+	"int":              true,
+	"List<string>":     true,
+	"long":             true,
+	"object":           true,
+	"sbyte":            true,
+	"short":            true,
+	"string":           true,
+	"uint":             true,
+	"ulong":            true,
+	"ushort":           true,
+	"XmlQualifiedName": true,
+}
+
+// csharpValueType defines the built-in value types of C#, which are declared
+// as nullable if the elements are optional.
+var csharpValueType = map[string]bool{
+	"bool":     true,
+	"byte":     true,
+	"DateTime": true,
+	"decimal":  true,
+	"double":   true,
+	"float":    true,
+	"int":      true,
+	"long":     true,
+	"sbyte":    true,
+	"short":    true,
+	"uint":     true,
+	"ulong":    true,
+	"ushort":   true,
+}
+
+// GenCSharp generate C# programming language source code for XML schema
+// definition files. The classes are annotated with the attributes in
+// System.Xml.Serialization for the XmlSerializer. The records with the
+// init-only properties and the nullable reference types are generated for the
+// target framework net5.0 or later, and the properties are annotated for
+// System.Text.Json as well.
+func (gen *CodeGenerator) GenCSharp() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("CSharp%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	usingNamespace := "using System;\nusing System.Collections.Generic;\nusing System.Xml;\nusing System.Xml.Serialization;\n"
+	if gen.isCSharpRecords() {
+		usingNamespace = "#nullable enable\n\nusing System;\nusing System.Collections.Generic;\nusing System.Text.Json.Serialization;\nusing System.Xml;\nusing System.Xml.Serialization;\n"
+	}
+	for _, mapping := range gen.getImportMappings() {
+		usingNamespace += fmt.Sprintf("using %s;\n", mapping.Import)
+	}
+	var field string
+	for _, line := range strings.Split(strings.TrimSpace(strings.Replace(gen.Field, "\r\n", "\n", -1)), "\n") {
+		if line != "" {
+			line = "\t" + line
+		}
+		field += line + "\n"
+	}
+	source := []byte(fmt.Sprintf("%s\n\n%s\nnamespace %s\n{\n%s}\n", copyright, usingNamespace, packageName, field))
+	return gen.writeFile(gen.File+".cs", source)
+}
+
+func genCSharpFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// isCSharpRecords returns if the records are generated for the target
+// framework, which supports C# 9 since net5.0.
+func (gen *CodeGenerator) isCSharpRecords() bool {
+	if !strings.HasPrefix(gen.TargetFramework, "net") {
+		return false
+	}
+	version, err := strconv.ParseFloat(strings.TrimPrefix(gen.TargetFramework, "net"), 64)
+	return err == nil && strings.Contains(gen.TargetFramework, ".") && version >= 5
+}
+
+// genCSharpKind returns the kind of the generated classes.
+func (gen *CodeGenerator) genCSharpKind() string {
+	if gen.isCSharpRecords() {
+		return "record"
+	}
+	return "class"
+}
+
+func (gen *CodeGenerator) genCSharpFieldType(name string, plural bool) (fieldType string) {
+	if _, ok := csharpBuildInType[name]; ok {
+		fieldType = name
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+	} else if fieldType = genCSharpFieldName(name); fieldType == "" {
+		fieldType = "object"
+	} else {
+		fieldType = gen.typeName(fieldType)
+	}
+	if plural {
+		fieldType = fmt.Sprintf("List<%s>", fieldType)
+	}
+	return
+}
+
+// genCSharpProperty generates the auto-implemented property of the class by
+// given attribute in System.Xml.Serialization, the property is renamed if it
+// has the same name as the enclosing class. The properties of the records are
+// init-only, and the non-nullable references are initialized.
+func (gen *CodeGenerator) genCSharpProperty(className, attribute, name, fieldType string) string {
+	propertyName := gen.fieldName(genCSharpFieldName(name))
+	if propertyName == className {
+		propertyName += "Value"
+	}
+	if attribute != "" {
+		attribute = fmt.Sprintf("\t[%s]\n", attribute)
+	}
+	if !gen.isCSharpRecords() {
+		return fmt.Sprintf("\n%s\tpublic %s %s { get; set; }\n", attribute, fieldType, propertyName)
+	}
+	var initializer string
+	switch {
+	case strings.HasSuffix(fieldType, "?") || csharpValueType[fieldType]:
+	case strings.HasPrefix(fieldType, "List<"):
+		initializer = " = new();"
+	default:
+		initializer = " = null!;"
+	}
+	return fmt.Sprintf("\n%s\tpublic %s %s { get; init; }%s\n", attribute, fieldType, propertyName, initializer)
+}
+
+// genCSharpJSONName returns the System.Text.Json attribute of the property
+// of the records by given XML name.
+func (gen *CodeGenerator) genCSharpJSONName(name string) string {
+	if !gen.isCSharpRecords() {
+		return ""
+	}
+	return fmt.Sprintf("JsonPropertyName(%q), ", name)
+}
+
+// genCSharpElement generates the property for the element, the optional
+// value types are declared as nullable, and so are the optional reference
+// types of the records.
+func (gen *CodeGenerator) genCSharpElement(className string, element Element) string {
+	fieldType := gen.genCSharpFieldType(gen.getBaseType(element.Type), element.Plural)
+	if element.Optional && !element.Plural && (csharpValueType[fieldType] || gen.isCSharpRecords()) {
+		fieldType += "?"
+	}
+	return gen.genCSharpProperty(className, fmt.Sprintf("%sXmlElement(%q)", gen.genCSharpJSONName(element.Name), element.Name), element.Name, fieldType)
+}
+
+// genCSharpAttribute generates the property for the attribute, the optional
+// reference types of the records are declared as nullable, the value types
+// are not since the XmlSerializer can't serialize the nullable attributes.
+func (gen *CodeGenerator) genCSharpAttribute(className string, attribute Attribute) string {
+	fieldType := gen.genCSharpFieldType(gen.getBaseType(attribute.Type), attribute.Plural)
+	if attribute.Optional && gen.isCSharpRecords() && !csharpValueType[fieldType] {
+		fieldType += "?"
+	}
+	return gen.genCSharpProperty(className, fmt.Sprintf("%sXmlAttribute(%q)", gen.genCSharpJSONName(attribute.Name), attribute.Name), attribute.Name, fieldType)
+}
+
+// genCSharpClass generates the class by given declaration, attributes and
+// members.
+func (gen *CodeGenerator) genCSharpClass(name, doc, source, location, deprecated, attribute, content string) {
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genCSharpFieldName(name))
+	gen.Field += fmt.Sprintf("%s[%s]\npublic partial %s %s\n{\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), attribute, gen.genCSharpKind(), fieldName, strings.TrimPrefix(content, "\n"))
+}
+
+// CSharpSimpleType generates code for simple type XML schema in C# language
+// syntax.
+func (gen *CodeGenerator) CSharpSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	if v.List {
+		content := gen.genCSharpProperty(fieldName, "XmlText", "Value", "string")
+		content += gen.genCSharpProperty(fieldName, "XmlIgnore", "Items", gen.genCSharpFieldType(gen.getBaseType(v.Base), true))
+		gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var content string
+		for _, member := range v.MemberTypes {
+			content += gen.genCSharpProperty(fieldName, "XmlIgnore", member.Name, gen.genCSharpFieldType(gen.getBaseType(member.Type), false))
+		}
+		content += gen.genCSharpProperty(fieldName, "XmlText", "Value", "string")
+		gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := gen.constantName(genCSharpFieldName(ConvertCase(enum, PascalCase)))
+			if member == "" || (member[0] >= '0' && member[0] <= '9') {
+				member = "Value" + member
+			}
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content = append(content, fmt.Sprintf("\t[XmlEnum(%q)]\n\t%s", enum, member))
+		}
+		gen.StructAST[v.Name] = strings.Join(content, ",\n")
+		gen.Field += fmt.Sprintf("%s[XmlType(%q)]\npublic enum %s\n{\n%s\n}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), v.Name, fieldName, gen.StructAST[v.Name])
+		return
+	}
+	content := gen.genCSharpProperty(fieldName, "XmlText", "Value", gen.genCSharpFieldType(gen.getBaseType(v.Base), false))
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+	return
+}
+
+// CSharpComplexType generates code for complex type XML schema in C# language
+// syntax.
+func (gen *CodeGenerator) CSharpComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	for _, attrGroup := range v.AttributeGroup {
+		content += gen.genCSharpProperty(fieldName, "", attrGroup.Name, gen.genCSharpFieldType(gen.getBaseType(attrGroup.Ref), false))
+	}
+	for _, attribute := range v.Attributes {
+		content += gen.genCSharpAttribute(fieldName, attribute)
+	}
+	for _, group := range v.Groups {
+		content += gen.genCSharpProperty(fieldName, "", group.Name, gen.genCSharpFieldType(gen.getBaseType(group.Ref), group.Plural))
+	}
+	for _, element := range v.Elements {
+		content += gen.genCSharpElement(fieldName, element)
+	}
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+	return
+}
+
+// CSharpGroup generates code for group XML schema in C# language syntax.
+func (gen *CodeGenerator) CSharpGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	for _, element := range v.Elements {
+		content += gen.genCSharpElement(fieldName, element)
+	}
+	for _, group := range v.Groups {
+		content += gen.genCSharpProperty(fieldName, "", group.Name, gen.genCSharpFieldType(gen.getBaseType(group.Ref), group.Plural))
+	}
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+	return
+}
+
+// CSharpAttributeGroup generates code for attribute group XML schema in C#
+// language syntax.
+func (gen *CodeGenerator) CSharpAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	for _, attribute := range v.Attributes {
+		content += gen.genCSharpAttribute(fieldName, attribute)
+	}
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+	return
+}
+
+// CSharpElement generates code for element XML schema in C# language syntax.
+// The element of complex type is generated as the root class derived from the
+// type, otherwise the value is the text of the root.
+func (gen *CodeGenerator) CSharpElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	fieldType := gen.genCSharpFieldType(gen.getBaseType(v.Type), v.Plural)
+	if _, ok := csharpBuildInType[fieldType]; !ok && !v.Plural && fieldType != fieldName {
+		gen.StructAST[v.Name] = fieldType
+		gen.Field += fmt.Sprintf("%s[XmlRoot(%q)]\npublic partial %s %s : %s\n{\n}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), v.Name, gen.genCSharpKind(), fieldName, fieldType)
+		return
+	}
+	attribute := "XmlText"
+	if v.Plural {
+		attribute = fmt.Sprintf("XmlElement(%q)", v.Name)
+	}
+	content := gen.genCSharpProperty(fieldName, attribute, "Value", fieldType)
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlRoot(%q)", v.Name), content)
+	return
+}
+
+// CSharpAttribute generates code for attribute XML schema in C# language
+// syntax.
+func (gen *CodeGenerator) CSharpAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genCSharpFieldName(v.Name))
+	content := gen.genCSharpProperty(fieldName, "XmlText", "Value", gen.genCSharpFieldType(gen.getBaseType(v.Type), v.Plural))
+	gen.genCSharpClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%q)", v.Name), content)
+	return
+}
@@ -0,0 +1,379 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var cueBuildInType = map[string]bool{
+	"[...string]": true,
+	"_":           true,
+	"bool":        true,
+	"int":         true,
+	"int & <=-1":  true,
+	"int & <=0":   true,
+	"int & >=1":   true,
+	"int16":       true,
+	"int32":       true,
+	"int64":       true,
+	"int8":        true,
+	"number":      true,
+	"string":      true,
+	"uint":        true,
+	"uint16":      true,
+	"uint32":      true,
+	"uint64":      true,
+	"uint8":       true,
+}
+
+// cueIdentifier matches the labels which can be written as the identifiers
+// in CUE, the labels start with the underscore or the hash are the hidden
+// fields or definitions, so they should be quoted.
+var cueIdentifier = regexp.MustCompile(`^[A-Za-z$][A-Za-z0-9_$]*$`)
+
+// cueKeywords defines the keywords of CUE, which are quoted in the labels.
+var cueKeywords = toSet("false", "for", "if", "import", "in", "let", "null", "package", "true")
+
+// GenCUE generate CUE definitions for XML schema definition files. The
+// declarations are generated as the definitions, the attributes and the
+// elements of the complex types are both generated as the fields of the
+// closed structs by their XML names, the optional fields are marked by the
+// question mark, and the facets of the restrictions are generated as the
+// constraints.
+func (gen *CodeGenerator) GenCUE() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("CUE%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	var imports []string
+	for name := range gen.ImportBuildIn {
+		imports = append(imports, name)
+	}
+	for _, mapping := range gen.getImportMappings() {
+		imports = append(imports, mapping.Import)
+	}
+	sort.Strings(imports)
+	var importPackage string
+	for i, name := range imports {
+		if name == "" || i > 0 && imports[i-1] == name {
+			continue
+		}
+		importPackage += fmt.Sprintf("import %q\n", name)
+	}
+	if importPackage != "" {
+		importPackage = "\n" + importPackage
+	}
+	source := []byte(fmt.Sprintf("%s\n\npackage %s\n%s%s", copyright, packageName, importPackage, strings.Replace(gen.Field, "\r\n", "\n", -1)))
+	return gen.writeFile(gen.File+".cue", source)
+}
+
+func genCUEFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// cueLabel returns the label of the field by given XML name, which is quoted
+// unless it's a valid identifier.
+func cueLabel(name string) string {
+	if cueIdentifier.MatchString(name) && !cueKeywords[name] {
+		return name
+	}
+	return graphqlQuote(name)
+}
+
+// genCUEType returns the expression of the type by given name, the
+// declarations are referred by the definitions.
+func (gen *CodeGenerator) genCUEType(name string) string {
+	name = trimNSPrefix(name)
+	if _, ok := cueBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	if fieldName := genCUEFieldName(name); fieldName != "" {
+		return "#" + gen.typeName(fieldName)
+	}
+	return "_"
+}
+
+// genCUEValue returns the literal of the lexical value by given type, the
+// values which aren't valid numbers or booleans are kept as the strings,
+// e.g. the special values INF and NaN of the floats.
+func (gen *CodeGenerator) genCUEValue(typeName, value string) string {
+	trimmed := strings.TrimSpace(value)
+	switch baseType := gen.getBaseType(typeName); {
+	case baseType == "number" || strings.HasPrefix(baseType, "int") || strings.HasPrefix(baseType, "uint"):
+		if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case baseType == "bool":
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			return strconv.FormatBool(b)
+		}
+	}
+	return graphqlQuote(value)
+}
+
+// genCUEFacets returns the expression of the type with the constraints of
+// the facets in the restriction, the enumerations are the disjunctions of the
+// values, and the lengths of the lists are constrained by the number of the
+// items.
+func (gen *CodeGenerator) genCUEFacets(expr, typeName string, restriction Restriction) string {
+	if len(restriction.Enum) > 0 {
+		var values []string
+		for _, value := range restriction.Enum {
+			values = append(values, gen.genCUEValue(typeName, value))
+		}
+		return strings.Join(values, " | ")
+	}
+	constraints := []string{expr}
+	if restriction.Pattern != nil {
+		// The patterns of XSD are implicitly anchored at both ends.
+		constraints = append(constraints, "=~"+graphqlQuote(fmt.Sprintf("^(?:%s)$", restriction.Pattern.String())))
+	}
+	lengthPackage, minLength, maxLength := "strings", "strings.MinRunes(%d)", "strings.MaxRunes(%d)"
+	if strings.HasPrefix(expr, "[") {
+		lengthPackage, minLength, maxLength = "list", "list.MinItems(%d)", "list.MaxItems(%d)"
+	}
+	if restriction.MinLength > 0 {
+		constraints = append(constraints, fmt.Sprintf(minLength, restriction.MinLength))
+	}
+	if restriction.MaxLength > 0 {
+		constraints = append(constraints, fmt.Sprintf(maxLength, restriction.MaxLength))
+	}
+	if restriction.MinLength > 0 || restriction.MaxLength > 0 {
+		if gen.ImportBuildIn == nil {
+			gen.ImportBuildIn = map[string]bool{}
+		}
+		gen.ImportBuildIn[lengthPackage] = true
+	}
+	if restriction.HasMin {
+		operator := ">="
+		if restriction.MinExclusive {
+			operator = ">"
+		}
+		constraints = append(constraints, operator+strconv.FormatFloat(restriction.Min, 'g', -1, 64))
+	}
+	if restriction.HasMax {
+		operator := "<="
+		if restriction.MaxExclusive {
+			operator = "<"
+		}
+		constraints = append(constraints, operator+strconv.FormatFloat(restriction.Max, 'g', -1, 64))
+	}
+	return strings.Join(constraints, " & ")
+}
+
+// genCUEComment generates the comment of the field by given documentation
+// and deprecation message.
+func (gen *CodeGenerator) genCUEComment(doc, deprecated string) (comment string) {
+	if doc = strings.TrimSpace(doc); doc != "" {
+		comment = "\t// " + strings.Replace(strings.Replace(doc, "\t", "", -1), "\n", "\n\t// ", -1) + "\n"
+	}
+	if deprecated != "" {
+		comment += "\t" + strings.Replace(strings.TrimPrefix(gen.genDeprecated(deprecated), "//\r\n"), "\r\n", "\n", -1)
+	}
+	return
+}
+
+// cueStruct holds the fields of the struct in generating.
+type cueStruct struct {
+	fields []string
+}
+
+// addField provides a function to add the field by given XML name and
+// expression of the type, the plural fields are the lists of the items, and
+// the default value is marked as the default of the disjunction.
+func (s *cueStruct) addField(gen *CodeGenerator, name, doc, expr, defaultValue, deprecated string, plural, optional bool) {
+	if plural && !strings.HasPrefix(expr, "[") {
+		expr = "[..." + expr + "]"
+	} else if defaultValue != "" {
+		expr = "*" + defaultValue + " | " + expr
+	}
+	label := cueLabel(name)
+	if optional {
+		label += "?"
+	}
+	s.fields = append(s.fields, fmt.Sprintf("%s\t%s: %s\n", gen.genCUEComment(doc, deprecated), label, expr))
+}
+
+// addElement adds the field of the element.
+func (s *cueStruct) addElement(gen *CodeGenerator, element Element) {
+	var defaultValue string
+	if element.Default != "" {
+		defaultValue = gen.genCUEValue(element.Type, element.Default)
+	}
+	s.addField(gen, element.Name, element.Doc, gen.genCUEFacets(gen.genCUEType(element.Type), element.Type, element.Restriction), defaultValue, element.Deprecated, element.Plural, element.Optional)
+}
+
+// addAttribute adds the field of the attribute.
+func (s *cueStruct) addAttribute(gen *CodeGenerator, attribute Attribute) {
+	var defaultValue string
+	if attribute.Default != "" {
+		defaultValue = gen.genCUEValue(attribute.Type, attribute.Default)
+	}
+	s.addField(gen, attribute.Name, attribute.Doc, gen.genCUEFacets(gen.genCUEType(attribute.Type), attribute.Type, attribute.Restriction), defaultValue, attribute.Deprecated, attribute.Plural, attribute.Optional)
+}
+
+// genCUEDefinition generates the definition by given declaration and
+// expression.
+func (gen *CodeGenerator) genCUEDefinition(name, doc, source, location, deprecated, expr string) {
+	gen.StructAST[name] = expr
+	fieldName := gen.typeName(genCUEFieldName(name))
+	gen.Field += fmt.Sprintf("%s#%s: %s\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, expr)
+}
+
+// genCUEStruct generates the definition of the struct by given declaration
+// and fields.
+func (gen *CodeGenerator) genCUEStruct(name, doc, source, location, deprecated string, s *cueStruct) {
+	if len(s.fields) == 0 {
+		gen.genCUEDefinition(name, doc, source, location, deprecated, "{}")
+		return
+	}
+	gen.genCUEDefinition(name, doc, source, location, deprecated, fmt.Sprintf("{\n%s}", strings.Join(s.fields, "")))
+}
+
+// CUESimpleType generates code for simple type XML schema in CUE. The lists
+// are generated as the lists of the items, and the unions are generated as
+// the disjunctions of the member types.
+func (gen *CodeGenerator) CUESimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var expr string
+	switch {
+	case v.List:
+		expr = gen.genCUEFacets(fmt.Sprintf("[...%s]", gen.genCUEType(v.Base)), v.Base, v.Restriction)
+	case v.Union && len(v.MemberTypes) > 0:
+		var members []string
+		for _, member := range v.MemberTypes {
+			if gen.getSimpleType(member.Name) != nil {
+				members = append(members, gen.genCUEType(member.Name))
+				continue
+			}
+			members = append(members, gen.genCUEType(gen.getBaseType(member.Type)))
+		}
+		expr = strings.Join(members, " | ")
+	case v.Union:
+		expr = "string"
+	default:
+		expr = gen.genCUEFacets(gen.genCUEType(v.Base), v.Base, v.Restriction)
+	}
+	gen.genCUEDefinition(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, expr)
+	return
+}
+
+// CUEComplexType generates code for complex type XML schema in CUE.
+func (gen *CodeGenerator) CUEComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &cueStruct{}
+	for _, attrGroup := range v.AttributeGroup {
+		s.addField(gen, attrGroup.Name, "", gen.genCUEType(attrGroup.Ref), "", "", false, false)
+	}
+	for _, attribute := range v.Attributes {
+		s.addAttribute(gen, attribute)
+	}
+	for _, group := range v.Groups {
+		s.addField(gen, group.Name, "", gen.genCUEType(group.Ref), "", "", group.Plural, false)
+	}
+	for _, element := range v.Elements {
+		s.addElement(gen, element)
+	}
+	gen.genCUEStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CUEGroup generates code for group XML schema in CUE.
+func (gen *CodeGenerator) CUEGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &cueStruct{}
+	for _, element := range v.Elements {
+		s.addElement(gen, element)
+	}
+	for _, group := range v.Groups {
+		s.addField(gen, group.Name, "", gen.genCUEType(group.Ref), "", "", group.Plural, false)
+	}
+	gen.genCUEStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CUEAttributeGroup generates code for attribute group XML schema in CUE.
+func (gen *CodeGenerator) CUEAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &cueStruct{}
+	for _, attribute := range v.Attributes {
+		s.addAttribute(gen, attribute)
+	}
+	gen.genCUEStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CUEElement generates code for element XML schema in CUE, the element is
+// generated as the definition of its type unless the type has the same name.
+func (gen *CodeGenerator) CUEElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if !v.Plural && trimNSPrefix(v.Type) == v.Name {
+		gen.StructAST[v.Name] = v.Type
+		return
+	}
+	expr := gen.genCUEFacets(gen.genCUEType(v.Type), v.Type, v.Restriction)
+	if v.Plural {
+		expr = "[..." + expr + "]"
+	}
+	gen.genCUEDefinition(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, expr)
+	return
+}
+
+// CUEAttribute generates code for attribute XML schema in CUE.
+func (gen *CodeGenerator) CUEAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	expr := gen.genCUEFacets(gen.genCUEType(v.Type), v.Type, v.Restriction)
+	if v.Plural && !strings.HasPrefix(expr, "[") {
+		expr = "[..." + expr + "]"
+	}
+	gen.genCUEDefinition(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, expr)
+	return
+}
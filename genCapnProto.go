@@ -0,0 +1,296 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var capnProtoBuildInType = map[string]bool{
+	"AnyPointer": true,
+	"Bool":       true,
+	"Data":       true,
+	"Float32":    true,
+	"Float64":    true,
+	"Int16":      true,
+	"Int32":      true,
+	"Int64":      true,
+	"Int8":       true,
+	"List(Text)": true,
+	"Text":       true,
+	"UInt16":     true,
+	"UInt32":     true,
+	"UInt64":     true,
+	"UInt8":      true,
+}
+
+// GenCapnProto generate Cap'n Proto schema for XML schema definition files.
+// The complex types, groups and attribute groups are generated as structs,
+// the enumerations are generated as enums, the unions are generated as the
+// structs with the unnamed union, and the other simple types are generated
+// as the aliases of their closest primitive types. The unique file ID is
+// derived from the name of the file, so it's stable between generations.
+func (gen *CodeGenerator) GenCapnProto() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("CapnProto%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var imports []string
+	for _, mapping := range gen.getImportMappings() {
+		if mapping.Import != "" {
+			imports = append(imports, fmt.Sprintf("using %s = import %q.%s;\n", mapping.Type, mapping.Import, mapping.Type))
+		}
+	}
+	sort.Strings(imports)
+	var importFile string
+	for i, line := range imports {
+		if i > 0 && imports[i-1] == line {
+			continue
+		}
+		importFile += line
+	}
+	if importFile != "" {
+		importFile = "\n" + importFile
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(gen.Package + "/" + filepath.Base(gen.File)))
+	source := []byte(fmt.Sprintf("# %s\n\n@0x%016x;\n%s%s", strings.TrimPrefix(copyright, "// "), hash.Sum64()|1<<63, importFile, gen.Field))
+	return gen.writeFile(gen.File+".capnp", source)
+}
+
+func genCapnProtoFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genCapnProtoMemberName returns the name of the field or enumerant by given
+// XML name, which must start with a lower case letter and can't contain the
+// underscores in Cap'n Proto.
+func genCapnProtoMemberName(name string) string {
+	member := ConvertCase(genCapnProtoFieldName(name), CamelCase)
+	if member == "" || (member[0] >= '0' && member[0] <= '9') {
+		member = "value" + member
+	}
+	return member
+}
+
+// genCapnProtoFieldType generates the type for Cap'n Proto by given type
+// name.
+func (gen *CodeGenerator) genCapnProtoFieldType(name string) string {
+	name = gen.getBaseType(name)
+	if _, ok := capnProtoBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genCapnProtoFieldName(name)
+	if fieldType == "" {
+		return "AnyPointer"
+	}
+	return gen.typeName(fieldType)
+}
+
+// capnProtoStruct holds the fields of the struct in generating, the fields
+// are numbered by the ordinals in the order of declaration.
+type capnProtoStruct struct {
+	fields []string
+	names  map[string]bool
+}
+
+// addField provides a function to add the field by given XML name and type,
+// the plural fields are the lists of the items. The ordinal is appended to
+// the name of the field which collides with another, e.g. the attribute and
+// element with the same name.
+func (s *capnProtoStruct) addField(gen *CodeGenerator, name, fieldType, deprecated string, plural bool) {
+	if plural {
+		fieldType = fmt.Sprintf("List(%s)", fieldType)
+	}
+	var comment string
+	if deprecated != "" {
+		comment = fmt.Sprintf("  # Deprecated: %s\n", strings.Replace(deprecated, "\n", " ", -1))
+	}
+	ordinal := len(s.fields)
+	fieldName := gen.fieldName(genCapnProtoMemberName(name))
+	if s.names == nil {
+		s.names = map[string]bool{}
+	}
+	if s.names[fieldName] {
+		fieldName = fmt.Sprintf("%s%d", fieldName, ordinal)
+	}
+	s.names[fieldName] = true
+	s.fields = append(s.fields, fmt.Sprintf("%s  %s @%d :%s;\n", comment, fieldName, ordinal, fieldType))
+}
+
+// genCapnProtoStruct generates the struct by given declaration and fields.
+func (gen *CodeGenerator) genCapnProtoStruct(name, doc, source, location, deprecated string, s *capnProtoStruct) {
+	content := strings.Join(s.fields, "")
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genCapnProtoFieldName(name))
+	gen.Field += fmt.Sprintf("%sstruct %s {\n%s}\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genCapnProtoAlias generates the alias by given declaration and type.
+func (gen *CodeGenerator) genCapnProtoAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genCapnProtoFieldName(name))
+	gen.Field += fmt.Sprintf("%susing %s = %s;\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// CapnProtoSimpleType generates code for simple type XML schema in Cap'n
+// Proto schema. The enumerants are in the camel case, and the XML values are
+// noted in the comments.
+func (gen *CodeGenerator) CapnProtoSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genCapnProtoAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("List(%s)", gen.genCapnProtoFieldType(v.Base)))
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		s := &capnProtoStruct{}
+		for _, member := range v.MemberTypes {
+			memberType := member.Type
+			if gen.getSimpleType(member.Name) != nil {
+				memberType = member.Name
+			}
+			s.addField(gen, member.Name, gen.genCapnProtoFieldType(memberType), "", false)
+		}
+		s.fields = []string{fmt.Sprintf("  union {\n  %s  }\n", strings.Join(s.fields, "  "))}
+		gen.genCapnProtoStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.genCapnProtoAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genCapnProtoFieldType(v.Base))
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := gen.constantName(genCapnProtoMemberName(ConvertCase(enum, PascalCase)))
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("  %s @%d; # %s\n", member, len(members)-1, strings.Replace(enum, "\n", " ", -1))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genCapnProtoFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%senum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// CapnProtoComplexType generates code for complex type XML schema in Cap'n
+// Proto schema.
+func (gen *CodeGenerator) CapnProtoComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &capnProtoStruct{}
+	for _, attrGroup := range v.AttributeGroup {
+		s.addField(gen, attrGroup.Name, gen.genCapnProtoFieldType(attrGroup.Ref), "", false)
+	}
+	for _, attribute := range v.Attributes {
+		s.addField(gen, attribute.Name, gen.genCapnProtoFieldType(attribute.Type), attribute.Deprecated, attribute.Plural)
+	}
+	for _, group := range v.Groups {
+		s.addField(gen, group.Name, gen.genCapnProtoFieldType(group.Ref), "", group.Plural)
+	}
+	for _, element := range v.Elements {
+		s.addField(gen, element.Name, gen.genCapnProtoFieldType(element.Type), element.Deprecated, element.Plural)
+	}
+	gen.genCapnProtoStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CapnProtoGroup generates code for group XML schema in Cap'n Proto schema.
+func (gen *CodeGenerator) CapnProtoGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &capnProtoStruct{}
+	for _, element := range v.Elements {
+		s.addField(gen, element.Name, gen.genCapnProtoFieldType(element.Type), element.Deprecated, element.Plural)
+	}
+	for _, group := range v.Groups {
+		s.addField(gen, group.Name, gen.genCapnProtoFieldType(group.Ref), "", group.Plural)
+	}
+	gen.genCapnProtoStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CapnProtoAttributeGroup generates code for attribute group XML schema in
+// Cap'n Proto schema.
+func (gen *CodeGenerator) CapnProtoAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	s := &capnProtoStruct{}
+	for _, attribute := range v.Attributes {
+		s.addField(gen, attribute.Name, gen.genCapnProtoFieldType(attribute.Type), attribute.Deprecated, attribute.Plural)
+	}
+	gen.genCapnProtoStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, s)
+	return
+}
+
+// CapnProtoElement generates code for element XML schema in Cap'n Proto
+// schema, the element is generated as the alias of its type unless the type
+// has the same name.
+func (gen *CodeGenerator) CapnProtoElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genCapnProtoFieldType(v.Type)
+	if v.Plural {
+		fieldType = fmt.Sprintf("List(%s)", fieldType)
+	}
+	if fieldType == gen.typeName(genCapnProtoFieldName(v.Name)) {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	gen.genCapnProtoAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
+
+// CapnProtoAttribute generates code for attribute XML schema in Cap'n Proto
+// schema, the attribute is generated as the alias of its type unless the
+// type has the same name.
+func (gen *CodeGenerator) CapnProtoAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genCapnProtoFieldType(v.Type)
+	if v.Plural {
+		fieldType = fmt.Sprintf("List(%s)", fieldType)
+	}
+	if fieldType == gen.typeName(genCapnProtoFieldName(v.Name)) {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	gen.genCapnProtoAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
@@ -0,0 +1,287 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var crystalBuildInType = map[string]bool{
+	"Array(String)": true,
+	"BigDecimal":    true,
+	"BigInt":        true,
+	"Bool":          true,
+	"Float32":       true,
+	"Float64":       true,
+	"Int16":         true,
+	"Int32":         true,
+	"Int64":         true,
+	"Int8":          true,
+	"String":        true,
+	"UInt16":        true,
+	"UInt32":        true,
+	"UInt64":        true,
+	"UInt8":         true,
+	"XML::Node":     true,
+}
+
+// GenCrystal generate Crystal programming language source code for XML schema
+// definition files. Like the Ruby output, the types are declared in a module,
+// the complex types are generated as structs including the XML::Serializable
+// module with the XML::Field annotations, and the simple types are generated
+// as aliases. The standard library of Crystal only provides the XML parser,
+// so the XML::Serializable module should be provided by a shard.
+func (gen *CodeGenerator) GenCrystal() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Crystal%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	requirePackage := "require \"xml\"\n"
+	if gen.ImportBuildIn["big"] {
+		requirePackage += "require \"big\"\n"
+	}
+	for _, mapping := range gen.getImportMappings() {
+		requirePackage += fmt.Sprintf("require %s\n", crystalQuote(mapping.Import))
+	}
+	module := "Schema"
+	if gen.Package != "" {
+		module = MakeFirstUpperCase(gen.Package)
+	}
+	source := []byte(fmt.Sprintf("# %s\n\n%s\nmodule %s%send\n", strings.TrimPrefix(copyright, "// "), requirePackage, module, gen.Field))
+	return gen.writeFile(gen.File+".cr", source)
+}
+
+func genCrystalFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genCrystalPropertyName generates the snake case property name for Crystal
+// code.
+func (gen *CodeGenerator) genCrystalPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genCrystalFieldName(name), SnakeCase))
+}
+
+// genCrystalFieldType generates the type for Crystal code, the requirement
+// of the big numbers is recorded.
+func (gen *CodeGenerator) genCrystalFieldType(name string) string {
+	if _, ok := crystalBuildInType[name]; ok {
+		if name == "BigInt" || name == "BigDecimal" {
+			if gen.ImportBuildIn == nil {
+				gen.ImportBuildIn = map[string]bool{}
+			}
+			gen.ImportBuildIn["big"] = true
+		}
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genCrystalFieldName(name)
+	if fieldType == "" {
+		return "XML::Node"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genCrystalProperty generates the annotated property of the struct by given
+// XML name of the element or attribute. The optional property is nilable and
+// the plural property defaults to an empty array.
+func (gen *CodeGenerator) genCrystalProperty(name, typeName string, attribute, plural, optional bool) string {
+	fieldType := gen.genCrystalFieldType(gen.getBaseType(typeName))
+	var isAttribute string
+	if attribute {
+		isAttribute = ", attribute: true"
+	}
+	property := fmt.Sprintf("    @[XML::Field(key: %s%s)]\n    property %s : ", crystalQuote(name), isAttribute, gen.genCrystalPropertyName(name))
+	switch {
+	case plural:
+		return property + fmt.Sprintf("Array(%s) = [] of %s\n", fieldType, fieldType)
+	case optional:
+		return property + fieldType + "?\n"
+	}
+	return property + fieldType + "\n"
+}
+
+// genCrystalInlineProperty generates the property for the group or attribute
+// group, which is inlined into the enclosing struct in the XML.
+func (gen *CodeGenerator) genCrystalInlineProperty(name, ref string, plural bool) string {
+	fieldType := gen.genCrystalFieldType(gen.getBaseType(ref))
+	if plural {
+		fieldType = fmt.Sprintf("Array(%s)", fieldType)
+	}
+	return fmt.Sprintf("    @[XML::Field(inline: true)]\n    property %s : %s\n", gen.genCrystalPropertyName(name), fieldType)
+}
+
+// genCrystalStruct generates the struct by given declaration and properties.
+func (gen *CodeGenerator) genCrystalStruct(name, doc, source, location, deprecated string, properties []string) {
+	content := "    include XML::Serializable\n"
+	if len(properties) > 0 {
+		content += "\n" + strings.Join(properties, "\n")
+	}
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genCrystalFieldName(name))
+	gen.Field += fmt.Sprintf("%s  struct %s\n%s  end\n", gen.genCrystalComment(fieldName, doc, source, location, deprecated), fieldName, content)
+}
+
+// genCrystalAlias generates the alias by given declaration and type.
+func (gen *CodeGenerator) genCrystalAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genCrystalFieldName(name))
+	gen.Field += fmt.Sprintf("%s  alias %s = %s\n", gen.genCrystalComment(fieldName, doc, source, location, deprecated), fieldName, fieldType)
+}
+
+// genCrystalComment generates the comment of the declaration indented in the
+// module.
+func (gen *CodeGenerator) genCrystalComment(name, doc, source, location, deprecated string) string {
+	return strings.Replace(genFieldComment(name, doc, source, location, "#")+gen.genDeprecated(deprecated), "\r\n#", "\r\n  #", -1)
+}
+
+// CrystalSimpleType generates code for simple type XML schema in Crystal
+// language syntax. The enumerations are generated as the modules of the
+// constants, the other simple types are generated as aliases.
+func (gen *CodeGenerator) CrystalSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genCrystalAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("Array(%s)", gen.genCrystalFieldType(gen.getBaseType(v.Base))))
+		return
+	}
+	if v.Union {
+		gen.genCrystalAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "String")
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.genCrystalAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genCrystalFieldType(gen.getBaseType(v.Base)))
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("    %s = %s\n", member, crystalQuote(enum))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genCrystalFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%s  module %s\n%s  end\n", gen.genCrystalComment(fieldName, v.Doc, v.Source, v.Location, v.Deprecated), fieldName, content)
+	return
+}
+
+// CrystalComplexType generates code for complex type XML schema in Crystal
+// language syntax.
+func (gen *CodeGenerator) CrystalComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, attrGroup := range v.AttributeGroup {
+		properties = append(properties, gen.genCrystalInlineProperty(attrGroup.Name, attrGroup.Ref, false))
+	}
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genCrystalProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genCrystalInlineProperty(group.Name, group.Ref, group.Plural))
+	}
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genCrystalProperty(element.Name, element.Type, false, element.Plural, element.Optional))
+	}
+	gen.genCrystalStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// CrystalGroup generates code for group XML schema in Crystal language
+// syntax.
+func (gen *CodeGenerator) CrystalGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genCrystalProperty(element.Name, element.Type, false, element.Plural, element.Optional))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genCrystalInlineProperty(group.Name, group.Ref, group.Plural))
+	}
+	gen.genCrystalStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// CrystalAttributeGroup generates code for attribute group XML schema in
+// Crystal language syntax.
+func (gen *CodeGenerator) CrystalAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genCrystalProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional))
+	}
+	gen.genCrystalStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// CrystalElement generates code for element XML schema in Crystal language
+// syntax.
+func (gen *CodeGenerator) CrystalElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genCrystalFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType = fmt.Sprintf("Array(%s)", fieldType)
+	}
+	if fieldType == gen.typeName(genCrystalFieldName(v.Name)) {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	gen.genCrystalAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
+
+// CrystalAttribute generates code for attribute XML schema in Crystal
+// language syntax.
+func (gen *CodeGenerator) CrystalAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genCrystalFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType = fmt.Sprintf("Array(%s)", fieldType)
+	}
+	if fieldType == gen.typeName(genCrystalFieldName(v.Name)) {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	gen.genCrystalAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
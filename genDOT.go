@@ -0,0 +1,363 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var dotBuildInType = map[string]bool{
+	"xml:base":              true,
+	"xml:id":                true,
+	"xml:lang":              true,
+	"xml:space":             true,
+	"xs:ENTITIES":           true,
+	"xs:ENTITY":             true,
+	"xs:ID":                 true,
+	"xs:IDREF":              true,
+	"xs:IDREFS":             true,
+	"xs:NCName":             true,
+	"xs:NMTOKEN":            true,
+	"xs:NMTOKENS":           true,
+	"xs:NOTATION":           true,
+	"xs:Name":               true,
+	"xs:QName":              true,
+	"xs:anyType":            true,
+	"xs:anyURI":             true,
+	"xs:base64Binary":       true,
+	"xs:boolean":            true,
+	"xs:byte":               true,
+	"xs:date":               true,
+	"xs:dateTime":           true,
+	"xs:dateTimeStamp":      true,
+	"xs:dayTimeDuration":    true,
+	"xs:decimal":            true,
+	"xs:double":             true,
+	"xs:duration":           true,
+	"xs:float":              true,
+	"xs:gDay":               true,
+	"xs:gMonth":             true,
+	"xs:gMonthDay":          true,
+	"xs:gYear":              true,
+	"xs:gYearMonth":         true,
+	"xs:hexBinary":          true,
+	"xs:int":                true,
+	"xs:integer":            true,
+	"xs:language":           true,
+	"xs:long":               true,
+	"xs:negativeInteger":    true,
+	"xs:nonNegativeInteger": true,
+	"xs:nonPositiveInteger": true,
+	"xs:normalizedString":   true,
+	"xs:positiveInteger":    true,
+	"xs:short":              true,
+	"xs:string":             true,
+	"xs:time":               true,
+	"xs:token":              true,
+	"xs:unsignedByte":       true,
+	"xs:unsignedInt":        true,
+	"xs:unsignedLong":       true,
+	"xs:unsignedShort":      true,
+	"xs:yearMonthDuration":  true,
+}
+
+// dotShapes defines the attributes of the nodes by the kinds of the
+// declarations.
+var dotShapes = map[string]string{
+	"SimpleType":     "shape=ellipse",
+	"ComplexType":    "shape=box",
+	"Group":          "shape=box, style=rounded",
+	"AttributeGroup": "shape=box, style=rounded",
+	"Element":        "shape=plaintext",
+	"Attribute":      "shape=plaintext",
+	"Placeholder":    "shape=box, style=dashed",
+}
+
+// dotNode is the declaration in the graph with the references from it.
+type dotNode struct {
+	kind  string
+	name  string
+	edges []dotEdge
+}
+
+// dotEdge is the reference to the type, group or attribute group by the
+// field with the label.
+type dotEdge struct {
+	target string
+	label  string
+	plural bool
+}
+
+// GenDOT generate the GraphViz DOT graph of the references between the
+// declarations for XML schema definition files. The declarations are the
+// nodes labeled by the number of the references to them, and the fields
+// referring the declared types, groups and attribute groups are the edges.
+// The edges in the cycles of the references are highlighted in red.
+func (gen *CodeGenerator) GenDOT() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("DOT%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var ids []string
+	references := map[string]int{}
+	graph := map[string][]string{}
+	for id, node := range gen.Schemas {
+		ids = append(ids, id)
+		for _, edge := range node.(*dotNode).edges {
+			references[edge.target]++
+			graph[id] = append(graph[id], edge.target)
+		}
+	}
+	sort.Strings(ids)
+	cycles := genDOTCycles(ids, graph)
+	var nodes, edges string
+	for _, id := range ids {
+		node := gen.Schemas[id].(*dotNode)
+		label := node.name
+		switch {
+		case references[id] == 1:
+			label += "\n(1 ref)"
+		case references[id] > 1:
+			label += fmt.Sprintf("\n(%d refs)", references[id])
+		}
+		nodes += fmt.Sprintf("  %s [label=%s, %s];\n", dotQuote(id), dotQuote(label), dotShapes[node.kind])
+		for _, edge := range node.edges {
+			var attributes []string
+			if edge.label != "" {
+				label := edge.label
+				if edge.plural {
+					label += "*"
+				}
+				attributes = append(attributes, "label="+dotQuote(label))
+			}
+			if cycles[id] != 0 && cycles[id] == cycles[edge.target] {
+				attributes = append(attributes, "color=red")
+			}
+			var attribute string
+			if len(attributes) > 0 {
+				attribute = " [" + strings.Join(attributes, ", ") + "]"
+			}
+			edges += fmt.Sprintf("  %s -> %s%s;\n", dotQuote(id), dotQuote(edge.target), attribute)
+		}
+	}
+	source := []byte(fmt.Sprintf("%s\n\ndigraph %s {\n  rankdir=LR;\n  node [fontname=\"Helvetica\"];\n  edge [fontname=\"Helvetica\", fontsize=10];\n\n%s\n%s}\n", copyright, dotQuote(filepath.Base(gen.File)), nodes, edges))
+	return gen.writeFile(gen.File+".dot", source)
+}
+
+func genDOTFieldName(name string) string {
+	return trimNSPrefix(name)
+}
+
+// dotQuote returns the quoted ID of DOT for the given string.
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// genDOTCycles returns the index of the strongly connected components
+// containing a cycle for each node in the graph by Tarjan's algorithm, the
+// nodes which aren't in any cycle are absent.
+func genDOTCycles(ids []string, graph map[string][]string) map[string]int {
+	index, lowLink, onStack := map[string]int{}, map[string]int{}, map[string]bool{}
+	cycles := map[string]int{}
+	var stack []string
+	var components int
+	var strongConnect func(id string)
+	strongConnect = func(id string) {
+		index[id] = len(index) + 1
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		var selfLoop bool
+		for _, target := range graph[id] {
+			if target == id {
+				selfLoop = true
+			}
+			if _, ok := index[target]; !ok {
+				strongConnect(target)
+				if lowLink[target] < lowLink[id] {
+					lowLink[id] = lowLink[target]
+				}
+			} else if onStack[target] && index[target] < lowLink[id] {
+				lowLink[id] = index[target]
+			}
+		}
+		if lowLink[id] != index[id] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			components++
+			for _, member := range component {
+				cycles[member] = components
+			}
+		}
+	}
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			strongConnect(id)
+		}
+	}
+	return cycles
+}
+
+// genDOTTarget returns the ID of the node by given type name, the built-in
+// and mapped types aren't the nodes, and the undeclared types are referred
+// by the placeholders.
+func (gen *CodeGenerator) genDOTTarget(name string) string {
+	if name == "" || dotBuildInType[name] {
+		return ""
+	}
+	name = trimNSPrefix(name)
+	if _, ok := gen.TypeMapping[name]; ok {
+		return ""
+	}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name {
+				return "SimpleType:" + name
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return "ComplexType:" + name
+			}
+		}
+	}
+	return "Placeholder:" + name
+}
+
+// addDOTEdge adds the edge to the target if it's a node.
+func addDOTEdge(node *dotNode, target, label string, plural bool) {
+	if target != "" {
+		node.edges = append(node.edges, dotEdge{target: target, label: label, plural: plural})
+	}
+}
+
+// genDOTNode records the node by given kind and name of the declaration.
+func (gen *CodeGenerator) genDOTNode(kind, name string, node *dotNode) {
+	node.kind, node.name = kind, name
+	gen.StructAST[name] = kind
+	if gen.Schemas == nil {
+		gen.Schemas = map[string]interface{}{}
+	}
+	gen.Schemas[kind+":"+name] = node
+}
+
+// DOTSimpleType generates the node for simple type XML schema in DOT, the
+// base type, the item type of the list and the member types of the union are
+// referred.
+func (gen *CodeGenerator) DOTSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	switch {
+	case v.Union:
+		for _, member := range v.MemberTypes {
+			if gen.getSimpleType(member.Name) != nil {
+				addDOTEdge(node, gen.genDOTTarget(member.Name), "", false)
+			}
+		}
+	default:
+		addDOTEdge(node, gen.genDOTTarget(v.Base), "", v.List)
+	}
+	gen.genDOTNode("SimpleType", v.Name, node)
+	return
+}
+
+// DOTComplexType generates the node for complex type XML schema in DOT.
+func (gen *CodeGenerator) DOTComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	for _, attrGroup := range v.AttributeGroup {
+		addDOTEdge(node, "AttributeGroup:"+trimNSPrefix(attrGroup.Ref), "", false)
+	}
+	for _, attribute := range v.Attributes {
+		addDOTEdge(node, gen.genDOTTarget(attribute.Type), "@"+attribute.Name, attribute.Plural)
+	}
+	for _, group := range v.Groups {
+		addDOTEdge(node, "Group:"+trimNSPrefix(group.Ref), "", group.Plural)
+	}
+	for _, element := range v.Elements {
+		addDOTEdge(node, gen.genDOTTarget(element.Type), element.Name, element.Plural)
+	}
+	gen.genDOTNode("ComplexType", v.Name, node)
+	return
+}
+
+// DOTGroup generates the node for group XML schema in DOT.
+func (gen *CodeGenerator) DOTGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	for _, element := range v.Elements {
+		addDOTEdge(node, gen.genDOTTarget(element.Type), element.Name, element.Plural)
+	}
+	for _, group := range v.Groups {
+		addDOTEdge(node, "Group:"+trimNSPrefix(group.Ref), "", group.Plural)
+	}
+	gen.genDOTNode("Group", v.Name, node)
+	return
+}
+
+// DOTAttributeGroup generates the node for attribute group XML schema in
+// DOT.
+func (gen *CodeGenerator) DOTAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	for _, attribute := range v.Attributes {
+		addDOTEdge(node, gen.genDOTTarget(attribute.Type), "@"+attribute.Name, attribute.Plural)
+	}
+	gen.genDOTNode("AttributeGroup", v.Name, node)
+	return
+}
+
+// DOTElement generates the node for element XML schema in DOT.
+func (gen *CodeGenerator) DOTElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	addDOTEdge(node, gen.genDOTTarget(v.Type), "", v.Plural)
+	gen.genDOTNode("Element", v.Name, node)
+	return
+}
+
+// DOTAttribute generates the node for attribute XML schema in DOT.
+func (gen *CodeGenerator) DOTAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	node := &dotNode{}
+	addDOTEdge(node, gen.genDOTTarget(v.Type), "", v.Plural)
+	gen.genDOTNode("Attribute", v.Name, node)
+	return
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var dartBuildInType = map[string]bool{
+	"BigInt":       true,
+	"bool":         true,
+	"DateTime":     true,
+	"double":       true,
+	"int":          true,
+	"List<String>": true,
+	"String":       true,
+}
+
+// dartValue holds the Dart type of the value, the parse expression which
+// converts the text in XML to the value and the format expression which
+// converts the value to the text, "%s" in the expressions is replaced with the
+// operand. The value of the class type is parsed from and built into the XML
+// element.
+type dartValue struct {
+	fieldType, parse, format string
+	class                    bool
+}
+
+// dartBuildInValue defines the values of the built-in types of Dart.
+var dartBuildInValue = map[string]dartValue{
+	"BigInt":       {fieldType: "BigInt", parse: "BigInt.parse(%s)", format: "%s"},
+	"bool":         {fieldType: "bool", parse: "_parseBool(%s)", format: "%s"},
+	"DateTime":     {fieldType: "DateTime", parse: "DateTime.parse(%s)", format: "%s.toIso8601String()"},
+	"double":       {fieldType: "double", parse: "double.parse(%s)", format: "%s"},
+	"int":          {fieldType: "int", parse: "int.parse(%s)", format: "%s"},
+	"List<String>": {fieldType: "List<String>", parse: "_parseList(%s, (value) => value)", format: "%s.join(' ')"},
+	"String":       {fieldType: "String", parse: "%s", format: "%s"},
+}
+
+// dartHelpers defines the private functions used by the generated code to
+// parse the text in XML.
+const dartHelpers = `T? _parse<S, T>(S? value, T Function(S value) parse) =>
+    value == null ? null : parse(value);
+
+bool _parseBool(String value) => value == 'true' || value == '1';
+
+List<T> _parseList<T>(String value, T Function(String value) parse) =>
+    value.trim().isEmpty ? <T>[] : value.trim().split(RegExp(r'\s+')).map(parse).toList();
+`
+
+// dartEnumMembers defines the members of the enhanced enumerations in Dart,
+// which can't be used as the names of the enumeration values.
+var dartEnumMembers = toSet("index", "name", "value", "values")
+
+// dartProperty holds the final field of the generated Dart class with the
+// expression in the factory constructor to parse it from XML and the
+// statement to build it into XML.
+type dartProperty struct {
+	name, fieldType, fromXML, toXML string
+	nullable, plural                bool
+}
+
+// GenDart generate Dart programming language source code for XML schema
+// definition files. The types are generated as classes with the fromXml
+// factory constructor and the toXml method built on package:xml.
+func (gen *CodeGenerator) GenDart() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Dart%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "import 'package:xml/xml.dart';\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import %s;\n", singleQuote(mapping.Import))
+	}
+	source := []byte(fmt.Sprintf("%s\n\n%s\n%s%s", copyright, importPackage, dartHelpers, gen.Field))
+	return gen.writeFile(gen.File+".dart", source)
+}
+
+func genDartFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genDartPropertyName generates the camel case field name for Dart code.
+func (gen *CodeGenerator) genDartPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genDartFieldName(name), CamelCase))
+}
+
+// genDartValue generates the value for Dart code by given type. The list
+// simple types are parsed from the whitespace-separated items, and the union
+// simple types are kept as the text.
+func (gen *CodeGenerator) genDartValue(name string) dartValue {
+	if value, ok := dartBuildInValue[name]; ok {
+		return value
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return dartValue{fieldType: mappedType, parse: mappedType + ".parse(%s)", format: "%s", class: true}
+	}
+	fieldType := genDartFieldName(name)
+	if fieldType == "" {
+		return dartBuildInValue["String"]
+	}
+	fieldType = gen.typeName(fieldType)
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		item := gen.genDartValue(gen.getBaseType(v.Base))
+		return dartValue{fieldType: fieldType, parse: fmt.Sprintf("_parseList(%%s, %s)", item.parser()), format: "%s.join(' ')"}
+	} else if v != nil && v.Union {
+		return dartValue{fieldType: fieldType, parse: "%s", format: "%s"}
+	}
+	return dartValue{fieldType: fieldType, parse: fieldType + ".parse(%s)", format: "%s", class: true}
+}
+
+// parser returns the function which parses the text in XML to the value.
+func (value dartValue) parser() string {
+	if value.class {
+		return value.fieldType + ".fromXml"
+	}
+	if fn := strings.TrimSuffix(value.parse, "(%s)"); fn != value.parse && !strings.ContainsAny(fn, "(%") {
+		return fn
+	}
+	return "(value) => " + fmt.Sprintf(value.parse, "value")
+}
+
+// plural returns the value of the whitespace-separated list of the value.
+func (value dartValue) plural() dartValue {
+	return dartValue{fieldType: fmt.Sprintf("List<%s>", value.fieldType), parse: fmt.Sprintf("_parseList(%%s, %s)", value.parser()), format: "%s.join(' ')"}
+}
+
+// genDartElement generates the field for the element.
+func (gen *CodeGenerator) genDartElement(element Element) dartProperty {
+	value := gen.genDartValue(gen.getBaseType(element.Type))
+	property := dartProperty{name: gen.genDartPropertyName(element.Name), fieldType: value.fieldType}
+	name := singleQuote(element.Name)
+	field := "this." + property.name
+	nest := func(operand string) string {
+		if value.class {
+			return fmt.Sprintf("() => %s.buildXml(builder)", operand)
+		}
+		return fmt.Sprintf(value.format, operand)
+	}
+	switch {
+	case element.Plural:
+		property.plural = true
+		property.fieldType = fmt.Sprintf("List<%s>", value.fieldType)
+		property.fromXML = fmt.Sprintf("element.findElements(%s).map((e) => %s).toList()", name, fmt.Sprintf(value.parse, "e.innerText"))
+		if value.class {
+			property.fromXML = fmt.Sprintf("element.findElements(%s).map(%s).toList()", name, value.parser())
+		}
+		property.toXML = fmt.Sprintf("for (final value in %s) {\n      builder.element(%s, nest: %s);\n    }", field, name, nest("value"))
+	case element.Optional:
+		property.nullable = true
+		property.fromXML = fmt.Sprintf("_parse(element.getElement(%s)?.innerText, %s)", name, value.parser())
+		if value.class {
+			property.fromXML = fmt.Sprintf("_parse(element.getElement(%s), %s)", name, value.parser())
+		}
+		property.toXML = fmt.Sprintf("if (%s case final value?) {\n      builder.element(%s, nest: %s);\n    }", field, name, nest("value"))
+	default:
+		property.fromXML = fmt.Sprintf(value.parse, fmt.Sprintf("element.getElement(%s)!.innerText", name))
+		if value.class {
+			property.fromXML = fmt.Sprintf("%s(element.getElement(%s)!)", value.parser(), name)
+		}
+		property.toXML = fmt.Sprintf("builder.element(%s, nest: %s);", name, nest(field))
+	}
+	return property
+}
+
+// genDartAttribute generates the field for the attribute.
+func (gen *CodeGenerator) genDartAttribute(attribute Attribute) dartProperty {
+	value := gen.genDartValue(gen.getBaseType(attribute.Type))
+	value.class = false
+	if attribute.Plural {
+		value = value.plural()
+	}
+	property := dartProperty{name: gen.genDartPropertyName(attribute.Name), fieldType: value.fieldType}
+	name := singleQuote(attribute.Name)
+	field := "this." + property.name
+	if attribute.Optional {
+		property.nullable = true
+		property.fromXML = fmt.Sprintf("_parse(element.getAttribute(%s), %s)", name, value.parser())
+		property.toXML = fmt.Sprintf("if (%s case final value?) {\n      builder.attribute(%s, %s);\n    }", field, name, fmt.Sprintf(value.format, "value"))
+		return property
+	}
+	property.fromXML = fmt.Sprintf(value.parse, fmt.Sprintf("element.getAttribute(%s)!", name))
+	property.toXML = fmt.Sprintf("builder.attribute(%s, %s);", name, fmt.Sprintf(value.format, field))
+	return property
+}
+
+// genDartInline generates the field for the group or attribute group, which
+// is parsed from and built into the enclosing element.
+func (gen *CodeGenerator) genDartInline(name, ref string) dartProperty {
+	value := gen.genDartValue(gen.getBaseType(ref))
+	property := dartProperty{name: gen.genDartPropertyName(name), fieldType: value.fieldType}
+	property.fromXML = fmt.Sprintf("%s(element)", value.parser())
+	property.toXML = fmt.Sprintf("this.%s.buildXml(builder);", property.name)
+	return property
+}
+
+// genDartClass generates the class with the constructor, the fromXml factory
+// constructor, the final fields, and the buildXml and toXml methods by given
+// declaration and properties.
+func (gen *CodeGenerator) genDartClass(name, doc, source, location, deprecated string, properties []dartProperty) {
+	fieldName := gen.typeName(genDartFieldName(name))
+	var parameters, arguments, fields, statements string
+	for _, property := range properties {
+		switch {
+		case property.plural:
+			parameters += fmt.Sprintf("    this.%s = const [],\n", property.name)
+		case property.nullable:
+			parameters += fmt.Sprintf("    this.%s,\n", property.name)
+		default:
+			parameters += fmt.Sprintf("    required this.%s,\n", property.name)
+		}
+		arguments += fmt.Sprintf("        %s: %s,\n", property.name, property.fromXML)
+		fieldType := property.fieldType
+		if property.nullable {
+			fieldType += "?"
+		}
+		fields += fmt.Sprintf("  final %s %s;\n", fieldType, property.name)
+		statements += fmt.Sprintf("    %s\n", property.toXML)
+	}
+	var content string
+	if len(properties) == 0 {
+		content = fmt.Sprintf("  %[1]s();\n\n  factory %[1]s.fromXml(XmlElement element) => %[1]s();\n\n  void buildXml(XmlBuilder builder) {}\n", fieldName)
+	} else {
+		content = fmt.Sprintf("  %[1]s({\n%[2]s  });\n\n  factory %[1]s.fromXml(XmlElement element) => %[1]s(\n%[3]s      );\n\n%[4]s\n  void buildXml(XmlBuilder builder) {\n%[5]s  }\n", fieldName, parameters, arguments, fields, statements)
+	}
+	content += "\n  XmlDocument toXml(String name) {\n    final builder = XmlBuilder();\n    builder.element(name, nest: () => buildXml(builder));\n    return builder.buildDocument();\n  }\n"
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%sclass %s {\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genDartAlias generates the type alias by given declaration and type.
+func (gen *CodeGenerator) genDartAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genDartFieldName(name))
+	gen.Field += fmt.Sprintf("%stypedef %s = %s;\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// DartSimpleType generates code for simple type XML schema in Dart language
+// syntax.
+func (gen *CodeGenerator) DartSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genDartAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("List<%s>", gen.genDartValue(gen.getBaseType(v.Base)).fieldType))
+		return
+	}
+	if v.Union {
+		gen.genDartAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "String")
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := ConvertCase(enum, CamelCase)
+			if member == "" || (member[0] >= '0' && member[0] <= '9') || dartEnumMembers[member] {
+				member = "value" + ConvertCase(enum, PascalCase)
+			}
+			member = gen.constantName(member)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content = append(content, fmt.Sprintf("  %s(%s)", member, singleQuote(enum)))
+		}
+		gen.StructAST[v.Name] = strings.Join(content, ",\n")
+		fieldName := gen.typeName(genDartFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%senum %[2]s {\n%[3]s;\n\n  const %[2]s(this.value);\n\n  final String value;\n\n  static %[2]s fromXml(String value) =>\n      values.firstWhere((e) => e.value == value);\n}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		return
+	}
+	gen.genDartAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genDartValue(gen.getBaseType(v.Base)).fieldType)
+	return
+}
+
+// DartComplexType generates code for complex type XML schema in Dart language
+// syntax.
+func (gen *CodeGenerator) DartComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []dartProperty
+	for _, attrGroup := range v.AttributeGroup {
+		properties = append(properties, gen.genDartInline(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genDartAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genDartInline(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genDartElement(element))
+	}
+	gen.genDartClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// DartGroup generates code for group XML schema in Dart language syntax.
+func (gen *CodeGenerator) DartGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []dartProperty
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genDartElement(element))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genDartInline(group.Name, group.Ref))
+	}
+	gen.genDartClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// DartAttributeGroup generates code for attribute group XML schema in Dart
+// language syntax.
+func (gen *CodeGenerator) DartAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []dartProperty
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genDartAttribute(attribute))
+	}
+	gen.genDartClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// DartElement generates code for element XML schema in Dart language syntax.
+func (gen *CodeGenerator) DartElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldType := gen.genDartValue(gen.getBaseType(v.Type)).fieldType
+		if v.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		gen.genDartAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	}
+	return
+}
+
+// DartAttribute generates code for attribute XML schema in Dart language
+// syntax.
+func (gen *CodeGenerator) DartAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldType := gen.genDartValue(gen.getBaseType(v.Type)).fieldType
+		if v.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		gen.genDartAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	}
+	return
+}
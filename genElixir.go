@@ -0,0 +1,381 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var elixirBuildInType = map[string]bool{
+	"[String.t()]":      true,
+	"boolean()":         true,
+	"Date.t()":          true,
+	"Decimal.t()":       true,
+	"float()":           true,
+	"integer()":         true,
+	"NaiveDateTime.t()": true,
+	"String.t()":        true,
+	"term()":            true,
+	"Time.t()":          true,
+}
+
+// elixirBuildInParser defines the functions which parse the text in XML to
+// the built-in types of Elixir, {namespace} in the functions is replaced with
+// the namespace of the generated modules. The text is kept as is for the types
+// which aren't listed.
+var elixirBuildInParser = map[string]string{
+	"[String.t()]":      "&String.split/1",
+	"boolean()":         "&{namespace}.Xgen.to_boolean/1",
+	"Date.t()":          "&Date.from_iso8601!/1",
+	"Decimal.t()":       "&Decimal.new/1",
+	"float()":           "&{namespace}.Xgen.to_float/1",
+	"integer()":         "&String.to_integer/1",
+	"NaiveDateTime.t()": "&NaiveDateTime.from_iso8601!/1",
+	"Time.t()":          "&Time.from_iso8601!/1",
+}
+
+// elixirHelpers defines the module of the functions used by the generated
+// code to parse the text in XML.
+const elixirHelpers = `defmodule Xgen do
+  @moduledoc false
+
+  def optional(nil, _parse), do: nil
+  def optional(value, parse), do: parse.(value)
+
+  def to_boolean(value), do: value in ["true", "1"]
+
+  def to_float(value) do
+    {float, ""} = Float.parse(value)
+    float
+  end
+
+  def to_list(value, parse), do: value |> String.split() |> Enum.map(parse)
+end
+`
+
+// elixirValue holds the type specification of the value and the function
+// which parses it. The value of the simple type is parsed from the text, and
+// the others are parsed from the XML node.
+type elixirValue struct {
+	spec, parser string
+	simple       bool
+}
+
+// GenElixir generate Elixir programming language source code for XML schema
+// definition files. The types are generated as the modules of structs with
+// the parse function based on SweetXml, which are nested in the module named
+// by the package.
+func (gen *CodeGenerator) GenElixir() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Elixir%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var content string
+	for _, line := range strings.Split(strings.Replace(elixirHelpers+gen.Field, "\r\n", "\n", -1), "\n") {
+		if line != "" {
+			line = "  " + line
+		}
+		content += line + "\n"
+	}
+	source := []byte(fmt.Sprintf("# %s\n\ndefmodule %s do\n  @moduledoc false\n\n%send\n", strings.TrimPrefix(copyright, "// "), gen.elixirNamespace(), strings.TrimSuffix(content, "\n")))
+	return gen.writeFile(gen.File+".ex", source)
+}
+
+// elixirNamespace returns the namespace module of the generated Elixir code.
+func (gen *CodeGenerator) elixirNamespace() string {
+	if gen.Package == "" {
+		return "Schema"
+	}
+	return genElixirFieldName(gen.Package)
+}
+
+// elixirQuote returns the Elixir string literal of the given value, the
+// interpolations in the value are escaped.
+func elixirQuote(value string) string {
+	return strings.Replace(strconv.Quote(value), "#{", `\#{`, -1)
+}
+
+func genElixirFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genElixirKeyName generates the snake case key of the struct for Elixir
+// code.
+func (gen *CodeGenerator) genElixirKeyName(name string) string {
+	return gen.fieldName(ToSnakeCase(genElixirFieldName(name)))
+}
+
+// genElixirValue generates the value for Elixir code by given type. The list
+// and union simple types are parsed by the parse function of their modules.
+func (gen *CodeGenerator) genElixirValue(name string) elixirValue {
+	if _, ok := elixirBuildInType[name]; ok {
+		return elixirValue{spec: name, parser: strings.Replace(elixirBuildInParser[name], "{namespace}", gen.elixirNamespace(), -1), simple: true}
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return elixirValue{spec: mappedType + ".t()", parser: fmt.Sprintf("&%s.parse/1", mappedType)}
+	}
+	fieldType := genElixirFieldName(name)
+	if fieldType == "" {
+		return elixirValue{spec: "String.t()", simple: true}
+	}
+	module := gen.elixirNamespace() + "." + gen.typeName(fieldType)
+	return elixirValue{spec: module + ".t()", parser: fmt.Sprintf("&%s.parse/1", module), simple: gen.getSimpleType(name) != nil}
+}
+
+// genElixirField generates the key of the struct with the type specification
+// and the expression which parses it from the node by given XPath.
+func (gen *CodeGenerator) genElixirField(name, path string, value elixirValue, plural, optional bool) (key, spec, expr string) {
+	key, spec = gen.genElixirKeyName(name), value.spec
+	modifiers := "s"
+	if !value.simple {
+		modifiers = "e"
+	}
+	expr = fmt.Sprintf("node |> xpath(~x\"%s\"", path)
+	switch {
+	case plural:
+		spec = fmt.Sprintf("[%s]", spec)
+		expr += modifiers + "l)"
+		if value.parser != "" {
+			expr += fmt.Sprintf(" |> Enum.map(%s)", value.parser)
+		}
+	case optional:
+		spec += " | nil"
+		expr += modifiers + "o)"
+		if value.parser != "" {
+			expr += fmt.Sprintf(" |> %s.Xgen.optional(%s)", gen.elixirNamespace(), value.parser)
+		}
+	default:
+		expr += modifiers + ")"
+		if value.parser != "" {
+			expr += fmt.Sprintf(" |> then(%s)", value.parser)
+		}
+	}
+	return
+}
+
+// genElixirStruct generates the module of the struct with the type
+// specification and the parse function by given declaration and fields.
+func (gen *CodeGenerator) genElixirStruct(name, doc, source, location, deprecated string, keys, specs, exprs []string) {
+	fieldName := gen.typeName(genElixirFieldName(name))
+	var content string
+	if len(keys) == 0 {
+		content = "  defstruct []\n\n  @type t :: %__MODULE__{}\n\n  @spec parse(term()) :: t()\n  def parse(_node), do: %__MODULE__{}\n"
+	} else {
+		var fields, types, values []string
+		for i, key := range keys {
+			fields = append(fields, fmt.Sprintf("    %s: nil", key))
+			if strings.HasPrefix(specs[i], "[") {
+				fields[i] = fmt.Sprintf("    %s: []", key)
+			}
+			types = append(types, fmt.Sprintf("          %s: %s", key, specs[i]))
+			values = append(values, fmt.Sprintf("      %s: %s", key, exprs[i]))
+		}
+		content = fmt.Sprintf("  import SweetXml\n\n  defstruct [\n%s\n  ]\n\n  @type t :: %%__MODULE__{\n%s\n        }\n\n  @spec parse(term()) :: t()\n  def parse(node) do\n    %%__MODULE__{\n%s\n    }\n  end\n", strings.Join(fields, ",\n"), strings.Join(types, ",\n"), strings.Join(values, ",\n"))
+	}
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%sdefmodule %s do\n%send\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genElixirModule generates the module with the type specification and the
+// parse function by given declaration, type and the body of the function.
+func (gen *CodeGenerator) genElixirModule(name, doc, source, location, deprecated, spec, content string) {
+	gen.StructAST[name] = spec
+	fieldName := gen.typeName(genElixirFieldName(name))
+	gen.Field += fmt.Sprintf("%sdefmodule %s do\n  @type t :: %s\n\n%send\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, spec, content)
+}
+
+// genElixirParse generates the parse function of the simple type module,
+// which parses the text by given parser.
+func genElixirParse(parser string) string {
+	if parser == "" {
+		return "  @spec parse(String.t()) :: t()\n  def parse(value), do: value\n"
+	}
+	return fmt.Sprintf("  @spec parse(String.t()) :: t()\n  def parse(value), do: then(value, %s)\n", parser)
+}
+
+// ElixirSimpleType generates code for simple type XML schema in Elixir
+// language syntax.
+func (gen *CodeGenerator) ElixirSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		item := gen.genElixirValue(gen.getBaseType(v.Base))
+		parser := item.parser
+		if parser == "" {
+			parser = "& &1"
+		}
+		content := fmt.Sprintf("  @spec parse(String.t()) :: t()\n  def parse(value), do: %s.Xgen.to_list(value, %s)\n", gen.elixirNamespace(), parser)
+		gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("[%s]", item.spec), content)
+		return
+	}
+	if v.Union {
+		gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "String.t()", genElixirParse(""))
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var values []string
+		for _, enum := range v.Restriction.Enum {
+			values = append(values, elixirQuote(enum))
+		}
+		content := fmt.Sprintf("  @values [%s]\n\n  @spec values() :: [t()]\n  def values, do: @values\n\n%s", strings.Join(values, ", "), genElixirParse(""))
+		gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "String.t()", content)
+		return
+	}
+	value := gen.genElixirValue(gen.getBaseType(v.Base))
+	gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value.spec, genElixirParse(value.parser))
+	return
+}
+
+// ElixirComplexType generates code for complex type XML schema in Elixir
+// language syntax.
+func (gen *CodeGenerator) ElixirComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var keys, specs, exprs []string
+	add := func(key, spec, expr string) {
+		keys, specs, exprs = append(keys, key), append(specs, spec), append(exprs, expr)
+	}
+	for _, attrGroup := range v.AttributeGroup {
+		add(gen.genElixirInline(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		add(gen.genElixirAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		add(gen.genElixirInline(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		add(gen.genElixirElement(element))
+	}
+	gen.genElixirStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, keys, specs, exprs)
+	return
+}
+
+// genElixirElement generates the field for the element.
+func (gen *CodeGenerator) genElixirElement(element Element) (key, spec, expr string) {
+	value := gen.genElixirValue(gen.getBaseType(element.Type))
+	path := "./" + element.Name
+	if value.simple {
+		path += "/text()"
+	}
+	return gen.genElixirField(element.Name, path, value, element.Plural, element.Optional)
+}
+
+// genElixirAttribute generates the field for the attribute, the attribute is
+// always parsed from the text.
+func (gen *CodeGenerator) genElixirAttribute(attribute Attribute) (key, spec, expr string) {
+	value := gen.genElixirValue(gen.getBaseType(attribute.Type))
+	value.simple = true
+	if attribute.Plural {
+		if value.parser == "" {
+			value.parser = "& &1"
+		}
+		value = elixirValue{spec: fmt.Sprintf("[%s]", value.spec), parser: fmt.Sprintf("&%s.Xgen.to_list(&1, %s)", gen.elixirNamespace(), value.parser), simple: true}
+	}
+	return gen.genElixirField(attribute.Name, "./@"+attribute.Name, value, false, attribute.Optional)
+}
+
+// genElixirInline generates the field for the group or attribute group, which
+// is parsed from the enclosing node.
+func (gen *CodeGenerator) genElixirInline(name, ref string) (key, spec, expr string) {
+	value := gen.genElixirValue(gen.getBaseType(ref))
+	return gen.genElixirKeyName(name), value.spec, fmt.Sprintf("node |> then(%s)", value.parser)
+}
+
+// ElixirGroup generates code for group XML schema in Elixir language syntax.
+func (gen *CodeGenerator) ElixirGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var keys, specs, exprs []string
+	add := func(key, spec, expr string) {
+		keys, specs, exprs = append(keys, key), append(specs, spec), append(exprs, expr)
+	}
+	for _, element := range v.Elements {
+		add(gen.genElixirElement(element))
+	}
+	for _, group := range v.Groups {
+		add(gen.genElixirInline(group.Name, group.Ref))
+	}
+	gen.genElixirStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, keys, specs, exprs)
+	return
+}
+
+// ElixirAttributeGroup generates code for attribute group XML schema in
+// Elixir language syntax.
+func (gen *CodeGenerator) ElixirAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var keys, specs, exprs []string
+	for _, attribute := range v.Attributes {
+		key, spec, expr := gen.genElixirAttribute(attribute)
+		keys, specs, exprs = append(keys, key), append(specs, spec), append(exprs, expr)
+	}
+	gen.genElixirStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, keys, specs, exprs)
+	return
+}
+
+// ElixirElement generates code for element XML schema in Elixir language
+// syntax. The module of the element parses the node by the parse function of
+// its type.
+func (gen *CodeGenerator) ElixirElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genElixirValue(gen.getBaseType(v.Type))
+	if !value.simple && !v.Plural {
+		gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value.spec, fmt.Sprintf("  @spec parse(term()) :: t()\n  defdelegate parse(node), to: %s\n", strings.TrimSuffix(strings.TrimPrefix(value.parser, "&"), ".parse/1")))
+		return
+	}
+	path := "./text()"
+	if !value.simple {
+		path = "."
+	}
+	_, spec, expr := gen.genElixirField(v.Name, path, value, v.Plural, false)
+	gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, spec, fmt.Sprintf("  import SweetXml\n\n  @spec parse(term()) :: t()\n  def parse(node), do: %s\n", expr))
+	return
+}
+
+// ElixirAttribute generates code for attribute XML schema in Elixir language
+// syntax.
+func (gen *CodeGenerator) ElixirAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genElixirValue(gen.getBaseType(v.Type))
+	if v.Plural {
+		if value.parser == "" {
+			value.parser = "& &1"
+		}
+		content := fmt.Sprintf("  @spec parse(String.t()) :: t()\n  def parse(value), do: %s.Xgen.to_list(value, %s)\n", gen.elixirNamespace(), value.parser)
+		gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("[%s]", value.spec), content)
+		return
+	}
+	gen.genElixirModule(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value.spec, genElixirParse(value.parser))
+	return
+}
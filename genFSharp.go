@@ -0,0 +1,261 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var fsharpBuildInType = map[string]bool{
+	"DateTime":         true,
+	"XmlQualifiedName": true,
+	"bigint":           true,
+	"bool":             true,
+	"byte":             true,
+	"byte[]":           true,
+	"decimal":          true,
+	"float":            true,
+	"float32":          true,
+	"int":              true,
+	"int16":            true,
+	"int64":            true,
+	"obj":              true,
+	"sbyte":            true,
+	"string":           true,
+	"string list":      true,
+	"uint16":           true,
+	"uint32":           true,
+	"uint64":           true,
+}
+
+// GenFSharp generate F# programming language source code for XML schema
+// definition files. The complex types, groups and attribute groups are
+// generated as records, the enumerations and the union simple types are
+// generated as discriminated unions. The declarations are placed in a
+// recursive module, so they can reference each other regardless of the order.
+func (gen *CodeGenerator) GenFSharp() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("FSharp%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	module := "Schema"
+	if gen.Package != "" {
+		module = MakeFirstUpperCase(gen.Package)
+	}
+	importPackage := "open System\nopen System.Xml\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("open %s\n", mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("%s\n\nmodule rec %s\n\n%s%s", copyright, module, importPackage, gen.Field))
+	return gen.writeFile(gen.File+".fs", source)
+}
+
+func genFSharpFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genFSharpFieldType generates the type for F# code by given type. The list
+// and union simple types are referenced by the types generated for them.
+func (gen *CodeGenerator) genFSharpFieldType(name string) string {
+	if _, ok := fsharpBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genFSharpFieldName(name)
+	if fieldType == "" {
+		return "string"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genFSharpCaseName generates the name of the union case by given name, the
+// case name must start with an upper case letter.
+func (gen *CodeGenerator) genFSharpCaseName(name string) string {
+	caseName := ConvertCase(name, PascalCase)
+	if caseName == "" || !(caseName[0] >= 'A' && caseName[0] <= 'Z') {
+		caseName = "Value" + caseName
+	}
+	return gen.escapeReservedWord(caseName)
+}
+
+// genFSharpField generates the field of the record by given name and type.
+func (gen *CodeGenerator) genFSharpField(name, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		fieldType += " list"
+	case optional:
+		fieldType += " option"
+	}
+	return fmt.Sprintf("%s: %s", gen.fieldName(genFSharpFieldName(name)), fieldType)
+}
+
+// genFSharpRecord generates the record by given declaration and fields, the
+// declaration without any field is generated as the single case union.
+func (gen *CodeGenerator) genFSharpRecord(name, doc, source, location, deprecated string, fields []string) {
+	fieldName := gen.typeName(genFSharpFieldName(name))
+	content := fmt.Sprintf("\n    { %s }", strings.Join(fields, "\n      "))
+	if len(fields) == 0 {
+		content = " " + fieldName
+	}
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%stype %s =%s\n", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genFSharpAlias generates the type abbreviation by given declaration and
+// type.
+func (gen *CodeGenerator) genFSharpAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genFSharpFieldName(name))
+	gen.Field += fmt.Sprintf("%stype %s = %s\n", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// genFSharpUnion generates the discriminated union by given declaration and
+// cases, the cases are accessed with the name of the union.
+func (gen *CodeGenerator) genFSharpUnion(name, doc, source, location, deprecated string, cases []string, members string) {
+	fieldName := gen.typeName(genFSharpFieldName(name))
+	content := "\n    | " + strings.Join(cases, "\n    | ") + "\n"
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%s[<RequireQualifiedAccess>]\ntype %s =%s%s", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, content, members)
+}
+
+// FSharpSimpleType generates code for simple type XML schema in F# language
+// syntax.
+func (gen *CodeGenerator) FSharpSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genFSharpAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genFSharpFieldType(gen.getBaseType(v.Base))+" list")
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var cases []string
+		for _, member := range v.MemberTypes {
+			cases = append(cases, fmt.Sprintf("%s of %s", gen.genFSharpCaseName(genFSharpFieldName(member.Name)), gen.genFSharpFieldType(gen.getBaseType(member.Type))))
+		}
+		gen.genFSharpUnion(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, cases, "")
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		fieldName := gen.typeName(genFSharpFieldName(v.Name))
+		var cases, values []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			caseName := gen.genFSharpCaseName(enum)
+			if members[caseName] {
+				continue
+			}
+			members[caseName] = true
+			cases = append(cases, caseName)
+			values = append(values, fmt.Sprintf("        | %s.%s -> %s", fieldName, caseName, strconv.Quote(enum)))
+		}
+		gen.genFSharpUnion(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, cases, fmt.Sprintf("\n    /// Value returns the value of the enumeration in the schema.\n    member this.Value =\n        match this with\n%s\n", strings.Join(values, "\n")))
+		return
+	}
+	gen.genFSharpAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genFSharpFieldType(gen.getBaseType(v.Base)))
+	return
+}
+
+// FSharpComplexType generates code for complex type XML schema in F# language
+// syntax.
+func (gen *CodeGenerator) FSharpComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []string
+	for _, attrGroup := range v.AttributeGroup {
+		fields = append(fields, gen.genFSharpField(attrGroup.Name, gen.genFSharpFieldType(gen.getBaseType(attrGroup.Ref)), false, false))
+	}
+	for _, attribute := range v.Attributes {
+		fields = append(fields, gen.genFSharpField(attribute.Name, gen.genFSharpFieldType(gen.getBaseType(attribute.Type)), attribute.Plural, attribute.Optional))
+	}
+	for _, group := range v.Groups {
+		fields = append(fields, gen.genFSharpField(group.Name, gen.genFSharpFieldType(gen.getBaseType(group.Ref)), group.Plural, false))
+	}
+	for _, element := range v.Elements {
+		fields = append(fields, gen.genFSharpField(element.Name, gen.genFSharpFieldType(gen.getBaseType(element.Type)), element.Plural, element.Optional))
+	}
+	gen.genFSharpRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// FSharpGroup generates code for group XML schema in F# language syntax.
+func (gen *CodeGenerator) FSharpGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []string
+	for _, element := range v.Elements {
+		fields = append(fields, gen.genFSharpField(element.Name, gen.genFSharpFieldType(gen.getBaseType(element.Type)), element.Plural, element.Optional))
+	}
+	for _, group := range v.Groups {
+		fields = append(fields, gen.genFSharpField(group.Name, gen.genFSharpFieldType(gen.getBaseType(group.Ref)), group.Plural, false))
+	}
+	gen.genFSharpRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// FSharpAttributeGroup generates code for attribute group XML schema in F#
+// language syntax.
+func (gen *CodeGenerator) FSharpAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []string
+	for _, attribute := range v.Attributes {
+		fields = append(fields, gen.genFSharpField(attribute.Name, gen.genFSharpFieldType(gen.getBaseType(attribute.Type)), attribute.Plural, attribute.Optional))
+	}
+	gen.genFSharpRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// FSharpElement generates code for element XML schema in F# language syntax.
+func (gen *CodeGenerator) FSharpElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genFSharpFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType += " list"
+	}
+	gen.genFSharpAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
+
+// FSharpAttribute generates code for attribute XML schema in F# language
+// syntax.
+func (gen *CodeGenerator) FSharpAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genFSharpFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType += " list"
+	}
+	gen.genFSharpAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
@@ -0,0 +1,299 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var flatBuffersBuildInType = map[string]bool{
+	"[string]": true,
+	"[ubyte]":  true,
+	"bool":     true,
+	"byte":     true,
+	"double":   true,
+	"float":    true,
+	"int":      true,
+	"long":     true,
+	"short":    true,
+	"string":   true,
+	"ubyte":    true,
+	"uint":     true,
+	"ulong":    true,
+	"ushort":   true,
+}
+
+// flatBuffersScalarType defines the scalar types of FlatBuffers, the fields
+// of them are stored inline and can't be required.
+var flatBuffersScalarType = toSet("bool", "byte", "double", "float", "int", "long", "short", "ubyte", "uint", "ulong", "ushort")
+
+// GenFlatBuffers generate FlatBuffers schema for XML schema definition files.
+// The complex types, groups and attribute groups are generated as tables, and
+// the enumerations are generated as enums. There are no aliases in
+// FlatBuffers, so the lists are replaced by the vectors, the unions are
+// replaced by the strings in the lexical form, and the other simple types are
+// replaced by the scalar types. The type of the first global element is
+// declared as the root type.
+func (gen *CodeGenerator) GenFlatBuffers() error {
+	var rootType string
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("FlatBuffers%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+		if v, ok := ele.(*Element); ok && rootType == "" && !v.Plural {
+			if fieldType, scalar := gen.genFlatBuffersFieldType(v.Type); !scalar && !flatBuffersBuildInType[fieldType] && !strings.HasPrefix(fieldType, "[") {
+				rootType = fmt.Sprintf("\nroot_type %s;\n", fieldType)
+			}
+		}
+	}
+	gen.genPlaceholders()
+	var includes []string
+	for _, mapping := range gen.getImportMappings() {
+		includes = append(includes, mapping.Import)
+	}
+	sort.Strings(includes)
+	var include string
+	for i, name := range includes {
+		if i > 0 && includes[i-1] == name {
+			continue
+		}
+		include += fmt.Sprintf("include %q;\n", name)
+	}
+	if include != "" {
+		include = "\n" + include
+	}
+	var namespace string
+	if gen.Package != "" {
+		namespace = fmt.Sprintf("\nnamespace %s;\n", gen.Package)
+	}
+	source := []byte(fmt.Sprintf("%s\n%s%s%s%s", copyright, include, namespace, gen.Field, rootType))
+	return gen.writeFile(gen.File+".fbs", source)
+}
+
+func genFlatBuffersFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genFlatBuffersFieldType generates the type for FlatBuffers by given type
+// name, and reports whether it's a scalar. The enumerations are referred by
+// the enum types, the lists are the vectors of the items, and the other
+// restrictions are replaced by their base types. The vectors can't be
+// nested, so the lists of lists are kept in the lexical form.
+func (gen *CodeGenerator) genFlatBuffersFieldType(name string) (string, bool) {
+	if v := gen.getSimpleType(trimNSPrefix(name)); v != nil {
+		switch {
+		case v.List:
+			if itemType, _ := gen.genFlatBuffersFieldType(v.Base); !strings.HasPrefix(itemType, "[") {
+				return "[" + itemType + "]", false
+			}
+			return "[string]", false
+		case v.Union:
+			return "string", false
+		case len(v.Restriction.Enum) > 0:
+			return gen.typeName(genFlatBuffersFieldName(v.Name)), true
+		}
+	}
+	name = gen.getBaseType(name)
+	if _, ok := flatBuffersBuildInType[name]; ok {
+		return name, flatBuffersScalarType[name]
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType, false
+	}
+	fieldType := genFlatBuffersFieldName(name)
+	if fieldType == "" {
+		return "string", false
+	}
+	return gen.typeName(fieldType), false
+}
+
+// flatBuffersTable holds the fields of the table in generating.
+type flatBuffersTable struct {
+	fields []string
+	names  map[string]bool
+}
+
+// addField provides a function to add the field by given XML name and type,
+// the plural fields are the vectors of the items, the optional scalars
+// default to null to track the presence, and the required non-scalar fields
+// are marked by the required attribute. The index of the field is appended to
+// the name of the field which collides with another, e.g. the attribute and
+// element with the same name.
+func (t *flatBuffersTable) addField(gen *CodeGenerator, name, typeName, deprecated string, plural, optional bool) {
+	fieldType, scalar := gen.genFlatBuffersFieldType(typeName)
+	if plural && !strings.HasPrefix(fieldType, "[") {
+		fieldType, scalar = "["+fieldType+"]", false
+	}
+	switch {
+	case scalar && optional:
+		fieldType += " = null"
+	case !scalar && !optional:
+		fieldType += " (required)"
+	}
+	var comment string
+	if deprecated != "" {
+		comment = fmt.Sprintf("  // Deprecated: %s\n", strings.Replace(deprecated, "\n", " ", -1))
+	}
+	fieldName := gen.fieldName(ConvertCase(genFlatBuffersFieldName(name), SnakeCase))
+	if t.names == nil {
+		t.names = map[string]bool{}
+	}
+	if t.names[fieldName] {
+		fieldName = fmt.Sprintf("%s_%d", fieldName, len(t.fields)+1)
+	}
+	t.names[fieldName] = true
+	t.fields = append(t.fields, fmt.Sprintf("%s  %s:%s;\n", comment, fieldName, fieldType))
+}
+
+// genFlatBuffersTable generates the table by given declaration and fields.
+func (gen *CodeGenerator) genFlatBuffersTable(name, doc, source, location, deprecated string, table *flatBuffersTable) {
+	content := strings.Join(table.fields, "")
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genFlatBuffersFieldName(name))
+	gen.Field += fmt.Sprintf("%stable %s {\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// FlatBuffersSimpleType generates code for simple type XML schema in
+// FlatBuffers schema. The enumerations are generated as enums with the
+// smallest unsigned underlying type, and the XML values are noted in the
+// comments.
+func (gen *CodeGenerator) FlatBuffersSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name], _ = gen.genFlatBuffersFieldType(v.Name)
+		return
+	}
+	var values, comments []string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, PascalCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "Value" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		values = append(values, fmt.Sprintf("  %s = %d", member, len(values)))
+		comments = append(comments, strings.Replace(enum, "\n", " ", -1))
+	}
+	underlyingType := "ubyte"
+	if len(values) > 1<<16 {
+		underlyingType = "uint"
+	} else if len(values) > 1<<8 {
+		underlyingType = "ushort"
+	}
+	var content string
+	for i, value := range values {
+		separator := ","
+		if i == len(values)-1 {
+			separator = ""
+		}
+		content += fmt.Sprintf("%s%s // %s\n", value, separator, comments[i])
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genFlatBuffersFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%senum %s : %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, underlyingType, content)
+	return
+}
+
+// FlatBuffersComplexType generates code for complex type XML schema in
+// FlatBuffers schema.
+func (gen *CodeGenerator) FlatBuffersComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	table := &flatBuffersTable{}
+	for _, attrGroup := range v.AttributeGroup {
+		table.addField(gen, attrGroup.Name, attrGroup.Ref, "", false, false)
+	}
+	for _, attribute := range v.Attributes {
+		table.addField(gen, attribute.Name, attribute.Type, attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	for _, group := range v.Groups {
+		table.addField(gen, group.Name, group.Ref, "", group.Plural, false)
+	}
+	for _, element := range v.Elements {
+		table.addField(gen, element.Name, element.Type, element.Deprecated, element.Plural, element.Optional)
+	}
+	gen.genFlatBuffersTable(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, table)
+	return
+}
+
+// FlatBuffersGroup generates code for group XML schema in FlatBuffers
+// schema.
+func (gen *CodeGenerator) FlatBuffersGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	table := &flatBuffersTable{}
+	for _, element := range v.Elements {
+		table.addField(gen, element.Name, element.Type, element.Deprecated, element.Plural, element.Optional)
+	}
+	for _, group := range v.Groups {
+		table.addField(gen, group.Name, group.Ref, "", group.Plural, false)
+	}
+	gen.genFlatBuffersTable(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, table)
+	return
+}
+
+// FlatBuffersAttributeGroup generates code for attribute group XML schema in
+// FlatBuffers schema.
+func (gen *CodeGenerator) FlatBuffersAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	table := &flatBuffersTable{}
+	for _, attribute := range v.Attributes {
+		table.addField(gen, attribute.Name, attribute.Type, attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	gen.genFlatBuffersTable(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, table)
+	return
+}
+
+// FlatBuffersElement generates code for element XML schema in FlatBuffers
+// schema, the element is referred by its type.
+func (gen *CodeGenerator) FlatBuffersElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name], _ = gen.genFlatBuffersFieldType(v.Type)
+	}
+	return
+}
+
+// FlatBuffersAttribute generates code for attribute XML schema in FlatBuffers
+// schema, the attribute is referred by its type.
+func (gen *CodeGenerator) FlatBuffersAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name], _ = gen.genFlatBuffersFieldType(v.Type)
+	}
+	return
+}
@@ -10,23 +10,66 @@ package xgen
 
 import (
 	"fmt"
+	"go/ast"
 	"go/format"
-	"os"
+	"go/parser"
+	"go/token"
+	"math"
+	"math/big"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // CodeGenerator holds code generator overrides and runtime data that are used
 // when generate code from proto tree.
 type CodeGenerator struct {
-	Lang              string
-	File              string
-	Field             string
-	Package           string
-	ImportTime        bool // For Go language
-	ImportEncodingXML bool // For Go language
-	ProtoTree         []interface{}
-	StructAST         map[string]string
+	Lang                string
+	File                string
+	Field               string
+	Package             string
+	CMake               bool     // For C language
+	Zod                 bool     // For TypeScript language
+	Lxml                bool     // For Python language
+	TargetFramework     string   // For C# language
+	OptionalPointers    bool     // For Go language
+	StructTags          []string // For Go language
+	SplitFiles          bool     // For Go language
+	DeepCopy            bool     // For Go language
+	Stringer            bool     // For Go language
+	DecodeEach          bool     // For Go language
+	Naming              NamingConvention
+	Escape              Escape
+	Escaped             map[string]string
+	Renamed             map[string]string
+	SkipWrappers        bool
+	TypeMapping         map[string]TypeMapping
+	ImportMapping       map[string]bool
+	ImportTime          bool                   // For Python language
+	ImportEncodingXML   bool                   // For Ruby language
+	ImportXsdTime       bool                   // For Go language
+	ImportXsdNumber     bool                   // For Go language
+	ImportActiveModel   bool                   // For Ruby language
+	ImportDecimal       bool                   // For Python language
+	ImportBuildIn       map[string]bool        // For Kotlin, Crystal and Protobuf
+	Converters          string                 // For OCaml and Nim language
+	Forwards            string                 // For Nim, Julia and SQL language
+	Constraints         string                 // For SQL language
+	Implementation      string                 // For Objective-C and zod schemas of TypeScript
+	Schemas             map[string]interface{} // For Avro, DOT and XML language
+	TargetNamespace     string                 // For XML language
+	ProtoTree           []interface{}
+	Targets             map[string]string
+	StructAST           map[string]string
+	Failures            *FailureLog
+	Failed              map[string]string
+	Outputs             map[string][]byte
+	goValidated         map[string]bool
+	goConstructed       map[string]bool
+	goSubstitutionHeads map[string]string
+	goForeignStructs    map[string]bool
 }
 
 var goBuildinType = map[string]bool{
@@ -59,40 +102,386 @@ var goBuildinType = map[string]bool{
 // GenGo generate Go programming language source code for XML schema
 // definition files.
 func (gen *CodeGenerator) GenGo() error {
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	var paths []string
+	files, support := map[string]string{}, map[string]bool{}
+	addFile := func(path, content string) {
+		if _, ok := files[path]; !ok {
+			paths = append(paths, path)
+		}
+		files[path] += content
+	}
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("Go%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		if gen.SplitFiles {
+			gen.Field = ""
+		}
+		if !gen.isWrapper(ele) {
+			gen.genDeclaration(ele, fmt.Sprintf("Go%s", reflect.TypeOf(ele).String()[6:]))
+		}
+		if element, ok := ele.(*Element); ok && gen.DecodeEach {
+			gen.genGoDecodeEach(element)
+		}
+		if gen.SplitFiles && gen.Field != "" {
+			addFile(gen.goTypeFile(declarationName(ele)), gen.Field)
+		}
+	}
+	if gen.SplitFiles {
+		gen.Field = ""
+	}
+	gen.genPlaceholders()
+	if !gen.SplitFiles || gen.Field != "" {
+		addFile(gen.File+".go", gen.Field)
+	}
+	if gen.ImportXsdTime {
+		path := filepath.Join(filepath.Dir(gen.File), "xsdtime.go")
+		addFile(path, goXsdTimeSource())
+		support[path] = true
+	}
+	if gen.ImportXsdNumber {
+		path, content := filepath.Join(filepath.Dir(gen.File), "xsdnumber.go"), goXsdNumber
+		if gen.DeepCopy {
+			content += goXsdNumberDeepCopy
+		}
+		addFile(path, content)
+		support[path] = true
+	}
+	if gen.Stringer {
+		path := filepath.Join(filepath.Dir(gen.File), "xsddump.go")
+		addFile(path, goXsdDump)
+		support[path] = true
+	}
+	if gen.DeepCopy || gen.Stringer {
+		decls, err := newGoTypeDecls(paths, files, support)
+		if err != nil {
+			return err
+		}
+		if gen.DeepCopy {
+			for name := range gen.goForeignStructs {
+				// the structs in the packages of the other namespaces are
+				// generated with the deep copy methods as well.
+				if mapping, ok := gen.TypeMapping[name]; ok {
+					decls.copiers[strings.TrimPrefix(mapping.Type, "*")] = true
+				}
+			}
+			decls.genDeepCopy(paths, files)
+		}
+		if gen.Stringer {
+			decls.genStringers(paths, files)
+		}
+	}
+	for _, path := range paths {
+		if err := gen.genGoFile(path, packageName, files[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goTypeFile returns the path of the file of the type declared by given name
+// in the split files mode, which is in the package directory and named by
+// the file naming convention, or in snake case by default.
+func (gen *CodeGenerator) goTypeFile(name string) string {
+	convention := gen.Naming.File
+	if convention == "" {
+		convention = SnakeCase
 	}
-	f, err := os.Create(gen.File + ".go")
+	return filepath.Join(filepath.Dir(gen.File), ConvertCase(gen.typeName(genGoFieldName(name)), convention)+".go")
+}
+
+// goStandardPackages maps the names of the standard packages which could be
+// referenced by the generated code to their import paths.
+var goStandardPackages = map[string]string{
+	"big":     "math/big",
+	"bytes":   "bytes",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"io":      "io",
+	"regexp":  "regexp",
+	"strconv": "strconv",
+	"strings": "strings",
+	"time":    "time",
+	"utf8":    "unicode/utf8",
+	"xml":     "encoding/xml",
+}
+
+// genGoFile writes the given declarations into the file by given path, the
+// import block is computed from the packages referenced by the declarations,
+// and the source is formatted by gofmt.
+func (gen *CodeGenerator) genGoFile(path, packageName, content string) error {
+	source := fmt.Sprintf("%s\n\npackage %s\n%s", copyright, packageName, content)
+	imports, err := gen.goImports(source)
 	if err != nil {
+		gen.writeFile(path, []byte(source))
 		return err
 	}
-	defer f.Close()
-	var importPackage, packages string
-	if gen.ImportTime {
-		packages += "\t\"time\"\n"
+	var importPackage string
+	if len(imports) != 0 {
+		importPackage = fmt.Sprintf("\nimport (\n%s)\n", strings.Join(imports, ""))
 	}
-	if gen.ImportEncodingXML {
-		packages += "\t\"encoding/xml\"\n"
+	source = fmt.Sprintf("%s\n\npackage %s\n%s%s", copyright, packageName, importPackage, content)
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		gen.writeFile(path, []byte(source))
+		return err
 	}
-	if packages != "" {
-		importPackage = fmt.Sprintf("import (\n%s)", packages)
+	return gen.writeFile(path, formatted)
+}
+
+// goImports returns the lines of the import block for the packages referenced
+// by the qualified identifiers in the given source, which are the standard
+// packages and the packages of the mapped types. The standard packages are
+// grouped before the others as goimports does.
+func (gen *CodeGenerator) goImports(source string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, 0)
+	if err != nil {
+		return nil, err
 	}
-	packageName := gen.Package
-	if packageName == "" {
-		packageName = "schema"
+	packages := map[string]string{}
+	for name, path := range goStandardPackages {
+		packages[name] = path
+	}
+	var names []string
+	for name := range gen.TypeMapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mapping := gen.TypeMapping[name]
+		if qualifier := goQualifier(mapping.Type); qualifier != "" && mapping.Import != "" {
+			packages[qualifier] = mapping.Import
+		}
+	}
+	referenced := map[string]bool{}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			// the identifiers declared in the source are resolved, the
+			// unresolved ones are the package names.
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil && packages[ident.Name] != "" {
+				referenced[packages[ident.Name]] = true
+			}
+		}
+		return true
+	})
+	var standard, others []string
+	for path := range referenced {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+			continue
+		}
+		standard = append(standard, path)
+	}
+	sort.Strings(standard)
+	sort.Strings(others)
+	var imports []string
+	for _, path := range standard {
+		imports = append(imports, fmt.Sprintf("\t%q\n", path))
+	}
+	if len(standard) != 0 && len(others) != 0 {
+		imports = append(imports, "\n")
+	}
+	for _, path := range others {
+		imports = append(imports, fmt.Sprintf("\t%q\n", path))
+	}
+	return imports, nil
+}
+
+// goQualifier returns the package name qualifying the given Go type, such as
+// money for *money.Amount, or an empty string if the type isn't qualified.
+func goQualifier(typeName string) string {
+	i := strings.LastIndex(typeName, ".")
+	if i == -1 {
+		return ""
 	}
-	source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n%s%s", copyright, packageName, importPackage, gen.Field)))
+	typeName = typeName[:i]
+	return typeName[strings.LastIndexAny(typeName, "*[]) ")+1:]
+}
+
+// goXsdTimeLayouts defines the types of the date and time values of the XML
+// schema in Go, and the layouts of their lexical forms without the timezone.
+var goXsdTimeLayouts = map[string][2]string{
+	"XsdDate":       {"date", "2006-01-02"},
+	"XsdDateTime":   {"dateTime", "2006-01-02T15:04:05.999999999"},
+	"XsdTime":       {"time", "15:04:05.999999999"},
+	"XsdGDay":       {"gDay", "---02"},
+	"XsdGMonth":     {"gMonth", "--01"},
+	"XsdGMonthDay":  {"gMonthDay", "--01-02"},
+	"XsdGYear":      {"gYear", "2006"},
+	"XsdGYearMonth": {"gYearMonth", "2006-01"},
+}
+
+// goXsdTimeHelpers defines the functions shared by the date and time types,
+// the values without the timezone are in the location without name, which
+// are formatted without the timezone as well.
+const goXsdTimeHelpers = `
+// xsdLocalTime is the location of the values without the timezone.
+var xsdLocalTime = time.FixedZone("", 0)
+
+// parseXsdTime parses the value in the lexical form by given layout, which
+// is followed by the optional timezone Z or ±hh:mm.
+func parseXsdTime(layout, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation(layout, value, xsdLocalTime); err == nil {
+		return t, nil
+	}
+	return time.Parse(layout+"Z07:00", value)
+}
+
+// formatXsdTime formats the time in the lexical form by given layout, the
+// timezone is omitted if the time was parsed without it.
+func formatXsdTime(layout string, t time.Time) string {
+	if t.Location() == xsdLocalTime {
+		return t.Format(layout)
+	}
+	return t.Format(layout + "Z07:00")
+}
+`
+
+// goXsdTimeType defines the methods of a date and time type, which encode
+// and decode the value in the lexical form of the XML schema as the
+// character data or attribute value.
+const goXsdTimeType = `
+// %[1]s is the xs:%[2]s value, the lexical form is given by the layout
+// %[3]s with the optional timezone.
+type %[1]s time.Time
+
+// String returns the %[1]s in the lexical form.
+func (v %[1]s) String() string {
+	return formatXsdTime(%[3]q, time.Time(v))
+}
+
+// MarshalText encodes the %[1]s in the lexical form.
+func (v %[1]s) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText decodes the %[1]s from the lexical form.
+func (v *%[1]s) UnmarshalText(text []byte) error {
+	t, err := parseXsdTime(%[3]q, string(text))
 	if err != nil {
-		f.WriteString(fmt.Sprintf("package %s\n%s%s", packageName, importPackage, gen.Field))
 		return err
 	}
-	f.Write(source)
-	return err
+	*v = %[1]s(t)
+	return nil
+}
+
+// Scan decodes the %[1]s from the lexical form, which implements the
+// fmt.Scanner for decoding the items of the lists.
+func (v *%[1]s) Scan(state fmt.ScanState, verb rune) error {
+	token, err := state.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalText(token)
+}
+
+// MarshalXML encodes the %[1]s as the character data of the element.
+func (v %[1]s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(v.String(), start)
+}
+
+// UnmarshalXML decodes the %[1]s from the character data of the element.
+func (v *%[1]s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	return v.UnmarshalText([]byte(text))
+}
+
+// MarshalXMLAttr encodes the %[1]s as the attribute value.
+func (v %[1]s) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: v.String()}, nil
+}
+
+// UnmarshalXMLAttr decodes the %[1]s from the attribute value.
+func (v *%[1]s) UnmarshalXMLAttr(attr xml.Attr) error {
+	return v.UnmarshalText([]byte(attr.Value))
+}
+`
+
+// goXsdTimeSource returns the declarations of the xsdtime.go beside the
+// generated code, which declares the types of the date and time values. The
+// time.Time only accepts the RFC 3339 date and time, the types parse and
+// format the lexical forms of the XML schema instead.
+func goXsdTimeSource() string {
+	names := make([]string, 0, len(goXsdTimeLayouts))
+	for name := range goXsdTimeLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	content := goXsdTimeHelpers
+	for _, name := range names {
+		content += fmt.Sprintf(goXsdTimeType, name, goXsdTimeLayouts[name][0], goXsdTimeLayouts[name][1])
+	}
+	return content
+}
+
+// goXsdNumber defines the arbitrary-precision types which the unbounded
+// integers and decimals of the XML schema map to with the big numbers.
+const goXsdNumber = `
+// XsdInteger is the integer of arbitrary size, which the xs:integer and the
+// integer types derived from it without the fixed size map to.
+type XsdInteger struct {
+	big.Int
+}
+
+// String returns the integer in the lexical form.
+func (v XsdInteger) String() string {
+	return v.Int.String()
+}
+
+// MarshalText encodes the integer in the lexical form.
+func (v XsdInteger) MarshalText() ([]byte, error) {
+	return v.Int.MarshalText()
+}
+
+// UnmarshalText decodes the integer in the lexical form, which is always in
+// base 10.
+func (v *XsdInteger) UnmarshalText(text []byte) error {
+	if _, ok := v.SetString(strings.TrimSpace(string(text)), 10); !ok {
+		return fmt.Errorf("invalid integer %q", text)
+	}
+	return nil
+}
+
+// XsdDecimal is the decimal number of arbitrary precision in the lexical
+// form, which the xs:decimal maps to.
+type XsdDecimal string
+
+// Rat returns the value of the decimal number, ok is false if it isn't a
+// valid number.
+func (v XsdDecimal) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(strings.TrimSpace(string(v)))
+}
+
+// compareXsdNumber compares the number in the lexical form with the bound of
+// the facets, the invalid number is less than any bound.
+func compareXsdNumber(value, bound string) int {
+	x, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return -1
+	}
+	y, _ := new(big.Rat).SetString(bound)
+	return x.Cmp(y)
+}
+`
+
+// goXsdNumberDeepCopy defines the deep copy method of the XsdInteger, the
+// copy of big.Int shares the words of the value.
+const goXsdNumberDeepCopy = `
+// DeepCopyInto copies the XsdInteger into out, which shares no references
+// with the receiver.
+func (in *XsdInteger) DeepCopyInto(out *XsdInteger) {
+	*out = XsdInteger{}
+	out.Set(&in.Int)
 }
+`
 
 func genGoFieldName(name string) (fieldName string) {
 	for _, str := range strings.Split(name, ":") {
@@ -107,17 +496,28 @@ func genGoFieldName(name string) (fieldName string) {
 	return
 }
 
-func genGoFieldType(name string) string {
+func (gen *CodeGenerator) genGoFieldType(name string) string {
+	if _, ok := goXsdTimeLayouts[name]; ok {
+		gen.ImportXsdTime = true
+		return name
+	}
+	if name == "XsdInteger" || name == "XsdDecimal" {
+		gen.ImportXsdNumber = true
+		return name
+	}
 	if _, ok := goBuildinType[name]; ok {
 		return name
 	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
 	var fieldType string
 	for _, str := range strings.Split(name, ".") {
 		fieldType += MakeFirstUpperCase(str)
 	}
 	fieldType = strings.Replace(MakeFirstUpperCase(strings.Replace(fieldType, "-", "", -1)), "_", "", -1)
 	if fieldType != "" {
-		return "*" + fieldType
+		return "*" + gen.typeName(fieldType)
 	}
 	return "interface{}"
 }
@@ -129,62 +529,506 @@ var copyright = `// Code generated by xgen. DO NOT EDIT.`
 func (gen *CodeGenerator) GoSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			if fieldType == "time.Time" {
-				gen.ImportTime = true
-			}
-			content := fmt.Sprintf(" []%s\n", genGoFieldType(fieldType))
+			fieldType := gen.genGoFieldType(gen.getBaseType(v.Base))
+			content := fmt.Sprintf(" []%s\n", gen.genGoFieldType(fieldType))
 			gen.StructAST[v.Name] = content
-			fieldName := genGoFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genGoFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+			gen.Field += gen.genGoListMethods(fieldName)
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			content := " struct {\n"
-			fieldName := genGoFieldName(v.Name)
-			if fieldName != v.Name {
-				gen.ImportEncodingXML = true
-				content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
-			}
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
-				content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(memberName), genGoFieldType(memberType))
+			fieldName := gen.typeName(genGoFieldName(v.Name))
+			for _, member := range v.MemberTypes {
+				content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(genGoFieldName(member.Name)), gen.genGoFieldType(member.Type), gen.genGoTags("", member.Name, false))
 			}
 			content += "}\n"
 			gen.StructAST[v.Name] = content
-			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := fmt.Sprintf(" %s\n", genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)))
+		fieldType := gen.genGoFieldType(gen.getBaseType(v.Base))
+		content := fmt.Sprintf(" %s\n", fieldType)
 		gen.StructAST[v.Name] = content
-		fieldName := genGoFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genGoFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.Field += gen.genGoEnumConstants(fieldName, fieldType, v.Restriction.Enum)
+		value := "v"
+		if fieldType == "string" {
+			value = "string(v)"
+		}
+		if checks := gen.genGoFacetChecks(fieldName, value, fieldType, gen.goFacets(v.Base, v.Restriction)); checks != "" {
+			gen.genGoValidate(fieldName, fieldName, checks)
+		}
+	}
+	return
+}
+
+// genGoAssertions generates the ValidateAssertions method of the complex type
+// by given type name and the XPath 2.0 expressions of the XSD 1.1 assertions,
+// the expressions are evaluated by the function given by the caller.
+func (gen *CodeGenerator) genGoAssertions(typeName string, assertions []string) string {
+	tests := make([]string, len(assertions))
+	for i, assertion := range assertions {
+		tests[i] = strconv.Quote(assertion)
+	}
+	return fmt.Sprintf(`
+// ValidateAssertions checks the %[1]s against the assertions of the schema by
+// given function, which evaluates the XPath 2.0 expression on the value.
+func (v *%[1]s) ValidateAssertions(eval func(v interface{}, test string) (bool, error)) error {
+	for _, test := range []string{%[2]s} {
+		ok, err := eval(v, test)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%[1]s: assertion %%s failed", test)
+		}
+	}
+	return nil
+}
+`, typeName, strings.Join(tests, ", "))
+}
+
+// goFacets returns the facets of the value by given type name and the
+// restriction declared on it, the facets absent in the restriction are
+// inherited from the named simple types which the type is derived from.
+func (gen *CodeGenerator) goFacets(typeName string, restriction Restriction) Restriction {
+	visited := map[string]bool{}
+	for name := trimNSPrefix(typeName); !visited[name]; {
+		visited[name] = true
+		simpleType := gen.getSimpleType(name)
+		if simpleType == nil || simpleType.List || simpleType.Union {
+			break
+		}
+		inherited := simpleType.Restriction
+		if restriction.Pattern == nil {
+			restriction.Pattern = inherited.Pattern
+		}
+		if restriction.MinLength == 0 {
+			restriction.MinLength = inherited.MinLength
+		}
+		if restriction.MaxLength == 0 {
+			restriction.MaxLength = inherited.MaxLength
+		}
+		if !restriction.HasMin && inherited.HasMin {
+			restriction.Min, restriction.HasMin, restriction.MinExclusive = inherited.Min, true, inherited.MinExclusive
+		}
+		if !restriction.HasMax && inherited.HasMax {
+			restriction.Max, restriction.HasMax, restriction.MaxExclusive = inherited.Max, true, inherited.MaxExclusive
+		}
+		name = trimNSPrefix(simpleType.Base)
+	}
+	return restriction
+}
+
+// hasGoFacets reports whether the value by given type name and restriction
+// has the facets checked in the Validate methods.
+func (gen *CodeGenerator) hasGoFacets(typeName string, restriction Restriction) bool {
+	restriction = gen.goFacets(typeName, restriction)
+	return restriction.Pattern != nil || restriction.MinLength > 0 || restriction.MaxLength > 0 || restriction.HasMin || restriction.HasMax
+}
+
+// isGoValidated reports whether the Validate method is generated for the
+// complex type, group or attribute group declared in the proto tree by given
+// name.
+func (gen *CodeGenerator) isGoValidated(name string) bool {
+	if gen.goValidated == nil {
+		gen.goValidated = gen.goValidatedTypes()
+	}
+	return gen.goValidated[gen.getBaseType(name)]
+}
+
+// goValidatedTypes returns the names of the complex types, groups and
+// attribute groups in the proto tree which have the fields with facets, or
+// reach such a type through the base type, groups, attribute groups or child
+// elements.
+func (gen *CodeGenerator) goValidatedTypes() map[string]bool {
+	validated, refs := map[string]bool{}, map[string][]string{}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			if v.Base != "" && !gen.hasSimpleContent(v) {
+				refs[v.Name] = append(refs[v.Name], v.Base)
+			}
+			for _, attrGroup := range v.AttributeGroup {
+				refs[v.Name] = append(refs[v.Name], attrGroup.Ref)
+			}
+			for _, group := range v.Groups {
+				refs[v.Name] = append(refs[v.Name], group.Ref)
+			}
+			validated[v.Name] = gen.hasGoValidatedAttributes(v.Attributes) || gen.hasGoValidatedElements(v.Elements, refs, v.Name)
+		case *Group:
+			for _, group := range v.Groups {
+				refs[v.Name] = append(refs[v.Name], group.Ref)
+			}
+			validated[v.Name] = gen.hasGoValidatedElements(v.Elements, refs, v.Name)
+		case *AttributeGroup:
+			validated[v.Name] = gen.hasGoValidatedAttributes(v.Attributes)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, names := range refs {
+			for _, ref := range names {
+				if !validated[name] && validated[gen.getBaseType(ref)] {
+					validated[name], changed = true, true
+				}
+			}
+		}
+	}
+	return validated
+}
+
+// hasGoValidatedAttributes reports whether any of the attributes has the
+// facets checked in the Validate methods.
+func (gen *CodeGenerator) hasGoValidatedAttributes(attributes []Attribute) bool {
+	for _, attribute := range attributes {
+		if gen.hasGoFacets(attribute.Type, attribute.Restriction) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGoValidatedElements reports whether any of the elements has the facets
+// checked in the Validate methods, and records the types of the elements as
+// referenced by the type by given name. The alternatives of the choices and
+// the elements of the abstract types are skipped.
+func (gen *CodeGenerator) hasGoValidatedElements(elements []Element, refs map[string][]string, name string) (validated bool) {
+	for _, element := range elements {
+		if element.Choice != "" || gen.isAbstractType(element.Type) || gen.goSubstitutionHead(element) != "" {
+			continue
+		}
+		validated = validated || gen.hasGoFacets(element.Type, element.Restriction)
+		refs[name] = append(refs[name], element.Type)
+	}
+	return
+}
+
+// genGoComplexTypeValidate generates the Validate method of the complex type
+// by given type name, which checks the fields against the facets and calls
+// the Validate methods of the base type, groups, attribute groups and child
+// elements.
+func (gen *CodeGenerator) genGoComplexTypeValidate(typeName string, v *ComplexType) {
+	if !gen.isGoValidated(v.Name) {
+		return
+	}
+	var checks string
+	if v.Base != "" && !gen.hasSimpleContent(v) && gen.isGoValidated(v.Base) {
+		base := strings.TrimPrefix(gen.genGoFieldType(gen.getBaseType(v.Base)), "*")
+		checks += fmt.Sprintf("if err := v.%s.Validate(); err != nil {\n\treturn err\n}\n", base)
+	}
+	for _, attrGroup := range v.AttributeGroup {
+		checks += gen.genGoFieldValidation(typeName, gen.fieldName(genGoFieldName(attrGroup.Name)), gen.genGoFieldType(gen.getBaseType(attrGroup.Ref)), attrGroup.Ref, false, false, Restriction{})
+	}
+	checks += gen.genGoAttributesValidation(typeName, v.Attributes)
+	checks += gen.genGoGroupsValidation(typeName, v.Groups)
+	checks += gen.genGoElementsValidation(typeName, v.Elements)
+	gen.genGoValidate(typeName, "*"+typeName, checks)
+}
+
+// genGoAttributesValidation returns the statements validating the fields of
+// the attributes in the struct by given type name.
+func (gen *CodeGenerator) genGoAttributesValidation(typeName string, attributes []Attribute) (checks string) {
+	for _, attribute := range attributes {
+		checks += gen.genGoFieldValidation(typeName, gen.fieldName(genGoFieldName(attribute.Name)+"Attr"), gen.genGoAttributeFieldType(attribute), attribute.Type, false, attribute.Optional, attribute.Restriction)
+	}
+	return
+}
+
+// genGoGroupsValidation returns the statements calling the Validate methods
+// of the groups in the struct by given type name.
+func (gen *CodeGenerator) genGoGroupsValidation(typeName string, groups []Group) (checks string) {
+	for _, group := range groups {
+		checks += gen.genGoFieldValidation(typeName, gen.fieldName(genGoFieldName(group.Name)), gen.genGoFieldType(gen.getBaseType(group.Ref)), group.Ref, group.Plural, false, Restriction{})
+	}
+	return
+}
+
+// genGoElementsValidation returns the statements validating the fields of the
+// elements in the struct by given type name, the alternatives of the choices
+// and the elements of the abstract types are skipped.
+func (gen *CodeGenerator) genGoElementsValidation(typeName string, elements []Element) (checks string) {
+	for _, element := range elements {
+		if element.Choice != "" || gen.isAbstractType(element.Type) || gen.goSubstitutionHead(element) != "" {
+			continue
+		}
+		if gen.isGoNillable(element) {
+			checks += gen.genGoNillableValidation(typeName, element)
+			continue
+		}
+		checks += gen.genGoFieldValidation(typeName, gen.fieldName(genGoFieldName(element.Name)), gen.genGoElementFieldType(typeName, element), element.Type, element.Plural, false, element.Restriction)
+	}
+	return
+}
+
+// genGoNillableValidation returns the statements validating the Value of the
+// wrappers of the nillable element, the nil values are skipped.
+func (gen *CodeGenerator) genGoNillableValidation(typeName string, element Element) string {
+	fieldName := gen.fieldName(genGoFieldName(element.Name))
+	value := "v." + fieldName
+	if element.Plural {
+		value = "item"
+	}
+	valueType := "*" + strings.TrimPrefix(gen.genGoValueType(element.Type), "*")
+	checks := gen.genGoFieldValidation(typeName, fieldName+".Value", valueType, element.Type, false, false, element.Restriction)
+	if checks == "" {
+		return ""
+	}
+	checks = strings.Replace(checks, "v."+fieldName+".Value", value+".Value", -1)
+	switch {
+	case element.Plural:
+		checks = genGoBlock(fmt.Sprintf("for _, item := range v.%s", fieldName), checks)
+	case element.Optional:
+		checks = genGoBlock(fmt.Sprintf("if v.%s != nil", fieldName), checks)
+	}
+	return checks
+}
+
+// genGoFieldValidation returns the statements validating the field by given
+// type name, field name, Go type of the field, XSD type and facets of the
+// value. The items of the plural fields are validated one by one, the nil
+// pointers and the zero values of the optional fields are skipped.
+func (gen *CodeGenerator) genGoFieldValidation(typeName, fieldName, fieldType, xsdType string, plural, optional bool, restriction Restriction) string {
+	value := "v." + fieldName
+	if plural {
+		value = "item"
+	}
+	pointer := strings.HasPrefix(fieldType, "*")
+	var checks string
+	if gen.isGoValidated(xsdType) {
+		checks = fmt.Sprintf("if err := %s.Validate(); err != nil {\n\treturn err\n}\n", value)
+	} else {
+		elem := value
+		if pointer {
+			elem = "*" + value
+		}
+		if enumType, baseType := gen.goEnumType(xsdType); enumType != "" && strings.TrimPrefix(fieldType, "*") == enumType {
+			// the facets are checked on the value of the enumeration
+			// converted to its base type.
+			elem, fieldType = fmt.Sprintf("%s(%s)", baseType, elem), strings.Replace(fieldType, enumType, baseType, 1)
+		}
+		checks = gen.genGoFacetChecks(typeName+"."+fieldName, elem, strings.TrimPrefix(fieldType, "*"), gen.goFacets(xsdType, restriction))
+	}
+	if checks == "" {
+		return ""
+	}
+	switch {
+	case pointer:
+		checks = genGoBlock(fmt.Sprintf("if %s != nil", value), checks)
+	case optional && fieldType == "string":
+		checks = genGoBlock(fmt.Sprintf("if %s != \"\"", value), checks)
+	case optional && fieldType == "[]byte":
+		checks = genGoBlock(fmt.Sprintf("if len(%s) > 0", value), checks)
+	case optional:
+		checks = genGoBlock(fmt.Sprintf("if %s != 0", value), checks)
+	}
+	if plural {
+		checks = genGoBlock(fmt.Sprintf("for _, item := range v.%s", fieldName), checks)
+	}
+	return checks
+}
+
+// genGoFacetChecks returns the statements checking the value by given label in
+// the errors, expression and Go type of the value against the pattern, length
+// and range facets, returns empty string if none of the facets applies to the
+// type.
+func (gen *CodeGenerator) genGoFacetChecks(label, value, fieldType string, restriction Restriction) (checks string) {
+	fail := func(cond, format, arg string) {
+		checks += fmt.Sprintf("if %s {\n\treturn fmt.Errorf(%q, %s)\n}\n", cond, label+": "+format, arg)
+	}
+	switch fieldType {
+	case "string", "[]byte":
+		length := fmt.Sprintf("len(%s)", value)
+		if fieldType == "string" {
+			length = fmt.Sprintf("utf8.RuneCountInString(%s)", value)
+		}
+		if restriction.MinLength > 0 {
+			fail(fmt.Sprintf("%s < %d", length, restriction.MinLength), fmt.Sprintf("length %%d is less than %d", restriction.MinLength), length)
+		}
+		if restriction.MaxLength > 0 {
+			fail(fmt.Sprintf("%s > %d", length, restriction.MaxLength), fmt.Sprintf("length %%d is greater than %d", restriction.MaxLength), length)
+		}
+		if restriction.Pattern != nil && fieldType == "string" {
+			// The patterns of XSD are implicitly anchored at both ends.
+			pattern := "pattern" + strings.Replace(label, ".", "", -1)
+			gen.Field += fmt.Sprintf("\nvar %s = regexp.MustCompile(%q)\n", pattern, fmt.Sprintf("^(?:%s)$", restriction.Pattern.String()))
+			fail(fmt.Sprintf("!%s.MatchString(%s)", pattern, value), "%q doesn't match the pattern", value)
+		}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "float32", "float64":
+		if restriction.HasMin {
+			bound := strconv.FormatFloat(restriction.Min, 'g', -1, 64)
+			if restriction.MinExclusive {
+				fail(fmt.Sprintf("float64(%s) <= %s", value, bound), "%v is not greater than "+bound, value)
+			} else {
+				fail(fmt.Sprintf("float64(%s) < %s", value, bound), "%v is less than "+bound, value)
+			}
+		}
+		if restriction.HasMax {
+			bound := strconv.FormatFloat(restriction.Max, 'g', -1, 64)
+			if restriction.MaxExclusive {
+				fail(fmt.Sprintf("float64(%s) >= %s", value, bound), "%v is not less than "+bound, value)
+			} else {
+				fail(fmt.Sprintf("float64(%s) > %s", value, bound), "%v is greater than "+bound, value)
+			}
+		}
+	case "XsdInteger", "XsdDecimal":
+		// the arbitrary-precision numbers are compared in the lexical form.
+		number := fmt.Sprintf("string(%s)", value)
+		if fieldType == "XsdInteger" {
+			if strings.HasPrefix(value, "*") {
+				value = "(" + value + ")"
+			}
+			number = value + ".String()"
+		}
+		if restriction.HasMin {
+			bound := strconv.FormatFloat(restriction.Min, 'f', -1, 64)
+			if restriction.MinExclusive {
+				fail(fmt.Sprintf("compareXsdNumber(%s, %q) <= 0", number, bound), "%s is not greater than "+bound, number)
+			} else {
+				fail(fmt.Sprintf("compareXsdNumber(%s, %q) < 0", number, bound), "%s is less than "+bound, number)
+			}
+		}
+		if restriction.HasMax {
+			bound := strconv.FormatFloat(restriction.Max, 'f', -1, 64)
+			if restriction.MaxExclusive {
+				fail(fmt.Sprintf("compareXsdNumber(%s, %q) >= 0", number, bound), "%s is not less than "+bound, number)
+			} else {
+				fail(fmt.Sprintf("compareXsdNumber(%s, %q) > 0", number, bound), "%s is greater than "+bound, number)
+			}
+		}
 	}
 	return
 }
 
+// genGoBlock returns the statements enclosed in the block of the statement by
+// given head.
+func genGoBlock(head, body string) string {
+	return head + " {\n" + genGoIndent(body) + "}\n"
+}
+
+// genGoIndent returns the statements indented by one level.
+func genGoIndent(body string) string {
+	return "\t" + strings.Replace(strings.TrimSuffix(body, "\n"), "\n", "\n\t", -1) + "\n"
+}
+
+// genGoValidate generates the Validate method by given receiver and the
+// statements checking the value.
+func (gen *CodeGenerator) genGoValidate(typeName, receiver, checks string) {
+	if checks != "" {
+		checks = genGoIndent(checks)
+	}
+	gen.Field += fmt.Sprintf("\n// Validate checks the %s against the facets of the schema.\nfunc (v %s) Validate() error {\n%s\treturn nil\n}\n", typeName, receiver, checks)
+}
+
+// genGoListMethods generates the methods of the list type by given type name,
+// which encode and decode the items as the space-separated list in the
+// character data or attribute value.
+func (gen *CodeGenerator) genGoListMethods(typeName string) string {
+	return fmt.Sprintf(`
+// MarshalText encodes the %[1]s as the space-separated list.
+func (v %[1]s) MarshalText() ([]byte, error) {
+	items := make([]string, len(v))
+	for i, item := range v {
+		items[i] = fmt.Sprint(item)
+	}
+	return []byte(strings.Join(items, " ")), nil
+}
+
+// UnmarshalText decodes the space-separated list into the %[1]s.
+func (v *%[1]s) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	items := make(%[1]s, len(fields))
+	for i, field := range fields {
+		if _, err := fmt.Sscan(field, &items[i]); err != nil {
+			return err
+		}
+	}
+	*v = items
+	return nil
+}
+`, typeName)
+}
+
+// genGoEnumConstants generates typed constants for the enumeration values of
+// the simple type, returns empty string if any value can't be declared as
+// the constant of the base type.
+func (gen *CodeGenerator) genGoEnumConstants(typeName, fieldType string, enums []string) string {
+	if len(enums) == 0 {
+		return ""
+	}
+	var content string
+	for i, name := range enumNames(enums) {
+		value, ok := genGoConstantValue(fieldType, enums[i])
+		if !ok {
+			return ""
+		}
+		content += fmt.Sprintf("\t%s %s = %s\n", gen.constantName(typeName+name), typeName, value)
+	}
+	return fmt.Sprintf("\n// Enumeration values of %s.\nconst (\n%s)\n", typeName, content)
+}
+
+// genGoConstantValue returns the literal of the enumeration value in the Go
+// constant of given base type, the numeric and boolean values are normalized
+// to avoid such as the leading zeros being treated as octal literals.
+func genGoConstantValue(fieldType, value string) (string, bool) {
+	switch fieldType {
+	case "string":
+		return strconv.Quote(value), true
+	case "XsdDecimal":
+		if _, ok := new(big.Rat).SetString(value); ok {
+			return strconv.Quote(value), true
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b), true
+		}
+	case "int", "int8", "int16", "int32", "int64":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return strconv.FormatInt(i, 10), true
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		if u, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return strconv.FormatUint(u, 10), true
+		}
+	case "float32", "float64":
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64), true
+		}
+	}
+	return "", false
+}
+
 // GoComplexType generates code for complex type XML schema in Go language
 // syntax.
 func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := " struct {\n"
-		fieldName := genGoFieldName(v.Name)
-		if fieldName != v.Name {
-			gen.ImportEncodingXML = true
-			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
+		content, defaults := " struct {\n", ""
+		fieldName := gen.typeName(genGoFieldName(v.Name))
+		if base := trimNSPrefix(gen.complexBase(v)); gen.isGoConstructed(base) && !gen.goRequires(base, v.Name, map[string]bool{}) {
+			defaults += fmt.Sprintf("\tv.%[1]s = *New%[1]s()\n", strings.TrimPrefix(gen.genGoFieldType(gen.getBaseType(base)), "*"))
+		}
+		if element, ok := gen.globalElements()[v.Name]; ok && !element.Plural && trimNSPrefix(element.Type) == v.Name {
+			// the struct of the type declared in the global element is the
+			// wrapper of the element, the names of the other types are given
+			// by the fields referring to them.
+			content += fmt.Sprintf("\tXMLName\txml.Name%s\n", gen.genGoTags(genGoXMLName(element.Namespace, element.Name), "-", false))
+		}
+		content += gen.genGoBase(v)
+		if v.Mixed && !gen.hasSimpleContent(v) {
+			// the character data between the child elements of the mixed
+			// content.
+			content += fmt.Sprintf("\tValue\tstring%s\n", gen.genGoTags(",chardata", "value", false))
 		}
 		for _, attrGroup := range v.AttributeGroup {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			if fieldType == "time.Time" {
-				gen.ImportTime = true
-			}
-			content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(attrGroup.Name), genGoFieldType(fieldType))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(genGoFieldName(attrGroup.Name)), gen.genGoFieldType(fieldType), gen.genGoTags("", attrGroup.Name, false))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -192,18 +1036,17 @@ func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 			if attribute.Optional {
 				optional = `,omitempty`
 			}
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			if fieldType == "time.Time" {
-				gen.ImportTime = true
-			}
-			content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", genGoFieldName(attribute.Name), fieldType, attribute.Name, optional)
+			fieldType := gen.genGoAttributeFieldType(attribute)
+			name := gen.fieldName(genGoFieldName(attribute.Name) + "Attr")
+			content += fmt.Sprintf("\t%s\t%s%s\n", name, fieldType, gen.genGoTags(genGoXMLName(attribute.Namespace, attribute.Name)+",attr"+optional, genGoAttributeKey(attribute.Name, v.Elements), attribute.Optional))
+			defaults += gen.genGoDefault(name, fieldType, attribute.Type, attribute.Default, attribute.Fixed)
 		}
 		for _, group := range v.Groups {
 			var plural string
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(group.Name), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", gen.fieldName(genGoFieldName(group.Name)), plural, gen.genGoFieldType(gen.getBaseType(group.Ref)), gen.genGoTags("", group.Name, group.Plural))
 		}
 
 		for _, element := range v.Elements {
@@ -211,34 +1054,425 @@ func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 			if element.Plural {
 				plural = "[]"
 			}
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
-			if fieldType == "time.Time" {
-				gen.ImportTime = true
+			fieldType := gen.genGoElementFieldType(fieldName, element)
+			var optional string
+			if gen.OptionalPointers && element.Optional {
+				optional = `,omitempty`
+			}
+			xmlTag := genGoXMLName(element.Namespace, element.Name) + optional
+			if gen.goSubstitutionHead(element) != "" {
+				// the head element or any element in its substitution group.
+				xmlTag = ",any"
+			}
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", gen.fieldName(genGoFieldName(element.Name)), plural, fieldType, gen.genGoTags(xmlTag, element.Name, element.Optional || element.Plural))
+			if !element.Plural && element.Choice == "" && !gen.isGoNillable(element) {
+				defaults += gen.genGoDefault(gen.fieldName(genGoFieldName(element.Name)), fieldType, element.Type, element.Default, element.Fixed)
+			}
+			if required := gen.goRequiredType(element); required != "" && !gen.goRequires(required, v.Name, map[string]bool{}) {
+				defaults += gen.genGoAllocation(gen.fieldName(genGoFieldName(element.Name)), fieldType, required)
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\t`xml:\"%s\"`\n", genGoFieldName(element.Name), plural, fieldType, element.Name)
 		}
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		if defaults != "" {
+			gen.Field += fmt.Sprintf("\n// New%s returns the %s with the default values and the allocated\n// required child elements of the schema.\nfunc New%s() *%s {\n\tv := &%s{}\n%s\treturn v\n}\n", fieldName, fieldName, fieldName, fieldName, fieldName, defaults)
+		}
+		gen.genGoChoices(fieldName, v.Choices, v.Elements)
+		gen.genGoNillables(fieldName, v.Elements)
+		if v.Abstract {
+			gen.genGoAbstractType(fieldName, v)
+		}
+		if len(v.Assertions) > 0 {
+			gen.Field += gen.genGoAssertions(fieldName, v.Assertions)
+		}
+		gen.genGoComplexTypeValidate(fieldName, v)
 	}
 	return
 }
 
+// genGoDefault returns the statements assigning the default or fixed value
+// to the field by given name in the constructor, returns empty string if the
+// value can't be represented as a literal of the field type.
+func (gen *CodeGenerator) genGoDefault(name, fieldType, typeName, defaultValue, fixed string) string {
+	value, ok := valueConstraint(defaultValue, fixed)
+	if !ok {
+		return ""
+	}
+	literal, ok := genGoConstantValue(gen.genGoFieldType(gen.getSimpleBaseType(typeName)), value)
+	if !ok {
+		return ""
+	}
+	if strings.HasPrefix(fieldType, "*") {
+		return fmt.Sprintf("\tv.%s = new(%s)\n\t*v.%s = %s\n", name, strings.TrimPrefix(fieldType, "*"), name, literal)
+	}
+	return fmt.Sprintf("\tv.%s = %s\n", name, literal)
+}
+
+// genGoAllocation returns the statement allocating the required child element
+// of the complex type by given name in the constructor, by the constructor of
+// the complex type if it's generated.
+func (gen *CodeGenerator) genGoAllocation(name, fieldType, typeName string) string {
+	fieldType = strings.TrimPrefix(fieldType, "*")
+	if gen.isGoConstructed(typeName) {
+		return fmt.Sprintf("\tv.%s = New%s()\n", name, fieldType)
+	}
+	return fmt.Sprintf("\tv.%s = &%s{}\n", name, fieldType)
+}
+
+// goRequiredType returns the name of the complex type of the element which is
+// allocated by the constructor, or empty string if the element is optional,
+// nillable, repeated, an alternative of the choices, or isn't the concrete
+// complex type declared in the proto tree.
+func (gen *CodeGenerator) goRequiredType(element Element) string {
+	if element.Optional || element.Nillable || element.Plural || element.Choice != "" || gen.goSubstitutionHead(element) != "" {
+		return ""
+	}
+	name := trimNSPrefix(element.Type)
+	if _, ok := gen.TypeMapping[name]; ok {
+		return ""
+	}
+	if v := gen.getComplexType(name); v != nil && !v.Abstract {
+		return name
+	}
+	return ""
+}
+
+// goRequires reports whether the constructor of the complex type by given
+// name reaches the complex type by given type name through the base type and
+// the required child elements, the visited types are skipped. The types
+// reaching back to the type being constructed aren't allocated, otherwise the
+// constructors recurse infinitely.
+func (gen *CodeGenerator) goRequires(name, typeName string, visited map[string]bool) bool {
+	if name == typeName {
+		return true
+	}
+	v := gen.getComplexType(name)
+	if v == nil || visited[name] {
+		return false
+	}
+	visited[name] = true
+	if base := trimNSPrefix(gen.complexBase(v)); base != "" && gen.goRequires(base, typeName, visited) {
+		return true
+	}
+	for _, element := range v.Elements {
+		if required := gen.goRequiredType(element); required != "" && gen.goRequires(required, typeName, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGoConstructed reports whether the constructor is generated for the
+// complex type declared in the proto tree by given name.
+func (gen *CodeGenerator) isGoConstructed(name string) bool {
+	if gen.goConstructed == nil {
+		gen.goConstructed = gen.goConstructedTypes()
+	}
+	return gen.goConstructed[name]
+}
+
+// goConstructedTypes returns the names of the complex types in the proto tree
+// which have the members with default or fixed values, the required child
+// elements, or the base type with the constructor.
+func (gen *CodeGenerator) goConstructedTypes() map[string]bool {
+	constructed, bases := map[string]bool{}, map[string]string{}
+	for _, ele := range gen.ProtoTree {
+		v, ok := ele.(*ComplexType)
+		if !ok {
+			continue
+		}
+		for _, attribute := range v.Attributes {
+			constructed[v.Name] = constructed[v.Name] || gen.hasGoDefault(attribute.Type, attribute.Default, attribute.Fixed)
+		}
+		for _, element := range v.Elements {
+			if element.Plural || element.Choice != "" {
+				continue
+			}
+			constructed[v.Name] = constructed[v.Name] || gen.hasGoDefault(element.Type, element.Default, element.Fixed)
+			if required := gen.goRequiredType(element); required != "" && !gen.goRequires(required, v.Name, map[string]bool{}) {
+				constructed[v.Name] = true
+			}
+		}
+		if base := trimNSPrefix(gen.complexBase(v)); base != "" && !gen.goRequires(base, v.Name, map[string]bool{}) {
+			bases[v.Name] = base
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, base := range bases {
+			if !constructed[name] && constructed[base] {
+				constructed[name], changed = true, true
+			}
+		}
+	}
+	return constructed
+}
+
+// hasGoDefault reports whether the default or fixed value could be assigned
+// to the member of the type by given name in the constructor.
+func (gen *CodeGenerator) hasGoDefault(typeName, defaultValue, fixed string) bool {
+	value, ok := valueConstraint(defaultValue, fixed)
+	if !ok {
+		return false
+	}
+	fieldType := gen.getSimpleBaseType(typeName)
+	if mapping, ok := gen.TypeMapping[fieldType]; ok {
+		fieldType = mapping.Type
+	}
+	_, ok = genGoConstantValue(fieldType, value)
+	return ok
+}
+
+// genGoElementFieldType returns the type of the field for the element in the
+// struct by given type name, without the slice of the plural element.
+func (gen *CodeGenerator) genGoElementFieldType(typeName string, element Element) string {
+	if element.Choice != "" {
+		return gen.genGoChoiceFieldType(typeName, element)
+	}
+	if head := gen.goSubstitutionHead(element); head != "" {
+		if element.Plural {
+			return "Any" + gen.typeName(genGoFieldName(head))
+		}
+		return "*Any" + gen.typeName(genGoFieldName(head))
+	}
+	if gen.isGoNillable(element) {
+		if element.Optional && !element.Plural {
+			return "*" + gen.goNillableName(typeName, element)
+		}
+		return gen.goNillableName(typeName, element)
+	}
+	fieldType := gen.genGoValueType(element.Type)
+	if gen.isAbstractType(element.Type) {
+		fieldType = "*Any" + strings.TrimPrefix(fieldType, "*")
+	}
+	if gen.isGoPointerElement(element, fieldType) {
+		fieldType = "*" + fieldType
+	}
+	return fieldType
+}
+
+// genGoAttributeFieldType returns the type of the field for the attribute in
+// the struct, the optional attributes are pointers which are nil if absent
+// when generating with the optional pointers.
+func (gen *CodeGenerator) genGoAttributeFieldType(attribute Attribute) string {
+	fieldType := gen.genGoValueType(attribute.Type)
+	if gen.OptionalPointers && attribute.Optional && !strings.HasPrefix(fieldType, "*") && !strings.HasPrefix(fieldType, "[]") {
+		return "*" + fieldType
+	}
+	return fieldType
+}
+
+// genGoValueType returns the Go type of the value by given XSD type, the
+// enumerations are referred by their types with the typed constants.
+func (gen *CodeGenerator) genGoValueType(name string) string {
+	if enumType, _ := gen.goEnumType(name); enumType != "" {
+		return enumType
+	}
+	return gen.genGoFieldType(gen.getBaseType(name))
+}
+
+// goEnumType returns the Go type generated with the typed constants for the
+// enumeration by given XSD type and the Go type of its values, or empty
+// strings if the type isn't an enumeration declared in the schema.
+func (gen *CodeGenerator) goEnumType(name string) (enumType, baseType string) {
+	name = trimNSPrefix(name)
+	if _, ok := gen.TypeMapping[name]; ok {
+		return
+	}
+	v := gen.getSimpleType(name)
+	if v == nil || v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		return
+	}
+	return gen.typeName(genGoFieldName(v.Name)), gen.genGoFieldType(gen.getBaseType(v.Base))
+}
+
+// isGoPointerElement returns if the field of the element by given field type
+// should be a pointer, the optional single elements are nil if absent.
+func (gen *CodeGenerator) isGoPointerElement(element Element, fieldType string) bool {
+	if element.Plural || strings.HasPrefix(fieldType, "*") || strings.HasPrefix(fieldType, "[]") {
+		return false
+	}
+	return element.Optional
+}
+
+// isGoNillable returns if the element is held by a nillable wrapper, which
+// decodes and encodes the xsi:nil attribute.
+func (gen *CodeGenerator) isGoNillable(element Element) bool {
+	return element.Nillable && element.Choice == "" && gen.goSubstitutionHead(element) == "" && !gen.isAbstractType(element.Type)
+}
+
+// goNillableName returns the name of the nillable wrapper of the element in
+// the struct by given type name.
+func (gen *CodeGenerator) goNillableName(typeName string, element Element) string {
+	return typeName + gen.fieldName(genGoFieldName(element.Name)) + "Nillable"
+}
+
+// genGoNillables generates the wrappers of the nillable elements in the
+// struct by given type name, the Value of the wrapper is nil if the element
+// has the xsi:nil attribute.
+func (gen *CodeGenerator) genGoNillables(typeName string, elements []Element) {
+	for _, element := range elements {
+		if !gen.isGoNillable(element) {
+			continue
+		}
+		name := gen.goNillableName(typeName, element)
+		gen.Field += fmt.Sprintf(`
+// %[1]s holds the nillable %[2]s element of %[3]s,
+// the Value is nil if the element has the xsi:nil attribute.
+type %[1]s struct {
+	Value *%[4]s
+}
+
+// UnmarshalXML decodes the %[2]s element, which is nil if it has the xsi:nil
+// attribute.
+func (v *%[1]s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "http://www.w3.org/2001/XMLSchema-instance" && attr.Name.Local == "nil" && (attr.Value == "true" || attr.Value == "1") {
+			v.Value = nil
+			return d.Skip()
+		}
+	}
+	v.Value = new(%[4]s)
+	return d.DecodeElement(v.Value, &start)
+}
+
+// MarshalXML encodes the %[2]s element, which has the xsi:nil attribute if the
+// Value is nil.
+func (v %[1]s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.Value != nil {
+		return e.EncodeElement(v.Value, start)
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"}, xml.Attr{Name: xml.Name{Local: "xsi:nil"}, Value: "true"})
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+`, name, element.Name, typeName, strings.TrimPrefix(gen.genGoValueType(element.Type), "*"))
+	}
+}
+
+// IsValidStructTag reports whether the given struct tag could be generated
+// alongside the xml tags in Go.
+func IsValidStructTag(tag string) bool {
+	switch tag {
+	case "json", "yaml":
+		return true
+	}
+	return false
+}
+
+// genGoTags returns the struct tags of the field by given value of the xml
+// tag, and the name of the member for the keys of the tags specified by the
+// StructTags, which are converted by the naming convention of the tags. The
+// key "-" excludes the field, and the optional members are omitted if empty.
+func (gen *CodeGenerator) genGoTags(xmlTag, name string, optional bool) string {
+	var tags []string
+	if xmlTag != "" {
+		tags = append(tags, fmt.Sprintf("xml:%q", xmlTag))
+	}
+	key := name
+	if key != "-" {
+		key = ConvertCase(trimNSPrefix(name), gen.Naming.Tag)
+		if optional {
+			key += ",omitempty"
+		}
+	}
+	for _, tag := range gen.StructTags {
+		tags = append(tags, fmt.Sprintf("%s:%q", tag, key))
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return "\t`" + strings.Join(tags, " ") + "`"
+}
+
+// genGoAttributeKey returns the name of the attribute for the keys of the
+// struct tags, which is suffixed with Attr if an element in the same struct
+// has the name.
+func genGoAttributeKey(name string, elements []Element) string {
+	for _, element := range elements {
+		if trimNSPrefix(element.Name) == trimNSPrefix(name) {
+			return trimNSPrefix(name) + "Attr"
+		}
+	}
+	return name
+}
+
+// genGoXMLName returns the name in the struct tag by given namespace and local
+// name, the name is qualified with the namespace URI separated by a space
+// instead of the namespace prefix.
+func genGoXMLName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + " " + trimNSPrefix(name)
+}
+
+// genGoBase returns the field of the base type which the complex type extends,
+// the complex base type is embedded, and the value of the simple content is
+// the character data of the element.
+func (gen *CodeGenerator) genGoBase(v *ComplexType) string {
+	if v.Base == "" {
+		return ""
+	}
+	fieldType := gen.genGoFieldType(gen.getBaseType(v.Base))
+	if gen.hasSimpleContent(v) {
+		return fmt.Sprintf("\tValue\t%s%s\n", fieldType, gen.genGoTags(",chardata", "value", false))
+	}
+	return fmt.Sprintf("\t%s\n", strings.TrimPrefix(fieldType, "*"))
+}
+
+// genGoAbstractType generates the type holds the value of the abstract complex
+// type by given type name, and the UnmarshalXML method decodes the element
+// into the derived type named in the xsi:type attribute. The element without
+// the xsi:type attribute or with an unknown type is decoded into the abstract
+// type. The MarshalXML method encodes the value with the xsi:type attribute
+// naming its derived type.
+func (gen *CodeGenerator) genGoAbstractType(typeName string, v *ComplexType) {
+	var decodeCases, encodeCases, copies string
+	for _, derived := range gen.derivedTypes(v.Name) {
+		if derived.Abstract {
+			continue
+		}
+		derivedName := gen.typeName(genGoFieldName(derived.Name))
+		decodeCases += fmt.Sprintf("\tcase %q:\n\t\tv.Value = &%s{}\n", derived.Name, derivedName)
+		attrs, value := `xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"}`, derived.Name
+		if derived.Namespace != "" {
+			attrs += fmt.Sprintf(`, xml.Attr{Name: xml.Name{Local: "xmlns:ns"}, Value: %q}`, derived.Namespace)
+			value = "ns:" + derived.Name
+		}
+		encodeCases += fmt.Sprintf("\tcase %s, *%s:\n\t\tstart.Attr = append(start.Attr, %s, xml.Attr{Name: xml.Name{Local: \"xsi:type\"}, Value: %q})\n", derivedName, derivedName, attrs, value)
+		copies += fmt.Sprintf("\tcase *%s:\n\t\tout.Value = v.DeepCopy()\n", derivedName)
+	}
+	anyName := "Any" + typeName
+	gen.Field += fmt.Sprintf("\n// %s holds the value of %s or the type derived from it, which is\n// decoded by the type named in the xsi:type attribute.\ntype %s struct {\n\tValue interface{}\n}\n", anyName, typeName, anyName)
+	gen.Field += fmt.Sprintf("\n// UnmarshalXML decodes the element into the type named in the xsi:type\n// attribute.\nfunc (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n\tvar xsiType string\n\tfor _, attr := range start.Attr {\n\t\tif attr.Name.Space == \"http://www.w3.org/2001/XMLSchema-instance\" && attr.Name.Local == \"type\" {\n\t\t\txsiType = attr.Value[strings.Index(attr.Value, \":\")+1:]\n\t\t}\n\t}\n\tswitch xsiType {\n%s\tdefault:\n\t\tv.Value = &%s{}\n\t}\n\treturn d.DecodeElement(v.Value, &start)\n}\n", anyName, decodeCases, typeName)
+	gen.Field += fmt.Sprintf("\n// MarshalXML encodes the value as the element with the xsi:type attribute\n// naming its derived type, the nil value is omitted.\nfunc (v %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n\tswitch v.Value.(type) {\n\tcase nil:\n\t\treturn nil\n%s\t}\n\treturn e.EncodeElement(v.Value, start)\n}\n", anyName, encodeCases)
+	if gen.DeepCopy {
+		// the types of the values held by the interface are only known here,
+		// the DeepCopy method is generated along with the other structs.
+		gen.Field += fmt.Sprintf("\n// DeepCopyInto copies the %[1]s into out, which shares no references\n// with the receiver.\nfunc (in *%[1]s) DeepCopyInto(out *%[1]s) {\n\t*out = *in\n\tswitch v := in.Value.(type) {\n\tcase *%[2]s:\n\t\tout.Value = v.DeepCopy()\n%[3]s\t}\n}\n", anyName, typeName, copies)
+	}
+}
+
 // GoGroup generates code for group XML schema in Go language syntax.
 func (gen *CodeGenerator) GoGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " struct {\n"
-		fieldName := genGoFieldName(v.Name)
-		if fieldName != v.Name {
-			gen.ImportEncodingXML = true
-			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
-		}
+		fieldName := gen.typeName(genGoFieldName(v.Name))
 		for _, element := range v.Elements {
 			var plural string
 			if element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(element.Name), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)))
+			fieldType := gen.genGoElementFieldType(fieldName, element)
+			var xmlTag string
+			if gen.goSubstitutionHead(element) != "" {
+				xmlTag = ",any"
+			}
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", gen.fieldName(genGoFieldName(element.Name)), plural, fieldType, gen.genGoTags(xmlTag, element.Name, element.Optional || element.Plural))
 		}
 
 		for _, group := range v.Groups {
@@ -246,36 +1480,90 @@ func (gen *CodeGenerator) GoGroup(v *Group) {
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(group.Name), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", gen.fieldName(genGoFieldName(group.Name)), plural, gen.genGoFieldType(gen.getBaseType(group.Ref)), gen.genGoTags("", group.Name, group.Plural))
 		}
 
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genGoChoices(fieldName, v.Choices, v.Elements)
+		gen.genGoNillables(fieldName, v.Elements)
+		if gen.isGoValidated(v.Name) {
+			gen.genGoValidate(fieldName, "*"+fieldName, gen.genGoGroupsValidation(fieldName, v.Groups)+gen.genGoElementsValidation(fieldName, v.Elements))
+		}
 	}
 	return
 }
 
+// genGoChoiceFieldType returns the type of the field for the alternative
+// element of the choice in the struct by given type name, the single
+// alternative is a pointer which is nil if it's absent.
+func (gen *CodeGenerator) genGoChoiceFieldType(typeName string, element Element) string {
+	fieldType := typeName + gen.fieldName(genGoFieldName(element.Name))
+	if element.Plural {
+		return fieldType
+	}
+	return "*" + fieldType
+}
+
+// genGoChoices generates the interfaces of the choices in the struct by given
+// type name. The alternatives of a choice are the types defined on the types
+// of the elements implementing the interface, and the method named after the
+// choice returns the present alternative, or all of them in the order of the
+// alternatives if they are repeatable.
+func (gen *CodeGenerator) genGoChoices(typeName string, choices []Choice, elements []Element) {
+	for _, choice := range choices {
+		choiceName := typeName + choice.Name
+		alternatives := choiceAlternatives(choice, elements)
+		plural := choice.Plural
+		var names []string
+		for _, element := range alternatives {
+			names = append(names, element.Name)
+			plural = plural || element.Plural
+		}
+		gen.Field += fmt.Sprintf("\n// %s is one of the %s elements in %s.\ntype %s interface {\n\tis%s()\n}\n", choiceName, strings.Join(names, ", "), typeName, choiceName, choiceName)
+		var body string
+		for _, element := range alternatives {
+			fieldName := gen.fieldName(genGoFieldName(element.Name))
+			variant := typeName + fieldName
+			gen.Field += fmt.Sprintf("\n// %s is the %s alternative of %s.\ntype %s %s\n\nfunc (%s) is%s() {}\n", variant, element.Name, choiceName, variant, strings.TrimPrefix(gen.genGoFieldType(gen.getBaseType(element.Type)), "*"), variant, choiceName)
+			switch {
+			case element.Plural:
+				body += fmt.Sprintf("\tfor _, alternative := range v.%s {\n\t\talternatives = append(alternatives, alternative)\n\t}\n", fieldName)
+			case plural:
+				body += fmt.Sprintf("\tif v.%s != nil {\n\t\talternatives = append(alternatives, v.%s)\n\t}\n", fieldName, fieldName)
+			default:
+				body += fmt.Sprintf("\tif v.%s != nil {\n\t\treturn v.%s\n\t}\n", fieldName, fieldName)
+			}
+		}
+		if plural {
+			gen.Field += fmt.Sprintf("\n// %s returns the present alternatives of %s.\nfunc (v *%s) %s() (alternatives []%s) {\n%s\treturn\n}\n", choice.Name, choiceName, typeName, choice.Name, choiceName, body)
+			continue
+		}
+		gen.Field += fmt.Sprintf("\n// %s returns the present alternative of %s, or nil.\nfunc (v *%s) %s() %s {\n%s\treturn nil\n}\n", choice.Name, choiceName, typeName, choice.Name, choiceName, body)
+	}
+}
+
 // GoAttributeGroup generates code for attribute group XML schema in Go language
 // syntax.
 func (gen *CodeGenerator) GoAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " struct {\n"
-		fieldName := genGoFieldName(v.Name)
-		if fieldName != v.Name {
-			gen.ImportEncodingXML = true
-			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
-		}
+		fieldName := gen.typeName(genGoFieldName(v.Name))
 		for _, attribute := range v.Attributes {
 			var optional string
 			if attribute.Optional {
 				optional = `,omitempty`
 			}
-			content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", genGoFieldName(attribute.Name), genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)), attribute.Name, optional)
+			fieldType := gen.genGoAttributeFieldType(attribute)
+			content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(genGoFieldName(attribute.Name)+"Attr"), fieldType, gen.genGoTags(genGoXMLName(attribute.Namespace, attribute.Name)+",attr"+optional, attribute.Name, attribute.Optional))
 		}
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		if gen.isGoValidated(v.Name) {
+			gen.genGoValidate(fieldName, "*"+fieldName, gen.genGoAttributesValidation(fieldName, v.Attributes))
+		}
 	}
 	return
 }
@@ -287,14 +1575,134 @@ func (gen *CodeGenerator) GoElement(v *Element) {
 		if v.Plural {
 			plural = "[]"
 		}
-		content := fmt.Sprintf("\t%s%s\n", plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
+		fieldType, fieldName := gen.genGoFieldType(gen.getBaseType(v.Type)), gen.typeName(genGoFieldName(v.Name))
+		heads, members := substitutionHeads(v, gen.globalElements()), gen.substitutionMembers(v.Name)
+		if len(heads) > 0 || len(members) > 0 {
+			// the methods can't be declared on the pointer types.
+			fieldType = strings.TrimPrefix(fieldType, "*")
+		}
+		if fieldType == fieldName && len(heads) > 0 {
+			// the element declared without the type has the type of the head
+			// of its substitution group.
+			fieldType = "string"
+			if headType := gen.substitutionType(v); headType != v.Type {
+				fieldType = strings.TrimPrefix(gen.genGoFieldType(gen.getBaseType(headType)), "*")
+			}
+		}
+		content := fmt.Sprintf("\t%s%s\n", plural, fieldType)
+		if complexType := gen.getComplexType(gen.getBaseType(gen.substitutionType(v))); complexType != nil && !v.Plural {
+			// the wrapper of the element embeds the struct of the complex
+			// type, and holds the name of the element which the complex type
+			// shared by the elements hasn't.
+			embedded := strings.TrimPrefix(fieldType, "*")
+			if complexType.Abstract {
+				embedded = "Any" + embedded
+			}
+			content = fmt.Sprintf(" struct {\n\tXMLName\txml.Name%s\n\t%s\n}\n", gen.genGoTags(genGoXMLName(v.Namespace, v.Name), "-", false), embedded)
+		}
 		gen.StructAST[v.Name] = content
-		fieldName := genGoFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genGoSubstitutionGroup(v, heads, members)
 	}
 	return
 }
 
+// genGoSubstitutionGroup generates the interface of the substitution group
+// headed by the element, and the methods implement the interfaces of the
+// substitution groups which the element could be substituted for.
+func (gen *CodeGenerator) genGoSubstitutionGroup(v *Element, heads []string, members []*Element) {
+	fieldName := gen.typeName(genGoFieldName(v.Name))
+	if len(members) > 0 {
+		var names []string
+		for _, member := range members {
+			names = append(names, member.Name)
+		}
+		groupName := fieldName + "Group"
+		gen.Field += fmt.Sprintf("\n// %s is implemented by the %s element and the elements in its substitution\n// group: %s.\ntype %s interface {\n\tis%s()\n}\n", groupName, v.Name, strings.Join(names, ", "), groupName, groupName)
+		heads = append([]string{v.Name}, heads...)
+	}
+	for _, head := range heads {
+		groupName := gen.typeName(genGoFieldName(head)) + "Group"
+		gen.Field += fmt.Sprintf("\nfunc (%s) is%s() {}\n", fieldName, groupName)
+	}
+	if len(members) > 0 {
+		gen.genGoSubstitutionHolder(v, members)
+	}
+}
+
+// genGoSubstitutionHolder generates the type holds the head element or an
+// element in its substitution group, which is the type of the fields refer
+// to the head element. The UnmarshalXML method decodes the element into the
+// type of the element by its name, and the MarshalXML method encodes the
+// value as the element of its type.
+func (gen *CodeGenerator) genGoSubstitutionHolder(v *Element, members []*Element) {
+	fieldName := gen.typeName(genGoFieldName(v.Name))
+	holderName, groupName := "Any"+fieldName, fieldName+"Group"
+	var decodeCases, encodeCases string
+	for _, element := range append([]*Element{v}, members...) {
+		elementName := gen.typeName(genGoFieldName(element.Name))
+		decodeCases += fmt.Sprintf("\tcase %q:\n\t\tv.Value = new(%s)\n", element.Name, elementName)
+		encodeCases += fmt.Sprintf("\tcase %s, *%s:\n\t\tstart.Name = %s\n", elementName, elementName, genGoXMLNameLiteral(element.Namespace, element.Name))
+	}
+	gen.Field += fmt.Sprintf("\n// %s holds the %s element or an element in its substitution group,\n// which is decoded by the name of the element. The Value is nil if the\n// element is unknown.\ntype %s struct {\n\tValue %s\n}\n", holderName, v.Name, holderName, groupName)
+	gen.Field += fmt.Sprintf("\n// UnmarshalXML decodes the element into the type of the element by its\n// name.\nfunc (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n\tswitch start.Name.Local {\n%s\tdefault:\n\t\treturn d.Skip()\n\t}\n\treturn d.DecodeElement(v.Value, &start)\n}\n", holderName, decodeCases)
+	gen.Field += fmt.Sprintf("\n// MarshalXML encodes the value as the element of its type.\nfunc (v %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n\tswitch v.Value.(type) {\n%s\tdefault:\n\t\treturn nil\n\t}\n\treturn e.EncodeElement(v.Value, start)\n}\n", holderName, encodeCases)
+}
+
+// genGoDecodeEach generates the function decodes each element by given
+// global element in the document one by one, which streams the tokens of the
+// document by the xml.Decoder instead of loading it entirely.
+func (gen *CodeGenerator) genGoDecodeEach(v *Element) {
+	if v.Plural {
+		return
+	}
+	elementType := gen.substitutionType(v)
+	fieldName := gen.typeName(genGoFieldName(v.Name))
+	typeName := fieldName
+	if content, ok := gen.StructAST[v.Name]; !ok || strings.HasPrefix(content, "\t*") {
+		// the wrapper of the element isn't generated, or is the pointer type
+		// which can't be decoded into.
+		typeName = strings.TrimPrefix(gen.genGoFieldType(gen.getBaseType(elementType)), "*")
+	}
+	cond := fmt.Sprintf("start.Name.Local != %q", v.Name)
+	if v.Namespace != "" {
+		cond += fmt.Sprintf(" || start.Name.Space != %q", v.Namespace)
+	}
+	gen.Field += fmt.Sprintf("\n// DecodeEach%[1]s decodes each %[2]s element in the document read from r one\n// by one and calls fn with it, so that the large documents of the repeated\n// elements could be processed without loading them entirely. It stops at the\n// first error returned by fn.\nfunc DecodeEach%[1]s(r io.Reader, fn func(*%[3]s) error) error {\n\td := xml.NewDecoder(r)\n\tfor {\n\t\ttoken, err := d.Token()\n\t\tif err == io.EOF {\n\t\t\treturn nil\n\t\t}\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n\t\tstart, ok := token.(xml.StartElement)\n\t\tif !ok || %[4]s {\n\t\t\tcontinue\n\t\t}\n\t\tv := new(%[3]s)\n\t\tif err = d.DecodeElement(v, &start); err != nil {\n\t\t\treturn err\n\t\t}\n\t\tif err = fn(v); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n}\n", fieldName, v.Name, typeName, cond)
+}
+
+// genGoXMLNameLiteral returns the literal of the xml.Name by given namespace
+// and local name.
+func genGoXMLNameLiteral(space, local string) string {
+	if space == "" {
+		return fmt.Sprintf("xml.Name{Local: %q}", local)
+	}
+	return fmt.Sprintf("xml.Name{Space: %q, Local: %q}", space, local)
+}
+
+// goSubstitutionHead returns the name of the head element if the element in
+// the struct refers to the head of a substitution group declared in the
+// proto tree, the field of the element holds the head element or any element
+// in its substitution group.
+func (gen *CodeGenerator) goSubstitutionHead(element Element) string {
+	if element.Choice != "" {
+		return ""
+	}
+	if gen.goSubstitutionHeads == nil {
+		gen.goSubstitutionHeads = map[string]string{}
+		for name, head := range gen.globalElements() {
+			if len(gen.substitutionMembers(name)) > 0 {
+				gen.goSubstitutionHeads[name] = trimNSPrefix(head.Type)
+			}
+		}
+	}
+	name := trimNSPrefix(element.Name)
+	if headType, ok := gen.goSubstitutionHeads[name]; ok && headType == trimNSPrefix(element.Type) {
+		return name
+	}
+	return ""
+}
+
 // GoAttribute generates code for attribute XML schema in Go language syntax.
 func (gen *CodeGenerator) GoAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
@@ -302,10 +1710,10 @@ func (gen *CodeGenerator) GoAttribute(v *Attribute) {
 		if v.Plural {
 			plural = "[]"
 		}
-		content := fmt.Sprintf("\t%s%s\n", plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
+		content := fmt.Sprintf("\t%s%s\n", plural, gen.genGoFieldType(gen.getBaseType(v.Type)))
 		gen.StructAST[v.Name] = content
-		fieldName := genGoFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genGoFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -0,0 +1,302 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var graphqlBuildInType = map[string]bool{
+	"Any":      true,
+	"Boolean":  true,
+	"Date":     true,
+	"DateTime": true,
+	"Decimal":  true,
+	"Float":    true,
+	"ID":       true,
+	"Int":      true,
+	"Long":     true,
+	"String":   true,
+	"Time":     true,
+	"[String]": true,
+}
+
+// graphqlScalarType defines the built-in types which aren't the standard
+// scalars of GraphQL, the custom scalars are declared if they're used.
+var graphqlScalarType = map[string]bool{
+	"Any":      true,
+	"Date":     true,
+	"DateTime": true,
+	"Decimal":  true,
+	"Long":     true,
+	"Time":     true,
+}
+
+// GenGraphQL generate GraphQL schema definition language (SDL) for XML schema
+// definition files. The complex types, groups and attribute groups are
+// generated as object types, and the enumerations are generated as enums.
+// There are no aliases in GraphQL, so the other simple types are replaced by
+// the scalar types or lists, and the global elements and attributes are
+// referred by their types.
+func (gen *CodeGenerator) GenGraphQL() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("GraphQL%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var scalars []string
+	for name := range gen.ImportBuildIn {
+		scalars = append(scalars, name)
+	}
+	for _, mapping := range gen.getImportMappings() {
+		scalars = append(scalars, mapping.Type)
+	}
+	sort.Strings(scalars)
+	var scalar string
+	for i, name := range scalars {
+		if i > 0 && scalars[i-1] == name {
+			continue
+		}
+		scalar += fmt.Sprintf("scalar %s\n", name)
+	}
+	if scalar != "" {
+		scalar = "\n" + scalar
+	}
+	source := []byte(fmt.Sprintf("# %s\n%s%s", strings.TrimPrefix(copyright, "// "), scalar, strings.Replace(gen.Field, "\r\n", "\n", -1)))
+	return gen.writeFile(gen.File+".graphql", source)
+}
+
+func genGraphQLFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genGraphQLFieldType generates the type for GraphQL by given type name, the
+// enumerations are referred by the enum types, the lists are the list types
+// of the items, the unions are the strings in the lexical form, and the other
+// restrictions are replaced by their base types. The custom scalars used are
+// recorded.
+func (gen *CodeGenerator) genGraphQLFieldType(name string) string {
+	if v := gen.getSimpleType(trimNSPrefix(name)); v != nil {
+		switch {
+		case v.List:
+			return fmt.Sprintf("[%s!]", gen.genGraphQLFieldType(v.Base))
+		case v.Union:
+			return "String"
+		case len(v.Restriction.Enum) > 0:
+			return gen.typeName(genGraphQLFieldName(v.Name))
+		}
+	}
+	name = gen.getBaseType(name)
+	if _, ok := graphqlBuildInType[name]; ok {
+		if graphqlScalarType[name] {
+			if gen.ImportBuildIn == nil {
+				gen.ImportBuildIn = map[string]bool{}
+			}
+			gen.ImportBuildIn[name] = true
+		}
+		if name == "[String]" {
+			return "[String!]"
+		}
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genGraphQLFieldName(name)
+	if fieldType == "" {
+		return gen.genGraphQLFieldType("Any")
+	}
+	return gen.typeName(fieldType)
+}
+
+// graphqlQuote returns the string value of GraphQL for the given value, the
+// escape sequences of JSON strings are valid in GraphQL.
+func graphqlQuote(value string) string {
+	data, _ := marshalJSON(value)
+	return string(data)
+}
+
+// genGraphQLDescription generates the description of the definition by given
+// documentation in the block string.
+func genGraphQLDescription(doc, indent string) string {
+	if doc = strings.TrimSpace(doc); doc == "" {
+		return ""
+	}
+	doc = strings.Replace(strings.Replace(doc, `"""`, `\"""`, -1), "\t", "", -1)
+	return fmt.Sprintf("%[1]s\"\"\"\n%[1]s%[2]s\n%[1]s\"\"\"\n", indent, strings.Replace(doc, "\n", "\n"+indent, -1))
+}
+
+// graphqlObject holds the fields of the object type in generating.
+type graphqlObject struct {
+	fields []string
+	names  map[string]bool
+}
+
+// addField provides a function to add the field by given XML name and type,
+// the required fields are non-null, and the plural fields are the lists of
+// the non-null items. The index of the field is appended to the name of the
+// field which collides with another, e.g. the attribute and element with the
+// same name.
+func (o *graphqlObject) addField(gen *CodeGenerator, name, doc, fieldType, deprecated string, plural, optional bool) {
+	fieldName := gen.fieldName(ConvertCase(genGraphQLFieldName(name), CamelCase))
+	if o.names == nil {
+		o.names = map[string]bool{}
+	}
+	if o.names[fieldName] {
+		fieldName = fmt.Sprintf("%s%d", fieldName, len(o.fields))
+	}
+	o.names[fieldName] = true
+	if plural && !strings.HasPrefix(fieldType, "[") {
+		fieldType = fmt.Sprintf("[%s!]", fieldType)
+	}
+	if !optional {
+		fieldType += "!"
+	}
+	if deprecated != "" {
+		fieldType += fmt.Sprintf(" @deprecated(reason: %s)", graphqlQuote(deprecated))
+	}
+	o.fields = append(o.fields, fmt.Sprintf("%s  %s: %s\n", genGraphQLDescription(doc, "  "), fieldName, fieldType))
+}
+
+// genGraphQLObject generates the object type by given declaration and
+// fields, the object types without any field are generated as the custom
+// scalars since there must be at least one field in the object type.
+func (gen *CodeGenerator) genGraphQLObject(name, doc, deprecated string, object *graphqlObject) {
+	content := strings.Join(object.fields, "")
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genGraphQLFieldName(name))
+	if content == "" {
+		gen.Field += fmt.Sprintf("\n%s%sscalar %s\n", genGraphQLDescription(doc, ""), gen.genDeprecated(deprecated), fieldName)
+		return
+	}
+	gen.Field += fmt.Sprintf("\n%s%stype %s {\n%s}\n", genGraphQLDescription(doc, ""), gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// GraphQLSimpleType generates code for simple type XML schema in GraphQL
+// SDL. The enumerations are generated as enums with the values in the
+// screaming snake case, the XML values are noted in the comments.
+func (gen *CodeGenerator) GraphQLSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = gen.genGraphQLFieldType(v.Name)
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("  %s # %s\n", member, strings.Replace(enum, "\n", " ", -1))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genGraphQLFieldName(v.Name))
+	gen.Field += fmt.Sprintf("\n%s%senum %s {\n%s}\n", genGraphQLDescription(v.Doc, ""), gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// GraphQLComplexType generates code for complex type XML schema in GraphQL
+// SDL.
+func (gen *CodeGenerator) GraphQLComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	object := &graphqlObject{}
+	for _, attrGroup := range v.AttributeGroup {
+		object.addField(gen, attrGroup.Name, "", gen.genGraphQLFieldType(attrGroup.Ref), "", false, false)
+	}
+	for _, attribute := range v.Attributes {
+		object.addField(gen, attribute.Name, attribute.Doc, gen.genGraphQLFieldType(attribute.Type), attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	for _, group := range v.Groups {
+		object.addField(gen, group.Name, "", gen.genGraphQLFieldType(group.Ref), "", group.Plural, false)
+	}
+	for _, element := range v.Elements {
+		object.addField(gen, element.Name, element.Doc, gen.genGraphQLFieldType(element.Type), element.Deprecated, element.Plural, element.Optional)
+	}
+	gen.genGraphQLObject(v.Name, v.Doc, v.Deprecated, object)
+	return
+}
+
+// GraphQLGroup generates code for group XML schema in GraphQL SDL.
+func (gen *CodeGenerator) GraphQLGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	object := &graphqlObject{}
+	for _, element := range v.Elements {
+		object.addField(gen, element.Name, element.Doc, gen.genGraphQLFieldType(element.Type), element.Deprecated, element.Plural, element.Optional)
+	}
+	for _, group := range v.Groups {
+		object.addField(gen, group.Name, "", gen.genGraphQLFieldType(group.Ref), "", group.Plural, false)
+	}
+	gen.genGraphQLObject(v.Name, v.Doc, v.Deprecated, object)
+	return
+}
+
+// GraphQLAttributeGroup generates code for attribute group XML schema in
+// GraphQL SDL.
+func (gen *CodeGenerator) GraphQLAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	object := &graphqlObject{}
+	for _, attribute := range v.Attributes {
+		object.addField(gen, attribute.Name, attribute.Doc, gen.genGraphQLFieldType(attribute.Type), attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	gen.genGraphQLObject(v.Name, v.Doc, v.Deprecated, object)
+	return
+}
+
+// GraphQLElement generates code for element XML schema in GraphQL SDL, the
+// element is referred by its type.
+func (gen *CodeGenerator) GraphQLElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name] = gen.genGraphQLFieldType(v.Type)
+	}
+	return
+}
+
+// GraphQLAttribute generates code for attribute XML schema in GraphQL SDL,
+// the attribute is referred by its type.
+func (gen *CodeGenerator) GraphQLAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name] = gen.genGraphQLFieldType(v.Type)
+	}
+	return
+}
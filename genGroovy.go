@@ -0,0 +1,327 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var groovyBuildInType = map[string]bool{
+	"BigDecimal":   true,
+	"BigInteger":   true,
+	"Boolean":      true,
+	"Byte":         true,
+	"Double":       true,
+	"Float":        true,
+	"GPathResult":  true,
+	"Integer":      true,
+	"List<String>": true,
+	"Long":         true,
+	"Short":        true,
+	"String":       true,
+	"byte[]":       true,
+}
+
+// groovyConversions defines the expressions convert the text of the node to
+// the built-in types of Groovy, the %s is replaced with the text.
+var groovyConversions = map[string]string{
+	"BigDecimal":   "%s as BigDecimal",
+	"BigInteger":   "%s as BigInteger",
+	"Boolean":      "%s.toBoolean()",
+	"Byte":         "%s as Byte",
+	"Double":       "%s as Double",
+	"Float":        "%s as Float",
+	"Integer":      "%s as Integer",
+	"List<String>": "%s.tokenize()",
+	"Long":         "%s as Long",
+	"Short":        "%s as Short",
+	"String":       "%s",
+	"byte[]":       "%s.decodeBase64()",
+}
+
+// groovyIdentifier matches the XML names which can be used as the Groovy
+// property names and in the GPath expressions as is.
+var groovyIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// GenGroovy generate Groovy programming language source code for XML schema
+// definition files. The types are generated as classes with the properties
+// named after the XML names wherever possible, each class can be created from
+// the node parsed by XmlSlurper with the static fromXml method.
+func (gen *CodeGenerator) GenGroovy() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Groovy%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	importPackage := "import groovy.transform.EqualsAndHashCode\nimport groovy.transform.ToString\nimport groovy.xml.XmlSlurper\nimport groovy.xml.slurpersupport.GPathResult\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import %s\n", mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("%s\n\npackage %s\n\n%s%s", copyright, packageName, importPackage, gen.Field))
+	return gen.writeFile(gen.File+".groovy", source)
+}
+
+func genGroovyFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genGroovyPropertyName generates the property name for Groovy code. The XML
+// name is kept if it's a valid identifier, so the property can be accessed
+// the same way as the node in the GPath expressions, otherwise it's converted
+// to the camel case.
+func (gen *CodeGenerator) genGroovyPropertyName(name string) string {
+	if groovyIdentifier.MatchString(name) {
+		return gen.fieldName(name)
+	}
+	return gen.fieldName(ConvertCase(genGroovyFieldName(name), CamelCase))
+}
+
+// genGroovyPath generates the GPath expression to access the child element or
+// attribute of the node by given XML name, the name is quoted if it's not a
+// valid identifier.
+func genGroovyPath(name string, attribute bool) string {
+	if attribute {
+		name = "@" + name
+	}
+	if groovyIdentifier.MatchString(strings.TrimPrefix(name, "@")) && !ReservedWords["Groovy"][strings.TrimPrefix(name, "@")] {
+		return "node." + name
+	}
+	return "node." + singleQuote(name)
+}
+
+// genGroovyFieldType generates the type for Groovy code. Groovy doesn't have
+// type aliases, so the list simple types are generated as the lists of their
+// item types and the union simple types are generated as strings.
+func (gen *CodeGenerator) genGroovyFieldType(name string) string {
+	if _, ok := groovyBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		return fmt.Sprintf("List<%s>", gen.genGroovyFieldType(gen.getBaseType(v.Base)))
+	} else if v != nil && v.Union {
+		return "String"
+	}
+	fieldType := genGroovyFieldName(name)
+	if fieldType == "" {
+		return "GPathResult"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genGroovyValue generates the expression converts the node to the value of
+// the given type.
+func (gen *CodeGenerator) genGroovyValue(name, node string) string {
+	fieldType := gen.genGroovyFieldType(name)
+	if conversion, ok := groovyConversions[fieldType]; ok {
+		return fmt.Sprintf(conversion, node+".text()")
+	}
+	if fieldType == "GPathResult" {
+		return node
+	}
+	if _, ok := gen.TypeMapping[name]; ok {
+		return fmt.Sprintf("%s.text() as %s", node, fieldType)
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		itemType := gen.genGroovyFieldType(gen.getBaseType(v.Base))
+		if conversion, ok := groovyConversions[itemType]; ok && itemType != "String" {
+			return fmt.Sprintf("%s.text().tokenize().collect { %s }", node, fmt.Sprintf(conversion, "it"))
+		}
+		return fmt.Sprintf("%s.text().tokenize()", node)
+	} else if v != nil && v.Union {
+		return node + ".text()"
+	}
+	return fmt.Sprintf("%s.fromXml(%s)", fieldType, node)
+}
+
+// genGroovyProperty generates the declaration and the argument of the named
+// argument constructor for the property by given XML name of the element or
+// attribute. The plural property defaults to an empty list, and the optional
+// property is null if the node doesn't exist.
+func (gen *CodeGenerator) genGroovyProperty(name, typeName string, attribute, plural, optional bool) (property, argument string) {
+	baseType := gen.getBaseType(typeName)
+	fieldType, propertyName, path := gen.genGroovyFieldType(baseType), gen.genGroovyPropertyName(name), genGroovyPath(name, attribute)
+	switch {
+	case plural:
+		return fmt.Sprintf("    List<%s> %s = []\n", fieldType, propertyName), fmt.Sprintf("%s: %s.collect { %s }", propertyName, path, gen.genGroovyValue(baseType, "it"))
+	case optional:
+		return fmt.Sprintf("    %s %s\n", fieldType, propertyName), fmt.Sprintf("%s: %s.isEmpty() ? null : %s", propertyName, path, gen.genGroovyValue(baseType, path))
+	}
+	return fmt.Sprintf("    %s %s\n", fieldType, propertyName), fmt.Sprintf("%s: %s", propertyName, gen.genGroovyValue(baseType, path))
+}
+
+// genGroovyInlineProperty generates the declaration and the argument for the
+// group or attribute group, which is created from the enclosing node.
+func (gen *CodeGenerator) genGroovyInlineProperty(name, ref string, plural bool) (property, argument string) {
+	fieldType, propertyName := gen.genGroovyFieldType(gen.getBaseType(ref)), gen.genGroovyPropertyName(name)
+	if plural {
+		return fmt.Sprintf("    List<%s> %s = []\n", fieldType, propertyName), fmt.Sprintf("%s: [%s.fromXml(node)]", propertyName, fieldType)
+	}
+	return fmt.Sprintf("    %s %s\n", fieldType, propertyName), fmt.Sprintf("%s: %s.fromXml(node)", propertyName, fieldType)
+}
+
+// genGroovyClass generates the class by given declaration, properties and the
+// arguments to create it from the node.
+func (gen *CodeGenerator) genGroovyClass(name, doc, source, location, deprecated string, properties, arguments []string) {
+	fieldName := gen.typeName(genGroovyFieldName(name))
+	content := strings.Join(properties, "")
+	if content != "" {
+		content += "\n"
+	}
+	construct := fmt.Sprintf("new %s()", fieldName)
+	if len(arguments) > 0 {
+		construct = fmt.Sprintf("new %s(\n            %s\n        )", fieldName, strings.Join(arguments, ",\n            "))
+	}
+	content += fmt.Sprintf("    // fromXml creates %s from the node parsed by XmlSlurper.\n    static %s fromXml(GPathResult node) {\n        %s\n    }\n", fieldName, fieldName, construct)
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%s@ToString(includeNames = true, ignoreNulls = true)\n@EqualsAndHashCode\nclass %s {\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// GroovySimpleType generates code for simple type XML schema in Groovy
+// language syntax. Only the enumerations are generated, the other simple
+// types are resolved to the built-in types where they are referenced.
+func (gen *CodeGenerator) GroovySimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = ""
+		return
+	}
+	fieldName := gen.typeName(genGroovyFieldName(v.Name))
+	var constants []string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		constants = append(constants, fmt.Sprintf("    %s(%s)", member, templateQuote(enum)))
+	}
+	content := fmt.Sprintf("%s\n\n    final String value\n\n    %s(String value) {\n        this.value = value\n    }\n\n    // fromValue returns the constant of %s by given value in the schema.\n    static %s fromValue(String value) {\n        values().find { it.value == value }\n    }\n", strings.Join(constants, ",\n"), fieldName, fieldName, fieldName)
+	gen.StructAST[v.Name] = content
+	gen.Field += fmt.Sprintf("%senum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// GroovyComplexType generates code for complex type XML schema in Groovy
+// language syntax.
+func (gen *CodeGenerator) GroovyComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties, arguments []string
+	for _, attrGroup := range v.AttributeGroup {
+		property, argument := gen.genGroovyInlineProperty(attrGroup.Name, attrGroup.Ref, false)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	for _, attribute := range v.Attributes {
+		property, argument := gen.genGroovyProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		property, argument := gen.genGroovyInlineProperty(group.Name, group.Ref, group.Plural)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	for _, element := range v.Elements {
+		property, argument := gen.genGroovyProperty(element.Name, element.Type, false, element.Plural, element.Optional)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	gen.genGroovyClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties, arguments)
+	return
+}
+
+// GroovyGroup generates code for group XML schema in Groovy language syntax.
+func (gen *CodeGenerator) GroovyGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties, arguments []string
+	for _, element := range v.Elements {
+		property, argument := gen.genGroovyProperty(element.Name, element.Type, false, element.Plural, element.Optional)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		property, argument := gen.genGroovyInlineProperty(group.Name, group.Ref, group.Plural)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	gen.genGroovyClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties, arguments)
+	return
+}
+
+// GroovyAttributeGroup generates code for attribute group XML schema in
+// Groovy language syntax.
+func (gen *CodeGenerator) GroovyAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties, arguments []string
+	for _, attribute := range v.Attributes {
+		property, argument := gen.genGroovyProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		properties, arguments = append(properties, property), append(arguments, argument)
+	}
+	gen.genGroovyClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties, arguments)
+	return
+}
+
+// GroovyElement generates code for element XML schema in Groovy language
+// syntax. The element is generated as the class with the static parse method,
+// which parses the XML document with the element as the root by XmlSlurper.
+func (gen *CodeGenerator) GroovyElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genGroovyFieldName(v.Name))
+	baseType := gen.getBaseType(v.Type)
+	fieldType := gen.genGroovyFieldType(baseType)
+	if fieldType == fieldName {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	content := fmt.Sprintf("    // parse parses the XML document with the %s root element by XmlSlurper.\n    static %s parse(String xml) {\n        %s\n    }\n", v.Name, fieldType, gen.genGroovyValue(baseType, "new XmlSlurper().parseText(xml)"))
+	gen.StructAST[v.Name] = content
+	gen.Field += fmt.Sprintf("%sclass %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// GroovyAttribute generates code for attribute XML schema in Groovy language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) GroovyAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
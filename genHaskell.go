@@ -0,0 +1,380 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var haskellBuildInType = map[string]bool{
+	"[Text]":     true,
+	"Bool":       true,
+	"Double":     true,
+	"Float":      true,
+	"Int16":      true,
+	"Int32":      true,
+	"Int64":      true,
+	"Int8":       true,
+	"Integer":    true,
+	"Scientific": true,
+	"Text":       true,
+	"Word16":     true,
+	"Word32":     true,
+	"Word64":     true,
+	"Word8":      true,
+}
+
+// haskellBuildInPickler defines the picklers of the text for the built-in
+// types of Haskell, the numeric types are pickled by their Read and Show
+// instances.
+var haskellBuildInPickler = map[string]string{
+	"[Text]": "xpWords xpId",
+	"Bool":   "xpBoolean",
+	"Text":   "xpId",
+}
+
+// haskellHelpers defines the picklers used by the generated code for the
+// values which can't be pickled by the Read and Show instances.
+const haskellHelpers = `xpBoolean :: PU Text Bool
+xpBoolean = xpPartial parse (\value -> if value then "true" else "false")
+  where
+    parse value
+      | value ` + "`elem`" + ` ["true", "1"] = Right True
+      | value ` + "`elem`" + ` ["false", "0"] = Right False
+      | otherwise = Left ("invalid boolean: " <> value)
+
+xpWords :: PU Text a -> PU Text [a]
+xpWords pu = xpPartial (either (Left . T.pack . ppUnpickleError) Right . mapM (unpickle pu) . T.words) (T.unwords . map (pickle pu))
+`
+
+// haskellValue holds the Haskell type of the value and its pickler. The
+// pickler of the element type takes the name of the element and pickles the
+// nodes, and the others pickle the text.
+type haskellValue struct {
+	fieldType, pickler string
+	element            bool
+}
+
+// haskellField holds the field of the generated record and the pickler of
+// the field.
+type haskellField struct {
+	name, fieldType, pickler string
+}
+
+// GenHaskell generate Haskell programming language source code for XML schema
+// definition files. The types are generated as records with the picklers of
+// xml-picklers, which work on the xml-types nodes used by xml-conduit.
+func (gen *CodeGenerator) GenHaskell() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Haskell%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "import Data.Int (Int16, Int32, Int64, Int8)\nimport Data.Scientific (Scientific)\nimport Data.Text (Text)\nimport qualified Data.Text as T\nimport Data.Word (Word16, Word32, Word64, Word8)\nimport Data.XML.Pickle\nimport Data.XML.Types (Attribute, Name, Node)\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import %s\n", mapping.Import)
+	}
+	module := "Schema"
+	if gen.Package != "" {
+		module = MakeFirstUpperCase(gen.Package)
+	}
+	source := []byte(fmt.Sprintf("{-# LANGUAGE OverloadedStrings #-}\n\n-- %s\n\nmodule %s where\n\n%s\n%s%s", strings.TrimPrefix(copyright, "// "), module, importPackage, haskellHelpers, gen.Field))
+	return gen.writeFile(gen.File+".hs", source)
+}
+
+func genHaskellFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genHaskellRecordField generates the name of the record field, which is
+// prefixed with the name of the record to be unique in the module.
+func (gen *CodeGenerator) genHaskellRecordField(typeName, name string) string {
+	return gen.fieldName(strings.ToLower(typeName[:1]) + typeName[1:] + ConvertCase(genHaskellFieldName(name), PascalCase))
+}
+
+// haskellParen wraps the expression in parentheses if it's not atomic.
+func haskellParen(expr string) string {
+	if strings.ContainsAny(expr, " ") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// genHaskellValue generates the value for Haskell code by given type. The
+// list and union simple types are pickled by the picklers generated for them.
+func (gen *CodeGenerator) genHaskellValue(name string) haskellValue {
+	if _, ok := haskellBuildInType[name]; ok {
+		pickler, ok := haskellBuildInPickler[name]
+		if !ok {
+			pickler = "xpPrim"
+		}
+		return haskellValue{fieldType: name, pickler: pickler}
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return haskellValue{fieldType: mappedType, pickler: "xp" + mappedType, element: true}
+	}
+	fieldType := genHaskellFieldName(name)
+	if fieldType == "" {
+		return haskellValue{fieldType: "Text", pickler: "xpId"}
+	}
+	fieldType = gen.typeName(fieldType)
+	return haskellValue{fieldType: fieldType, pickler: "xp" + fieldType, element: gen.getSimpleType(name) == nil}
+}
+
+// genHaskellElement generates the field for the element.
+func (gen *CodeGenerator) genHaskellElement(typeName string, element Element) haskellField {
+	value := gen.genHaskellValue(gen.getBaseType(element.Type))
+	field := haskellField{name: gen.genHaskellRecordField(typeName, element.Name), fieldType: value.fieldType}
+	pickler := fmt.Sprintf("xpElemNodes %s (xpContent %s)", strconv.Quote(element.Name), haskellParen(value.pickler))
+	if value.element {
+		pickler = fmt.Sprintf("%s %s", value.pickler, strconv.Quote(element.Name))
+	}
+	switch {
+	case element.Plural:
+		field.fieldType = fmt.Sprintf("[%s]", field.fieldType)
+		pickler = fmt.Sprintf("xpList (%s)", pickler)
+	case element.Optional:
+		field.fieldType = "Maybe " + haskellParen(field.fieldType)
+		pickler = fmt.Sprintf("xpOption (%s)", pickler)
+	}
+	field.pickler = pickler
+	return field
+}
+
+// genHaskellAttribute generates the field for the attribute.
+func (gen *CodeGenerator) genHaskellAttribute(typeName string, attribute Attribute) haskellField {
+	value := gen.genHaskellValue(gen.getBaseType(attribute.Type))
+	field := haskellField{name: gen.genHaskellRecordField(typeName, attribute.Name), fieldType: value.fieldType}
+	pickler := value.pickler
+	if attribute.Plural {
+		field.fieldType = fmt.Sprintf("[%s]", field.fieldType)
+		pickler = "xpWords " + haskellParen(pickler)
+	}
+	if attribute.Optional {
+		field.fieldType = "Maybe " + haskellParen(field.fieldType)
+		field.pickler = fmt.Sprintf("xpAttribute' %s %s", strconv.Quote(attribute.Name), haskellParen(pickler))
+		return field
+	}
+	field.pickler = fmt.Sprintf("xpAttribute %s %s", strconv.Quote(attribute.Name), haskellParen(pickler))
+	return field
+}
+
+// genHaskellInline generates the field for the group or attribute group,
+// which is pickled with the enclosing element.
+func (gen *CodeGenerator) genHaskellInline(typeName, name, ref string) haskellField {
+	value := gen.genHaskellValue(gen.getBaseType(ref))
+	return haskellField{name: gen.genHaskellRecordField(typeName, name), fieldType: value.fieldType, pickler: value.pickler}
+}
+
+// genHaskellPair generates the pickler of the right-nested pairs by given
+// picklers, and the pattern and the expression of the pairs by given
+// variables and fields.
+func genHaskellPair(fields []haskellField, vars []string) (pickler, pattern, expr string) {
+	switch len(fields) {
+	case 0:
+		return "xpUnit", "()", "()"
+	case 1:
+		return fields[0].pickler, vars[0], fmt.Sprintf("%s x", fields[0].name)
+	}
+	pickler, pattern, expr = genHaskellPair(fields[1:], vars[1:])
+	return fmt.Sprintf("xpPair %s %s", haskellParen(fields[0].pickler), haskellParen(pickler)), fmt.Sprintf("(%s, %s)", vars[0], pattern), fmt.Sprintf("(%s x, %s)", fields[0].name, expr)
+}
+
+// genHaskellRecord generates the record by given declaration and fields, and
+// the pickler of it. The attributes fields are pickled with the attributes,
+// the nodes fields are pickled with the child nodes. The pickler of the
+// complex type pickles the element by given name, and the picklers of the
+// group and attribute group pickle the nodes and attributes.
+func (gen *CodeGenerator) genHaskellRecord(name, doc, source, location, deprecated, kind string, attributes, nodes []haskellField) {
+	fieldName := gen.typeName(genHaskellFieldName(name))
+	fields := append(append([]haskellField{}, attributes...), nodes...)
+	var vars []string
+	for i := range fields {
+		vars = append(vars, fmt.Sprintf("v%d", i+1))
+	}
+	var declaration string
+	if len(fields) == 0 {
+		declaration = fmt.Sprintf("data %[1]s = %[1]s deriving (Eq, Show)\n", fieldName)
+	} else {
+		var lines []string
+		for _, field := range fields {
+			lines = append(lines, fmt.Sprintf("%s :: %s", field.name, field.fieldType))
+		}
+		declaration = fmt.Sprintf("data %[1]s = %[1]s\n  { %[2]s\n  } deriving (Eq, Show)\n", fieldName, strings.Join(lines, "\n  , "))
+	}
+	constructor := strings.Join(append([]string{fieldName}, vars...), " ")
+	inverse := "\\x"
+	if len(fields) == 0 {
+		inverse = "\\_"
+	}
+	attributesPickler, attributesPattern, attributesExpr := genHaskellPair(attributes, vars)
+	nodesPickler, nodesPattern, nodesExpr := genHaskellPair(nodes, vars[len(attributes):])
+	var pickler string
+	switch kind {
+	case "Element":
+		pickler = fmt.Sprintf("xp%[1]s :: Name -> PU [Node] %[1]s\nxp%[1]s name =\n  xpWrap\n    (\\(%[2]s, %[3]s) -> %[4]s)\n    (%[5]s -> (%[6]s, %[7]s))\n    (xpElem name\n      %[8]s\n      %[9]s)\n", fieldName, attributesPattern, nodesPattern, constructor, inverse, attributesExpr, nodesExpr, haskellParen(attributesPickler), haskellParen(nodesPickler))
+	case "Attribute":
+		pickler = fmt.Sprintf("xp%[1]s :: PU [Attribute] %[1]s\nxp%[1]s =\n  xpWrap\n    (\\%[2]s -> %[3]s)\n    (%[4]s -> %[5]s)\n    %[6]s\n", fieldName, attributesPattern, constructor, inverse, attributesExpr, haskellParen(attributesPickler))
+	default:
+		pickler = fmt.Sprintf("xp%[1]s :: PU [Node] %[1]s\nxp%[1]s =\n  xpWrap\n    (\\%[2]s -> %[3]s)\n    (%[4]s -> %[5]s)\n    %[6]s\n", fieldName, nodesPattern, constructor, inverse, nodesExpr, haskellParen(nodesPickler))
+	}
+	gen.StructAST[name] = declaration
+	gen.Field += fmt.Sprintf("%s%s\n%s", genFieldComment(fieldName, doc, source, location, "--")+gen.genDeprecated(deprecated), declaration, pickler)
+}
+
+// genHaskellAlias generates the type synonym by given declaration and type,
+// and the pickler of it.
+func (gen *CodeGenerator) genHaskellAlias(name, doc, source, location, deprecated, fieldType, picklerType, pickler string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genHaskellFieldName(name))
+	gen.Field += fmt.Sprintf("%stype %[2]s = %[3]s\n\nxp%[2]s :: %[4]s %[2]s\nxp%[2]s = %[5]s\n", genFieldComment(fieldName, doc, source, location, "--")+gen.genDeprecated(deprecated), fieldName, fieldType, picklerType, pickler)
+}
+
+// HaskellSimpleType generates code for simple type XML schema in Haskell
+// language syntax.
+func (gen *CodeGenerator) HaskellSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		item := gen.genHaskellValue(gen.getBaseType(v.Base))
+		gen.genHaskellAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("[%s]", item.fieldType), "PU Text", "xpWords "+haskellParen(item.pickler))
+		return
+	}
+	if v.Union {
+		gen.genHaskellAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Text", "PU Text", "xpId")
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		fieldName := gen.typeName(genHaskellFieldName(v.Name))
+		var constructors, parse, format []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := fieldName + ConvertCase(enum, PascalCase)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			constructors = append(constructors, member)
+			parse = append(parse, fmt.Sprintf("    parse %s = Right %s", strconv.Quote(enum), member))
+			format = append(format, fmt.Sprintf("    format %s = %s", member, strconv.Quote(enum)))
+		}
+		gen.StructAST[v.Name] = strings.Join(constructors, " | ")
+		gen.Field += fmt.Sprintf("%sdata %[2]s\n  = %[3]s\n  deriving (Eq, Show, Enum, Bounded)\n\nxp%[2]s :: PU Text %[2]s\nxp%[2]s = xpPartial parse format\n  where\n%[4]s\n    parse value = Left (%[5]s <> value)\n%[6]s\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "--")+gen.genDeprecated(v.Deprecated), fieldName, strings.Join(constructors, "\n  | "), strings.Join(parse, "\n"), strconv.Quote("invalid "+fieldName+": "), strings.Join(format, "\n"))
+		return
+	}
+	value := gen.genHaskellValue(gen.getBaseType(v.Base))
+	gen.genHaskellAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value.fieldType, "PU Text", value.pickler)
+	return
+}
+
+// HaskellComplexType generates code for complex type XML schema in Haskell
+// language syntax.
+func (gen *CodeGenerator) HaskellComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	typeName := gen.typeName(genHaskellFieldName(v.Name))
+	var attributes, nodes []haskellField
+	for _, attrGroup := range v.AttributeGroup {
+		attributes = append(attributes, gen.genHaskellInline(typeName, attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		attributes = append(attributes, gen.genHaskellAttribute(typeName, attribute))
+	}
+	for _, group := range v.Groups {
+		nodes = append(nodes, gen.genHaskellInline(typeName, group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		nodes = append(nodes, gen.genHaskellElement(typeName, element))
+	}
+	gen.genHaskellRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Element", attributes, nodes)
+	return
+}
+
+// HaskellGroup generates code for group XML schema in Haskell language
+// syntax.
+func (gen *CodeGenerator) HaskellGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	typeName := gen.typeName(genHaskellFieldName(v.Name))
+	var nodes []haskellField
+	for _, element := range v.Elements {
+		nodes = append(nodes, gen.genHaskellElement(typeName, element))
+	}
+	for _, group := range v.Groups {
+		nodes = append(nodes, gen.genHaskellInline(typeName, group.Name, group.Ref))
+	}
+	gen.genHaskellRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Group", nil, nodes)
+	return
+}
+
+// HaskellAttributeGroup generates code for attribute group XML schema in
+// Haskell language syntax.
+func (gen *CodeGenerator) HaskellAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	typeName := gen.typeName(genHaskellFieldName(v.Name))
+	var attributes []haskellField
+	for _, attribute := range v.Attributes {
+		attributes = append(attributes, gen.genHaskellAttribute(typeName, attribute))
+	}
+	gen.genHaskellRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Attribute", attributes, nil)
+	return
+}
+
+// HaskellElement generates code for element XML schema in Haskell language
+// syntax. The pickler of the element pickles the nodes of the element.
+func (gen *CodeGenerator) HaskellElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genHaskellValue(gen.getBaseType(v.Type))
+	fieldType, pickler := value.fieldType, fmt.Sprintf("xpElemNodes %s (xpContent %s)", strconv.Quote(v.Name), haskellParen(value.pickler))
+	if value.element {
+		pickler = fmt.Sprintf("%s %s", value.pickler, strconv.Quote(v.Name))
+	}
+	if v.Plural {
+		fieldType, pickler = fmt.Sprintf("[%s]", fieldType), fmt.Sprintf("xpList (%s)", pickler)
+	}
+	gen.genHaskellAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType, "PU [Node]", pickler)
+	return
+}
+
+// HaskellAttribute generates code for attribute XML schema in Haskell
+// language syntax. The pickler of the attribute pickles the attributes.
+func (gen *CodeGenerator) HaskellAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genHaskellValue(gen.getBaseType(v.Type))
+	fieldType, pickler := value.fieldType, value.pickler
+	if v.Plural {
+		fieldType, pickler = fmt.Sprintf("[%s]", fieldType), "xpWords "+haskellParen(pickler)
+	}
+	gen.genHaskellAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType, "PU [Attribute]", fmt.Sprintf("xpAttribute %s %s", strconv.Quote(v.Name), haskellParen(pickler)))
+	return
+}
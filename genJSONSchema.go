@@ -0,0 +1,471 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDialect is the meta-schema of the generated JSON Schema documents.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var jsonSchemaBuildInType = map[string]bool{
+	"any":                true,
+	"array":              true,
+	"base16":             true,
+	"base64":             true,
+	"boolean":            true,
+	"date":               true,
+	"date-time":          true,
+	"duration":           true,
+	"int16":              true,
+	"int32":              true,
+	"int64":              true,
+	"int8":               true,
+	"integer":            true,
+	"negativeInteger":    true,
+	"nonNegativeInteger": true,
+	"nonPositiveInteger": true,
+	"number":             true,
+	"positiveInteger":    true,
+	"string":             true,
+	"time":               true,
+	"uint16":             true,
+	"uint32":             true,
+	"uint64":             true,
+	"uint8":              true,
+	"uri":                true,
+}
+
+// jsonSchema is the JSON object of the schema, the keywords are marshaled in
+// the order in which they were set.
+type jsonSchema struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set provides a function to set the value of the keyword.
+func (s *jsonSchema) set(key string, value interface{}) *jsonSchema {
+	if s.values == nil {
+		s.values = map[string]interface{}{}
+	}
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.values[key] = value
+	return s
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s *jsonSchema) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range s.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte(':')
+		if data, err = marshalJSON(s.values[key]); err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalJSON returns the JSON encoding of the value without escaping the
+// HTML characters, which are common in the patterns.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// GenJSONSchema generate JSON Schema (draft 2020-12) document for XML schema
+// definition files. The declarations are generated in the $defs of the
+// document, and the document validates the global elements. The attributes
+// and the elements of the complex types are both generated as the properties
+// of the objects, the repeated elements are arrays.
+func (gen *CodeGenerator) GenJSONSchema() error {
+	var roots []*jsonSchema
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) {
+			continue
+		}
+		if v, ok := ele.(*Element); ok {
+			if gen.isWrapper(ele) {
+				roots = append(roots, gen.genJSONSchemaType(v.Type, v.Plural))
+				continue
+			}
+			roots = append(roots, gen.genJSONSchemaRef(v.Name))
+		}
+		if gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("JSONSchema%s", reflect.TypeOf(ele).String()[6:])
+		if err := gen.genDeclaration(ele, funcName); err != nil {
+			return err
+		}
+	}
+	gen.genPlaceholders()
+	header := (&jsonSchema{}).set("$schema", jsonSchemaDialect)
+	switch {
+	case len(roots) == 1 && len(roots[0].keys) == 1 && roots[0].values["$ref"] != nil:
+		header.set("$ref", roots[0].values["$ref"])
+	case len(roots) > 0:
+		header.set("anyOf", roots)
+	}
+	data, err := marshalJSON(header)
+	if err != nil {
+		return err
+	}
+	var source bytes.Buffer
+	document := fmt.Sprintf("%s,\"$defs\":{%s}}", bytes.TrimSuffix(data, []byte("}")), strings.TrimPrefix(gen.Field, ","))
+	if err = json.Indent(&source, []byte(document), "", "  "); err != nil {
+		return err
+	}
+	source.WriteByte('\n')
+	return gen.writeFile(gen.File+".schema.json", source.Bytes())
+}
+
+func genJSONSchemaFieldName(name string) string {
+	return trimNSPrefix(name)
+}
+
+// genJSONSchemaRef returns the schema refers to the definition by given name,
+// the definitions are in the components of the OpenAPI document.
+func (gen *CodeGenerator) genJSONSchemaRef(name string) *jsonSchema {
+	if gen.Lang == "OpenAPI" {
+		return (&jsonSchema{}).set("$ref", "#/components/schemas/"+genJSONSchemaFieldName(name))
+	}
+	return (&jsonSchema{}).set("$ref", "#/$defs/"+genJSONSchemaFieldName(name))
+}
+
+// genJSONSchemaBuildIn returns the schema of the built-in type, which are the
+// keywords of the JSON types with the formats and the numeric bounds.
+func genJSONSchemaBuildIn(name string) *jsonSchema {
+	schema := &jsonSchema{}
+	switch name {
+	case "any":
+	case "array":
+		schema.set("type", "array").set("items", (&jsonSchema{}).set("type", "string"))
+	case "boolean", "number", "integer", "string":
+		schema.set("type", name)
+	case "base16", "base64":
+		schema.set("type", "string").set("contentEncoding", name)
+	case "date", "date-time", "duration", "time":
+		schema.set("type", "string").set("format", name)
+	case "uri":
+		schema.set("type", "string").set("format", "uri-reference")
+	case "int8", "int16", "int32", "int64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(name, "int"))
+		schema.set("type", "integer").set("minimum", json.Number(fmt.Sprintf("-%d", uint64(1)<<uint(bits-1)))).set("maximum", json.Number(fmt.Sprintf("%d", uint64(1)<<uint(bits-1)-1)))
+	case "uint8", "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(name, "uint"))
+		schema.set("type", "integer").set("minimum", json.Number("0")).set("maximum", json.Number(strconv.FormatUint(^uint64(0)>>uint(64-bits), 10)))
+	case "negativeInteger":
+		schema.set("type", "integer").set("maximum", json.Number("-1"))
+	case "nonPositiveInteger":
+		schema.set("type", "integer").set("maximum", json.Number("0"))
+	case "nonNegativeInteger":
+		schema.set("type", "integer").set("minimum", json.Number("0"))
+	case "positiveInteger":
+		schema.set("type", "integer").set("minimum", json.Number("1"))
+	}
+	return schema
+}
+
+// genJSONSchemaType returns the schema of the type by given name, the
+// declarations are referred in the $defs and the mapped types are referred by
+// the URI in the type mapping.
+func (gen *CodeGenerator) genJSONSchemaType(name string, plural bool) (schema *jsonSchema) {
+	name = trimNSPrefix(name)
+	if _, ok := jsonSchemaBuildInType[name]; ok {
+		schema = genJSONSchemaBuildIn(name)
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		schema = (&jsonSchema{}).set("$ref", mappedType)
+	} else if name == "" {
+		schema = &jsonSchema{}
+	} else {
+		schema = gen.genJSONSchemaRef(name)
+	}
+	if plural {
+		schema = (&jsonSchema{}).set("type", "array").set("items", schema)
+	}
+	return
+}
+
+// genJSONSchemaValue returns the JSON value of the lexical value by given
+// type, the values which aren't valid numbers or booleans are kept as the
+// strings, e.g. the special values INF and NaN of the floats.
+func (gen *CodeGenerator) genJSONSchemaValue(typeName, value string) interface{} {
+	value = strings.TrimSpace(value)
+	switch genJSONSchemaBuildIn(gen.getBaseType(typeName)).values["type"] {
+	case "integer", "number":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(i, 10))
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// genJSONSchemaFacets sets the JSON Schema keywords of the facets in the
+// restriction on the schema of the given type.
+func (gen *CodeGenerator) genJSONSchemaFacets(schema *jsonSchema, typeName string, restriction Restriction) *jsonSchema {
+	if len(restriction.Enum) > 0 {
+		var enum []interface{}
+		for _, value := range restriction.Enum {
+			enum = append(enum, gen.genJSONSchemaValue(typeName, value))
+		}
+		schema.set("enum", enum)
+	}
+	if restriction.Pattern != nil {
+		// The patterns of XSD are implicitly anchored at both ends.
+		schema.set("pattern", fmt.Sprintf("^(?:%s)$", restriction.Pattern.String()))
+	}
+	minLength, maxLength := "minLength", "maxLength"
+	if schema.values["type"] == "array" {
+		minLength, maxLength = "minItems", "maxItems"
+	}
+	if restriction.MinLength > 0 {
+		schema.set(minLength, restriction.MinLength)
+	}
+	if restriction.MaxLength > 0 {
+		schema.set(maxLength, restriction.MaxLength)
+	}
+	if restriction.HasMin {
+		keyword := "minimum"
+		if restriction.MinExclusive {
+			keyword = "exclusiveMinimum"
+		}
+		schema.set(keyword, restriction.Min)
+	}
+	if restriction.HasMax {
+		keyword := "maximum"
+		if restriction.MaxExclusive {
+			keyword = "exclusiveMaximum"
+		}
+		schema.set(keyword, restriction.Max)
+	}
+	return schema
+}
+
+// genJSONSchemaAnnotations sets the annotations of the declaration on the
+// schema.
+func genJSONSchemaAnnotations(schema *jsonSchema, doc, location, deprecated string) *jsonSchema {
+	if doc != "" {
+		schema.set("description", strings.TrimSpace(doc))
+	}
+	if location != "" {
+		schema.set("$comment", "source: "+location)
+	}
+	if deprecated != "" {
+		schema.set("deprecated", true)
+	}
+	return schema
+}
+
+// genJSONSchemaDef generates the definition in the $defs by given name and
+// schema, or in the schemas of the components in YAML for OpenAPI.
+func (gen *CodeGenerator) genJSONSchemaDef(name string, schema *jsonSchema) error {
+	data, err := marshalJSON(schema)
+	if err != nil {
+		return err
+	}
+	gen.StructAST[name] = string(data)
+	if gen.Lang == "OpenAPI" {
+		gen.Field += genOpenAPIYAML((&jsonSchema{}).set(genJSONSchemaFieldName(name), schema), "    ")
+		return nil
+	}
+	key, _ := marshalJSON(genJSONSchemaFieldName(name))
+	gen.Field += fmt.Sprintf(",%s:%s", key, data)
+	return nil
+}
+
+// genJSONSchemaObject generates the object schema by given properties, the
+// names of the required properties are listed in the required keyword.
+func genJSONSchemaObject(properties *jsonSchema, required []string) *jsonSchema {
+	schema := (&jsonSchema{}).set("type", "object")
+	if len(properties.keys) > 0 {
+		schema.set("properties", properties)
+	}
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+	return schema
+}
+
+// genJSONSchemaElement sets the property for the element.
+func (gen *CodeGenerator) genJSONSchemaElement(properties *jsonSchema, required []string, element Element) []string {
+	schema := gen.genJSONSchemaFacets(gen.genJSONSchemaType(element.Type, false), element.Type, element.Restriction)
+	if element.Default != "" {
+		schema.set("default", gen.genJSONSchemaValue(element.Type, element.Default))
+	}
+	if element.Plural {
+		schema = (&jsonSchema{}).set("type", "array").set("items", schema)
+	}
+	properties.set(element.Name, genJSONSchemaAnnotations(schema, element.Doc, "", element.Deprecated))
+	if !element.Optional {
+		required = append(required, element.Name)
+	}
+	return required
+}
+
+// genJSONSchemaAttribute sets the property for the attribute.
+func (gen *CodeGenerator) genJSONSchemaAttribute(properties *jsonSchema, required []string, attribute Attribute) []string {
+	schema := gen.genJSONSchemaFacets(gen.genJSONSchemaType(attribute.Type, attribute.Plural), attribute.Type, attribute.Restriction)
+	if attribute.Default != "" {
+		schema.set("default", gen.genJSONSchemaValue(attribute.Type, attribute.Default))
+	}
+	genJSONSchemaAnnotations(schema, attribute.Doc, "", attribute.Deprecated)
+	if gen.Lang == "OpenAPI" {
+		schema.set("xml", (&jsonSchema{}).set("attribute", true))
+	}
+	properties.set(attribute.Name, schema)
+	if !attribute.Optional {
+		required = append(required, attribute.Name)
+	}
+	return required
+}
+
+// JSONSchemaSimpleType generates code for simple type XML schema in JSON
+// Schema. The lists are generated as arrays, the unions are generated as any
+// of the member types.
+func (gen *CodeGenerator) JSONSchemaSimpleType(v *SimpleType) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var schema *jsonSchema
+	switch {
+	case v.List:
+		schema = gen.genJSONSchemaFacets(gen.genJSONSchemaType(v.Base, true), v.Base, v.Restriction)
+	case v.Union && len(v.MemberTypes) > 0:
+		var members []interface{}
+		for _, member := range v.MemberTypes {
+			if gen.getSimpleType(member.Name) != nil {
+				members = append(members, gen.genJSONSchemaRef(member.Name))
+				continue
+			}
+			members = append(members, gen.genJSONSchemaType(gen.getBaseType(member.Type), false))
+		}
+		schema = (&jsonSchema{}).set("anyOf", members)
+	case v.Union:
+		schema = genJSONSchemaBuildIn("string")
+	default:
+		schema = gen.genJSONSchemaFacets(gen.genJSONSchemaType(v.Base, false), v.Base, v.Restriction)
+	}
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(schema, v.Doc, v.Location, v.Deprecated))
+	return
+}
+
+// JSONSchemaComplexType generates code for complex type XML schema in JSON
+// Schema.
+func (gen *CodeGenerator) JSONSchemaComplexType(v *ComplexType) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	properties := &jsonSchema{}
+	var required []string
+	for _, attrGroup := range v.AttributeGroup {
+		properties.set(attrGroup.Name, gen.genJSONSchemaType(attrGroup.Ref, false))
+	}
+	for _, attribute := range v.Attributes {
+		required = gen.genJSONSchemaAttribute(properties, required, attribute)
+	}
+	for _, group := range v.Groups {
+		properties.set(group.Name, gen.genJSONSchemaType(group.Ref, group.Plural))
+	}
+	for _, element := range v.Elements {
+		required = gen.genJSONSchemaElement(properties, required, element)
+	}
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(genJSONSchemaObject(properties, required), v.Doc, v.Location, v.Deprecated))
+	return
+}
+
+// JSONSchemaGroup generates code for group XML schema in JSON Schema.
+func (gen *CodeGenerator) JSONSchemaGroup(v *Group) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	properties := &jsonSchema{}
+	var required []string
+	for _, element := range v.Elements {
+		required = gen.genJSONSchemaElement(properties, required, element)
+	}
+	for _, group := range v.Groups {
+		properties.set(group.Name, gen.genJSONSchemaType(group.Ref, group.Plural))
+	}
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(genJSONSchemaObject(properties, required), v.Doc, v.Location, v.Deprecated))
+	return
+}
+
+// JSONSchemaAttributeGroup generates code for attribute group XML schema in
+// JSON Schema.
+func (gen *CodeGenerator) JSONSchemaAttributeGroup(v *AttributeGroup) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	properties := &jsonSchema{}
+	var required []string
+	for _, attribute := range v.Attributes {
+		required = gen.genJSONSchemaAttribute(properties, required, attribute)
+	}
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(genJSONSchemaObject(properties, required), v.Doc, v.Location, v.Deprecated))
+	return
+}
+
+// JSONSchemaElement generates code for element XML schema in JSON Schema.
+func (gen *CodeGenerator) JSONSchemaElement(v *Element) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if !v.Plural && trimNSPrefix(v.Type) == v.Name {
+		gen.StructAST[v.Name] = v.Type
+		return
+	}
+	schema := gen.genJSONSchemaFacets(gen.genJSONSchemaType(v.Type, false), v.Type, v.Restriction)
+	if v.Plural {
+		schema = (&jsonSchema{}).set("type", "array").set("items", schema)
+	}
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(schema, v.Doc, v.Location, v.Deprecated))
+	return
+}
+
+// JSONSchemaAttribute generates code for attribute XML schema in JSON Schema.
+func (gen *CodeGenerator) JSONSchemaAttribute(v *Attribute) (err error) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	schema := gen.genJSONSchemaFacets(gen.genJSONSchemaType(v.Type, v.Plural), v.Type, v.Restriction)
+	err = gen.genJSONSchemaDef(v.Name, genJSONSchemaAnnotations(schema, v.Doc, v.Location, v.Deprecated))
+	return
+}
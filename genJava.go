@@ -10,8 +10,9 @@ package xgen
 
 import (
 	"fmt"
-	"os"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -33,17 +34,13 @@ var javaBuildInType = map[string]bool{
 // definition files.
 func (gen *CodeGenerator) GenJava() error {
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
 			continue
 		}
 		funcName := fmt.Sprintf("Java%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration(ele, funcName)
 	}
-	f, err := os.Create(gen.File + ".java")
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	gen.genPlaceholders()
 	packageName := gen.Package
 	if packageName == "" {
 		packageName = "schema"
@@ -52,13 +49,24 @@ func (gen *CodeGenerator) GenJava() error {
 import java.util.List;
 import javax.xml.bind.annotation.XmlAccessType;
 import javax.xml.bind.annotation.XmlAccessorType;
+import javax.xml.bind.annotation.XmlAnyElement;
 import javax.xml.bind.annotation.XmlAttribute;
 import javax.xml.bind.annotation.XmlElement;
+import javax.xml.bind.annotation.XmlElements;
+import javax.xml.bind.annotation.XmlEnum;
+import javax.xml.bind.annotation.XmlEnumValue;
+import javax.xml.bind.annotation.XmlList;
+import javax.xml.bind.annotation.XmlMixed;
+import javax.xml.bind.annotation.XmlRootElement;
 import javax.xml.bind.annotation.XmlSchemaType;
-import javax.xml.bind.annotation.XmlType;`
+import javax.xml.bind.annotation.XmlSeeAlso;
+import javax.xml.bind.annotation.XmlType;
+import javax.xml.bind.annotation.XmlValue;`
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("\nimport %s;", mapping.Import)
+	}
 
-	f.Write([]byte(fmt.Sprintf("%s\n\npackage %s;\n\n%s\n%s", copyright, packageName, importPackage, gen.Field)))
-	return err
+	return gen.writeFile(gen.File+".java", []byte(fmt.Sprintf("%s\n\npackage %s;\n\n%s\n%s", copyright, packageName, importPackage, gen.Field)))
 }
 
 func genJavaFieldName(name string) (fieldName string) {
@@ -74,17 +82,20 @@ func genJavaFieldName(name string) (fieldName string) {
 	return
 }
 
-func genJavaFieldType(name string) string {
+func (gen *CodeGenerator) genJavaFieldType(name string) string {
 	if _, ok := javaBuildInType[name]; ok {
 		return name
 	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
 	var fieldType string
 	for _, str := range strings.Split(name, ".") {
 		fieldType += MakeFirstUpperCase(str)
 	}
 	fieldType = MakeFirstUpperCase(strings.Replace(fieldType, "-", "", -1))
 	if fieldType != "" {
-		return fieldType
+		return gen.typeName(fieldType)
 	}
 	return "void"
 }
@@ -94,48 +105,76 @@ func genJavaFieldType(name string) string {
 func (gen *CodeGenerator) JavaSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf("\tprotected List<%s> %s;\n", fieldType, genJavaFieldName(v.Name))
+			fieldType := gen.genJavaFieldType(gen.getBaseType(v.Base))
+			content := fmt.Sprintf("\t@XmlValue\n\t@XmlList\n\tprotected List<%s> %s;\n", fieldType, gen.fieldName(genJavaFieldName(v.Name)))
 			gen.StructAST[v.Name] = content
-			gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name), gen.StructAST[v.Name])
+			gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, gen.typeName(genJavaFieldName(v.Name)), gen.StructAST[v.Name])
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			content := " {\n"
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
-				fieldType := genJavaFieldType(memberType)
-				content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", fieldType, genJavaFieldName(memberName))
+			for _, member := range v.MemberTypes {
+				fieldType := gen.genJavaFieldType(member.Type)
+				content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(member.Name)))
 			}
 			content += "}\n"
 			gen.StructAST[v.Name] = content
-			fieldName := genJavaFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genJavaFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		}
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		if _, ok := gen.StructAST[v.Name]; !ok {
+			gen.StructAST[v.Name] = gen.genJavaEnumConstants(v.Restriction.Enum)
+			fieldName, xmlEnum := gen.typeName(genJavaFieldName(v.Name)), "@XmlEnum"
+			if fieldType := gen.genJavaFieldType(gen.getBaseType(v.Base)); fieldType != "String" && javaBuildInType[fieldType] {
+				xmlEnum += fmt.Sprintf("(%s.class)", fieldType)
+			}
+			gen.Field += fmt.Sprintf("%s@XmlType(name = \"%s\")\n%s\npublic enum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), v.Name, xmlEnum, fieldName, gen.StructAST[v.Name])
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name))
+		fieldType := gen.genJavaFieldType(gen.getBaseType(v.Base))
+		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(v.Name)))
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%s@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), v.Name, fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genJavaFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%s@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), v.Name, fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
 
+// genJavaEnumConstants generates the constants of the enum type for the
+// enumeration values, the constants are named in SCREAMING_SNAKE_CASE unless
+// the constant naming convention is specified.
+func (gen *CodeGenerator) genJavaEnumConstants(enums []string) string {
+	convention := gen.Naming.Constant
+	if convention == "" {
+		convention = ScreamingSnakeCase
+	}
+	var constants []string
+	for i, name := range enumNames(enums) {
+		constants = append(constants, fmt.Sprintf("\t@XmlEnumValue(%q)\n\t%s", enums[i], gen.escapeReservedWord(ConvertCase(name, convention))))
+	}
+	return strings.Join(constants, ",\n") + ";\n"
+}
+
 // JavaComplexType generates code for complex type XML schema in Java language
 // syntax.
 func (gen *CodeGenerator) JavaComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " {\n"
+		if gen.hasSimpleContent(v) {
+			content += fmt.Sprintf("\t@XmlValue\n\tprotected %s value;\n", gen.genJavaFieldType(gen.getBaseType(v.Base)))
+		} else if v.Mixed {
+			content += "\t@XmlMixed\n\t@XmlAnyElement\n\tprotected List<Object> content;\n"
+		}
 		for _, attrGroup := range v.AttributeGroup {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", genJavaFieldType(fieldType), genJavaFieldName(attrGroup.Name))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", gen.genJavaFieldType(fieldType), gen.fieldName(genJavaFieldName(attrGroup.Name)))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -143,60 +182,221 @@ func (gen *CodeGenerator) JavaComplexType(v *ComplexType) {
 			if attribute.Optional {
 				required = ""
 			}
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %sAttr %s;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name))
+			fieldType := gen.genJavaFieldType(gen.getBaseType(attribute.Type))
+			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s%s)\n\tprotected %sAttr %s%s;\n", attribute.Name, genJavaNamespace(attribute.Namespace), required, fieldType, gen.fieldName(genJavaFieldName(attribute.Name)), gen.genJavaInitializer(attribute.Type, attribute.Default, attribute.Fixed))
 		}
 		for _, group := range v.Groups {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+			var fieldType = gen.genJavaFieldType(gen.getBaseType(group.Ref))
 			if group.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(group.Name))
+			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(group.Name)))
 		}
 
 		for _, element := range v.Elements {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			if element.Choice != "" {
+				continue
+			}
+			fieldType := gen.genJavaFieldType(gen.getBaseType(element.Type))
 			if element.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(element.Name))
+			var initializer string
+			if !element.Plural {
+				initializer = gen.genJavaInitializer(element.Type, element.Default, element.Fixed)
+			}
+			content += fmt.Sprintf("\t@XmlElement(required = true%s, name = \"%s\"%s)\n\tprotected %s %s%s;\n", genJavaNillable(element), element.Name, genJavaNamespace(element.Namespace), fieldType, gen.fieldName(genJavaFieldName(element.Name)), initializer)
 		}
+		fieldName := gen.typeName(genJavaFieldName(v.Name))
+		content += gen.genJavaChoiceFields(fieldName, v.Choices, v.Elements)
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%s%s%s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), genJavaXMLType(v), gen.genJavaClassDeclaration(fieldName, v), gen.StructAST[v.Name])
+		gen.genJavaChoices(fieldName, v.Choices, v.Elements)
 	}
 	return
 }
 
+// genJavaInitializer returns the initializer of the field by given type
+// name, default and fixed value, returns empty string if the value can't be
+// represented as a literal of the field type.
+func (gen *CodeGenerator) genJavaInitializer(typeName, defaultValue, fixed string) string {
+	value, ok := valueConstraint(defaultValue, fixed)
+	if !ok {
+		return ""
+	}
+	switch gen.genJavaFieldType(gen.getSimpleBaseType(typeName)) {
+	case "String":
+		return " = " + strconv.Quote(value)
+	case "Boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return " = " + strconv.FormatBool(b)
+		}
+	case "Integer":
+		if i, err := strconv.ParseInt(value, 10, 32); err == nil {
+			return " = " + strconv.FormatInt(i, 10)
+		}
+	case "Long":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return " = " + strconv.FormatInt(i, 10) + "L"
+		}
+	case "Float":
+		if f, err := strconv.ParseFloat(value, 32); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return " = " + strconv.FormatFloat(f, 'g', -1, 32) + "f"
+		}
+	}
+	return ""
+}
+
+// genJavaNamespace returns the namespace parameter of the annotations by given
+// namespace, returns empty string if the namespace is empty.
+func genJavaNamespace(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf(", namespace = %q", namespace)
+}
+
+// genJavaNillable returns the nillable parameter of the @XmlElement annotation
+// for the nillable element, the JAXB marshals the null field as the element
+// with the xsi:nil attribute.
+func genJavaNillable(element Element) string {
+	if element.Nillable {
+		return ", nillable = true"
+	}
+	return ""
+}
+
+// genJavaClassDeclaration returns the declaration of the class for the complex
+// type by given class name. The class extends the class of the complex type
+// it extends, and lists the classes derived from it directly in the
+// @XmlSeeAlso annotation, which the JAXB dispatches the xsi:type attribute
+// on.
+func (gen *CodeGenerator) genJavaClassDeclaration(className string, v *ComplexType) string {
+	declaration := fmt.Sprintf("public class %s", className)
+	if v.Abstract {
+		declaration = fmt.Sprintf("public abstract class %s", className)
+	}
+	if base := gen.complexBase(v); base != "" {
+		declaration += " extends " + gen.genJavaFieldType(gen.getBaseType(base))
+	}
+	var classes []string
+	for _, derived := range gen.derivedTypes(v.Name) {
+		if derived.Base == v.Name {
+			classes = append(classes, gen.typeName(genJavaFieldName(derived.Name))+".class")
+		}
+	}
+	if len(classes) > 0 {
+		declaration = fmt.Sprintf("@XmlSeeAlso({%s})\n%s", strings.Join(classes, ", "), declaration)
+	}
+	return declaration
+}
+
 // JavaGroup generates code for group XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " {\n"
 		for _, element := range v.Elements {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			if element.Choice != "" {
+				continue
+			}
+			var fieldType = gen.genJavaFieldType(gen.getBaseType(element.Type))
 			if element.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(element.Name))
+			var initializer string
+			if !element.Plural {
+				initializer = gen.genJavaInitializer(element.Type, element.Default, element.Fixed)
+			}
+			content += fmt.Sprintf("\t@XmlElement(required = true%s, name = \"%s\"%s)\n\tprotected %s %s%s;\n", genJavaNillable(element), element.Name, genJavaNamespace(element.Namespace), fieldType, gen.fieldName(genJavaFieldName(element.Name)), initializer)
 		}
 
 		for _, group := range v.Groups {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+			var fieldType = gen.genJavaFieldType(gen.getBaseType(group.Ref))
 			if group.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(group.Name))
+			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(group.Name)))
 		}
 
+		fieldName := gen.typeName(genJavaFieldName(v.Name))
+		content += gen.genJavaChoiceFields(fieldName, v.Choices, v.Elements)
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%s%spublic class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), genJavaPropOrder(v.Unordered), fieldName, gen.StructAST[v.Name])
+		gen.genJavaChoices(fieldName, v.Choices, v.Elements)
 	}
 	return
 }
 
+// genJavaXMLType returns the XmlType annotation of the class by given complex
+// type, the annotation qualifies the type name with the target namespace.
+func genJavaXMLType(v *ComplexType) string {
+	if v.Namespace == "" {
+		return genJavaPropOrder(v.Unordered)
+	}
+	var propOrder string
+	if v.Unordered {
+		propOrder = ", propOrder = {}"
+	}
+	return fmt.Sprintf("@XmlType(name = \"%s\"%s%s)\n", v.Name, genJavaNamespace(v.Namespace), propOrder)
+}
+
+// genJavaPropOrder returns the annotation of the class whose elements may
+// occur in any order, the empty property order maps the class to xs:all.
+func genJavaPropOrder(unordered bool) string {
+	if unordered {
+		return "@XmlType(propOrder = {})\n"
+	}
+	return ""
+}
+
+// genJavaChoiceFields generates the fields of the choices in the class by
+// given type name, the field holds the alternatives in the document order if
+// the choice or any alternative is repeatable.
+func (gen *CodeGenerator) genJavaChoiceFields(typeName string, choices []Choice, elements []Element) (content string) {
+	for _, choice := range choices {
+		choiceName := typeName + choice.Name
+		plural := choice.Plural
+		var annotations []string
+		for _, element := range choiceAlternatives(choice, elements) {
+			annotations = append(annotations, fmt.Sprintf("\t\t@XmlElement(name = \"%s\", type = %s%s.class)", element.Name, typeName, gen.fieldName(genJavaFieldName(element.Name))))
+			plural = plural || element.Plural
+		}
+		fieldType := choiceName
+		if plural {
+			fieldType = fmt.Sprintf("List<%s>", choiceName)
+		}
+		content += fmt.Sprintf("\t@XmlElements({\n%s\n\t})\n\tprotected %s %s;\n", strings.Join(annotations, ",\n"), fieldType, gen.fieldName(choice.Name))
+	}
+	return
+}
+
+// genJavaChoices generates the interfaces of the choices in the class by
+// given type name and the classes of the alternatives implementing them. The
+// alternatives of the complex types extend the classes of the types, and the
+// others hold the values.
+func (gen *CodeGenerator) genJavaChoices(typeName string, choices []Choice, elements []Element) {
+	for _, choice := range choices {
+		choiceName := typeName + choice.Name
+		alternatives := choiceAlternatives(choice, elements)
+		var names []string
+		for _, element := range alternatives {
+			names = append(names, element.Name)
+		}
+		gen.Field += fmt.Sprintf("\n// %s is one of the %s elements in %s.\npublic interface %s {\n}\n", choiceName, strings.Join(names, ", "), typeName, choiceName)
+		for _, element := range alternatives {
+			variant := typeName + gen.fieldName(genJavaFieldName(element.Name))
+			fieldType := gen.genJavaFieldType(gen.getBaseType(element.Type))
+			if _, ok := javaBuildInType[fieldType]; !ok {
+				gen.Field += fmt.Sprintf("\n// %s is the %s alternative of %s.\npublic class %s extends %s implements %s {\n}\n", variant, element.Name, choiceName, variant, fieldType, choiceName)
+				continue
+			}
+			gen.Field += fmt.Sprintf("\n// %s is the %s alternative of %s.\n@XmlAccessorType(XmlAccessType.FIELD)\npublic class %s implements %s {\n\t@XmlValue\n\tprotected %s value;\n}\n", variant, element.Name, choiceName, variant, choiceName, fieldType)
+		}
+	}
+}
+
 // JavaAttributeGroup generates code for attribute group XML schema in Java language
 // syntax.
 func (gen *CodeGenerator) JavaAttributeGroup(v *AttributeGroup) {
@@ -207,13 +407,13 @@ func (gen *CodeGenerator) JavaAttributeGroup(v *AttributeGroup) {
 			if attribute.Optional {
 				required = ""
 			}
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %sAttr %s;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name))
+			fieldType := gen.genJavaFieldType(gen.getBaseType(attribute.Type))
+			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s%s)\n\tprotected %sAttr %s%s;\n", attribute.Name, genJavaNamespace(attribute.Namespace), required, fieldType, gen.fieldName(genJavaFieldName(attribute.Name)), gen.genJavaInitializer(attribute.Type, attribute.Default, attribute.Fixed))
 		}
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genJavaFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -221,27 +421,49 @@ func (gen *CodeGenerator) JavaAttributeGroup(v *AttributeGroup) {
 // JavaElement generates code for element XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaElement(v *Element) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var fieldType = gen.genJavaFieldType(gen.getBaseType(v.Type))
 		if v.Plural {
 			fieldType = fmt.Sprintf("List<%s>", fieldType)
 		}
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name))
+		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(v.Name)))
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlElement(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name), gen.StructAST[v.Name])
+		extends := gen.genJavaSubstitutionGroup(v)
+		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlRootElement(name = \"%s\"%s)\npublic class %s%s {\n%s}\n", v.Name, genJavaNamespace(v.Namespace), gen.typeName(genJavaFieldName(v.Name)), extends, gen.StructAST[v.Name])
 	}
 	return
 }
 
+// genJavaSubstitutionGroup generates the abstract class of the substitution
+// group headed by the element, which extends the class of the substitution
+// group the element could be substituted for. Returns the extends clause of
+// the class of the element.
+func (gen *CodeGenerator) genJavaSubstitutionGroup(v *Element) string {
+	var extends string
+	if heads := substitutionHeads(v, gen.globalElements()); len(heads) > 0 {
+		extends = fmt.Sprintf(" extends %sGroup", gen.typeName(genJavaFieldName(heads[0])))
+	}
+	if members := gen.substitutionMembers(v.Name); len(members) > 0 {
+		var names []string
+		for _, member := range members {
+			names = append(names, member.Name)
+		}
+		groupName := gen.typeName(genJavaFieldName(v.Name)) + "Group"
+		gen.Field += fmt.Sprintf("\n// %s is extended by the %s element and the elements in its substitution\n// group: %s.\npublic abstract class %s%s {\n}\n", groupName, v.Name, strings.Join(names, ", "), groupName, extends)
+		extends = " extends " + groupName
+	}
+	return extends
+}
+
 // JavaAttribute generates code for attribute XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var fieldType = gen.genJavaFieldType(gen.getBaseType(v.Type))
 		if v.Plural {
 			fieldType = fmt.Sprintf("List<%s>", fieldType)
 		}
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name))
+		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, gen.fieldName(genJavaFieldName(v.Name)))
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name), gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, gen.typeName(genJavaFieldName(v.Name)), gen.StructAST[v.Name])
 	}
 	return
 }
@@ -0,0 +1,361 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var juliaBuildInType = map[string]bool{
+	"BigInt":         true,
+	"Bool":           true,
+	"EzXML.Node":     true,
+	"Float32":        true,
+	"Float64":        true,
+	"Int16":          true,
+	"Int32":          true,
+	"Int64":          true,
+	"Int8":           true,
+	"String":         true,
+	"UInt16":         true,
+	"UInt32":         true,
+	"UInt64":         true,
+	"UInt8":          true,
+	"Vector{String}": true,
+}
+
+// juliaHelpers defines the helper functions of the generated module, the
+// converters are passed as the first argument.
+const juliaHelpers = `xgen_children(node::EzXML.Node, name::AbstractString) =
+    filter(child -> nodename(child) == name, elements(node))
+
+function xgen_attribute(convert, node::EzXML.Node, name::AbstractString, optional::Bool = false)
+    if !haskey(node, name)
+        optional && return nothing
+        error("missing attribute $(name) in $(nodename(node))")
+    end
+    convert(node[name])
+end
+
+function xgen_element(convert, node::EzXML.Node, name::AbstractString, optional::Bool = false)
+    children = xgen_children(node, name)
+    if isempty(children)
+        optional && return nothing
+        error("missing element $(name) in $(nodename(node))")
+    end
+    convert(first(children))
+end
+
+xgen_elements(convert, node::EzXML.Node, name::AbstractString) =
+    map(convert, xgen_children(node, name))
+
+xgen_text(convert) = node -> convert(nodecontent(node))
+`
+
+// GenJulia generate Julia programming language source code for XML schema
+// definition files. The complex types are generated as structs with the
+// constructors create them from the elements parsed by EzXML.jl. Julia
+// doesn't support forward declarations, so each struct has an abstract super
+// type declared ahead, which is used to reference the struct in the fields.
+func (gen *CodeGenerator) GenJulia() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Julia%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	module := "Schema"
+	if gen.Package != "" {
+		module = MakeFirstUpperCase(gen.Package)
+	}
+	importPackage := "using EzXML\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("using %s\n", mapping.Import)
+	}
+	var forwards string
+	if gen.Forwards != "" {
+		forwards = "\n" + gen.Forwards
+	}
+	source := []byte(fmt.Sprintf("# %s\n\nmodule %s\n\n%s\n%s%s%s\nend\n", strings.TrimPrefix(copyright, "// "), module, importPackage, juliaHelpers, forwards, gen.Field))
+	return gen.writeFile(gen.File+".jl", source)
+}
+
+func genJuliaFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genJuliaPropertyName generates the snake case field name for Julia code.
+func (gen *CodeGenerator) genJuliaPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genJuliaFieldName(name), SnakeCase))
+}
+
+// genJuliaFieldType generates the type for Julia code, the structs are
+// referenced by their abstract super types.
+func (gen *CodeGenerator) genJuliaFieldType(name string) string {
+	if _, ok := juliaBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		return fmt.Sprintf("Vector{%s}", gen.genJuliaFieldType(gen.getBaseType(v.Base)))
+	} else if v != nil {
+		return "String"
+	}
+	fieldType := genJuliaFieldName(name)
+	if fieldType == "" {
+		return "EzXML.Node"
+	}
+	return "Abstract" + gen.typeName(fieldType)
+}
+
+// genJuliaConverter generates the function converts the text to the value of
+// the given simple type, it returns false if the given type isn't a simple
+// type.
+func (gen *CodeGenerator) genJuliaConverter(name string) (string, bool) {
+	switch fieldType := gen.genJuliaFieldType(name); {
+	case fieldType == "String":
+		return "identity", true
+	case fieldType == "Vector{String}":
+		return "x -> String.(split(x))", true
+	case strings.HasPrefix(fieldType, "Vector{"):
+		return fmt.Sprintf("x -> parse.(%s, split(x))", strings.TrimSuffix(strings.TrimPrefix(fieldType, "Vector{"), "}")), true
+	case juliaBuildInType[fieldType] && fieldType != "EzXML.Node":
+		return fmt.Sprintf("x -> parse(%s, x)", fieldType), true
+	}
+	return "", false
+}
+
+// genJuliaNodeConverter generates the function converts the element to the
+// value of the given type.
+func (gen *CodeGenerator) genJuliaNodeConverter(name string) string {
+	if converter, ok := gen.genJuliaConverter(name); ok {
+		return fmt.Sprintf("xgen_text(%s)", converter)
+	}
+	fieldType := gen.genJuliaFieldType(name)
+	if fieldType == "EzXML.Node" {
+		return "identity"
+	}
+	return strings.TrimPrefix(fieldType, "Abstract")
+}
+
+// genJuliaField generates the field declaration and the argument of the
+// constructor by given XML name of the element or attribute. The optional
+// field may be nothing and the plural field is a vector.
+func (gen *CodeGenerator) genJuliaField(name, typeName string, attribute, plural, optional bool) (field, argument string) {
+	baseType := gen.getBaseType(typeName)
+	fieldType, xmlName := gen.genJuliaFieldType(baseType), templateQuote(name)
+	var optionalArg string
+	if optional {
+		optionalArg = ", true"
+	}
+	switch {
+	case attribute:
+		converter, ok := gen.genJuliaConverter(baseType)
+		if !ok {
+			converter = "identity"
+		}
+		argument = fmt.Sprintf("xgen_attribute(%s, node, %s%s)", converter, xmlName, optionalArg)
+	case plural:
+		argument = fmt.Sprintf("xgen_elements(%s, node, %s)", gen.genJuliaNodeConverter(baseType), xmlName)
+	default:
+		argument = fmt.Sprintf("xgen_element(%s, node, %s%s)", gen.genJuliaNodeConverter(baseType), xmlName, optionalArg)
+	}
+	return genJuliaFieldDeclaration(gen.genJuliaPropertyName(name), fieldType, plural, optional), argument
+}
+
+// genJuliaInlineField generates the field declaration and the argument of the
+// constructor for the group or attribute group, which is created from the
+// enclosing element.
+func (gen *CodeGenerator) genJuliaInlineField(name, ref string, plural bool) (field, argument string) {
+	fieldType := gen.genJuliaFieldType(gen.getBaseType(ref))
+	argument = fmt.Sprintf("%s(node)", strings.TrimPrefix(fieldType, "Abstract"))
+	if plural {
+		argument = fmt.Sprintf("[%s(node)]", strings.TrimPrefix(fieldType, "Abstract"))
+	}
+	return genJuliaFieldDeclaration(gen.genJuliaPropertyName(name), fieldType, plural, false), argument
+}
+
+// genJuliaFieldDeclaration generates the declaration of the field of the
+// struct.
+func genJuliaFieldDeclaration(name, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		return fmt.Sprintf("    %s::Vector{%s}\n", name, fieldType)
+	case optional:
+		return fmt.Sprintf("    %s::Union{%s, Nothing}\n", name, fieldType)
+	}
+	return fmt.Sprintf("    %s::%s\n", name, fieldType)
+}
+
+// genJuliaStruct generates the struct, its abstract super type and the
+// constructor by given declaration, fields and the arguments of the default
+// constructor.
+func (gen *CodeGenerator) genJuliaStruct(name, doc, source, location, deprecated string, fields, arguments []string) {
+	fieldName := gen.typeName(genJuliaFieldName(name))
+	content := fmt.Sprintf("struct %s <: Abstract%s\n%send\n", fieldName, fieldName, strings.Join(fields, ""))
+	if len(fields) == 0 {
+		content = fmt.Sprintf("struct %s <: Abstract%s end\n", fieldName, fieldName)
+	}
+	construct := fieldName + "()"
+	if len(arguments) > 0 {
+		construct = fmt.Sprintf("%s(\n        %s,\n    )", fieldName, strings.Join(arguments, ",\n        "))
+	}
+	content += fmt.Sprintf("\n# %s creates %s from the element parsed by EzXML.\nfunction %s(node::EzXML.Node)\n    %s\nend\n", fieldName, fieldName, fieldName, construct)
+	gen.StructAST[name] = content
+	gen.Forwards += fmt.Sprintf("abstract type Abstract%s end\n", fieldName)
+	gen.Field += genFieldComment(fieldName, doc, source, location, "#") + gen.genDeprecated(deprecated) + content
+}
+
+// genJuliaAlias generates the type alias by given declaration and type.
+func (gen *CodeGenerator) genJuliaAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genJuliaFieldName(name))
+	gen.Field += fmt.Sprintf("%sconst %s = %s\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// JuliaSimpleType generates code for simple type XML schema in Julia language
+// syntax. The enumerations are generated as the modules of the constants, the
+// other simple types are generated as the type aliases.
+func (gen *CodeGenerator) JuliaSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union {
+		gen.genJuliaAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genJuliaFieldType(v.Name))
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.genJuliaAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genJuliaFieldType(gen.getBaseType(v.Base)))
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("const %s = %s\n", member, templateQuote(enum))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genJuliaFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%smodule %s\n%send\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// JuliaComplexType generates code for complex type XML schema in Julia
+// language syntax.
+func (gen *CodeGenerator) JuliaComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, arguments []string
+	for _, attrGroup := range v.AttributeGroup {
+		field, argument := gen.genJuliaInlineField(attrGroup.Name, attrGroup.Ref, false)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	for _, attribute := range v.Attributes {
+		field, argument := gen.genJuliaField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		field, argument := gen.genJuliaInlineField(group.Name, group.Ref, group.Plural)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	for _, element := range v.Elements {
+		field, argument := gen.genJuliaField(element.Name, element.Type, false, element.Plural, element.Optional)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	gen.genJuliaStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, arguments)
+	return
+}
+
+// JuliaGroup generates code for group XML schema in Julia language syntax.
+func (gen *CodeGenerator) JuliaGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, arguments []string
+	for _, element := range v.Elements {
+		field, argument := gen.genJuliaField(element.Name, element.Type, false, element.Plural, element.Optional)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		field, argument := gen.genJuliaInlineField(group.Name, group.Ref, group.Plural)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	gen.genJuliaStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, arguments)
+	return
+}
+
+// JuliaAttributeGroup generates code for attribute group XML schema in Julia
+// language syntax.
+func (gen *CodeGenerator) JuliaAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, arguments []string
+	for _, attribute := range v.Attributes {
+		field, argument := gen.genJuliaField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		fields, arguments = append(fields, field), append(arguments, argument)
+	}
+	gen.genJuliaStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, arguments)
+	return
+}
+
+// JuliaElement generates code for element XML schema in Julia language
+// syntax. The element is generated as the parse function, which parses the
+// XML document with the element as the root.
+func (gen *CodeGenerator) JuliaElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genJuliaFieldName(v.Name))
+	baseType := gen.getBaseType(v.Type)
+	converter := gen.genJuliaNodeConverter(baseType)
+	if converter == fieldName {
+		gen.StructAST[v.Name] = converter
+		return
+	}
+	functionName := "parse_" + ConvertCase(fieldName, SnakeCase)
+	content := fmt.Sprintf("function %s(xml::AbstractString)\n    %s(root(parsexml(xml)))\nend\n", functionName, converter)
+	gen.StructAST[v.Name] = content
+	gen.Field += fmt.Sprintf("\n# %s parses the XML document with the %s root element.\n%s", functionName, v.Name, gen.genDeprecated(v.Deprecated)+content)
+	return
+}
+
+// JuliaAttribute generates code for attribute XML schema in Julia language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) JuliaAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
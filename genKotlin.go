@@ -0,0 +1,299 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var kotlinBuildInType = map[string]bool{
+	"Any":           true,
+	"BigDecimal":    true,
+	"BigInteger":    true,
+	"Boolean":       true,
+	"Byte":          true,
+	"ByteArray":     true,
+	"Double":        true,
+	"Duration":      true,
+	"Float":         true,
+	"Int":           true,
+	"List<String>":  true,
+	"LocalDate":     true,
+	"LocalDateTime": true,
+	"LocalTime":     true,
+	"Long":          true,
+	"QName":         true,
+	"Short":         true,
+	"String":        true,
+}
+
+// kotlinTypeImports defines the imports required by the built-in types of
+// Kotlin.
+var kotlinTypeImports = map[string]string{
+	"BigDecimal":    "java.math.BigDecimal",
+	"BigInteger":    "java.math.BigInteger",
+	"Duration":      "java.time.Duration",
+	"LocalDate":     "java.time.LocalDate",
+	"LocalDateTime": "java.time.LocalDateTime",
+	"LocalTime":     "java.time.LocalTime",
+	"QName":         "javax.xml.namespace.QName",
+}
+
+// GenKotlin generate Kotlin programming language source code for XML schema
+// definition files. The types are generated as data classes annotated for
+// the Jackson XML data format.
+func (gen *CodeGenerator) GenKotlin() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Kotlin%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	imports := []string{
+		"com.fasterxml.jackson.annotation.JsonProperty",
+		"com.fasterxml.jackson.dataformat.xml.annotation.JacksonXmlElementWrapper",
+		"com.fasterxml.jackson.dataformat.xml.annotation.JacksonXmlProperty",
+	}
+	for name := range gen.ImportBuildIn {
+		imports = append(imports, kotlinTypeImports[name])
+	}
+	for _, mapping := range gen.getImportMappings() {
+		imports = append(imports, mapping.Import)
+	}
+	sort.Strings(imports)
+	var importPackage string
+	for i, name := range imports {
+		if i > 0 && imports[i-1] == name {
+			continue
+		}
+		importPackage += fmt.Sprintf("import %s\n", name)
+	}
+	source := []byte(fmt.Sprintf("%s\n\npackage %s\n\n%s%s", copyright, packageName, importPackage, gen.Field))
+	return gen.writeFile(gen.File+".kt", source)
+}
+
+func genKotlinFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genKotlinPropertyName generates the camel case property name for Kotlin
+// code.
+func (gen *CodeGenerator) genKotlinPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genKotlinFieldName(name), CamelCase))
+}
+
+// genKotlinFieldType generates the type for Kotlin code, the imports required
+// by the built-in types are recorded.
+func (gen *CodeGenerator) genKotlinFieldType(name string, plural bool) (fieldType string) {
+	if _, ok := kotlinBuildInType[name]; ok {
+		fieldType = name
+		if _, ok = kotlinTypeImports[name]; ok {
+			if gen.ImportBuildIn == nil {
+				gen.ImportBuildIn = map[string]bool{}
+			}
+			gen.ImportBuildIn[name] = true
+		}
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+	} else if fieldType = genKotlinFieldName(name); fieldType == "" {
+		fieldType = "Any"
+	} else {
+		fieldType = gen.typeName(fieldType)
+	}
+	if plural {
+		fieldType = fmt.Sprintf("List<%s>", fieldType)
+	}
+	return
+}
+
+// genKotlinProperty generates the constructor property of the data class by
+// given XML name of the element or attribute, the optional property defaults
+// to null and the plural property defaults to an empty list which is not
+// wrapped in the XML.
+func (gen *CodeGenerator) genKotlinProperty(name, fieldType string, attribute, plural, optional bool) string {
+	var annotations string
+	if name != "" {
+		isAttribute := ""
+		if attribute {
+			isAttribute = ", isAttribute = true"
+		}
+		annotations = fmt.Sprintf("\t@field:JacksonXmlProperty(localName = %s%s)\n", templateQuote(name), isAttribute)
+	}
+	switch {
+	case plural:
+		if name != "" {
+			annotations += "\t@field:JacksonXmlElementWrapper(useWrapping = false)\n"
+		}
+		return fmt.Sprintf("%s\tval %%s: %s = emptyList(),\n", annotations, fieldType)
+	case optional:
+		return fmt.Sprintf("%s\tval %%s: %s? = null,\n", annotations, fieldType)
+	}
+	return fmt.Sprintf("%s\tval %%s: %s,\n", annotations, fieldType)
+}
+
+// genKotlinElement generates the property for the element.
+func (gen *CodeGenerator) genKotlinElement(element Element) string {
+	property := gen.genKotlinProperty(element.Name, gen.genKotlinFieldType(gen.getBaseType(element.Type), element.Plural), false, element.Plural, element.Optional)
+	return fmt.Sprintf(property, gen.genKotlinPropertyName(element.Name))
+}
+
+// genKotlinAttribute generates the property for the attribute.
+func (gen *CodeGenerator) genKotlinAttribute(attribute Attribute) string {
+	property := gen.genKotlinProperty(attribute.Name, gen.genKotlinFieldType(gen.getBaseType(attribute.Type), attribute.Plural), true, attribute.Plural, attribute.Optional)
+	return fmt.Sprintf(property, gen.genKotlinPropertyName(attribute.Name))
+}
+
+// genKotlinClass generates the data class by given declaration and
+// properties, a class without properties is generated as a plain class.
+func (gen *CodeGenerator) genKotlinClass(name, doc, source, location, deprecated, content string) {
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genKotlinFieldName(name))
+	if content == "" {
+		gen.Field += fmt.Sprintf("%sclass %s\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName)
+		return
+	}
+	gen.Field += fmt.Sprintf("%sdata class %s(\n%s)\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genKotlinAlias generates the type alias by given declaration and type.
+func (gen *CodeGenerator) genKotlinAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genKotlinFieldName(name))
+	gen.Field += fmt.Sprintf("%stypealias %s = %s\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// KotlinSimpleType generates code for simple type XML schema in Kotlin
+// language syntax.
+func (gen *CodeGenerator) KotlinSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genKotlinAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genKotlinFieldType(gen.getBaseType(v.Base), true))
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var content string
+		for _, member := range v.MemberTypes {
+			content += fmt.Sprintf(gen.genKotlinProperty("", gen.genKotlinFieldType(gen.getBaseType(member.Type), false), false, false, true), gen.genKotlinPropertyName(member.Name))
+		}
+		gen.genKotlinClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := gen.constantName(ConvertCase(enum, ScreamingSnakeCase))
+			if member == "" || (member[0] >= '0' && member[0] <= '9') {
+				member = "VALUE_" + member
+			}
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content = append(content, fmt.Sprintf("\t@JsonProperty(%s)\n\t%s(%s)", templateQuote(enum), member, templateQuote(enum)))
+		}
+		gen.StructAST[v.Name] = strings.Join(content, ",\n")
+		fieldName := gen.typeName(genKotlinFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%senum class %s(val value: String) {\n%s,\n}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		return
+	}
+	gen.genKotlinAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genKotlinFieldType(gen.getBaseType(v.Base), false))
+	return
+}
+
+// KotlinComplexType generates code for complex type XML schema in Kotlin
+// language syntax.
+func (gen *CodeGenerator) KotlinComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, attrGroup := range v.AttributeGroup {
+		content += fmt.Sprintf(gen.genKotlinProperty("", gen.genKotlinFieldType(gen.getBaseType(attrGroup.Ref), false), false, false, true), gen.genKotlinPropertyName(attrGroup.Name))
+	}
+	for _, attribute := range v.Attributes {
+		content += gen.genKotlinAttribute(attribute)
+	}
+	for _, group := range v.Groups {
+		content += fmt.Sprintf(gen.genKotlinProperty("", gen.genKotlinFieldType(gen.getBaseType(group.Ref), group.Plural), false, group.Plural, true), gen.genKotlinPropertyName(group.Name))
+	}
+	for _, element := range v.Elements {
+		content += gen.genKotlinElement(element)
+	}
+	gen.genKotlinClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// KotlinGroup generates code for group XML schema in Kotlin language syntax.
+func (gen *CodeGenerator) KotlinGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, element := range v.Elements {
+		content += gen.genKotlinElement(element)
+	}
+	for _, group := range v.Groups {
+		content += fmt.Sprintf(gen.genKotlinProperty("", gen.genKotlinFieldType(gen.getBaseType(group.Ref), group.Plural), false, group.Plural, true), gen.genKotlinPropertyName(group.Name))
+	}
+	gen.genKotlinClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// KotlinAttributeGroup generates code for attribute group XML schema in
+// Kotlin language syntax.
+func (gen *CodeGenerator) KotlinAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, attribute := range v.Attributes {
+		content += gen.genKotlinAttribute(attribute)
+	}
+	gen.genKotlinClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// KotlinElement generates code for element XML schema in Kotlin language
+// syntax.
+func (gen *CodeGenerator) KotlinElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genKotlinAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genKotlinFieldType(gen.getBaseType(v.Type), v.Plural))
+	}
+	return
+}
+
+// KotlinAttribute generates code for attribute XML schema in Kotlin language
+// syntax.
+func (gen *CodeGenerator) KotlinAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genKotlinAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genKotlinFieldType(gen.getBaseType(v.Type), v.Plural))
+	}
+	return
+}
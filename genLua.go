@@ -0,0 +1,432 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var luaBuildInType = map[string]bool{
+	"any":      true,
+	"boolean":  true,
+	"integer":  true,
+	"number":   true,
+	"string":   true,
+	"string[]": true,
+}
+
+// luaConverters defines the functions convert the text of the nodes to the
+// values of the built-in types of Lua.
+var luaConverters = map[string]string{
+	"boolean":  "xgen.boolean",
+	"integer":  "tonumber",
+	"number":   "tonumber",
+	"string":   "xgen.string",
+	"string[]": "xgen.words",
+}
+
+// luaHelpers defines the private helper functions of the generated module, the
+// nodes are the tables built by the lxp.lom module of LuaExpat.
+const luaHelpers = `local xgen = {}
+
+local function local_name(name)
+  return (name:gsub("^.*:", ""))
+end
+
+function xgen.parse(xml)
+  local node, err = lom.parse(xml)
+  if not node then
+    error(err, 2)
+  end
+  return node
+end
+
+function xgen.children(node, name)
+  local children = {}
+  for _, child in ipairs(node) do
+    if type(child) == "table" and local_name(child.tag) == name then
+      children[#children + 1] = child
+    end
+  end
+  return children
+end
+
+function xgen.text(node)
+  local text = {}
+  for _, child in ipairs(node) do
+    if type(child) == "string" then
+      text[#text + 1] = child
+    end
+  end
+  return table.concat(text)
+end
+
+function xgen.attribute(node, name, convert, optional)
+  local value = node.attr[name]
+  if value == nil then
+    if optional then
+      return nil
+    end
+    error(string.format("missing attribute %q in %s", name, node.tag), 0)
+  end
+  return convert(value)
+end
+
+function xgen.element(node, name, convert, optional)
+  local child = xgen.children(node, name)[1]
+  if child == nil then
+    if optional then
+      return nil
+    end
+    error(string.format("missing element %q in %s", name, node.tag), 0)
+  end
+  return convert(child)
+end
+
+function xgen.elements(node, name, convert)
+  local values = {}
+  for i, child in ipairs(xgen.children(node, name)) do
+    values[i] = convert(child)
+  end
+  return values
+end
+
+function xgen.value(convert)
+  return function(node)
+    return convert(xgen.text(node))
+  end
+end
+
+function xgen.node(node)
+  return node
+end
+
+function xgen.string(value)
+  return value
+end
+
+function xgen.boolean(value)
+  return value == "true" or value == "1"
+end
+
+function xgen.words(value)
+  local words = {}
+  for word in value:gmatch("%S+") do
+    words[#words + 1] = word
+  end
+  return words
+end
+
+function xgen.list(convert)
+  return function(value)
+    local values = xgen.words(value)
+    for i, v in ipairs(values) do
+      values[i] = convert(v)
+    end
+    return values
+  end
+end
+`
+
+// GenLua generate Lua programming language source code for XML schema
+// definition files. The generated module returns a table of the types, each
+// complex type is a class table with the new constructor and the from_node
+// deserializer, which creates the object from the node built by the lxp.lom
+// module of LuaExpat. The types are annotated for the Lua language server.
+func (gen *CodeGenerator) GenLua() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Lua%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "local lom = require(\"lxp.lom\")\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("local %s = require(%s)\n", mapping.Type, strconv.Quote(mapping.Import))
+	}
+	source := []byte(fmt.Sprintf("-- %s\n\n%s\n%s\nlocal M = {}\n%s\nreturn M\n", strings.TrimPrefix(copyright, "// "), importPackage, luaHelpers, gen.Field))
+	return gen.writeFile(gen.File+".lua", source)
+}
+
+func genLuaFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genLuaPropertyName generates the snake case field name for Lua code.
+func (gen *CodeGenerator) genLuaPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genLuaFieldName(name), SnakeCase))
+}
+
+// genLuaFieldType generates the type annotation for Lua code. The list and
+// union simple types are referenced by the aliases generated for them, the
+// values of the union simple types are kept as strings.
+func (gen *CodeGenerator) genLuaFieldType(name string) string {
+	if _, ok := luaBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genLuaFieldName(name)
+	if fieldType == "" {
+		return "any"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genLuaConverter generates the function converts the text of the node to the
+// value of the given simple type, it returns false if the given type isn't a
+// simple type. The mapped types are deserialized from the nodes by their
+// from_node functions.
+func (gen *CodeGenerator) genLuaConverter(name string) (string, bool) {
+	if converter, ok := luaConverters[name]; ok {
+		return converter, true
+	}
+	if _, ok := gen.TypeMapping[name]; ok {
+		return "", false
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		converter, _ := gen.genLuaConverter(gen.getBaseType(v.Base))
+		if converter == "xgen.string" {
+			return "xgen.words", true
+		}
+		return fmt.Sprintf("xgen.list(%s)", converter), true
+	} else if v != nil {
+		return "xgen.string", true
+	}
+	return "", false
+}
+
+// genLuaNodeConverter generates the function converts the node to the value
+// of the given type.
+func (gen *CodeGenerator) genLuaNodeConverter(name string) string {
+	if converter, ok := gen.genLuaConverter(name); ok {
+		return fmt.Sprintf("xgen.value(%s)", converter)
+	}
+	fieldType := gen.genLuaFieldType(name)
+	if _, ok := gen.TypeMapping[name]; ok {
+		return fieldType + ".from_node"
+	}
+	if fieldType == "any" {
+		return "xgen.node"
+	}
+	return fmt.Sprintf("M.%s.from_node", fieldType)
+}
+
+// genLuaField generates the annotation and the table constructor field of the
+// deserializer by given XML name of the element or attribute.
+func (gen *CodeGenerator) genLuaField(name, typeName string, attribute, plural, optional bool) (annotation, field string) {
+	baseType := gen.getBaseType(typeName)
+	fieldType, fieldName := gen.genLuaFieldType(baseType), gen.genLuaPropertyName(name)
+	var optionalArg string
+	if optional {
+		optionalArg = ", true"
+	}
+	switch {
+	case attribute:
+		converter, ok := gen.genLuaConverter(baseType)
+		if !ok {
+			converter = "xgen.string"
+		}
+		field = fmt.Sprintf("%s = xgen.attribute(node, %s, %s%s)", fieldName, strconv.Quote(name), converter, optionalArg)
+	case plural:
+		field = fmt.Sprintf("%s = xgen.elements(node, %s, %s)", fieldName, strconv.Quote(name), gen.genLuaNodeConverter(baseType))
+	default:
+		field = fmt.Sprintf("%s = xgen.element(node, %s, %s%s)", fieldName, strconv.Quote(name), gen.genLuaNodeConverter(baseType), optionalArg)
+	}
+	return genLuaAnnotation(fieldName, fieldType, plural, optional), field
+}
+
+// genLuaInlineField generates the annotation and the table constructor field
+// for the group or attribute group, which is deserialized from the enclosing
+// node.
+func (gen *CodeGenerator) genLuaInlineField(name, ref string, plural bool) (annotation, field string) {
+	fieldType, fieldName := gen.genLuaFieldType(gen.getBaseType(ref)), gen.genLuaPropertyName(name)
+	field = fmt.Sprintf("%s = M.%s.from_node(node)", fieldName, fieldType)
+	if plural {
+		field = fmt.Sprintf("%s = { M.%s.from_node(node) }", fieldName, fieldType)
+	}
+	return genLuaAnnotation(fieldName, fieldType, plural, false), field
+}
+
+// genLuaAnnotation generates the field annotation of the class for the Lua
+// language server.
+func genLuaAnnotation(name, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		fieldType += "[]"
+	case optional:
+		name += "?"
+	}
+	return fmt.Sprintf("---@field %s %s\n", name, fieldType)
+}
+
+// genLuaClass generates the class table by given declaration, annotations and
+// the table constructor fields of the deserializer.
+func (gen *CodeGenerator) genLuaClass(name, doc, source, location, deprecated string, annotations, fields []string) {
+	fieldName := gen.typeName(genLuaFieldName(name))
+	constructor := "{}"
+	if len(fields) > 0 {
+		constructor = fmt.Sprintf("{\n    %s,\n  }", strings.Join(fields, ",\n    "))
+	}
+	content := fmt.Sprintf("---@class %[1]s\n%[2]sM.%[1]s = {}\nM.%[1]s.__index = M.%[1]s\n\n-- new creates %[1]s with the given fields.\n---@param fields? table\n---@return %[1]s\nfunction M.%[1]s.new(fields)\n  return setmetatable(fields or {}, M.%[1]s)\nend\n\n-- from_node creates %[1]s from the node built by lxp.lom.\n---@param node table\n---@return %[1]s\nfunction M.%[1]s.from_node(node)\n  return M.%[1]s.new(%[3]s)\nend\n", fieldName, strings.Join(annotations, ""), constructor)
+	gen.StructAST[name] = content
+	gen.Field += genFieldComment(fieldName, doc, source, location, "--") + gen.genDeprecated(deprecated) + content
+}
+
+// genLuaAlias generates the type alias annotation by given declaration and
+// type.
+func (gen *CodeGenerator) genLuaAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genLuaFieldName(name))
+	gen.Field += fmt.Sprintf("%s---@alias %s %s\n", genFieldComment(fieldName, doc, source, location, "--")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// LuaSimpleType generates code for simple type XML schema in Lua language
+// syntax. The enumerations are generated as the tables of the constants, the
+// list and union simple types are generated as the type aliases.
+func (gen *CodeGenerator) LuaSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genLuaAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genLuaFieldType(gen.getBaseType(v.Base))+"[]")
+		return
+	}
+	if v.Union {
+		gen.genLuaAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "string")
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = ""
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("  %s = %s,\n", member, strconv.Quote(enum))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genLuaFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%s---@enum %s\nM.%s = {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "--")+gen.genDeprecated(v.Deprecated), fieldName, fieldName, content)
+	return
+}
+
+// LuaComplexType generates code for complex type XML schema in Lua language
+// syntax.
+func (gen *CodeGenerator) LuaComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var annotations, fields []string
+	for _, attrGroup := range v.AttributeGroup {
+		annotation, field := gen.genLuaInlineField(attrGroup.Name, attrGroup.Ref, false)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	for _, attribute := range v.Attributes {
+		annotation, field := gen.genLuaField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	for _, group := range v.Groups {
+		annotation, field := gen.genLuaInlineField(group.Name, group.Ref, group.Plural)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	for _, element := range v.Elements {
+		annotation, field := gen.genLuaField(element.Name, element.Type, false, element.Plural, element.Optional)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	gen.genLuaClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, annotations, fields)
+	return
+}
+
+// LuaGroup generates code for group XML schema in Lua language syntax.
+func (gen *CodeGenerator) LuaGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var annotations, fields []string
+	for _, element := range v.Elements {
+		annotation, field := gen.genLuaField(element.Name, element.Type, false, element.Plural, element.Optional)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	for _, group := range v.Groups {
+		annotation, field := gen.genLuaInlineField(group.Name, group.Ref, group.Plural)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	gen.genLuaClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, annotations, fields)
+	return
+}
+
+// LuaAttributeGroup generates code for attribute group XML schema in Lua
+// language syntax.
+func (gen *CodeGenerator) LuaAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var annotations, fields []string
+	for _, attribute := range v.Attributes {
+		annotation, field := gen.genLuaField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		annotations, fields = append(annotations, annotation), append(fields, field)
+	}
+	gen.genLuaClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, annotations, fields)
+	return
+}
+
+// LuaElement generates code for element XML schema in Lua language syntax.
+// The element is generated as the table with the parse function, which
+// parses the XML document with the element as the root.
+func (gen *CodeGenerator) LuaElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genLuaFieldName(v.Name))
+	baseType := gen.getBaseType(v.Type)
+	fieldType := gen.genLuaFieldType(baseType)
+	if fieldType == fieldName {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	content := fmt.Sprintf("M.%[1]s = {}\n\n-- parse parses the XML document with the %[2]s root element.\n---@param xml string\n---@return %[3]s\nfunction M.%[1]s.parse(xml)\n  return %[4]s(xgen.parse(xml))\nend\n", fieldName, v.Name, fieldType, gen.genLuaNodeConverter(baseType))
+	gen.StructAST[v.Name] = content
+	gen.Field += genFieldComment(fieldName, v.Doc, v.Source, v.Location, "--") + gen.genDeprecated(v.Deprecated) + content
+	return
+}
+
+// LuaAttribute generates code for attribute XML schema in Lua language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) LuaAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
@@ -0,0 +1,406 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var markdownBuildInType = map[string]bool{
+	"xml:base":              true,
+	"xml:id":                true,
+	"xml:lang":              true,
+	"xml:space":             true,
+	"xs:ENTITIES":           true,
+	"xs:ENTITY":             true,
+	"xs:ID":                 true,
+	"xs:IDREF":              true,
+	"xs:IDREFS":             true,
+	"xs:NCName":             true,
+	"xs:NMTOKEN":            true,
+	"xs:NMTOKENS":           true,
+	"xs:NOTATION":           true,
+	"xs:Name":               true,
+	"xs:QName":              true,
+	"xs:anyType":            true,
+	"xs:anyURI":             true,
+	"xs:base64Binary":       true,
+	"xs:boolean":            true,
+	"xs:byte":               true,
+	"xs:date":               true,
+	"xs:dateTime":           true,
+	"xs:dateTimeStamp":      true,
+	"xs:dayTimeDuration":    true,
+	"xs:decimal":            true,
+	"xs:double":             true,
+	"xs:duration":           true,
+	"xs:float":              true,
+	"xs:gDay":               true,
+	"xs:gMonth":             true,
+	"xs:gMonthDay":          true,
+	"xs:gYear":              true,
+	"xs:gYearMonth":         true,
+	"xs:hexBinary":          true,
+	"xs:int":                true,
+	"xs:integer":            true,
+	"xs:language":           true,
+	"xs:long":               true,
+	"xs:negativeInteger":    true,
+	"xs:nonNegativeInteger": true,
+	"xs:nonPositiveInteger": true,
+	"xs:normalizedString":   true,
+	"xs:positiveInteger":    true,
+	"xs:short":              true,
+	"xs:string":             true,
+	"xs:time":               true,
+	"xs:token":              true,
+	"xs:unsignedByte":       true,
+	"xs:unsignedInt":        true,
+	"xs:unsignedLong":       true,
+	"xs:unsignedShort":      true,
+	"xs:yearMonthDuration":  true,
+}
+
+// markdownCellReplacer escapes the text in the cells of the tables.
+var markdownCellReplacer = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\t", "")
+
+// markdownKinds defines the kinds of the declarations, which are used in the
+// table of contents and the anchors of the sections.
+var markdownKinds = map[string]string{
+	"SimpleType":     "simple type",
+	"ComplexType":    "complex type",
+	"Group":          "group",
+	"AttributeGroup": "attribute group",
+	"Element":        "element",
+	"Attribute":      "attribute",
+}
+
+// GenMarkdown generate the data dictionary in Markdown for XML schema
+// definition files. Each declaration is rendered as a section with its
+// documentation, and the attributes and elements of the complex types,
+// groups and attribute groups are listed in the tables with their types,
+// cardinality, facets and documentation. The declared types are linked to
+// their sections.
+func (gen *CodeGenerator) GenMarkdown() error {
+	var contents string
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		kind := reflect.TypeOf(ele).String()[6:]
+		if name := declarationName(ele); name != "" {
+			contents += fmt.Sprintf("- [%s](#%s) (%s)\n", markdownCellReplacer.Replace(name), genMarkdownAnchor(kind, name), markdownKinds[kind])
+		}
+		funcName := fmt.Sprintf("Markdown%s", kind)
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	if contents != "" {
+		contents = "\n## Contents\n\n" + contents
+	}
+	source := []byte(fmt.Sprintf("<!-- %s -->\n\n# %s\n%s%s", strings.TrimPrefix(copyright, "// "), filepath.Base(gen.File), contents, strings.Replace(gen.Field, "\r\n", "\n", -1)))
+	return gen.writeFile(gen.File+".md", source)
+}
+
+func genMarkdownFieldName(name string) string {
+	return trimNSPrefix(name)
+}
+
+// genMarkdownAnchor returns the anchor of the section by given kind and name
+// of the declaration.
+func genMarkdownAnchor(kind, name string) string {
+	return strings.Replace(markdownKinds[kind], " ", "-", -1) + "-" + strings.Replace(name, " ", "-", -1)
+}
+
+// genMarkdownType returns the type by given name, the declared simple and
+// complex types are linked to their sections.
+func (gen *CodeGenerator) genMarkdownType(name string) string {
+	if name == "" {
+		return "`xs:anyType`"
+	}
+	if markdownBuildInType[name] {
+		return "`" + name + "`"
+	}
+	if mappedType, ok := gen.getMappedType(trimNSPrefix(name)); ok {
+		return "`" + mappedType + "`"
+	}
+	name = trimNSPrefix(name)
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name {
+				return fmt.Sprintf("[%s](#%s)", name, genMarkdownAnchor("SimpleType", name))
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return fmt.Sprintf("[%s](#%s)", name, genMarkdownAnchor("ComplexType", name))
+			}
+		}
+	}
+	return "`" + name + "`"
+}
+
+// genMarkdownRef returns the link to the group or attribute group by given
+// kind and name.
+func genMarkdownRef(kind, name string) string {
+	name = trimNSPrefix(name)
+	return fmt.Sprintf("[%s](#%s)", name, genMarkdownAnchor(kind, name))
+}
+
+// genMarkdownCardinality returns the cardinality of the attribute or
+// element.
+func genMarkdownCardinality(plural, optional bool) string {
+	switch {
+	case plural && optional:
+		return "0..*"
+	case plural:
+		return "1..*"
+	case optional:
+		return "0..1"
+	}
+	return "1"
+}
+
+// genMarkdownFacets returns the descriptions of the facets in the
+// restriction.
+func genMarkdownFacets(restriction Restriction) (facets []string) {
+	if len(restriction.Enum) > 0 {
+		var values []string
+		for _, value := range restriction.Enum {
+			values = append(values, "`"+value+"`")
+		}
+		facets = append(facets, "one of "+strings.Join(values, ", "))
+	}
+	if restriction.Pattern != nil {
+		facets = append(facets, "pattern `"+restriction.Pattern.String()+"`")
+	}
+	if restriction.MinLength > 0 {
+		facets = append(facets, fmt.Sprintf("min length %d", restriction.MinLength))
+	}
+	if restriction.MaxLength > 0 {
+		facets = append(facets, fmt.Sprintf("max length %d", restriction.MaxLength))
+	}
+	if restriction.HasMin {
+		operator := "≥"
+		if restriction.MinExclusive {
+			operator = ">"
+		}
+		facets = append(facets, operator+" "+strconv.FormatFloat(restriction.Min, 'g', -1, 64))
+	}
+	if restriction.HasMax {
+		operator := "≤"
+		if restriction.MaxExclusive {
+			operator = "<"
+		}
+		facets = append(facets, operator+" "+strconv.FormatFloat(restriction.Max, 'g', -1, 64))
+	}
+	if restriction.TotalDigits > 0 {
+		facets = append(facets, fmt.Sprintf("total digits %d", restriction.TotalDigits))
+	}
+	if restriction.Precision > 0 {
+		facets = append(facets, fmt.Sprintf("fraction digits %d", restriction.Precision))
+	}
+	return
+}
+
+// genMarkdownDescription returns the description of the field by given
+// documentation, facets, default value and deprecation message.
+func genMarkdownDescription(doc, defaultValue, deprecated string, restriction Restriction) string {
+	var description []string
+	if deprecated != "" {
+		description = append(description, "**Deprecated:** "+deprecated)
+	}
+	if doc = strings.TrimSpace(doc); doc != "" {
+		description = append(description, doc)
+	}
+	facets := genMarkdownFacets(restriction)
+	if defaultValue != "" {
+		facets = append(facets, "default `"+defaultValue+"`")
+	}
+	if len(facets) > 0 {
+		description = append(description, "("+strings.Join(facets, "; ")+")")
+	}
+	return markdownCellReplacer.Replace(strings.Join(description, " "))
+}
+
+// markdownTable holds the rows of the fields table in generating.
+type markdownTable struct {
+	rows      []string
+	unordered bool
+}
+
+// addRow provides a function to add the row of the field.
+func (t *markdownTable) addRow(name, kind, fieldType, cardinality, description string) {
+	t.rows = append(t.rows, fmt.Sprintf("| `%s` | %s | %s | %s | %s |\n", markdownCellReplacer.Replace(name), kind, fieldType, cardinality, description))
+}
+
+// addAttribute adds the row of the attribute.
+func (t *markdownTable) addAttribute(gen *CodeGenerator, attribute Attribute) {
+	t.addRow("@"+attribute.Name, "attribute", gen.genMarkdownType(attribute.Type), genMarkdownCardinality(attribute.Plural, attribute.Optional), genMarkdownDescription(attribute.Doc, attribute.Default, attribute.Deprecated, attribute.Restriction))
+}
+
+// addElement adds the row of the element.
+func (t *markdownTable) addElement(gen *CodeGenerator, element Element) {
+	t.addRow(element.Name, "element", gen.genMarkdownType(element.Type), genMarkdownCardinality(element.Plural, element.Optional), genMarkdownDescription(element.Doc, element.Default, element.Deprecated, element.Restriction))
+}
+
+// genMarkdownSection generates the section of the declaration by given
+// kind, summary and content.
+func (gen *CodeGenerator) genMarkdownSection(kind, name, doc, location, deprecated, summary, content string) {
+	gen.StructAST[name] = summary + content
+	section := fmt.Sprintf("\n<a name=\"%s\"></a>\n\n## %s\n\n%s%s\n", genMarkdownAnchor(kind, name), markdownCellReplacer.Replace(name), gen.genDeprecated(deprecated), summary)
+	if doc = strings.TrimSpace(doc); doc != "" {
+		section += "\n" + strings.Replace(doc, "\t", "", -1) + "\n"
+	}
+	if location != "" {
+		section += fmt.Sprintf("\nDefined in `%s`.\n", location)
+	}
+	if content != "" {
+		section += "\n" + content
+	}
+	gen.Field += section
+}
+
+// genMarkdownTable generates the section of the declaration with the table
+// of the fields.
+func (gen *CodeGenerator) genMarkdownTable(kind, name, doc, location, deprecated string, t *markdownTable) {
+	summary := strings.ToUpper(markdownKinds[kind][:1]) + markdownKinds[kind][1:] + "."
+	if t.unordered {
+		summary = strings.TrimSuffix(summary, ".") + ", the elements may occur in any order."
+	}
+	var content string
+	if len(t.rows) > 0 {
+		content = "| Name | Kind | Type | Cardinality | Description |\n| --- | --- | --- | --- | --- |\n" + strings.Join(t.rows, "")
+	}
+	gen.genMarkdownSection(kind, name, doc, location, deprecated, summary, content)
+}
+
+// MarkdownSimpleType generates the section for simple type XML schema in
+// Markdown, the values of the enumerations are listed in the table.
+func (gen *CodeGenerator) MarkdownSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var summary, content string
+	switch {
+	case v.List:
+		summary = fmt.Sprintf("Simple type, list of %s.", gen.genMarkdownType(v.Base))
+	case v.Union:
+		var members []string
+		for _, member := range v.MemberTypes {
+			if gen.getSimpleType(member.Name) != nil {
+				members = append(members, gen.genMarkdownType(member.Name))
+				continue
+			}
+			members = append(members, gen.genMarkdownType(member.Type))
+		}
+		summary = fmt.Sprintf("Simple type, union of %s.", strings.Join(members, ", "))
+	default:
+		summary = fmt.Sprintf("Simple type, restriction of %s.", gen.genMarkdownType(v.Base))
+	}
+	restriction := v.Restriction
+	if len(restriction.Enum) > 0 {
+		content = "| Value |\n| --- |\n"
+		for _, value := range restriction.Enum {
+			content += fmt.Sprintf("| `%s` |\n", markdownCellReplacer.Replace(value))
+		}
+		restriction.Enum = nil
+	}
+	if facets := genMarkdownFacets(restriction); len(facets) > 0 {
+		content = "- " + strings.Join(facets, "\n- ") + "\n" + strings.Replace(content, "| Value", "\n| Value", 1)
+	}
+	gen.genMarkdownSection("SimpleType", v.Name, v.Doc, v.Location, v.Deprecated, summary, content)
+	return
+}
+
+// MarkdownComplexType generates the section for complex type XML schema in
+// Markdown.
+func (gen *CodeGenerator) MarkdownComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	t := &markdownTable{unordered: v.Unordered}
+	for _, attrGroup := range v.AttributeGroup {
+		t.addRow(attrGroup.Name, "attribute group", genMarkdownRef("AttributeGroup", attrGroup.Ref), "1", "")
+	}
+	for _, attribute := range v.Attributes {
+		t.addAttribute(gen, attribute)
+	}
+	for _, group := range v.Groups {
+		t.addRow(group.Name, "group", genMarkdownRef("Group", group.Ref), genMarkdownCardinality(group.Plural, false), "")
+	}
+	for _, element := range v.Elements {
+		t.addElement(gen, element)
+	}
+	gen.genMarkdownTable("ComplexType", v.Name, v.Doc, v.Location, v.Deprecated, t)
+	return
+}
+
+// MarkdownGroup generates the section for group XML schema in Markdown.
+func (gen *CodeGenerator) MarkdownGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	t := &markdownTable{unordered: v.Unordered}
+	for _, element := range v.Elements {
+		t.addElement(gen, element)
+	}
+	for _, group := range v.Groups {
+		t.addRow(group.Name, "group", genMarkdownRef("Group", group.Ref), genMarkdownCardinality(group.Plural, false), "")
+	}
+	gen.genMarkdownTable("Group", v.Name, v.Doc, v.Location, v.Deprecated, t)
+	return
+}
+
+// MarkdownAttributeGroup generates the section for attribute group XML
+// schema in Markdown.
+func (gen *CodeGenerator) MarkdownAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	t := &markdownTable{}
+	for _, attribute := range v.Attributes {
+		t.addAttribute(gen, attribute)
+	}
+	gen.genMarkdownTable("AttributeGroup", v.Name, v.Doc, v.Location, v.Deprecated, t)
+	return
+}
+
+// MarkdownElement generates the section for element XML schema in Markdown.
+func (gen *CodeGenerator) MarkdownElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	summary := fmt.Sprintf("Element of type %s, cardinality %s.", gen.genMarkdownType(v.Type), genMarkdownCardinality(v.Plural, v.Optional))
+	var content string
+	if facets := genMarkdownFacets(v.Restriction); len(facets) > 0 {
+		content = "- " + strings.Join(facets, "\n- ") + "\n"
+	}
+	gen.genMarkdownSection("Element", v.Name, v.Doc, v.Location, v.Deprecated, summary, content)
+	return
+}
+
+// MarkdownAttribute generates the section for attribute XML schema in
+// Markdown.
+func (gen *CodeGenerator) MarkdownAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	summary := fmt.Sprintf("Attribute of type %s.", gen.genMarkdownType(v.Type))
+	var content string
+	if facets := genMarkdownFacets(v.Restriction); len(facets) > 0 {
+		content = "- " + strings.Join(facets, "\n- ") + "\n"
+	}
+	gen.genMarkdownSection("Attribute", v.Name, v.Doc, v.Location, v.Deprecated, summary, content)
+	return
+}
@@ -0,0 +1,371 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var nimBuildInType = map[string]bool{
+	"XmlNode":     true,
+	"bool":        true,
+	"float32":     true,
+	"float64":     true,
+	"int16":       true,
+	"int32":       true,
+	"int64":       true,
+	"int8":        true,
+	"seq[string]": true,
+	"string":      true,
+	"uint16":      true,
+	"uint32":      true,
+	"uint64":      true,
+	"uint8":       true,
+}
+
+// nimConversions defines the expressions convert the text to the built-in
+// types of Nim, the %s is replaced with the text.
+var nimConversions = map[string]string{
+	"bool":        "parseBool(%s)",
+	"float32":     "float32(parseFloat(%s))",
+	"float64":     "parseFloat(%s)",
+	"int16":       "int16(parseInt(%s))",
+	"int32":       "int32(parseInt(%s))",
+	"int64":       "int64(parseBiggestInt(%s))",
+	"int8":        "int8(parseInt(%s))",
+	"seq[string]": "splitWhitespace(%s)",
+	"string":      "%s",
+	"uint16":      "uint16(parseUInt(%s))",
+	"uint32":      "uint32(parseUInt(%s))",
+	"uint64":      "uint64(parseBiggestUInt(%s))",
+	"uint8":       "uint8(parseUInt(%s))",
+}
+
+// nimFromXmlSignature defines the signature of the fromXml proc, the %[1]s is
+// replaced with the type name.
+const nimFromXmlSignature = "proc fromXml*(T: typedesc[%[1]s], node: XmlNode): %[1]s"
+
+// nimHelpers defines the private helper procs of the generated module.
+const nimHelpers = `proc xgenChildren(node: XmlNode, name: string): seq[XmlNode] =
+  for child in node:
+    if child.kind == xnElement and child.tag.split(':')[^1] == name:
+      result.add child
+
+proc xgenHasAttribute(node: XmlNode, name: string): bool =
+  node.attrs != nil and node.attrs.hasKey(name)
+
+proc xgenAttribute(node: XmlNode, name: string): string =
+  if not xgenHasAttribute(node, name):
+    raise newException(ValueError, "missing attribute " & name & " in " & node.tag)
+  node.attrs[name]
+
+proc xgenHasElement(node: XmlNode, name: string): bool =
+  xgenChildren(node, name).len > 0
+
+proc xgenElement(node: XmlNode, name: string): XmlNode =
+  let children = xgenChildren(node, name)
+  if children.len == 0:
+    raise newException(ValueError, "missing element " & name & " in " & node.tag)
+  children[0]
+`
+
+// GenNim generate Nim programming language source code for XML schema
+// definition files. The types are declared in a single type section, so they
+// can reference each other regardless of the order. Each object type has a
+// fromXml proc, which creates the object from the node parsed by the
+// xmlparser module, the procs are declared before their definitions.
+func (gen *CodeGenerator) GenNim() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Nim%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "import std/[options, sequtils, strtabs, strutils, xmlparser, xmltree]\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import %s\n", mapping.Import)
+	}
+	var typeSection string
+	if gen.Field != "" {
+		typeSection = fmt.Sprintf("\ntype%s", gen.Field)
+	}
+	var forwards string
+	if gen.Forwards != "" {
+		forwards = "\n" + gen.Forwards
+	}
+	source := []byte(fmt.Sprintf("# %s\n\n%s%s\n%s%s", strings.TrimPrefix(copyright, "// "), importPackage, typeSection, nimHelpers, forwards+gen.Converters))
+	return gen.writeFile(gen.File+".nim", source)
+}
+
+func genNimFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genNimPropertyName generates the camel case field name for Nim code.
+func (gen *CodeGenerator) genNimPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genNimFieldName(name), CamelCase))
+}
+
+// genNimFieldType generates the type for Nim code. The list and union simple
+// types are referenced by the types generated for them.
+func (gen *CodeGenerator) genNimFieldType(name string) string {
+	if _, ok := nimBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genNimFieldName(name)
+	if fieldType == "" {
+		return "XmlNode"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genNimConversion generates the expression converts the text to the value of
+// the given simple type, it returns false if the given type isn't a simple
+// type.
+func (gen *CodeGenerator) genNimConversion(name, text string) (string, bool) {
+	if conversion, ok := nimConversions[name]; ok {
+		return fmt.Sprintf(conversion, text), true
+	}
+	if _, ok := gen.TypeMapping[name]; ok {
+		return "", false
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		itemType := gen.getBaseType(v.Base)
+		if conversion, _ := gen.genNimConversion(itemType, "it"); conversion != "it" {
+			return fmt.Sprintf("splitWhitespace(%s).mapIt(%s)", text, conversion), true
+		}
+		return fmt.Sprintf("splitWhitespace(%s)", text), true
+	} else if v != nil {
+		return text, true
+	}
+	return "", false
+}
+
+// genNimNodeValue generates the expression converts the node to the value of
+// the given type.
+func (gen *CodeGenerator) genNimNodeValue(name, node string) string {
+	if conversion, ok := gen.genNimConversion(name, node+".innerText"); ok {
+		return conversion
+	}
+	fieldType := gen.genNimFieldType(name)
+	if fieldType == "XmlNode" {
+		return node
+	}
+	return fmt.Sprintf("%s.fromXml(%s)", fieldType, node)
+}
+
+// genNimField generates the field declaration and the field of the object
+// constructor by given XML name of the element or attribute. The optional
+// field is an Option and the plural field is a sequence.
+func (gen *CodeGenerator) genNimField(name, typeName string, attribute, plural, optional bool) (field, value string) {
+	baseType := gen.getBaseType(typeName)
+	fieldType, fieldName, xmlName := gen.genNimFieldType(baseType), gen.genNimPropertyName(name), hexQuote(name)
+	var has, get string
+	if attribute {
+		has, get = fmt.Sprintf("xgenHasAttribute(node, %s)", xmlName), fmt.Sprintf("xgenAttribute(node, %s)", xmlName)
+		if conversion, ok := gen.genNimConversion(baseType, get); ok {
+			get = conversion
+		}
+	} else {
+		has, get = fmt.Sprintf("xgenHasElement(node, %s)", xmlName), gen.genNimNodeValue(baseType, fmt.Sprintf("xgenElement(node, %s)", xmlName))
+	}
+	switch {
+	case plural:
+		return fmt.Sprintf("    %s*: seq[%s]\n", fieldName, fieldType), fmt.Sprintf("%s: xgenChildren(node, %s).mapIt(%s)", fieldName, xmlName, gen.genNimNodeValue(baseType, "it"))
+	case optional:
+		return fmt.Sprintf("    %s*: Option[%s]\n", fieldName, fieldType), fmt.Sprintf("%s: (if %s: some(%s) else: none(%s))", fieldName, has, get, fieldType)
+	}
+	return fmt.Sprintf("    %s*: %s\n", fieldName, fieldType), fmt.Sprintf("%s: %s", fieldName, get)
+}
+
+// genNimInlineField generates the field declaration and the field of the
+// object constructor for the group or attribute group, which is created from
+// the enclosing node.
+func (gen *CodeGenerator) genNimInlineField(name, ref string, plural bool) (field, value string) {
+	fieldType, fieldName := gen.genNimFieldType(gen.getBaseType(ref)), gen.genNimPropertyName(name)
+	if plural {
+		return fmt.Sprintf("    %s*: seq[%s]\n", fieldName, fieldType), fmt.Sprintf("%s: @[%s.fromXml(node)]", fieldName, fieldType)
+	}
+	return fmt.Sprintf("    %s*: %s\n", fieldName, fieldType), fmt.Sprintf("%s: %s.fromXml(node)", fieldName, fieldType)
+}
+
+// genNimComment generates the comment of the declaration indented in the
+// type section.
+func (gen *CodeGenerator) genNimComment(name, doc, source, location, deprecated string) string {
+	return strings.Replace(genFieldComment(name, doc, source, location, "#")+gen.genDeprecated(deprecated), "\r\n#", "\r\n  #", -1)
+}
+
+// genNimObject generates the object type and the fromXml proc by given
+// declaration, fields and the fields of the object constructor.
+func (gen *CodeGenerator) genNimObject(name, doc, source, location, deprecated string, fields, values []string) {
+	fieldName := gen.typeName(genNimFieldName(name))
+	content := strings.Join(fields, "")
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%s  %s* = ref object\n%s", gen.genNimComment(fieldName, doc, source, location, deprecated), fieldName, content)
+	constructor := fieldName + "()"
+	if len(values) > 0 {
+		constructor = fmt.Sprintf("%s(\n    %s\n  )", fieldName, strings.Join(values, ",\n    "))
+	}
+	signature := fmt.Sprintf(nimFromXmlSignature, fieldName)
+	gen.Forwards += signature + "\n"
+	gen.Converters += fmt.Sprintf("\n%s =\n  %s\n", signature, constructor)
+}
+
+// genNimAlias generates the type alias by given declaration and type.
+func (gen *CodeGenerator) genNimAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genNimFieldName(name))
+	gen.Field += fmt.Sprintf("%s  %s* = %s\n", gen.genNimComment(fieldName, doc, source, location, deprecated), fieldName, fieldType)
+}
+
+// NimSimpleType generates code for simple type XML schema in Nim language
+// syntax. The enumerations are generated as the pure enums with the values in
+// the schema, the other simple types are generated as the type aliases.
+func (gen *CodeGenerator) NimSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genNimAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("seq[%s]", gen.genNimFieldType(gen.getBaseType(v.Base))))
+		return
+	}
+	if v.Union {
+		gen.genNimAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "string")
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.genNimAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genNimFieldType(gen.getBaseType(v.Base)))
+		return
+	}
+	var members []string
+	declared := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, PascalCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "V" + member
+		}
+		member = gen.constantName(member)
+		if declared[strings.ToLower(member)] {
+			continue
+		}
+		declared[strings.ToLower(member)] = true
+		members = append(members, fmt.Sprintf("    %s = %s", member, hexQuote(enum)))
+	}
+	content := strings.Join(members, ",\n") + "\n"
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genNimFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%s  %s* {.pure.} = enum\n%s", gen.genNimComment(fieldName, v.Doc, v.Source, v.Location, v.Deprecated), fieldName, content)
+	return
+}
+
+// NimComplexType generates code for complex type XML schema in Nim language
+// syntax.
+func (gen *CodeGenerator) NimComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, values []string
+	for _, attrGroup := range v.AttributeGroup {
+		field, value := gen.genNimInlineField(attrGroup.Name, attrGroup.Ref, false)
+		fields, values = append(fields, field), append(values, value)
+	}
+	for _, attribute := range v.Attributes {
+		field, value := gen.genNimField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		fields, values = append(fields, field), append(values, value)
+	}
+	for _, group := range v.Groups {
+		field, value := gen.genNimInlineField(group.Name, group.Ref, group.Plural)
+		fields, values = append(fields, field), append(values, value)
+	}
+	for _, element := range v.Elements {
+		field, value := gen.genNimField(element.Name, element.Type, false, element.Plural, element.Optional)
+		fields, values = append(fields, field), append(values, value)
+	}
+	gen.genNimObject(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, values)
+	return
+}
+
+// NimGroup generates code for group XML schema in Nim language syntax.
+func (gen *CodeGenerator) NimGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, values []string
+	for _, element := range v.Elements {
+		field, value := gen.genNimField(element.Name, element.Type, false, element.Plural, element.Optional)
+		fields, values = append(fields, field), append(values, value)
+	}
+	for _, group := range v.Groups {
+		field, value := gen.genNimInlineField(group.Name, group.Ref, group.Plural)
+		fields, values = append(fields, field), append(values, value)
+	}
+	gen.genNimObject(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, values)
+	return
+}
+
+// NimAttributeGroup generates code for attribute group XML schema in Nim
+// language syntax.
+func (gen *CodeGenerator) NimAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields, values []string
+	for _, attribute := range v.Attributes {
+		field, value := gen.genNimField(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		fields, values = append(fields, field), append(values, value)
+	}
+	gen.genNimObject(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields, values)
+	return
+}
+
+// NimElement generates code for element XML schema in Nim language syntax.
+// The element is generated as the parse proc, which parses the XML document
+// with the element as the root.
+func (gen *CodeGenerator) NimElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genNimFieldName(v.Name))
+	baseType := gen.getBaseType(v.Type)
+	fieldType := gen.genNimFieldType(baseType)
+	if fieldType == fieldName {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	content := fmt.Sprintf("\n# parse%[1]s parses the XML document with the %[2]s root element.\nproc parse%[1]s*(xml: string): %[3]s =\n  %[4]s\n", fieldName, v.Name, fieldType, gen.genNimNodeValue(baseType, "parseXml(xml)"))
+	gen.StructAST[v.Name] = content
+	gen.Converters += content
+	return
+}
+
+// NimAttribute generates code for attribute XML schema in Nim language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) NimAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
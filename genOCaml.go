@@ -0,0 +1,465 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+var ocamlBuildInType = map[string]bool{
+	"bool":        true,
+	"float":       true,
+	"int":         true,
+	"int64":       true,
+	"string":      true,
+	"string list": true,
+}
+
+// ocamlBuildInConverters defines the functions converting the built-in types
+// of OCaml from and to the text.
+var ocamlBuildInConverters = map[string][2]string{
+	"bool":        {"Xgen.bool_of_string", "string_of_bool"},
+	"float":       {"float_of_string", "Xgen.string_of_float"},
+	"int":         {"int_of_string", "string_of_int"},
+	"int64":       {"Int64.of_string", "Int64.to_string"},
+	"string":      {"Fun.id", "Fun.id"},
+	"string list": {"Xgen.words", "String.concat \" \""},
+}
+
+// ocamlHelpers defines the functions used by the generated code to convert
+// the values from and to the xml-light nodes.
+const ocamlHelpers = `module Xgen = struct
+  let bool_of_string = function
+    | "true" | "1" -> true
+    | "false" | "0" -> false
+    | value -> invalid_arg ("invalid boolean: " ^ value)
+
+  let string_of_float value = Printf.sprintf "%.17g" value
+
+  let words value =
+    String.map (function '\t' | '\n' | '\r' -> ' ' | c -> c) value
+    |> String.split_on_char ' '
+    |> List.filter (fun word -> word <> "")
+
+  let list of_string value = List.map of_string (words value)
+
+  let string_of_list to_string values = String.concat " " (List.map to_string values)
+
+  let text node =
+    Xml.children node
+    |> List.filter_map (function Xml.PCData text -> Some text | Xml.Element _ -> None)
+    |> String.concat ""
+
+  let text_of of_string node = of_string (text node)
+
+  let text_element to_string name value = Xml.Element (name, [], [ Xml.PCData (to_string value) ])
+
+  let attribute_opt name of_string node = Option.map of_string (List.assoc_opt name (Xml.attribs node))
+
+  let attribute name of_string node =
+    match attribute_opt name of_string node with
+    | Some value -> value
+    | None -> failwith ("missing attribute " ^ name)
+
+  let attribute_pair name to_string value = (name, to_string value)
+
+  let elements name of_xml node =
+    Xml.children node
+    |> List.filter_map (function
+         | Xml.Element (tag, _, _) as child when tag = name -> Some (of_xml child)
+         | _ -> None)
+
+  let element_opt name of_xml node =
+    match elements name Fun.id node with child :: _ -> Some (of_xml child) | [] -> None
+
+  let element name of_xml node =
+    match element_opt name of_xml node with
+    | Some value -> value
+    | None -> failwith ("missing element " ^ name)
+end
+`
+
+// ocamlPlaceholderConverters defines the conversion functions of the
+// placeholder, which keeps the node as is.
+const ocamlPlaceholderConverters = "\nand %[1]s_of_xml (node : Xml.xml) : %[1]s = node\n\nand %[1]s_to_xml (_ : string) (node : %[1]s) : Xml.xml = node\n"
+
+// ocamlCommentReplacer escapes the delimiters of the comments and the string
+// literals, which are lexed in the comments of OCaml.
+var ocamlCommentReplacer = strings.NewReplacer("(*", "( *", "*)", "* )", `"`, "'", "{|", "{ |")
+
+// ocamlValue holds the OCaml type of the value and the functions converting
+// it. The functions of the element type convert the value from the node and
+// to the node by given name, and the others convert the text.
+type ocamlValue struct {
+	fieldType, of, to string
+	element           bool
+}
+
+// ocamlField holds the field of the generated record and the expressions
+// converting the field from the node and to the attributes or nodes.
+type ocamlField struct {
+	name, fieldType, of, to string
+}
+
+// GenOCaml generate OCaml programming language source code for XML schema
+// definition files. The types are generated as records with the functions
+// converting them from and to the nodes of xml-light.
+func (gen *CodeGenerator) GenOCaml() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("OCaml%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var importPackage string
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("open %s\n", mapping.Import)
+	}
+	if importPackage != "" {
+		importPackage += "\n"
+	}
+	types := strings.Replace(gen.Field, "\nand ", "\ntype ", 1)
+	converters := strings.Replace(gen.Converters, "\nand ", "\nlet rec ", 1)
+	source := []byte(fmt.Sprintf("(* %s *)\n\n%s%s%s%s", strings.TrimPrefix(copyright, "// "), importPackage, ocamlHelpers, types, converters))
+	return gen.writeFile(gen.File+".ml", source)
+}
+
+func genOCamlFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return ToSnakeCase(fieldName)
+}
+
+// ocamlComment converts the line comments generated with the " *" prefix to
+// the OCaml comment.
+func ocamlComment(comment string) string {
+	comment = strings.Replace(ocamlCommentReplacer.Replace(comment), "\r\n *", "\r\n(*", 1)
+	comment = strings.TrimSuffix(comment, "\r\n")
+	if strings.Count(comment, "\r\n") == 1 {
+		return comment + " *)\r\n"
+	}
+	return comment + "\r\n *)\r\n"
+}
+
+// ocamlParen wraps the expression in parentheses if it's not atomic.
+func ocamlParen(expr string) string {
+	if strings.ContainsAny(expr, " ") {
+		return "(" + expr + ")"
+	}
+	return expr
+}
+
+// ocamlList generates the expression concatenating the lists by given
+// expressions at the indentation.
+func ocamlList(exprs []string, indent string) string {
+	if len(exprs) == 0 {
+		return "[]"
+	}
+	return fmt.Sprintf("List.concat\n%[1]s  [\n%[1]s    %[2]s;\n%[1]s  ]", indent, strings.Join(exprs, fmt.Sprintf(";\n%s    ", indent)))
+}
+
+// genOCamlValue generates the value for OCaml code by given type. The list
+// and union simple types are converted by the functions generated for them.
+func (gen *CodeGenerator) genOCamlValue(name string) ocamlValue {
+	if _, ok := ocamlBuildInType[name]; ok {
+		converters := ocamlBuildInConverters[name]
+		return ocamlValue{fieldType: name, of: converters[0], to: converters[1]}
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return ocamlValue{fieldType: mappedType, of: mappedType + "_of_xml", to: mappedType + "_to_xml", element: true}
+	}
+	fieldType := genOCamlFieldName(name)
+	if fieldType == "" {
+		return ocamlValue{fieldType: "string", of: "Fun.id", to: "Fun.id"}
+	}
+	fieldType = gen.typeName(fieldType)
+	if gen.getSimpleType(name) != nil {
+		return ocamlValue{fieldType: fieldType, of: fieldType + "_of_string", to: fieldType + "_to_string"}
+	}
+	return ocamlValue{fieldType: fieldType, of: fieldType + "_of_xml", to: fieldType + "_to_xml", element: true}
+}
+
+// genOCamlNodeValue returns the functions converting the value from the node
+// and to the node by given name.
+func genOCamlNodeValue(value ocamlValue) (of, to string) {
+	if value.element {
+		return value.of, value.to
+	}
+	return "Xgen.text_of " + ocamlParen(value.of), "Xgen.text_element " + ocamlParen(value.to)
+}
+
+// genOCamlElement generates the field for the element.
+func (gen *CodeGenerator) genOCamlElement(element Element) ocamlField {
+	value := gen.genOCamlValue(gen.getBaseType(element.Type))
+	field := ocamlField{name: gen.fieldName(genOCamlFieldName(element.Name)), fieldType: value.fieldType}
+	of, to := genOCamlNodeValue(value)
+	name := hexQuote(element.Name)
+	switch {
+	case element.Plural:
+		field.fieldType += " list"
+		field.of = fmt.Sprintf("Xgen.elements %s %s node", name, ocamlParen(of))
+		field.to = fmt.Sprintf("List.map (%s %s) v.%s", to, name, field.name)
+	case element.Optional:
+		field.fieldType += " option"
+		field.of = fmt.Sprintf("Xgen.element_opt %s %s node", name, ocamlParen(of))
+		field.to = fmt.Sprintf("Option.to_list (Option.map (%s %s) v.%s)", to, name, field.name)
+	default:
+		field.of = fmt.Sprintf("Xgen.element %s %s node", name, ocamlParen(of))
+		field.to = fmt.Sprintf("[ %s %s v.%s ]", to, name, field.name)
+	}
+	return field
+}
+
+// genOCamlAttribute generates the field for the attribute.
+func (gen *CodeGenerator) genOCamlAttribute(attribute Attribute) ocamlField {
+	value := gen.genOCamlValue(gen.getBaseType(attribute.Type))
+	field := ocamlField{name: gen.fieldName(genOCamlFieldName(attribute.Name)), fieldType: value.fieldType}
+	of, to := ocamlParen(value.of), ocamlParen(value.to)
+	if attribute.Plural {
+		field.fieldType += " list"
+		of, to = fmt.Sprintf("(Xgen.list %s)", of), fmt.Sprintf("(Xgen.string_of_list %s)", to)
+	}
+	name := hexQuote(attribute.Name)
+	if attribute.Optional {
+		field.fieldType += " option"
+		field.of = fmt.Sprintf("Xgen.attribute_opt %s %s node", name, of)
+		field.to = fmt.Sprintf("Option.to_list (Option.map (Xgen.attribute_pair %s %s) v.%s)", name, to, field.name)
+		return field
+	}
+	field.of = fmt.Sprintf("Xgen.attribute %s %s node", name, of)
+	field.to = fmt.Sprintf("[ Xgen.attribute_pair %s %s v.%s ]", name, to, field.name)
+	return field
+}
+
+// genOCamlInline generates the field for the group or attribute group, which
+// is converted with the enclosing element.
+func (gen *CodeGenerator) genOCamlInline(name, ref string) ocamlField {
+	value := gen.genOCamlValue(gen.getBaseType(ref))
+	fieldName := gen.fieldName(genOCamlFieldName(name))
+	return ocamlField{name: fieldName, fieldType: value.fieldType, of: fmt.Sprintf("%s node", value.of), to: fmt.Sprintf("%s v.%s", value.to, fieldName)}
+}
+
+// genOCamlDeclaration appends the type declaration and the conversion
+// functions of it to the recursive definitions.
+func (gen *CodeGenerator) genOCamlDeclaration(comment, declaration, converters string) {
+	gen.Field += fmt.Sprintf("%sand %s\n", comment, declaration)
+	gen.Converters += converters
+}
+
+// genOCamlRecord generates the record by given declaration and fields, and
+// the conversion functions of it. The attributes fields are converted to the
+// attributes, the nodes fields are converted to the child nodes. The complex
+// type is converted to the element by given name, and the group and
+// attribute group are converted to the nodes and attributes.
+func (gen *CodeGenerator) genOCamlRecord(name, doc, source, location, deprecated, kind string, attributes, nodes []ocamlField) {
+	fieldName := gen.typeName(genOCamlFieldName(name))
+	fields := append(append([]ocamlField{}, attributes...), nodes...)
+	declaration, of, value := fmt.Sprintf("%s = unit", fieldName), "()", "_"
+	if len(fields) > 0 {
+		var lines, values []string
+		for _, field := range fields {
+			lines = append(lines, fmt.Sprintf("  %s : %s;", field.name, field.fieldType))
+			values = append(values, fmt.Sprintf("    %s = %s;", field.name, field.of))
+		}
+		declaration = fmt.Sprintf("%s = {\n%s\n}", fieldName, strings.Join(lines, "\n"))
+		of, value = fmt.Sprintf("{\n%s\n  }", strings.Join(values, "\n")), "v"
+	}
+	node := "node"
+	if len(fields) == 0 {
+		node = "_"
+	}
+	var attributesTo, nodesTo []string
+	for _, field := range attributes {
+		attributesTo = append(attributesTo, field.to)
+	}
+	for _, field := range nodes {
+		nodesTo = append(nodesTo, field.to)
+	}
+	converters := fmt.Sprintf("\nand %[1]s_of_xml (%[2]s : Xml.xml) : %[1]s =\n  %[3]s\n", fieldName, node, of)
+	switch kind {
+	case "Element":
+		converters += fmt.Sprintf("\nand %[1]s_to_xml (name : string) (%[2]s : %[1]s) : Xml.xml =\n  Xml.Element\n    ( name,\n      %[3]s,\n      %[4]s )\n", fieldName, value, ocamlList(attributesTo, "      "), ocamlList(nodesTo, "      "))
+	case "Attribute":
+		converters += fmt.Sprintf("\nand %[1]s_to_xml (%[2]s : %[1]s) : (string * string) list =\n  %[3]s\n", fieldName, value, ocamlList(attributesTo, "  "))
+	default:
+		converters += fmt.Sprintf("\nand %[1]s_to_xml (%[2]s : %[1]s) : Xml.xml list =\n  %[3]s\n", fieldName, value, ocamlList(nodesTo, "  "))
+	}
+	gen.StructAST[name] = declaration
+	gen.genOCamlDeclaration(ocamlComment(genFieldComment(fieldName, doc, source, location, " *"))+gen.genDeprecated(deprecated), declaration, converters)
+}
+
+// genOCamlAlias generates the type abbreviation by given declaration and
+// type, and the conversion functions of it.
+func (gen *CodeGenerator) genOCamlAlias(name, doc, source, location, deprecated, fieldName, fieldType, converters string) {
+	gen.StructAST[name] = fieldType
+	gen.genOCamlDeclaration(ocamlComment(genFieldComment(fieldName, doc, source, location, " *"))+gen.genDeprecated(deprecated), fmt.Sprintf("%s = %s", fieldName, fieldType), converters)
+}
+
+// genOCamlTextAlias generates the type abbreviation of the simple type by
+// given value, which is converted from and to the text.
+func (gen *CodeGenerator) genOCamlTextAlias(v *SimpleType, fieldType, of, to string) {
+	fieldName := gen.typeName(genOCamlFieldName(v.Name))
+	gen.genOCamlAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldName, fieldType,
+		fmt.Sprintf("\nand %[1]s_of_string (value : string) : %[1]s = %[2]s value\n\nand %[1]s_to_string (value : %[1]s) : string = %[3]s value\n", fieldName, of, to))
+}
+
+// genOCamlVariant returns the tag of the polymorphic variant by given
+// enumeration value.
+func genOCamlVariant(enum string) string {
+	variant := ConvertCase(enum, PascalCase)
+	if variant == "" || !unicode.IsLetter(rune(variant[0])) {
+		variant = "V" + variant
+	}
+	return "`" + variant
+}
+
+// OCamlSimpleType generates code for simple type XML schema in OCaml language
+// syntax.
+func (gen *CodeGenerator) OCamlSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		item := gen.genOCamlValue(gen.getBaseType(v.Base))
+		gen.genOCamlTextAlias(v, item.fieldType+" list", "Xgen.list "+ocamlParen(item.of), "Xgen.string_of_list "+ocamlParen(item.to))
+		return
+	}
+	if v.Union {
+		gen.genOCamlTextAlias(v, "string", "Fun.id", "Fun.id")
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		fieldName := gen.typeName(genOCamlFieldName(v.Name))
+		var variants, of, to []string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			variant := genOCamlVariant(enum)
+			if members[variant] {
+				continue
+			}
+			members[variant] = true
+			variants = append(variants, variant)
+			of = append(of, fmt.Sprintf("  | %s -> %s", hexQuote(enum), variant))
+			to = append(to, fmt.Sprintf("  | %s -> %s", variant, hexQuote(enum)))
+		}
+		declaration := fmt.Sprintf("%s = [ %s ]", fieldName, strings.Join(variants, " | "))
+		gen.StructAST[v.Name] = declaration
+		gen.genOCamlDeclaration(ocamlComment(genFieldComment(fieldName, v.Doc, v.Source, v.Location, " *"))+gen.genDeprecated(v.Deprecated), declaration,
+			fmt.Sprintf("\nand %[1]s_of_string (value : string) : %[1]s =\n  match value with\n%[2]s\n  | _ -> invalid_arg (%[3]s ^ value)\n\nand %[1]s_to_string (value : %[1]s) : string =\n  match value with\n%[4]s\n", fieldName, strings.Join(of, "\n"), hexQuote("invalid "+fieldName+": "), strings.Join(to, "\n")))
+		return
+	}
+	value := gen.genOCamlValue(gen.getBaseType(v.Base))
+	gen.genOCamlTextAlias(v, value.fieldType, value.of, value.to)
+	return
+}
+
+// OCamlComplexType generates code for complex type XML schema in OCaml
+// language syntax.
+func (gen *CodeGenerator) OCamlComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var attributes, nodes []ocamlField
+	for _, attrGroup := range v.AttributeGroup {
+		attributes = append(attributes, gen.genOCamlInline(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		attributes = append(attributes, gen.genOCamlAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		nodes = append(nodes, gen.genOCamlInline(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		nodes = append(nodes, gen.genOCamlElement(element))
+	}
+	gen.genOCamlRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Element", attributes, nodes)
+	return
+}
+
+// OCamlGroup generates code for group XML schema in OCaml language syntax.
+func (gen *CodeGenerator) OCamlGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var nodes []ocamlField
+	for _, element := range v.Elements {
+		nodes = append(nodes, gen.genOCamlElement(element))
+	}
+	for _, group := range v.Groups {
+		nodes = append(nodes, gen.genOCamlInline(group.Name, group.Ref))
+	}
+	gen.genOCamlRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Group", nil, nodes)
+	return
+}
+
+// OCamlAttributeGroup generates code for attribute group XML schema in OCaml
+// language syntax.
+func (gen *CodeGenerator) OCamlAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var attributes []ocamlField
+	for _, attribute := range v.Attributes {
+		attributes = append(attributes, gen.genOCamlAttribute(attribute))
+	}
+	gen.genOCamlRecord(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "Attribute", attributes, nil)
+	return
+}
+
+// OCamlElement generates code for element XML schema in OCaml language
+// syntax. The element is converted from the node and to the node by the name
+// of the element, the plural element is converted from the child nodes of the
+// parent node.
+func (gen *CodeGenerator) OCamlElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genOCamlFieldName(v.Name))
+	value := gen.genOCamlValue(gen.getBaseType(v.Type))
+	of, to := genOCamlNodeValue(value)
+	name := hexQuote(v.Name)
+	if v.Plural {
+		gen.genOCamlAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldName, value.fieldType+" list",
+			fmt.Sprintf("\nand %[1]s_of_xml (node : Xml.xml) : %[1]s = Xgen.elements %[2]s %[3]s node\n\nand %[1]s_to_xml (v : %[1]s) : Xml.xml list = List.map (%[4]s %[2]s) v\n", fieldName, name, ocamlParen(of), to))
+		return
+	}
+	gen.genOCamlAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldName, value.fieldType,
+		fmt.Sprintf("\nand %[1]s_of_xml (node : Xml.xml) : %[1]s = %[3]s node\n\nand %[1]s_to_xml (v : %[1]s) : Xml.xml = %[4]s %[2]s v\n", fieldName, name, of, to))
+	return
+}
+
+// OCamlAttribute generates code for attribute XML schema in OCaml language
+// syntax. The attribute is converted from the node and to the pair of the
+// name and the value.
+func (gen *CodeGenerator) OCamlAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genOCamlFieldName(v.Name))
+	value := gen.genOCamlValue(gen.getBaseType(v.Type))
+	fieldType, of, to := value.fieldType, ocamlParen(value.of), ocamlParen(value.to)
+	if v.Plural {
+		fieldType, of, to = fieldType+" list", fmt.Sprintf("(Xgen.list %s)", of), fmt.Sprintf("(Xgen.string_of_list %s)", to)
+	}
+	gen.genOCamlAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldName, fieldType,
+		fmt.Sprintf("\nand %[1]s_of_xml (node : Xml.xml) : %[1]s = Xgen.attribute %[2]s %[3]s node\n\nand %[1]s_to_xml (v : %[1]s) : string * string = Xgen.attribute_pair %[2]s %[4]s v\n", fieldName, hexQuote(v.Name), of, to))
+	return
+}
@@ -0,0 +1,633 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var objcBuildInType = map[string]bool{
+	"BOOL":                  true,
+	"NSArray<NSString *> *": true,
+	"NSData *":              true,
+	"NSDate *":              true,
+	"NSDecimalNumber *":     true,
+	"NSInteger":             true,
+	"NSString *":            true,
+	"NSUInteger":            true,
+	"double":                true,
+	"float":                 true,
+}
+
+// objcBuildInParse defines the expressions parsing the built-in types of
+// Objective-C from the text, the %s is replaced with the text expression.
+var objcBuildInParse = map[string]string{
+	"BOOL":                  "XGParseBool(%s)",
+	"NSArray<NSString *> *": "XGWords(%s)",
+	"NSData *":              "XGParseData(%s)",
+	"NSDate *":              "XGParseDate(%s)",
+	"NSDecimalNumber *":     "[NSDecimalNumber decimalNumberWithString:%s]",
+	"NSInteger":             "[%s integerValue]",
+	"NSString *":            "%s",
+	"NSUInteger":            "(NSUInteger)[%s longLongValue]",
+	"double":                "[%s doubleValue]",
+	"float":                 "[%s floatValue]",
+}
+
+// objcNodeHeader and objcNodeImplementation define the element parsed by
+// NSXMLParser, which the generated classes are initialized from, and the
+// functions used by the generated code to parse the values.
+const (
+	objcNodeHeader = `#import <Foundation/Foundation.h>
+
+NS_ASSUME_NONNULL_BEGIN
+
+/// XGXMLNode is the element parsed by NSXMLParser, the classes generated by
+/// xgen are initialized from it.
+@interface XGXMLNode : NSObject
+
+@property (nonatomic, copy, readonly) NSString *name;
+@property (nonatomic, copy, readonly) NSDictionary<NSString *, NSString *> *attributes;
+@property (nonatomic, copy, readonly) NSArray<XGXMLNode *> *children;
+@property (nonatomic, copy, readonly) NSString *text;
+
+/// nodeWithData:error: parses the XML document and returns the root element.
++ (nullable XGXMLNode *)nodeWithData:(NSData *)data error:(NSError **)error;
+
+/// childNamed: returns the first child element by given local name.
+- (nullable XGXMLNode *)childNamed:(NSString *)name;
+
+/// childrenNamed: returns the child elements by given local name.
+- (NSArray<XGXMLNode *> *)childrenNamed:(NSString *)name;
+
+@end
+
+FOUNDATION_EXPORT BOOL XGParseBool(NSString *text);
+FOUNDATION_EXPORT NSDate *_Nullable XGParseDate(NSString *text);
+FOUNDATION_EXPORT NSData *_Nullable XGParseData(NSString *text);
+FOUNDATION_EXPORT NSArray<NSString *> *XGWords(NSString *text);
+FOUNDATION_EXPORT NSArray *XGMap(NSArray *items, id _Nullable (^transform)(id item));
+
+NS_ASSUME_NONNULL_END
+`
+	objcNodeImplementation = `#import "XGXMLNode.h"
+
+@interface XGXMLNode ()
+
+@property (nonatomic, copy, readwrite) NSString *name;
+@property (nonatomic, copy, readwrite) NSDictionary<NSString *, NSString *> *attributes;
+@property (nonatomic, strong) NSMutableArray<XGXMLNode *> *mutableChildren;
+@property (nonatomic, strong) NSMutableString *mutableText;
+
+@end
+
+@interface XGXMLNodeBuilder : NSObject <NSXMLParserDelegate>
+
+@property (nonatomic, strong) NSMutableArray<XGXMLNode *> *stack;
+@property (nonatomic, strong, nullable) XGXMLNode *root;
+
+@end
+
+@implementation XGXMLNode
+
+- (instancetype)init {
+    if ((self = [super init])) {
+        _name = @"";
+        _attributes = @{};
+        _mutableChildren = [NSMutableArray array];
+        _mutableText = [NSMutableString string];
+    }
+    return self;
+}
+
+- (NSArray<XGXMLNode *> *)children {
+    return [self.mutableChildren copy];
+}
+
+- (NSString *)text {
+    return [self.mutableText copy];
+}
+
++ (nullable XGXMLNode *)nodeWithData:(NSData *)data error:(NSError **)error {
+    NSXMLParser *parser = [[NSXMLParser alloc] initWithData:data];
+    XGXMLNodeBuilder *builder = [[XGXMLNodeBuilder alloc] init];
+    parser.delegate = builder;
+    parser.shouldProcessNamespaces = YES;
+    if (![parser parse]) {
+        if (error) {
+            *error = parser.parserError;
+        }
+        return nil;
+    }
+    return builder.root;
+}
+
+- (nullable XGXMLNode *)childNamed:(NSString *)name {
+    for (XGXMLNode *child in self.mutableChildren) {
+        if ([child.name isEqualToString:name]) {
+            return child;
+        }
+    }
+    return nil;
+}
+
+- (NSArray<XGXMLNode *> *)childrenNamed:(NSString *)name {
+    NSMutableArray<XGXMLNode *> *children = [NSMutableArray array];
+    for (XGXMLNode *child in self.mutableChildren) {
+        if ([child.name isEqualToString:name]) {
+            [children addObject:child];
+        }
+    }
+    return children;
+}
+
+@end
+
+@implementation XGXMLNodeBuilder
+
+- (instancetype)init {
+    if ((self = [super init])) {
+        _stack = [NSMutableArray array];
+    }
+    return self;
+}
+
+- (void)parser:(NSXMLParser *)parser didStartElement:(NSString *)elementName namespaceURI:(nullable NSString *)namespaceURI qualifiedName:(nullable NSString *)qName attributes:(NSDictionary<NSString *, NSString *> *)attributeDict {
+    XGXMLNode *node = [[XGXMLNode alloc] init];
+    node.name = elementName;
+    node.attributes = attributeDict;
+    [self.stack.lastObject.mutableChildren addObject:node];
+    if (self.root == nil) {
+        self.root = node;
+    }
+    [self.stack addObject:node];
+}
+
+- (void)parser:(NSXMLParser *)parser didEndElement:(NSString *)elementName namespaceURI:(nullable NSString *)namespaceURI qualifiedName:(nullable NSString *)qName {
+    [self.stack removeLastObject];
+}
+
+- (void)parser:(NSXMLParser *)parser foundCharacters:(NSString *)string {
+    [self.stack.lastObject.mutableText appendString:string];
+}
+
+- (void)parser:(NSXMLParser *)parser foundCDATA:(NSData *)CDATABlock {
+    NSString *string = [[NSString alloc] initWithData:CDATABlock encoding:NSUTF8StringEncoding];
+    if (string != nil) {
+        [self.stack.lastObject.mutableText appendString:string];
+    }
+}
+
+@end
+
+BOOL XGParseBool(NSString *text) {
+    NSString *value = [text stringByTrimmingCharactersInSet:[NSCharacterSet whitespaceAndNewlineCharacterSet]];
+    return [value isEqualToString:@"true"] || [value isEqualToString:@"1"];
+}
+
+NSDate *_Nullable XGParseDate(NSString *text) {
+    NSString *value = [text stringByTrimmingCharactersInSet:[NSCharacterSet whitespaceAndNewlineCharacterSet]];
+    NSISO8601DateFormatter *formatter = [[NSISO8601DateFormatter alloc] init];
+    NSArray<NSNumber *> *options = @[
+        @(NSISO8601DateFormatWithInternetDateTime),
+        @(NSISO8601DateFormatWithInternetDateTime | NSISO8601DateFormatWithFractionalSeconds),
+        @(NSISO8601DateFormatWithFullDate),
+    ];
+    for (NSNumber *option in options) {
+        formatter.formatOptions = option.unsignedIntegerValue;
+        NSDate *date = [formatter dateFromString:value];
+        if (date != nil) {
+            return date;
+        }
+    }
+    return nil;
+}
+
+NSData *_Nullable XGParseData(NSString *text) {
+    return [[NSData alloc] initWithBase64EncodedString:text options:NSDataBase64DecodingIgnoreUnknownCharacters];
+}
+
+NSArray<NSString *> *XGWords(NSString *text) {
+    NSMutableArray<NSString *> *words = [NSMutableArray array];
+    for (NSString *word in [text componentsSeparatedByCharactersInSet:[NSCharacterSet whitespaceAndNewlineCharacterSet]]) {
+        if (word.length > 0) {
+            [words addObject:word];
+        }
+    }
+    return words;
+}
+
+NSArray *XGMap(NSArray *items, id _Nullable (^transform)(id item)) {
+    NSMutableArray *result = [NSMutableArray arrayWithCapacity:items.count];
+    for (id item in items) {
+        id value = transform(item);
+        if (value != nil) {
+            [result addObject:value];
+        }
+    }
+    return result;
+}
+`
+)
+
+// objcPlaceholderImplementation defines the implementation of the
+// placeholder, which is initialized without any property.
+const objcPlaceholderImplementation = "\n@implementation %[1]s\n\n- (nullable instancetype)initWithXMLNode:(XGXMLNode *)node {\n    return [super init];\n}\n\n@end\n"
+
+// objcValue holds the Objective-C type of the value and the expression
+// parsing it from the text. The value of the class type is initialized from
+// the element, and the scalar value is boxed in NSNumber in the collections
+// and the optional properties.
+type objcValue struct {
+	fieldType, parse string
+	class, scalar    bool
+}
+
+// objcProperty holds the property of the generated class, and the statements
+// parsing the property from the element in the initializer.
+type objcProperty struct {
+	declaration, parse string
+	class              string
+	text, child        bool
+}
+
+// GenObjectiveC generate Objective-C programming language source code for XML
+// schema definition files. The complex types, groups and attribute groups are
+// generated as the interface and implementation pairs with the initializers
+// from the elements parsed by NSXMLParser, the element and the functions used
+// by the generated code are placed in the XGXMLNode files beside the
+// generated code.
+func (gen *CodeGenerator) GenObjectiveC() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("ObjectiveC%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	dir := filepath.Dir(gen.File)
+	if err := gen.writeFile(filepath.Join(dir, "XGXMLNode.h"), []byte(fmt.Sprintf("%s\n\n%s", copyright, objcNodeHeader))); err != nil {
+		return err
+	}
+	if err := gen.writeFile(filepath.Join(dir, "XGXMLNode.m"), []byte(fmt.Sprintf("%s\n\n%s", copyright, objcNodeImplementation))); err != nil {
+		return err
+	}
+	importPackage := "#import <Foundation/Foundation.h>\n#import \"XGXMLNode.h\"\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("#import %s\n", strconv.Quote(mapping.Import))
+	}
+	header := []byte(fmt.Sprintf("%s\n\n%s\nNS_ASSUME_NONNULL_BEGIN\n%s\nNS_ASSUME_NONNULL_END\n", copyright, importPackage, gen.Field))
+	if err := gen.writeFile(gen.File+".h", header); err != nil {
+		return err
+	}
+	implementation := []byte(fmt.Sprintf("%s\n\n#import %s\n%s", copyright, strconv.Quote(filepath.Base(gen.File)+".h"), gen.Implementation))
+	return gen.writeFile(gen.File+".m", implementation)
+}
+
+func genObjectiveCFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genObjCPropertyName generates the name of the property in lower camel case.
+func (gen *CodeGenerator) genObjCPropertyName(name string) string {
+	propertyName := genObjectiveCFieldName(name)
+	if propertyName == "" {
+		return propertyName
+	}
+	return gen.fieldName(strings.ToLower(propertyName[:1]) + propertyName[1:])
+}
+
+// objcDeclare returns the declaration of the variable or property by given
+// type and name.
+func objcDeclare(fieldType, name string) string {
+	if strings.HasSuffix(fieldType, "*") {
+		return fieldType + name
+	}
+	return fieldType + " " + name
+}
+
+// genObjCValue generates the value for Objective-C code by given type. The
+// list and union simple types are parsed as the arrays and strings.
+func (gen *CodeGenerator) genObjCValue(name string) objcValue {
+	if _, ok := objcBuildInType[name]; ok {
+		return objcValue{fieldType: name, parse: objcBuildInParse[name], scalar: !strings.HasSuffix(name, "*")}
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return objcValue{fieldType: mappedType + " *", class: true}
+	}
+	if simpleType := gen.getSimpleType(name); simpleType != nil {
+		if simpleType.List {
+			item := gen.genObjCValue(gen.getBaseType(simpleType.Base))
+			if item.class {
+				item = objcValue{fieldType: "NSString *", parse: "%s"}
+			}
+			return objcValue{fieldType: fmt.Sprintf("NSArray<%s> *", item.boxedType()), parse: fmt.Sprintf("XGMap(XGWords(%%s), ^id(NSString *item) { return %s; })", item.boxedParse("item"))}
+		}
+		return objcValue{fieldType: "NSString *", parse: "%s"}
+	}
+	fieldType := genObjectiveCFieldName(name)
+	if fieldType == "" {
+		return objcValue{fieldType: "NSString *", parse: "%s"}
+	}
+	return objcValue{fieldType: gen.typeName(fieldType) + " *", class: true}
+}
+
+// className returns the name of the class of the value.
+func (value objcValue) className() string {
+	return strings.TrimSuffix(value.fieldType, " *")
+}
+
+// boxedType returns the type of the value in the collections.
+func (value objcValue) boxedType() string {
+	if value.scalar {
+		return "NSNumber *"
+	}
+	return value.fieldType
+}
+
+// boxedParse returns the expression parsing the value in the collections
+// from the text.
+func (value objcValue) boxedParse(text string) string {
+	if value.scalar {
+		return fmt.Sprintf("@(%s)", fmt.Sprintf(value.parse, text))
+	}
+	return fmt.Sprintf(value.parse, text)
+}
+
+// genObjCPropertyDeclaration generates the declaration of the property by
+// given name and type.
+func genObjCPropertyDeclaration(name string, value objcValue, fieldType string, optional bool) string {
+	attributes := "nonatomic"
+	switch {
+	case value.class && fieldType == value.fieldType:
+		attributes += ", strong"
+	case strings.HasSuffix(fieldType, "*"):
+		attributes += ", copy"
+	}
+	if optional {
+		attributes += ", nullable"
+	}
+	return fmt.Sprintf("@property (%s) %s;\n", attributes, objcDeclare(fieldType, name))
+}
+
+// genObjCElement generates the property for the element.
+func (gen *CodeGenerator) genObjCElement(element Element) objcProperty {
+	value := gen.genObjCValue(gen.getBaseType(element.Type))
+	name, tag := gen.genObjCPropertyName(element.Name), objcQuote(element.Name)
+	var property objcProperty
+	if value.class {
+		property.class = value.className()
+	}
+	switch {
+	case element.Plural:
+		fieldType := fmt.Sprintf("NSArray<%s> *", value.boxedType())
+		property.declaration = genObjCPropertyDeclaration(name, value, fieldType, false)
+		parse := value.boxedParse("item.text")
+		if value.class {
+			parse = fmt.Sprintf("[[%s alloc] initWithXMLNode:item]", value.className())
+		}
+		property.parse = fmt.Sprintf("    _%s = XGMap([node childrenNamed:%s], ^id(XGXMLNode *item) {\n        return %s;\n    });\n", name, tag, parse)
+	case element.Optional:
+		fieldType := value.boxedType()
+		property.declaration, property.child = genObjCPropertyDeclaration(name, value, fieldType, true), true
+		parse := value.boxedParse("child.text")
+		if value.class {
+			parse = fmt.Sprintf("[[%s alloc] initWithXMLNode:child]", value.className())
+		}
+		property.parse = fmt.Sprintf("    child = [node childNamed:%s];\n    if (child != nil) {\n        _%s = %s;\n    }\n", tag, name, parse)
+	default:
+		property.declaration, property.child = genObjCPropertyDeclaration(name, value, value.fieldType, false), true
+		property.parse = fmt.Sprintf("    child = [node childNamed:%s];\n    if (child == nil) {\n        return nil;\n    }\n", tag)
+		if value.class {
+			property.parse += fmt.Sprintf("    _%s = [[%s alloc] initWithXMLNode:child];\n    if (_%s == nil) {\n        return nil;\n    }\n", name, value.className(), name)
+			break
+		}
+		property.parse += fmt.Sprintf("    _%s = %s;\n", name, fmt.Sprintf(value.parse, "child.text"))
+	}
+	return property
+}
+
+// genObjCAttribute generates the property for the attribute.
+func (gen *CodeGenerator) genObjCAttribute(attribute Attribute) objcProperty {
+	value := gen.genObjCValue(gen.getBaseType(attribute.Type))
+	if value.class {
+		value = objcValue{fieldType: "NSString *", parse: "%s"}
+	}
+	if attribute.Plural {
+		value = objcValue{fieldType: fmt.Sprintf("NSArray<%s> *", value.boxedType()), parse: fmt.Sprintf("XGMap(XGWords(%%s), ^id(NSString *item) { return %s; })", value.boxedParse("item"))}
+	}
+	name, key := gen.genObjCPropertyName(attribute.Name), objcQuote(attribute.Name)
+	property := objcProperty{text: true}
+	if attribute.Optional {
+		property.declaration = genObjCPropertyDeclaration(name, value, value.boxedType(), true)
+		property.parse = fmt.Sprintf("    text = node.attributes[%s];\n    if (text != nil) {\n        _%s = %s;\n    }\n", key, name, value.boxedParse("text"))
+		return property
+	}
+	property.declaration = genObjCPropertyDeclaration(name, value, value.fieldType, false)
+	property.parse = fmt.Sprintf("    text = node.attributes[%s];\n    if (text == nil) {\n        return nil;\n    }\n    _%s = %s;\n", key, name, fmt.Sprintf(value.parse, "text"))
+	return property
+}
+
+// genObjCInline generates the property for the group or attribute group,
+// which is initialized from the enclosing element.
+func (gen *CodeGenerator) genObjCInline(name, ref string) objcProperty {
+	value := gen.genObjCValue(gen.getBaseType(ref))
+	name = gen.genObjCPropertyName(name)
+	property := objcProperty{declaration: genObjCPropertyDeclaration(name, value, value.fieldType, false)}
+	if value.class {
+		property.class = value.className()
+		property.parse = fmt.Sprintf("    _%s = [[%s alloc] initWithXMLNode:node];\n    if (_%s == nil) {\n        return nil;\n    }\n", name, value.className(), name)
+	}
+	return property
+}
+
+// objcQuote returns the Objective-C string literal of the given value.
+func objcQuote(value string) string {
+	return "@" + strconv.Quote(value)
+}
+
+// genObjCClass generates the interface and implementation by given
+// declaration and properties, the initializer returns nil if any required
+// attribute or element is missing.
+func (gen *CodeGenerator) genObjCClass(name, doc, source, location, deprecated string, properties []objcProperty) {
+	fieldName := gen.typeName(genObjectiveCFieldName(name))
+	var forward []string
+	declared := map[string]bool{fieldName: true}
+	var content, parse string
+	var text, child bool
+	for _, property := range properties {
+		if property.class != "" && !declared[property.class] {
+			declared[property.class] = true
+			forward = append(forward, property.class)
+		}
+		content += property.declaration
+		parse += property.parse
+		text, child = text || property.text, child || property.child
+	}
+	if content != "" {
+		content += "\n"
+	}
+	var variables string
+	if text {
+		variables += "    NSString *text;\n"
+	}
+	if child {
+		variables += "    XGXMLNode *child;\n"
+	}
+	gen.StructAST[name] = content
+	var classes string
+	if len(forward) > 0 {
+		classes = fmt.Sprintf("\n@class %s;\n", strings.Join(forward, ", "))
+	}
+	gen.Field += fmt.Sprintf("%s%s@interface %[3]s : NSObject\n\n%[4]s/// initWithXMLNode: initializes the %[3]s from the element, and returns nil\n/// if any required attribute or element is missing.\n- (nullable instancetype)initWithXMLNode:(XGXMLNode *)node;\n\n@end\n", classes, genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, content)
+	gen.Implementation += fmt.Sprintf("\n@implementation %s\n\n- (nullable instancetype)initWithXMLNode:(XGXMLNode *)node {\n    if (!(self = [super init])) {\n        return nil;\n    }\n%s%s    return self;\n}\n\n@end\n", fieldName, variables, parse)
+}
+
+// genObjCAlias generates the type definition by given declaration and value,
+// the class is aliased by the compatibility alias.
+func (gen *CodeGenerator) genObjCAlias(name, doc, source, location, deprecated string, value objcValue, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genObjectiveCFieldName(name))
+	declaration := fmt.Sprintf("typedef %s;\n", objcDeclare(fieldType, fieldName))
+	if value.class && fieldType == value.fieldType {
+		declaration = fmt.Sprintf("@class %[1]s;\n@compatibility_alias %[2]s %[1]s;\n", value.className(), fieldName)
+	}
+	gen.Field += fmt.Sprintf("%s%s", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), declaration)
+}
+
+// ObjectiveCSimpleType generates code for simple type XML schema in
+// Objective-C language syntax. The enumerations are generated as the typed
+// string constants.
+func (gen *CodeGenerator) ObjectiveCSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if len(v.Restriction.Enum) > 0 && !v.List && !v.Union {
+		fieldName := gen.typeName(genObjectiveCFieldName(v.Name))
+		var content, implementation string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := fieldName + ConvertCase(enum, PascalCase)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content += fmt.Sprintf("/// %s is the %s value of %s.\nFOUNDATION_EXPORT %s const %s;\n", member, strconv.Quote(enum), fieldName, fieldName, member)
+			implementation += fmt.Sprintf("%s const %s = %s;\n", fieldName, member, objcQuote(enum))
+		}
+		gen.StructAST[v.Name] = content
+		gen.Field += fmt.Sprintf("%stypedef NSString *%s NS_TYPED_ENUM;\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "///")+gen.genDeprecated(v.Deprecated), fieldName, content)
+		gen.Implementation += "\n" + implementation
+		return
+	}
+	value := gen.genObjCValue(v.Name)
+	if !v.List && !v.Union {
+		value = gen.genObjCValue(gen.getBaseType(v.Base))
+	}
+	gen.genObjCAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value, value.fieldType)
+	return
+}
+
+// ObjectiveCComplexType generates code for complex type XML schema in
+// Objective-C language syntax.
+func (gen *CodeGenerator) ObjectiveCComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []objcProperty
+	for _, attrGroup := range v.AttributeGroup {
+		properties = append(properties, gen.genObjCInline(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genObjCAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genObjCInline(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genObjCElement(element))
+	}
+	gen.genObjCClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// ObjectiveCGroup generates code for group XML schema in Objective-C language
+// syntax.
+func (gen *CodeGenerator) ObjectiveCGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []objcProperty
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genObjCElement(element))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genObjCInline(group.Name, group.Ref))
+	}
+	gen.genObjCClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// ObjectiveCAttributeGroup generates code for attribute group XML schema in
+// Objective-C language syntax.
+func (gen *CodeGenerator) ObjectiveCAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []objcProperty
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genObjCAttribute(attribute))
+	}
+	gen.genObjCClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// ObjectiveCElement generates code for element XML schema in Objective-C
+// language syntax.
+func (gen *CodeGenerator) ObjectiveCElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genObjCValue(gen.getBaseType(v.Type))
+	fieldType := value.fieldType
+	if v.Plural {
+		fieldType = fmt.Sprintf("NSArray<%s> *", value.boxedType())
+	}
+	gen.genObjCAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value, fieldType)
+	return
+}
+
+// ObjectiveCAttribute generates code for attribute XML schema in Objective-C
+// language syntax.
+func (gen *CodeGenerator) ObjectiveCAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	value := gen.genObjCValue(gen.getBaseType(v.Type))
+	fieldType := value.fieldType
+	if v.Plural {
+		fieldType = fmt.Sprintf("NSArray<%s> *", value.boxedType())
+	}
+	gen.genObjCAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, value, fieldType)
+	return
+}
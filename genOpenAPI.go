@@ -0,0 +1,109 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// yamlPlainScalar matches the strings which can be written as the plain
+// scalars in YAML without quoting.
+var yamlPlainScalar = regexp.MustCompile(`^[A-Za-z_$]([A-Za-z0-9_./$ ,()'-]*[A-Za-z0-9_./$)'-])?$`)
+
+// yamlReservedScalar defines the plain scalars which are resolved as the
+// booleans or null in YAML, they should be quoted as the strings.
+var yamlReservedScalar = toSet("n", "no", "null", "off", "on", "true", "false", "y", "yes")
+
+// GenOpenAPI generate OpenAPI 3.1 document for XML schema definition files,
+// the types are generated in the schemas of the components. The schemas of
+// OpenAPI 3.1 are JSON Schema (draft 2020-12), so they are generated like the
+// JSON Schema output, and the properties of the attributes are annotated by
+// the XML objects.
+func (gen *CodeGenerator) GenOpenAPI() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("JSONSchema%s", reflect.TypeOf(ele).String()[6:])
+		if err := gen.genDeclaration(ele, funcName); err != nil {
+			return err
+		}
+	}
+	gen.genPlaceholders()
+	schemas := "  schemas: {}\n"
+	if gen.Field != "" {
+		schemas = "  schemas:\n" + gen.Field
+	}
+	source := []byte(fmt.Sprintf("# %s\n\nopenapi: 3.1.0\ninfo:\n  title: %s\n  version: \"1.0\"\ncomponents:\n%s", strings.TrimPrefix(copyright, "// "), yamlQuote(filepath.Base(gen.File)), schemas))
+	return gen.writeFile(gen.File+".openapi.yaml", source)
+}
+
+// yamlQuote returns the YAML scalar of the given string, which is quoted by
+// the escape sequences of JSON strings unless it's a safe plain scalar.
+func yamlQuote(value string) string {
+	if yamlPlainScalar.MatchString(value) && !yamlReservedScalar[strings.ToLower(value)] {
+		return value
+	}
+	data, _ := marshalJSON(value)
+	return string(data)
+}
+
+// genOpenAPIYAML returns the YAML block of the mapping or sequence by given
+// indent, the keywords of the schemas are kept in order.
+func genOpenAPIYAML(value interface{}, indent string) (yaml string) {
+	switch v := value.(type) {
+	case *jsonSchema:
+		for _, key := range v.keys {
+			yaml += indent + yamlQuote(key) + ":" + genOpenAPIYAMLValue(v.values[key], indent)
+		}
+	case []string:
+		for _, item := range v {
+			yaml += indent + "- " + yamlQuote(item) + "\n"
+		}
+	case []interface{}:
+		for _, item := range v {
+			switch item.(type) {
+			case *jsonSchema, []string, []interface{}:
+				yaml += indent + "- " + strings.TrimPrefix(genOpenAPIYAML(item, indent+"  "), indent+"  ")
+			default:
+				yaml += indent + "-" + genOpenAPIYAMLValue(item, indent)
+			}
+		}
+	}
+	return
+}
+
+// genOpenAPIYAMLValue returns the YAML of the value in the mapping by given
+// indent of the key, the empty collections are in the flow style.
+func genOpenAPIYAMLValue(value interface{}, indent string) string {
+	switch v := value.(type) {
+	case *jsonSchema:
+		if len(v.keys) == 0 {
+			return " {}\n"
+		}
+	case []string:
+		if len(v) == 0 {
+			return " []\n"
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return " []\n"
+		}
+	case string:
+		return " " + yamlQuote(v) + "\n"
+	default:
+		data, _ := marshalJSON(v)
+		return " " + string(data) + "\n"
+	}
+	return "\n" + genOpenAPIYAML(value, indent+"  ")
+}
@@ -0,0 +1,346 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var phpBuildInType = map[string]bool{
+	"\\DateTimeImmutable": true,
+	"array":               true,
+	"bool":                true,
+	"float":               true,
+	"int":                 true,
+	"mixed":               true,
+	"string":              true,
+}
+
+// phpSerializerType defines the types of the JMS serializer for the built-in
+// types of PHP.
+var phpSerializerType = map[string]string{
+	"\\DateTimeImmutable": "DateTimeImmutable",
+	"array":               "array<string>",
+	"bool":                "bool",
+	"float":               "float",
+	"int":                 "int",
+	"string":              "string",
+}
+
+// GenPHP generate PHP programming language source code for XML schema
+// definition files. The types are generated as PHP 8 classes with typed
+// properties, and the XML names of the properties are declared by the
+// attributes of the JMS serializer. The generated code requires PHP 8.1 or
+// later for the enumerations.
+func (gen *CodeGenerator) GenPHP() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("PHP%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "use JMS\\Serializer\\Annotation as Serializer;\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("use %s;\n", strings.TrimPrefix(mapping.Import, "\\"))
+	}
+	source := []byte(fmt.Sprintf("<?php\n\n%s\n\ndeclare(strict_types=1);\n\nnamespace %s;\n\n%s%s", copyright, gen.phpNamespace(), importPackage, gen.Field))
+	return gen.writeFile(gen.File+".php", source)
+}
+
+// phpNamespace returns the namespace of the generated PHP code.
+func (gen *CodeGenerator) phpNamespace() string {
+	if gen.Package == "" {
+		return "schema"
+	}
+	return gen.Package
+}
+
+func genPHPFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genPHPPropertyName generates the camel case property name for PHP code.
+func (gen *CodeGenerator) genPHPPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genPHPFieldName(name), CamelCase))
+}
+
+// genPHPFieldType generates the property type and the type of the JMS
+// serializer for PHP code. PHP doesn't have type aliases, so the list and
+// union simple types are generated as an array and the mixed type.
+func (gen *CodeGenerator) genPHPFieldType(name string) (fieldType, serializerType string) {
+	if _, ok := phpBuildInType[name]; ok {
+		return name, phpSerializerType[name]
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		serializerType = mappedType
+		if mapping := gen.TypeMapping[name]; mapping.Import != "" {
+			serializerType = strings.TrimPrefix(mapping.Import, "\\")
+		}
+		return mappedType, serializerType
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		return "array", phpSerializerType["array"]
+	} else if v != nil && v.Union {
+		return "mixed", ""
+	}
+	if fieldType = genPHPFieldName(name); fieldType == "" {
+		return "mixed", ""
+	}
+	fieldType = gen.typeName(fieldType)
+	return fieldType, gen.phpNamespace() + "\\" + fieldType
+}
+
+// genPHPDocType generates the type of the docblocks for PHPStan and Psalm, the
+// arrays of the list types are declared as the list generics of the item
+// type, and the union types as the union of the member types.
+func (gen *CodeGenerator) genPHPDocType(name string) string {
+	fieldType, _ := gen.genPHPFieldType(name)
+	if _, ok := phpBuildInType[name]; ok {
+		if fieldType == "array" {
+			return "list<string>"
+		}
+		return fieldType
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List && fieldType == "array" {
+		return fmt.Sprintf("list<%s>", gen.genPHPDocType(gen.getBaseType(v.Base)))
+	} else if v != nil && v.Union && fieldType == "mixed" {
+		var members []string
+		seen := map[string]bool{}
+		for _, member := range v.MemberTypes {
+			memberType := gen.genPHPDocType(gen.getBaseType(member.Type))
+			if memberType == "mixed" {
+				return "mixed"
+			}
+			if !seen[memberType] {
+				seen[memberType] = true
+				members = append(members, memberType)
+			}
+		}
+		if len(members) > 0 {
+			return strings.Join(members, "|")
+		}
+	}
+	return fieldType
+}
+
+// genPHPDocBlock generates the @var docblock of the property by given type of
+// the docblocks, which is only generated for the arrays and the mixed types
+// where the declaration of the property isn't precise enough for the static
+// analyzers.
+func genPHPDocBlock(docType, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		docType = fmt.Sprintf("list<%s>", docType)
+	case fieldType != "array" && fieldType != "mixed":
+		return ""
+	case optional && docType != "mixed":
+		docType += "|null"
+	}
+	if docType == fieldType {
+		return ""
+	}
+	return fmt.Sprintf("\t/** @var %s */\n", docType)
+}
+
+// genPHPProperty generates the typed property with the attributes of the JMS
+// serializer by given XML name of the element or attribute. The optional
+// property defaults to null and the plural property defaults to an empty
+// array which is inlined in the XML.
+func (gen *CodeGenerator) genPHPProperty(name, typeName string, attribute, plural, optional bool) string {
+	fieldType, serializerType := gen.genPHPFieldType(gen.getBaseType(typeName))
+	content := genPHPDocBlock(gen.genPHPDocType(gen.getBaseType(typeName)), fieldType, plural, optional)
+	content += fmt.Sprintf("\t#[Serializer\\SerializedName(%s)]\n", singleQuote(name))
+	if attribute {
+		content += "\t#[Serializer\\XmlAttribute]\n"
+	}
+	if plural {
+		content += fmt.Sprintf("\t#[Serializer\\XmlList(inline: true, entry: %s)]\n", singleQuote(name))
+		if serializerType != "" {
+			serializerType = fmt.Sprintf("array<%s>", serializerType)
+		}
+	}
+	if serializerType != "" {
+		content += fmt.Sprintf("\t#[Serializer\\Type(%s)]\n", singleQuote(serializerType))
+	}
+	return content + genPHPPropertyDeclaration(gen.genPHPPropertyName(name), fieldType, plural, optional)
+}
+
+// genPHPInlineProperty generates the property for the group or attribute
+// group, which is inlined into the enclosing class in the XML.
+func (gen *CodeGenerator) genPHPInlineProperty(name, ref string) string {
+	fieldType, serializerType := gen.genPHPFieldType(gen.getBaseType(ref))
+	content := "\t#[Serializer\\Inline]\n"
+	if serializerType != "" {
+		content += fmt.Sprintf("\t#[Serializer\\Type(%s)]\n", singleQuote(serializerType))
+	}
+	return content + genPHPPropertyDeclaration(gen.genPHPPropertyName(name), fieldType, false, true)
+}
+
+// genPHPPropertyDeclaration generates the declaration of the typed property.
+func genPHPPropertyDeclaration(name, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		return fmt.Sprintf("\tpublic array $%s = [];\n", name)
+	case optional && fieldType == "mixed":
+		return fmt.Sprintf("\tpublic mixed $%s = null;\n", name)
+	case optional:
+		return fmt.Sprintf("\tpublic ?%s $%s = null;\n", fieldType, name)
+	}
+	return fmt.Sprintf("\tpublic %s $%s;\n", fieldType, name)
+}
+
+// genPHPClass generates the class by given declaration and properties.
+func (gen *CodeGenerator) genPHPClass(name, doc, source, location, deprecated string, properties []string) {
+	content := strings.Join(properties, "\n")
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genPHPFieldName(name))
+	gen.Field += fmt.Sprintf("%sclass %s\n{\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// PHPSimpleType generates code for simple type XML schema in PHP language
+// syntax. Only the enumerations are generated, the other simple types are
+// resolved to the built-in types where they are referenced.
+func (gen *CodeGenerator) PHPSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = ""
+		return
+	}
+	var content string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, PascalCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "Value" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("\tcase %s = %s;\n", member, singleQuote(enum))
+	}
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genPHPFieldName(v.Name))
+	gen.Field += fmt.Sprintf("%senum %s: string\n{\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// PHPComplexType generates code for complex type XML schema in PHP language
+// syntax.
+func (gen *CodeGenerator) PHPComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, attrGroup := range v.AttributeGroup {
+		properties = append(properties, gen.genPHPInlineProperty(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genPHPProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genPHPInlineProperty(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genPHPProperty(element.Name, element.Type, false, element.Plural, element.Optional))
+	}
+	gen.genPHPClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// PHPGroup generates code for group XML schema in PHP language syntax.
+func (gen *CodeGenerator) PHPGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genPHPProperty(element.Name, element.Type, false, element.Plural, element.Optional))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, gen.genPHPInlineProperty(group.Name, group.Ref))
+	}
+	gen.genPHPClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// PHPAttributeGroup generates code for attribute group XML schema in PHP
+// language syntax.
+func (gen *CodeGenerator) PHPAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []string
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genPHPProperty(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional))
+	}
+	gen.genPHPClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// PHPElement generates code for element XML schema in PHP language syntax.
+// The element is generated as the root class, which extends the class of the
+// complex type or holds the value of the simple type.
+func (gen *CodeGenerator) PHPElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genPHPFieldName(v.Name))
+	fieldType, serializerType := gen.genPHPFieldType(gen.getBaseType(v.Type))
+	docType := gen.genPHPDocType(gen.getBaseType(v.Type))
+	if fieldType == fieldName {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	if _, ok := phpBuildInType[fieldType]; ok || v.Plural {
+		if v.Plural {
+			fieldType = "array"
+			if serializerType != "" {
+				serializerType = fmt.Sprintf("array<%s>", serializerType)
+			}
+		}
+		var content string
+		if serializerType != "" {
+			content = fmt.Sprintf("\t#[Serializer\\Type(%s)]\n", singleQuote(serializerType))
+		}
+		content = genPHPDocBlock(docType, fieldType, v.Plural, false) + "\t#[Serializer\\XmlValue]\n" + content + genPHPPropertyDeclaration("value", fieldType, false, false)
+		gen.StructAST[v.Name] = content
+		gen.Field += fmt.Sprintf("%s#[Serializer\\XmlRoot(%s)]\nclass %s\n{\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), singleQuote(v.Name), fieldName, content)
+		return
+	}
+	gen.StructAST[v.Name] = fieldType
+	gen.Field += fmt.Sprintf("%s#[Serializer\\XmlRoot(%s)]\nclass %s extends %s\n{\n}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), singleQuote(v.Name), fieldName, fieldType)
+	return
+}
+
+// PHPAttribute generates code for attribute XML schema in PHP language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) PHPAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
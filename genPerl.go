@@ -0,0 +1,384 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var perlBuildInType = map[string]bool{
+	"Any":           true,
+	"ArrayRef[Str]": true,
+	"Bool":          true,
+	"Int":           true,
+	"Num":           true,
+	"Str":           true,
+}
+
+// perlConverters defines the names of the helper converters for the built-in
+// types of Perl.
+var perlConverters = map[string]string{
+	"Any":           "node",
+	"ArrayRef[Str]": "words",
+	"Bool":          "boolean",
+	"Int":           "number",
+	"Num":           "number",
+	"Str":           "string",
+}
+
+// perlHelpers defines the helper package of the generated code, the {Package}
+// is replaced with the root package name. The converters are referenced by
+// their names, the "list:" prefix converts each word of the text, and the
+// other names are the classes created by their from_node methods. The
+// package may be defined by several generated files, so the redefine
+// warnings are disabled.
+const perlHelpers = `package {Package}::Xgen;
+
+no warnings 'redefine';
+
+use XML::LibXML;
+
+my %converters = (
+    string  => sub { $_[0] },
+    number  => sub { 0 + $_[0] },
+    boolean => sub { $_[0] eq 'true' || $_[0] eq '1' ? 1 : 0 },
+    words   => sub { [split ' ', $_[0]] },
+);
+
+sub parse {
+    my ($xml) = @_;
+    return XML::LibXML->load_xml(string => $xml)->documentElement;
+}
+
+sub children {
+    my ($node, $name) = @_;
+    return grep { $_->isa('XML::LibXML::Element') && $_->localname eq $name } $node->childNodes;
+}
+
+sub convert_text {
+    my ($converter, $text) = @_;
+    if ($converter =~ /^list:(.*)$/) {
+        my $item = $1;
+        return [map { convert_text($item, $_) } split ' ', $text];
+    }
+    return $converters{$converter}->($text);
+}
+
+sub convert_node {
+    my ($converter, $node) = @_;
+    return $node if $converter eq 'node';
+    return convert_text($converter, $node->textContent) if $converter =~ /^list:/ || exists $converters{$converter};
+    return $converter->from_node($node);
+}
+
+sub attribute {
+    my ($node, $name, $converter, $optional) = @_;
+    unless ($node->hasAttribute($name)) {
+        return undef if $optional;
+        die sprintf("missing attribute '%s' in %s\n", $name, $node->nodeName);
+    }
+    return convert_text($converter, $node->getAttribute($name));
+}
+
+sub element {
+    my ($node, $name, $converter, $optional) = @_;
+    my ($child) = children($node, $name);
+    unless ($child) {
+        return undef if $optional;
+        die sprintf("missing element '%s' in %s\n", $name, $node->nodeName);
+    }
+    return convert_node($converter, $child);
+}
+
+sub elements {
+    my ($node, $name, $converter) = @_;
+    return [map { convert_node($converter, $_) } children($node, $name)];
+}
+
+sub compact {
+    my @args;
+    while (my ($key, $value) = splice @_, 0, 2) {
+        push @args, $key, $value if defined $value;
+    }
+    return @args;
+}
+`
+
+// GenPerl generate Perl programming language source code for XML schema
+// definition files. The types are generated as Moo classes with the
+// Types::Standard type constraints, each class can be created from the
+// element parsed by XML::LibXML with the from_node method.
+func (gen *CodeGenerator) GenPerl() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Perl%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var importPackage string
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("use %s;\n", mapping.Import)
+	}
+	if importPackage != "" {
+		importPackage += "\n"
+	}
+	helpers := strings.Replace(perlHelpers, "{Package}", gen.perlPackage(), -1)
+	source := []byte(fmt.Sprintf("# %s\n\nuse strict;\nuse warnings;\n\n%s%s%s\n1;\n", strings.TrimPrefix(copyright, "// "), importPackage, helpers, gen.Field))
+	return gen.writeFile(gen.File+".pm", source)
+}
+
+// perlPackage returns the root package name of the generated Perl code.
+func (gen *CodeGenerator) perlPackage() string {
+	if gen.Package == "" {
+		return "Schema"
+	}
+	return MakeFirstUpperCase(gen.Package)
+}
+
+func genPerlFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genPerlClassName generates the fully qualified package name of the class
+// for Perl code.
+func (gen *CodeGenerator) genPerlClassName(name string) string {
+	return gen.perlPackage() + "::" + gen.typeName(genPerlFieldName(name))
+}
+
+// genPerlAttributeName generates the snake case attribute name of the class
+// for Perl code.
+func (gen *CodeGenerator) genPerlAttributeName(name string) string {
+	return gen.fieldName(ConvertCase(genPerlFieldName(name), SnakeCase))
+}
+
+// genPerlFieldType generates the type constraint and the name of the
+// converter for Perl code. Perl doesn't have type aliases, so the list simple
+// types are generated as array references and the union simple types are
+// generated as strings.
+func (gen *CodeGenerator) genPerlFieldType(name string) (fieldType, converter string) {
+	if _, ok := perlBuildInType[name]; ok {
+		return name, perlConverters[name]
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return fmt.Sprintf("InstanceOf['%s']", mappedType), mappedType
+	}
+	if v := gen.getSimpleType(name); v != nil && v.List {
+		itemType, itemConverter := gen.genPerlFieldType(gen.getBaseType(v.Base))
+		if itemConverter == "string" {
+			return "ArrayRef[Str]", "words"
+		}
+		return fmt.Sprintf("ArrayRef[%s]", itemType), "list:" + itemConverter
+	} else if v != nil {
+		return "Str", "string"
+	}
+	if genPerlFieldName(name) == "" {
+		return "Any", "node"
+	}
+	className := gen.genPerlClassName(name)
+	return fmt.Sprintf("InstanceOf['%s']", className), className
+}
+
+// genPerlAttribute generates the attribute declaration and the constructor
+// argument of the class by given XML name of the element or attribute. The
+// plural attribute defaults to an empty array reference, and the optional
+// attribute is omitted if the node doesn't exist.
+func (gen *CodeGenerator) genPerlAttribute(name, typeName string, attribute, plural, optional bool) (declaration, argument string) {
+	fieldType, converter := gen.genPerlFieldType(gen.getBaseType(typeName))
+	attributeName := gen.genPerlAttributeName(name)
+	var optionalArg string
+	if optional {
+		optionalArg = ", 1"
+	}
+	switch {
+	case attribute:
+		argument = fmt.Sprintf("%s => %s::Xgen::attribute($node, %s, %s%s)", attributeName, gen.perlPackage(), singleQuote(name), singleQuote(converter), optionalArg)
+	case plural:
+		argument = fmt.Sprintf("%s => %s::Xgen::elements($node, %s, %s)", attributeName, gen.perlPackage(), singleQuote(name), singleQuote(converter))
+	default:
+		argument = fmt.Sprintf("%s => %s::Xgen::element($node, %s, %s%s)", attributeName, gen.perlPackage(), singleQuote(name), singleQuote(converter), optionalArg)
+	}
+	return genPerlDeclaration(attributeName, fieldType, plural, optional), argument
+}
+
+// genPerlInlineAttribute generates the attribute declaration and the
+// constructor argument for the group or attribute group, which is created
+// from the enclosing node.
+func (gen *CodeGenerator) genPerlInlineAttribute(name, ref string, plural bool) (declaration, argument string) {
+	fieldType, converter := gen.genPerlFieldType(gen.getBaseType(ref))
+	attributeName := gen.genPerlAttributeName(name)
+	argument = fmt.Sprintf("%s => %s->from_node($node)", attributeName, converter)
+	if plural {
+		argument = fmt.Sprintf("%s => [%s->from_node($node)]", attributeName, converter)
+	}
+	return genPerlDeclaration(attributeName, fieldType, plural, false), argument
+}
+
+// genPerlDeclaration generates the declaration of the attribute of the Moo
+// class.
+func genPerlDeclaration(name, fieldType string, plural, optional bool) string {
+	switch {
+	case plural:
+		return fmt.Sprintf("has %s => (is => 'ro', isa => ArrayRef[%s], default => sub { [] });\n", name, fieldType)
+	case optional:
+		return fmt.Sprintf("has %s => (is => 'ro', isa => %s, predicate => 1);\n", name, fieldType)
+	}
+	return fmt.Sprintf("has %s => (is => 'ro', isa => %s, required => 1);\n", name, fieldType)
+}
+
+// genPerlClass generates the Moo class by given declaration, attributes and
+// the arguments to create it from the node.
+func (gen *CodeGenerator) genPerlClass(name, doc, source, location, deprecated string, declarations, arguments []string) {
+	className := gen.genPerlClassName(name)
+	content := fmt.Sprintf("package %s;\n\nuse Moo;\nuse Types::Standard qw(-types);\n\n", className)
+	if len(declarations) > 0 {
+		content += strings.Join(declarations, "") + "\n"
+	}
+	construct := "$class->new"
+	if len(arguments) > 0 {
+		construct = fmt.Sprintf("$class->new(%s::Xgen::compact(\n        %s,\n    ))", gen.perlPackage(), strings.Join(arguments, ",\n        "))
+	}
+	content += fmt.Sprintf("# from_node creates %s from the element parsed by XML::LibXML.\nsub from_node {\n    my ($class, $node) = @_;\n    return %s;\n}\n", className, construct)
+	gen.StructAST[name] = content
+	gen.Field += genFieldComment(className, doc, source, location, "#") + gen.genDeprecated(deprecated) + content
+}
+
+// PerlSimpleType generates code for simple type XML schema in Perl language
+// syntax. Only the enumerations are generated as the packages of constants,
+// the other simple types are resolved to the type constraints where they are
+// referenced.
+func (gen *CodeGenerator) PerlSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = ""
+		return
+	}
+	var constants []string
+	members := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = gen.constantName(member)
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		constants = append(constants, fmt.Sprintf("    %s => %s,\n", member, singleQuote(enum)))
+	}
+	className := gen.genPerlClassName(v.Name)
+	content := fmt.Sprintf("package %s;\n\nuse constant {\n%s};\n", className, strings.Join(constants, ""))
+	gen.StructAST[v.Name] = content
+	gen.Field += genFieldComment(className, v.Doc, v.Source, v.Location, "#") + gen.genDeprecated(v.Deprecated) + content
+	return
+}
+
+// PerlComplexType generates code for complex type XML schema in Perl language
+// syntax.
+func (gen *CodeGenerator) PerlComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var declarations, arguments []string
+	for _, attrGroup := range v.AttributeGroup {
+		declaration, argument := gen.genPerlInlineAttribute(attrGroup.Name, attrGroup.Ref, false)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	for _, attribute := range v.Attributes {
+		declaration, argument := gen.genPerlAttribute(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		declaration, argument := gen.genPerlInlineAttribute(group.Name, group.Ref, group.Plural)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	for _, element := range v.Elements {
+		declaration, argument := gen.genPerlAttribute(element.Name, element.Type, false, element.Plural, element.Optional)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	gen.genPerlClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, declarations, arguments)
+	return
+}
+
+// PerlGroup generates code for group XML schema in Perl language syntax.
+func (gen *CodeGenerator) PerlGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var declarations, arguments []string
+	for _, element := range v.Elements {
+		declaration, argument := gen.genPerlAttribute(element.Name, element.Type, false, element.Plural, element.Optional)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	for _, group := range v.Groups {
+		declaration, argument := gen.genPerlInlineAttribute(group.Name, group.Ref, group.Plural)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	gen.genPerlClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, declarations, arguments)
+	return
+}
+
+// PerlAttributeGroup generates code for attribute group XML schema in Perl
+// language syntax.
+func (gen *CodeGenerator) PerlAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var declarations, arguments []string
+	for _, attribute := range v.Attributes {
+		declaration, argument := gen.genPerlAttribute(attribute.Name, attribute.Type, true, attribute.Plural, attribute.Optional)
+		declarations, arguments = append(declarations, declaration), append(arguments, argument)
+	}
+	gen.genPerlClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, declarations, arguments)
+	return
+}
+
+// PerlElement generates code for element XML schema in Perl language syntax.
+// The element is generated as the package with the parse method, which
+// parses the XML document with the element as the root by XML::LibXML.
+func (gen *CodeGenerator) PerlElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	className := gen.genPerlClassName(v.Name)
+	_, converter := gen.genPerlFieldType(gen.getBaseType(v.Type))
+	if converter == className {
+		gen.StructAST[v.Name] = converter
+		return
+	}
+	content := fmt.Sprintf("package %[1]s;\n\n# parse parses the XML document with the %[2]s root element by XML::LibXML.\nsub parse {\n    my ($class, $xml) = @_;\n    return %[3]s::Xgen::convert_node(%[4]s, %[3]s::Xgen::parse($xml));\n}\n", className, v.Name, gen.perlPackage(), singleQuote(converter))
+	gen.StructAST[v.Name] = content
+	gen.Field += genFieldComment(className, v.Doc, v.Source, v.Location, "#") + gen.genDeprecated(v.Deprecated) + content
+	return
+}
+
+// PerlAttribute generates code for attribute XML schema in Perl language
+// syntax. The attributes are resolved to their types where they are
+// referenced, so nothing is generated for them.
+func (gen *CodeGenerator) PerlAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.StructAST[v.Name] = ""
+	}
+	return
+}
@@ -0,0 +1,308 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var protobufBuildInType = map[string]bool{
+	"bool":                      true,
+	"bytes":                     true,
+	"double":                    true,
+	"float":                     true,
+	"google.protobuf.Any":       true,
+	"google.protobuf.Timestamp": true,
+	"int32":                     true,
+	"int64":                     true,
+	"repeated string":           true,
+	"string":                    true,
+	"uint32":                    true,
+	"uint64":                    true,
+}
+
+// protobufTypeImports defines the files of the well-known types which are
+// required to be imported.
+var protobufTypeImports = map[string]string{
+	"google.protobuf.Any":       "google/protobuf/any.proto",
+	"google.protobuf.Timestamp": "google/protobuf/timestamp.proto",
+}
+
+// GenProtobuf generate Protocol Buffers (proto3) message definitions for XML
+// schema definition files. The complex types, groups and attribute groups are
+// generated as messages, the enumerations are generated as enums, the lists
+// and unions are generated as the messages with the repeated field and the
+// oneof field. There are no aliases in proto3, so the other simple types are
+// replaced by the scalar types, and the global elements are generated as the
+// messages with a single value field.
+func (gen *CodeGenerator) GenProtobuf() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Protobuf%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	var imports []string
+	for name := range gen.ImportBuildIn {
+		imports = append(imports, protobufTypeImports[name])
+	}
+	for _, mapping := range gen.getImportMappings() {
+		imports = append(imports, mapping.Import)
+	}
+	sort.Strings(imports)
+	var importFile string
+	for i, name := range imports {
+		if i > 0 && imports[i-1] == name {
+			continue
+		}
+		importFile += fmt.Sprintf("import %q;\n", name)
+	}
+	if importFile != "" {
+		importFile = "\n" + importFile
+	}
+	source := []byte(fmt.Sprintf("%s\n\nsyntax = \"proto3\";\n\npackage %s;\n%s%s", copyright, packageName, importFile, gen.Field))
+	return gen.writeFile(gen.File+".proto", source)
+}
+
+func genProtobufFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genProtobufFieldType generates the type for Protocol Buffers by given type
+// name, the enumerations are referred by the enum types, and the other
+// restrictions are replaced by the scalar types. The imports required by the
+// well-known types are recorded.
+func (gen *CodeGenerator) genProtobufFieldType(name string) string {
+	if v := gen.getSimpleType(trimNSPrefix(name)); v != nil && !v.List && !v.Union && len(v.Restriction.Enum) > 0 {
+		return gen.typeName(genProtobufFieldName(v.Name))
+	}
+	name = gen.getBaseType(name)
+	if _, ok := protobufBuildInType[name]; ok {
+		if _, ok = protobufTypeImports[name]; ok {
+			if gen.ImportBuildIn == nil {
+				gen.ImportBuildIn = map[string]bool{}
+			}
+			gen.ImportBuildIn[name] = true
+		}
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genProtobufFieldName(name)
+	if fieldType == "" {
+		return gen.genProtobufFieldType("google.protobuf.Any")
+	}
+	return gen.typeName(fieldType)
+}
+
+// protobufMessage holds the fields of the message in generating, the fields
+// are numbered in the order of declaration.
+type protobufMessage struct {
+	fields []string
+	names  map[string]bool
+}
+
+// addField provides a function to add the field by given XML name and type,
+// the field is labeled by repeated if it's plural, or optional to track the
+// presence. The repeated types can't be nested, so the plural repeated string
+// is flattened. The field number is appended to the name of the field which
+// collides with another, e.g. the attribute and element with the same name.
+func (m *protobufMessage) addField(gen *CodeGenerator, name, fieldType, deprecated string, plural, optional bool) {
+	switch {
+	case strings.HasPrefix(fieldType, "repeated "):
+	case plural:
+		fieldType = "repeated " + fieldType
+	case optional:
+		fieldType = "optional " + fieldType
+	}
+	var options string
+	if deprecated != "" {
+		options = " [deprecated = true]"
+	}
+	number := len(m.fields) + 1
+	fieldName := gen.fieldName(ConvertCase(genProtobufFieldName(name), SnakeCase))
+	if m.names == nil {
+		m.names = map[string]bool{}
+	}
+	if m.names[fieldName] {
+		fieldName = fmt.Sprintf("%s_%d", fieldName, number)
+	}
+	m.names[fieldName] = true
+	m.fields = append(m.fields, fmt.Sprintf("  %s %s = %d%s;\n", fieldType, fieldName, number, options))
+}
+
+// genProtobufMessage generates the message by given declaration and fields.
+func (gen *CodeGenerator) genProtobufMessage(name, doc, source, location, deprecated string, message *protobufMessage) {
+	content := strings.Join(message.fields, "")
+	if deprecated != "" {
+		content = "  option deprecated = true;\n" + content
+	}
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genProtobufFieldName(name))
+	gen.Field += fmt.Sprintf("%smessage %s {\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// ProtobufSimpleType generates code for simple type XML schema in Protocol
+// Buffers syntax. The first value of the enum is the unspecified zero value
+// required by proto3, and the values are prefixed by the enum name since
+// they are scoped in the package.
+func (gen *CodeGenerator) ProtobufSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	message := &protobufMessage{}
+	if v.List {
+		message.addField(gen, "items", gen.genProtobufFieldType(v.Base), "", true, false)
+		gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		for _, member := range v.MemberTypes {
+			memberType := member.Type
+			if gen.getSimpleType(member.Name) != nil {
+				memberType = member.Name
+			}
+			// The fields of oneof can't be repeated, the lists are kept in
+			// the lexical form.
+			message.addField(gen, member.Name, strings.TrimPrefix(gen.genProtobufFieldType(memberType), "repeated "), "", false, false)
+		}
+		message.fields = []string{fmt.Sprintf("  oneof value {\n  %s  }\n", strings.Join(message.fields, "  "))}
+		gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+		return
+	}
+	if len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = gen.genProtobufFieldType(v.Base)
+		return
+	}
+	fieldName := gen.typeName(genProtobufFieldName(v.Name))
+	prefix := ConvertCase(fieldName, ScreamingSnakeCase)
+	content := fmt.Sprintf("  %s_UNSPECIFIED = 0;\n", prefix)
+	if v.Deprecated != "" {
+		content = "  option deprecated = true;\n" + content
+	}
+	members := map[string]bool{prefix + "_UNSPECIFIED": true}
+	for _, enum := range v.Restriction.Enum {
+		member := ConvertCase(enum, ScreamingSnakeCase)
+		if member == "" || (member[0] >= '0' && member[0] <= '9') {
+			member = "VALUE_" + member
+		}
+		member = prefix + "_" + member
+		if members[member] {
+			continue
+		}
+		members[member] = true
+		content += fmt.Sprintf("  %s = %d; // %s\n", member, len(members)-1, strings.Replace(enum, "\n", " ", -1))
+	}
+	gen.StructAST[v.Name] = content
+	gen.Field += fmt.Sprintf("%senum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// ProtobufComplexType generates code for complex type XML schema in Protocol
+// Buffers syntax.
+func (gen *CodeGenerator) ProtobufComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	message := &protobufMessage{}
+	for _, attrGroup := range v.AttributeGroup {
+		message.addField(gen, attrGroup.Name, gen.genProtobufFieldType(attrGroup.Ref), "", false, false)
+	}
+	for _, attribute := range v.Attributes {
+		message.addField(gen, attribute.Name, gen.genProtobufFieldType(attribute.Type), attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	for _, group := range v.Groups {
+		message.addField(gen, group.Name, gen.genProtobufFieldType(group.Ref), "", group.Plural, false)
+	}
+	for _, element := range v.Elements {
+		message.addField(gen, element.Name, gen.genProtobufFieldType(element.Type), element.Deprecated, element.Plural, element.Optional)
+	}
+	gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+	return
+}
+
+// ProtobufGroup generates code for group XML schema in Protocol Buffers
+// syntax.
+func (gen *CodeGenerator) ProtobufGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	message := &protobufMessage{}
+	for _, element := range v.Elements {
+		message.addField(gen, element.Name, gen.genProtobufFieldType(element.Type), element.Deprecated, element.Plural, element.Optional)
+	}
+	for _, group := range v.Groups {
+		message.addField(gen, group.Name, gen.genProtobufFieldType(group.Ref), "", group.Plural, false)
+	}
+	gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+	return
+}
+
+// ProtobufAttributeGroup generates code for attribute group XML schema in
+// Protocol Buffers syntax.
+func (gen *CodeGenerator) ProtobufAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	message := &protobufMessage{}
+	for _, attribute := range v.Attributes {
+		message.addField(gen, attribute.Name, gen.genProtobufFieldType(attribute.Type), attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+	return
+}
+
+// ProtobufElement generates code for element XML schema in Protocol Buffers
+// syntax. The element is generated as the message with the value field of
+// the element type, unless the type has the same name.
+func (gen *CodeGenerator) ProtobufElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genProtobufFieldType(v.Type)
+	if !v.Plural && fieldType == gen.typeName(genProtobufFieldName(v.Name)) {
+		gen.StructAST[v.Name] = fieldType
+		return
+	}
+	message := &protobufMessage{}
+	message.addField(gen, "value", fieldType, "", v.Plural, false)
+	gen.genProtobufMessage(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, message)
+	return
+}
+
+// ProtobufAttribute generates code for attribute XML schema in Protocol
+// Buffers syntax. The references to the global attributes are resolved to
+// their types, and there are no aliases in proto3, so nothing is generated
+// for the attribute.
+func (gen *CodeGenerator) ProtobufAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	gen.StructAST[v.Name] = gen.genProtobufFieldType(v.Type)
+	return
+}
@@ -0,0 +1,404 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var pythonBuildInType = map[string]bool{
+	"Any":       true,
+	"bool":      true,
+	"bytes":     true,
+	"date":      true,
+	"datetime":  true,
+	"Decimal":   true,
+	"float":     true,
+	"int":       true,
+	"List[str]": true,
+	"str":       true,
+	"time":      true,
+}
+
+// pythonInvalidIdentifier matches the characters which can't be used in the
+// Python identifiers.
+var pythonInvalidIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// GenPython generate Python programming language source code for XML schema
+// definition files. The types are generated as dataclasses with type hints,
+// and the XML names of the fields are kept in the field metadata. The
+// generated code requires Python 3.10 or later.
+func (gen *CodeGenerator) GenPython() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Python%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "from __future__ import annotations\n\nfrom dataclasses import dataclass, field\n"
+	if gen.Lxml {
+		importPackage = "from __future__ import annotations\n\nimport base64\nfrom dataclasses import dataclass, field, fields, is_dataclass\n"
+	}
+	if gen.ImportTime {
+		importPackage += "from datetime import date, datetime, time\n"
+	}
+	if gen.ImportDecimal {
+		importPackage += "from decimal import Decimal\n"
+	}
+	importPackage += "from enum import Enum\nfrom typing import Any, List, Optional, TypeAlias, Union\n"
+	if gen.Lxml {
+		importPackage = strings.TrimSuffix(importPackage, "\n") + ", get_args, get_origin, get_type_hints\n\nfrom lxml import etree\n"
+	}
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("from %s import %s\n", mapping.Import, mapping.Type)
+	}
+	if gen.Lxml {
+		importPackage += pythonLxmlHelpers
+	}
+	source := []byte(fmt.Sprintf("# %s\n\n%s%s", strings.TrimPrefix(copyright, "// "), importPackage, gen.Field))
+	return gen.writeFile(gen.File+".py", source)
+}
+
+func genPythonFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genPythonAttrName generates the snake case attribute name of the class for
+// Python code.
+func (gen *CodeGenerator) genPythonAttrName(name string) string {
+	return gen.fieldName(ToSnakeCase(genPythonFieldName(name)))
+}
+
+// genPythonFieldType generates the type hint for Python code, the imports
+// required by the built-in types are recorded.
+func (gen *CodeGenerator) genPythonFieldType(name string, plural bool) (fieldType string) {
+	if _, ok := pythonBuildInType[name]; ok {
+		fieldType = name
+		switch name {
+		case "date", "datetime", "time":
+			gen.ImportTime = true
+		case "Decimal":
+			gen.ImportDecimal = true
+		}
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+	} else if fieldType = genPythonFieldName(name); fieldType == "" {
+		fieldType = "Any"
+	} else {
+		fieldType = gen.typeName(fieldType)
+	}
+	if plural {
+		fieldType = fmt.Sprintf("List[%s]", fieldType)
+	}
+	return
+}
+
+// genPythonField generates the dataclass field by given XML name, namespace
+// and kind (Element, Attribute, Group or AttributeGroup) of the field, the
+// optional field defaults to None and the plural field defaults to an empty
+// list.
+func (gen *CodeGenerator) genPythonField(name, namespace, kind, fieldType string, plural, optional bool) string {
+	metadata := fmt.Sprintf("metadata={\"name\": %q, \"type\": %q}", name, kind)
+	if namespace != "" {
+		metadata = fmt.Sprintf("metadata={\"name\": %q, \"namespace\": %q, \"type\": %q}", name, namespace, kind)
+	}
+	switch {
+	case plural:
+		return fmt.Sprintf("    %s: %s = field(default_factory=list, %s)\n", gen.genPythonAttrName(name), fieldType, metadata)
+	case optional:
+		return fmt.Sprintf("    %s: Optional[%s] = field(default=None, %s)\n", gen.genPythonAttrName(name), fieldType, metadata)
+	}
+	return fmt.Sprintf("    %s: %s = field(%s)\n", gen.genPythonAttrName(name), fieldType, metadata)
+}
+
+// genPythonClass generates the dataclass by given declaration and fields.
+func (gen *CodeGenerator) genPythonClass(name, doc, source, location, deprecated, content string) {
+	fieldName := gen.typeName(genPythonFieldName(name))
+	if gen.Lxml {
+		content += fmt.Sprintf(pythonLxmlMethods, fieldName)
+	}
+	if content == "" {
+		content = "    pass\n"
+	}
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("\n%s@dataclass(kw_only=True)\nclass %s:\n%s", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, content)
+}
+
+// genPythonAlias generates the type alias by given declaration and type, the
+// type is quoted as a forward reference, so the aliases could be declared
+// before the types they refer to.
+func (gen *CodeGenerator) genPythonAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genPythonFieldName(name))
+	gen.Field += fmt.Sprintf("\n%s%s: TypeAlias = %q\n", genFieldComment(fieldName, doc, source, location, "#")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// pythonLxmlMethods defines the methods of the dataclasses parsing and
+// serializing the lxml elements.
+const pythonLxmlMethods = `
+    @classmethod
+    def from_element(cls, element: etree._Element) -> %[1]s:
+        """Parses the %[1]s from the lxml element."""
+        return _from_element(cls, element)
+
+    def to_element(self, tag: str) -> etree._Element:
+        """Serializes the %[1]s as the lxml element by given tag, which is
+        qualified as {namespace}name."""
+        element = etree.Element(tag)
+        _fill_element(element, self)
+        return element
+`
+
+// pythonLxmlHelpers defines the functions converting between the dataclasses
+// and the lxml elements by the XML names and namespaces in the metadata of the
+// fields, the groups and attribute groups are read from and written into the
+// enclosing element.
+const pythonLxmlHelpers = `
+
+XSI_NIL = "{http://www.w3.org/2001/XMLSchema-instance}nil"
+
+
+def _qname(metadata: Any) -> str:
+    if metadata.get("namespace"):
+        return "{%s}%s" % (metadata["namespace"], metadata["name"])
+    return metadata["name"]
+
+
+def _unwrap(hint: Any) -> Any:
+    if get_origin(hint) is Union:
+        args = [arg for arg in get_args(hint) if arg is not type(None)]
+        if len(args) == 1:
+            return args[0]
+    return hint
+
+
+def _decode(hint: Any, text: str) -> Any:
+    hint = _unwrap(hint)
+    if get_origin(hint) is list:
+        return [_decode(get_args(hint)[0], item) for item in text.split()]
+    if get_origin(hint) is Union:
+        for arg in get_args(hint):
+            try:
+                return _decode(arg, text)
+            except (ValueError, ArithmeticError):
+                pass
+        return text
+    if not isinstance(hint, type) or hint is str:
+        return text
+    if hint is bool:
+        return text.strip() in ("true", "1")
+    if hint is bytes:
+        return base64.b64decode(text)
+    if hasattr(hint, "fromisoformat"):
+        return hint.fromisoformat(text.strip())
+    if issubclass(hint, Enum):
+        return hint(text)
+    return hint(text.strip())
+
+
+def _encode(value: Any) -> str:
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if isinstance(value, Enum):
+        return _encode(value.value)
+    if isinstance(value, list):
+        return " ".join(_encode(item) for item in value)
+    if isinstance(value, bytes):
+        return base64.b64encode(value).decode()
+    if hasattr(value, "isoformat"):
+        return value.isoformat()
+    return str(value)
+
+
+def _from_child(hint: Any, child: etree._Element) -> Any:
+    if child.get(XSI_NIL) in ("true", "1"):
+        return None
+    hint = _unwrap(hint)
+    if is_dataclass(hint):
+        return _from_element(hint, child)
+    return _decode(hint, child.text or "")
+
+
+def _from_element(cls: Any, element: etree._Element) -> Any:
+    hints = get_type_hints(cls)
+    values = {}
+    for item in fields(cls):
+        hint, kind = hints[item.name], item.metadata["type"]
+        plural = item.default_factory is list
+        if plural and kind != "Attribute":
+            hint = get_args(_unwrap(hint))[0]
+        if kind == "Attribute":
+            text = element.get(_qname(item.metadata))
+            if text is not None:
+                values[item.name] = _decode(hint, text)
+        elif kind != "Element":
+            value = _from_element(_unwrap(hint), element)
+            values[item.name] = [value] if plural else value
+        else:
+            children = [_from_child(hint, child) for child in element.findall(_qname(item.metadata))]
+            if plural:
+                values[item.name] = children
+            elif children:
+                values[item.name] = children[0]
+    return cls(**values)
+
+
+def _fill_element(element: etree._Element, value: Any) -> None:
+    for item in fields(value):
+        kind, field_value = item.metadata["type"], getattr(value, item.name)
+        if field_value is None:
+            continue
+        if kind == "Attribute":
+            if field_value != []:
+                element.set(_qname(item.metadata), _encode(field_value))
+            continue
+        for field_item in field_value if item.default_factory is list else [field_value]:
+            if kind != "Element":
+                _fill_element(element, field_item)
+                continue
+            child = etree.SubElement(element, _qname(item.metadata))
+            if field_item is None:
+                child.set(XSI_NIL, "true")
+            elif is_dataclass(field_item):
+                _fill_element(child, field_item)
+            else:
+                child.text = _encode(field_item)
+`
+
+// genPythonEnumMember generates the enumeration member name for Python code.
+func (gen *CodeGenerator) genPythonEnumMember(value string) string {
+	name := strings.Trim(pythonInvalidIdentifier.ReplaceAllString(strings.ToUpper(ToSnakeCase(value)), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "VALUE_" + name
+	}
+	return gen.constantName(name)
+}
+
+// PythonSimpleType generates code for simple type XML schema in Python
+// language syntax.
+func (gen *CodeGenerator) PythonSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genPythonAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genPythonFieldType(gen.getBaseType(v.Base), true))
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var memberTypes []string
+		for _, member := range v.MemberTypes {
+			memberTypes = append(memberTypes, gen.genPythonFieldType(gen.getBaseType(member.Type), false))
+		}
+		gen.genPythonAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("Union[%s]", strings.Join(memberTypes, ", ")))
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := gen.genPythonEnumMember(enum)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content += fmt.Sprintf("    %s = %q\n", member, enum)
+		}
+		gen.StructAST[v.Name] = content
+		fieldName := gen.typeName(genPythonFieldName(v.Name))
+		gen.Field += fmt.Sprintf("\n%sclass %s(Enum):\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, content)
+		return
+	}
+	gen.genPythonAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genPythonFieldType(gen.getBaseType(v.Base), false))
+	return
+}
+
+// PythonComplexType generates code for complex type XML schema in Python
+// language syntax.
+func (gen *CodeGenerator) PythonComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, attrGroup := range v.AttributeGroup {
+		content += gen.genPythonField(attrGroup.Name, "", "AttributeGroup", gen.genPythonFieldType(gen.getBaseType(attrGroup.Ref), false), false, true)
+	}
+	for _, attribute := range v.Attributes {
+		content += gen.genPythonField(attribute.Name, attribute.Namespace, "Attribute", gen.genPythonFieldType(gen.getBaseType(attribute.Type), attribute.Plural), attribute.Plural, attribute.Optional)
+	}
+	for _, group := range v.Groups {
+		content += gen.genPythonField(group.Name, "", "Group", gen.genPythonFieldType(gen.getBaseType(group.Ref), group.Plural), group.Plural, true)
+	}
+	for _, element := range v.Elements {
+		content += gen.genPythonField(element.Name, element.Namespace, "Element", gen.genPythonFieldType(gen.getBaseType(element.Type), element.Plural), element.Plural, element.Optional)
+	}
+	gen.genPythonClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// PythonGroup generates code for group XML schema in Python language syntax.
+func (gen *CodeGenerator) PythonGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, element := range v.Elements {
+		content += gen.genPythonField(element.Name, element.Namespace, "Element", gen.genPythonFieldType(gen.getBaseType(element.Type), element.Plural), element.Plural, element.Optional)
+	}
+	for _, group := range v.Groups {
+		content += gen.genPythonField(group.Name, "", "Group", gen.genPythonFieldType(gen.getBaseType(group.Ref), group.Plural), group.Plural, true)
+	}
+	gen.genPythonClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// PythonAttributeGroup generates code for attribute group XML schema in
+// Python language syntax.
+func (gen *CodeGenerator) PythonAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	for _, attribute := range v.Attributes {
+		content += gen.genPythonField(attribute.Name, attribute.Namespace, "Attribute", gen.genPythonFieldType(gen.getBaseType(attribute.Type), attribute.Plural), attribute.Plural, attribute.Optional)
+	}
+	gen.genPythonClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, content)
+	return
+}
+
+// PythonElement generates code for element XML schema in Python language
+// syntax.
+func (gen *CodeGenerator) PythonElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genPythonAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genPythonFieldType(gen.getBaseType(v.Type), v.Plural))
+	}
+	return
+}
+
+// PythonAttribute generates code for attribute XML schema in Python language
+// syntax.
+func (gen *CodeGenerator) PythonAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genPythonAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genPythonFieldType(gen.getBaseType(v.Type), v.Plural))
+	}
+	return
+}
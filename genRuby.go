@@ -10,7 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -37,20 +36,22 @@ var rubyBuildinType = map[string]bool{
 // definition files.
 func (gen *CodeGenerator) GenRuby() error {
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
 			continue
 		}
 		funcName := fmt.Sprintf("Ruby%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration(ele, funcName)
 	}
-	f, err := os.Create(gen.File + ".rb")
-	if err != nil {
-		return err
+	gen.genPlaceholders()
+	var requirePackage string
+	if gen.ImportActiveModel {
+		requirePackage = "require 'active_model'\n"
 	}
-	defer f.Close()
-	source := []byte(fmt.Sprintf("# frozen_string_literal: true\n\n%s\n\nrequire 'xmlmapper'\n\nmodule Ota\n\t%s\nend", `# Code generated by xgen. DO NOT EDIT.`, gen.Field))
-	f.Write(source)
-	return err
+	for _, mapping := range gen.getImportMappings() {
+		requirePackage += fmt.Sprintf("require '%s'\n", mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("# frozen_string_literal: true\n\n%s\n\nrequire 'xmlmapper'\n%s\nmodule Ota\n\t%s\nend", `# Code generated by xgen. DO NOT EDIT.`, requirePackage, gen.Field))
+	return gen.writeFile(gen.File+".rb", source)
 }
 
 func genRubyFieldName(name string) (fieldName string) {
@@ -66,79 +67,166 @@ func genRubyFieldName(name string) (fieldName string) {
 	return
 }
 
-func genRubyFieldType(name string) string {
+func (gen *CodeGenerator) genRubyFieldType(name string) string {
 	if _, ok := rubyBuildinType[name]; ok {
 		return name
 	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
 	var fieldType string
 	for _, str := range strings.Split(name, ".") {
 		fieldType += MakeFirstUpperCase(str)
 	}
 	fieldType = strings.Replace(MakeFirstUpperCase(strings.Replace(fieldType, "-", "", -1)), "_", "", -1)
 	if fieldType != "" {
-		return fieldType
+		return gen.typeName(fieldType)
 	}
 	return "String"
 }
 
+// genRubyValidation generates ActiveModel validates declaration for the field
+// by given type, occurrence and facets of the field, returns empty string if
+// there is nothing to validate. The required booleans are validated by the
+// inclusion, since false isn't present for ActiveModel.
+func (gen *CodeGenerator) genRubyValidation(name, fieldType string, required bool, restriction Restriction) string {
+	var rules []string
+	switch {
+	case required && fieldType == "Boolean" && len(restriction.Enum) == 0:
+		rules = append(rules, "inclusion: { in: [true, false] }")
+	case required:
+		rules = append(rules, "presence: true")
+	}
+	if restriction.Pattern != nil {
+		rules = append(rules, fmt.Sprintf("format: { with: /\\A(?:%s)\\z/ }", strings.ReplaceAll(restriction.Pattern.String(), "/", "\\/")))
+	}
+	var length []string
+	if restriction.MinLength > 0 {
+		length = append(length, fmt.Sprintf("minimum: %d", restriction.MinLength))
+	}
+	if restriction.MaxLength > 0 {
+		length = append(length, fmt.Sprintf("maximum: %d", restriction.MaxLength))
+	}
+	if len(length) > 0 {
+		rules = append(rules, fmt.Sprintf("length: { %s }", strings.Join(length, ", ")))
+	}
+	if len(restriction.Enum) > 0 {
+		var enums []string
+		for _, enum := range restriction.Enum {
+			enums = append(enums, fmt.Sprintf("'%s'", strings.ReplaceAll(enum, "'", "\\'")))
+		}
+		rules = append(rules, fmt.Sprintf("inclusion: { in: [%s] }", strings.Join(enums, ", ")))
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	if !required {
+		rules = append(rules, "allow_nil: true")
+	}
+	return fmt.Sprintf("\t\tvalidates :%s, %s\n", gen.fieldName(ToSnakeCase(genRubyFieldName(name))), strings.Join(rules, ", "))
+}
+
+// genRubyOccursValidation generates ActiveModel validates declaration for
+// the number of the repeated element by given element, returns empty string
+// if the number isn't bounded.
+func (gen *CodeGenerator) genRubyOccursValidation(element Element) string {
+	if !element.Plural {
+		return ""
+	}
+	var length []string
+	if element.MinOccurs > 0 {
+		length = append(length, fmt.Sprintf("minimum: %d", element.MinOccurs))
+	}
+	if element.MaxOccurs != Unbounded {
+		length = append(length, fmt.Sprintf("maximum: %d", element.MaxOccurs))
+	}
+	if len(length) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\t\tvalidates :%s, length: { %s }\n", gen.fieldName(ToSnakeCase(genRubyFieldName(element.Name))), strings.Join(length, ", "))
+}
+
 // RubySimpleType generates code for simple type XML schema in Ruby language
 // syntax.
 func (gen *CodeGenerator) RubySimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genRubyFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf(" %s", genRubyFieldType(fieldType))
+			fieldType := gen.genRubyFieldType(gen.getBaseType(v.Base))
+			content := fmt.Sprintf(" %s", gen.genRubyFieldType(fieldType))
 			gen.StructAST[v.Name] = content
-			fieldName := genRubyFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%s\nclass %s < %s; end\n", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genRubyFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%s\nclass %s < %s; end\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			content := ""
-			fieldName := genRubyFieldName(v.Name)
+			fieldName := gen.typeName(genRubyFieldName(v.Name))
 			if fieldName != v.Name {
 				gen.ImportEncodingXML = true
 				content += fmt.Sprintf("\t\ttag \"%s\"\n", v.Name)
 			}
 
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
-				// content += fmt.Sprintf("\t%s\t%s\n", ToSnakeCase(genRubyFieldName(memberName)), genRubyFieldType(memberType))
-				content += fmt.Sprintf("\t\tattribute :%s, 'OTA::%s', tag: '%s'\n", ToSnakeCase(genRubyFieldName(memberName)), genRubyFieldType(memberType), memberName)
+			for _, member := range v.MemberTypes {
+				// content += fmt.Sprintf("\t%s\t%s\n", ToSnakeCase(genRubyFieldName(member.Name)), genRubyFieldType(member.Type))
+				content += fmt.Sprintf("\t\tattribute :%s, 'OTA::%s', tag: '%s'\n", gen.fieldName(ToSnakeCase(genRubyFieldName(member.Name))), gen.genRubyFieldType(member.Type), member.Name)
 			}
 			content += "\tend\n"
 			gen.StructAST[v.Name] = content
-			gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s\n", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+			gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := fmt.Sprintf(" %s", genRubyFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)))
+		content := fmt.Sprintf(" %s", gen.genRubyFieldType(gen.getBaseType(v.Base)))
 		gen.StructAST[v.Name] = content
-		fieldName := genRubyFieldName(v.Name)
-		gen.Field += fmt.Sprintf("\t%s\tclass %s <%s; end\n", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
+		if len(v.Restriction.Enum) > 0 {
+			gen.Field += fmt.Sprintf("\t%s\tclass %s <%s\n%s\tend\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name], gen.genRubyEnumConstants(v.Restriction.Enum))
+			return
+		}
+		gen.Field += fmt.Sprintf("\t%s\tclass %s <%s; end\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
 
+// genRubyEnumConstants generates the frozen constants for the enumeration
+// values and the VALUES constant lists all of them.
+func (gen *CodeGenerator) genRubyEnumConstants(enums []string) string {
+	var content string
+	var constants []string
+	for i, name := range enumNames(enums) {
+		constant := ConvertCase(name, ScreamingSnakeCase)
+		content += fmt.Sprintf("\t\t%s = '%s'\n", constant, strings.ReplaceAll(strings.ReplaceAll(enums[i], "\\", "\\\\"), "'", "\\'"))
+		constants = append(constants, constant)
+	}
+	return content + fmt.Sprintf("\t\tVALUES = [%s].freeze\n", strings.Join(constants, ", "))
+}
+
 // RubyComplexType generates code for complex type XML schema in Ruby language
 // syntax.
 func (gen *CodeGenerator) RubyComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := ""
-		fieldName := genRubyFieldName(v.Name)
+		var content, include, validations string
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
 		if fieldName != v.Name {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\t\ttag \"%s\"\n", v.Name)
 		}
+		var extends string
+		if gen.hasSimpleContent(v) {
+			content += fmt.Sprintf("\t\tcontent :value, %s\n", gen.genRubyFieldType(gen.getBaseType(v.Base)))
+		} else if v.Base != "" {
+			extends = " < " + gen.genRubyFieldType(gen.getBaseType(v.Base))
+		}
+		if v.Mixed && !gen.hasSimpleContent(v) {
+			content += "\t\tcontent :value, String\n"
+		}
 		for _, attrGroup := range v.AttributeGroup {
-			// fmt.Printf("%s\n", getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree))
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t\telement :%s, 'OTA::%s', tag: '%s'\n", ToSnakeCase(genRubyFieldName(attrGroup.Name)), genRubyFieldType(fieldType), genRubyFieldName(attrGroup.Name))
+			// fmt.Printf("%s\n", gen.getBaseType(attrGroup.Ref))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t\telement :%s, 'OTA::%s', tag: '%s'\n", gen.fieldName(ToSnakeCase(genRubyFieldName(attrGroup.Name))), gen.genRubyFieldType(fieldType), genRubyFieldName(attrGroup.Name))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -146,15 +234,16 @@ func (gen *CodeGenerator) RubyComplexType(v *ComplexType) {
 			if attribute.Plural {
 				plural = "has_many"
 			}
-			fieldType := genRubyFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t\t%s :%s, 'OTA::%s', tag: '%s'\n", plural, ToSnakeCase(genRubyFieldName(attribute.Name)), fieldType, attribute.Name)
+			fieldType := gen.genRubyFieldType(gen.getBaseType(attribute.Type))
+			content += fmt.Sprintf("\t\t%s :%s, 'OTA::%s', tag: '%s'\n", plural, gen.fieldName(ToSnakeCase(genRubyFieldName(attribute.Name))), fieldType, attribute.Name)
+			validations += gen.genRubyValidation(attribute.Name, fieldType, !attribute.Optional, attribute.Restriction)
 		}
 		for _, group := range v.Groups {
 			var plural string
 			if group.Plural {
 				plural = ""
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", ToSnakeCase(genRubyFieldName(group.Name)), plural, genRubyFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(ToSnakeCase(genRubyFieldName(group.Name))), plural, gen.genRubyFieldType(gen.getBaseType(group.Ref)))
 		}
 
 		for _, element := range v.Elements {
@@ -162,12 +251,19 @@ func (gen *CodeGenerator) RubyComplexType(v *ComplexType) {
 			if element.Plural {
 				plural = "has_many"
 			}
-			fieldType := genRubyFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t\t%s :%s, 'OTA::%s', tag: '%s'\n", plural, ToSnakeCase(genRubyFieldName(element.Name)), fieldType, element.Name)
+			fieldType := gen.genRubyFieldType(gen.getBaseType(element.Type))
+			content += fmt.Sprintf("\t\t%s :%s, 'OTA::%s', tag: '%s'\n", plural, gen.fieldName(ToSnakeCase(genRubyFieldName(element.Name))), fieldType, element.Name)
+			validations += gen.genRubyValidation(element.Name, fieldType, !element.Optional, element.Restriction)
+			validations += gen.genRubyOccursValidation(element)
+		}
+		if validations != "" {
+			gen.ImportActiveModel = true
+			include = "\t\tinclude ActiveModel::Validations\n"
+			content += "\n" + validations
 		}
 		content += "\tend\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\t%s\tclass %s%s\n\t\tinclude XmlMapper\n%s\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, extends, include, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -176,7 +272,7 @@ func (gen *CodeGenerator) RubyComplexType(v *ComplexType) {
 func (gen *CodeGenerator) RubyGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := ""
-		fieldName := genRubyFieldName(v.Name)
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
 		if fieldName != v.Name {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\t\ttag \"%s\"\n", v.Name)
@@ -186,7 +282,7 @@ func (gen *CodeGenerator) RubyGroup(v *Group) {
 			if element.Plural {
 				plural = ""
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", ToSnakeCase(genRubyFieldName(element.Name)), plural, genRubyFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)))
+			content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(ToSnakeCase(genRubyFieldName(element.Name))), plural, gen.genRubyFieldType(gen.getBaseType(element.Type)))
 
 		}
 
@@ -195,11 +291,11 @@ func (gen *CodeGenerator) RubyGroup(v *Group) {
 			if group.Plural {
 				plural = ""
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", ToSnakeCase(genRubyFieldName(group.Name)), plural, genRubyFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			content += fmt.Sprintf("\t%s\t%s%s\n", gen.fieldName(ToSnakeCase(genRubyFieldName(group.Name))), plural, gen.genRubyFieldType(gen.getBaseType(group.Ref)))
 		}
 		content += "\tend\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -208,20 +304,26 @@ func (gen *CodeGenerator) RubyGroup(v *Group) {
 // syntax.
 func (gen *CodeGenerator) RubyAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := ""
-		fieldName := genRubyFieldName(v.Name)
+		var content, include, validations string
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
 		if fieldName != v.Name {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\t\ttag \"%s\"\n", v.Name)
 		}
 		for _, attribute := range v.Attributes {
-			// content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", ToSnakeCase(genRubyFieldName(attribute.Name)), genRubyFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)), attribute.Name, optional)
-			content += fmt.Sprintf("\t\tattribute :%s, 'OTA::%s', tag: '%s'\n", ToSnakeCase(genRubyFieldName(attribute.Name)), genRubyFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)), attribute.Name)
+			// content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", ToSnakeCase(genRubyFieldName(attribute.Name)), genRubyFieldType(gen.getBaseType(attribute.Type)), attribute.Name, optional)
+			content += fmt.Sprintf("\t\tattribute :%s, 'OTA::%s', tag: '%s'\n", gen.fieldName(ToSnakeCase(genRubyFieldName(attribute.Name))), gen.genRubyFieldType(gen.getBaseType(attribute.Type)), attribute.Name)
 			// fmt.Println(attribute.Name)
+			validations += gen.genRubyValidation(attribute.Name, gen.genRubyFieldType(gen.getBaseType(attribute.Type)), !attribute.Optional, attribute.Restriction)
+		}
+		if validations != "" {
+			gen.ImportActiveModel = true
+			include = "\t\tinclude ActiveModel::Validations\n"
+			content += "\n" + validations
 		}
 		content += "\tend\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n\n%s", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\t%s\tclass %s\n\t\tinclude XmlMapper\n%s\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, include, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -229,14 +331,14 @@ func (gen *CodeGenerator) RubyAttributeGroup(v *AttributeGroup) {
 // RubyElement generates code for element XML schema in Ruby language syntax.
 func (gen *CodeGenerator) RubyElement(v *Element) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var plural string = genRubyFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var plural string = gen.genRubyFieldType(gen.getBaseType(v.Type))
 		if v.Plural {
 			plural = "Array"
 		}
 		content := fmt.Sprintf(" < %s; ", plural)
 		gen.StructAST[v.Name] = content
-		fieldName := genRubyFieldName(v.Name)
-		gen.Field += fmt.Sprintf("\t%s\tclass %s%send\n", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
+		gen.Field += fmt.Sprintf("\t%s\tclass %s%send\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -244,14 +346,14 @@ func (gen *CodeGenerator) RubyElement(v *Element) {
 // RubyAttribute generates code for attribute XML schema in Ruby language syntax.
 func (gen *CodeGenerator) RubyAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var plural string = genRubyFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var plural string = gen.genRubyFieldType(gen.getBaseType(v.Type))
 		if v.Plural {
 			plural = "Array"
 		}
 		content := fmt.Sprintf(" < %s; ", plural)
 		gen.StructAST[v.Name] = content
-		fieldName := genRubyFieldName(v.Name)
-		gen.Field += fmt.Sprintf("\t%s\tclass %s%send\n", genFieldComment(fieldName, v.Doc, "#"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genRubyFieldName(v.Name))
+		gen.Field += fmt.Sprintf("\t%s\tclass %s%send\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "#")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -10,7 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -38,85 +37,30 @@ var (
 		"char":        true,
 		"String":      true,
 	}
-	rustKeywords = map[string]bool{
-		"as":       true,
-		"break":    true,
-		"const":    true,
-		"continue": true,
-		"crate":    true,
-		"dyn":      true,
-		"else":     true,
-		"enum":     true,
-		"extern":   true,
-		"false":    true,
-		"fn":       true,
-		"for":      true,
-		"if":       true,
-		"impl":     true,
-		"in":       true,
-		"let":      true,
-		"loop":     true,
-		"match":    true,
-		"mod":      true,
-		"move":     true,
-		"mut":      true,
-		"pub":      true,
-		"ref":      true,
-		"return":   true,
-		"Self":     true,
-		"self":     true,
-		"static":   true,
-		"struct":   true,
-		"super":    true,
-		"trait":    true,
-		"true":     true,
-		"type":     true,
-		"unsafe":   true,
-		"use":      true,
-		"where":    true,
-		"while":    true,
-		"abstract": true,
-		"async":    true,
-		"await":    true,
-		"become":   true,
-		"box":      true,
-		"do":       true,
-		"final":    true,
-		"macro":    true,
-		"override": true,
-		"priv":     true,
-		"try":      true,
-		"typeof":   true,
-		"unsized":  true,
-		"virtual":  true,
-		"yield":    true,
-	}
 )
 
 // GenRust generate Go programming language source code for XML schema
 // definition files.
 func (gen *CodeGenerator) GenRust() error {
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
 			continue
 		}
 		funcName := fmt.Sprintf("Rust%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
-	}
-	f, err := os.Create(gen.File + ".rs")
-	if err != nil {
-		return err
+		gen.genDeclaration(ele, funcName)
 	}
-	defer f.Close()
+	gen.genPlaceholders()
 	var extern = `#[macro_use]
 extern crate serde_derive;
 extern crate serde;
 extern crate serde_xml_rs;
 
 use serde_xml_rs::from_reader;`
+	for _, mapping := range gen.getImportMappings() {
+		extern += fmt.Sprintf("\nuse %s;", mapping.Import)
+	}
 	source := []byte(fmt.Sprintf("%s\n\n%s\n%s", copyright, extern, gen.Field))
-	f.Write(source)
-	return err
+	return gen.writeFile(gen.File+".rs", source)
 }
 
 // genRustFieldName generate struct field name for Rust code.
@@ -130,9 +74,6 @@ func genRustFieldName(name string) (fieldName string) {
 	}
 	fieldName = tmp
 	fieldName = ToSnakeCase(strings.Replace(fieldName, "-", "", -1))
-	if _, ok := rustKeywords[fieldName]; ok {
-		fieldName += "_attr"
-	}
 	return
 }
 
@@ -151,50 +92,84 @@ func genRustStructName(name string) (structName string) {
 }
 
 // genRustFieldType generate struct field type for Rust code.
-func genRustFieldType(name string) string {
+func (gen *CodeGenerator) genRustFieldType(name string) string {
 	if _, ok := rustBuildinType[name]; ok {
 		return name
 	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
 	fieldType := genRustStructName(name)
 	if fieldType != "" {
-		return fieldType
+		return gen.typeName(fieldType)
 	}
 	return "char"
 }
 
+// genRustListImpls generates the implementations of serialization for the
+// list type by given type name, which serialize and deserialize the items as
+// the space-separated list in the character data or attribute value.
+func genRustListImpls(typeName string) string {
+	return fmt.Sprintf(`
+impl serde::Serialize for %[1]s {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		let items: Vec<String> = self.0.iter().map(|item| item.to_string()).collect();
+		serializer.serialize_str(&items.join(" "))
+	}
+}
+
+impl<'de> serde::Deserialize<'de> for %[1]s {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let value = <String as serde::Deserialize>::deserialize(deserializer)?;
+		value.split_whitespace().map(|item| item.parse().map_err(serde::de::Error::custom)).collect::<Result<Vec<_>, _>>().map(%[1]s)
+	}
+}
+`, typeName)
+}
+
 // RustSimpleType generates code for simple type XML schema in Rust language
 // syntax.
 func (gen *CodeGenerator) RustSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", v.Name, genRustFieldName(v.Name), fieldType)
+			fieldType := gen.genRustFieldType(gen.getBaseType(v.Base))
+			content := fmt.Sprintf("(pub Vec<%s>);\n", fieldType)
 			gen.StructAST[v.Name] = content
-			fieldName := genRustStructName(v.Name)
-			gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genRustStructName(v.Name))
+			gen.Field += fmt.Sprintf("\n%s#[derive(Debug, PartialEq)]\npub struct %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+			gen.Field += genRustListImpls(fieldName)
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			var content string
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
-				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, genRustFieldName(memberName), genRustFieldType(memberType))
+			for _, member := range v.MemberTypes {
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, gen.fieldName(genRustFieldName(member.Name)), gen.genRustFieldType(member.Type))
 			}
 			gen.StructAST[v.Name] = content
 			gen.Field += fmt.Sprintf("\n#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genRustStructName(v.Name), gen.StructAST[v.Name])
 		}
 		return
 	}
+	if len(v.Restriction.Enum) > 0 {
+		if _, ok := gen.StructAST[v.Name]; !ok {
+			var content string
+			for i, name := range enumNames(v.Restriction.Enum) {
+				content += fmt.Sprintf("\t#[serde(rename = %q)]\n\t%s,\n", v.Restriction.Enum[i], gen.constantName(name))
+			}
+			gen.StructAST[v.Name] = content
+			fieldName := gen.typeName(genRustStructName(v.Name))
+			gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub enum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		}
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-		content := fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, genRustFieldName(v.Name), fieldType)
+		fieldType := gen.genRustFieldType(gen.getBaseType(v.Base))
+		content := fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, gen.fieldName(genRustFieldName(v.Name)), fieldType)
 		gen.StructAST[v.Name] = content
-		fieldName := genRustStructName(v.Name)
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genRustStructName(v.Name))
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -205,20 +180,23 @@ func (gen *CodeGenerator) RustComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var content string
 		for _, attrGroup := range v.AttributeGroup {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", attrGroup.Name, genRustFieldName(attrGroup.Name), genRustFieldType(fieldType))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", attrGroup.Name, gen.fieldName(genRustFieldName(attrGroup.Name)), gen.genRustFieldType(fieldType))
 		}
 		for _, attribute := range v.Attributes {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+			fieldType := gen.genRustFieldType(gen.getBaseType(attribute.Type))
 			if attribute.Optional {
-				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", attribute.Name, genRustFieldName(attribute.Name), fieldType)
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", attribute.Name, gen.fieldName(genRustFieldName(attribute.Name)), fieldType)
 			} else {
-				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", attribute.Name, genRustFieldName(attribute.Name), fieldType)
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", attribute.Name, gen.fieldName(genRustFieldName(attribute.Name)), fieldType)
 			}
 		}
 		for _, group := range v.Groups {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
-			fieldName := genRustFieldName(group.Name)
+			fieldType := gen.genRustFieldType(gen.getBaseType(group.Ref))
+			fieldName := gen.fieldName(genRustFieldName(group.Name))
+			if !group.Plural && gen.isRecursiveType(group.Ref, v.Name) {
+				fieldType = fmt.Sprintf("Box<%s>", fieldType)
+			}
 			if group.Plural {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", group.Name, fieldName, fieldType)
 			} else {
@@ -226,12 +204,20 @@ func (gen *CodeGenerator) RustComplexType(v *ComplexType) {
 			}
 		}
 		for _, element := range v.Elements {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
-			fieldName := genRustFieldName(element.Name)
+			if element.Choice != "" {
+				continue
+			}
+			fieldType := gen.genRustFieldType(gen.getBaseType(element.Type))
+			fieldName := gen.fieldName(genRustFieldName(element.Name))
+			if !element.Plural && gen.isRecursiveType(element.Type, v.Name) {
+				// the recursive field is boxed, since the size of the struct
+				// holding itself is infinite.
+				fieldType = fmt.Sprintf("Box<%s>", fieldType)
+			}
 			if element.Plural {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", element.Name, fieldName, fieldType)
 			} else {
-				if element.Optional {
+				if element.Optional || element.Nillable {
 					content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", element.Name, fieldName, fieldType)
 				} else {
 					content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", element.Name, fieldName, fieldType)
@@ -239,9 +225,11 @@ func (gen *CodeGenerator) RustComplexType(v *ComplexType) {
 			}
 
 		}
+		fieldName := gen.typeName(genRustStructName(v.Name))
+		content += gen.genRustChoiceFields(fieldName, v.Choices, v.Elements)
 		gen.StructAST[v.Name] = content
-		fieldName := genRustStructName(v.Name)
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genRustChoices(fieldName, v.Choices, v.Elements)
 	}
 	return
 }
@@ -251,30 +239,77 @@ func (gen *CodeGenerator) RustGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var content string
 		for _, element := range v.Elements {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
-			fieldName := genRustFieldName(element.Name)
+			if element.Choice != "" {
+				continue
+			}
+			fieldType := gen.genRustFieldType(gen.getBaseType(element.Type))
+			fieldName := gen.fieldName(genRustFieldName(element.Name))
+			if !v.Plural && gen.isRecursiveType(element.Type, v.Name) {
+				fieldType = fmt.Sprintf("Box<%s>", fieldType)
+			}
 			if v.Plural {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", element.Name, fieldName, fieldType)
+			} else if element.Nillable {
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", element.Name, fieldName, fieldType)
 			} else {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", element.Name, fieldName, fieldType)
 			}
 		}
 		for _, group := range v.Groups {
-			fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
-			fieldName := genRustFieldName(group.Name)
+			fieldType := gen.genRustFieldType(gen.getBaseType(group.Ref))
+			fieldName := gen.fieldName(genRustFieldName(group.Name))
+			if !v.Plural && gen.isRecursiveType(group.Ref, v.Name) {
+				fieldType = fmt.Sprintf("Box<%s>", fieldType)
+			}
 			if v.Plural {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", group.Name, fieldName, fieldType)
 			} else {
 				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", group.Name, fieldName, fieldType)
 			}
 		}
+		fieldName := gen.typeName(genRustStructName(v.Name))
+		content += gen.genRustChoiceFields(fieldName, v.Choices, v.Elements)
 		gen.StructAST[v.Name] = content
-		fieldName := genRustStructName(v.Name)
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genRustChoices(fieldName, v.Choices, v.Elements)
 	}
 	return
 }
 
+// genRustChoiceFields generates the fields of the choices in the struct by
+// given struct name, the field is a vector of the alternatives if the choice
+// or any alternative is repeatable.
+func (gen *CodeGenerator) genRustChoiceFields(structName string, choices []Choice, elements []Element) (content string) {
+	for _, choice := range choices {
+		fieldType, plural := structName+choice.Name, choice.Plural
+		for _, element := range choiceAlternatives(choice, elements) {
+			plural = plural || element.Plural
+		}
+		switch {
+		case plural:
+			fieldType = fmt.Sprintf("Vec<%s>", fieldType)
+		case choice.Optional:
+			fieldType = fmt.Sprintf("Option<%s>", fieldType)
+		}
+		content += fmt.Sprintf("\t#[serde(rename = \"$value\")]\n\tpub %s: %s,\n", gen.fieldName(genRustFieldName(choice.Name)), fieldType)
+	}
+	return
+}
+
+// genRustChoices generates the enums of the choices in the struct by given
+// struct name, the variants are the alternatives.
+func (gen *CodeGenerator) genRustChoices(structName string, choices []Choice, elements []Element) {
+	for _, choice := range choices {
+		var names []string
+		var variants string
+		for _, element := range choiceAlternatives(choice, elements) {
+			names = append(names, element.Name)
+			variants += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\t%s(%s),\n", element.Name, genRustStructName(element.Name), gen.genRustFieldType(gen.getBaseType(element.Type)))
+		}
+		gen.Field += fmt.Sprintf("\n// %s%s is one of the %s elements in %s.\n#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub enum %s%s {\n%s}\n", structName, choice.Name, strings.Join(names, ", "), structName, structName, choice.Name, variants)
+	}
+}
+
 // RustAttributeGroup generates code for attribute group XML schema in Rust language
 // syntax.
 func (gen *CodeGenerator) RustAttributeGroup(v *AttributeGroup) {
@@ -282,14 +317,14 @@ func (gen *CodeGenerator) RustAttributeGroup(v *AttributeGroup) {
 		var content string
 		for _, attribute := range v.Attributes {
 			if attribute.Optional {
-				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", attribute.Name, genRustFieldName(attribute.Name), genRustFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)))
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Option<%s>,\n", attribute.Name, gen.fieldName(genRustFieldName(attribute.Name)), gen.genRustFieldType(gen.getBaseType(attribute.Type)))
 			} else {
-				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", attribute.Name, genRustFieldName(attribute.Name), genRustFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)))
+				content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", attribute.Name, gen.fieldName(genRustFieldName(attribute.Name)), gen.genRustFieldType(gen.getBaseType(attribute.Type)))
 			}
 		}
 		gen.StructAST[v.Name] = content
-		fieldName := genRustStructName(v.Name)
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genRustStructName(v.Name))
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -297,29 +332,54 @@ func (gen *CodeGenerator) RustAttributeGroup(v *AttributeGroup) {
 // RustElement generates code for element XML schema in Rust language syntax.
 func (gen *CodeGenerator) RustElement(v *Element) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
-		fieldName := genRustFieldName(v.Name)
+		fieldType := gen.genRustFieldType(gen.getBaseType(v.Type))
+		fieldName := gen.typeName(genRustFieldName(v.Name))
 		if v.Plural {
 			gen.StructAST[v.Name] = fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", v.Name, fieldName, fieldType)
 		} else {
 			gen.StructAST[v.Name] = fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, fieldName, fieldType)
 		}
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genRustSubstitutionGroup(v)
 	}
 	return
 }
 
+// genRustSubstitutionGroup generates the enum of the substitution group
+// headed by the element, the variants are the head element and the elements
+// in its substitution group.
+func (gen *CodeGenerator) genRustSubstitutionGroup(v *Element) {
+	members := gen.substitutionMembers(v.Name)
+	if len(members) == 0 {
+		return
+	}
+	var names []string
+	var variants string
+	for i, element := range append([]*Element{v}, members...) {
+		fieldType := gen.genRustFieldType(gen.getBaseType(element.Type))
+		if element.Plural {
+			fieldType = fmt.Sprintf("Vec<%s>", fieldType)
+		}
+		if i > 0 {
+			names = append(names, element.Name)
+		}
+		variants += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\t%s(%s),\n", element.Name, genRustStructName(element.Name), fieldType)
+	}
+	groupName := gen.typeName(genRustStructName(v.Name)) + "Group"
+	gen.Field += fmt.Sprintf("\n// %s is the %s element or one of the elements in its substitution\n// group: %s.\n#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub enum %s {\n%s}\n", groupName, v.Name, strings.Join(names, ", "), groupName, variants)
+}
+
 // RustAttribute generates code for attribute XML schema in Rust language syntax.
 func (gen *CodeGenerator) RustAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		fieldType := genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
-		fieldName := genRustFieldName(v.Name)
+		fieldType := gen.genRustFieldType(gen.getBaseType(v.Type))
+		fieldName := gen.typeName(genRustFieldName(v.Name))
 		if v.Plural {
 			gen.StructAST[v.Name] = fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: Vec<%s>,\n", v.Name, fieldName, fieldType)
 		} else {
 			gen.StructAST[v.Name] = fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", v.Name, fieldName, fieldType)
 		}
-		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
 	}
 	return
 }
@@ -0,0 +1,326 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var sqlBuildInType = map[string]bool{
+	"BIGINT":                   true,
+	"BOOLEAN":                  true,
+	"BYTEA":                    true,
+	"DATE":                     true,
+	"DOUBLE PRECISION":         true,
+	"INTEGER":                  true,
+	"INTERVAL":                 true,
+	"NUMERIC":                  true,
+	"REAL":                     true,
+	"SMALLINT":                 true,
+	"TEXT":                     true,
+	"TEXT[]":                   true,
+	"TIME":                     true,
+	"TIMESTAMP WITH TIME ZONE": true,
+	"XML":                      true,
+}
+
+// sqlKey defines the name of the surrogate primary key column of the tables.
+const sqlKey = "id"
+
+// GenSQL generate SQL data definition language (DDL) in the PostgreSQL
+// dialect for XML schema definition files. The complex types are flattened
+// into tables with the surrogate primary keys, the attributes and elements of
+// the simple types are generated as the columns, and the groups and attribute
+// groups are inlined. The nested complex elements are referred by the foreign
+// keys, and the plural ones are associated by the join tables. The
+// enumerations are generated as enum types, and the other simple types are
+// replaced by their base types.
+func (gen *CodeGenerator) GenSQL() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("SQL%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	var constraints string
+	if gen.Constraints != "" {
+		constraints = "\n" + gen.Constraints
+	}
+	source := []byte(fmt.Sprintf("-- %s\n%s%s%s", strings.TrimPrefix(copyright, "// "), strings.Replace(gen.Forwards, "\r\n", "\n", -1), strings.Replace(gen.Field, "\r\n", "\n", -1), constraints))
+	return gen.writeFile(gen.File+".sql", source)
+}
+
+func genSQLFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	return ConvertCase(tmp, SnakeCase)
+}
+
+// sqlQuote returns the string literal of SQL for the given value.
+func sqlQuote(value string) string {
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// genSQLComment generates the comment of the column or join table by given
+// documentation and deprecation message.
+func genSQLComment(doc, deprecated, indent string) (comment string) {
+	if doc = strings.TrimSpace(doc); doc != "" {
+		comment = indent + "-- " + strings.Replace(strings.Replace(doc, "\t", "", -1), "\n", "\n"+indent+"-- ", -1) + "\n"
+	}
+	if deprecated != "" {
+		comment += fmt.Sprintf("%s-- Deprecated: %s\n", indent, strings.Replace(deprecated, "\n", " ", -1))
+	}
+	return
+}
+
+// sqlTable holds the columns of the table in generating, the names of the
+// columns are recorded without escaping.
+type sqlTable struct {
+	name    string
+	columns []string
+	joins   string
+	names   map[string]bool
+	groups  map[string]bool
+}
+
+// columnName returns the unique name of the column by given name, the
+// smallest index making the name unique in the table is appended to the name
+// of the column which collides with another, e.g. the attribute named id and
+// the primary key.
+func (t *sqlTable) columnName(name string) string {
+	if t.names == nil {
+		t.names = map[string]bool{sqlKey: true}
+	}
+	unique := name
+	for i := 1; t.names[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	t.names[unique] = true
+	return unique
+}
+
+// genSQLColumnType returns the column type for PostgreSQL by given type
+// name, the enumerations are referred by the enum types, the lists are the
+// arrays of the items and the unions are the texts in the lexical form. The
+// other restrictions are replaced by their base types. It returns an empty
+// string if the type is a complex type, which is referred by the foreign key.
+func (gen *CodeGenerator) genSQLColumnType(name string) string {
+	if v := gen.getSimpleType(trimNSPrefix(name)); v != nil {
+		switch {
+		case v.List:
+			if itemType := gen.genSQLColumnType(v.Base); itemType != "" && !strings.HasSuffix(itemType, "[]") {
+				return itemType + "[]"
+			}
+			return "TEXT[]"
+		case v.Union:
+			return "TEXT"
+		case len(v.Restriction.Enum) > 0:
+			return gen.typeName(genSQLFieldName(v.Name))
+		}
+	}
+	name = gen.getBaseType(name)
+	if _, ok := sqlBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	if genSQLFieldName(name) == "" {
+		return "XML"
+	}
+	return ""
+}
+
+// addColumn provides a function to add the column by given XML name and
+// type. The required columns are not null, and the plural columns of the
+// simple types are the arrays. The complex types are referred by the foreign
+// keys, and the plural ones are associated by the join tables with the
+// positions of the items.
+func (t *sqlTable) addColumn(gen *CodeGenerator, name, doc, typeName, deprecated string, plural, optional bool) {
+	notNull := " NOT NULL"
+	if optional {
+		notNull = ""
+	}
+	columnType := gen.genSQLColumnType(typeName)
+	if columnType != "" {
+		if plural && !strings.HasSuffix(columnType, "[]") {
+			columnType += "[]"
+		}
+		t.columns = append(t.columns, fmt.Sprintf("%s  %s %s%s", genSQLComment(doc, deprecated, "  "), gen.fieldName(t.columnName(genSQLFieldName(name))), columnType, notNull))
+		return
+	}
+	table := gen.typeName(genSQLFieldName(typeName))
+	if !plural {
+		column := t.columnName(genSQLFieldName(name) + "_" + sqlKey)
+		t.columns = append(t.columns, fmt.Sprintf("%s  %s BIGINT%s", genSQLComment(doc, deprecated, "  "), gen.fieldName(column), notNull))
+		gen.Constraints += fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (%s);\n", gen.typeName(t.name), gen.fieldName(column), table, sqlKey)
+		return
+	}
+	joinTable := gen.typeName(t.name + "_" + t.columnName(genSQLFieldName(name)))
+	parentKey, childKey := gen.fieldName(t.name+"_"+sqlKey), gen.fieldName(genSQLFieldName(typeName)+"_"+sqlKey)
+	if t.name == genSQLFieldName(typeName) {
+		childKey = gen.fieldName("child_" + sqlKey)
+	}
+	t.joins += fmt.Sprintf("\n%sCREATE TABLE %s (\n  %s BIGINT NOT NULL,\n  position INTEGER NOT NULL,\n  %s BIGINT NOT NULL,\n  PRIMARY KEY (%s, position)\n);\n", genSQLComment(doc, deprecated, ""), joinTable, parentKey, childKey, parentKey)
+	gen.Constraints += fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE CASCADE;\n", joinTable, parentKey, gen.typeName(t.name), sqlKey)
+	gen.Constraints += fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (%s);\n", joinTable, childKey, table, sqlKey)
+}
+
+// addAttributeGroup inlines the attributes of the attribute group by given
+// name into the table, or refers it by the foreign key if it's undeclared.
+func (t *sqlTable) addAttributeGroup(gen *CodeGenerator, attrGroup AttributeGroup) {
+	name := trimNSPrefix(attrGroup.Ref)
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*AttributeGroup); ok && v.Name == name {
+			if t.groups[name] {
+				return
+			}
+			t.groups[name] = true
+			for _, attribute := range v.Attributes {
+				t.addColumn(gen, attribute.Name, attribute.Doc, attribute.Type, attribute.Deprecated, attribute.Plural, attribute.Optional)
+			}
+			return
+		}
+	}
+	t.addColumn(gen, attrGroup.Name, "", attrGroup.Ref, "", false, false)
+}
+
+// addGroup inlines the elements of the group by given name into the table,
+// the plural groups are stored as the XML fragments since the content of
+// them can't be flattened into the columns.
+func (t *sqlTable) addGroup(gen *CodeGenerator, group Group) {
+	name := trimNSPrefix(group.Ref)
+	if group.Plural {
+		t.columns = append(t.columns, fmt.Sprintf("  %s XML NOT NULL", gen.fieldName(t.columnName(genSQLFieldName(group.Name)))))
+		return
+	}
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*Group); ok && v.Name == name {
+			if t.groups[name] {
+				return
+			}
+			t.groups[name] = true
+			t.addElements(gen, v.Elements, v.Groups)
+			return
+		}
+	}
+	t.addColumn(gen, group.Name, "", group.Ref, "", false, false)
+}
+
+// addElements adds the columns of the elements and groups into the table.
+func (t *sqlTable) addElements(gen *CodeGenerator, elements []Element, groups []Group) {
+	for _, element := range elements {
+		t.addColumn(gen, element.Name, element.Doc, element.Type, element.Deprecated, element.Plural, element.Optional)
+	}
+	for _, group := range groups {
+		t.addGroup(gen, group)
+	}
+}
+
+// SQLSimpleType generates code for simple type XML schema in SQL DDL. The
+// enumerations are generated as enum types with the XML values.
+func (gen *CodeGenerator) SQLSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List || v.Union || len(v.Restriction.Enum) == 0 {
+		gen.StructAST[v.Name] = gen.genSQLColumnType(v.Name)
+		return
+	}
+	var values []string
+	seen := map[string]bool{}
+	for _, enum := range v.Restriction.Enum {
+		if seen[enum] {
+			continue
+		}
+		seen[enum] = true
+		values = append(values, "  "+sqlQuote(enum))
+	}
+	content := strings.Join(values, ",\n")
+	gen.StructAST[v.Name] = content
+	fieldName := gen.typeName(genSQLFieldName(v.Name))
+	gen.Forwards += fmt.Sprintf("%s%sCREATE TYPE %s AS ENUM (\n%s\n);\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "--"), gen.genDeprecated(v.Deprecated), fieldName, content)
+	return
+}
+
+// SQLComplexType generates code for complex type XML schema in SQL DDL.
+func (gen *CodeGenerator) SQLComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	table := &sqlTable{name: genSQLFieldName(v.Name), groups: map[string]bool{}}
+	gen.StructAST[v.Name] = table.name
+	var fields string
+	for _, attrGroup := range v.AttributeGroup {
+		table.addAttributeGroup(gen, attrGroup)
+	}
+	for _, attribute := range v.Attributes {
+		table.addColumn(gen, attribute.Name, attribute.Doc, attribute.Type, attribute.Deprecated, attribute.Plural, attribute.Optional)
+	}
+	table.addElements(gen, v.Elements, v.Groups)
+	for _, column := range table.columns {
+		fields += ",\n" + column
+	}
+	fieldName := gen.typeName(table.name)
+	gen.Field += fmt.Sprintf("%s%sCREATE TABLE %s (\n  %s BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY%s\n);\n%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "--"), gen.genDeprecated(v.Deprecated), fieldName, sqlKey, fields, table.joins)
+	return
+}
+
+// SQLGroup generates code for group XML schema in SQL DDL, the group is
+// inlined into the tables which refer it.
+func (gen *CodeGenerator) SQLGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	gen.StructAST[v.Name] = genSQLFieldName(v.Name)
+	return
+}
+
+// SQLAttributeGroup generates code for attribute group XML schema in SQL
+// DDL, the attribute group is inlined into the tables which refer it.
+func (gen *CodeGenerator) SQLAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	gen.StructAST[v.Name] = genSQLFieldName(v.Name)
+	return
+}
+
+// SQLElement generates code for element XML schema in SQL DDL, the element
+// is stored in the table of its type.
+func (gen *CodeGenerator) SQLElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name] = gen.genSQLColumnType(v.Type)
+	}
+	return
+}
+
+// SQLAttribute generates code for attribute XML schema in SQL DDL, the
+// attribute is stored in the columns of its type.
+func (gen *CodeGenerator) SQLAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if trimNSPrefix(v.Type) != v.Name {
+		gen.StructAST[v.Name] = gen.genSQLColumnType(v.Type)
+	}
+	return
+}
@@ -0,0 +1,310 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var swiftBuildInType = map[string]bool{
+	"[String]": true,
+	"Bool":     true,
+	"Data":     true,
+	"Date":     true,
+	"Decimal":  true,
+	"Double":   true,
+	"Float":    true,
+	"Int":      true,
+	"Int16":    true,
+	"Int32":    true,
+	"Int64":    true,
+	"Int8":     true,
+	"String":   true,
+	"UInt16":   true,
+	"UInt32":   true,
+	"UInt64":   true,
+	"UInt8":    true,
+	"URL":      true,
+}
+
+// swiftProperty holds the property of the generated Swift struct and the XML
+// name it's coded with.
+type swiftProperty struct {
+	name, xmlName, fieldType string
+	attribute                bool
+}
+
+// GenSwift generate Swift programming language source code for XML schema
+// definition files. The types are generated as Codable structs with the
+// coding keys mapped to the XML names, and the attributes are coded by the
+// dynamic node encoding of XMLCoder.
+func (gen *CodeGenerator) GenSwift() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Swift%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "import Foundation\nimport XMLCoder\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import %s\n", mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("%s\n\n%s%s", copyright, importPackage, gen.Field))
+	return gen.writeFile(gen.File+".swift", source)
+}
+
+// swiftQuote returns the Swift string literal of the given value.
+func swiftQuote(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\\', '"':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func genSwiftFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genSwiftPropertyName generates the camel case property name for Swift
+// code.
+func (gen *CodeGenerator) genSwiftPropertyName(name string) string {
+	return gen.fieldName(ConvertCase(genSwiftFieldName(name), CamelCase))
+}
+
+// genSwiftFieldType generates the type for Swift code, the plural type is
+// generated as an array and the optional type is generated as an optional.
+func (gen *CodeGenerator) genSwiftFieldType(name string, plural, optional bool) (fieldType string) {
+	if _, ok := swiftBuildInType[name]; ok {
+		fieldType = name
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+	} else if fieldType = genSwiftFieldName(name); fieldType == "" {
+		fieldType = "String"
+	} else {
+		fieldType = gen.typeName(fieldType)
+	}
+	if plural {
+		fieldType = fmt.Sprintf("[%s]", fieldType)
+	}
+	if optional {
+		fieldType += "?"
+	}
+	return
+}
+
+// genSwiftElement generates the property for the element.
+func (gen *CodeGenerator) genSwiftElement(element Element) swiftProperty {
+	return swiftProperty{
+		name:      gen.genSwiftPropertyName(element.Name),
+		xmlName:   element.Name,
+		fieldType: gen.genSwiftFieldType(gen.getBaseType(element.Type), element.Plural, element.Optional),
+	}
+}
+
+// genSwiftAttribute generates the property for the attribute.
+func (gen *CodeGenerator) genSwiftAttribute(attribute Attribute) swiftProperty {
+	return swiftProperty{
+		name:      gen.genSwiftPropertyName(attribute.Name),
+		xmlName:   attribute.Name,
+		fieldType: gen.genSwiftFieldType(gen.getBaseType(attribute.Type), attribute.Plural, attribute.Optional),
+		attribute: true,
+	}
+}
+
+// genSwiftStruct generates the Codable struct by given declaration and
+// properties. The coding keys are generated for the properties with the XML
+// names, and the struct conforms to the dynamic node encoding if any of the
+// properties is an attribute.
+func (gen *CodeGenerator) genSwiftStruct(name, doc, source, location, deprecated string, properties []swiftProperty) {
+	fieldName := gen.typeName(genSwiftFieldName(name))
+	var content, keys, attributes string
+	for _, property := range properties {
+		content += fmt.Sprintf("\tpublic var %s: %s\n", property.name, property.fieldType)
+		if property.xmlName == "" {
+			keys += fmt.Sprintf("\t\tcase %s\n", property.name)
+			continue
+		}
+		keys += fmt.Sprintf("\t\tcase %s = %s\n", property.name, swiftQuote(property.xmlName))
+		if property.attribute {
+			attributes += fmt.Sprintf("\t\tcase CodingKeys.%s:\n\t\t\treturn .attribute\n", property.name)
+		}
+	}
+	if keys != "" {
+		content += fmt.Sprintf("\n\tenum CodingKeys: String, CodingKey {\n%s\t}\n", keys)
+	}
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%spublic struct %s: Codable {\n%s}\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, content)
+	if attributes != "" {
+		gen.Field += fmt.Sprintf("\nextension %s: DynamicNodeEncoding {\n\tpublic static func nodeEncoding(for key: CodingKey) -> XMLEncoder.NodeEncoding {\n\t\tswitch key {\n%s\t\tdefault:\n\t\t\treturn .element\n\t\t}\n\t}\n}\n", fieldName, attributes)
+	}
+}
+
+// genSwiftAlias generates the type alias by given declaration and type.
+func (gen *CodeGenerator) genSwiftAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genSwiftFieldName(name))
+	gen.Field += fmt.Sprintf("%spublic typealias %s = %s\n", genFieldComment(fieldName, doc, source, location, "//")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// SwiftSimpleType generates code for simple type XML schema in Swift language
+// syntax.
+func (gen *CodeGenerator) SwiftSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genSwiftAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genSwiftFieldType(gen.getBaseType(v.Base), true, false))
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var properties []swiftProperty
+		for _, member := range v.MemberTypes {
+			properties = append(properties, swiftProperty{
+				name:      gen.genSwiftPropertyName(member.Name),
+				fieldType: gen.genSwiftFieldType(gen.getBaseType(member.Type), false, true),
+			})
+		}
+		gen.genSwiftStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := ConvertCase(enum, CamelCase)
+			if member == "" || (member[0] >= '0' && member[0] <= '9') {
+				member = "value" + ConvertCase(enum, PascalCase)
+			}
+			member = gen.constantName(member)
+			if members[member] {
+				continue
+			}
+			members[member] = true
+			content += fmt.Sprintf("\tcase %s = %s\n", member, swiftQuote(enum))
+		}
+		gen.StructAST[v.Name] = content
+		fieldName := gen.typeName(genSwiftFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%spublic enum %s: String, Codable {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+		return
+	}
+	gen.genSwiftAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genSwiftFieldType(gen.getBaseType(v.Base), false, false))
+	return
+}
+
+// SwiftComplexType generates code for complex type XML schema in Swift
+// language syntax.
+func (gen *CodeGenerator) SwiftComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []swiftProperty
+	for _, attrGroup := range v.AttributeGroup {
+		properties = append(properties, swiftProperty{
+			name:      gen.genSwiftPropertyName(attrGroup.Name),
+			fieldType: gen.genSwiftFieldType(gen.getBaseType(attrGroup.Ref), false, true),
+		})
+	}
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genSwiftAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, swiftProperty{
+			name:      gen.genSwiftPropertyName(group.Name),
+			fieldType: gen.genSwiftFieldType(gen.getBaseType(group.Ref), group.Plural, true),
+		})
+	}
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genSwiftElement(element))
+	}
+	gen.genSwiftStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// SwiftGroup generates code for group XML schema in Swift language syntax.
+func (gen *CodeGenerator) SwiftGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []swiftProperty
+	for _, element := range v.Elements {
+		properties = append(properties, gen.genSwiftElement(element))
+	}
+	for _, group := range v.Groups {
+		properties = append(properties, swiftProperty{
+			name:      gen.genSwiftPropertyName(group.Name),
+			fieldType: gen.genSwiftFieldType(gen.getBaseType(group.Ref), group.Plural, true),
+		})
+	}
+	gen.genSwiftStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// SwiftAttributeGroup generates code for attribute group XML schema in Swift
+// language syntax.
+func (gen *CodeGenerator) SwiftAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var properties []swiftProperty
+	for _, attribute := range v.Attributes {
+		properties = append(properties, gen.genSwiftAttribute(attribute))
+	}
+	gen.genSwiftStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, properties)
+	return
+}
+
+// SwiftElement generates code for element XML schema in Swift language
+// syntax.
+func (gen *CodeGenerator) SwiftElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genSwiftAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genSwiftFieldType(gen.getBaseType(v.Type), v.Plural, false))
+	}
+	return
+}
+
+// SwiftAttribute generates code for attribute XML schema in Swift language
+// syntax.
+func (gen *CodeGenerator) SwiftAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; !ok {
+		gen.genSwiftAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genSwiftFieldType(gen.getBaseType(v.Type), v.Plural, false))
+	}
+	return
+}
@@ -10,8 +10,9 @@ package xgen
 
 import (
 	"fmt"
-	"os"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -29,21 +30,22 @@ var typeScriptBuildInType = map[string]bool{
 // schema definition files.
 func (gen *CodeGenerator) GenTypeScript() error {
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
 			continue
 		}
 		funcName := fmt.Sprintf("TypeScript%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration(ele, funcName)
 	}
-	f, err := os.Create(gen.File + ".ts")
-	if err != nil {
-		return err
+	gen.genPlaceholders()
+	var importPackage string
+	if gen.Zod {
+		importPackage += "import { z } from 'zod';\n"
 	}
-	defer f.Close()
-	source := []byte(fmt.Sprintf("%s\n%s", copyright, gen.Field))
-	f.Write(source)
-	return err
-
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("import { %s } from '%s';\n", mapping.Type, mapping.Import)
+	}
+	source := []byte(fmt.Sprintf("%s\n%s%s%s", copyright, importPackage, gen.Field, gen.Implementation))
+	return gen.writeFile(gen.File+".ts", source)
 }
 
 func genTypeScriptFieldName(name string) (fieldName string) {
@@ -59,9 +61,19 @@ func genTypeScriptFieldName(name string) (fieldName string) {
 	return
 }
 
-func genTypeScriptFieldType(name string, plural bool) (fieldType string) {
+func (gen *CodeGenerator) genTypeScriptFieldType(name string, plural bool) (fieldType string) {
 	if _, ok := typeScriptBuildInType[name]; ok {
 		fieldType = name
+		if plural {
+			fieldType = fmt.Sprintf("Array<%s>", fieldType)
+		}
+		return
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+		if plural {
+			fieldType = fmt.Sprintf("Array<%s>", fieldType)
+		}
 		return
 	}
 	for _, str := range strings.Split(name, ".") {
@@ -70,6 +82,8 @@ func genTypeScriptFieldType(name string, plural bool) (fieldType string) {
 	fieldType = MakeFirstUpperCase(strings.Replace(fieldType, "-", "", -1))
 	if fieldType == "" || fieldType == "Any" {
 		fieldType = "any"
+	} else {
+		fieldType = gen.typeName(fieldType)
 	}
 	if plural {
 		fieldType = fmt.Sprintf("Array<%s>", fieldType)
@@ -82,52 +96,55 @@ func genTypeScriptFieldType(name string, plural bool) (fieldType string) {
 func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), true)
+			fieldType := gen.genTypeScriptFieldType(gen.getBaseType(v.Base), true)
 			content := fmt.Sprintf(" = %s;\n", fieldType)
 			gen.StructAST[v.Name] = content
-			fieldName := genTypeScriptFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%sexport type %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%sexport type %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+			gen.Field += gen.genTypeScriptListFunctions(fieldName, gen.genTypeScriptFieldType(gen.getBaseType(v.Base), false))
+			gen.genZodSchema(fieldName, fmt.Sprintf("z.array(%s)", gen.genZodType(v.Base, false, v.Restriction)), false)
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			content := " {\n"
-			for memberName, memberType := range v.MemberTypes {
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
-				}
-				content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(memberName), genTypeScriptFieldType(memberType, false))
+			content, object := " {\n", &zodObject{}
+			for _, member := range v.MemberTypes {
+				content += fmt.Sprintf("\t%s: %s;\n", gen.fieldName(genTypeScriptFieldName(member.Name)), gen.genTypeScriptFieldType(member.Type, false))
+				object.addField(gen.fieldName(genTypeScriptFieldName(member.Name)), gen.genZodType(member.Type, false, Restriction{}), "")
 			}
 			content += "}\n"
 			gen.StructAST[v.Name] = content
-			fieldName := genTypeScriptFieldName(v.Name)
-			gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+			gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+			gen.genZodSchema(fieldName, object.String(), true)
 		}
 		return
 	}
 	if len(v.Restriction.Enum) > 0 {
 		var content string
-		baseType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
+		baseType := gen.genTypeScriptFieldType(gen.getBaseType(v.Base), false)
 		for _, enum := range v.Restriction.Enum {
 			switch baseType {
 			case "string":
-				content += fmt.Sprintf("\t%s = '%s',\n", enum, enum)
+				content += fmt.Sprintf("\t%s = '%s',\n", gen.constantName(enum), enum)
 			case "number":
-				content += fmt.Sprintf("\tEnum%s = %s,\n", enum, enum)
+				content += fmt.Sprintf("\t%s = %s,\n", gen.constantName("Enum"+enum), enum)
 			default:
-				content += fmt.Sprintf("\tEnum%s = '%s',\n", enum, enum)
+				content += fmt.Sprintf("\t%s = '%s',\n", gen.constantName("Enum"+enum), enum)
 			}
 		}
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport enum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, content)
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%sexport enum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, content)
+		gen.genZodSchema(fieldName, fmt.Sprintf("z.nativeEnum(%s)", fieldName), false)
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false))
+		content := fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(gen.getBaseType(v.Base), false))
 		gen.StructAST[v.Name] = content
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genZodSchema(fieldName, gen.genZodType(v.Base, false, v.Restriction), false)
 	}
 	return
 }
@@ -136,32 +153,65 @@ func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 // syntax.
 func (gen *CodeGenerator) TypeScriptComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := " {\n"
+		content, object := " {\n", &zodObject{}
+		if v.Namespace != "" {
+			content += fmt.Sprintf("\tstatic readonly Namespace = %q;\n", v.Namespace)
+		}
+		if gen.hasSimpleContent(v) {
+			content += fmt.Sprintf("\tValue: %s;\n", gen.genTypeScriptFieldType(gen.getBaseType(v.Base), false))
+			object.addField("Value", gen.genZodType(v.Base, false, Restriction{}), "")
+		} else if v.Mixed {
+			content += "\tValue: string;\n"
+			object.addField("Value", gen.genZodType("string", false, Restriction{}), "")
+		}
 		for _, attrGroup := range v.AttributeGroup {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(attrGroup.Name), genTypeScriptFieldType(fieldType, false))
+			fieldType := gen.getBaseType(attrGroup.Ref)
+			content += fmt.Sprintf("\t%s: %s;\n", gen.fieldName(genTypeScriptFieldName(attrGroup.Name)), gen.genTypeScriptFieldType(fieldType, false))
+			object.addField(gen.fieldName(genTypeScriptFieldName(attrGroup.Name)), gen.genZodType(attrGroup.Ref, false, Restriction{}), "")
 		}
 
 		for _, attribute := range v.Attributes {
-			var optional string
+			var optional, zodOptional, initializer string
 			if attribute.Optional {
-				optional = ` | null`
+				optional, zodOptional = ` | null`, ".nullable()"
 			}
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural)
-			content += fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name), fieldType, optional)
+			if literal, ok := gen.genTypeScriptDefault(attribute.Type, attribute.Default, attribute.Fixed); ok && !attribute.Plural {
+				initializer, zodOptional = " = "+literal, zodOptional+fmt.Sprintf(".default(%s)", literal)
+			}
+			fieldType := gen.genTypeScriptFieldType(gen.getBaseType(attribute.Type), attribute.Plural)
+			content += fmt.Sprintf("\t%s: %s%s%s;\n", gen.fieldName(genTypeScriptFieldName(attribute.Name)+"Attr"), fieldType, optional, initializer)
+			object.addField(gen.fieldName(genTypeScriptFieldName(attribute.Name)+"Attr"), gen.genZodType(attribute.Type, attribute.Plural, attribute.Restriction), zodOptional)
 		}
 		for _, group := range v.Groups {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(group.Name), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
+			content += fmt.Sprintf("\t%s: %s;\n", gen.fieldName(genTypeScriptFieldName(group.Name)), gen.genTypeScriptFieldType(gen.getBaseType(group.Ref), group.Plural))
+			object.addField(gen.fieldName(genTypeScriptFieldName(group.Name)), gen.genZodType(group.Ref, group.Plural, Restriction{}), "")
 		}
 
 		for _, element := range v.Elements {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural)
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(element.Name), fieldType)
+			if element.Choice != "" {
+				continue
+			}
+			fieldType := gen.genTypeScriptFieldType(gen.getBaseType(element.Type), element.Plural)
+			if element.Nillable {
+				fieldType += " | null"
+			}
+			content += fmt.Sprintf("\t%s: %s%s;\n", gen.fieldName(genTypeScriptFieldName(element.Name)), fieldType, gen.genTypeScriptInitializer(element))
+			object.addElement(gen, element)
 		}
-		content += "}\n"
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		fields, unions := gen.genTypeScriptChoices(fieldName, v.Choices, v.Elements, object)
+		content += fields + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		var extends string
+		schema := object.String()
+		if base := gen.complexBase(v); base != "" {
+			// the schema of the derived type is the intersection with the
+			// schema of the base type.
+			extends = " extends " + gen.genTypeScriptFieldType(gen.getBaseType(base), false)
+			schema = fmt.Sprintf("%s.and(%s)", gen.genZodType(base, false, Restriction{}), schema)
+		}
+		gen.Field += fmt.Sprintf("%sexport class %s%s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, extends, gen.StructAST[v.Name]) + unions
+		gen.genZodSchema(fieldName, schema, true)
 	}
 	return
 }
@@ -169,19 +219,61 @@ func (gen *CodeGenerator) TypeScriptComplexType(v *ComplexType) {
 // TypeScriptGroup generates code for group XML schema in TypeScript language syntax.
 func (gen *CodeGenerator) TypeScriptGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := " {\n"
+		content, object := " {\n", &zodObject{}
 		for _, element := range v.Elements {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(element.Name), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural))
+			if element.Choice != "" {
+				continue
+			}
+			fieldType := gen.genTypeScriptFieldType(gen.getBaseType(element.Type), element.Plural)
+			if element.Nillable {
+				fieldType += " | null"
+			}
+			content += fmt.Sprintf("\t%s: %s%s;\n", gen.fieldName(genTypeScriptFieldName(element.Name)), fieldType, gen.genTypeScriptInitializer(element))
+			object.addElement(gen, element)
 		}
 
 		for _, group := range v.Groups {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(group.Name), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
+			content += fmt.Sprintf("\t%s: %s;\n", gen.fieldName(genTypeScriptFieldName(group.Name)), gen.genTypeScriptFieldType(gen.getBaseType(group.Ref), group.Plural))
+			object.addField(gen.fieldName(genTypeScriptFieldName(group.Name)), gen.genZodType(group.Ref, group.Plural, Restriction{}), "")
 		}
 
-		content += "}\n"
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		fields, unions := gen.genTypeScriptChoices(fieldName, v.Choices, v.Elements, object)
+		content += fields + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name]) + unions
+		gen.genZodSchema(fieldName, object.String(), true)
+	}
+	return
+}
+
+// genTypeScriptChoices generates the fields of the choices in the class by
+// given type name and the tagged unions of the alternatives, which are tagged
+// by the names of the elements in the kind. The field is an array of the
+// alternatives if the choice or any alternative is repeatable, and the zod
+// schemas of the unions are generated before the schema of the class.
+func (gen *CodeGenerator) genTypeScriptChoices(typeName string, choices []Choice, elements []Element, object *zodObject) (fields, unions string) {
+	for _, choice := range choices {
+		choiceName := typeName + choice.Name
+		plural := choice.Plural
+		var names, variants, schemas []string
+		for _, element := range choiceAlternatives(choice, elements) {
+			names = append(names, element.Name)
+			variants = append(variants, fmt.Sprintf("\t| { kind: '%s'; value: %s }", element.Name, gen.genTypeScriptFieldType(gen.getBaseType(element.Type), false)))
+			schemas = append(schemas, fmt.Sprintf("\tz.object({ kind: z.literal(%s), value: %s }),\n", strconv.Quote(element.Name), gen.genZodType(element.Type, false, element.Restriction)))
+			plural = plural || element.Plural
+		}
+		unions += fmt.Sprintf("\n// %s is one of the %s elements in %s.\nexport type %s =\n%s;\n", choiceName, strings.Join(names, ", "), typeName, choiceName, strings.Join(variants, "\n"))
+		gen.genZodSchema(choiceName, fmt.Sprintf("z.discriminatedUnion(\"kind\", [\n%s])", strings.Join(schemas, "")), false)
+		fieldType, schema, modifier := choiceName, choiceName+"Schema", ""
+		switch {
+		case plural:
+			fieldType, schema = fmt.Sprintf("Array<%s>", fieldType), fmt.Sprintf("z.array(%s)", schema)
+		case choice.Optional:
+			fieldType, modifier = fieldType+" | null", ".nullable()"
+		}
+		fields += fmt.Sprintf("\t%s: %s;\n", gen.fieldName(genTypeScriptFieldName(choice.Name)), fieldType)
+		object.addField(gen.fieldName(genTypeScriptFieldName(choice.Name)), schema, modifier)
 	}
 	return
 }
@@ -190,18 +282,23 @@ func (gen *CodeGenerator) TypeScriptGroup(v *Group) {
 // syntax.
 func (gen *CodeGenerator) TypeScriptAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := " {\n"
+		content, object := " {\n", &zodObject{}
 		for _, attribute := range v.Attributes {
-			var optional string
+			var optional, zodOptional, initializer string
 			if attribute.Optional {
-				optional = ` | null`
+				optional, zodOptional = ` | null`, ".nullable()"
+			}
+			if literal, ok := gen.genTypeScriptDefault(attribute.Type, attribute.Default, attribute.Fixed); ok && !attribute.Plural {
+				initializer, zodOptional = " = "+literal, zodOptional+fmt.Sprintf(".default(%s)", literal)
 			}
-			content += fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural), optional)
+			content += fmt.Sprintf("\t%s: %s%s%s;\n", gen.fieldName(genTypeScriptFieldName(attribute.Name)+"Attr"), gen.genTypeScriptFieldType(gen.getBaseType(attribute.Type), attribute.Plural), optional, initializer)
+			object.addField(gen.fieldName(genTypeScriptFieldName(attribute.Name)+"Attr"), gen.genZodType(attribute.Type, attribute.Plural, attribute.Restriction), zodOptional)
 		}
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genZodSchema(fieldName, object.String(), true)
 	}
 	return
 }
@@ -209,9 +306,10 @@ func (gen *CodeGenerator) TypeScriptAttributeGroup(v *AttributeGroup) {
 // TypeScriptElement generates code for element XML schema in TypeScript language syntax.
 func (gen *CodeGenerator) TypeScriptElement(v *Element) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(gen.getBaseType(v.Type), v.Plural))
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genZodSchema(fieldName, gen.genZodType(v.Type, v.Plural, v.Restriction), false)
 	}
 	return
 }
@@ -219,9 +317,197 @@ func (gen *CodeGenerator) TypeScriptElement(v *Element) {
 // TypeScriptAttribute generates code for attribute XML schema in TypeScript language syntax.
 func (gen *CodeGenerator) TypeScriptAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
-		fieldName := genTypeScriptFieldName(v.Name)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(gen.getBaseType(v.Type), v.Plural))
+		fieldName := gen.typeName(genTypeScriptFieldName(v.Name))
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "//")+genAppinfo(v.Appinfo, "//")+gen.genDeprecated(v.Deprecated), fieldName, gen.StructAST[v.Name])
+		gen.genZodSchema(fieldName, gen.genZodType(v.Type, v.Plural, v.Restriction), false)
 	}
 	return
 }
+
+// genTypeScriptListFunctions generates the functions of the list type by given
+// type name and the type of the items, which parse and format the items as
+// the space-separated list in the character data or attribute value.
+func (gen *CodeGenerator) genTypeScriptListFunctions(typeName, itemType string) string {
+	var item string
+	switch itemType {
+	case "number":
+		item = "Number(item)"
+	case "boolean":
+		item = "item === 'true' || item === '1'"
+	case "string":
+		item = "item"
+	default:
+		item = fmt.Sprintf("item as %s", itemType)
+	}
+	return fmt.Sprintf(`
+// parse%[1]s parses the space-separated list into the %[1]s.
+export function parse%[1]s(value: string): %[1]s {
+	return value.split(/\s+/).filter((item) => item !== '').map((item) => %[2]s);
+}
+
+// format%[1]s formats the %[1]s as the space-separated list.
+export function format%[1]s(value: %[1]s): string {
+	return value.join(' ');
+}
+`, typeName, item)
+}
+
+// genZodType returns the zod schema of the type by given name, the facets in
+// the restriction are carried over as the refinements. The declared types
+// are referred lazily, since the schemas may be declared later or recursive.
+func (gen *CodeGenerator) genZodType(name string, plural bool, restriction Restriction) (schema string) {
+	baseType := gen.getBaseType(name)
+	switch baseType {
+	case "string", "number", "boolean":
+		schema = fmt.Sprintf("z.%s()", baseType)
+	case "Uint8Array":
+		schema = "z.instanceof(Uint8Array)"
+	case "void", "null", "undefined":
+		schema = fmt.Sprintf("z.%s()", baseType)
+	default:
+		if mappedType, ok := gen.getMappedType(baseType); ok {
+			schema = fmt.Sprintf("z.custom<%s>()", mappedType)
+			break
+		}
+		if fieldType := gen.genTypeScriptFieldType(baseType, false); fieldType != "any" {
+			schema = fmt.Sprintf("z.lazy(() => %sSchema)", fieldType)
+			break
+		}
+		schema = "z.any()"
+	}
+	if len(restriction.Enum) > 0 {
+		var values []string
+		for _, value := range restriction.Enum {
+			if baseType == "string" {
+				values = append(values, graphqlQuote(value))
+				continue
+			}
+			values = append(values, fmt.Sprintf("z.literal(%s)", value))
+		}
+		switch {
+		case baseType == "string":
+			schema = fmt.Sprintf("z.enum([%s])", strings.Join(values, ", "))
+		case len(values) == 1:
+			schema = values[0]
+		default:
+			schema = fmt.Sprintf("z.union([%s])", strings.Join(values, ", "))
+		}
+	}
+	if restriction.Pattern != nil && baseType == "string" {
+		// The patterns of XSD are implicitly anchored at both ends.
+		schema += fmt.Sprintf(".regex(new RegExp(%s))", graphqlQuote(fmt.Sprintf("^(?:%s)$", restriction.Pattern.String())))
+	}
+	if baseType == "string" || strings.HasPrefix(schema, "z.array(") {
+		if restriction.MinLength > 0 {
+			schema += fmt.Sprintf(".min(%d)", restriction.MinLength)
+		}
+		if restriction.MaxLength > 0 {
+			schema += fmt.Sprintf(".max(%d)", restriction.MaxLength)
+		}
+	}
+	if baseType == "number" {
+		if restriction.HasMin {
+			method := "gte"
+			if restriction.MinExclusive {
+				method = "gt"
+			}
+			schema += fmt.Sprintf(".%s(%s)", method, strconv.FormatFloat(restriction.Min, 'g', -1, 64))
+		}
+		if restriction.HasMax {
+			method := "lte"
+			if restriction.MaxExclusive {
+				method = "lt"
+			}
+			schema += fmt.Sprintf(".%s(%s)", method, strconv.FormatFloat(restriction.Max, 'g', -1, 64))
+		}
+	}
+	if plural {
+		schema = fmt.Sprintf("z.array(%s)", schema)
+	}
+	return
+}
+
+// zodObject holds the fields of the zod object schema in generating.
+type zodObject struct {
+	fields []string
+}
+
+// genTypeScriptDefault returns the literal of the default or fixed value by
+// given type name, returns false if the value can't be represented as a
+// literal of the type.
+func (gen *CodeGenerator) genTypeScriptDefault(typeName, defaultValue, fixed string) (string, bool) {
+	value, ok := valueConstraint(defaultValue, fixed)
+	if !ok {
+		return "", false
+	}
+	switch gen.getSimpleBaseType(typeName) {
+	case "string":
+		return graphqlQuote(value), true
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b), true
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// genTypeScriptInitializer returns the initializer of the field of the single
+// element with the default or fixed value.
+func (gen *CodeGenerator) genTypeScriptInitializer(element Element) string {
+	if literal, ok := gen.genTypeScriptDefault(element.Type, element.Default, element.Fixed); ok && !element.Plural {
+		return " = " + literal
+	}
+	return ""
+}
+
+// addField provides a function to add the field by given name and schema.
+func (o *zodObject) addField(name, schema, modifier string) {
+	o.fields = append(o.fields, fmt.Sprintf("\t%s: %s%s,\n", name, schema, modifier))
+}
+
+// addElement adds the field of the element, the number of the repeated
+// elements is bounded by the occurrences, the nillable elements are nullable
+// and the optional elements are optional in the schema.
+func (o *zodObject) addElement(gen *CodeGenerator, element Element) {
+	var modifier string
+	if element.Plural && element.MinOccurs > 0 {
+		modifier = fmt.Sprintf(".min(%d)", element.MinOccurs)
+	}
+	if element.Plural && element.MaxOccurs != Unbounded {
+		modifier += fmt.Sprintf(".max(%d)", element.MaxOccurs)
+	}
+	if element.Nillable {
+		modifier += ".nullable()"
+	}
+	if element.Optional {
+		modifier += ".optional()"
+	}
+	if literal, ok := gen.genTypeScriptDefault(element.Type, element.Default, element.Fixed); ok && !element.Plural {
+		modifier += fmt.Sprintf(".default(%s)", literal)
+	}
+	o.addField(gen.fieldName(genTypeScriptFieldName(element.Name)), gen.genZodType(element.Type, element.Plural, element.Restriction), modifier)
+}
+
+// String returns the zod object schema.
+func (o *zodObject) String() string {
+	return fmt.Sprintf("z.object({\n%s})", strings.Join(o.fields, ""))
+}
+
+// genZodSchema generates the zod schema by given type name if the zod schemas
+// are enabled. The object schemas are typed explicitly, since the types of
+// the recursive schemas can't be inferred.
+func (gen *CodeGenerator) genZodSchema(fieldName, schema string, object bool) {
+	if !gen.Zod {
+		return
+	}
+	var schemaType string
+	if object {
+		schemaType = ": z.ZodTypeAny"
+	}
+	gen.Implementation += fmt.Sprintf("\nexport const %sSchema%s = %s;\n", fieldName, schemaType, schema)
+}
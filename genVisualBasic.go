@@ -0,0 +1,291 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var visualBasicBuildInType = map[string]bool{
+	"Boolean":          true,
+	"Byte":             true,
+	"Byte()":           true,
+	"Date":             true,
+	"Decimal":          true,
+	"Double":           true,
+	"Integer":          true,
+	"List(Of String)":  true,
+	"Long":             true,
+	"Object":           true,
+	"SByte":            true,
+	"Short":            true,
+	"Single":           true,
+	"String":           true,
+	"UInteger":         true,
+	"ULong":            true,
+	"UShort":           true,
+	"XmlQualifiedName": true,
+}
+
+// visualBasicValueType defines the built-in value types of Visual Basic,
+// which are declared as nullable if the elements are optional.
+var visualBasicValueType = map[string]bool{
+	"Boolean":  true,
+	"Byte":     true,
+	"Date":     true,
+	"Decimal":  true,
+	"Double":   true,
+	"Integer":  true,
+	"Long":     true,
+	"SByte":    true,
+	"Short":    true,
+	"Single":   true,
+	"UInteger": true,
+	"ULong":    true,
+	"UShort":   true,
+}
+
+// GenVisualBasic generate Visual Basic .NET programming language source code
+// for XML schema definition files. Like the C# output, the classes are
+// annotated with the attributes in System.Xml.Serialization for the
+// XmlSerializer.
+func (gen *CodeGenerator) GenVisualBasic() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("VisualBasic%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	importsNamespace := "Imports System\nImports System.Collections.Generic\nImports System.Xml\nImports System.Xml.Serialization\n"
+	for _, mapping := range gen.getImportMappings() {
+		importsNamespace += fmt.Sprintf("Imports %s\n", mapping.Import)
+	}
+	var field string
+	for _, line := range strings.Split(strings.TrimSpace(strings.Replace(gen.Field, "\r\n", "\n", -1)), "\n") {
+		if line != "" {
+			line = "\t" + line
+		}
+		field += line + "\n"
+	}
+	source := []byte(fmt.Sprintf("' %s\n\n%s\nNamespace %s\n%sEnd Namespace\n", strings.TrimPrefix(copyright, "// "), importsNamespace, packageName, field))
+	return gen.writeFile(gen.File+".vb", source)
+}
+
+func genVisualBasicFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+func (gen *CodeGenerator) genVisualBasicFieldType(name string, plural bool) (fieldType string) {
+	if _, ok := visualBasicBuildInType[name]; ok {
+		fieldType = name
+	} else if mappedType, ok := gen.getMappedType(name); ok {
+		fieldType = mappedType
+	} else if fieldType = genVisualBasicFieldName(name); fieldType == "" {
+		fieldType = "Object"
+	} else {
+		fieldType = gen.typeName(fieldType)
+	}
+	if plural {
+		fieldType = fmt.Sprintf("List(Of %s)", fieldType)
+	}
+	return
+}
+
+// genVisualBasicProperty generates the auto-implemented property of the class
+// by given attribute in System.Xml.Serialization, the property is renamed if
+// it has the same name as the enclosing class, the identifiers of Visual
+// Basic are case-insensitive.
+func (gen *CodeGenerator) genVisualBasicProperty(className, attribute, name, fieldType string) string {
+	propertyName := gen.fieldName(genVisualBasicFieldName(name))
+	if strings.EqualFold(propertyName, className) {
+		propertyName += "Value"
+	}
+	if attribute != "" {
+		attribute = fmt.Sprintf("\t<%s>\n", attribute)
+	}
+	return fmt.Sprintf("\n%s\tPublic Property %s As %s\n", attribute, propertyName, fieldType)
+}
+
+// genVisualBasicElement generates the property for the element, the optional
+// value types are declared as nullable.
+func (gen *CodeGenerator) genVisualBasicElement(className string, element Element) string {
+	fieldType := gen.genVisualBasicFieldType(gen.getBaseType(element.Type), element.Plural)
+	if element.Optional && !element.Plural && visualBasicValueType[fieldType] {
+		fieldType += "?"
+	}
+	return gen.genVisualBasicProperty(className, fmt.Sprintf("XmlElement(%s)", vbQuote(element.Name)), element.Name, fieldType)
+}
+
+// genVisualBasicAttribute generates the property for the attribute.
+func (gen *CodeGenerator) genVisualBasicAttribute(className string, attribute Attribute) string {
+	fieldType := gen.genVisualBasicFieldType(gen.getBaseType(attribute.Type), attribute.Plural)
+	return gen.genVisualBasicProperty(className, fmt.Sprintf("XmlAttribute(%s)", vbQuote(attribute.Name)), attribute.Name, fieldType)
+}
+
+// genVisualBasicClass generates the class by given declaration, attributes
+// and members.
+func (gen *CodeGenerator) genVisualBasicClass(name, doc, source, location, deprecated, attribute, content string) {
+	gen.StructAST[name] = content
+	fieldName := gen.typeName(genVisualBasicFieldName(name))
+	gen.Field += fmt.Sprintf("%s<%s>\nPartial Public Class %s\n%sEnd Class\n", genFieldComment(fieldName, doc, source, location, "'")+gen.genDeprecated(deprecated), attribute, fieldName, strings.TrimPrefix(content, "\n"))
+}
+
+// VisualBasicSimpleType generates code for simple type XML schema in Visual
+// Basic language syntax.
+func (gen *CodeGenerator) VisualBasicSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	if v.List {
+		content := gen.genVisualBasicProperty(fieldName, "XmlText", "Value", "String")
+		content += gen.genVisualBasicProperty(fieldName, "XmlIgnore", "Items", gen.genVisualBasicFieldType(gen.getBaseType(v.Base), true))
+		gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+		return
+	}
+	if v.Union && len(v.MemberTypes) > 0 {
+		var content string
+		for _, member := range v.MemberTypes {
+			content += gen.genVisualBasicProperty(fieldName, "XmlIgnore", member.Name, gen.genVisualBasicFieldType(gen.getBaseType(member.Type), false))
+		}
+		content += gen.genVisualBasicProperty(fieldName, "XmlText", "Value", "String")
+		gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		var content string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			member := gen.constantName(genVisualBasicFieldName(ConvertCase(enum, PascalCase)))
+			if member == "" || (member[0] >= '0' && member[0] <= '9') {
+				member = "Value" + member
+			}
+			if members[strings.ToLower(member)] {
+				continue
+			}
+			members[strings.ToLower(member)] = true
+			content += fmt.Sprintf("\t<XmlEnum(%s)> %s\n", vbQuote(enum), member)
+		}
+		gen.StructAST[v.Name] = content
+		gen.Field += fmt.Sprintf("%s<XmlType(%s)>\nPublic Enum %s\n%sEnd Enum\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "'")+gen.genDeprecated(v.Deprecated), vbQuote(v.Name), fieldName, content)
+		return
+	}
+	content := gen.genVisualBasicProperty(fieldName, "XmlText", "Value", gen.genVisualBasicFieldType(gen.getBaseType(v.Base), false))
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+	return
+}
+
+// VisualBasicComplexType generates code for complex type XML schema in
+// Visual Basic language syntax.
+func (gen *CodeGenerator) VisualBasicComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	for _, attrGroup := range v.AttributeGroup {
+		content += gen.genVisualBasicProperty(fieldName, "", attrGroup.Name, gen.genVisualBasicFieldType(gen.getBaseType(attrGroup.Ref), false))
+	}
+	for _, attribute := range v.Attributes {
+		content += gen.genVisualBasicAttribute(fieldName, attribute)
+	}
+	for _, group := range v.Groups {
+		content += gen.genVisualBasicProperty(fieldName, "", group.Name, gen.genVisualBasicFieldType(gen.getBaseType(group.Ref), group.Plural))
+	}
+	for _, element := range v.Elements {
+		content += gen.genVisualBasicElement(fieldName, element)
+	}
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+	return
+}
+
+// VisualBasicGroup generates code for group XML schema in Visual Basic
+// language syntax.
+func (gen *CodeGenerator) VisualBasicGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	for _, element := range v.Elements {
+		content += gen.genVisualBasicElement(fieldName, element)
+	}
+	for _, group := range v.Groups {
+		content += gen.genVisualBasicProperty(fieldName, "", group.Name, gen.genVisualBasicFieldType(gen.getBaseType(group.Ref), group.Plural))
+	}
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+	return
+}
+
+// VisualBasicAttributeGroup generates code for attribute group XML schema in
+// Visual Basic language syntax.
+func (gen *CodeGenerator) VisualBasicAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var content string
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	for _, attribute := range v.Attributes {
+		content += gen.genVisualBasicAttribute(fieldName, attribute)
+	}
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+	return
+}
+
+// VisualBasicElement generates code for element XML schema in Visual Basic
+// language syntax. The element of complex type is generated as the root class
+// inherits the type, otherwise the value is the text of the root.
+func (gen *CodeGenerator) VisualBasicElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	fieldType := gen.genVisualBasicFieldType(gen.getBaseType(v.Type), v.Plural)
+	if _, ok := visualBasicBuildInType[fieldType]; !ok && !v.Plural && !strings.EqualFold(fieldType, fieldName) {
+		gen.StructAST[v.Name] = fieldType
+		gen.Field += fmt.Sprintf("%s<XmlRoot(%s)>\nPartial Public Class %s\n\tInherits %s\nEnd Class\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "'")+gen.genDeprecated(v.Deprecated), vbQuote(v.Name), fieldName, fieldType)
+		return
+	}
+	attribute := "XmlText"
+	if v.Plural {
+		attribute = fmt.Sprintf("XmlElement(%s)", vbQuote(v.Name))
+	}
+	content := gen.genVisualBasicProperty(fieldName, attribute, "Value", fieldType)
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlRoot(%s)", vbQuote(v.Name)), content)
+	return
+}
+
+// VisualBasicAttribute generates code for attribute XML schema in Visual
+// Basic language syntax.
+func (gen *CodeGenerator) VisualBasicAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldName := gen.typeName(genVisualBasicFieldName(v.Name))
+	content := gen.genVisualBasicProperty(fieldName, "XmlText", "Value", gen.genVisualBasicFieldType(gen.getBaseType(v.Type), v.Plural))
+	gen.genVisualBasicClass(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fmt.Sprintf("XmlType(%s)", vbQuote(v.Name)), content)
+	return
+}
@@ -0,0 +1,300 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+var xmlBuildInType = map[string]bool{
+	"xml:base":              true,
+	"xml:id":                true,
+	"xml:lang":              true,
+	"xml:space":             true,
+	"xs:ENTITIES":           true,
+	"xs:ENTITY":             true,
+	"xs:ID":                 true,
+	"xs:IDREF":              true,
+	"xs:IDREFS":             true,
+	"xs:NCName":             true,
+	"xs:NMTOKEN":            true,
+	"xs:NMTOKENS":           true,
+	"xs:NOTATION":           true,
+	"xs:Name":               true,
+	"xs:QName":              true,
+	"xs:anyType":            true,
+	"xs:anyURI":             true,
+	"xs:base64Binary":       true,
+	"xs:boolean":            true,
+	"xs:byte":               true,
+	"xs:date":               true,
+	"xs:dateTime":           true,
+	"xs:dateTimeStamp":      true,
+	"xs:dayTimeDuration":    true,
+	"xs:decimal":            true,
+	"xs:double":             true,
+	"xs:duration":           true,
+	"xs:float":              true,
+	"xs:gDay":               true,
+	"xs:gMonth":             true,
+	"xs:gMonthDay":          true,
+	"xs:gYear":              true,
+	"xs:gYearMonth":         true,
+	"xs:hexBinary":          true,
+	"xs:int":                true,
+	"xs:integer":            true,
+	"xs:language":           true,
+	"xs:long":               true,
+	"xs:negativeInteger":    true,
+	"xs:nonNegativeInteger": true,
+	"xs:nonPositiveInteger": true,
+	"xs:normalizedString":   true,
+	"xs:positiveInteger":    true,
+	"xs:short":              true,
+	"xs:string":             true,
+	"xs:time":               true,
+	"xs:token":              true,
+	"xs:unsignedByte":       true,
+	"xs:unsignedInt":        true,
+	"xs:unsignedLong":       true,
+	"xs:unsignedShort":      true,
+	"xs:yearMonthDuration":  true,
+}
+
+// xmlCommentReplacer escapes the text in the XML comments, the double
+// hyphens aren't allowed in them.
+var xmlCommentReplacer = strings.NewReplacer("--", "- -")
+
+// xmlSampler generates the sample instance of the element by the
+// declarations in the proto tree, the visiting holds the types and groups
+// on the path from the root to break the recursions.
+type xmlSampler struct {
+	gen             *CodeGenerator
+	simpleTypes     map[string]*SimpleType
+	complexTypes    map[string]*ComplexType
+	groups          map[string]*Group
+	attributeGroups map[string]*AttributeGroup
+	visiting        map[string]bool
+}
+
+// GenXML generate the sample XML instance documents for XML schema
+// definition files, one document for each global element which isn't
+// abstract. The documents are the minimal instances: the optional elements
+// and attributes are omitted, the required elements and the first
+// alternatives of the required choices occur once, and the values are the
+// valid samples of the types satisfying the facets, e.g. the first
+// enumeration value. The recursive required elements and the
+// references to the failed declarations are left empty with a comment.
+func (gen *CodeGenerator) GenXML() error {
+	var names []string
+	for _, ele := range gen.ProtoTree {
+		// The global elements are the root elements of the documents,
+		// even if the wrappers are skipped in the generated code.
+		if v, ok := ele.(*Element); ok && v != nil && !gen.isMappedType(v) {
+			if _, ok := gen.StructAST[v.Name]; !ok && !v.Abstract {
+				names = append(names, v.Name)
+			}
+			gen.genDeclaration(v, "XMLElement")
+		}
+	}
+	for _, name := range names {
+		document, ok := gen.Schemas[name].(string)
+		if !ok {
+			continue
+		}
+		source := []byte(fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!-- %s -->\n%s", strings.TrimPrefix(copyright, "// "), document))
+		if err := gen.writeFile(gen.File+"."+name+".xml", source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func genXMLFieldName(name string) string {
+	return trimNSPrefix(name)
+}
+
+// xmlEscape returns the escaped text or attribute value in XML.
+func xmlEscape(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+// newXMLSampler creates the sampler with the declarations in the proto tree
+// of the generator.
+func newXMLSampler(gen *CodeGenerator) *xmlSampler {
+	s := &xmlSampler{
+		gen:             gen,
+		simpleTypes:     map[string]*SimpleType{},
+		complexTypes:    map[string]*ComplexType{},
+		groups:          map[string]*Group{},
+		attributeGroups: map[string]*AttributeGroup{},
+		visiting:        map[string]bool{},
+	}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			s.simpleTypes[v.Name] = v
+		case *ComplexType:
+			s.complexTypes[v.Name] = v
+		case *Group:
+			s.groups[v.Name] = v
+		case *AttributeGroup:
+			s.attributeGroups[v.Name] = v
+		}
+	}
+	return s
+}
+
+// value generates the sample value of the simple type or built-in type by
+// given name with the facets.
+func (s *xmlSampler) value(typeName string, restriction Restriction) (string, error) {
+	if v, ok := s.simpleTypes[trimNSPrefix(typeName)]; ok && !xmlBuildInType[typeName] {
+		return sampleSimpleType(v, s.simpleTypes, map[string]bool{})
+	}
+	return SampleValue(trimNSPrefix(typeName), restriction)
+}
+
+// comment returns the comment after the empty element or attribute which has
+// no valid sample value by given type name and error.
+func (s *xmlSampler) comment(typeName string, err error) string {
+	if reason, ok := s.gen.Failed[trimNSPrefix(typeName)]; ok {
+		return fmt.Sprintf("<!-- %s is a placeholder, xgen failed to generate it: %s -->", trimNSPrefix(typeName), xmlCommentReplacer.Replace(strings.Replace(reason, "\n", " ", -1)))
+	}
+	return fmt.Sprintf("<!-- no valid sample of %s: %s -->", trimNSPrefix(typeName), xmlCommentReplacer.Replace(err.Error()))
+}
+
+// attributes generates the required attributes and the ones of the attribute
+// groups.
+func (s *xmlSampler) attributes(out *strings.Builder, comments *[]string, attributes []Attribute, attributeGroups []AttributeGroup) {
+	for _, attribute := range attributes {
+		if attribute.Optional {
+			continue
+		}
+		value, err := s.value(attribute.Type, attribute.Restriction)
+		if err != nil {
+			*comments = append(*comments, s.comment(attribute.Type, err))
+		}
+		fmt.Fprintf(out, " %s=\"%s\"", attribute.Name, xmlEscape(value))
+	}
+	for _, attributeGroup := range attributeGroups {
+		name := trimNSPrefix(attributeGroup.Ref)
+		if v, ok := s.attributeGroups[name]; ok && !s.visiting["AttributeGroup:"+name] {
+			s.visiting["AttributeGroup:"+name] = true
+			s.attributes(out, comments, v.Attributes, nil)
+			delete(s.visiting, "AttributeGroup:"+name)
+		}
+	}
+}
+
+// content generates the required child elements, the first alternatives of
+// the required choices and the elements of the groups.
+func (s *xmlSampler) content(out *strings.Builder, indent string, elements []Element, choices []Choice, groups []Group) {
+	for _, element := range elements {
+		if element.Optional || element.Wildcard {
+			continue
+		}
+		s.element(out, indent, element.Name, "", element.Type, element.Restriction, element.Deprecated)
+	}
+	for _, choice := range choices {
+		if alternatives := choiceAlternatives(choice, elements); !choice.Optional && len(alternatives) > 0 {
+			element := alternatives[0]
+			s.element(out, indent, element.Name, "", element.Type, element.Restriction, element.Deprecated)
+		}
+	}
+	for _, group := range groups {
+		name := trimNSPrefix(group.Ref)
+		if v, ok := s.groups[name]; ok && !s.visiting["Group:"+name] {
+			s.visiting["Group:"+name] = true
+			s.content(out, indent, v.Elements, v.Choices, v.Groups)
+			delete(s.visiting, "Group:"+name)
+		}
+	}
+}
+
+// baseChain returns the complex type and the complex types it extends
+// directly or indirectly, the base types come first since their members
+// precede the members of the derived types in the documents.
+func (s *xmlSampler) baseChain(v *ComplexType) []*ComplexType {
+	chain := []*ComplexType{v}
+	seen := map[string]bool{v.Name: true}
+	for base := trimNSPrefix(s.gen.complexBase(v)); base != "" && !seen[base]; base = trimNSPrefix(s.gen.complexBase(v)) {
+		seen[base] = true
+		if v = s.complexTypes[base]; v == nil {
+			break
+		}
+		chain = append([]*ComplexType{v}, chain...)
+	}
+	return chain
+}
+
+// element generates the sample element by given name and type at the
+// indentation, the namespace is declared as the default namespace if it's
+// not empty.
+func (s *xmlSampler) element(out *strings.Builder, indent, name, namespace, typeName string, restriction Restriction, deprecated string) {
+	if deprecated != "" {
+		out.WriteString(indent + strings.TrimSuffix(s.gen.genDeprecated(deprecated), "\r\n") + "\n")
+	}
+	start := indent + "<" + name
+	if namespace != "" {
+		start += fmt.Sprintf(" xmlns=\"%s\"", xmlEscape(namespace))
+	}
+	complexTypeName := trimNSPrefix(typeName)
+	v, ok := s.complexTypes[complexTypeName]
+	if !ok || xmlBuildInType[typeName] {
+		value, err := s.value(typeName, restriction)
+		if err != nil {
+			fmt.Fprintf(out, "%s/>%s\n", start, s.comment(typeName, err))
+			return
+		}
+		fmt.Fprintf(out, "%s>%s</%s>\n", start, xmlEscape(value), name)
+		return
+	}
+	if s.visiting["ComplexType:"+complexTypeName] {
+		fmt.Fprintf(out, "%s/><!-- recursive %s is omitted -->\n", start, complexTypeName)
+		return
+	}
+	s.visiting["ComplexType:"+complexTypeName] = true
+	defer delete(s.visiting, "ComplexType:"+complexTypeName)
+	var attributes, content strings.Builder
+	var comments []string
+	chain := s.baseChain(v)
+	for _, v := range chain {
+		s.attributes(&attributes, &comments, v.Attributes, v.AttributeGroup)
+	}
+	for _, comment := range comments {
+		content.WriteString(indent + "  " + comment + "\n")
+	}
+	for _, v := range chain {
+		s.content(&content, indent+"  ", v.Elements, v.Choices, v.Groups)
+	}
+	if content.Len() == 0 {
+		fmt.Fprintf(out, "%s%s/>\n", start, attributes.String())
+		return
+	}
+	fmt.Fprintf(out, "%s%s>\n%s%s</%s>\n", start, attributes.String(), content.String(), indent, name)
+}
+
+// XMLElement generates the sample document for element XML schema.
+func (gen *CodeGenerator) XMLElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok || v.Abstract {
+		return
+	}
+	var document strings.Builder
+	newXMLSampler(gen).element(&document, "", v.Name, gen.TargetNamespace, v.Type, v.Restriction, v.Deprecated)
+	gen.StructAST[v.Name] = v.Type
+	if gen.Schemas == nil {
+		gen.Schemas = map[string]interface{}{}
+	}
+	gen.Schemas[v.Name] = document.String()
+	return
+}
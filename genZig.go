@@ -0,0 +1,407 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var zigBuildInType = map[string]bool{
+	"[]const []const u8": true,
+	"[]const u8":         true,
+	"bool":               true,
+	"f32":                true,
+	"f64":                true,
+	"i16":                true,
+	"i32":                true,
+	"i64":                true,
+	"i8":                 true,
+	"u16":                true,
+	"u32":                true,
+	"u64":                true,
+	"u8":                 true,
+}
+
+// zigHelpers defines the error and the functions used by the generated code
+// to parse and free the values.
+const zigHelpers = `/// ParseError is the error returned by the parse functions of the types.
+pub const ParseError = std.mem.Allocator.Error || error{ MissingAttribute, MissingElement, InvalidValue };
+
+/// xgen holds the functions used by the generated code. The node passed to the
+/// parse functions can be any value with the methods:
+///
+///     fn attribute(self, name: []const u8) ?[]const u8
+///     fn text(self) []const u8
+///     fn children(self, name: []const u8) Iterator
+///
+/// where the next method of the Iterator returns the child elements by the
+/// name in order, and null at the end.
+const xgen = struct {
+    fn parseText(comptime T: type, allocator: std.mem.Allocator, text: []const u8) ParseError!T {
+        const value = std.mem.trim(u8, text, " \t\r\n");
+        switch (@typeInfo(T)) {
+            .bool => {
+                if (std.mem.eql(u8, value, "true") or std.mem.eql(u8, value, "1")) return true;
+                if (std.mem.eql(u8, value, "false") or std.mem.eql(u8, value, "0")) return false;
+                return error.InvalidValue;
+            },
+            .int => return std.fmt.parseInt(T, value, 10) catch return error.InvalidValue,
+            .float => return std.fmt.parseFloat(T, value) catch return error.InvalidValue,
+            .@"enum" => return std.meta.stringToEnum(T, value) orelse return error.InvalidValue,
+            .pointer => |pointer| {
+                if (pointer.child == u8) return try allocator.dupe(u8, text);
+                var count: usize = 0;
+                var counter = std.mem.tokenizeAny(u8, text, " \t\r\n");
+                while (counter.next()) |_| count += 1;
+                const items = try allocator.alloc(pointer.child, count);
+                var parsed: usize = 0;
+                errdefer {
+                    for (items[0..parsed]) |item| free(pointer.child, allocator, item);
+                    allocator.free(items);
+                }
+                var tokens = std.mem.tokenizeAny(u8, text, " \t\r\n");
+                while (tokens.next()) |token| : (parsed += 1) {
+                    items[parsed] = try parseText(pointer.child, allocator, token);
+                }
+                return items;
+            },
+            else => @compileError("unsupported type " ++ @typeName(T)),
+        }
+    }
+
+    fn parseNode(comptime T: type, allocator: std.mem.Allocator, node: anytype) ParseError!T {
+        if (@typeInfo(T) == .@"struct") return T.parse(allocator, node);
+        return parseText(T, allocator, node.text());
+    }
+
+    fn attribute(comptime T: type, allocator: std.mem.Allocator, node: anytype, name: []const u8) ParseError!T {
+        const text = node.attribute(name) orelse return error.MissingAttribute;
+        return parseText(T, allocator, text);
+    }
+
+    fn optionalAttribute(comptime T: type, allocator: std.mem.Allocator, node: anytype, name: []const u8) ParseError!?T {
+        const text = node.attribute(name) orelse return null;
+        return try parseText(T, allocator, text);
+    }
+
+    fn element(comptime T: type, allocator: std.mem.Allocator, node: anytype, name: []const u8) ParseError!T {
+        var children = node.children(name);
+        const child = children.next() orelse return error.MissingElement;
+        return parseNode(T, allocator, child);
+    }
+
+    fn optionalElement(comptime T: type, allocator: std.mem.Allocator, node: anytype, name: []const u8) ParseError!?T {
+        var children = node.children(name);
+        const child = children.next() orelse return null;
+        return try parseNode(T, allocator, child);
+    }
+
+    fn elements(comptime T: type, allocator: std.mem.Allocator, node: anytype, name: []const u8) ParseError![]T {
+        var count: usize = 0;
+        var counter = node.children(name);
+        while (counter.next()) |_| count += 1;
+        const items = try allocator.alloc(T, count);
+        var parsed: usize = 0;
+        errdefer {
+            for (items[0..parsed]) |item| free(T, allocator, item);
+            allocator.free(items);
+        }
+        var children = node.children(name);
+        while (children.next()) |child| : (parsed += 1) {
+            items[parsed] = try parseNode(T, allocator, child);
+        }
+        return items;
+    }
+
+    fn free(comptime T: type, allocator: std.mem.Allocator, value: T) void {
+        switch (@typeInfo(T)) {
+            .@"struct" => value.free(allocator),
+            .optional => |optional| if (value) |child| free(optional.child, allocator, child),
+            .pointer => |pointer| {
+                if (pointer.child != u8) {
+                    for (value) |item| free(pointer.child, allocator, item);
+                }
+                allocator.free(value);
+            },
+            else => {},
+        }
+    }
+};
+`
+
+// zigIdentifier matches the identifiers which can be used in Zig without the
+// @"" syntax if they are not the reserved words.
+var zigIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// zigField holds the field of the generated struct, and the expression
+// parsing the field from the node.
+type zigField struct {
+	name, fieldType, parse string
+	optional               bool
+}
+
+// GenZig generate Zig programming language source code for XML schema
+// definition files. The complex types are generated as structs with the parse
+// functions taking the allocator and the node, and the free functions
+// releasing the memory allocated by the parse functions.
+func (gen *CodeGenerator) GenZig() error {
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isMappedType(ele) || gen.isWrapper(ele) {
+			continue
+		}
+		funcName := fmt.Sprintf("Zig%s", reflect.TypeOf(ele).String()[6:])
+		gen.genDeclaration(ele, funcName)
+	}
+	gen.genPlaceholders()
+	importPackage := "const std = @import(\"std\");\n"
+	for _, mapping := range gen.getImportMappings() {
+		importPackage += fmt.Sprintf("const %s = @import(%s);\n", strings.TrimSuffix(path.Base(mapping.Import), ".zig"), hexQuote(mapping.Import))
+	}
+	source := []byte(fmt.Sprintf("%s\n\n%s\n%s%s", copyright, importPackage, zigHelpers, gen.Field))
+	return gen.writeFile(gen.File+".zig", source)
+}
+
+func genZigFieldName(name string) (fieldName string) {
+	for _, str := range strings.Split(name, ":") {
+		fieldName += MakeFirstUpperCase(str)
+	}
+	var tmp string
+	for _, str := range strings.Split(fieldName, ".") {
+		tmp += MakeFirstUpperCase(str)
+	}
+	fieldName = tmp
+	fieldName = strings.Replace(strings.Replace(fieldName, "-", "", -1), "_", "", -1)
+	return
+}
+
+// genZigFieldKey generates the snake case name of the struct field for Zig
+// code.
+func (gen *CodeGenerator) genZigFieldKey(name string) string {
+	return gen.fieldName(ToSnakeCase(genZigFieldName(name)))
+}
+
+// genZigFieldType generates the type for Zig code by given type. The list and
+// union simple types are declared as the slices and strings.
+func (gen *CodeGenerator) genZigFieldType(name string) string {
+	if _, ok := zigBuildInType[name]; ok {
+		return name
+	}
+	if mappedType, ok := gen.getMappedType(name); ok {
+		return mappedType
+	}
+	fieldType := genZigFieldName(name)
+	if fieldType == "" {
+		return "[]const u8"
+	}
+	return gen.typeName(fieldType)
+}
+
+// genZigElement generates the field for the element.
+func (gen *CodeGenerator) genZigElement(element Element) zigField {
+	fieldType := gen.genZigFieldType(gen.getBaseType(element.Type))
+	field := zigField{name: gen.genZigFieldKey(element.Name), fieldType: fieldType}
+	switch {
+	case element.Plural:
+		field.fieldType = "[]const " + fieldType
+		field.parse = fmt.Sprintf("xgen.elements(%s, allocator, node, %s)", fieldType, hexQuote(element.Name))
+	case element.Optional:
+		field.fieldType, field.optional = "?"+fieldType, true
+		field.parse = fmt.Sprintf("xgen.optionalElement(%s, allocator, node, %s)", fieldType, hexQuote(element.Name))
+	default:
+		field.parse = fmt.Sprintf("xgen.element(%s, allocator, node, %s)", fieldType, hexQuote(element.Name))
+	}
+	return field
+}
+
+// genZigAttribute generates the field for the attribute.
+func (gen *CodeGenerator) genZigAttribute(attribute Attribute) zigField {
+	fieldType := gen.genZigFieldType(gen.getBaseType(attribute.Type))
+	if attribute.Plural {
+		fieldType = "[]const " + fieldType
+	}
+	field := zigField{name: gen.genZigFieldKey(attribute.Name), fieldType: fieldType}
+	if attribute.Optional {
+		field.fieldType, field.optional = "?"+fieldType, true
+		field.parse = fmt.Sprintf("xgen.optionalAttribute(%s, allocator, node, %s)", fieldType, hexQuote(attribute.Name))
+		return field
+	}
+	field.parse = fmt.Sprintf("xgen.attribute(%s, allocator, node, %s)", fieldType, hexQuote(attribute.Name))
+	return field
+}
+
+// genZigInline generates the field for the group or attribute group, which
+// is parsed from the enclosing element.
+func (gen *CodeGenerator) genZigInline(name, ref string) zigField {
+	fieldType := gen.genZigFieldType(gen.getBaseType(ref))
+	return zigField{name: gen.genZigFieldKey(name), fieldType: fieldType, parse: fmt.Sprintf("%s.parse(allocator, node)", fieldType)}
+}
+
+// genZigStruct generates the struct by given declaration and fields with the
+// parse and free functions. The fields parsed before the failure are freed by
+// the errdefer statements.
+func (gen *CodeGenerator) genZigStruct(name, doc, source, location, deprecated string, fields []zigField) {
+	fieldName := gen.typeName(genZigFieldName(name))
+	var content, parse, free string
+	for i, field := range fields {
+		content += fmt.Sprintf("    %s: %s", field.name, field.fieldType)
+		if field.optional {
+			content += " = null"
+		}
+		content += ",\n"
+		parse += fmt.Sprintf("        result.%s = try %s;\n", field.name, field.parse)
+		if i < len(fields)-1 {
+			parse += fmt.Sprintf("        errdefer xgen.free(%s, allocator, result.%s);\n", field.fieldType, field.name)
+		}
+		free += fmt.Sprintf("        xgen.free(%s, allocator, self.%s);\n", field.fieldType, field.name)
+	}
+	if len(fields) == 0 {
+		parse = "        _ = allocator;\n        _ = node;\n        return .{};\n"
+		free = "        _ = self;\n        _ = allocator;\n"
+	} else {
+		parse = fmt.Sprintf("        var result: %s = undefined;\n%s        return result;\n", fieldName, parse)
+		content += "\n"
+	}
+	gen.StructAST[name] = content
+	gen.Field += fmt.Sprintf("%spub const %[2]s = struct {\n%[3]s    /// parse parses the %[2]s from the node, the result should be released\n    /// by free with the same allocator.\n    pub fn parse(allocator: std.mem.Allocator, node: anytype) ParseError!%[2]s {\n%[4]s    }\n\n    /// free releases the memory allocated by parse.\n    pub fn free(self: %[2]s, allocator: std.mem.Allocator) void {\n%[5]s    }\n};\n", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, content, parse, free)
+}
+
+// genZigAlias generates the constant of the type by given declaration and
+// type.
+func (gen *CodeGenerator) genZigAlias(name, doc, source, location, deprecated, fieldType string) {
+	gen.StructAST[name] = fieldType
+	fieldName := gen.typeName(genZigFieldName(name))
+	gen.Field += fmt.Sprintf("%spub const %s = %s;\n", genFieldComment(fieldName, doc, source, location, "///")+gen.genDeprecated(deprecated), fieldName, fieldType)
+}
+
+// genZigEnumField generates the field of the enum by given enumeration value,
+// the tag name of the field is the value so it can be parsed and formatted by
+// std.meta.stringToEnum and @tagName.
+func genZigEnumField(enum string) string {
+	if zigIdentifier.MatchString(enum) && enum != "_" && !ReservedWords["Zig"][enum] {
+		return enum
+	}
+	return "@" + hexQuote(enum)
+}
+
+// ZigSimpleType generates code for simple type XML schema in Zig language
+// syntax.
+func (gen *CodeGenerator) ZigSimpleType(v *SimpleType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	if v.List {
+		gen.genZigAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "[]const "+gen.genZigFieldType(gen.getBaseType(v.Base)))
+		return
+	}
+	if v.Union {
+		gen.genZigAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, "[]const u8")
+		return
+	}
+	if len(v.Restriction.Enum) > 0 {
+		fieldName := gen.typeName(genZigFieldName(v.Name))
+		var content string
+		members := map[string]bool{}
+		for _, enum := range v.Restriction.Enum {
+			if members[enum] {
+				continue
+			}
+			members[enum] = true
+			content += fmt.Sprintf("    %s,\n", genZigEnumField(enum))
+		}
+		gen.StructAST[v.Name] = content
+		gen.Field += fmt.Sprintf("%spub const %s = enum {\n%s};\n", genFieldComment(fieldName, v.Doc, v.Source, v.Location, "///")+gen.genDeprecated(v.Deprecated), fieldName, content)
+		return
+	}
+	gen.genZigAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, gen.genZigFieldType(gen.getBaseType(v.Base)))
+	return
+}
+
+// ZigComplexType generates code for complex type XML schema in Zig language
+// syntax.
+func (gen *CodeGenerator) ZigComplexType(v *ComplexType) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []zigField
+	for _, attrGroup := range v.AttributeGroup {
+		fields = append(fields, gen.genZigInline(attrGroup.Name, attrGroup.Ref))
+	}
+	for _, attribute := range v.Attributes {
+		fields = append(fields, gen.genZigAttribute(attribute))
+	}
+	for _, group := range v.Groups {
+		fields = append(fields, gen.genZigInline(group.Name, group.Ref))
+	}
+	for _, element := range v.Elements {
+		fields = append(fields, gen.genZigElement(element))
+	}
+	gen.genZigStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// ZigGroup generates code for group XML schema in Zig language syntax.
+func (gen *CodeGenerator) ZigGroup(v *Group) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []zigField
+	for _, element := range v.Elements {
+		fields = append(fields, gen.genZigElement(element))
+	}
+	for _, group := range v.Groups {
+		fields = append(fields, gen.genZigInline(group.Name, group.Ref))
+	}
+	gen.genZigStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// ZigAttributeGroup generates code for attribute group XML schema in Zig
+// language syntax.
+func (gen *CodeGenerator) ZigAttributeGroup(v *AttributeGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	var fields []zigField
+	for _, attribute := range v.Attributes {
+		fields = append(fields, gen.genZigAttribute(attribute))
+	}
+	gen.genZigStruct(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fields)
+	return
+}
+
+// ZigElement generates code for element XML schema in Zig language syntax.
+func (gen *CodeGenerator) ZigElement(v *Element) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genZigFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType = "[]const " + fieldType
+	}
+	gen.genZigAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
+
+// ZigAttribute generates code for attribute XML schema in Zig language
+// syntax.
+func (gen *CodeGenerator) ZigAttribute(v *Attribute) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	fieldType := gen.genZigFieldType(gen.getBaseType(v.Type))
+	if v.Plural {
+		fieldType = "[]const " + fieldType
+	}
+	gen.genZigAlias(v.Name, v.Doc, v.Source, v.Location, v.Deprecated, fieldType)
+	return
+}
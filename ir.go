@@ -0,0 +1,182 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+)
+
+// IRVersion is the version of the intermediate representation, which is
+// increased on every incompatible change of the representation.
+const IRVersion = 2
+
+// Kinds of the declarations in the intermediate representation.
+const (
+	KindSimpleType     = "simpleType"
+	KindComplexType    = "complexType"
+	KindElement        = "element"
+	KindAttribute      = "attribute"
+	KindGroup          = "group"
+	KindAttributeGroup = "attributeGroup"
+)
+
+// IR is the intermediate representation of a schema document, which holds
+// the declarations of the proto tree in order after the normalization pass
+// of NewIR. The pass resolves the Targets of the simple types, global
+// elements and attributes and the types of the union members declared
+// before them, and attaches the facets of the simple types to the elements
+// and attributes which are parsed before the simple types. The parser hands
+// the normalized declarations to the code generators by ProtoTree, and the
+// representation could be serialized as JSON for the external tools.
+type IR struct {
+	Version         int
+	TargetNamespace string
+	Declarations    []Declaration
+	Targets         map[string]string
+}
+
+// Declaration holds a declaration in the intermediate representation, only
+// the field of the Kind is set.
+type Declaration struct {
+	Kind           string
+	SimpleType     *SimpleType     `json:",omitempty"`
+	ComplexType    *ComplexType    `json:",omitempty"`
+	Element        *Element        `json:",omitempty"`
+	Attribute      *Attribute      `json:",omitempty"`
+	Group          *Group          `json:",omitempty"`
+	AttributeGroup *AttributeGroup `json:",omitempty"`
+}
+
+// NewIR creates the intermediate representation by normalizing the proto
+// tree of the schema document with the target namespace.
+func NewIR(protoTree []interface{}, targetNamespace string) *IR {
+	ir := &IR{Version: IRVersion, TargetNamespace: targetNamespace, Targets: resolveTargets(protoTree)}
+	resolveMemberTypes(protoTree, ir.Targets)
+	seen := map[interface{}]bool{}
+	for _, ele := range protoTree {
+		if ele == nil || reflect.ValueOf(ele).IsNil() || seen[ele] {
+			continue
+		}
+		seen[ele] = true
+		var declaration Declaration
+		switch v := ele.(type) {
+		case *SimpleType:
+			declaration = Declaration{Kind: KindSimpleType, SimpleType: v}
+		case *ComplexType:
+			v.Elements = normalizeElements(v.Elements, protoTree)
+			v.Attributes = normalizeAttributes(v.Attributes, protoTree)
+			declaration = Declaration{Kind: KindComplexType, ComplexType: v}
+		case *Element:
+			normalizeRestriction(v.Type, &v.Restriction, protoTree)
+			declaration = Declaration{Kind: KindElement, Element: v}
+		case *Attribute:
+			normalizeRestriction(v.Type, &v.Restriction, protoTree)
+			declaration = Declaration{Kind: KindAttribute, Attribute: v}
+		case *Group:
+			v.Elements = normalizeElements(v.Elements, protoTree)
+			declaration = Declaration{Kind: KindGroup, Group: v}
+		case *AttributeGroup:
+			v.Attributes = normalizeAttributes(v.Attributes, protoTree)
+			declaration = Declaration{Kind: KindAttributeGroup, AttributeGroup: v}
+		default:
+			continue
+		}
+		ir.Declarations = append(ir.Declarations, declaration)
+	}
+	return ir
+}
+
+// normalizeElements attaches the facets of the simple types declared after
+// the elements, which are unknown while parsing the elements.
+func normalizeElements(elements []Element, protoTree []interface{}) []Element {
+	for i := range elements {
+		normalizeRestriction(elements[i].Type, &elements[i].Restriction, protoTree)
+	}
+	return elements
+}
+
+// normalizeAttributes attaches the facets of the simple types declared after
+// the attributes, which are unknown while parsing the attributes.
+func normalizeAttributes(attributes []Attribute, protoTree []interface{}) []Attribute {
+	for i := range attributes {
+		normalizeRestriction(attributes[i].Type, &attributes[i].Restriction, protoTree)
+	}
+	return attributes
+}
+
+// normalizeRestriction attaches the facets of the simple type by given type
+// name if no facets are attached yet.
+func normalizeRestriction(typeName string, restriction *Restriction, protoTree []interface{}) {
+	if reflect.DeepEqual(*restriction, Restriction{}) {
+		*restriction = getRestrictionFromSimpleType(trimNSPrefix(typeName), protoTree)
+	}
+}
+
+// ProtoTree returns the declarations of the intermediate representation as
+// the proto tree for the code generators.
+func (ir *IR) ProtoTree() []interface{} {
+	protoTree := make([]interface{}, 0, len(ir.Declarations))
+	for _, declaration := range ir.Declarations {
+		switch declaration.Kind {
+		case KindSimpleType:
+			protoTree = append(protoTree, declaration.SimpleType)
+		case KindComplexType:
+			protoTree = append(protoTree, declaration.ComplexType)
+		case KindElement:
+			protoTree = append(protoTree, declaration.Element)
+		case KindAttribute:
+			protoTree = append(protoTree, declaration.Attribute)
+		case KindGroup:
+			protoTree = append(protoTree, declaration.Group)
+		case KindAttributeGroup:
+			protoTree = append(protoTree, declaration.AttributeGroup)
+		}
+	}
+	return protoTree
+}
+
+// Encode provides a function to encode the intermediate representation as
+// JSON.
+func (ir *IR) Encode() (data []byte, err error) {
+	if data, err = json.MarshalIndent(ir, "", "    "); err != nil {
+		return
+	}
+	return append(data, '\n'), nil
+}
+
+// Save provides a function to write the intermediate representation as JSON
+// by given path.
+func (ir *IR) Save(path string) (err error) {
+	var data []byte
+	if data, err = ir.Encode(); err != nil {
+		return
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// LoadIR provides a function to read the intermediate representation from
+// the JSON file by given path, the representation of the other versions is
+// rejected.
+func LoadIR(path string) (ir *IR, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+	ir = &IR{}
+	if err = json.Unmarshal(data, ir); err != nil {
+		return
+	}
+	if ir.Version != IRVersion {
+		err = fmt.Errorf("unsupported IR version %d in %s, expected %d", ir.Version, path, IRVersion)
+	}
+	return
+}
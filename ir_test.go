@@ -0,0 +1,53 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewIR(t *testing.T) {
+	protoTree := []interface{}{
+		&ComplexType{
+			Name:       "itemType",
+			Elements:   []Element{{Name: "code", Type: "codeType"}},
+			Attributes: []Attribute{{Name: "size", Type: "sizeType"}},
+		},
+		&SimpleType{Name: "sizeType", Union: true, MemberTypes: []MemberType{{Name: "codeType"}, {Name: "int", Type: "int"}}},
+		&SimpleType{Name: "codeType", Base: "string", Restriction: Restriction{MaxLength: 3}},
+		&Element{Name: "item", Type: "itemType"},
+		nil,
+	}
+	ir := NewIR(protoTree, "urn:item")
+	assert.Equal(t, IRVersion, ir.Version)
+	assert.Equal(t, "urn:item", ir.TargetNamespace)
+	assert.Equal(t, map[string]string{"codeType": "string", "item": "itemType"}, ir.Targets)
+	assert.Len(t, ir.Declarations, 4)
+	assert.Equal(t, KindComplexType, ir.Declarations[0].Kind)
+	// the facets of the simple type declared after the element are attached.
+	assert.Equal(t, 3, ir.Declarations[0].ComplexType.Elements[0].Restriction.MaxLength)
+	// the member type declared after the union is resolved to its target.
+	assert.Equal(t, []MemberType{{Name: "codeType", Type: "string"}, {Name: "int", Type: "int"}}, ir.Declarations[1].SimpleType.MemberTypes)
+	assert.Equal(t, protoTree[:4], ir.ProtoTree())
+
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "item.ir.json")
+	assert.NoError(t, ir.Save(path))
+	loaded, err := LoadIR(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ir.Targets, loaded.Targets)
+	assert.Equal(t, "code", loaded.Declarations[0].ComplexType.Elements[0].Name)
+}
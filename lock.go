@@ -0,0 +1,152 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"golang.org/x/net/html/charset"
+)
+
+// LockEntry holds the URL, the version declared by the version attribute of
+// the schema element and the SHA-256 checksum of a remote schema.
+type LockEntry struct {
+	URL     string `json:"url"`
+	Version string `json:"version,omitempty"`
+	SHA256  string `json:"sha256"`
+}
+
+// Lockfile records the remote schemas resolved for the generation, the
+// schemas are verified against the recorded checksums on subsequent runs to
+// make the generation reproducible and detect the upstream schema changes.
+// The changed schemas are recorded again instead if Update is true.
+type Lockfile struct {
+	Schemas []*LockEntry `json:"schemas"`
+	Update  bool         `json:"-"`
+
+	changed bool
+}
+
+// LoadLockfile loads the lockfile by given path, the empty lockfile is
+// returned if the file doesn't exist.
+func LoadLockfile(path string) (lock *Lockfile, err error) {
+	lock = &Lockfile{}
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	if err = json.Unmarshal(data, lock); err != nil {
+		err = fmt.Errorf("parse lockfile %s: %v", path, err)
+	}
+	return
+}
+
+// entry returns the lock entry by given URL.
+func (l *Lockfile) entry(URL string) *LockEntry {
+	for _, entry := range l.Schemas {
+		if entry.URL == URL {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Verify provides a function to verify the schema resolved for the URL by
+// the checksum recorded in the lockfile, the schema is recorded if it's not
+// in the lockfile yet.
+func (l *Lockfile) Verify(URL, path string) (err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	entry := l.entry(URL)
+	if entry != nil && entry.SHA256 == checksum {
+		return
+	}
+	if entry != nil && !l.Update {
+		err = fmt.Errorf("checksum mismatch for %s: locked %s, got %s", URL, entry.SHA256, checksum)
+		return
+	}
+	if entry == nil {
+		entry = &LockEntry{URL: URL}
+		l.Schemas = append(l.Schemas, entry)
+	}
+	entry.Version, entry.SHA256, l.changed = schemaVersion(data), checksum, true
+	return
+}
+
+// Save provides a function to write the lockfile by given path if any schema
+// is recorded or updated, the schemas are sorted by URL.
+func (l *Lockfile) Save(path string) (err error) {
+	if !l.changed {
+		return
+	}
+	sort.Slice(l.Schemas, func(i, j int) bool { return l.Schemas[i].URL < l.Schemas[j].URL })
+	var data []byte
+	if data, err = json.MarshalIndent(l, "", "    "); err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(path, append(data, '\n'), 0644); err == nil {
+		l.changed = false
+	}
+	return
+}
+
+// schemaVersion returns the value of the version attribute of the schema
+// element in the document.
+func schemaVersion(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "version" && attr.Name.Space == "" {
+					return attr.Value
+				}
+			}
+			return ""
+		}
+	}
+}
+
+// LockedResolver verifies the schemas resolved by the resolver for the URLs
+// with the lockfile.
+type LockedResolver struct {
+	Resolver SchemaResolver
+	Lock     *Lockfile
+}
+
+// Resolve provides a function to resolve the schema location by the resolver
+// and verify the resolved schema with the lockfile.
+func (r *LockedResolver) Resolve(location string) (path string, err error) {
+	if path, err = r.Resolver.Resolve(location); err != nil || path == "" || !isValidURL(location) {
+		return
+	}
+	if err = r.Lock.Verify(location, path); err != nil {
+		path = ""
+	}
+	return
+}
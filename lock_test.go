@@ -0,0 +1,76 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	schema := filepath.Join(dir, "b.xsd")
+	assert.NoError(t, ioutil.WriteFile(schema, []byte(`<xs:schema version="1.2"/>`), 0644))
+	other := filepath.Join(dir, "a.xsd")
+	assert.NoError(t, ioutil.WriteFile(other, []byte(`<xs:schema/>`), 0644))
+
+	// the missing lockfile is loaded as empty, and nothing is written
+	// before any schema is recorded.
+	lockFile := filepath.Join(dir, "xgen.lock")
+	lock, err := LoadLockfile(lockFile)
+	assert.NoError(t, err)
+	assert.Empty(t, lock.Schemas)
+	assert.NoError(t, lock.Save(lockFile))
+	_, err = os.Stat(lockFile)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, lock.Verify("http://example.com/b.xsd", schema))
+	assert.NoError(t, lock.Verify("http://example.com/a.xsd", other))
+	assert.Equal(t, "1.2", lock.entry("http://example.com/b.xsd").Version)
+	assert.Len(t, lock.entry("http://example.com/b.xsd").SHA256, 64)
+	assert.NoError(t, lock.Save(lockFile))
+	data, err := ioutil.ReadFile(lockFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.Index(string(data), "a.xsd") < strings.Index(string(data), "b.xsd"))
+
+	// the unchanged lockfile isn't written again.
+	lock, err = LoadLockfile(lockFile)
+	assert.NoError(t, err)
+	assert.NoError(t, lock.Verify("http://example.com/b.xsd", schema))
+	assert.NoError(t, os.Remove(lockFile))
+	assert.NoError(t, lock.Save(lockFile))
+	_, err = os.Stat(lockFile)
+	assert.True(t, os.IsNotExist(err))
+
+	// the changed schema is rejected unless the lockfile is updated.
+	assert.NoError(t, ioutil.WriteFile(schema, []byte(`<xs:schema version="1.3"/>`), 0644))
+	err = lock.Verify("http://example.com/b.xsd", schema)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch for http://example.com/b.xsd")
+	resolver := &LockedResolver{Resolver: &SearchPathResolver{Paths: []string{dir}}, Lock: lock}
+	path, err := resolver.Resolve("http://example.com/b.xsd")
+	assert.Error(t, err)
+	assert.Empty(t, path)
+
+	lock.Update = true
+	path, err = resolver.Resolve("http://example.com/b.xsd")
+	assert.NoError(t, err)
+	assert.Equal(t, schema, path)
+	assert.Equal(t, "1.3", lock.entry("http://example.com/b.xsd").Version)
+	assert.NoError(t, lock.Save(lockFile))
+	_, err = os.Stat(lockFile)
+	assert.NoError(t, err)
+}
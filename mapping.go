@@ -0,0 +1,139 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeMapping holds the existing type in the user's codebase which a schema
+// simple or complex type maps to, and the import required for using it. For
+// example, map MoneyType to money.Amount with the import
+// github.com/acme/money in Go, or to Money with the import com.acme.Money in
+// Java. No definition will be generated for the mapped schema type.
+type TypeMapping struct {
+	Type   string
+	Import string
+}
+
+// ParseTypeMapping parses the type mapping in the form of
+// XSDType=Type[,Import], and returns the schema type name and the mapping.
+func ParseTypeMapping(value string) (name string, mapping TypeMapping, err error) {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+		err = fmt.Errorf("invalid type mapping %q, expected XSDType=Type[,Import]", value)
+		return
+	}
+	name = kv[0]
+	target := strings.SplitN(kv[1], ",", 2)
+	mapping.Type = target[0]
+	if len(target) == 2 {
+		mapping.Import = target[1]
+	}
+	return
+}
+
+// isMappedType reports whether the given proto tree node is mapped to an
+// existing type, its definition should be skipped in the generated code.
+func (gen *CodeGenerator) isMappedType(ele interface{}) bool {
+	var name string
+	switch v := ele.(type) {
+	case *SimpleType:
+		name = v.Name
+	case *ComplexType:
+		name = v.Name
+	case *Group:
+		name = v.Name
+	case *AttributeGroup:
+		name = v.Name
+	case *Element:
+		name = v.Name
+	case *Attribute:
+		name = v.Name
+	}
+	_, ok := gen.TypeMapping[trimNSPrefix(name)]
+	return ok
+}
+
+// getBaseType returns the base type of the given type by the targets resolved
+// from the proto tree, the type name will be kept if it's mapped to an
+// existing type.
+func (gen *CodeGenerator) getBaseType(name string) string {
+	name = trimNSPrefix(name)
+	if _, ok := gen.TypeMapping[name]; ok {
+		return name
+	}
+	if gen.Targets == nil {
+		gen.Targets = resolveTargets(gen.ProtoTree)
+	}
+	return resolveTarget(name, gen.Targets)
+}
+
+// getSimpleType returns the simple type declared with the given name in the
+// proto tree, or nil if there isn't one.
+func (gen *CodeGenerator) getSimpleType(name string) *SimpleType {
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*SimpleType); ok && v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// getSimpleBaseType returns the built-in type which the simple type by given
+// name is derived from, or the base type of the name if it isn't a simple
+// type.
+func (gen *CodeGenerator) getSimpleBaseType(name string) string {
+	name = gen.getBaseType(name)
+	visited := map[string]bool{}
+	for simpleType := gen.getSimpleType(name); simpleType != nil && simpleType.Base != "" && !visited[name]; simpleType = gen.getSimpleType(name) {
+		visited[name] = true
+		name = gen.getBaseType(simpleType.Base)
+	}
+	return name
+}
+
+// getMappedType returns the existing type for the given schema type if it's
+// mapped, and records the mapping to generate the import for it.
+func (gen *CodeGenerator) getMappedType(name string) (string, bool) {
+	mapping, ok := gen.TypeMapping[name]
+	if !ok {
+		return "", false
+	}
+	if gen.ImportMapping == nil {
+		gen.ImportMapping = map[string]bool{}
+	}
+	gen.ImportMapping[name] = true
+	return mapping.Type, true
+}
+
+// getImportMappings returns the mappings which are referenced in generated
+// code and require an import, in a stable order.
+func (gen *CodeGenerator) getImportMappings() (mappings []TypeMapping) {
+	var names []string
+	for name := range gen.ImportMapping {
+		if gen.TypeMapping[name].Import != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	imported := map[string]bool{}
+	for _, name := range names {
+		mapping := gen.TypeMapping[name]
+		key := mapping.Type + "\x00" + mapping.Import
+		if imported[key] {
+			continue
+		}
+		imported[key] = true
+		mappings = append(mappings, mapping)
+	}
+	return
+}
@@ -0,0 +1,182 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// goStructField holds the name and type of a field in the generated Go
+// struct.
+type goStructField struct {
+	Name string
+	Type string
+}
+
+// goStructFields returns the fields of the Go struct generated for the
+// complex type, group or attribute group, in the same way as GoComplexType,
+// GoGroup and GoAttributeGroup.
+func (gen *CodeGenerator) goStructFields(ele interface{}) (name string, fields []goStructField, ok bool) {
+	plural := func(p bool) string {
+		if p {
+			return "[]"
+		}
+		return ""
+	}
+	switch v := ele.(type) {
+	case *ComplexType:
+		for _, attrGroup := range v.AttributeGroup {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(attrGroup.Name)), gen.genGoFieldType(gen.getBaseType(attrGroup.Ref))})
+		}
+		for _, attribute := range v.Attributes {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(attribute.Name) + "Attr"), gen.genGoFieldType(gen.getBaseType(attribute.Type))})
+		}
+		for _, group := range v.Groups {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(group.Name)), plural(group.Plural) + gen.genGoFieldType(gen.getBaseType(group.Ref))})
+		}
+		for _, element := range v.Elements {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(element.Name)), plural(element.Plural) + gen.genGoFieldType(gen.getBaseType(element.Type))})
+		}
+		return v.Name, fields, true
+	case *Group:
+		for _, element := range v.Elements {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(element.Name)), plural(element.Plural) + gen.genGoFieldType(gen.getBaseType(element.Type))})
+		}
+		for _, group := range v.Groups {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(group.Name)), plural(group.Plural) + gen.genGoFieldType(gen.getBaseType(group.Ref))})
+		}
+		return v.Name, fields, true
+	case *AttributeGroup:
+		for _, attribute := range v.Attributes {
+			fields = append(fields, goStructField{gen.fieldName(genGoFieldName(attribute.Name) + "Attr"), gen.genGoFieldType(gen.getBaseType(attribute.Type))})
+		}
+		return v.Name, fields, true
+	}
+	return
+}
+
+// goStructs returns the fields of the Go structs generated for the proto
+// tree by type names, and the type names in the order of the proto tree.
+func (gen *CodeGenerator) goStructs() (structs map[string][]goStructField, names []string) {
+	structs = map[string][]goStructField{}
+	for _, ele := range gen.ProtoTree {
+		name, fields, ok := gen.goStructFields(ele)
+		if !ok {
+			continue
+		}
+		typeName := gen.typeName(genGoFieldName(name))
+		if _, ok := structs[typeName]; ok {
+			continue
+		}
+		structs[typeName] = fields
+		names = append(names, typeName)
+	}
+	return
+}
+
+// GenGoMigration generates Go conversion functions from the structs generated
+// for the old version of schema to the structs of the new version in the
+// proto tree, as the starting point of upgrading to the new schema release.
+// The generated code imports the packages of the old and new version by given
+// import paths, and the TODO markers are left where the fields or types were
+// added, removed or retyped.
+func (gen *CodeGenerator) GenGoMigration(oldProtoTree []interface{}, oldImport, newImport string) error {
+	prev := &CodeGenerator{
+		Lang:        gen.Lang,
+		Naming:      gen.Naming,
+		Escape:      gen.Escape,
+		TypeMapping: gen.TypeMapping,
+		ProtoTree:   oldProtoTree,
+	}
+	oldStructs, oldNames := prev.goStructs()
+	newStructs, newNames := gen.goStructs()
+
+	var content, importPackage string
+	for _, name := range oldNames {
+		if _, ok := newStructs[name]; !ok {
+			content += fmt.Sprintf("// TODO: %s is removed in the new schema version.\n\n", name)
+		}
+	}
+	for _, name := range newNames {
+		oldFields, ok := oldStructs[name]
+		if !ok {
+			content += fmt.Sprintf("// TODO: %s is added in the new schema version.\n\n", name)
+			continue
+		}
+		content += gen.genGoConvertFunc(name, oldFields, newStructs[name], oldStructs, newStructs)
+		importPackage = fmt.Sprintf("import (\n\tprev %q\n\tnext %q\n)\n\n", oldImport, newImport)
+	}
+
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "migration"
+	}
+	code := fmt.Sprintf("%s\n\npackage %s\n\n%s%s", copyright, packageName, importPackage, content)
+	source, err := format.Source([]byte(code))
+	if err != nil {
+		gen.writeFile(gen.File+"_migration.go", []byte(code))
+		return err
+	}
+	return gen.writeFile(gen.File+"_migration.go", source)
+}
+
+// genGoConvertFunc generates the conversion function for the struct which
+// exists in both versions of schema.
+func (gen *CodeGenerator) genGoConvertFunc(name string, oldFields, newFields []goStructField, oldStructs, newStructs map[string][]goStructField) string {
+	oldTypes := map[string]string{}
+	for _, field := range oldFields {
+		oldTypes[field.Name] = field.Type
+	}
+	content := fmt.Sprintf("// Convert%s converts %s from the old schema version to the new one.\nfunc Convert%s(in *prev.%s) *next.%s {\n\tif in == nil {\n\t\treturn nil\n\t}\n\tout := &next.%s{}\n", name, name, name, name, name, name)
+	newTypes := map[string]bool{}
+	for _, field := range newFields {
+		newTypes[field.Name] = true
+		oldType, ok := oldTypes[field.Name]
+		if !ok {
+			content += fmt.Sprintf("\t// TODO: field %s %s is added.\n", field.Name, field.Type)
+			continue
+		}
+		if oldType != field.Type {
+			content += fmt.Sprintf("\t// TODO: field %s is retyped from %s to %s.\n", field.Name, oldType, field.Type)
+			continue
+		}
+		elemType := strings.TrimPrefix(field.Type, "[]")
+		if !strings.HasPrefix(elemType, "*") || elemType == "*" {
+			content += fmt.Sprintf("\tout.%s = in.%s\n", field.Name, field.Name)
+			continue
+		}
+		typeName := strings.TrimPrefix(elemType, "*")
+		_, isOldStruct := oldStructs[typeName]
+		_, isNewStruct := newStructs[typeName]
+		convert := fmt.Sprintf("(*next.%s)", typeName)
+		if isOldStruct && isNewStruct {
+			convert = "Convert" + typeName
+		}
+		if strings.HasPrefix(field.Type, "[]") {
+			content += fmt.Sprintf("\tfor _, v := range in.%s {\n\t\tout.%s = append(out.%s, %s(v))\n\t}\n", field.Name, field.Name, field.Name, convert)
+			continue
+		}
+		content += fmt.Sprintf("\tout.%s = %s(in.%s)\n", field.Name, convert, field.Name)
+	}
+	var removed []string
+	for fieldName := range oldTypes {
+		if !newTypes[fieldName] {
+			removed = append(removed, fieldName)
+		}
+	}
+	sort.Strings(removed)
+	for _, fieldName := range removed {
+		content += fmt.Sprintf("\t// TODO: field %s %s is removed.\n", fieldName, oldTypes[fieldName])
+	}
+	content += "\treturn out\n}\n\n"
+	return content
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// xsdNamespace is the namespace of the XML Schema.
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// nsPackageName returns the Go package name for the target namespace, which
+// is derived from the last non-version segment of the namespace URI.
+func nsPackageName(ns string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, nsFromURI(ns))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "ns" + name
+	}
+	return name
+}
+
+// GoModulePath returns the import path of the given directory, which is
+// derived from the module path declared in the go.mod file of the directory
+// or the nearest parent directory.
+func GoModulePath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	dir, rel := abs, ""
+	for {
+		if data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "module" {
+					return path.Join(strings.Trim(fields[1], "\"`"), rel), nil
+				}
+			}
+			return "", fmt.Errorf("no module path declared in %s", filepath.Join(dir, "go.mod"))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found for %s", abs)
+		}
+		rel, dir = path.Join(filepath.Base(dir), rel), parent
+	}
+}
+
+// recordForeignType records the type referenced by the given value if it's
+// defined in the namespace other than the target namespace of the schema.
+func (opt *Options) recordForeignType(value string) {
+	ns := opt.parseNS(value)
+	if ns == "" || ns == xsdNamespace || ns == opt.TargetNamespace {
+		return
+	}
+	if _, ok := getBuildInTypeByLang(trimNSPrefix(value), opt.Lang); ok {
+		return
+	}
+	if opt.ForeignTypes == nil {
+		opt.ForeignTypes = map[string]string{}
+	}
+	opt.ForeignTypes[trimNSPrefix(value)] = ns
+}
+
+// namespaceTypeMapping returns the type mappings which qualify the types in
+// other namespaces with the packages generated for the namespaces, the
+// packages are imported under the given module path. The types defined in the
+// schema take precedence over the types with the same name in other
+// namespaces.
+func (gen *CodeGenerator) namespaceTypeMapping(foreignTypes map[string]string, modulePath string) map[string]TypeMapping {
+	if len(foreignTypes) == 0 {
+		return gen.TypeMapping
+	}
+	typeMapping, local := map[string]TypeMapping{}, map[string]bool{}
+	for name, mapping := range gen.TypeMapping {
+		typeMapping[name] = mapping
+	}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			local[v.Name] = true
+		case *ComplexType:
+			local[v.Name] = true
+		}
+	}
+	for name, ns := range foreignTypes {
+		if _, ok := typeMapping[name]; ok || local[name] {
+			continue
+		}
+		pkg := nsPackageName(ns)
+		typeMapping[name] = TypeMapping{
+			Type:   "*" + pkg + "." + gen.typeName(genGoFieldName(name)),
+			Import: path.Join(modulePath, pkg),
+		}
+	}
+	return typeMapping
+}
+
+// foreignStructs returns the names of the types in other namespaces which are
+// declared as the complex types in the parsed schemas, the structs of them
+// are generated in the packages of the namespaces.
+func (opt *Options) foreignStructs() map[string]bool {
+	structs := map[string]bool{}
+	for _, tree := range opt.ParseFileMap {
+		for _, ele := range tree {
+			if v, ok := ele.(*ComplexType); ok && v.Namespace != "" && opt.ForeignTypes[v.Name] == v.Namespace {
+				structs[v.Name] = true
+			}
+		}
+	}
+	return structs
+}
+
+// markNamespaces records the target namespace of the schema and the prefix
+// bound to it in the global declarations of the schema.
+func (opt *Options) markNamespaces() {
+	if opt.TargetNamespace == "" {
+		return
+	}
+	prefix := opt.declaredPrefix(opt.TargetNamespace)
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			v.Namespace, v.Prefix = opt.TargetNamespace, prefix
+		case *ComplexType:
+			v.Namespace, v.Prefix = opt.TargetNamespace, prefix
+		case *Element:
+			v.Namespace, v.Prefix = opt.TargetNamespace, prefix
+		case *Attribute:
+			v.Namespace, v.Prefix = opt.TargetNamespace, prefix
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Naming conventions could be applied on the identifiers and file names of
+// the generated code.
+const (
+	PascalCase         = "PascalCase"
+	CamelCase          = "camelCase"
+	SnakeCase          = "snake_case"
+	ScreamingSnakeCase = "SCREAMING_SNAKE_CASE"
+)
+
+// NamingConvention holds the naming conventions for the type names, field
+// names, file names, constants and the keys of the struct tags of the
+// generated code. An empty value means using the default convention of the
+// target language, the keys of the struct tags are the names in the schema by
+// default.
+type NamingConvention struct {
+	Type     string
+	Field    string
+	File     string
+	Constant string
+	Tag      string
+}
+
+// IsValidNamingConvention reports whether the given value is a supported
+// naming convention, an empty value is valid and means the language default.
+func IsValidNamingConvention(convention string) bool {
+	switch convention {
+	case "", PascalCase, CamelCase, SnakeCase, ScreamingSnakeCase:
+		return true
+	}
+	return false
+}
+
+// splitWords splits identifier into words on case boundaries, digits are kept
+// with the preceding word and any other non-letter characters are treated as
+// separators.
+func splitWords(name string) (words []string) {
+	runes := []rune(name)
+	var word []rune
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return
+}
+
+// ConvertCase converts the identifier to the given naming convention, the
+// identifier will be returned as is with an empty or unknown convention.
+func ConvertCase(name, convention string) string {
+	if convention == "" || !IsValidNamingConvention(convention) {
+		return name
+	}
+	words := splitWords(name)
+	for i, word := range words {
+		switch convention {
+		case PascalCase:
+			words[i] = MakeFirstUpperCase(strings.ToLower(word))
+		case CamelCase:
+			if i == 0 {
+				words[i] = strings.ToLower(word)
+				continue
+			}
+			words[i] = MakeFirstUpperCase(strings.ToLower(word))
+		case SnakeCase:
+			words[i] = strings.ToLower(word)
+		case ScreamingSnakeCase:
+			words[i] = strings.ToUpper(word)
+		}
+	}
+	if convention == SnakeCase || convention == ScreamingSnakeCase {
+		return strings.Join(words, "_")
+	}
+	return strings.Join(words, "")
+}
+
+// convertFileName converts the base name of the given file path to the naming
+// convention, the extensions of the file are kept unchanged.
+func convertFileName(path, convention string) string {
+	if convention == "" {
+		return path
+	}
+	dir, base := filepath.Split(path)
+	var ext string
+	if idx := strings.Index(base, "."); idx > 0 {
+		base, ext = base[:idx], base[idx:]
+	}
+	return filepath.Join(dir, ConvertCase(base, convention)+ext)
+}
+
+// typeName applies the type naming convention on the type name derived by
+// the language generator, and escapes it if collides with reserved words. The
+// type collided with the type in other namespace or renamed by the directive
+// will be renamed.
+func (gen *CodeGenerator) typeName(name string) string {
+	if renamed, ok := gen.Renamed[name]; ok {
+		name = renamed
+	}
+	return gen.escapeReservedWord(ConvertCase(name, gen.Naming.Type))
+}
+
+// fieldName applies the field naming convention on the field name derived by
+// the language generator, and escapes it if collides with reserved words.
+func (gen *CodeGenerator) fieldName(name string) string {
+	return gen.escapeReservedWord(ConvertCase(name, gen.Naming.Field))
+}
+
+// constantName applies the constant naming convention on the constant name
+// derived by the language generator, and escapes it if collides with reserved
+// words.
+func (gen *CodeGenerator) constantName(name string) string {
+	return gen.escapeReservedWord(ConvertCase(name, gen.Naming.Constant))
+}
+
+// enumNames derives the PascalCase identifiers from the values of the
+// enumeration, the characters other than letters and digits are dropped. The
+// empty identifier is named Empty, the identifier starts with a digit is
+// prefixed with Value, and the duplicated identifiers are suffixed with the
+// sequence numbers.
+func enumNames(values []string) []string {
+	names, seen := make([]string, len(values)), map[string]bool{}
+	for i, value := range values {
+		var base string
+		for _, word := range splitWords(value) {
+			base += MakeFirstUpperCase(word)
+		}
+		if base == "" {
+			base = "Empty"
+		}
+		if unicode.IsDigit([]rune(base)[0]) {
+			base = "Value" + base
+		}
+		name := base
+		for seq := 2; seen[name]; seq++ {
+			name = base + strconv.Itoa(seq)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertCase(t *testing.T) {
+	for _, c := range []struct {
+		name, convention, expected string
+	}{
+		{"OTA_AirLowFareSearchRQ", PascalCase, "OtaAirLowFareSearchRq"},
+		{"XMLName", CamelCase, "xmlName"},
+		{"myType2", SnakeCase, "my_type2"},
+		{"base64-binary", ScreamingSnakeCase, "BASE64_BINARY"},
+		{"keepAsIs", "", "keepAsIs"},
+	} {
+		assert.Equal(t, c.expected, ConvertCase(c.name, c.convention), c.name)
+	}
+	assert.Equal(t, "out/my_schema.xsd", convertFileName("out/MySchema.xsd", SnakeCase))
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// declareBeforeUse defines the languages which require the types to be
+// declared before they are used.
+var declareBeforeUse = map[string]bool{
+	"C": true,
+}
+
+// sortBySchemaOrder sorts the proto tree in the schema document order by the
+// ordinals of the top-level declarations in the schema, the types derived
+// from the same declaration keep the order encountered by the parser.
+func sortBySchemaOrder(protoTree []interface{}, ordinals map[interface{}]int) {
+	sort.SliceStable(protoTree, func(i, j int) bool {
+		return ordinals[protoTree[i]] < ordinals[protoTree[j]]
+	})
+}
+
+// getDependencies returns the names of types which are referenced by the
+// given proto tree node.
+func getDependencies(ele interface{}) (deps []string) {
+	switch v := ele.(type) {
+	case *SimpleType:
+		deps = append(deps, v.Base)
+		for _, member := range v.MemberTypes {
+			deps = append(deps, member.Name)
+		}
+	case *ComplexType:
+		deps = append(deps, v.Base)
+		for _, attrGroup := range v.AttributeGroup {
+			deps = append(deps, attrGroup.Ref)
+		}
+		for _, attribute := range v.Attributes {
+			deps = append(deps, attribute.Type)
+		}
+		for _, group := range v.Groups {
+			deps = append(deps, group.Ref)
+		}
+		for _, element := range v.Elements {
+			deps = append(deps, element.Type)
+		}
+	case *Group:
+		for _, group := range v.Groups {
+			deps = append(deps, group.Ref)
+		}
+		for _, element := range v.Elements {
+			deps = append(deps, element.Type)
+		}
+	case *AttributeGroup:
+		for _, attribute := range v.Attributes {
+			deps = append(deps, attribute.Type)
+		}
+	case *Element:
+		deps = append(deps, v.Type)
+	case *Attribute:
+		deps = append(deps, v.Type)
+	}
+	return
+}
+
+// orderDependencies moves the types before the types which depend on them,
+// and keeps the order of the proto tree otherwise. Circular dependencies are
+// kept in their original order.
+func orderDependencies(protoTree []interface{}) []interface{} {
+	definitions := map[string][]interface{}{}
+	for _, ele := range protoTree {
+		var name string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name = v.Name
+		case *ComplexType:
+			name = v.Name
+		case *Group:
+			name = v.Name
+		case *AttributeGroup:
+			name = v.Name
+		}
+		if name != "" {
+			definitions[name] = append(definitions[name], ele)
+		}
+	}
+	ordered := make([]interface{}, 0, len(protoTree))
+	visited := map[interface{}]bool{}
+	var visit func(ele interface{})
+	visit = func(ele interface{}) {
+		if visited[ele] {
+			return
+		}
+		visited[ele] = true
+		for _, dep := range getDependencies(ele) {
+			for _, def := range definitions[trimNSPrefix(dep)] {
+				visit(def)
+			}
+		}
+		ordered = append(ordered, ele)
+	}
+	for _, ele := range protoTree {
+		visit(ele)
+	}
+	return ordered
+}
+
+// filterRoots returns the global declarations in the proto tree matching the
+// given root names, and the types which they depend on transitively. The
+// namespace of root name is compared with the target namespace of the schema,
+// an empty namespace matches any.
+func filterRoots(protoTree []interface{}, roots []xml.Name, targetNamespace string) []interface{} {
+	declarations := map[string][]interface{}{}
+	for _, ele := range protoTree {
+		var name string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name = v.Name
+		case *ComplexType:
+			name = v.Name
+		case *Group:
+			name = v.Name
+		case *AttributeGroup:
+			name = v.Name
+		case *Element:
+			name = v.Name
+		case *Attribute:
+			name = v.Name
+		}
+		if name != "" {
+			declarations[name] = append(declarations[name], ele)
+		}
+	}
+	required := map[interface{}]bool{}
+	var require func(name string)
+	require = func(name string) {
+		for _, ele := range declarations[trimNSPrefix(name)] {
+			if required[ele] {
+				continue
+			}
+			required[ele] = true
+			for _, dep := range getDependencies(ele) {
+				require(dep)
+			}
+		}
+	}
+	for _, root := range roots {
+		if root.Space == "" || root.Space == targetNamespace {
+			require(root.Local)
+		}
+	}
+	filtered := make([]interface{}, 0, len(required))
+	for _, ele := range protoTree {
+		if required[ele] {
+			filtered = append(filtered, ele)
+		}
+	}
+	return filtered
+}
@@ -9,8 +9,10 @@
 package xgen
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -24,11 +26,49 @@ import (
 type Options struct {
 	FilePath            string
 	FileDir             string
+	BaseURI             string
 	InputDir            string
 	OutputDir           string
 	Extract             bool
 	Lang                string
 	Package             string
+	CMake               bool
+	Zod                 bool
+	Lxml                bool
+	TargetFramework     string
+	OptionalPointers    bool
+	StructTags          []string
+	SplitFiles          bool
+	BigNumbers          bool
+	DeepCopy            bool
+	Stringer            bool
+	DecodeEach          bool
+	Naming              NamingConvention
+	Escape              Escape
+	Escaped             map[string]string
+	Cycles              []string
+	TypeMapping         map[string]TypeMapping
+	Collision           string
+	TypeNamespaces      map[string]string
+	TargetNamespace     string
+	SkipWrappers        bool
+	SchemaOrder         bool
+	Roots               []xml.Name
+	Directives          map[string]*Directive
+	PackagePerNamespace bool
+	ModulePath          string
+	EmbedSource         bool
+	SourceLocation      bool
+	DeprecationMarker   string
+	EmitIR              bool
+	Fixtures            bool
+	KeepGoing           bool
+	XSD11               bool
+	Failures            *FailureLog
+	Sources             map[string][]byte
+	Outputs             map[string][]byte
+	Resolver            SchemaResolver
+	ForeignTypes        map[string]string
 	IncludeMap          map[string]bool
 	LocalNameNSMap      map[string]string
 	NSSchemaLocationMap map[string]string
@@ -42,6 +82,22 @@ type Options struct {
 	InGroup          int
 	InUnion          bool
 	InAttributeGroup bool
+	InAppinfo        bool
+	InDirective      string
+	Declarations     []string
+	failed           map[string]string
+	facets           *Restriction
+	openElements     []*Element
+	included         []interface{}
+	chain            []string
+	elemQualified    bool
+	attrQualified    bool
+	appinfo          map[string][]string
+	payload          *appinfoPayload
+	xsd11            bool
+	openContent      string
+	chameleon        string
+	chameleons       map[string]bool
 
 	SimpleType     *Stack
 	ComplexType    *Stack
@@ -49,6 +105,7 @@ type Options struct {
 	Attribute      *Stack
 	Group          *Stack
 	AttributeGroup *Stack
+	Choice         *Stack
 }
 
 // NewParser creates a new parser options for the Parse. Useful for XML schema
@@ -62,17 +119,12 @@ func NewParser(options *Options) *Options {
 // parse will fetch schema used in <import> or <include> statements.
 func (opt *Options) Parse() (err error) {
 	opt.FileDir = filepath.Dir(opt.FilePath)
-	var fi os.FileInfo
-	fi, err = os.Stat(opt.FilePath)
-	if err != nil {
+	var isDir bool
+	if isDir, err = opt.statSchema(opt.FilePath); err != nil || isDir {
 		return
 	}
-	if fi.IsDir() {
-		return
-	}
-	var xmlFile *os.File
-	xmlFile, err = os.Open(opt.FilePath)
-	if err != nil {
+	var xmlFile io.ReadCloser
+	if xmlFile, err = opt.openSchema(opt.FilePath); err != nil {
 		return
 	}
 	if !opt.Extract {
@@ -86,6 +138,24 @@ func (opt *Options) Parse() (err error) {
 	opt.InGroup = 0
 	opt.InUnion = false
 	opt.InAttributeGroup = false
+	opt.InAppinfo = false
+	opt.InDirective = ""
+	opt.Declarations = nil
+	opt.ForeignTypes = nil
+	opt.failed = nil
+	opt.facets = nil
+	opt.openElements = nil
+	opt.included = nil
+	opt.Cycles = nil
+	opt.elemQualified, opt.attrQualified = false, false
+	opt.appinfo, opt.payload = nil, nil
+	opt.xsd11, opt.openContent = opt.XSD11, ""
+	if opt.chameleons == nil {
+		opt.chameleons = map[string]bool{}
+	}
+	if opt.KeepGoing && opt.Failures == nil {
+		opt.Failures = &FailureLog{}
+	}
 
 	opt.SimpleType = NewStack()
 	opt.ComplexType = NewStack()
@@ -93,10 +163,22 @@ func (opt *Options) Parse() (err error) {
 	opt.Attribute = NewStack()
 	opt.Group = NewStack()
 	opt.AttributeGroup = NewStack()
+	opt.Choice = NewStack()
 
-	decoder := xml.NewDecoder(xmlFile)
+	var depth, declaration, ordered, sourceStart, sourceTree int
+	var lineOffset int64
+	var sourceName, location, declarationName string
+	line := 1
+	var source bytes.Buffer
+	ordinals := map[interface{}]int{}
+	var reader io.Reader = xmlFile
+	if opt.EmbedSource || opt.SourceLocation {
+		reader = io.TeeReader(xmlFile, &source)
+	}
+	decoder := xml.NewDecoder(reader)
 	decoder.CharsetReader = charset.NewReaderLabel
 	for {
+		offset := decoder.InputOffset()
 		token, _ := decoder.Token()
 		if token == nil {
 			break
@@ -104,21 +186,95 @@ func (opt *Options) Parse() (err error) {
 
 		switch element := token.(type) {
 		case xml.StartElement:
-
+			if opt.excluded(element) {
+				_ = decoder.Skip()
+				continue
+			}
+			if depth++; depth == 2 {
+				declaration++
+				declarationName = ""
+				for _, attr := range element.Attr {
+					if attr.Name.Local == "name" {
+						declarationName = attr.Value
+					}
+				}
+				if opt.SourceLocation {
+					line += bytes.Count(source.Bytes()[lineOffset:offset], []byte{'\n'})
+					lineOffset, location = offset, fmt.Sprintf("%s:%d", opt.sourceFile(), line)
+				}
+				if opt.EmbedSource || opt.SourceLocation {
+					sourceStart, sourceTree = bytes.LastIndexByte(source.Bytes()[:offset], '\n')+1, len(opt.ProtoTree)
+					sourceName = ""
+					for _, attr := range element.Attr {
+						if attr.Name.Local == "name" {
+							sourceName = attr.Value
+						}
+					}
+				}
+			}
+			if opt.InAppinfo && element.Name.Space == DirectiveNamespace {
+				opt.onDirective(element)
+				continue
+			}
+			if opt.payload != nil {
+				opt.payload.depth++
+				opt.payload.encode(element)
+				continue
+			}
+			opt.enterDeclaration(element)
 			opt.InElement = element.Name.Local
 			funcName := fmt.Sprintf("On%s", MakeFirstUpperCase(opt.InElement))
 			if err = callFuncByName(opt, funcName, []reflect.Value{reflect.ValueOf(element), reflect.ValueOf(opt.ProtoTree)}); err != nil {
-				return
+				if !opt.failDeclaration(declarationName, err) {
+					return
+				}
+				err = nil
+			}
+			if opaqueElements[opt.InElement] {
+				depth--
+				_ = decoder.Skip()
 			}
 
 		case xml.EndElement:
+			depth--
+			if opt.InAppinfo && element.Name.Space == DirectiveNamespace {
+				opt.InDirective = ""
+				continue
+			}
+			if opt.payload != nil && opt.payload.depth > 0 {
+				opt.payload.depth--
+				opt.payload.encode(element)
+				continue
+			}
 			funcName := fmt.Sprintf("End%s", MakeFirstUpperCase(element.Name.Local))
 			if err = callFuncByName(opt, funcName, []reflect.Value{reflect.ValueOf(element), reflect.ValueOf(opt.ProtoTree)}); err != nil {
-				return
+				if !opt.failDeclaration(declarationName, err) {
+					return
+				}
+				err = nil
+			}
+			opt.leaveDeclaration(element)
+			if depth == 1 && sourceName != "" {
+				var fragment string
+				if opt.EmbedSource {
+					fragment = trimSource(string(source.Bytes()[sourceStart:decoder.InputOffset()]))
+				}
+				setSource(opt.ProtoTree[sourceTree:], sourceName, fragment, location)
 			}
+			for _, ele := range opt.ProtoTree[ordered:] {
+				ordinals[ele] = declaration
+			}
+			ordered = len(opt.ProtoTree)
 		case xml.CharData:
+			if opt.payload != nil && opt.InDirective == "" {
+				opt.payload.encode(element.Copy())
+				continue
+			}
 			if err = opt.OnCharData(string(element), opt.ProtoTree); err != nil {
-				return
+				if !opt.failDeclaration(declarationName, err) {
+					return
+				}
+				err = nil
 			}
 		default:
 		}
@@ -126,33 +282,136 @@ func (opt *Options) Parse() (err error) {
 	}
 	defer xmlFile.Close()
 
+	renamed, typeMapping := opt.applyDirectives()
+	opt.markDeprecated()
+	opt.markNamespaces()
+	opt.markAppinfo()
+	if opt.Roots != nil {
+		opt.ProtoTree = filterRoots(opt.ProtoTree, opt.Roots, opt.TargetNamespace)
+	}
+	if opt.SchemaOrder {
+		sortBySchemaOrder(opt.ProtoTree, ordinals)
+		if declareBeforeUse[opt.Lang] {
+			opt.ProtoTree = orderDependencies(opt.ProtoTree)
+		}
+	}
+	ir := NewIR(opt.ProtoTree, opt.TargetNamespace)
+	opt.ProtoTree = ir.ProtoTree()
+
 	if !opt.Extract {
 		opt.ParseFileList[opt.FilePath] = true
 		opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
-		path := filepath.Join(opt.OutputDir, strings.TrimPrefix(opt.FilePath, opt.InputDir))
-		if err := PrepareOutputDir(filepath.Dir(path)); err != nil {
-			fmt.Println(err)
-			os.Exit(1)
+		path, packageName := convertFileName(filepath.Join(opt.OutputDir, strings.TrimPrefix(opt.FilePath, opt.InputDir)), opt.Naming.File), opt.Package
+		if opt.PackagePerNamespace && opt.Lang == "Go" && opt.TargetNamespace != "" {
+			packageName = nsPackageName(opt.TargetNamespace)
+			path = filepath.Join(opt.OutputDir, packageName, filepath.Base(path))
+		}
+		if opt.Outputs == nil {
+			if err := PrepareOutputDir(filepath.Dir(path)); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
 		}
 		generator := &CodeGenerator{
-			Lang:      opt.Lang,
-			Package:   opt.Package,
-			File:      path,
-			ProtoTree: opt.ProtoTree,
-			StructAST: map[string]string{},
+			Lang:             opt.Lang,
+			Package:          packageName,
+			CMake:            opt.CMake,
+			Zod:              opt.Zod,
+			Lxml:             opt.Lxml,
+			TargetFramework:  opt.TargetFramework,
+			OptionalPointers: opt.OptionalPointers,
+			StructTags:       opt.StructTags,
+			SplitFiles:       opt.SplitFiles,
+			DeepCopy:         opt.DeepCopy,
+			Stringer:         opt.Stringer,
+			DecodeEach:       opt.DecodeEach,
+			Naming:           opt.Naming,
+			Escape:           opt.Escape,
+			TypeMapping:      typeMapping,
+			SkipWrappers:     opt.SkipWrappers,
+			File:             path,
+			ProtoTree:        opt.ProtoTree,
+			Targets:          ir.Targets,
+			TargetNamespace:  opt.TargetNamespace,
+			StructAST:        map[string]string{},
+			Failures:         opt.Failures,
+			Failed:           opt.failed,
+			Outputs:          opt.Outputs,
+		}
+		if opt.PackagePerNamespace && opt.Lang == "Go" {
+			generator.TypeMapping = generator.namespaceTypeMapping(opt.ForeignTypes, opt.ModulePath)
+			generator.goForeignStructs = opt.foreignStructs()
+		}
+		generator.renameTypes(opt.resolveCollisions(), renamed)
+		if opt.EmitIR {
+			var data []byte
+			if data, err = ir.Encode(); err != nil {
+				return
+			}
+			if err = generator.writeFile(path+".ir.json", data); err != nil {
+				return
+			}
 		}
 		funcName := fmt.Sprintf("Gen%s", MakeFirstUpperCase(opt.Lang))
-		if err = callFuncByName(generator, funcName, []reflect.Value{}); err != nil {
+		err = callFuncByName(generator, funcName, []reflect.Value{})
+		opt.Escaped = generator.Escaped
+		if err != nil {
 			return
 		}
+		if opt.Fixtures {
+			err = generator.genFixtures(ir)
+		}
 	}
 	return
 }
 
+// GenerateFor parses the XSD document by given options, and generates code
+// in given language only for the global elements or types with the root
+// names and their transitive dependencies, the file is self-contained for the
+// roots defined in the schema. For example, generate code for the order
+// element in Go:
+//
+//	err := parser.GenerateFor([]xml.Name{{Space: "urn:example:order", Local: "order"}}, "Go")
+func (opt *Options) GenerateFor(rootQNames []xml.Name, lang string) (err error) {
+	if len(rootQNames) == 0 {
+		err = fmt.Errorf("no root names specified")
+		return
+	}
+	opt.Roots, opt.Lang, opt.Extract = rootQNames, lang, false
+	return opt.Parse()
+}
+
+// buildInType returns the built-in type of the language which the XSD data
+// type by given name maps to, the unbounded integers and decimals map to the
+// arbitrary-precision types in Go with the big numbers.
+func (opt *Options) buildInType(value string) (string, bool) {
+	if buildType, ok := goBigNumberTypes[value]; ok && opt.BigNumbers && opt.Lang == "Go" {
+		return buildType, true
+	}
+	return getBuildInTypeByLang(value, opt.Lang)
+}
+
 // GetValueType convert XSD schema value type to the build-in type for the
 // given value and proto tree.
 func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueType string, err error) {
-	if buildType, ok := getBuildInTypeByLang(trimNSPrefix(value), opt.Lang); ok {
+	defer func() {
+		// the unresolvable reference is replaced by the placeholder type.
+		if err != nil && opt.failDeclaration(value, err) {
+			valueType, err = trimNSPrefix(value), nil
+		}
+	}()
+	if opt.PackagePerNamespace {
+		defer func() {
+			if valueType == trimNSPrefix(value) {
+				opt.recordForeignType(value)
+			}
+		}()
+	}
+	if _, ok := opt.TypeMapping[trimNSPrefix(value)]; ok {
+		valueType = trimNSPrefix(value)
+		return
+	}
+	if buildType, ok := opt.buildInType(trimNSPrefix(value)); ok {
 		valueType = buildType
 		return
 	}
@@ -160,35 +419,39 @@ func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueTy
 	if valueType != trimNSPrefix(value) && valueType != "" {
 		return
 	}
-	if opt.Extract {
+	// the declarations of the included schemas are in the same namespace.
+	if valueType = getBasefromSimpleType(trimNSPrefix(value), opt.included); valueType != trimNSPrefix(value) && valueType != "" {
 		return
 	}
-	schemaLocation := opt.NSSchemaLocationMap[opt.parseNS(value)]
-	if isValidURL(schemaLocation) {
+	if opt.Extract {
 		return
 	}
-	xsdFile := filepath.Join(opt.FileDir, schemaLocation)
-	var fi os.FileInfo
-	fi, err = os.Stat(xsdFile)
-	if err != nil {
+	xsdFile, baseURI := opt.FileDir, ""
+	if schemaLocation := opt.NSSchemaLocationMap[opt.parseNS(value)]; schemaLocation != "" {
+		if xsdFile, err = opt.resolveSchema(schemaLocation); err != nil || xsdFile == "" {
+			return
+		}
+		if isValidURL(schemaLocation) {
+			baseURI = schemaLocation
+		}
+	}
+	var isDir bool
+	if isDir, err = opt.statSchema(xsdFile); err != nil {
 		return
 	}
-	if fi.IsDir() {
+	if isDir {
 		// extract type of value from include schema.
 		valueType = ""
 		for include := range opt.IncludeMap {
-			parser := NewParser(&Options{
-				FilePath:            filepath.Join(opt.FileDir, include),
-				OutputDir:           opt.OutputDir,
-				Extract:             true,
-				Lang:                opt.Lang,
-				IncludeMap:          opt.IncludeMap,
-				LocalNameNSMap:      opt.LocalNameNSMap,
-				NSSchemaLocationMap: opt.NSSchemaLocationMap,
-				ParseFileList:       opt.ParseFileList,
-				ParseFileMap:        opt.ParseFileMap,
-				ProtoTree:           make([]interface{}, 0),
-			})
+			var includeFile string
+			if includeFile, err = opt.resolveSchema(include); err != nil || includeFile == "" {
+				continue
+			}
+			includeBaseURI := ""
+			if isValidURL(include) {
+				includeBaseURI = include
+			}
+			parser := opt.subParser(includeFile, includeBaseURI, true)
 			if parser.Parse() != nil {
 				return
 			}
@@ -204,18 +467,7 @@ func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueTy
 
 	depXSDSchema, ok := opt.ParseFileMap[xsdFile]
 	if !ok {
-		parser := NewParser(&Options{
-			FilePath:            xsdFile,
-			OutputDir:           opt.OutputDir,
-			Extract:             false,
-			Lang:                opt.Lang,
-			IncludeMap:          opt.IncludeMap,
-			LocalNameNSMap:      opt.LocalNameNSMap,
-			NSSchemaLocationMap: opt.NSSchemaLocationMap,
-			ParseFileList:       opt.ParseFileList,
-			ParseFileMap:        opt.ParseFileMap,
-			ProtoTree:           make([]interface{}, 0),
-		})
+		parser := opt.subParser(xsdFile, baseURI, false)
 		if parser.Parse() != nil {
 			return
 		}
@@ -225,21 +477,89 @@ func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueTy
 	if valueType != trimNSPrefix(value) && valueType != "" {
 		return
 	}
-	parser := NewParser(&Options{
-		FilePath:            xsdFile,
+	parser := opt.subParser(xsdFile, baseURI, true)
+	if parser.Parse() != nil {
+		return
+	}
+	valueType = getBasefromSimpleType(trimNSPrefix(value), parser.ProtoTree)
+	return
+}
+
+// subParser returns the parser for the schema referenced by the include or
+// import statements by given path and base URI, the parser shares the
+// options and the state of the schemas parsed with the parser.
+func (opt *Options) subParser(path, baseURI string, extract bool) *Options {
+	chain := append(append([]string{}, opt.chain...), opt.documentURI())
+	return NewParser(&Options{
+		FilePath:            path,
+		BaseURI:             baseURI,
+		InputDir:            opt.InputDir,
 		OutputDir:           opt.OutputDir,
-		Extract:             true,
+		Extract:             extract,
 		Lang:                opt.Lang,
+		Package:             opt.Package,
+		CMake:               opt.CMake,
+		Zod:                 opt.Zod,
+		Lxml:                opt.Lxml,
+		TargetFramework:     opt.TargetFramework,
+		OptionalPointers:    opt.OptionalPointers,
+		StructTags:          opt.StructTags,
+		SplitFiles:          opt.SplitFiles,
+		BigNumbers:          opt.BigNumbers,
+		DeepCopy:            opt.DeepCopy,
+		Stringer:            opt.Stringer,
+		DecodeEach:          opt.DecodeEach,
+		Naming:              opt.Naming,
+		Escape:              opt.Escape,
 		IncludeMap:          opt.IncludeMap,
 		LocalNameNSMap:      opt.LocalNameNSMap,
 		NSSchemaLocationMap: opt.NSSchemaLocationMap,
+		TypeMapping:         opt.TypeMapping,
+		Collision:           opt.Collision,
+		TypeNamespaces:      opt.TypeNamespaces,
+		SkipWrappers:        opt.SkipWrappers,
+		SchemaOrder:         opt.SchemaOrder,
+		PackagePerNamespace: opt.PackagePerNamespace,
+		ModulePath:          opt.ModulePath,
+		EmbedSource:         opt.EmbedSource,
+		SourceLocation:      opt.SourceLocation,
+		DeprecationMarker:   opt.DeprecationMarker,
+		EmitIR:              opt.EmitIR,
+		Fixtures:            opt.Fixtures,
+		KeepGoing:           opt.KeepGoing,
+		XSD11:               opt.xsd11,
+		Failures:            opt.Failures,
+		Sources:             opt.Sources,
+		Outputs:             opt.Outputs,
+		Resolver:            opt.Resolver,
 		ParseFileList:       opt.ParseFileList,
 		ParseFileMap:        opt.ParseFileMap,
 		ProtoTree:           make([]interface{}, 0),
+		chain:               chain,
+		chameleons:          opt.chameleons,
 	})
-	if parser.Parse() != nil {
-		return
+}
+
+// documentURI returns the URI of the schema being parsed, which is the URL of
+// the remote schema or the file path.
+func (opt *Options) documentURI() string {
+	if opt.BaseURI != "" {
+		return opt.BaseURI
 	}
-	valueType = getBasefromSimpleType(trimNSPrefix(value), parser.ProtoTree)
-	return
+	return opt.FilePath
+}
+
+// isCircular reports whether the schema by given location is being parsed by
+// the parser or the parsers including or importing it, the reference to it is
+// circular, and the chain of the schemas in the cycle is recorded in the
+// Cycles, such as a.xsd -> b.xsd -> a.xsd.
+func (opt *Options) isCircular(location string) bool {
+	chain := append(append([]string{}, opt.chain...), opt.documentURI())
+	for i, uri := range chain {
+		if uri == location {
+			opt.Cycles = append(opt.Cycles, strings.Join(append(chain[i:], location), " -> "))
+			return true
+		}
+	}
+	return false
 }
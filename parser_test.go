@@ -9,9 +9,13 @@
 package xgen
 
 import (
+	"encoding/xml"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -33,6 +37,101 @@ var (
 	xsdSrcDir   = filepath.Join(testDir, "xsd")
 )
 
+// newTestParser returns the parser of the schema file by given path in the
+// given language, the proto tree and the states of the parser are initialized
+// on the options.
+func newTestParser(lang, path string, opt Options) *Options {
+	opt.FilePath, opt.Lang = path, lang
+	opt.IncludeMap = make(map[string]bool)
+	opt.LocalNameNSMap = make(map[string]string)
+	opt.NSSchemaLocationMap = make(map[string]string)
+	opt.ParseFileList = make(map[string]bool)
+	opt.ParseFileMap = make(map[string][]interface{})
+	opt.ProtoTree = make([]interface{}, 0)
+	return NewParser(&opt)
+}
+
+// generateTestCode generates the code in the given language for the schema by
+// given path, and returns the generated code by the output paths. The schema
+// is the source of the path unless the sources are given in the options.
+func generateTestCode(t *testing.T, lang, path string, schema []byte, opt Options) map[string][]byte {
+	t.Helper()
+	if opt.Sources == nil {
+		opt.Sources = map[string][]byte{path: schema}
+	}
+	opt.Outputs = map[string][]byte{}
+	assert.NoError(t, newTestParser(lang, path, opt).Parse(), lang)
+	return opt.Outputs
+}
+
+// assertContains asserts that the code contains all the expected snippets.
+func assertContains(t *testing.T, code string, expected []string, msgAndArgs ...interface{}) {
+	t.Helper()
+	for _, snippet := range expected {
+		assert.Contains(t, code, snippet, msgAndArgs...)
+	}
+}
+
+// runGoCode runs the main function with the generated Go code in the main
+// package, and returns the output of the program. The test is skipped if the
+// Go toolchain isn't available.
+func runGoCode(t *testing.T, outputs map[string][]byte, main string) string {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the Go toolchain isn't available")
+	}
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	files := map[string][]byte{"go.mod": []byte("module example.com/gen\n\ngo 1.16\n"), "main.go": []byte(main)}
+	for path, code := range outputs {
+		if filepath.Ext(path) == ".go" {
+			files[path] = code
+		}
+	}
+	for path, code := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		assert.NoError(t, PrepareOutputDir(filepath.Dir(path)))
+		assert.NoError(t, ioutil.WriteFile(path, code, 0644))
+	}
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+	return string(output)
+}
+
+// runPythonCode runs the main script importing the generated Python code as
+// the schema module and returns its output. The lxml package is substituted
+// by the xml.etree.ElementTree if it isn't installed, which provides the
+// same API used by the generated helpers.
+func runPythonCode(t *testing.T, code []byte, main string) string {
+	t.Helper()
+	pythonBin, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("the Python interpreter isn't available")
+	}
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{"schema.py": string(code), "main.py": main}
+	if exec.Command(pythonBin, "-c", "import lxml").Run() != nil {
+		files["lxml/__init__.py"] = ""
+		files["lxml/etree.py"] = "from xml.etree.ElementTree import *\n"
+	}
+	for path, code := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		assert.NoError(t, PrepareOutputDir(filepath.Dir(path)))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(code), 0644))
+	}
+	cmd := exec.Command(pythonBin, "main.py")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+	return string(output)
+}
+
 func TestParseGo(t *testing.T) {
 	err := PrepareOutputDir(goCodeDir)
 	assert.NoError(t, err)
@@ -183,3 +282,3016 @@ func TestParseRust(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestGetFileList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, file := range []string{"a.xsd", "b.WSDL", "c.txt", ".hidden.xsd", "sub/d.xsd", "sub/deep/e.xsd", ".git/f.xsd", ".cache/g.xsd"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		assert.NoError(t, PrepareOutputDir(filepath.Dir(path)))
+		assert.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	files, err := GetFileList(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.xsd"),
+		filepath.Join(dir, "b.WSDL"),
+		filepath.Join(dir, "sub", "d.xsd"),
+		filepath.Join(dir, "sub", "deep", "e.xsd"),
+	}, files)
+
+	files, err = GetFileListWithOptions(dir, FileListOptions{Extensions: []string{"xsd"}, MaxDepth: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.xsd"), filepath.Join(dir, "sub", "d.xsd")}, files)
+
+	files, err = GetFileList(filepath.Join(dir, "c.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "c.txt")}, files)
+}
+
+func TestSampleValue(t *testing.T) {
+	for _, c := range []struct {
+		base        string
+		restriction Restriction
+		expected    string
+	}{
+		{"string", Restriction{}, "sample"},
+		{"string", Restriction{MaxLength: 3}, "sam"},
+		{"string", Restriction{Enum: []string{"red", "green"}}, "red"},
+		{"string", Restriction{Pattern: regexp.MustCompile(`[A-Z]{2}[0-9]+`), MinLength: 5}, "AA000"},
+		{"int", Restriction{Min: 100, HasMin: true}, "100"},
+		{"int", Restriction{Max: 0, HasMax: true, MaxExclusive: true}, "-1"},
+		{"decimal", Restriction{Min: 0, HasMin: true, MinExclusive: true, Max: 1, HasMax: true, MaxExclusive: true}, "0.5"},
+		{"positiveInteger", Restriction{}, "1"},
+		{"date", Restriction{}, "2006-01-02"},
+		{"hexBinary", Restriction{MinLength: 2, MaxLength: 2}, "7867"},
+	} {
+		value, err := SampleValue(c.base, c.restriction)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, value)
+	}
+	_, err := SampleValue("int", Restriction{Min: 5, HasMin: true, Max: 2, HasMax: true})
+	assert.EqualError(t, err, "no number satisfies the range facets")
+}
+
+func TestGenerateLanguages(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="colorType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="red"/>
+      <xs:enumeration value="dark-green"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="title" type="xs:string"/>
+      <xs:element name="tag" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="price" type="xs:decimal" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:int" use="required"/>
+  </xs:complexType>
+  <xs:element name="item" type="itemType"/>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Python", ".py", []string{
+			"class ColorType(Enum):\n    RED = \"red\"\n    DARK_GREEN = \"dark-green\"\n",
+			"@dataclass(kw_only=True)\nclass ItemType:\n",
+			"    id: int = field(metadata={\"name\": \"id\", \"type\": \"Attribute\"})\n",
+			"    tag: List[str] = field(default_factory=list, metadata={\"name\": \"tag\", \"type\": \"Element\"})\n",
+			"    price: Optional[Decimal] = field(default=None, metadata={\"name\": \"price\", \"type\": \"Element\"})\n",
+			"Item: TypeAlias = \"ItemType\"\n",
+		}},
+		{"CSharp", ".cs", []string{
+			"\tpublic enum ColorType\n\t{\n\t\t[XmlEnum(\"red\")]\n\t\tRed,\n\t\t[XmlEnum(\"dark-green\")]\n\t\tDarkGreen\n\t}\n",
+			"\t[XmlType(\"itemType\")]\n\tpublic partial class ItemType\n\t{\n\t\t[XmlAttribute(\"id\")]\n\t\tpublic int Id { get; set; }\n",
+			"\t\t[XmlElement(\"tag\")]\n\t\tpublic List<string> Tag { get; set; }\n",
+			"\t\t[XmlElement(\"price\")]\n\t\tpublic decimal? Price { get; set; }\n",
+			"\t[XmlRoot(\"item\")]\n\tpublic partial class Item : ItemType\n",
+		}},
+		{"Kotlin", ".kt", []string{
+			"enum class ColorType(val value: String) {\n\t@JsonProperty(\"red\")\n\tRED(\"red\"),\n\t@JsonProperty(\"dark-green\")\n\tDARK_GREEN(\"dark-green\"),\n}\n",
+			"data class ItemType(\n\t@field:JacksonXmlProperty(localName = \"id\", isAttribute = true)\n\tval id: Int,\n",
+			"\t@field:JacksonXmlProperty(localName = \"tag\")\n\t@field:JacksonXmlElementWrapper(useWrapping = false)\n\tval tag: List<String> = emptyList(),\n",
+			"\t@field:JacksonXmlProperty(localName = \"price\")\n\tval price: BigDecimal? = null,\n",
+			"import java.math.BigDecimal\n",
+			"typealias Item = ItemType\n",
+		}},
+		{"Swift", ".swift", []string{
+			"public enum ColorType: String, Codable {\n\tcase red = \"red\"\n\tcase darkGreen = \"dark-green\"\n}\n",
+			"public struct ItemType: Codable {\n\tpublic var id: Int32\n",
+			"\tpublic var tag: [String]?\n",
+			"\tpublic var price: Decimal?\n",
+			"\t\tcase id = \"id\"\n",
+			"extension ItemType: DynamicNodeEncoding {\n",
+			"\t\tcase CodingKeys.id:\n\t\t\treturn .attribute\n",
+			"public typealias Item = ItemType\n",
+		}},
+		{"PHP", ".php", []string{
+			"enum ColorType: string\n{\n\tcase Red = 'red';\n\tcase DarkGreen = 'dark-green';\n}\n",
+			"class ItemType\n{\n\t#[Serializer\\SerializedName('id')]\n\t#[Serializer\\XmlAttribute]\n\t#[Serializer\\Type('int')]\n\tpublic int $id;\n",
+			"\t#[Serializer\\XmlList(inline: true, entry: 'tag')]\n\t#[Serializer\\Type('array<string>')]\n\tpublic array $tag = [];\n",
+			"\t#[Serializer\\Type('string')]\n\tpublic ?string $price = null;\n",
+			"#[Serializer\\XmlRoot('item')]\nclass Item extends ItemType\n",
+		}},
+		{"Dart", ".dart", []string{
+			"enum ColorType {\n  red('red'),\n  darkGreen('dark-green');\n",
+			"class ItemType {\n  ItemType({\n    required this.id,\n",
+			"        id: int.parse(element.getAttribute('id')!),\n",
+			"        tag: element.findElements('tag').map((e) => e.innerText).toList(),\n",
+			"        price: _parse(element.getElement('price')?.innerText, double.parse),\n",
+			"    builder.attribute('id', this.id);\n",
+			"  XmlDocument toXml(String name) {\n",
+			"typedef Item = ItemType;\n",
+		}},
+		{"Elixir", ".ex", []string{
+			"defmodule Schema do\n",
+			"  defmodule ColorType do\n    @type t :: String.t()\n\n    @values [\"red\", \"dark-green\"]\n",
+			"  defmodule ItemType do\n    import SweetXml\n",
+			"            id: integer(),\n",
+			"        id: node |> xpath(~x\"./@id\"s) |> then(&String.to_integer/1),\n",
+			"        tag: node |> xpath(~x\"./tag/text()\"sl),\n",
+			"        price: node |> xpath(~x\"./price/text()\"so) |> Schema.Xgen.optional(&Decimal.new/1)\n",
+			"  defmodule Item do\n    @type t :: Schema.ItemType.t()\n",
+		}},
+		{"Haskell", ".hs", []string{
+			"data ColorType\n  = ColorTypeRed\n  | ColorTypeDarkGreen\n  deriving (Eq, Show, Enum, Bounded)\n",
+			"    parse \"dark-green\" = Right ColorTypeDarkGreen\n",
+			"data ItemType = ItemType\n  { itemTypeId :: Int32\n",
+			"  , itemTypeTag :: [Text]\n  , itemTypePrice :: Maybe Scientific\n",
+			"xpItemType :: Name -> PU [Node] ItemType\n",
+			"      (xpAttribute \"id\" xpPrim)\n",
+			"(xpList (xpElemNodes \"tag\" (xpContent xpId)))",
+			"xpItem :: PU [Node] Item\nxpItem = xpItemType \"item\"\n",
+		}},
+		{"OCaml", ".ml", []string{
+			"type color_type = [ `Red | `DarkGreen ]\n",
+			"and item_type = {\n  id : int;\n  title : string;\n  tag : string list;\n  price : float option;\n}\n",
+			"let rec color_type_of_string (value : string) : color_type =\n  match value with\n  | \"red\" -> `Red\n",
+			"    id = Xgen.attribute \"id\" int_of_string node;\n",
+			"    price = Xgen.element_opt \"price\" (Xgen.text_of float_of_string) node;\n",
+			"          [ Xgen.attribute_pair \"id\" string_of_int v.id ];\n",
+			"          List.map (Xgen.text_element Fun.id \"tag\") v.tag;\n",
+			"and item_to_xml (v : item) : Xml.xml = item_type_to_xml \"item\" v\n",
+		}},
+		{"Zig", ".zig", []string{
+			"pub const ColorType = enum {\n    red,\n    @\"dark-green\",\n};\n",
+			"pub const ItemType = struct {\n    id: i32,\n    title: []const u8,\n    tag: []const []const u8,\n    price: ?f64 = null,\n",
+			"    pub fn parse(allocator: std.mem.Allocator, node: anytype) ParseError!ItemType {\n",
+			"        result.id = try xgen.attribute(i32, allocator, node, \"id\");\n        errdefer xgen.free(i32, allocator, result.id);\n",
+			"        result.tag = try xgen.elements([]const u8, allocator, node, \"tag\");\n",
+			"        result.price = try xgen.optionalElement(f64, allocator, node, \"price\");\n        return result;\n",
+			"        xgen.free([]const []const u8, allocator, self.tag);\n",
+			"pub const Item = ItemType;\n",
+		}},
+		{"FSharp", ".fs", []string{
+			"module rec Schema\n",
+			"[<RequireQualifiedAccess>]\ntype ColorType =\n    | Red\n    | DarkGreen\n",
+			"        | ColorType.DarkGreen -> \"dark-green\"\n",
+			"type ItemType =\n    { Id: int\n      Title: string\n      Tag: string list\n      Price: decimal option }\n",
+			"type Item = ItemType\n",
+		}},
+		{"ObjectiveC", ".h", []string{
+			"typedef NSString *ColorType NS_TYPED_ENUM;\n",
+			"FOUNDATION_EXPORT ColorType const ColorTypeDarkGreen;\n",
+			"@interface ItemType : NSObject\n\n@property (nonatomic) NSInteger id_;\n@property (nonatomic, copy) NSString *title;\n@property (nonatomic, copy) NSArray<NSString *> *tag;\n@property (nonatomic, copy, nullable) NSDecimalNumber *price;\n",
+			"- (nullable instancetype)initWithXMLNode:(XGXMLNode *)node;\n",
+			"@compatibility_alias Item ItemType;\n",
+		}},
+		{"ObjectiveC", ".m", []string{
+			"ColorType const ColorTypeDarkGreen = @\"dark-green\";\n",
+			"    text = node.attributes[@\"id\"];\n    if (text == nil) {\n        return nil;\n    }\n    _id_ = [text integerValue];\n",
+			"    _tag = XGMap([node childrenNamed:@\"tag\"], ^id(XGXMLNode *item) {\n        return item.text;\n    });\n",
+			"        _price = [NSDecimalNumber decimalNumberWithString:child.text];\n",
+		}},
+		{"Groovy", ".groovy", []string{
+			"    DARK_GREEN(\"dark-green\")\n",
+			"    Integer id\n    String title\n    List<String> tag = []\n    BigDecimal price\n",
+			"            id: node.@id.text() as Integer,\n",
+			"            tag: node.tag.collect { it.text() },\n",
+			"            price: node.price.isEmpty() ? null : node.price.text() as BigDecimal\n",
+		}},
+		{"Lua", ".lua", []string{
+			"  DARK_GREEN = \"dark-green\",\n",
+			"---@field id integer\n---@field title string\n---@field tag string[]\n---@field price? number\n",
+			"    id = xgen.attribute(node, \"id\", tonumber),\n",
+			"    tag = xgen.elements(node, \"tag\", xgen.value(xgen.string)),\n",
+			"    price = xgen.element(node, \"price\", xgen.value(tonumber), true),\n",
+		}},
+		{"Perl", ".pm", []string{
+			"    DARK_GREEN => 'dark-green',\n",
+			"has id => (is => 'ro', isa => Int, required => 1);\nhas title => (is => 'ro', isa => Str, required => 1);\nhas tag => (is => 'ro', isa => ArrayRef[Str], default => sub { [] });\nhas price => (is => 'ro', isa => Num, predicate => 1);\n",
+			"        id => Schema::Xgen::attribute($node, 'id', 'number'),\n",
+			"        tag => Schema::Xgen::elements($node, 'tag', 'string'),\n",
+			"        price => Schema::Xgen::element($node, 'price', 'number', 1),\n",
+		}},
+		{"Crystal", ".cr", []string{
+			"    DARK_GREEN = \"dark-green\"\n",
+			"    @[XML::Field(key: \"id\", attribute: true)]\n    property id : Int32\n",
+			"    @[XML::Field(key: \"tag\")]\n    property tag : Array(String) = [] of String\n",
+			"    @[XML::Field(key: \"price\")]\n    property price : BigDecimal?\n",
+		}},
+		{"Nim", ".nim", []string{
+			"    DarkGreen = \"dark-green\"\n",
+			"  ItemType* = ref object\n    id*: int32\n    title*: string\n    tag*: seq[string]\n    price*: Option[float64]\n",
+			"    id: int32(parseInt(xgenAttribute(node, \"id\"))),\n",
+			"    tag: xgenChildren(node, \"tag\").mapIt(it.innerText),\n",
+			"    price: (if xgenHasElement(node, \"price\"): some(parseFloat(xgenElement(node, \"price\").innerText)) else: none(float64))\n",
+		}},
+		{"Julia", ".jl", []string{
+			"const DARK_GREEN = \"dark-green\"\n",
+			"struct ItemType <: AbstractItemType\n    id::Int32\n    title::String\n    tag::Vector{String}\n    price::Union{Float64, Nothing}\nend\n",
+			"        xgen_attribute(x -> parse(Int32, x), node, \"id\"),\n",
+			"        xgen_elements(xgen_text(identity), node, \"tag\"),\n",
+			"        xgen_element(xgen_text(x -> parse(Float64, x)), node, \"price\", true),\n",
+		}},
+		{"VisualBasic", ".vb", []string{
+			"\tPublic Enum ColorType\n\t\t<XmlEnum(\"red\")> Red\n\t\t<XmlEnum(\"dark-green\")> DarkGreen\n\tEnd Enum\n",
+			"\t<XmlType(\"itemType\")>\n\tPartial Public Class ItemType\n\t\t<XmlAttribute(\"id\")>\n\t\tPublic Property Id As Integer\n",
+			"\t\t<XmlElement(\"tag\")>\n\t\tPublic Property Tag As List(Of String)\n",
+			"\t\t<XmlElement(\"price\")>\n\t\tPublic Property Price As Decimal?\n",
+			"\t<XmlRoot(\"item\")>\n\tPartial Public Class Item\n\t\tInherits ItemType\n\tEnd Class\n",
+		}},
+		{"JSONSchema", ".schema.json", []string{
+			"  \"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n  \"$ref\": \"#/$defs/item\",\n",
+			"    \"colorType\": {\n      \"type\": \"string\",\n      \"enum\": [\n        \"red\",\n        \"dark-green\"\n      ]\n    },\n",
+			"        \"id\": {\n          \"type\": \"integer\",\n          \"minimum\": -2147483648,\n          \"maximum\": 2147483647\n        },\n",
+			"        \"tag\": {\n          \"type\": \"array\",\n          \"items\": {\n            \"type\": \"string\"\n          }\n        },\n",
+			"      \"required\": [\n        \"id\",\n        \"title\"\n      ]\n",
+		}},
+		{"Protobuf", ".proto", []string{
+			"syntax = \"proto3\";\n\npackage schema;\n",
+			"enum ColorType {\n  COLOR_TYPE_UNSPECIFIED = 0;\n  COLOR_TYPE_RED = 1; // red\n  COLOR_TYPE_DARK_GREEN = 2; // dark-green\n}\n",
+			"message ItemType {\n  int32 id = 1;\n  string title = 2;\n  repeated string tag = 3;\n  optional string price = 4;\n}\n",
+			"message Item {\n  ItemType value = 1;\n}\n",
+		}},
+		{"Avro", ".avsc", []string{
+			"{\n  \"type\": \"record\",\n  \"name\": \"ItemType\",\n  \"namespace\": \"schema\",\n",
+			"    {\n      \"name\": \"id\",\n      \"type\": \"int\"\n    },\n",
+			"      \"name\": \"tag\",\n      \"type\": {\n        \"type\": \"array\",\n        \"items\": \"string\"\n      },\n      \"default\": []\n",
+			"      \"name\": \"price\",\n      \"type\": [\n        \"null\",\n        \"string\"\n      ],\n      \"default\": null\n",
+		}},
+		{"GraphQL", ".graphql", []string{
+			"scalar Decimal\n",
+			"enum ColorType {\n  RED # red\n  DARK_GREEN # dark-green\n}\n",
+			"type ItemType {\n  id: Int!\n  title: String!\n  tag: [String!]\n  price: Decimal\n}\n",
+		}},
+		{"OpenAPI", ".openapi.yaml", []string{
+			"openapi: 3.1.0\n",
+			"components:\n  schemas:\n    colorType:\n      type: string\n      enum:\n        - red\n        - dark-green\n",
+			"        id:\n          type: integer\n          minimum: -2147483648\n          maximum: 2147483647\n          xml:\n            attribute: true\n",
+			"      required:\n        - id\n        - title\n",
+			"    item:\n      $ref: \"#/components/schemas/itemType\"\n",
+		}},
+		{"SQL", ".sql", []string{
+			"CREATE TYPE color_type AS ENUM (\n  'red',\n  'dark-green'\n);",
+			"CREATE TABLE item_type (\n  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,\n  id_1 INTEGER NOT NULL,\n  title TEXT NOT NULL,\n  tag TEXT[],\n  price NUMERIC\n);",
+		}},
+		{"FlatBuffers", ".fbs", []string{
+			"enum ColorType : ubyte {\n  Red = 0, // red\n  DarkGreen = 1 // dark-green\n}",
+			"table ItemType {\n  id:int;\n  title:string (required);\n  tag:[string];\n  price:string;\n}",
+			"root_type ItemType;",
+		}},
+		{"CapnProto", ".capnp", []string{
+			"enum ColorType {\n  red @0; # red\n  darkGreen @1; # dark-green\n}",
+			"struct ItemType {\n  id @0 :Int32;\n  title @1 :Text;\n  tag @2 :List(Text);\n  price @3 :Text;\n}",
+			"using Item = ItemType;",
+		}},
+		{"CUE", ".cue", []string{
+			"#ColorType: \"red\" | \"dark-green\"",
+			"#ItemType: {\n\tid: int32\n\ttitle: string\n\ttag?: [...string]\n\tprice?: number\n}",
+			"#Item: #ItemType",
+		}},
+		{"Markdown", ".md", []string{
+			"- [itemType](#complex-type-itemType) (complex type)\n",
+			"| Value |\n| --- |\n| `red` |\n| `dark-green` |\n",
+			"| `@id` | attribute | `xs:int` | 1 |  |\n| `title` | element | `xs:string` | 1 |  |\n| `tag` | element | `xs:string` | 0..* |  |\n",
+			"Element of type [itemType](#complex-type-itemType), cardinality 1.",
+		}},
+		{"DOT", ".dot", []string{
+			"digraph \"item.xsd\" {",
+			"  \"ComplexType:itemType\" [label=\"itemType\\n(1 ref)\", shape=box];\n",
+			"  \"SimpleType:colorType\" [label=\"colorType\", shape=ellipse];\n",
+			"  \"Element:item\" -> \"ComplexType:itemType\";\n",
+		}},
+		{"XML", ".item.xml", []string{
+			"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n",
+			"<item id=\"1\">\n  <title>sample</title>\n</item>\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "item.xsd", schema, Options{})
+		code, ok := outputs["item.xsd"+c.ext]
+		assert.True(t, ok, c.lang)
+		assertContains(t, string(code), c.expected, c.lang)
+	}
+}
+
+func TestGenerateTypeScriptZod(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3}"/>
+      <xs:maxLength value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType"/>
+      <xs:element name="tag" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:int"/>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "TypeScript", "item.xsd", schema, Options{Zod: true})
+	code := string(outputs["item.xsd.ts"])
+	assert.Contains(t, code, "import { z } from 'zod';\n")
+	assert.Contains(t, code, "export const ItemTypeSchema: z.ZodTypeAny = z.object({\n")
+	assert.Contains(t, code, "\tIdAttr: z.number().nullable(),\n")
+	assert.Contains(t, code, "\tCode: z.string().regex(new RegExp(\"^(?:[A-Z]{3})$\")).max(3),\n")
+	assert.Contains(t, code, "\tTag: z.array(z.string()).optional(),\n")
+}
+
+func TestGenerateChoice(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="cardType">
+    <xs:sequence>
+      <xs:element name="number" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="paymentType">
+    <xs:sequence>
+      <xs:element name="amount" type="xs:decimal"/>
+      <xs:choice>
+        <xs:element name="cash" type="xs:string"/>
+        <xs:element name="card" type="cardType"/>
+      </xs:choice>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"\tCash   *PaymentTypeCash `xml:\"cash\"`\n",
+			"type PaymentTypeChoice interface {\n\tisPaymentTypeChoice()\n}\n",
+			"type PaymentTypeCard CardType\n\nfunc (PaymentTypeCard) isPaymentTypeChoice() {}\n",
+			"func (v *PaymentType) Choice() PaymentTypeChoice {\n\tif v.Cash != nil {\n\t\treturn v.Cash\n\t}\n",
+		}},
+		{"Java", ".java", []string{
+			"\t@XmlElements({\n\t\t@XmlElement(name = \"cash\", type = PaymentTypeCash.class),\n\t\t@XmlElement(name = \"card\", type = PaymentTypeCard.class)\n\t})\n\tprotected PaymentTypeChoice Choice;\n",
+			"public class PaymentTypeCash implements PaymentTypeChoice {\n\t@XmlValue\n\tprotected String value;\n}\n",
+			"public class PaymentTypeCard extends CardType implements PaymentTypeChoice {\n}\n",
+		}},
+		{"Rust", ".rs", []string{
+			"\t#[serde(rename = \"$value\")]\n\tpub choice: PaymentTypeChoice,\n",
+			"pub enum PaymentTypeChoice {\n\t#[serde(rename = \"cash\")]\n\tCash(String),\n\t#[serde(rename = \"card\")]\n\tCard(CardType),\n}\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"\tChoice: PaymentTypeChoice;\n",
+			"export type PaymentTypeChoice =\n\t| { kind: 'cash'; value: string }\n\t| { kind: 'card'; value: CardType };\n",
+		}},
+		{"Python", ".py", []string{
+			"    cash: Optional[str] = field(default=None, metadata={\"name\": \"cash\", \"type\": \"Element\"})\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "payment.xsd", schema, Options{})
+		code := string(outputs["payment.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="itemType">
+    <xs:all>
+      <xs:element name="title" type="xs:string"/>
+      <xs:element name="price" type="xs:decimal" minOccurs="0"/>
+    </xs:all>
+  </xs:complexType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="item" type="itemType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Java", ".java", []string{
+			"@XmlType(propOrder = {})\npublic class ItemType {\n",
+			"// OrderType ...\r\npublic class OrderType {\n",
+		}},
+		{"Markdown", ".md", []string{
+			"Complex type, the elements may occur in any order.\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "item.xsd", Options{
+			Sources: map[string][]byte{"item.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.True(t, parser.ProtoTree[0].(*ComplexType).Unordered, c.lang)
+		assert.False(t, parser.ProtoTree[1].(*ComplexType).Unordered, c.lang)
+		code := string(outputs["item.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestParseFacets(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="code">
+        <xs:simpleType>
+          <xs:restriction base="xs:string">
+            <xs:pattern value="[A-Z]{3}"/>
+            <xs:pattern value="[0-9]{3}"/>
+            <xs:maxLength value="3"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+    </xs:sequence>
+    <xs:attribute name="size">
+      <xs:simpleType>
+        <xs:restriction base="xs:string">
+          <xs:enumeration value="S"/>
+          <xs:enumeration value="L"/>
+        </xs:restriction>
+      </xs:simpleType>
+    </xs:attribute>
+  </xs:complexType>
+  <xs:simpleType name="priceType">
+    <xs:restriction base="xs:decimal">
+      <xs:minInclusive value="0"/>
+      <xs:maxExclusive value="1000"/>
+      <xs:totalDigits value="5"/>
+      <xs:fractionDigits value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`)
+	parser := newTestParser("TypeScript", "item.xsd", Options{
+		Zod:     true,
+		Sources: map[string][]byte{"item.xsd": schema},
+		Outputs: map[string][]byte{},
+	})
+	assert.NoError(t, parser.Parse())
+	assert.Len(t, parser.ProtoTree, 2)
+	itemType := parser.ProtoTree[0].(*ComplexType)
+	code := itemType.Elements[0].Restriction
+	assert.Equal(t, "(?:[A-Z]{3})|(?:[0-9]{3})", code.Pattern.String())
+	assert.Equal(t, 3, code.MaxLength)
+	assert.Equal(t, "string", itemType.Attributes[0].Type)
+	assert.Equal(t, []string{"S", "L"}, itemType.Attributes[0].Restriction.Enum)
+	price := parser.ProtoTree[1].(*SimpleType).Restriction
+	assert.True(t, price.HasMin && price.HasMax && price.MaxExclusive)
+	assert.Equal(t, []float64{0, 1000}, []float64{price.Min, price.Max})
+	assert.Equal(t, []int{5, 2}, []int{price.TotalDigits, price.Precision})
+	assert.Contains(t, string(parser.Outputs["item.xsd.ts"]), "\tSizeAttr: z.enum([\"S\", \"L\"]).nullable(),\n\tCode: z.string().regex(new RegExp(\"^(?:(?:[A-Z]{3})|(?:[0-9]{3}))$\")).max(3),\n")
+}
+
+func TestGenerateEnum(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="in-progress"/>
+      <xs:enumeration value="done"/>
+      <xs:enumeration value="1st"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="levelType">
+    <xs:restriction base="xs:int">
+      <xs:enumeration value="01"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type StatusType string\n\n// Enumeration values of StatusType.\nconst (\n\tStatusTypeInProgress StatusType = \"in-progress\"\n\tStatusTypeDone       StatusType = \"done\"\n\tStatusTypeValue1st   StatusType = \"1st\"\n)\n",
+			"\tLevelTypeValue01 LevelType = 1\n",
+		}},
+		{"Java", ".java", []string{
+			"@XmlType(name = \"statusType\")\n@XmlEnum\npublic enum StatusType {\n\t@XmlEnumValue(\"in-progress\")\n\tIN_PROGRESS,\n\t@XmlEnumValue(\"done\")\n\tDONE,\n\t@XmlEnumValue(\"1st\")\n\tVALUE1ST;\n}\n",
+			"@XmlEnum(Integer.class)\npublic enum LevelType {\n",
+		}},
+		{"Rust", ".rs", []string{
+			"pub enum StatusType {\n\t#[serde(rename = \"in-progress\")]\n\tInProgress,\n\t#[serde(rename = \"done\")]\n\tDone,\n\t#[serde(rename = \"1st\")]\n\tValue1st,\n}\n",
+		}},
+		{"Ruby", ".rb", []string{
+			"\tclass StatusType < String\n\t\tIN_PROGRESS = 'in-progress'\n\t\tDONE = 'done'\n\t\tVALUE1ST = '1st'\n\t\tVALUES = [IN_PROGRESS, DONE, VALUE1ST].freeze\n\tend\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "status.xsd", schema, Options{})
+		code := string(outputs["status.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+	assert.Equal(t, []string{"A", "A2", "Empty", "Value1"}, enumNames([]string{"a", "a", "", "1"}))
+}
+
+func TestGenerateSubstitutionGroup(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="vehicleType">
+    <xs:sequence>
+      <xs:element name="wheels" type="xs:int"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="vehicle" type="vehicleType"/>
+  <xs:element name="car" type="vehicleType" substitutionGroup="vehicle"/>
+  <xs:element name="sportsCar" type="vehicleType" substitutionGroup="car"/>
+  <xs:element name="bike" type="xs:string" substitutionGroup="vehicle"/>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type Vehicle struct {\n\tXMLName xml.Name `xml:\"vehicle\"`\n\tVehicleType\n}\n\n// VehicleGroup is implemented by the vehicle element and the elements in its substitution\n// group: car, sportsCar, bike.\ntype VehicleGroup interface {\n\tisVehicleGroup()\n}\n\nfunc (Vehicle) isVehicleGroup() {}\n",
+			"func (Car) isCarGroup() {}\n\nfunc (Car) isVehicleGroup() {}\n",
+			"type SportsCar struct {\n\tXMLName xml.Name `xml:\"sportsCar\"`\n\tVehicleType\n}\n\nfunc (SportsCar) isCarGroup() {}\n\nfunc (SportsCar) isVehicleGroup() {}\n",
+			"type Bike string\n\nfunc (Bike) isVehicleGroup() {}\n",
+		}},
+		{"Java", ".java", []string{
+			"public abstract class VehicleGroup {\n}\n",
+			"public class Vehicle extends VehicleGroup {\n",
+			"public abstract class CarGroup extends VehicleGroup {\n}\n",
+			"public class Car extends CarGroup {\n",
+			"public class SportsCar extends CarGroup {\n",
+			"public class Bike extends VehicleGroup {\n",
+		}},
+		{"Rust", ".rs", []string{
+			"pub enum VehicleGroup {\n\t#[serde(rename = \"vehicle\")]\n\tVehicle(VehicleType),\n\t#[serde(rename = \"car\")]\n\tCar(VehicleType),\n\t#[serde(rename = \"sportsCar\")]\n\tSportsCar(VehicleType),\n\t#[serde(rename = \"bike\")]\n\tBike(String),\n}\n",
+			"pub enum CarGroup {\n\t#[serde(rename = \"car\")]\n\tCar(VehicleType),\n\t#[serde(rename = \"sportsCar\")]\n\tSportsCar(VehicleType),\n}\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "vehicle.xsd", Options{
+			Sources: map[string][]byte{"vehicle.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.Equal(t, "car", parser.ProtoTree[3].(*Element).SubstitutionGroup, c.lang)
+		code := string(outputs["vehicle.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateAbstractType(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="shapeType" abstract="true">
+    <xs:sequence>
+      <xs:element name="color" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="circleType">
+    <xs:complexContent>
+      <xs:extension base="shapeType">
+        <xs:sequence>
+          <xs:element name="radius" type="xs:double"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="drawingType">
+    <xs:sequence>
+      <xs:element name="shape" type="shapeType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"import (\n\t\"encoding/xml\"\n\t\"strings\"\n)\n",
+			"type AnyShapeType struct {\n\tValue interface{}\n}\n",
+			"func (v *AnyShapeType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n",
+			"\tswitch xsiType {\n\tcase \"circleType\":\n\t\tv.Value = &CircleType{}\n\tdefault:\n\t\tv.Value = &ShapeType{}\n\t}\n\treturn d.DecodeElement(v.Value, &start)\n",
+			"func (v AnyShapeType) MarshalXML(e *xml.Encoder, start xml.StartElement) error {\n\tswitch v.Value.(type) {\n\tcase nil:\n\t\treturn nil\n\tcase CircleType, *CircleType:\n\t\tstart.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: \"xmlns:xsi\"}, Value: \"http://www.w3.org/2001/XMLSchema-instance\"}, xml.Attr{Name: xml.Name{Local: \"xsi:type\"}, Value: \"circleType\"})\n\t}\n",
+			"\tShape []*AnyShapeType `xml:\"shape\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"@XmlSeeAlso({CircleType.class})\npublic abstract class ShapeType {\n",
+			"public class CircleType extends ShapeType {\n",
+			"\tprotected List<ShapeType> Shape;\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "shape.xsd", Options{
+			Sources: map[string][]byte{"shape.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.True(t, parser.ProtoTree[0].(*ComplexType).Abstract, c.lang)
+		assert.Equal(t, "shapeType", parser.ProtoTree[1].(*ComplexType).Base, c.lang)
+		code := string(outputs["shape.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateAbstractTypeRoundTrip(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="shapeType" abstract="true">
+    <xs:sequence>
+      <xs:element name="color" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="circleType">
+    <xs:complexContent>
+      <xs:extension base="shapeType">
+        <xs:sequence>
+          <xs:element name="radius" type="xs:int"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "shape.xsd", schema, Options{Package: "main"})
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var drawing struct {
+		XMLName xml.Name       `+"`xml:\"drawing\"`"+`
+		Shape   []AnyShapeType `+"`xml:\"shape\"`"+`
+	}
+	if err := xml.Unmarshal([]byte(`+"`"+`<drawing xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><shape xsi:type="circleType"><color>red</color><radius>2</radius></shape></drawing>`+"`"+`), &drawing); err != nil {
+		panic(err)
+	}
+	circle := drawing.Shape[0].Value.(*CircleType)
+	fmt.Println(circle.Color, circle.Radius)
+	data, err := xml.Marshal(drawing)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+}
+`)
+	assert.Equal(t, "red 2\n<drawing><shape xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:type=\"circleType\"><color>red</color><radius>2</radius></shape></drawing>\n", output)
+}
+
+func TestGenerateExtension(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="shapeType">
+    <xs:sequence>
+      <xs:element name="color" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="circleType">
+    <xs:complexContent>
+      <xs:extension base="shapeType">
+        <xs:sequence>
+          <xs:element name="radius" type="xs:double"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="labelType">
+    <xs:simpleContent>
+      <xs:extension base="xs:string">
+        <xs:attribute name="lang" type="xs:string"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"\tShapeType\n\tRadius float64 `xml:\"radius\"`\n",
+			"\tValue    string `xml:\",chardata\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"@XmlSeeAlso({CircleType.class})\npublic class ShapeType {\n",
+			"public class CircleType extends ShapeType {\n",
+			"public class LabelType {\n\t@XmlValue\n\tprotected String value;\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"export class CircleType extends ShapeType {\n\tRadius: number;\n}\n",
+			"export class LabelType {\n\tValue: string;\n",
+		}},
+		{"Ruby", ".rb", []string{
+			"\tclass CircleType < ShapeType\n",
+			"\t\tcontent :value, String\n",
+		}},
+		{"Python", ".py", []string{
+			"class CircleType:\n    color: str = field(metadata={\"name\": \"color\", \"type\": \"Element\"})\n    radius: float = field(metadata={\"name\": \"radius\", \"type\": \"Element\"})\n",
+		}},
+		{"Protobuf", ".proto", []string{
+			"message CircleType {\n  string color = 1;\n  double radius = 2;\n}\n",
+		}},
+		{"CapnProto", ".capnp", []string{
+			"struct CircleType {\n  color @0 :Text;\n  radius @1 :Float64;\n}\n",
+		}},
+		{"GraphQL", ".graphql", []string{
+			"type CircleType {\n  color: String!\n  radius: Float!\n}\n",
+		}},
+		{"SQL", ".sql", []string{
+			"CREATE TABLE circle_type (\n  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,\n  color TEXT NOT NULL,\n  radius DOUBLE PRECISION NOT NULL\n);\n",
+		}},
+		{"C", ".h", []string{
+			"typedef struct {\n\tchar Color;\n\tfloat Radius;\n} CircleType;\n",
+		}},
+		{"CSharp", ".cs", []string{
+			"\tpublic partial class CircleType\n\t{\n\t\t[XmlElement(\"color\")]\n\t\tpublic string Color { get; set; }\n\n\t\t[XmlElement(\"radius\")]\n\t\tpublic double Radius { get; set; }\n\t}\n",
+		}},
+		{"Kotlin", ".kt", []string{
+			"data class CircleType(\n\t@field:JacksonXmlProperty(localName = \"color\")\n\tval color: String,\n\t@field:JacksonXmlProperty(localName = \"radius\")\n\tval radius: Double,\n)\n",
+		}},
+		{"Rust", ".rs", []string{
+			"pub struct CircleType {\n\t#[serde(rename = \"color\")]\n\tpub color: String,\n\t#[serde(rename = \"radius\")]\n\tpub radius: f64,\n}\n",
+		}},
+		{"Swift", ".swift", []string{
+			"public struct CircleType: Codable {\n\tpublic var color: String\n\tpublic var radius: Double\n",
+		}},
+		{"PHP", ".php", []string{
+			"class CircleType\n{\n\t#[Serializer\\SerializedName('color')]\n\t#[Serializer\\Type('string')]\n\tpublic string $color;\n",
+		}},
+		{"Dart", ".dart", []string{
+			"  final String color;\n  final double radius;\n",
+		}},
+		{"JSONSchema", ".schema.json", []string{
+			"\"circleType\": {\n      \"type\": \"object\",\n      \"properties\": {\n        \"color\": {\n          \"type\": \"string\"\n        },\n        \"radius\": {\n",
+		}},
+		{"OpenAPI", ".openapi.yaml", []string{
+			"    circleType:\n      type: object\n      properties:\n        color:\n          type: string\n        radius:\n          type: number\n      required:\n        - color\n        - radius\n",
+		}},
+		{"Avro", ".avsc", []string{
+			"\"name\": \"CircleType\",\n    \"namespace\": \"schema\",\n    \"fields\": [\n      {\n        \"name\": \"color\",\n        \"type\": \"string\"\n      },\n      {\n        \"name\": \"radius\",\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "shape.xsd", Options{
+			Sources: map[string][]byte{"shape.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.Equal(t, "shapeType", parser.ProtoTree[1].(*ComplexType).Base, c.lang)
+		code := string(outputs["shape.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateMixed(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="paragraphType" mixed="true">
+    <xs:sequence>
+      <xs:element name="bold" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="noteType">
+    <xs:complexContent mixed="true">
+      <xs:extension base="paragraphType">
+        <xs:attribute name="author" type="xs:string"/>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type ParagraphType struct {\n\tValue string   `xml:\",chardata\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"public class ParagraphType {\n\t@XmlMixed\n\t@XmlAnyElement\n\tprotected List<Object> content;\n",
+			"import javax.xml.bind.annotation.XmlMixed;\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"export class ParagraphType {\n\tValue: string;\n",
+		}},
+		{"Ruby", ".rb", []string{
+			"\t\ttag \"paragraphType\"\n\t\tcontent :value, String\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "paragraph.xsd", Options{
+			Sources: map[string][]byte{"paragraph.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.True(t, parser.ProtoTree[0].(*ComplexType).Mixed, c.lang)
+		assert.True(t, parser.ProtoTree[1].(*ComplexType).Mixed, c.lang)
+		code := string(outputs["paragraph.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateNillable(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="personType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+      <xs:element name="age" type="xs:int" nillable="true"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"\tAge  PersonTypeAgeNillable `xml:\"age\"`\n",
+			"type PersonTypeAgeNillable struct {\n\tValue *int\n}\n",
+		}},
+		{"Java", ".java", []string{"\t@XmlElement(required = true, nillable = true, name = \"age\")\n\tprotected Integer Age;\n"}},
+		{"TypeScript", ".ts", []string{"\tAge: number | null;\n"}},
+		{"Rust", ".rs", []string{"\tpub age: Option<i32>,\n"}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "person.xsd", Options{
+			Sources: map[string][]byte{"person.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.True(t, parser.ProtoTree[0].(*ComplexType).Elements[1].Nillable, c.lang)
+		code := string(outputs["person.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateNillableRoundTrip(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="personType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string" nillable="true"/>
+      <xs:element name="age" type="xs:int" nillable="true"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="person" type="personType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "person.xsd", schema, Options{Package: "main"})
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var person Person
+	if err := xml.Unmarshal([]byte(`+"`"+`<person xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><name>a</name><age xsi:nil="true"/></person>`+"`"+`), &person); err != nil {
+		panic(err)
+	}
+	fmt.Println(*person.Name.Value, person.Age.Value == nil)
+	data, err := xml.Marshal(person)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+}
+`)
+	assert.Equal(t, "a true\n<person><name>a</name><age xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" xsi:nil=\"true\"></age></person>\n", output)
+}
+
+func TestGeneratePythonLxml(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:person" elementFormDefault="qualified">
+  <xs:simpleType name="colorType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="red"/>
+      <xs:enumeration value="dark-green"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="city" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="personType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+      <xs:element name="age" type="xs:int" minOccurs="0"/>
+      <xs:element name="color" type="colorType"/>
+      <xs:element name="address" type="addressType" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:int" use="required"/>
+    <xs:attribute name="active" type="xs:boolean"/>
+  </xs:complexType>
+  <xs:element name="person" type="personType"/>
+</xs:schema>`)
+	code := generateTestCode(t, "Python", "person.xsd", schema, Options{Lxml: true})["person.xsd.py"]
+	assertContains(t, string(code), []string{
+		"from lxml import etree\n",
+		"    name: str = field(metadata={\"name\": \"name\", \"namespace\": \"urn:person\", \"type\": \"Element\"})\n",
+		"    @classmethod\n    def from_element(cls, element: etree._Element) -> PersonType:\n",
+		"    def to_element(self, tag: str) -> etree._Element:\n",
+	})
+	output := runPythonCode(t, code, `from lxml import etree
+
+from schema import PersonType
+
+person = PersonType.from_element(etree.fromstring(
+    '<person xmlns="urn:person" id="1" active="true"><name>a</name><color>dark-green</color>'
+    '<address><city>b</city></address><address><city>c</city></address></person>'))
+print(person.id, person.active, person.name, person.age, person.color, [address.city for address in person.address])
+print(etree.tostring(person.to_element("{urn:person}person")).decode())
+`)
+	assert.Equal(t, "1 True a None dark-green ['b', 'c']\n"+
+		"<ns0:person xmlns:ns0=\"urn:person\" id=\"1\" active=\"true\"><ns0:name>a</ns0:name><ns0:color>dark-green</ns0:color>"+
+		"<ns0:address><ns0:city>b</ns0:city></ns0:address><ns0:address><ns0:city>c</ns0:city></ns0:address></ns0:person>\n", output)
+}
+
+func TestGenerateCSharpRecords(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+      <xs:element name="note" type="xs:string" minOccurs="0"/>
+      <xs:element name="price" type="xs:decimal" minOccurs="0"/>
+      <xs:element name="tag" type="xs:string" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:int" use="required"/>
+    <xs:attribute name="lang" type="xs:string"/>
+  </xs:complexType>
+  <xs:element name="item" type="itemType"/>
+</xs:schema>`)
+	for _, c := range []struct {
+		framework string
+		expected  []string
+	}{
+		{"net8.0", []string{
+			"#nullable enable\n",
+			"using System.Text.Json.Serialization;\n",
+			"\t[XmlType(\"itemType\")]\n\tpublic partial record ItemType\n\t{\n\t\t[JsonPropertyName(\"id\"), XmlAttribute(\"id\")]\n\t\tpublic int Id { get; init; }\n",
+			"\t\t[JsonPropertyName(\"lang\"), XmlAttribute(\"lang\")]\n\t\tpublic string? Lang { get; init; }\n",
+			"\t\t[JsonPropertyName(\"name\"), XmlElement(\"name\")]\n\t\tpublic string Name { get; init; } = null!;\n",
+			"\t\tpublic string? Note { get; init; }\n",
+			"\t\tpublic decimal? Price { get; init; }\n",
+			"\t\tpublic List<string> Tag { get; init; } = new();\n",
+			"\t[XmlRoot(\"item\")]\n\tpublic partial record Item : ItemType\n",
+		}},
+		{"net48", []string{
+			"\tpublic partial class ItemType\n\t{\n\t\t[XmlAttribute(\"id\")]\n\t\tpublic int Id { get; set; }\n",
+			"\t\t[XmlElement(\"note\")]\n\t\tpublic string Note { get; set; }\n",
+		}},
+	} {
+		code := string(generateTestCode(t, "CSharp", "item.xsd", schema, Options{TargetFramework: c.framework})["item.xsd.cs"])
+		assertContains(t, code, c.expected, c.framework)
+		if c.framework == "net48" {
+			assert.NotContains(t, code, "#nullable", c.framework)
+		}
+	}
+}
+
+func TestGeneratePHPDocBlocks(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="sizeList">
+    <xs:list itemType="xs:int"/>
+  </xs:simpleType>
+  <xs:simpleType name="sizeType">
+    <xs:union memberTypes="xs:int xs:string"/>
+  </xs:simpleType>
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="city" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+      <xs:element name="tag" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="address" type="addressType" maxOccurs="unbounded"/>
+      <xs:element name="sizes" type="sizeList"/>
+      <xs:element name="size" type="sizeType" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	code := string(generateTestCode(t, "PHP", "item.xsd", schema, Options{})["item.xsd.php"])
+	assertContains(t, code, []string{
+		"\t#[Serializer\\SerializedName('name')]\n\t#[Serializer\\Type('string')]\n\tpublic string $name;\n",
+		"\t/** @var list<string> */\n\t#[Serializer\\SerializedName('tag')]\n",
+		"\t/** @var list<AddressType> */\n\t#[Serializer\\SerializedName('address')]\n",
+		"\t/** @var list<int> */\n\t#[Serializer\\SerializedName('sizes')]\n",
+		"\t/** @var int|string|null */\n\t#[Serializer\\SerializedName('size')]\n\tpublic mixed $size = null;\n",
+	})
+	assert.NotContains(t, code, "/** @var string */")
+}
+
+func TestGenerateRubyValidation(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]+"/>
+      <xs:maxLength value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType"/>
+      <xs:element name="active" type="xs:boolean"/>
+      <xs:element name="note" type="codeType" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="enabled" type="xs:boolean" use="required"/>
+  </xs:complexType>
+</xs:schema>`)
+	code := string(generateTestCode(t, "Ruby", "item.xsd", schema, Options{})["item.xsd.rb"])
+	assertContains(t, code, []string{
+		"\t\tvalidates :enabled, inclusion: { in: [true, false] }\n",
+		"\t\tvalidates :code, presence: true, format: { with: /\\A(?:[A-Z]+)\\z/ }, length: { maximum: 3 }\n",
+		"\t\tvalidates :active, inclusion: { in: [true, false] }\n",
+		"\t\tvalidates :note, format: { with: /\\A(?:[A-Z]+)\\z/ }, length: { maximum: 3 }, allow_nil: true\n",
+	})
+}
+
+func TestGenerateOptionalElement(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="taskType">
+    <xs:sequence>
+      <xs:element name="title" type="xs:string"/>
+      <xs:element name="note" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{"\tTitle string  `xml:\"title\"`\n", "\tNote  *string `xml:\"note\"`\n"}},
+		{"Rust", ".rs", []string{"\tpub title: String,\n", "\tpub note: Option<String>,\n"}},
+		{"TypeScript", ".ts", []string{"\tTitle: string;\n", "\tNote: string;\n"}},
+		{"C", ".h", []string{"\tchar Title;\n", "\tchar Note;\n"}},
+		{"Java", ".java", []string{"\t@XmlElement(required = true, name = \"note\")\n\tprotected String Note;\n"}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "task.xsd", Options{
+			Sources: map[string][]byte{"task.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		elements := parser.ProtoTree[0].(*ComplexType).Elements
+		assert.Equal(t, []bool{false, true}, []bool{elements[0].Optional, elements[1].Optional}, c.lang)
+		assertContains(t, string(outputs["task.xsd"+c.ext]), c.expected, c.lang)
+	}
+
+	outputs := generateTestCode(t, "Go", "task.xsd", schema, Options{Package: "main"})
+	assert.Equal(t, "<nil> true\n", runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var absent, present TaskType
+	if err := xml.Unmarshal([]byte("<task><title>a</title></task>"), &absent); err != nil {
+		panic(err)
+	}
+	if err := xml.Unmarshal([]byte("<task><title>a</title><note></note></task>"), &present); err != nil {
+		panic(err)
+	}
+	fmt.Println(absent.Note, present.Note != nil && *present.Note == "")
+}
+`))
+}
+
+func TestGenerateCMake(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="note" type="xs:string"/>
+  <xs:element name="order" type="xs:string"/>
+</xs:schema>`)
+	outputs := map[string][]byte{"test_order.c": []byte("// filled in by hand\n")}
+	parser := newTestParser("C", "order.xsd", Options{
+		Sources: map[string][]byte{"order.xsd": schema},
+		Outputs: outputs,
+		CMake:   true,
+	})
+	assert.NoError(t, parser.Parse())
+	assertContains(t, string(outputs["CMakeLists.txt"]), []string{"project(schema C)\n", "add_test(NAME ${XGEN_TARGET} COMMAND ${XGEN_TARGET})\n"})
+	assertContains(t, string(outputs["test_note.c"]), []string{"#include \"order.xsd.h\"\n", "\tNote root;\n"})
+	assert.Equal(t, "// filled in by hand\n", string(outputs["test_order.c"]))
+	for _, path := range []string{"CMakeLists.txt", "test_note.c"} {
+		assert.NotContains(t, string(outputs[path]), "DO NOT EDIT", path)
+	}
+}
+
+func TestGenerateOccurs(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+      <xs:element name="note" type="xs:string" minOccurs="0"/>
+      <xs:element name="item" type="xs:string" minOccurs="1" maxOccurs="3"/>
+      <xs:element name="tag" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"\tId   string   `xml:\"id\"`\n",
+			"\tNote *string  `xml:\"note\"`\n",
+			"\tItem []string `xml:\"item\"`\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"\tItem: z.array(z.string()).min(1).max(3),\n",
+			"\tTag: z.array(z.string()).optional(),\n",
+		}},
+		{"Ruby", ".rb", []string{
+			"\t\tvalidates :item, length: { minimum: 1, maximum: 3 }\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Zod:     true,
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		elements := parser.ProtoTree[0].(*ComplexType).Elements
+		assert.Equal(t, []int{1, 0, 1, 0}, []int{elements[0].MinOccurs, elements[1].MinOccurs, elements[2].MinOccurs, elements[3].MinOccurs}, c.lang)
+		assert.Equal(t, []int{1, 1, 3, Unbounded}, []int{elements[0].MaxOccurs, elements[1].MaxOccurs, elements[2].MaxOccurs, elements[3].MaxOccurs}, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateDefault(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="quantity" type="xs:int" default="1" minOccurs="0"/>
+      <xs:element name="gift" type="xs:boolean" fixed="false"/>
+    </xs:sequence>
+    <xs:attribute name="currency" type="xs:string" default="USD"/>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"func NewOrderType() *OrderType {\n\tv := &OrderType{}\n\tv.CurrencyAttr = \"USD\"\n\tv.Quantity = new(int)\n\t*v.Quantity = 1\n\tv.Gift = false\n\treturn v\n}\n",
+		}},
+		{"Java", ".java", []string{
+			"\tprotected Integer Quantity = 1;\n",
+			"\tprotected Boolean Gift = false;\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"\tCurrencyAttr: string | null = \"USD\";\n",
+			"\tQuantity: number = 1;\n",
+			"\tQuantity: z.number().optional().default(1),\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Zod:     true,
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		complexType := parser.ProtoTree[0].(*ComplexType)
+		assert.Equal(t, "1", complexType.Elements[0].Default, c.lang)
+		assert.Equal(t, "false", complexType.Elements[1].Fixed, c.lang)
+		assert.Equal(t, "USD", complexType.Attributes[0].Default, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestParseInclude(t *testing.T) {
+	sources := map[string][]byte{
+		"order.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:geo="urn:geo">
+  <xs:include schemaLocation="common/address.xsd"/>
+  <xs:import namespace="urn:geo" schemaLocation="geo/geo.xsd"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType"/>
+      <xs:element name="address" type="addressType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"common/address.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="code.xsd"/>
+  <xs:include schemaLocation="../order.xsd"/>
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="zip" type="codeType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"common/code.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`),
+		"geo/geo.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:geo">
+  <xs:include schemaLocation="point.xsd"/>
+</xs:schema>`),
+		"geo/point.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:geo">
+  <xs:complexType name="pointType">
+    <xs:sequence>
+      <xs:element name="lat" type="xs:double"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	outputs := map[string][]byte{}
+	parser := newTestParser("Go", "order.xsd", Options{
+		Sources: sources,
+		Outputs: outputs,
+	})
+	assert.NoError(t, parser.Parse())
+	assert.Equal(t, map[string]bool{"common/address.xsd": true, "common/code.xsd": true, "geo/point.xsd": true}, parser.IncludeMap)
+	for _, name := range []string{"order.xsd.go", "common/address.xsd.go", "common/code.xsd.go", "geo/geo.xsd.go", "geo/point.xsd.go"} {
+		assert.Contains(t, outputs, name)
+	}
+	assert.Contains(t, string(outputs["order.xsd.go"]), "\tCode    string       `xml:\"code\"`\n\tAddress *AddressType `xml:\"address\"`\n")
+	assert.Contains(t, string(outputs["common/address.xsd.go"]), "\tZip string `xml:\"zip\"`\n")
+	assert.Contains(t, string(outputs["geo/point.xsd.go"]), "type PointType struct {\n")
+	assert.Equal(t, []string{"order.xsd -> common/address.xsd -> order.xsd"}, parser.Cycles)
+
+	sources = map[string][]byte{
+		"a.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:a">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+</xs:schema>`),
+		"b.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:b">
+  <xs:import namespace="urn:a" schemaLocation="a.xsd"/>
+</xs:schema>`),
+	}
+	parser = newTestParser("Go", "a.xsd", Options{
+		Sources: sources,
+		Outputs: map[string][]byte{},
+	})
+	assert.NoError(t, parser.Parse())
+	assert.Equal(t, []string{"a.xsd -> b.xsd -> a.xsd"}, parser.Cycles)
+}
+
+func TestParseChameleonInclude(t *testing.T) {
+	sources := map[string][]byte{
+		"a.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:a">
+  <xs:include schemaLocation="note.xsd"/>
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:element name="memo" type="noteType"/>
+</xs:schema>`),
+		"b.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:b">
+  <xs:include schemaLocation="note.xsd"/>
+</xs:schema>`),
+		"note.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="noteType">
+    <xs:sequence>
+      <xs:element name="text" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	outputs := map[string][]byte{}
+	parser := newTestParser("Go", "a.xsd", Options{
+		Sources: sources,
+		Outputs: outputs,
+	})
+	assert.NoError(t, parser.Parse())
+	assert.Equal(t, map[string]bool{"note.xsd": true, "note.xsd#urn:a": true, "note.xsd#urn:b": true}, parser.IncludeMap)
+	assert.NotContains(t, string(outputs["note.xsd.go"]), "XMLName")
+	for _, ele := range parser.included {
+		if v, ok := ele.(*ComplexType); ok {
+			assert.Equal(t, "urn:a", v.Namespace)
+		}
+	}
+}
+
+func TestGenerateNamespace(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:order" targetNamespace="urn:order" elementFormDefault="qualified">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+      <xs:element name="note" type="xs:string" form="unqualified"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="order" type="tns:orderType"/>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type Order struct {\n\tXMLName xml.Name `xml:\"urn:order order\"`\n\tOrderType\n}\n",
+			"\tId   string `xml:\"urn:order id\"`\n",
+			"\tNote string `xml:\"note\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"@XmlType(name = \"orderType\", namespace = \"urn:order\")\n",
+			"\t@XmlElement(required = true, name = \"id\", namespace = \"urn:order\")\n",
+			"\t@XmlElement(required = true, name = \"note\")\n",
+			"@XmlRootElement(name = \"order\", namespace = \"urn:order\")\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"\tstatic readonly Namespace = \"urn:order\";\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		complexType := parser.ProtoTree[0].(*ComplexType)
+		assert.Equal(t, "urn:order", complexType.Namespace, c.lang)
+		assert.Equal(t, "tns", complexType.Prefix, c.lang)
+		assert.Equal(t, "urn:order", complexType.Elements[0].Namespace, c.lang)
+		assert.Equal(t, "", complexType.Elements[1].Namespace, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateNamespaceRoundTrip(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:tree" targetNamespace="urn:tree" elementFormDefault="qualified">
+  <xs:complexType name="nodeType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+      <xs:element name="child" type="tns:nodeType" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="tree" type="tns:nodeType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "tree.xsd", schema, Options{Package: "main"})
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var tree Tree
+	if err := xml.Unmarshal([]byte(`+"`"+`<tree xmlns="urn:tree"><name>a</name><child><name>b</name><child><name>c</name></child></child></tree>`+"`"+`), &tree); err != nil {
+		panic(err)
+	}
+	fmt.Println(tree.Name, tree.Child[0].Name, tree.Child[0].Child[0].Name)
+	data, err := xml.Marshal(tree)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+}
+`)
+	assert.Equal(t, "a b c\n<tree xmlns=\"urn:tree\"><name xmlns=\"urn:tree\">a</name><child xmlns=\"urn:tree\"><name xmlns=\"urn:tree\">b</name><child xmlns=\"urn:tree\"><name xmlns=\"urn:tree\">c</name></child></child></tree>\n", output)
+}
+
+func TestGenerateAttributeForm(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="urn:order" targetNamespace="urn:order" attributeFormDefault="qualified">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="currency" type="xs:string"/>
+    <xs:attribute name="status" type="xs:string" form="unqualified"/>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"`xml:\"urn:order currency,attr,omitempty\"`\n",
+			"`xml:\"status,attr,omitempty\"`\n",
+			"`xml:\"id\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"\t@XmlAttribute(name = \"currency\", namespace = \"urn:order\")\n",
+			"\t@XmlAttribute(name = \"status\")\n",
+			"\t@XmlElement(required = true, name = \"id\")\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		complexType := parser.ProtoTree[0].(*ComplexType)
+		assert.Equal(t, "urn:order", complexType.Attributes[0].Namespace, c.lang)
+		assert.Equal(t, "tns", complexType.Attributes[0].Prefix, c.lang)
+		assert.Equal(t, "", complexType.Attributes[1].Namespace, c.lang)
+		assert.Equal(t, "", complexType.Elements[0].Namespace, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateAnonymous(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="item" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="sku" type="xs:string"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:element>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:complexType name="shipType">
+    <xs:sequence>
+      <xs:element name="item">
+        <xs:complexType>
+          <xs:sequence>
+            <xs:element name="weight" type="xs:int"/>
+          </xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type OrderItem struct {\n\tSku string `xml:\"sku\"`\n}\n",
+			"\tItem    []*OrderItem `xml:\"item\"`\n",
+			"type ShipTypeItem struct {\n\tWeight int `xml:\"weight\"`\n}\n",
+			"\tItem *ShipTypeItem `xml:\"item\"`\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"export class Order_Item {\n\tSku: string;\n}\n",
+			"\tItem: Array<Order_Item>;\n",
+			"\tItem: ShipType_Item;\n",
+		}},
+		{"Java", ".java", []string{
+			"\tprotected List<Order_Item> Item;\n",
+			"\tprotected ShipType_Item Item;\n",
+		}},
+		{"Rust", ".rs", []string{
+			"\tpub item: Vec<OrderItem>,\n",
+			"\tpub item: ShipTypeItem,\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		item := parser.ProtoTree[0].(*ComplexType)
+		assert.Equal(t, "order_Item", item.Name, c.lang)
+		assert.True(t, item.Anonymous, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateAnonymousSimpleType(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:group name="lineGroup">
+    <xs:sequence>
+      <xs:element name="qty">
+        <xs:simpleType>
+          <xs:restriction base="xs:int">
+            <xs:minInclusive value="1"/>
+            <xs:maxInclusive value="99"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+    </xs:sequence>
+  </xs:group>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="count">
+        <xs:simpleType>
+          <xs:restriction base="xs:int"/>
+        </xs:simpleType>
+      </xs:element>
+      <xs:element name="kind">
+        <xs:simpleType>
+          <xs:restriction base="xs:string">
+            <xs:enumeration value="retail"/>
+            <xs:enumeration value="wholesale"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+      <xs:element name="note" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="code">
+      <xs:simpleType>
+        <xs:restriction base="xs:string">
+          <xs:maxLength value="8"/>
+        </xs:restriction>
+      </xs:simpleType>
+    </xs:attribute>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type LineGroup struct {\n\tQty int\n}\n",
+			"\tCount    int    `xml:\"count\"`\n",
+			"\tKind     string `xml:\"kind\"`\n",
+			"\tNote     string `xml:\"note\"`\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"\tQty: z.number().gte(1).lte(99),\n",
+			"\tCount: z.number(),\n",
+			"\tKind: z.enum([\"retail\", \"wholesale\"]),\n",
+			"\tCodeAttr: z.string().max(8).nullable(),\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Zod:     true,
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		assert.Len(t, parser.ProtoTree, 2, c.lang)
+		group := parser.ProtoTree[0].(*Group)
+		assert.Equal(t, 99.0, group.Elements[0].Restriction.Max, c.lang)
+		complexType := parser.ProtoTree[1].(*ComplexType)
+		assert.Len(t, complexType.Elements, 3, c.lang)
+		assert.Equal(t, []string{"retail", "wholesale"}, complexType.Elements[1].Restriction.Enum, c.lang)
+		assert.Equal(t, 8, complexType.Attributes[0].Restriction.MaxLength, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateList(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="sizesType">
+    <xs:list itemType="xs:int"/>
+  </xs:simpleType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="sizes" type="sizesType"/>
+      <xs:element name="tags">
+        <xs:simpleType>
+          <xs:list itemType="xs:string"/>
+        </xs:simpleType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"type SizesType []int\n",
+			"func (v SizesType) MarshalText() ([]byte, error) {\n",
+			"func (v *SizesType) UnmarshalText(text []byte) error {\n",
+			"\tTags  *OrderTypeTags `xml:\"tags\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"\t@XmlValue\n\t@XmlList\n\tprotected List<Integer> SizesType;\n",
+			"\tprotected OrderType_Tags Tags;\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"export type SizesType = Array<number>;\n",
+			"export function parseSizesType(value: string): SizesType {\n\treturn value.split(/\\s+/).filter((item) => item !== '').map((item) => Number(item));\n}\n",
+			"export function formatSizesType(value: SizesType): string {\n\treturn value.join(' ');\n}\n",
+			"\tTags: OrderType_Tags;\n",
+		}},
+		{"Rust", ".rs", []string{
+			"pub struct SizesType(pub Vec<i32>);\n",
+			"impl serde::Serialize for SizesType {\n",
+			"impl<'de> serde::Deserialize<'de> for SizesType {\n",
+			"\tpub tags: OrderTypeTags,\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		list := parser.ProtoTree[1].(*SimpleType)
+		assert.Equal(t, "orderType_Tags", list.Name, c.lang)
+		assert.True(t, list.List, c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateUnionOrder(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="sizeType">
+    <xs:union memberTypes="xs:string xs:int  xs:boolean xs:decimal"/>
+  </xs:simpleType>
+</xs:schema>`)
+	var previous string
+	for i := 0; i < 10; i++ {
+		outputs := map[string][]byte{}
+		parser := newTestParser("Go", "size.xsd", Options{
+			Sources: map[string][]byte{"size.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse())
+		assert.Equal(t, []MemberType{
+			{Name: "string", Type: "string"},
+			{Name: "int", Type: "int"},
+			{Name: "boolean", Type: "bool"},
+			{Name: "decimal", Type: "float64"},
+		}, parser.ProtoTree[0].(*SimpleType).MemberTypes)
+		code := string(outputs["size.xsd.go"])
+		assert.Contains(t, code, "\tString  string\n\tInt     int\n\tBoolean bool\n\tDecimal float64\n")
+		if previous != "" {
+			assert.Equal(t, previous, code)
+		}
+		previous = code
+	}
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="nodeType">
+    <xs:sequence>
+      <xs:element name="parent" type="nodeType" minOccurs="0"/>
+      <xs:element name="child" type="nodeType" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="link" type="linkType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="linkType">
+    <xs:sequence>
+      <xs:element name="target" type="nodeType"/>
+      <xs:element name="label" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"\tParent *NodeType   `xml:\"parent\"`\n",
+			"\tChild  []*NodeType `xml:\"child\"`\n",
+		}},
+		{"Java", ".java", []string{
+			"\tprotected NodeType Parent;\n",
+			"\tprotected List<NodeType> Child;\n",
+		}},
+		{"Rust", ".rs", []string{
+			"\tpub parent: Option<Box<NodeType>>,\n",
+			"\tpub child: Vec<NodeType>,\n",
+			"\tpub link: Box<LinkType>,\n",
+			"\tpub target: Box<NodeType>,\n",
+			"\tpub label: String,\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "tree.xsd", schema, Options{})
+		code := string(outputs["tree.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+	}
+}
+
+func TestGenerateAppinfo(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:jaxb="http://java.sun.com/xml/ns/jaxb" xmlns:xgen="https://github.com/xuri/xgen">
+  <xs:complexType name="orderType">
+    <xs:annotation>
+      <xs:appinfo>
+        <jaxb:class name="PurchaseOrder"/>
+      </xs:appinfo>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="id" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo>column=ID</xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="codeType">
+    <xs:annotation>
+      <xs:appinfo><hint key="width">8</hint><xgen:name>Code</xgen:name></xs:appinfo>
+    </xs:annotation>
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"// OrderType ...\n//\n// appinfo: <class xmlns=\"http://java.sun.com/xml/ns/jaxb\" name=\"PurchaseOrder\"></class>\ntype OrderType struct {\n",
+			"// Code ...\n//\n// appinfo: <hint key=\"width\">8</hint>\ntype Code string\n",
+		}},
+		{"Java", ".java", []string{
+			"// appinfo: <class xmlns=\"http://java.sun.com/xml/ns/jaxb\" name=\"PurchaseOrder\"></class>\r\npublic class OrderType {\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"// appinfo: <class xmlns=\"http://java.sun.com/xml/ns/jaxb\" name=\"PurchaseOrder\"></class>\r\nexport class OrderType {\n",
+			"// appinfo: <hint key=\"width\">8</hint>\r\nexport type Code = string;\n",
+		}},
+	} {
+		outputs := map[string][]byte{}
+		parser := newTestParser(c.lang, "order.xsd", Options{
+			Sources: map[string][]byte{"order.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse(), c.lang)
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+		assert.Equal(t, []string{"column=ID"}, parser.ProtoTree[0].(*ComplexType).Elements[0].Appinfo, c.lang)
+	}
+}
+
+func TestGenerateXSD11(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:vc="http://www.w3.org/2007/XMLSchema-versioning">
+  <xs:complexType name="rangeType">
+    <xs:openContent mode="suffix">
+      <xs:any namespace="##other" processContents="lax"/>
+    </xs:openContent>
+    <xs:sequence>
+      <xs:element name="shape" type="shapeType">
+        <xs:alternative test="@kind = 'circle'" type="circleType"/>
+        <xs:alternative test="@kind = 'box'">
+          <xs:complexType>
+            <xs:sequence>
+              <xs:element name="side" type="xs:int"/>
+            </xs:sequence>
+          </xs:complexType>
+        </xs:alternative>
+      </xs:element>
+      <xs:element name="stamp" type="xs:dateTimeStamp"/>
+    </xs:sequence>
+    <xs:attribute name="min" type="xs:int"/>
+    <xs:attribute name="max" type="xs:int"/>
+    <xs:assert test="@min le @max"/>
+  </xs:complexType>
+  <xs:complexType name="shapeType">
+    <xs:attribute name="kind" type="xs:string"/>
+  </xs:complexType>
+  <xs:complexType name="circleType">
+    <xs:complexContent>
+      <xs:extension base="shapeType">
+        <xs:attribute name="radius" type="xs:int"/>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="legacy" type="xs:string" vc:maxVersion="1.1"/>
+  <xs:element name="modern" type="xs:dayTimeDuration" vc:minVersion="1.1"/>
+</xs:schema>`)
+	for _, xsd11 := range []bool{false, true} {
+		outputs := map[string][]byte{}
+		parser := newTestParser("Go", "range.xsd", Options{
+			XSD11:   xsd11,
+			Sources: map[string][]byte{"range.xsd": schema},
+			Outputs: outputs,
+		})
+		assert.NoError(t, parser.Parse())
+		code := string(outputs["range.xsd.go"])
+		rangeType := parser.ProtoTree[0].(*ComplexType)
+		assert.Equal(t, "shape", rangeType.Elements[0].Name)
+		assert.Len(t, rangeType.Elements, 2)
+		assert.Contains(t, code, "\tStamp   XsdDateTime `xml:\"stamp\"`\n")
+		assert.NotContains(t, code, "Side")
+		if !xsd11 {
+			assert.Contains(t, code, "type Legacy string\n")
+			assert.NotContains(t, code, "Modern")
+			assert.NotContains(t, code, "ValidateAssertions")
+			assert.Empty(t, rangeType.Assertions)
+			continue
+		}
+		assert.Contains(t, code, "type Modern string\n")
+		assert.NotContains(t, code, "Legacy")
+		assert.Equal(t, []string{"@min le @max"}, rangeType.Assertions)
+		assert.Equal(t, "suffix", rangeType.OpenContent)
+		assert.Equal(t, []Alternative{{Test: "@kind = 'circle'", Type: "circleType"}, {Test: "@kind = 'box'"}}, rangeType.Elements[0].Alternatives)
+		assert.Contains(t, code, "func (v *RangeType) ValidateAssertions(eval func(v interface{}, test string) (bool, error)) error {\n\tfor _, test := range []string{\"@min le @max\"} {\n")
+	}
+}
+
+func TestGenerateValidate(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3}"/>
+      <xs:maxLength value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="percentType">
+    <xs:restriction base="xs:int">
+      <xs:minInclusive value="0"/>
+      <xs:maxExclusive value="100"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="priceType">
+    <xs:attribute name="rate" type="percentType"/>
+  </xs:complexType>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType" minOccurs="0"/>
+      <xs:element name="price" type="priceType" maxOccurs="unbounded"/>
+      <xs:element name="note" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="noteType">
+    <xs:sequence>
+      <xs:element name="note" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "item.xsd", schema, Options{})
+	code := string(outputs["item.xsd.go"])
+	assert.Contains(t, code, "var patternCodeType = regexp.MustCompile(\"^(?:[A-Z]{3})$\")\n")
+	assert.Contains(t, code, "func (v CodeType) Validate() error {\n\tif utf8.RuneCountInString(string(v)) > 3 {\n\t\treturn fmt.Errorf(\"CodeType: length %d is greater than 3\", utf8.RuneCountInString(string(v)))\n\t}\n")
+	assert.Contains(t, code, "func (v PercentType) Validate() error {\n\tif float64(v) < 0 {\n\t\treturn fmt.Errorf(\"PercentType: %v is less than 0\", v)\n\t}\n\tif float64(v) >= 100 {\n\t\treturn fmt.Errorf(\"PercentType: %v is not less than 100\", v)\n\t}\n\treturn nil\n}\n")
+	assert.Contains(t, code, "func (v *PriceType) Validate() error {\n\tif v.RateAttr != 0 {\n\t\tif float64(v.RateAttr) < 0 {\n")
+	assert.Contains(t, code, "func (v *ItemType) Validate() error {\n\tif v.Code != nil {\n\t\tif utf8.RuneCountInString(*v.Code) > 3 {\n")
+	assert.Contains(t, code, "\tif v.Code != nil {\n\t\tif utf8.RuneCountInString(*v.Code) > 3 {\n\t\t\treturn fmt.Errorf(\"ItemType.Code: length %d is greater than 3\", utf8.RuneCountInString(*v.Code))\n\t\t}\n\t\tif !patternItemTypeCode.MatchString(*v.Code) {\n")
+	assert.Contains(t, code, "\tfor _, item := range v.Price {\n\t\tif item != nil {\n\t\t\tif err := item.Validate(); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t}\n\t}\n\treturn nil\n}\n")
+	assert.NotContains(t, code, "func (v *NoteType) Validate")
+}
+
+func TestGenerateOptionalPointers(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:attributeGroup name="tagged">
+    <xs:attribute name="tag" type="xs:string"/>
+  </xs:attributeGroup>
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="code" type="xs:string" minOccurs="0"/>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="rate" type="xs:int"/>
+    <xs:attribute name="flag" type="xs:boolean" default="true"/>
+    <xs:attribute name="id" type="xs:string" use="required"/>
+    <xs:attributeGroup ref="tagged"/>
+  </xs:complexType>
+</xs:schema>`)
+	for _, optionalPointers := range []bool{false, true} {
+		outputs := generateTestCode(t, "Go", "item.xsd", schema, Options{OptionalPointers: optionalPointers})
+		code := string(outputs["item.xsd.go"])
+		assert.Contains(t, code, "\tIdAttr   string  `xml:\"id,attr\"`\n")
+		assert.Contains(t, code, "\tName     string  `xml:\"name\"`\n")
+		if !optionalPointers {
+			assert.Contains(t, code, "\tRateAttr int     `xml:\"rate,attr,omitempty\"`\n")
+			assert.Contains(t, code, "\tCode     *string `xml:\"code\"`\n")
+			assert.Contains(t, code, "\tTagAttr string `xml:\"tag,attr,omitempty\"`\n")
+			assert.Contains(t, code, "\tv.FlagAttr = true\n")
+			continue
+		}
+		assert.Contains(t, code, "\tRateAttr *int    `xml:\"rate,attr,omitempty\"`\n")
+		assert.Contains(t, code, "\tFlagAttr *bool   `xml:\"flag,attr,omitempty\"`\n")
+		assert.Contains(t, code, "\tCode     *string `xml:\"code,omitempty\"`\n")
+		assert.Contains(t, code, "\tTagAttr *string `xml:\"tag,attr,omitempty\"`\n")
+		assert.Contains(t, code, "\tv.FlagAttr = new(bool)\n\t*v.FlagAttr = true\n")
+	}
+}
+
+func TestGenerateStructTags(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="itemType">
+    <xs:sequence>
+      <xs:element name="item_code" type="xs:string" minOccurs="0"/>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string" use="required"/>
+  </xs:complexType>
+  <xs:element name="item" type="itemType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "item.xsd", schema, Options{
+		StructTags: []string{"json", "yaml"},
+		Naming:     NamingConvention{Tag: CamelCase},
+	})
+	code := string(outputs["item.xsd.go"])
+	assert.Contains(t, code, "\tXMLName xml.Name `xml:\"item\" json:\"-\" yaml:\"-\"`\n")
+	assert.Contains(t, code, "`xml:\"id,attr\" json:\"idAttr\" yaml:\"idAttr\"`\n")
+	assert.Contains(t, code, "`xml:\"item_code\" json:\"itemCode,omitempty\" yaml:\"itemCode,omitempty\"`\n")
+	assert.Contains(t, code, "`xml:\"id\" json:\"id\" yaml:\"id\"`\n")
+	assert.True(t, IsValidStructTag("json"))
+	assert.False(t, IsValidStructTag("toml"))
+}
+
+func TestGenerateSplitFiles(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]+"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType"/>
+      <xs:element name="at" type="xs:dateTime"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="order" type="orderType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{
+		OutputDir:  "out",
+		SplitFiles: true,
+	})
+	assert.NotContains(t, outputs, "out/order.xsd.go")
+	code := string(outputs["out/code_type.go"])
+	assert.Contains(t, code, "import (\n\t\"fmt\"\n\t\"regexp\"\n)\n")
+	assert.Contains(t, code, "type CodeType string\n")
+	assert.NotContains(t, code, "OrderType")
+	code = string(outputs["out/order_type.go"])
+	assert.Contains(t, code, "import (\n\t\"fmt\"\n\t\"regexp\"\n)\n")
+	assert.Contains(t, code, "type OrderType struct {\n")
+	assert.Contains(t, code, "func (v *OrderType) Validate() error {\n")
+	assert.Contains(t, outputs, "out/order.go")
+	assert.Contains(t, outputs, "out/xsdtime.go")
+}
+
+func TestGenerateNSPackages(t *testing.T) {
+	sources := map[string][]byte{
+		"order.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order/v1" xmlns:o="http://example.com/order/v1" xmlns:c="http://example.com/common">
+  <xs:import namespace="http://example.com/common" schemaLocation="common.xsd"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="line" type="c:lineType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"common.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/common">
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	outputs := generateTestCode(t, "Go", "order.xsd", nil, Options{
+		OutputDir:           "out",
+		PackagePerNamespace: true,
+		ModulePath:          "example.com/gen",
+		Sources:             sources,
+	})
+	code := string(outputs["out/order/order.xsd.go"])
+	assert.Contains(t, code, "package order\n")
+	assert.Contains(t, code, "\t\"example.com/gen/common\"\n")
+	assert.Contains(t, code, "[]*common.LineType")
+	assert.Contains(t, string(outputs["out/common/common.xsd.go"]), "package common\n")
+
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	_, err = GoModulePath(dir)
+	assert.Error(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/gen\n\ngo 1.14\n"), 0644))
+	modulePath, err := GoModulePath(filepath.Join(dir, "api", "schema"))
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com/gen/api/schema", modulePath)
+}
+
+func TestGenerateConstructors(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="baseType">
+    <xs:sequence>
+      <xs:element name="kind" type="xs:string" default="postal"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="addressType">
+    <xs:complexContent>
+      <xs:extension base="baseType">
+        <xs:sequence>
+          <xs:element name="zip" type="xs:string"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="nodeType">
+    <xs:sequence>
+      <xs:element name="next" type="nodeType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="address" type="addressType"/>
+      <xs:element name="line" type="lineType"/>
+      <xs:element name="note" type="lineType" minOccurs="0"/>
+      <xs:element name="lines" type="lineType" maxOccurs="unbounded"/>
+      <xs:element name="node" type="nodeType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{})
+	code := string(outputs["order.xsd.go"])
+	assert.Contains(t, code, "func NewAddressType() *AddressType {\n\tv := &AddressType{}\n\tv.BaseType = *NewBaseType()\n\treturn v\n}\n")
+	assert.Contains(t, code, "func NewOrderType() *OrderType {\n\tv := &OrderType{}\n\tv.Address = NewAddressType()\n\tv.Line = &LineType{}\n\tv.Node = &NodeType{}\n\treturn v\n}\n")
+	assert.NotContains(t, code, "func NewLineType()")
+	assert.NotContains(t, code, "func NewNodeType()")
+}
+
+func TestGenerateSubstitutionHolder(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="vehicleType">
+    <xs:sequence>
+      <xs:element name="wheels" type="xs:int"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="vehicle" type="vehicleType"/>
+  <xs:element name="bike" substitutionGroup="vehicle"/>
+  <xs:complexType name="garageType">
+    <xs:sequence>
+      <xs:element ref="vehicle" maxOccurs="unbounded"/>
+      <xs:element name="owner" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "garage.xsd", schema, Options{})
+	code := string(outputs["garage.xsd.go"])
+	assert.Contains(t, code, "type Bike struct {\n\tXMLName xml.Name `xml:\"bike\"`\n\tVehicleType\n}\n")
+	assert.Contains(t, code, "type AnyVehicle struct {\n\tValue VehicleGroup\n}\n")
+	assert.Contains(t, code, "\tswitch start.Name.Local {\n\tcase \"vehicle\":\n\t\tv.Value = new(Vehicle)\n\tcase \"bike\":\n\t\tv.Value = new(Bike)\n\tdefault:\n\t\treturn d.Skip()\n\t}\n")
+	assert.Contains(t, code, "\tcase Bike, *Bike:\n\t\tstart.Name = xml.Name{Local: \"bike\"}\n")
+	assert.Contains(t, code, "\tVehicle []AnyVehicle `xml:\",any\"`\n\tOwner   string       `xml:\"owner\"`\n")
+}
+
+func TestGenerateBigNumbers(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="qty">
+        <xs:simpleType>
+          <xs:restriction base="xs:positiveInteger">
+            <xs:maxInclusive value="100"/>
+          </xs:restriction>
+        </xs:simpleType>
+      </xs:element>
+      <xs:element name="price" type="xs:decimal"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, bigNumbers := range []bool{false, true} {
+		outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{BigNumbers: bigNumbers})
+		code := string(outputs["order.xsd.go"])
+		if !bigNumbers {
+			assert.Contains(t, code, "\tQty   int     `xml:\"qty\"`\n\tPrice float64 `xml:\"price\"`\n")
+			assert.NotContains(t, outputs, "xsdnumber.go")
+			continue
+		}
+		assert.Contains(t, code, "\tQty   XsdInteger `xml:\"qty\"`\n\tPrice XsdDecimal `xml:\"price\"`\n")
+		assert.Contains(t, code, "compareXsdNumber(v.Qty.String(), \"100\") > 0")
+		assert.Contains(t, string(outputs["xsdnumber.go"]), "type XsdInteger struct {\n\tbig.Int\n}\n")
+	}
+}
+
+func TestGenerateGoImports(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="moneyType">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="placed" type="xs:dateTime"/>
+      <xs:element name="total" type="moneyType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="order" type="orderType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{
+		TypeMapping: map[string]TypeMapping{
+			"moneyType":  {Type: "money.Amount", Import: "github.com/acme/money"},
+			"unusedType": {Type: "unused.Type", Import: "github.com/acme/unused"},
+		},
+	})
+	code := string(outputs["order.xsd.go"])
+	assert.Contains(t, code, "import (\n\t\"encoding/xml\"\n\n\t\"github.com/acme/money\"\n)\n")
+	assert.Contains(t, code, "\tTotal  money.Amount `xml:\"total\"`\n")
+	assert.NotContains(t, code, "github.com/acme/unused")
+	assert.Contains(t, string(outputs["xsdtime.go"]), "import (\n\t\"encoding/xml\"\n\t\"fmt\"\n\t\"strings\"\n\t\"time\"\n)\n")
+}
+
+func TestGenerateDeepCopy(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="nodeType">
+    <xs:sequence>
+      <xs:element name="child" type="nodeType" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="leafType">
+    <xs:sequence>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "node.xsd", schema, Options{DeepCopy: true})
+	code := string(outputs["node.xsd.go"])
+	assert.Contains(t, code, "func (in *NodeType) DeepCopyInto(out *NodeType) {\n\t*out = *in\n\tif in.Child != nil {\n\t\tin, out := &in.Child, &out.Child\n\t\t*out = make([]*NodeType, len(*in))\n\t\tfor i := range *in {\n\t\t\tif (*in)[i] != nil {\n\t\t\t\tin, out := &(*in)[i], &(*out)[i]\n\t\t\t\t*out = new(NodeType)\n\t\t\t\t(*in).DeepCopyInto(*out)\n\t\t\t}\n\t\t}\n\t}\n}\n")
+	assert.Contains(t, code, "func (in *NodeType) DeepCopy() *NodeType {\n")
+	assert.Contains(t, code, "func (in *LeafType) DeepCopyInto(out *LeafType) {\n\t*out = *in\n}\n")
+}
+
+func TestGenerateDeepCopyNSPackages(t *testing.T) {
+	sources := map[string][]byte{
+		"order.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order" xmlns:c="http://example.com/common">
+  <xs:import namespace="http://example.com/common" schemaLocation="common.xsd"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="address" type="c:addressType"/>
+      <xs:element name="previous" type="c:addressType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"common.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/common">
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="city" type="xs:string" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	outputs := generateTestCode(t, "Go", "order.xsd", nil, Options{
+		PackagePerNamespace: true,
+		ModulePath:          "example.com/gen",
+		DeepCopy:            true,
+		Sources:             sources,
+	})
+	assert.Contains(t, string(outputs["order/order.xsd.go"]), "\t\t*out = new(common.AddressType)\n\t\t(*in).DeepCopyInto(*out)\n")
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"fmt"
+
+	"example.com/gen/common"
+	"example.com/gen/order"
+)
+
+func main() {
+	in := &order.OrderType{
+		Address:  &common.AddressType{City: []string{"a"}},
+		Previous: []*common.AddressType{{City: []string{"b"}}},
+	}
+	out := in.DeepCopy()
+	out.Address.City[0], out.Previous[0].City[0] = "x", "y"
+	fmt.Println(in.Address.City[0], in.Previous[0].City[0])
+}
+`)
+	assert.Equal(t, "a b\n", output)
+}
+
+func TestGenerateStringer(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="textType">
+    <xs:sequence>
+      <xs:element name="string" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{Stringer: true})
+	code := string(outputs["order.xsd.go"])
+	assert.Contains(t, code, "// String returns the OrderType as the indented XML for debugging.\nfunc (v OrderType) String() string {\n\treturn XsdDump(&v)\n}\n")
+	assert.NotContains(t, code, "func (v TextType) String() string")
+	assert.Contains(t, string(outputs["xsddump.go"]), "func XsdDump(v interface{}) string {\n\toutput, err := xml.MarshalIndent(v, \"\", \"  \")\n")
+}
+
+func TestGenerateDecodeEach(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="order" type="orderType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{DecodeEach: true})
+	code := string(outputs["order.xsd.go"])
+	assert.Contains(t, code, "import (\n\t\"encoding/xml\"\n\t\"io\"\n)\n")
+	assert.Contains(t, code, "func DecodeEachOrder(r io.Reader, fn func(*Order) error) error {\n\td := xml.NewDecoder(r)\n")
+	assert.Contains(t, code, "\t\tif !ok || start.Name.Local != \"order\" {\n\t\t\tcontinue\n\t\t}\n\t\tv := new(Order)\n")
+}
+
+func TestGenerateXsdTime(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="eventType">
+    <xs:sequence>
+      <xs:element name="date" type="xs:date"/>
+      <xs:element name="at" type="xs:dateTime" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="month" type="xs:gYearMonth"/>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := map[string][]byte{}
+	parser := newTestParser("Go", "event.xsd", Options{
+		Sources: map[string][]byte{"event.xsd": schema},
+		Outputs: outputs,
+	})
+	assert.NoError(t, parser.Parse())
+	code := string(outputs["event.xsd.go"])
+	assert.NotContains(t, code, "\"time\"")
+	assert.Contains(t, code, "\tMonthAttr XsdGYearMonth `xml:\"month,attr,omitempty\"`\n")
+	assert.Contains(t, code, "\tDate      XsdDate       `xml:\"date\"`\n")
+	assert.Contains(t, code, "\tAt        *XsdDateTime  `xml:\"at\"`\n")
+	support := string(outputs["xsdtime.go"])
+	assert.Contains(t, support, "\npackage schema\n")
+	assert.Contains(t, support, "type XsdGYearMonth time.Time\n")
+	assert.Contains(t, support, "func (v *XsdDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {\n")
+	assert.Contains(t, support, "func (v *XsdDateTime) UnmarshalXMLAttr(attr xml.Attr) error {\n")
+	assert.Contains(t, support, "\tt, err := parseXsdTime(\"2006-01\", string(text))\n")
+
+	outputs = map[string][]byte{}
+	parser = newTestParser("Go", "note.xsd", Options{
+		Sources: map[string][]byte{"note.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="note" type="xs:string"/></xs:schema>`)},
+		Outputs: outputs,
+	})
+	assert.NoError(t, parser.Parse())
+	assert.NotContains(t, outputs, "xsdtime.go")
+}
+
+func TestGenerateReservedWords(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="match">
+    <xs:sequence>
+      <xs:element name="type" type="xs:string"/>
+      <xs:element name="name" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		escape   Escape
+		expected string
+	}{
+		{Escape{}, "type_attr"},
+		{Escape{Strategy: EscapeSuffix, Affix: "_"}, "type_"},
+		{Escape{Strategy: EscapePrefix, Affix: "x_"}, "x_type"},
+		{Escape{Strategy: EscapeBacktick}, "r#type"},
+	} {
+		opt := Options{Escape: c.escape, Sources: map[string][]byte{"match.xsd": schema}, Outputs: map[string][]byte{}}
+		parser := newTestParser("Rust", "match.xsd", opt)
+		assert.NoError(t, parser.Parse(), c.escape.Strategy)
+		assertContains(t, string(parser.Outputs["match.xsd.rs"]), []string{
+			"\t#[serde(rename = \"type\")]\n\tpub " + c.expected + ": String,\n",
+			"\tpub name: String,\n",
+		}, c.escape.Strategy)
+		assert.Equal(t, map[string]string{"type": c.expected}, parser.Escaped, c.escape.Strategy)
+	}
+}
+
+func TestGenerateTypeMapping(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="MoneyType">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:complexType name="order">
+    <xs:sequence>
+      <xs:element name="total" type="MoneyType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext, mapping string
+		expected           []string
+	}{
+		{"Go", ".go", "MoneyType=money.Amount,github.com/acme/money", []string{
+			"import (\n\t\"github.com/acme/money\"\n)\n",
+			"\tTotal money.Amount `xml:\"total\"`\n",
+		}},
+		{"Java", ".java", "MoneyType=Money,com.acme.Money", []string{
+			"import com.acme.Money;\n",
+			"\tprotected Money Total;\n",
+		}},
+	} {
+		name, mapping, err := ParseTypeMapping(c.mapping)
+		assert.NoError(t, err, c.lang)
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{TypeMapping: map[string]TypeMapping{name: mapping}})
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+		assert.NotContains(t, code, "MoneyType", c.lang)
+	}
+	_, _, err := ParseTypeMapping("MoneyType")
+	assert.EqualError(t, err, `invalid type mapping "MoneyType", expected XSDType=Type[,Import]`)
+}
+
+func TestLoadBuildInTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	decimal, date := BuildInTypes["decimal"], BuildInTypes["date"]
+	dateSet := typeScriptBuildInType["Date"]
+	defer func() {
+		BuildInTypes["decimal"], BuildInTypes["date"] = decimal, date
+		if !dateSet {
+			delete(typeScriptBuildInType, "Date")
+		}
+	}()
+	path := filepath.Join(dir, "types.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{
+    "decimal": { "Go": "float32" },
+    "date": { "TypeScript": "Date" }
+}`), 0644))
+	assert.NoError(t, LoadBuildInTypes(path))
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="order">
+    <xs:sequence>
+      <xs:element name="total" type="xs:decimal"/>
+      <xs:element name="date" type="xs:date"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{})
+	assertContains(t, string(outputs["order.xsd.go"]), []string{
+		"\tTotal float32 `xml:\"total\"`\n",
+	})
+	outputs = generateTestCode(t, "TypeScript", "order.xsd", schema, Options{})
+	assertContains(t, string(outputs["order.xsd.ts"]), []string{
+		"\tTotal: number;\n",
+		"\tDate: Date;\n",
+	})
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"decimal": {"Cobol": "PIC 9"}}`), 0644))
+	assert.EqualError(t, LoadBuildInTypes(path), "unsupport language Cobol for type decimal in "+path)
+}
+
+func TestGenerateCollision(t *testing.T) {
+	sources := map[string][]byte{
+		"order.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:acme:order">
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="street" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"geo.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:loc="http://example.com/geo/v2" targetNamespace="http://example.com/geo/v2">
+  <xs:complexType name="addressType">
+    <xs:sequence>
+      <xs:element name="lat" type="xs:double"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="placeType">
+    <xs:sequence>
+      <xs:element name="address" type="loc:addressType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	for _, c := range []struct {
+		collision, expected string
+	}{
+		{"", "AddressType"},
+		{CollisionPrefix, "LocAddressType"},
+		{CollisionURI, "GeoAddressType"},
+		{CollisionNumeric, "AddressType2"},
+	} {
+		// the type defined in the namespace parsed first keeps its name.
+		typeNamespaces := map[string]string{}
+		outputs := generateTestCode(t, "Go", "order.xsd", nil, Options{Sources: sources, Collision: c.collision, TypeNamespaces: typeNamespaces})
+		assert.Contains(t, string(outputs["order.xsd.go"]), "type AddressType struct {\n", c.collision)
+		outputs = generateTestCode(t, "Go", "geo.xsd", nil, Options{Sources: sources, Collision: c.collision, TypeNamespaces: typeNamespaces})
+		assertContains(t, string(outputs["geo.xsd.go"]), []string{
+			"type " + c.expected + " struct {\n",
+			"\tAddress *" + c.expected + " `xml:\"address\"`\n",
+		}, c.collision)
+	}
+}
+
+func TestGenerateSkipWrappers(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="note" type="xs:string"/>
+  <xs:attribute name="lang" type="xs:string"/>
+  <xs:complexType name="order">
+    <xs:sequence>
+      <xs:element ref="note"/>
+    </xs:sequence>
+    <xs:attribute ref="lang"/>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		wrappers  []string
+		expected  []string
+	}{
+		{"Go", ".go", []string{"type Note string\n", "type Lang string\n"}, []string{
+			"\tLangAttr string `xml:\"lang,attr,omitempty\"`\n\tNote     string `xml:\"note\"`\n",
+		}},
+		{"Ruby", ".rb", []string{"\tclass Note < String; end\n", "\tclass Lang < String; end\n"}, []string{
+			"\t\tattribute :lang, 'OTA::String', tag: 'lang'\n\t\telement :note, 'OTA::String', tag: 'note'\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{})
+		assertContains(t, string(outputs["order.xsd"+c.ext]), append(c.wrappers, c.expected...), c.lang)
+
+		outputs = generateTestCode(t, c.lang, "order.xsd", schema, Options{SkipWrappers: true})
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, c.expected, c.lang)
+		for _, wrapper := range c.wrappers {
+			assert.NotContains(t, code, wrapper, c.lang)
+		}
+	}
+}
+
+func TestGenerateSchemaOrder(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="order">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="line" type="lineType"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="status" type="statusType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{"type Order struct {\n", "type LineType struct {\n", "type StatusType string\n"}},
+		// the dependencies are declared before use in C.
+		{"C", ".h", []string{"typedef char StatusType;\n", "} LineType;\n", "} Order;\n"}},
+	} {
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{SchemaOrder: true})
+		code := string(outputs["order.xsd"+c.ext])
+		offset := 0
+		for _, declaration := range c.expected {
+			idx := strings.Index(code[offset:], declaration)
+			if !assert.NotEqual(t, -1, idx, "%s: %q out of order", c.lang, declaration) {
+				break
+			}
+			offset += idx + len(declaration)
+		}
+	}
+}
+
+func TestGenerateFor(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:shop">
+  <xs:element name="order" type="orderType"/>
+  <xs:element name="invoice" type="invoiceType"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="line" type="lineType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="invoiceType">
+    <xs:sequence>
+      <xs:element name="total" type="xs:decimal"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	parser := newTestParser("Go", "shop.xsd", Options{Sources: map[string][]byte{"shop.xsd": schema}, Outputs: map[string][]byte{}})
+	assert.NoError(t, parser.GenerateFor([]xml.Name{{Space: "urn:shop", Local: "order"}}, "Go"))
+	code := string(parser.Outputs["shop.xsd.go"])
+	assertContains(t, code, []string{"type Order struct {\n", "type OrderType struct {\n", "type LineType struct {\n"})
+	for _, name := range []string{"Invoice", "InvoiceType"} {
+		assert.NotContains(t, code, "type "+name+" struct {\n")
+	}
+
+	// the roots in other namespaces don't match the declarations.
+	parser = newTestParser("Go", "shop.xsd", Options{Sources: map[string][]byte{"shop.xsd": schema}, Outputs: map[string][]byte{}})
+	assert.NoError(t, parser.GenerateFor([]xml.Name{{Space: "urn:bank", Local: "invoice"}}, "Go"))
+	assert.NotContains(t, string(parser.Outputs["shop.xsd.go"]), "type ")
+	assert.EqualError(t, parser.GenerateFor(nil, "Go"), "no root names specified")
+}
+
+func TestGenerateDirectives(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xgen="https://github.com/xuri/xgen">
+  <xs:complexType name="PurchaseOrderType">
+    <xs:annotation>
+      <xs:appinfo><xgen:name>Order</xgen:name></xs:appinfo>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="total" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo><xgen:type>float64</xgen:type></xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+      <xs:element name="internal" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo><xgen:skip/></xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+      <xs:element name="money" type="MoneyType"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="MoneyType">
+    <xs:annotation>
+      <xs:appinfo><xgen:type>int64</xgen:type></xs:appinfo>
+    </xs:annotation>
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:complexType name="LegacyType">
+    <xs:annotation>
+      <xs:appinfo><xgen:skip/></xs:appinfo>
+    </xs:annotation>
+  </xs:complexType>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{})
+	code := string(outputs["order.xsd.go"])
+	assert.Contains(t, code, "type Order struct {\n\tTotal float64 `xml:\"total\"`\n\tMoney int64   `xml:\"money\"`\n}\n")
+	for _, name := range []string{"PurchaseOrderType", "Internal", "MoneyType", "LegacyType"} {
+		assert.NotContains(t, code, name)
+	}
+}
+
+func TestGenerateGoMigration(t *testing.T) {
+	schema := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+      <xs:element name="qty" type="%s"/>
+      <xs:element name="%s" type="xs:string"/>
+      <xs:element name="line" type="lineType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="%s">
+    <xs:sequence>
+      <xs:element name="note" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	prev := newTestParser("Go", "v1.xsd", Options{
+		Sources: map[string][]byte{"v1.xsd": []byte(fmt.Sprintf(schema, "xs:int", "fax", "legacyType"))},
+		Outputs: map[string][]byte{},
+	})
+	assert.NoError(t, prev.Parse())
+	next := newTestParser("Go", "v2.xsd", Options{
+		Sources: map[string][]byte{"v2.xsd": []byte(fmt.Sprintf(schema, "xs:decimal", "email", "giftType"))},
+		Outputs: map[string][]byte{},
+	})
+	assert.NoError(t, next.Parse())
+
+	gen := &CodeGenerator{Lang: "Go", File: "order", ProtoTree: next.ProtoTree, Outputs: map[string][]byte{}}
+	assert.NoError(t, gen.GenGoMigration(prev.ProtoTree, "example.com/order/v1", "example.com/order/v2"))
+	assertContains(t, string(gen.Outputs["order_migration.go"]), []string{
+		"package migration\n\nimport (\n\tprev \"example.com/order/v1\"\n\tnext \"example.com/order/v2\"\n)\n",
+		"// TODO: LegacyType is removed in the new schema version.\n",
+		"// TODO: GiftType is added in the new schema version.\n",
+		"func ConvertOrderType(in *prev.OrderType) *next.OrderType {\n",
+		"\tout.Id = in.Id\n",
+		"\t// TODO: field Qty is retyped from int to float64.\n",
+		"\t// TODO: field Email string is added.\n",
+		"\tfor _, v := range in.Line {\n\t\tout.Line = append(out.Line, ConvertLineType(v))\n\t}\n",
+		"\t// TODO: field Fax string is removed.\n",
+		"func ConvertLineType(in *prev.LineType) *next.LineType {\n",
+		"\tout.Sku = in.Sku\n",
+	})
+}
+
+func TestGenerateNSPackagesRoundTrip(t *testing.T) {
+	sources := map[string][]byte{
+		"order.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/order" xmlns:c="http://example.com/common">
+  <xs:import namespace="http://example.com/common" schemaLocation="common.xsd"/>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="line" type="c:lineType" maxOccurs="unbounded"/>
+      <xs:element name="status" type="c:statusType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+		"common.xsd": []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://example.com/common">
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`),
+	}
+	outputs := generateTestCode(t, "Go", "order.xsd", nil, Options{
+		PackagePerNamespace: true,
+		ModulePath:          "example.com/gen",
+		Sources:             sources,
+	})
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"example.com/gen/common"
+	"example.com/gen/order"
+)
+
+func main() {
+	var v order.OrderType
+	if err := xml.Unmarshal([]byte("<order><line><sku>a</sku></line><line><sku>b</sku></line><status>open</status></order>"), &v); err != nil {
+		panic(err)
+	}
+	var line *common.LineType = v.Line[1]
+	fmt.Println(len(v.Line), line.Sku, v.Status)
+}
+`)
+	assert.Equal(t, "2 b open\n", output)
+}
+
+func TestGenerateEmbedSource(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="open"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="status" type="statusType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	fragment := "//\t<xs:complexType name=\"orderType\">\n//\t  <xs:sequence>\n//\t    <xs:element name=\"status\" type=\"statusType\"/>\n//\t  </xs:sequence>\n//\t</xs:complexType>\n"
+	// the comments end with CRLF in the languages other than Go.
+	for _, c := range []struct {
+		lang, ext, eol, declaration string
+	}{
+		{"Go", ".go", "\n", "type OrderType struct {\n"},
+		{"Java", ".java", "\r\n", "public class OrderType {\n"},
+		{"TypeScript", ".ts", "\r\n", "export class OrderType {\n"},
+		{"Rust", ".rs", "\r\n", "#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct OrderType {\n"},
+	} {
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{EmbedSource: true})
+		code := string(outputs["order.xsd"+c.ext])
+		assertContains(t, code, []string{
+			strings.Replace("// OrderType ...\n//\n"+fragment, "\n", c.eol, -1) + c.declaration,
+			"//\t<xs:simpleType name=\"statusType\">" + c.eol,
+		}, c.lang)
+		outputs = generateTestCode(t, c.lang, "order.xsd", schema, Options{})
+		assert.NotContains(t, string(outputs["order.xsd"+c.ext]), "<xs:", c.lang)
+	}
+}
+
+func TestGenerateDeprecated(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xgen="https://github.com/xuri/xgen">
+  <xs:complexType name="orderType">
+    <xs:annotation>
+      <xs:documentation>The order.
+Deprecated: use purchaseType instead.</xs:documentation>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="codeType">
+    <xs:annotation>
+      <xs:documentation>OBSOLETE since 2.0</xs:documentation>
+    </xs:annotation>
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:complexType name="faxType">
+    <xs:annotation>
+      <xs:appinfo><xgen:deprecated/></xs:appinfo>
+    </xs:annotation>
+  </xs:complexType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"// OrderType is The order.\n//\n// Deprecated: use purchaseType instead.\ntype OrderType struct {\n",
+			"// CodeType ...\n//\n// Deprecated: since 2.0\ntype CodeType string\n",
+			"// FaxType ...\n//\n// Deprecated: this declaration is deprecated in the schema.\ntype FaxType struct {\n",
+		}},
+		{"Java", ".java", []string{
+			"// OrderType is The order.\r\n@Deprecated\r\npublic class OrderType {\n",
+			"// FaxType ...\r\n@Deprecated\r\npublic class FaxType {\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"/** @deprecated use purchaseType instead. */\r\nexport class OrderType {\n",
+			"/** @deprecated since 2.0 */\r\nexport type CodeType = string;\n",
+		}},
+		{"Rust", ".rs", []string{
+			"#[deprecated(note = \"use purchaseType instead.\")]\r\n#[derive(Debug, Deserialize, Serialize, PartialEq)]\npub struct OrderType {\n",
+		}},
+	} {
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{DeprecationMarker: "obsolete"})
+		assertContains(t, string(outputs["order.xsd"+c.ext]), c.expected, c.lang)
+	}
+	// the custom marker isn't recognized unless it's specified.
+	outputs := generateTestCode(t, "Go", "order.xsd", schema, Options{})
+	assert.Contains(t, string(outputs["order.xsd.go"]), "// CodeType is OBSOLETE since 2.0\ntype CodeType string\n")
+}
+
+func TestResolveTargets(t *testing.T) {
+	protoTree := []interface{}{
+		&SimpleType{Name: "idsType", Union: true, MemberTypes: []MemberType{{Name: "codeType"}, {Name: "int", Type: "int"}}},
+		&SimpleType{Name: "codeType", Base: "qtyType"},
+		&SimpleType{Name: "qtyType", Base: "int"},
+		&SimpleType{Name: "listType", Base: "int", List: true},
+		&Element{Name: "note", Type: "bool"},
+		&Attribute{Name: "note", Type: "string"},
+	}
+	targets := resolveTargets(protoTree)
+	// the declaration found first wins, and the list types aren't linked.
+	assert.Equal(t, map[string]string{"codeType": "qtyType", "qtyType": "int", "note": "bool"}, targets)
+	assert.Equal(t, "int", resolveTarget("qtyType", targets))
+	assert.Equal(t, "listType", resolveTarget("listType", targets))
+	resolveMemberTypes(protoTree, targets)
+	assert.Equal(t, []MemberType{{Name: "codeType", Type: "qtyType"}, {Name: "int", Type: "int"}}, protoTree[0].(*SimpleType).MemberTypes)
+
+	// the base, ref and type links are resolved the same way in all the
+	// languages, regardless of the declaration order.
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="qty" type="qtyType"/>
+      <xs:element ref="note"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="note" type="xs:boolean"/>
+  <xs:simpleType name="qtyType">
+    <xs:restriction base="xs:int"/>
+  </xs:simpleType>
+</xs:schema>`)
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{"\tQty  int  `xml:\"qty\"`\n\tNote bool `xml:\"note\"`\n"}},
+		{"Java", ".java", []string{"\tprotected Integer Qty;\n", "\tprotected Boolean Note;\n"}},
+		{"TypeScript", ".ts", []string{"\tQty: number;\n\tNote: boolean;\n"}},
+		{"Rust", ".rs", []string{"\tpub qty: i32,\n", "\tpub note: bool,\n"}},
+		{"C", ".h", []string{"\tint Qty;\n\tbool Note;\n"}},
+		{"Ruby", ".rb", []string{"\t\telement :qty, 'OTA::Integer', tag: 'qty'\n\t\telement :note, 'OTA::Boolean', tag: 'note'\n"}},
+	} {
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{})
+		assertContains(t, string(outputs["order.xsd"+c.ext]), c.expected, c.lang)
+	}
+}
+
+func TestGenerateSourceLocation(t *testing.T) {
+	sources := map[string][]byte{
+		"xsd/order.xsd": []byte(`<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <!-- the status of the order -->
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="status" type="statusType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`),
+	}
+	for _, c := range []struct {
+		lang, ext, eol string
+	}{
+		{"Go", ".go", "\n"},
+		{"Java", ".java", "\r\n"},
+		{"TypeScript", ".ts", "\r\n"},
+		{"Rust", ".rs", "\r\n"},
+		{"C", ".h", "\r\n"},
+	} {
+		outputs := generateTestCode(t, c.lang, "xsd/order.xsd", nil, Options{InputDir: "xsd/", Sources: sources, SourceLocation: true})
+		assertContains(t, string(outputs["order.xsd"+c.ext]), []string{
+			"// StatusType ..." + c.eol + "//" + c.eol + "// source: order.xsd:4" + c.eol,
+			"// OrderType ..." + c.eol + "//" + c.eol + "// source: order.xsd:8" + c.eol,
+		}, c.lang)
+	}
+}
+
+func TestGenerateKeepGoing(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="orderType">
+    <xs:sequence>
+      <xs:element name="code" type="codeType"/>
+      <xs:element name="qty" type="xs:int" maxOccurs="many"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="codeType">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="ten"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="lineType">
+    <xs:sequence>
+      <xs:element name="sku" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`)
+	parser := newTestParser("Go", "order.xsd", Options{Sources: map[string][]byte{"order.xsd": schema}, Outputs: map[string][]byte{}})
+	assert.EqualError(t, parser.Parse(), `strconv.Atoi: parsing "many": invalid syntax`)
+
+	for _, c := range []struct {
+		lang, ext string
+		expected  []string
+	}{
+		{"Go", ".go", []string{
+			"// OrderType is a placeholder, xgen failed to generate it: strconv.Atoi: parsing \"many\": invalid syntax\ntype OrderType interface{}\n",
+			"// CodeType is a placeholder, xgen failed to generate it: strconv.Atoi: parsing \"ten\": invalid syntax\ntype CodeType interface{}\n",
+			"type LineType struct {\n\tSku string `xml:\"sku\"`\n}\n",
+		}},
+		{"TypeScript", ".ts", []string{
+			"export type OrderType = any;\n",
+			"export type CodeType = any;\n",
+			"export class LineType {\n\tSku: string;\n}\n",
+		}},
+	} {
+		failures := &FailureLog{}
+		outputs := generateTestCode(t, c.lang, "order.xsd", schema, Options{KeepGoing: true, Failures: failures})
+		assertContains(t, string(outputs["order.xsd"+c.ext]), c.expected, c.lang)
+		assert.Equal(t, "2 failure(s), placeholders are generated for the failed declarations:\n"+
+			"  order.xsd: orderType: strconv.Atoi: parsing \"many\": invalid syntax\n"+
+			"  order.xsd: codeType: strconv.Atoi: parsing \"ten\": invalid syntax\n", failures.Summary(), c.lang)
+	}
+	assert.Empty(t, (&FailureLog{}).Summary())
+}
+
+func TestGenerateJSONSchemaError(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ratioType">
+    <xs:restriction base="xs:double">
+      <xs:maxInclusive value="INF"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="ratio" type="xs:double"/>
+</xs:schema>`)
+	for _, lang := range []string{"JSONSchema", "OpenAPI"} {
+		parser := newTestParser(lang, "ratio.xsd", Options{Sources: map[string][]byte{"ratio.xsd": schema}, Outputs: map[string][]byte{}})
+		assert.EqualError(t, parser.Parse(), "json: error calling MarshalJSON for type *xgen.jsonSchema: json: unsupported value: +Inf", lang)
+
+		failures := &FailureLog{}
+		outputs := generateTestCode(t, lang, "ratio.xsd", schema, Options{KeepGoing: true, Failures: failures})
+		assert.Contains(t, string(outputs["ratio.xsd.schema.json"])+string(outputs["ratio.xsd.openapi.yaml"]), "ratioType is a placeholder, xgen failed to generate it: json: error calling MarshalJSON for type *xgen.jsonSchema: json: unsupported value: +Inf", lang)
+		assert.Equal(t, "1 failure(s), placeholders are generated for the failed declarations:\n"+
+			"  ratio.xsd: ratioType: json: error calling MarshalJSON for type *xgen.jsonSchema: json: unsupported value: +Inf\n", failures.Summary(), lang)
+	}
+}
+
+func TestGenerateSubstitutionGroupRoundTrip(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="vehicleType">
+    <xs:sequence>
+      <xs:element name="wheels" type="xs:int"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="vehicle" type="vehicleType"/>
+  <xs:element name="car" type="vehicleType" substitutionGroup="vehicle"/>
+  <xs:element name="bike" type="xs:string" substitutionGroup="vehicle"/>
+  <xs:complexType name="garageType">
+    <xs:sequence>
+      <xs:element ref="vehicle" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="garage" type="garageType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "vehicle.xsd", schema, Options{Package: "main"})
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	var garage Garage
+	if err := xml.Unmarshal([]byte("<garage><car><wheels>4</wheels></car><bike>bmx</bike></garage>"), &garage); err != nil {
+		panic(err)
+	}
+	for _, vehicle := range garage.Vehicle {
+		switch v := vehicle.Value.(type) {
+		case *Car:
+			fmt.Println("car", v.Wheels)
+		case *Bike:
+			fmt.Println("bike", *v)
+		}
+	}
+	data, err := xml.Marshal(garage)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+}
+`)
+	assert.Equal(t, "car 4\nbike bmx\n<garage><car><wheels>4</wheels></car><bike>bmx</bike></garage>\n", output)
+}
+
+func TestGenerateSQLColumnCollision(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="baseType">
+    <xs:attribute name="id" type="xs:int"/>
+  </xs:complexType>
+  <xs:complexType name="itemType">
+    <xs:complexContent>
+      <xs:extension base="baseType">
+        <xs:sequence>
+          <xs:element name="name" type="xs:string"/>
+          <xs:element name="tag" type="xs:string" maxOccurs="unbounded"/>
+          <xs:element name="id" type="xs:string"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>`)
+	// the colliding columns are numbered in each table regardless of the
+	// other columns and tables.
+	code := string(generateTestCode(t, "SQL", "item.xsd", schema, Options{})["item.xsd.sql"])
+	assertContains(t, code, []string{
+		"CREATE TABLE base_type (\n  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,\n  id_1 INTEGER\n);\n",
+		"CREATE TABLE item_type (\n  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,\n  id_1 INTEGER,\n  name TEXT NOT NULL,\n  tag TEXT[] NOT NULL,\n  id_2 TEXT NOT NULL\n);\n",
+	}, "SQL")
+}
+
+func TestGenerateXMLSample(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="shapeType">
+    <xs:sequence>
+      <xs:element name="color" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:int" use="required"/>
+  </xs:complexType>
+  <xs:complexType name="circleType">
+    <xs:complexContent>
+      <xs:extension base="shapeType">
+        <xs:sequence>
+          <xs:element name="radius" type="xs:int"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="shape" type="shapeType" abstract="true"/>
+  <xs:element name="circle" type="circleType" substitutionGroup="shape"/>
+</xs:schema>`)
+	parser := newTestParser("XML", "shape.xsd", Options{
+		Sources: map[string][]byte{"shape.xsd": schema},
+		Outputs: map[string][]byte{},
+	})
+	assert.NoError(t, parser.Parse())
+	assert.True(t, parser.ProtoTree[2].(*Element).Abstract)
+	// the abstract elements have no documents, and the members of the base
+	// types precede the members of the derived types.
+	_, ok := parser.Outputs["shape.xsd.shape.xml"]
+	assert.False(t, ok)
+	assert.Contains(t, string(parser.Outputs["shape.xsd.circle.xml"]), "<circle id=\"1\">\n  <color>sample</color>\n  <radius>1</radius>\n</circle>\n")
+}
+
+func TestGenerateEnumField(t *testing.T) {
+	schema := []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="statusType">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="open"/>
+      <xs:enumeration value="done"/>
+      <xs:maxLength value="4"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="taskType">
+    <xs:sequence>
+      <xs:element name="status" type="statusType" default="open"/>
+      <xs:element name="history" type="statusType" maxOccurs="unbounded"/>
+      <xs:element name="next" type="statusType" minOccurs="0"/>
+      <xs:element name="previous" type="statusType" nillable="true"/>
+    </xs:sequence>
+    <xs:attribute name="state" type="statusType"/>
+  </xs:complexType>
+  <xs:element name="task" type="taskType"/>
+</xs:schema>`)
+	outputs := generateTestCode(t, "Go", "task.xsd", schema, Options{Package: "main", OptionalPointers: true, StructTags: []string{"json"}, DeepCopy: true, Stringer: true, DecodeEach: true})
+	assertContains(t, string(outputs["task.xsd.go"]), []string{
+		"\tStateAttr *StatusType              `xml:\"state,attr,omitempty\" json:\"state,omitempty\"`\n\tStatus    StatusType               `xml:\"status\" json:\"status\"`\n\tHistory   []StatusType             `xml:\"history\" json:\"history,omitempty\"`\n\tNext      *StatusType              `xml:\"next,omitempty\" json:\"next,omitempty\"`\n",
+		"\tValue *StatusType\n",
+	}, "Go")
+	// the enumeration fields are assigned the typed constants and checked
+	// against the facets of the enumeration.
+	output := runGoCode(t, outputs, `package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+func main() {
+	task := NewTaskType()
+	fmt.Println(task.Status == StatusTypeOpen)
+	next := StatusTypeDone
+	task.Next, task.History = &next, []StatusType{StatusTypeOpen, "closed"}
+	fmt.Println(task.Validate())
+	var decoded Task
+	if err := xml.Unmarshal([]byte("<task state=\"done\"><status>done</status></task>"), &decoded); err != nil {
+		panic(err)
+	}
+	fmt.Println(*decoded.StateAttr == StatusTypeDone, decoded.DeepCopy().Status)
+}
+`)
+	assert.Equal(t, "true\nTaskType.History: length 6 is greater than 4\ntrue done\n", output)
+}
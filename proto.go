@@ -15,15 +15,34 @@ import "regexp"
 // https://www.w3.org/TR/xmlschema-1/#Simple_Type_Definitions
 type SimpleType struct {
 	Doc         string
+	Source      string
+	Location    string
+	Deprecated  string
+	Appinfo     []string
 	Name        string
+	Namespace   string
+	Prefix      string
 	Base        string
 	Anonymous   bool
 	List        bool
 	Union       bool
-	MemberTypes map[string]string
+	MemberTypes []MemberType
 	Restriction Restriction
 }
 
+// MemberType is a member type of the union simple type, the Name is the name
+// of the member type without the namespace prefix, and the Type is the type
+// it's resolved to. The member types are kept in the order of the memberTypes
+// attribute.
+type MemberType struct {
+	Name string
+	Type string
+}
+
+// Unbounded is the MaxOccurs of the elements which may occur any number of
+// times.
+const Unbounded = -1
+
 // Element declarations provide for: Local validation of element information
 // item values using a type definition; Specifying default or fixed values for
 // an element information items; Establishing uniquenesses and reference
@@ -32,15 +51,37 @@ type SimpleType struct {
 // mechanism of element substitution groups.
 // https://www.w3.org/TR/xmlschema-1/#cElement_Declarations
 type Element struct {
-	Doc      string
-	Name     string
-	Wildcard bool
-	Type     string
-	Abstract bool
-	Plural   bool
-	Optional bool
-	Nillable bool
-	Default  string
+	Doc               string
+	Source            string
+	Location          string
+	Deprecated        string
+	Appinfo           []string
+	Name              string
+	Namespace         string
+	Prefix            string
+	Wildcard          bool
+	Type              string
+	Abstract          bool
+	Plural            bool
+	Optional          bool
+	MinOccurs         int
+	MaxOccurs         int
+	Nillable          bool
+	Default           string
+	Fixed             string
+	Choice            string
+	SubstitutionGroup string
+	Alternatives      []Alternative
+	Restriction       Restriction
+}
+
+// Alternative is a type alternative of the element in XSD 1.1, the Type is
+// the type of the element if the XPath 2.0 expression in the Test is true,
+// the Test is empty for the default alternative.
+// https://www.w3.org/TR/xmlschema11-1/#cTypeAlternative
+type Alternative struct {
+	Test string
+	Type string
 }
 
 // Attribute declarations provide for: Local validation of attribute
@@ -48,12 +89,20 @@ type Element struct {
 // or fixed values for attribute information items.
 // https://www.w3.org/TR/xmlschema-1/structures.html#element-attribute
 type Attribute struct {
-	Name     string
-	Doc      string
-	Type     string
-	Plural   bool
-	Default  string
-	Optional bool
+	Name        string
+	Namespace   string
+	Prefix      string
+	Doc         string
+	Source      string
+	Location    string
+	Deprecated  string
+	Appinfo     []string
+	Type        string
+	Plural      bool
+	Default     string
+	Fixed       string
+	Optional    bool
+	Restriction Restriction
 }
 
 // ComplexType definitions are identified by their {name} and {target
@@ -69,14 +118,25 @@ type Attribute struct {
 // https://www.w3.org/TR/xmlschema-1/structures.html#element-complexType
 type ComplexType struct {
 	Doc            string
+	Source         string
+	Location       string
+	Deprecated     string
+	Appinfo        []string
 	Name           string
+	Namespace      string
+	Prefix         string
 	Base           string
 	Anonymous      bool
+	Abstract       bool
 	Elements       []Element
 	Attributes     []Attribute
 	Groups         []Group
+	Choices        []Choice
 	AttributeGroup []AttributeGroup
 	Mixed          bool
+	Unordered      bool
+	OpenContent    string
+	Assertions     []string
 }
 
 // Group (model group) definitions are provided primarily for reference from
@@ -85,12 +145,30 @@ type ComplexType struct {
 // facility.
 // https://www.w3.org/TR/xmlschema-1/structures.html#cModel_Group_Definitions
 type Group struct {
-	Doc      string
+	Doc        string
+	Source     string
+	Location   string
+	Deprecated string
+	Appinfo    []string
+	Name       string
+	Elements   []Element
+	Groups     []Group
+	Choices    []Choice
+	Plural     bool
+	Unordered  bool
+	Ref        string
+}
+
+// Choice allows one and only one of the elements contained in the selected
+// group to be present within the containing element. The alternatives of the
+// choice are the elements of the complex type or group referring it by the
+// name, the sequences and choices nested in the choice are flattened into the
+// alternatives.
+// https://www.w3.org/TR/xmlschema-1/structures.html#element-choice
+type Choice struct {
 	Name     string
-	Elements []Element
-	Groups   []Group
+	Optional bool
 	Plural   bool
-	Ref      string
 }
 
 // AttributeGroup definitions do not participate in ·validation· as such, but
@@ -104,19 +182,31 @@ type Group struct {
 // https://www.w3.org/TR/xmlschema-1/structures.html#Attribute_Group_Definition
 type AttributeGroup struct {
 	Doc        string
+	Source     string
+	Location   string
+	Deprecated string
+	Appinfo    []string
 	Name       string
 	Ref        string
 	Attributes []Attribute
 }
 
 // Restriction are used to define acceptable values for XML elements or
-// attributes. Restriction on XML elements are called facets.
+// attributes. Restriction on XML elements are called facets. The Min and Max
+// are the numeric bounds if HasMin and HasMax are true, which are exclusive
+// if MinExclusive and MaxExclusive are true, and the Precision is the
+// fractionDigits facet. The Base is the name of the schema type restricted,
+// or the item type for the lists, without the namespace prefix.
 // https://www.w3.org/TR/xmlschema-1/structures.html#element-restriction
 type Restriction struct {
-	Doc                  string
-	Precision            int
-	Enum                 []string
-	Min, Max             float64
-	MinLength, MaxLength int
-	Pattern              *regexp.Regexp
+	Doc                        string
+	Base                       string
+	Precision                  int
+	TotalDigits                int
+	Enum                       []string
+	Min, Max                   float64
+	HasMin, HasMax             bool
+	MinExclusive, MaxExclusive bool
+	MinLength, MaxLength       int
+	Pattern                    *regexp.Regexp
 }
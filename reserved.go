@@ -0,0 +1,333 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Escaping strategies for the derived identifiers which collide with the
+// reserved words of the target language. The backtick strategy uses the
+// native escaping syntax of the language (e.g. raw identifiers in Rust), and
+// fallback to the suffix strategy if the language doesn't have one.
+const (
+	EscapeSuffix   = "suffix"
+	EscapePrefix   = "prefix"
+	EscapeBacktick = "backtick"
+)
+
+// Escape holds the strategy and the affix used for escaping reserved words,
+// the default strategy is suffix and the default affix is "_".
+type Escape struct {
+	Strategy string
+	Affix    string
+}
+
+// IsValidEscapeStrategy reports whether the given value is a supported
+// escaping strategy, an empty value is valid and means the suffix strategy.
+func IsValidEscapeStrategy(strategy string) bool {
+	switch strategy {
+	case "", EscapeSuffix, EscapePrefix, EscapeBacktick:
+		return true
+	}
+	return false
+}
+
+// ReservedWords defines the keywords and reserved words of the supported
+// languages which can't be used as the identifier in generated code.
+var ReservedWords = map[string]map[string]bool{
+	"Go": toSet("break", "case", "chan", "const", "continue", "default", "defer",
+		"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var"),
+	"TypeScript": toSet("any", "as", "boolean", "break", "case", "catch", "class",
+		"const", "constructor", "continue", "debugger", "declare", "default",
+		"delete", "do", "else", "enum", "export", "extends", "false", "finally",
+		"for", "from", "function", "get", "if", "implements", "import", "in",
+		"instanceof", "interface", "let", "module", "new", "null", "number",
+		"of", "package", "private", "protected", "public", "require", "return",
+		"set", "static", "string", "super", "switch", "symbol", "this", "throw",
+		"true", "try", "type", "typeof", "var", "void", "while", "with",
+		"yield"),
+	"C": toSet("auto", "bool", "break", "case", "char", "const", "continue",
+		"default", "do", "double", "else", "enum", "extern", "float", "for",
+		"goto", "if", "inline", "int", "long", "register", "restrict", "return",
+		"short", "signed", "sizeof", "static", "struct", "switch", "typedef",
+		"union", "unsigned", "void", "volatile", "while"),
+	"Java": toSet("abstract", "assert", "boolean", "break", "byte", "case",
+		"catch", "char", "class", "const", "continue", "default", "do",
+		"double", "else", "enum", "extends", "false", "final", "finally",
+		"float", "for", "goto", "if", "implements", "import", "instanceof",
+		"int", "interface", "long", "native", "new", "null", "package",
+		"private", "protected", "public", "return", "short", "static",
+		"strictfp", "super", "switch", "synchronized", "this", "throw",
+		"throws", "transient", "true", "try", "var", "void", "volatile",
+		"while"),
+	"Rust": toSet("as", "break", "const", "continue", "crate", "dyn", "else",
+		"enum", "extern", "false", "fn", "for", "if", "impl", "in", "let",
+		"loop", "match", "mod", "move", "mut", "pub", "ref", "return", "Self",
+		"self", "static", "struct", "super", "trait", "true", "type", "unsafe",
+		"use", "where", "while", "abstract", "async", "await", "become", "box",
+		"do", "final", "macro", "override", "priv", "try", "typeof", "unsized",
+		"virtual", "yield"),
+	"Ruby": toSet("BEGIN", "END", "alias", "and", "begin", "break", "case",
+		"class", "def", "defined?", "do", "else", "elsif", "end", "ensure",
+		"false", "for", "if", "in", "module", "next", "nil", "not", "or",
+		"redo", "rescue", "retry", "return", "self", "super", "then", "true",
+		"undef", "unless", "until", "when", "while", "yield"),
+	"Python": toSet("False", "None", "True", "and", "as", "assert", "async",
+		"await", "break", "class", "continue", "def", "del", "elif", "else",
+		"except", "finally", "for", "from", "global", "if", "import", "in",
+		"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return",
+		"try", "while", "with", "yield"),
+	"CSharp": toSet("abstract", "as", "base", "bool", "break", "byte", "case",
+		"catch", "char", "checked", "class", "const", "continue", "decimal",
+		"default", "delegate", "do", "double", "else", "enum", "event", "explicit",
+		"extern", "false", "finally", "fixed", "float", "for", "foreach", "goto",
+		"if", "implicit", "in", "int", "interface", "internal", "is", "lock",
+		"long", "namespace", "new", "null", "object", "operator", "out", "override",
+		"params", "private", "protected", "public", "readonly", "ref", "return",
+		"sbyte", "sealed", "short", "sizeof", "stackalloc", "static", "string",
+		"struct", "switch", "this", "throw", "true", "try", "typeof", "uint",
+		"ulong", "unchecked", "unsafe", "ushort", "using", "virtual", "void",
+		"volatile", "while"),
+	"Kotlin": toSet("as", "break", "class", "continue", "do", "else", "false",
+		"for", "fun", "if", "in", "interface", "is", "null", "object", "package",
+		"return", "super", "this", "throw", "true", "try", "typealias", "typeof",
+		"val", "var", "when", "while"),
+	"Swift": toSet("Any", "Self", "as", "associatedtype", "await", "break",
+		"case", "catch", "class", "continue", "default", "defer", "deinit", "do",
+		"else", "enum", "extension", "fallthrough", "false", "fileprivate", "for",
+		"func", "guard", "if", "import", "in", "init", "inout", "internal", "is",
+		"let", "nil", "open", "operator", "precedencegroup", "private", "protocol",
+		"public", "repeat", "rethrows", "return", "self", "static", "struct",
+		"subscript", "super", "switch", "throw", "throws", "true", "try",
+		"typealias", "var", "where", "while"),
+	// The keywords of PHP are case-insensitive, and only the class names which
+	// are in Pascal case could collide with them.
+	"PHP": toSet("Abstract", "And", "Array", "As", "Bool", "Break", "Callable",
+		"Case", "Catch", "Class", "Clone", "Const", "Continue", "Declare",
+		"Default", "Do", "Echo", "Else", "Elseif", "Empty", "Enddeclare", "Endfor",
+		"Endforeach", "Endif", "Endswitch", "Endwhile", "Eval", "Exit", "Extends",
+		"False", "Final", "Finally", "Float", "Fn", "For", "Foreach", "Function",
+		"Global", "Goto", "If", "Implements", "Include", "Instanceof", "Insteadof",
+		"Int", "Interface", "Isset", "Iterable", "List", "Match", "Mixed",
+		"Namespace", "Never", "New", "Null", "Object", "Or", "Parent", "Print",
+		"Private", "Protected", "Public", "Readonly", "Require", "Return", "Self",
+		"Static", "String", "Switch", "Throw", "Trait", "True", "Try", "Unset",
+		"Use", "Var", "Void", "While", "Xor", "Yield"),
+	"Dart": toSet("Function", "abstract", "as", "assert", "await", "break",
+		"case", "catch", "class", "const", "continue", "covariant", "default",
+		"deferred", "do", "dynamic", "else", "enum", "export", "extends",
+		"extension", "external", "factory", "false", "final", "finally", "for",
+		"get", "if", "implements", "import", "in", "interface", "is", "late",
+		"library", "mixin", "new", "null", "operator", "part", "required",
+		"rethrow", "return", "set", "static", "super", "switch", "this", "throw",
+		"true", "try", "typedef", "var", "void", "while", "with", "yield"),
+	"Elixir": toSet("after", "and", "catch", "do", "else", "end", "false", "fn",
+		"in", "nil", "not", "or", "rescue", "true", "when"),
+	"Haskell": toSet("case", "class", "data", "default", "deriving", "do",
+		"else", "foreign", "if", "import", "in", "infix", "infixl", "infixr",
+		"instance", "let", "module", "newtype", "of", "then", "type", "where"),
+	"OCaml": toSet("and", "array", "as", "asr", "assert", "begin", "bool",
+		"bytes", "char", "class", "constraint", "do", "done", "downto", "else",
+		"end", "exception", "exn", "external", "false", "float", "for", "fun",
+		"function", "functor", "if", "in", "include", "inherit", "initializer",
+		"int", "int64", "land", "lazy", "let", "list", "lor", "lsl", "lsr", "lxor",
+		"match", "method", "mod", "module", "mutable", "new", "nonrec", "object",
+		"of", "open", "option", "or", "private", "rec", "sig", "string", "struct",
+		"then", "to", "true", "try", "type", "unit", "val", "virtual", "when",
+		"while", "with"),
+	"Zig": toSet("addrspace", "align", "allowzero", "and", "anyerror",
+		"anyframe", "anyopaque", "anytype", "asm", "async", "await", "bool",
+		"break", "callconv", "catch", "comptime", "const", "continue", "defer",
+		"else", "enum", "errdefer", "error", "export", "extern", "f128", "f16",
+		"f32", "f64", "f80", "false", "fn", "for", "free", "i128", "i16", "i32",
+		"i64", "i8", "if", "inline", "isize", "linksection", "noalias", "noinline",
+		"noreturn", "nosuspend", "null", "opaque", "or", "orelse", "packed",
+		"parse", "pub", "resume", "return", "struct", "suspend", "switch", "test",
+		"threadlocal", "true", "try", "type", "u128", "u16", "u32", "u64", "u8",
+		"undefined", "union", "unreachable", "usingnamespace", "usize", "var",
+		"void", "volatile", "while"),
+	"FSharp": toSet("abstract", "and", "as", "assert", "atomic", "base", "begin",
+		"break", "checked", "class", "component", "const", "constraint", "continue",
+		"default", "delegate", "do", "done", "downcast", "downto", "elif", "else",
+		"end", "event", "exception", "extern", "external", "false", "finally",
+		"fixed", "for", "fun", "function", "global", "if", "in", "include",
+		"inherit", "inline", "interface", "internal", "lazy", "let", "match",
+		"member", "mixin", "module", "mutable", "namespace", "new", "not", "null",
+		"of", "open", "or", "override", "parallel", "private", "process",
+		"protected", "public", "pure", "rec", "return", "sealed", "select", "sig",
+		"static", "struct", "tailcall", "then", "to", "trait", "true", "try",
+		"type", "upcast", "use", "val", "virtual", "void", "when", "while", "with",
+		"yield"),
+	"ObjectiveC": toSet("BOOL", "Class", "IMP", "NO", "Nil", "SEL", "YES",
+		"alloc", "auto", "autorelease", "break", "bycopy", "byref", "case", "char",
+		"class", "const", "continue", "copy", "dealloc", "default", "description",
+		"do", "double", "else", "enum", "extern", "float", "for", "goto", "hash",
+		"id", "if", "in", "init", "inline", "inout", "int", "long", "new", "nil",
+		"oneway", "out", "register", "release", "restrict", "retain", "return",
+		"self", "short", "signed", "sizeof", "static", "struct", "super",
+		"superclass", "switch", "typedef", "union", "unsigned", "void", "volatile",
+		"while", "zone"),
+	"Groovy": toSet("abstract", "as", "assert", "boolean", "break", "byte",
+		"case", "catch", "char", "class", "const", "continue", "def", "default",
+		"do", "double", "else", "enum", "extends", "false", "final", "finally",
+		"float", "for", "goto", "if", "implements", "import", "in", "instanceof",
+		"int", "interface", "long", "metaClass", "native", "new", "null", "package",
+		"private", "properties", "protected", "public", "return", "short", "static",
+		"strictfp", "super", "switch", "synchronized", "this", "threadsafe",
+		"throw", "throws", "trait", "transient", "true", "try", "var", "void",
+		"volatile", "while"),
+	"Lua": toSet("and", "break", "do", "else", "elseif", "end", "false", "for",
+		"function", "goto", "if", "in", "local", "nil", "not", "or", "repeat",
+		"return", "then", "true", "until", "while"),
+	"Perl": toSet("AUTOLOAD", "BUILD", "BUILDARGS", "DEMOLISH", "DESTROY",
+		"VERSION", "can", "does", "from_node", "import", "isa", "meta", "new",
+		"parse"),
+	"Crystal": toSet("abstract", "alias", "annotation", "as", "asm", "begin",
+		"break", "case", "class", "def", "do", "else", "elsif", "end", "ensure",
+		"enum", "extend", "false", "for", "fun", "if", "in", "include",
+		"instance_sizeof", "is_a?", "lib", "macro", "module", "next", "nil", "nil?",
+		"of", "offsetof", "out", "pointerof", "private", "protected", "require",
+		"rescue", "responds_to?", "return", "select", "self", "sizeof", "struct",
+		"super", "then", "true", "type", "typeof", "uninitialized", "union",
+		"unless", "until", "verbatim", "when", "while", "with", "yield"),
+	"Nim": toSet("addr", "and", "as", "asm", "bind", "block", "break", "case",
+		"cast", "concept", "const", "continue", "converter", "defer", "discard",
+		"distinct", "div", "do", "elif", "else", "end", "enum", "except", "export",
+		"finally", "for", "from", "func", "if", "import", "in", "include",
+		"interface", "is", "isnot", "iterator", "let", "macro", "method", "mixin",
+		"mod", "nil", "not", "notin", "object", "of", "or", "out", "proc", "ptr",
+		"raise", "ref", "return", "shl", "shr", "static", "template", "try",
+		"tuple", "type", "using", "var", "when", "while", "xor", "yield"),
+	"Julia": toSet("abstract", "baremodule", "begin", "break", "catch", "const",
+		"continue", "do", "else", "elseif", "end", "export", "false", "finally",
+		"for", "function", "global", "if", "import", "in", "isa", "let", "local",
+		"macro", "module", "mutable", "primitive", "quote", "return", "struct",
+		"true", "try", "type", "using", "where", "while"),
+	"VisualBasic": toSet("addhandler", "addressof", "alias", "and", "andalso",
+		"as", "boolean", "byref", "byte", "byval", "call", "case", "catch", "cbool",
+		"cbyte", "cchar", "cdate", "cdbl", "cdec", "char", "cint", "class", "clng",
+		"cobj", "const", "continue", "csbyte", "cshort", "csng", "cstr", "ctype",
+		"cuint", "culng", "cushort", "date", "decimal", "declare", "default",
+		"delegate", "dim", "directcast", "do", "double", "each", "else", "elseif",
+		"end", "endif", "enum", "erase", "error", "event", "exit", "false",
+		"finally", "for", "friend", "function", "get", "gettype", "getxmlnamespace",
+		"global", "gosub", "goto", "handles", "if", "implements", "imports", "in",
+		"inherits", "integer", "interface", "is", "isnot", "let", "lib", "like",
+		"long", "loop", "me", "mod", "module", "mustinherit", "mustoverride",
+		"mybase", "myclass", "nameof", "namespace", "narrowing", "new", "next",
+		"not", "nothing", "notinheritable", "notoverridable", "object", "of", "on",
+		"operator", "option", "optional", "or", "orelse", "overloads",
+		"overridable", "overrides", "paramarray", "partial", "private", "property",
+		"protected", "public", "raiseevent", "readonly", "redim", "rem",
+		"removehandler", "resume", "return", "sbyte", "select", "set", "shadows",
+		"shared", "short", "single", "static", "step", "stop", "string",
+		"structure", "sub", "synclock", "then", "throw", "to", "true", "try",
+		"trycast", "typeof", "uinteger", "ulong", "ushort", "using", "variant",
+		"wend", "when", "while", "widening", "with", "withevents", "writeonly",
+		"xor"),
+	"GraphQL": toSet("Any", "Boolean", "Date", "DateTime", "Decimal", "Float",
+		"ID", "Int", "Long", "Mutation", "Query", "String", "Subscription", "Time"),
+	"SQL": toSet("all", "analyse", "analyze", "and", "any", "array", "as", "asc",
+		"asymmetric", "authorization", "binary", "both", "case", "cast", "check",
+		"collate", "collation", "column", "concurrently", "constraint", "create",
+		"cross", "current_catalog", "current_date", "current_role",
+		"current_schema", "current_time", "current_timestamp", "current_user",
+		"default", "deferrable", "desc", "distinct", "do", "else", "end", "except",
+		"false", "fetch", "for", "foreign", "freeze", "from", "full", "grant",
+		"group", "having", "ilike", "in", "initially", "inner", "intersect", "into",
+		"is", "isnull", "join", "lateral", "leading", "left", "like", "limit",
+		"localtime", "localtimestamp", "natural", "not", "notnull", "null",
+		"offset", "on", "only", "or", "order", "outer", "overlaps", "placing",
+		"primary", "references", "returning", "right", "select", "session_user",
+		"similar", "some", "symmetric", "table", "tablesample", "then", "to",
+		"trailing", "true", "union", "unique", "user", "using", "variadic",
+		"verbose", "when", "where", "window", "with"),
+	"FlatBuffers": toSet("attribute", "bool", "byte", "double", "enum", "false",
+		"file_extension", "file_identifier", "float", "float32", "float64",
+		"include", "int", "int16", "int32", "int64", "int8", "long", "namespace",
+		"native_include", "root_type", "rpc_service", "short", "string", "struct",
+		"table", "true", "ubyte", "uint", "uint16", "uint32", "uint64", "uint8",
+		"ulong", "union", "ushort"),
+	"CapnProto": toSet("AnyList", "AnyPointer", "AnyStruct", "Bool",
+		"Capability", "Data", "Float32", "Float64", "Int16", "Int32", "Int64",
+		"Int8", "List", "Text", "UInt16", "UInt32", "UInt64", "UInt8", "Void",
+		"annotation", "const", "enum", "extends", "false", "group", "import", "inf",
+		"interface", "nan", "struct", "true", "union", "using", "void"),
+}
+
+// defaultEscapeAffix defines the affix for the language which doesn't use
+// "_" to escape reserved words by default.
+var defaultEscapeAffix = map[string]string{
+	"Rust": "_attr",
+	"Nim":  "Field",
+}
+
+// rustNonRawKeywords defines the keywords can't be used as raw identifiers.
+var rustNonRawKeywords = toSet("crate", "self", "Self", "super")
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// escapeReservedWord escapes the identifier if it collides with a reserved
+// word of the target language, and records the escape for reporting.
+func (gen *CodeGenerator) escapeReservedWord(name string) string {
+	word := name
+	if gen.Lang == "VisualBasic" {
+		// The keywords of Visual Basic are case-insensitive, which are
+		// defined in lower case.
+		word = strings.ToLower(name)
+	}
+	if !ReservedWords[gen.Lang][word] {
+		return name
+	}
+	affix := gen.Escape.Affix
+	if affix == "" {
+		if affix = defaultEscapeAffix[gen.Lang]; affix == "" {
+			affix = "_"
+		}
+	}
+	var escaped string
+	switch gen.Escape.Strategy {
+	case EscapePrefix:
+		escaped = affix + name
+	case EscapeBacktick:
+		switch {
+		case gen.Lang == "Rust" && !rustNonRawKeywords[name]:
+			escaped = fmt.Sprintf("r#%s", name)
+		case gen.Lang == "CSharp":
+			escaped = "@" + name
+		case gen.Lang == "SQL":
+			escaped = `"` + name + `"`
+		case gen.Lang == "VisualBasic":
+			escaped = "[" + name + "]"
+		case gen.Lang == "FSharp":
+			escaped = "``" + name + "``"
+		case gen.Lang == "Kotlin" || gen.Lang == "Swift" || gen.Lang == "Nim":
+			escaped = "`" + name + "`"
+		default:
+			escaped = name + affix
+		}
+	default:
+		escaped = name + affix
+	}
+	if gen.Escaped == nil {
+		gen.Escaped = map[string]string{}
+	}
+	gen.Escaped[name] = escaped
+	return escaped
+}
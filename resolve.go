@@ -0,0 +1,60 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// resolveTargets resolves the base, ref and type links of the declarations in
+// a single pass over the proto tree, and returns the targets of the linked
+// names. The name of a simple type links to its base type, and the name of a
+// global element or attribute links to its type, the declaration found first
+// wins if the names are duplicated.
+func resolveTargets(protoTree []interface{}) map[string]string {
+	targets := map[string]string{}
+	link := func(name, target string) {
+		if _, ok := targets[name]; !ok {
+			targets[name] = target
+		}
+	}
+	for _, ele := range protoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if !v.List && !v.Union {
+				link(v.Name, v.Base)
+			}
+		case *Attribute:
+			link(v.Name, v.Type)
+		case *Element:
+			link(v.Name, v.Type)
+		}
+	}
+	return targets
+}
+
+// resolveTarget returns the target of the linked name, or the name itself if
+// it isn't linked.
+func resolveTarget(name string, targets map[string]string) string {
+	if target, ok := targets[name]; ok {
+		return target
+	}
+	return name
+}
+
+// resolveMemberTypes annotates the member types of the unions which are
+// declared before the members with their targets, the member types are
+// unknown while parsing the unions.
+func resolveMemberTypes(protoTree []interface{}, targets map[string]string) {
+	for _, ele := range protoTree {
+		if v, ok := ele.(*SimpleType); ok && v.Union {
+			for i, member := range v.MemberTypes {
+				if member.Type == "" {
+					v.MemberTypes[i].Type = resolveTarget(member.Name, targets)
+				}
+			}
+		}
+	}
+}
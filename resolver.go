@@ -0,0 +1,296 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// xmlNamespace is the namespace bound to the xml prefix, which the xml:base
+// attribute belongs to.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// SchemaResolver resolves the schema location referenced by the import and
+// include statements to the path of the local file. The location is either
+// the URL or the absolute file path, and the resolver returns the empty path
+// if the location couldn't be resolved by it.
+type SchemaResolver interface {
+	Resolve(location string) (path string, err error)
+}
+
+// ResolverChain resolves the schema location by the resolvers in order, the
+// first resolved path is used. The Overrides map the locations to the paths
+// of local files directly, and take precedence over the resolvers.
+type ResolverChain struct {
+	Overrides map[string]string
+	Resolvers []SchemaResolver
+}
+
+// Resolve provides a function to resolve the schema location by the
+// overrides and the resolvers in the chain.
+func (c *ResolverChain) Resolve(location string) (path string, err error) {
+	if path, ok := c.Overrides[location]; ok {
+		return path, nil
+	}
+	for _, resolver := range c.Resolvers {
+		if path, err = resolver.Resolve(location); err != nil || path != "" {
+			return
+		}
+	}
+	return
+}
+
+// SearchPathResolver resolves the schema location to the local file at the
+// location, or the file with the same name in the search paths.
+type SearchPathResolver struct {
+	Paths []string
+}
+
+// Resolve provides a function to resolve the schema location by the local
+// file and the search paths.
+func (r *SearchPathResolver) Resolve(location string) (path string, err error) {
+	name := location
+	if u, err := url.Parse(location); err == nil && isValidURL(location) {
+		name = u.Path
+	} else if isFile(location) {
+		return location, nil
+	}
+	for _, dir := range r.Paths {
+		if path = filepath.Join(dir, filepath.Base(filepath.FromSlash(name))); isFile(path) {
+			return
+		}
+	}
+	return "", nil
+}
+
+// CatalogResolver resolves the schema location by the entries of the XML
+// catalog, the Entries map the URIs to the paths of local files, and the
+// Rewrites replace the prefixes of the URIs.
+type CatalogResolver struct {
+	Entries  map[string]string
+	Rewrites map[string]string
+}
+
+// LoadCatalog loads the OASIS XML catalog by given path, the uri, system,
+// rewriteURI and rewriteSystem entries are supported. The relative paths in
+// the catalog are resolved against the directory of the catalog.
+func LoadCatalog(path string) (catalog *CatalogResolver, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+	var doc struct {
+		Entries []struct {
+			XMLName             xml.Name
+			Name                string `xml:"name,attr"`
+			SystemID            string `xml:"systemId,attr"`
+			URI                 string `xml:"uri,attr"`
+			URIStartString      string `xml:"uriStartString,attr"`
+			SystemIDStartString string `xml:"systemIdStartString,attr"`
+			RewritePrefix       string `xml:"rewritePrefix,attr"`
+		} `xml:",any"`
+	}
+	if err = xml.Unmarshal(data, &doc); err != nil {
+		err = fmt.Errorf("parse catalog %s: %v", path, err)
+		return
+	}
+	catalog = &CatalogResolver{Entries: map[string]string{}, Rewrites: map[string]string{}}
+	for _, entry := range doc.Entries {
+		switch entry.XMLName.Local {
+		case "uri":
+			catalog.Entries[entry.Name] = resolveLocation(path, entry.URI)
+		case "system":
+			catalog.Entries[entry.SystemID] = resolveLocation(path, entry.URI)
+		case "rewriteURI":
+			catalog.Rewrites[entry.URIStartString] = resolveLocation(path, entry.RewritePrefix)
+		case "rewriteSystem":
+			catalog.Rewrites[entry.SystemIDStartString] = resolveLocation(path, entry.RewritePrefix)
+		}
+	}
+	return
+}
+
+// Resolve provides a function to resolve the schema location by the entries
+// of the catalog, the longest matched prefix of the rewrites wins.
+func (r *CatalogResolver) Resolve(location string) (path string, err error) {
+	if path, ok := r.Entries[location]; ok {
+		return path, nil
+	}
+	var prefix string
+	for start := range r.Rewrites {
+		if strings.HasPrefix(location, start) && len(start) > len(prefix) {
+			prefix = start
+		}
+	}
+	if prefix == "" {
+		return
+	}
+	path = r.Rewrites[prefix] + strings.TrimPrefix(location, prefix)
+	if isValidURL(path) {
+		return "", nil
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// CacheResolver resolves the schema URL to the file downloaded in the cache
+// directory previously.
+type CacheResolver struct {
+	Dir string
+}
+
+// Resolve provides a function to resolve the schema URL by the cache
+// directory.
+func (r *CacheResolver) Resolve(location string) (path string, err error) {
+	if path = cachePath(r.Dir, location); path != "" && isFile(path) {
+		return
+	}
+	return "", nil
+}
+
+// NetworkResolver resolves the schema URL by downloading the schema into the
+// cache directory, the schema is downloaded by the Fetcher or the fetcher
+// with the default settings if it's nil.
+type NetworkResolver struct {
+	CacheDir string
+	Fetcher  *Fetcher
+}
+
+// Resolve provides a function to resolve the schema URL by downloading.
+func (r *NetworkResolver) Resolve(location string) (path string, err error) {
+	if path = cachePath(r.CacheDir, location); path == "" {
+		if isValidURL(location) {
+			err = fmt.Errorf("fetch schema %s: invalid path for the cache directory", location)
+		}
+		return
+	}
+	fetcher := r.Fetcher
+	if fetcher == nil {
+		fetcher = &Fetcher{}
+	}
+	var body []byte
+	if body, err = fetcher.Fetch(location); err != nil {
+		err = fmt.Errorf("fetch schema %s: %v", location, err)
+		return
+	}
+	if len(body) == 0 {
+		err = fmt.Errorf("fetch schema %s: empty response", location)
+		return
+	}
+	if err = PrepareOutputDir(filepath.Dir(path)); err != nil {
+		return
+	}
+	err = ioutil.WriteFile(path, body, 0644)
+	return
+}
+
+// DefaultCacheDir returns the default directory for caching the downloaded
+// schemas.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "xgen")
+}
+
+// cachePath returns the path of the schema URL in the cache directory, the
+// path is empty if the location isn't a URL or the path of the URL escapes
+// the cache directory.
+func cachePath(dir, location string) string {
+	if !isValidURL(location) {
+		return ""
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "." || u.Host == ".." || strings.ContainsAny(u.Host, `/\`) {
+		return ""
+	}
+	name := path.Clean("/" + u.Path)
+	if name == "/" || strings.HasSuffix(u.Path, "/") {
+		name = path.Join(name, "index.xsd")
+	}
+	if dir == "" {
+		dir = DefaultCacheDir()
+	}
+	cached := filepath.Join(dir, u.Host, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dir, cached); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return cached
+}
+
+// isFile returns whether the path exists and isn't a directory.
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// resolveLocation resolves the location referenced in the document by the
+// base URI of the document, which is the URL, the file path or the directory
+// with the trailing slash.
+func resolveLocation(base, location string) string {
+	if location == "" || isValidURL(location) || filepath.IsAbs(location) {
+		return location
+	}
+	if isValidURL(base) {
+		u, err := url.Parse(base)
+		if err != nil {
+			return location
+		}
+		ref, err := url.Parse(location)
+		if err != nil {
+			return location
+		}
+		return u.ResolveReference(ref).String()
+	}
+	dir := base
+	if !strings.HasSuffix(base, "/") && !strings.HasSuffix(base, string(filepath.Separator)) {
+		dir = filepath.Dir(base)
+	}
+	resolved := filepath.Join(dir, filepath.FromSlash(location))
+	if strings.HasSuffix(location, "/") {
+		resolved += string(filepath.Separator)
+	}
+	return resolved
+}
+
+// baseURI returns the base URI for resolving the locations referenced by the
+// element, the xml:base attribute of the element is resolved against the
+// base URI of the document.
+func (opt *Options) baseURI(ele xml.StartElement) string {
+	base := opt.BaseURI
+	if base == "" {
+		base = opt.FilePath
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Space == xmlNamespace && attr.Name.Local == "base" {
+			base = resolveLocation(base, attr.Value)
+		}
+	}
+	return base
+}
+
+// resolveSchema resolves the schema location to the path of the local file
+// by the resolver, only the local files are resolved by default.
+func (opt *Options) resolveSchema(location string) (path string, err error) {
+	if _, ok := opt.Sources[filepath.ToSlash(location)]; ok {
+		return location, nil
+	}
+	resolver := opt.Resolver
+	if resolver == nil {
+		resolver = &SearchPathResolver{}
+	}
+	return resolver.Resolve(location)
+}
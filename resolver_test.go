@@ -0,0 +1,97 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolverChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, file := range []string{"search/a.xsd", "catalog/b.xsd", "catalog/lib/c.xsd", "cache/example.com/d.xsd", "override.xsd"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		assert.NoError(t, PrepareOutputDir(filepath.Dir(path)))
+		assert.NoError(t, ioutil.WriteFile(path, []byte("<xs:schema/>"), 0644))
+	}
+	catalogFile := filepath.Join(dir, "catalog", "catalog.xml")
+	assert.NoError(t, ioutil.WriteFile(catalogFile, []byte(`<catalog xmlns="urn:oasis:names:tc:entity:xmlns:xml:catalog">
+  <uri name="http://example.com/b.xsd" uri="b.xsd"/>
+  <rewriteURI uriStartString="http://example.com/lib/" rewritePrefix="lib/"/>
+</catalog>`), 0644))
+	catalog, err := LoadCatalog(catalogFile)
+	assert.NoError(t, err)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("<xs:schema/>"))
+	}))
+	defer server.Close()
+
+	cacheDir := filepath.Join(dir, "cache")
+	chain := &ResolverChain{
+		Overrides: map[string]string{"http://example.com/a.xsd": filepath.Join(dir, "override.xsd")},
+		Resolvers: []SchemaResolver{
+			&SearchPathResolver{Paths: []string{filepath.Join(dir, "search")}},
+			catalog,
+			&CacheResolver{Dir: cacheDir},
+			&NetworkResolver{CacheDir: cacheDir},
+		},
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, c := range []struct {
+		location, expected string
+	}{
+		{"http://example.com/a.xsd", filepath.Join(dir, "override.xsd")},
+		{"http://example.org/schemas/a.xsd", filepath.Join(dir, "search", "a.xsd")},
+		{"http://example.com/b.xsd", filepath.Join(dir, "catalog", "b.xsd")},
+		{"http://example.com/lib/c.xsd", filepath.Join(dir, "catalog", "lib", "c.xsd")},
+		{"http://example.com/d.xsd", filepath.Join(cacheDir, "example.com", "d.xsd")},
+		{server.URL + "/e.xsd", filepath.Join(cacheDir, host, "e.xsd")},
+		{server.URL + "/e.xsd", filepath.Join(cacheDir, host, "e.xsd")},
+	} {
+		path, err := chain.Resolve(c.location)
+		assert.NoError(t, err, c.location)
+		assert.Equal(t, c.expected, path, c.location)
+	}
+	assert.True(t, isFile(filepath.Join(cacheDir, host, "e.xsd")))
+	assert.Equal(t, 1, requests)
+}
+
+func TestCachePath(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), "xgen-cache")
+	for _, c := range []struct {
+		location, expected string
+	}{
+		{"http://example.com/xsd/a.xsd", filepath.Join(dir, "example.com", "xsd", "a.xsd")},
+		{"http://example.com/xsd/", filepath.Join(dir, "example.com", "xsd", "index.xsd")},
+		{"http://example.com", filepath.Join(dir, "example.com", "index.xsd")},
+		{"http://example.com/../../../../home/u/.bashrc", filepath.Join(dir, "example.com", "home", "u", ".bashrc")},
+		{"http://example.com/xsd/%2e%2e/%2E%2E/%2e%2e/a.xsd", filepath.Join(dir, "example.com", "a.xsd")},
+		{"http://../a.xsd", ""},
+		{"a.xsd", ""},
+	} {
+		assert.Equal(t, c.expected, cachePath(dir, c.location), c.location)
+	}
+
+	resolver := &NetworkResolver{CacheDir: dir, Fetcher: &Fetcher{Timeout: 1}}
+	path, err := resolver.Resolve("http://../a.xsd")
+	assert.EqualError(t, err, "fetch schema http://../a.xsd: invalid path for the cache directory")
+	assert.Empty(t, path)
+}
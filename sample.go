@@ -0,0 +1,361 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sampleIntegerBounds defines the value space bounds of the built-in integer
+// types.
+var sampleIntegerBounds = map[string][2]float64{
+	"integer":            {math.Inf(-1), math.Inf(1)},
+	"int":                {math.MinInt32, math.MaxInt32},
+	"long":               {math.MinInt64, math.MaxInt64},
+	"short":              {math.MinInt16, math.MaxInt16},
+	"byte":               {math.MinInt8, math.MaxInt8},
+	"unsignedLong":       {0, math.MaxUint64},
+	"unsignedInt":        {0, math.MaxUint32},
+	"unsignedShort":      {0, math.MaxUint16},
+	"unsignedByte":       {0, math.MaxUint8},
+	"positiveInteger":    {1, math.Inf(1)},
+	"nonNegativeInteger": {0, math.Inf(1)},
+	"negativeInteger":    {math.Inf(-1), -1},
+	"nonPositiveInteger": {math.Inf(-1), 0},
+}
+
+// sampleDecimalTypes defines the built-in types with the decimal value space.
+var sampleDecimalTypes = map[string]bool{"decimal": true, "double": true, "float": true}
+
+// sampleLexicalValues defines the sample values of the built-in types which
+// aren't strings, numbers or binaries.
+var sampleLexicalValues = map[string]string{
+	"boolean":           "true",
+	"date":              "2006-01-02",
+	"dateTime":          "2006-01-02T15:04:05Z",
+	"dateTimeStamp":     "2006-01-02T15:04:05Z",
+	"time":              "15:04:05",
+	"duration":          "P1D",
+	"dayTimeDuration":   "P1D",
+	"yearMonthDuration": "P1Y",
+	"gDay":              "---02",
+	"gMonth":            "--01",
+	"gMonthDay":         "--01-02",
+	"gYear":             "2006",
+	"gYearMonth":        "2006-01",
+	"anyURI":            "http://example.com/",
+	"QName":             "xs:string",
+	"language":          "en",
+}
+
+// SampleValue provides a function to generate a valid value in the lexical
+// form for the built-in schema type by given name, which satisfies the
+// enumerations, pattern, length, range and digits facets of the restriction.
+// An error will be returned if no value satisfying the facets is found.
+func SampleValue(base string, restriction Restriction) (value string, err error) {
+	if len(restriction.Enum) > 0 {
+		return restriction.Enum[0], nil
+	}
+	if restriction.Pattern != nil {
+		return samplePattern(restriction)
+	}
+	if bounds, ok := sampleIntegerBounds[base]; ok {
+		return sampleNumber(restriction, bounds, true)
+	}
+	if sampleDecimalTypes[base] {
+		return sampleNumber(restriction, [2]float64{math.Inf(-1), math.Inf(1)}, false)
+	}
+	switch base {
+	case "base64Binary", "hexBinary":
+		data := []byte(sampleLength("xgen", restriction.MinLength, restriction.MaxLength))
+		if base == "hexBinary" {
+			return hex.EncodeToString(data), nil
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	if value, ok := sampleLexicalValues[base]; ok {
+		return value, nil
+	}
+	return sampleLength("sample", restriction.MinLength, restriction.MaxLength), nil
+}
+
+// sampleLength adjusts the value to the length between the minimum and
+// maximum length.
+func sampleLength(value string, minLength, maxLength int) string {
+	if maxLength > 0 && len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	for len(value) < minLength {
+		value += "x"
+	}
+	return value
+}
+
+// sampleNumber generates the number within the range facets and the bounds
+// of the value space, which is 1 if it's in the range.
+func sampleNumber(restriction Restriction, bounds [2]float64, integer bool) (value string, err error) {
+	lo, hi, loExclusive, hiExclusive := bounds[0], bounds[1], false, false
+	if restriction.HasMin && restriction.Min >= lo {
+		lo, loExclusive = restriction.Min, restriction.MinExclusive
+	}
+	if restriction.HasMax && restriction.Max <= hi {
+		hi, hiExclusive = restriction.Max, restriction.MaxExclusive
+	}
+	if integer {
+		if lo = math.Ceil(lo); loExclusive && lo == restriction.Min {
+			lo++
+		}
+		if hi = math.Floor(hi); hiExclusive && hi == restriction.Max {
+			hi--
+		}
+		loExclusive, hiExclusive = false, false
+	}
+	number := 1.0
+	switch {
+	case number < lo || number == lo && loExclusive:
+		number = lo
+		if loExclusive {
+			number = lo + 1
+			if !math.IsInf(hi, 1) {
+				number = lo + (hi-lo)/2
+			}
+		}
+	case number > hi || number == hi && hiExclusive:
+		number = hi
+		if hiExclusive {
+			number = hi - 1
+			if !math.IsInf(lo, -1) {
+				number = lo + (hi-lo)/2
+			}
+		}
+	}
+	if number < lo || number > hi || number == lo && loExclusive || number == hi && hiExclusive {
+		err = fmt.Errorf("no number satisfies the range facets")
+		return
+	}
+	if integer {
+		value = strconv.FormatFloat(number, 'f', 0, 64)
+	} else {
+		value = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	if fraction := strings.SplitN(value, ".", 2); len(fraction) == 2 && restriction.Precision > 0 && len(fraction[1]) > restriction.Precision {
+		err = fmt.Errorf("no number satisfies the fractionDigits facet")
+		return
+	}
+	if digits := strings.TrimLeft(strings.NewReplacer("-", "", ".", "").Replace(value), "0"); restriction.TotalDigits > 0 && len(digits) > restriction.TotalDigits {
+		err = fmt.Errorf("no number satisfies the totalDigits facet")
+	}
+	return
+}
+
+// samplePattern generates the string matches the pattern facet, the
+// repetitions in the pattern are repeated more times until the string
+// satisfies the length facets.
+func samplePattern(restriction Restriction) (value string, err error) {
+	var re *syntax.Regexp
+	if re, err = syntax.Parse(restriction.Pattern.String(), syntax.Perl); err != nil {
+		return
+	}
+	var anchored *regexp.Regexp
+	if anchored, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", restriction.Pattern.String())); err != nil {
+		return
+	}
+	re = re.Simplify()
+	limit := restriction.MinLength + 16
+	for extra := 0; extra <= limit; extra++ {
+		var sample strings.Builder
+		sampleRegexp(&sample, re, extra)
+		value = sample.String()
+		length := utf8.RuneCountInString(value)
+		if length >= restriction.MinLength && (restriction.MaxLength == 0 || length <= restriction.MaxLength) && anchored.MatchString(value) {
+			return
+		}
+	}
+	return "", fmt.Errorf("no string matches the pattern %s within the length facets", restriction.Pattern.String())
+}
+
+// sampleRegexp writes the string matches the regular expression, the extra
+// is the times of repetitions beyond the minimum.
+func sampleRegexp(sample *strings.Builder, re *syntax.Regexp, extra int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sample.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sample.WriteRune(sampleRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sample.WriteRune('x')
+	case syntax.OpCapture:
+		sampleRegexp(sample, re.Sub[0], extra)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			sampleRegexp(sample, sub, extra)
+		}
+	case syntax.OpAlternate:
+		sampleRegexp(sample, re.Sub[0], extra)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		times := extra
+		if re.Op == syntax.OpPlus {
+			times++
+		}
+		if re.Op == syntax.OpQuest && times > 1 {
+			times = 1
+		}
+		for i := 0; i < times; i++ {
+			sampleRegexp(sample, re.Sub[0], extra)
+		}
+	case syntax.OpRepeat:
+		times := re.Min + extra
+		if re.Max != -1 && times > re.Max {
+			times = re.Max
+		}
+		for i := 0; i < times; i++ {
+			sampleRegexp(sample, re.Sub[0], extra)
+		}
+	}
+}
+
+// sampleRune returns the character in the ranges of the character class, the
+// printable ASCII letters and digits are preferred.
+func sampleRune(ranges []rune) rune {
+	for _, preferred := range [][2]rune{{'a', 'z'}, {'A', 'Z'}, {'0', '9'}, {'!', '~'}} {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			lo, hi := ranges[i], ranges[i+1]
+			if lo < preferred[0] {
+				lo = preferred[0]
+			}
+			if hi > preferred[1] {
+				hi = preferred[1]
+			}
+			if lo <= hi {
+				return lo
+			}
+		}
+	}
+	if len(ranges) > 0 {
+		return ranges[0]
+	}
+	return 'x'
+}
+
+// Samples provides a function to generate a valid value in the lexical form
+// for every simple type in the intermediate representation, the facets of the
+// restricted simple types are inherited. The simple types without any valid
+// value found are reported by the error, and omitted in the samples.
+func (ir *IR) Samples() (samples map[string]string, err error) {
+	simpleTypes := map[string]*SimpleType{}
+	for _, declaration := range ir.Declarations {
+		if declaration.SimpleType != nil {
+			if _, ok := simpleTypes[declaration.SimpleType.Name]; !ok {
+				simpleTypes[declaration.SimpleType.Name] = declaration.SimpleType
+			}
+		}
+	}
+	samples = map[string]string{}
+	var failed []string
+	for _, declaration := range ir.Declarations {
+		if v := declaration.SimpleType; v != nil {
+			if _, ok := samples[v.Name]; ok {
+				continue
+			}
+			value, sampleErr := sampleSimpleType(v, simpleTypes, map[string]bool{})
+			if sampleErr != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", v.Name, sampleErr))
+				continue
+			}
+			samples[v.Name] = value
+		}
+	}
+	if len(failed) > 0 {
+		err = fmt.Errorf("no valid sample for %s", strings.Join(failed, "; "))
+	}
+	return
+}
+
+// sampleSimpleType generates the sample value for the simple type, the
+// visited types are tracked to break the circular derivations.
+func sampleSimpleType(v *SimpleType, simpleTypes map[string]*SimpleType, visited map[string]bool) (value string, err error) {
+	if visited[v.Name] {
+		err = fmt.Errorf("circular derivation of %s", v.Name)
+		return
+	}
+	visited[v.Name] = true
+	defer delete(visited, v.Name)
+	restriction := v.Restriction
+	if len(restriction.Enum) > 0 {
+		return restriction.Enum[0], nil
+	}
+	if v.Union {
+		for _, member := range v.MemberTypes {
+			if value, err = sampleTypeName(member.Name, Restriction{}, simpleTypes, visited); err == nil {
+				return
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("no member types")
+		}
+		return
+	}
+	if v.List {
+		if value, err = sampleTypeName(restriction.Base, Restriction{}, simpleTypes, visited); err != nil {
+			return
+		}
+		items := restriction.MinLength
+		if items == 0 {
+			items = 1
+		}
+		return strings.TrimSpace(strings.Repeat(value+" ", items)), nil
+	}
+	return sampleTypeName(restriction.Base, restriction, simpleTypes, visited)
+}
+
+// sampleTypeName generates the sample value for the schema type by given
+// name with the facets, the facets not specified are inherited from the
+// simple type by the name.
+func sampleTypeName(name string, restriction Restriction, simpleTypes map[string]*SimpleType, visited map[string]bool) (string, error) {
+	base, ok := simpleTypes[name]
+	if !ok || visited[name] {
+		return SampleValue(name, restriction)
+	}
+	inherited := base.Restriction
+	if base.Union || base.List || restriction.Enum == nil && restriction.Pattern == nil && restriction.MinLength == 0 && restriction.MaxLength == 0 && !restriction.HasMin && !restriction.HasMax && restriction.Precision == 0 && restriction.TotalDigits == 0 {
+		return sampleSimpleType(base, simpleTypes, visited)
+	}
+	if restriction.Pattern == nil {
+		restriction.Pattern = inherited.Pattern
+	}
+	if restriction.MinLength == 0 {
+		restriction.MinLength = inherited.MinLength
+	}
+	if restriction.MaxLength == 0 {
+		restriction.MaxLength = inherited.MaxLength
+	}
+	if !restriction.HasMin {
+		restriction.Min, restriction.HasMin, restriction.MinExclusive = inherited.Min, inherited.HasMin, inherited.MinExclusive
+	}
+	if !restriction.HasMax {
+		restriction.Max, restriction.HasMax, restriction.MaxExclusive = inherited.Max, inherited.HasMax, inherited.MaxExclusive
+	}
+	if restriction.Precision == 0 {
+		restriction.Precision = inherited.Precision
+	}
+	if restriction.TotalDigits == 0 {
+		restriction.TotalDigits = inherited.TotalDigits
+	}
+	visited[name] = true
+	defer delete(visited, name)
+	return sampleTypeName(inherited.Base, restriction, simpleTypes, visited)
+}
@@ -29,15 +29,7 @@ func (opt *Options) prepareNSSchemaLocationMap(element xml.StartElement) {
 			if _, ok := opt.NSSchemaLocationMap[currentNS]; ok {
 				continue
 			}
-			if isValidURL(ele.Value) {
-				continue
-				// TODO: fetch remote schema
-				// var err error
-				// if opt.RemoteSchema[ele.Value], err = fetchSchema(ele.Value); err != nil {
-				// 	continue
-				// }
-			}
-			opt.NSSchemaLocationMap[currentNS] = ele.Value
+			opt.NSSchemaLocationMap[currentNS] = resolveLocation(opt.baseURI(element), ele.Value)
 		}
 	}
 	return
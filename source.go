@@ -0,0 +1,132 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// trimSource trims the XSD fragment of the declaration, the surrounding
+// blank lines and the common indentation of the lines are removed.
+func trimSource(fragment string) string {
+	lines := strings.Split(strings.Replace(fragment, "\r\n", "\n", -1), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for i, line := range lines {
+		line = strings.TrimRight(strings.Replace(line, "\t", "    ", -1), " ")
+		lines[i] = line
+		if line == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " ")); indent == -1 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent {
+			lines[i] = line[indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setSource sets the XSD fragment and the location in the form of file:line
+// of the global declaration on the types generated from the declaration by
+// given name.
+func setSource(protoTree []interface{}, name, source, location string) {
+	for _, ele := range protoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		case *ComplexType:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		case *Element:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		case *Attribute:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		case *Group:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				v.Source = source
+				v.Location = location
+			}
+		}
+	}
+}
+
+// sourceFile returns the name of the schema file for the locations, which is
+// relative to the input directory if the file is inside it.
+func (opt *Options) sourceFile() string {
+	if rel, err := filepath.Rel(opt.InputDir, opt.FilePath); err == nil && opt.InputDir != "" && rel != "." && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(opt.FilePath)
+}
+
+// statSchema returns whether the schema path is a directory, the path is
+// looked up in the in-memory sources if they are specified.
+func (opt *Options) statSchema(path string) (isDir bool, err error) {
+	if opt.Sources == nil {
+		var fi os.FileInfo
+		if fi, err = os.Stat(path); err != nil {
+			return
+		}
+		return fi.IsDir(), nil
+	}
+	path = filepath.ToSlash(path)
+	if _, ok := opt.Sources[path]; ok {
+		return false, nil
+	}
+	for name := range opt.Sources {
+		if path == "." || strings.HasPrefix(name, strings.TrimSuffix(path, "/")+"/") {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("open %s: file does not exist", path)
+}
+
+// openSchema opens the schema by given path, the schema is read from the
+// in-memory sources if they are specified.
+func (opt *Options) openSchema(path string) (io.ReadCloser, error) {
+	if opt.Sources == nil {
+		return os.Open(path)
+	}
+	data, ok := opt.Sources[filepath.ToSlash(path)]
+	if !ok {
+		return nil, fmt.Errorf("open %s: file does not exist", path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// goXsdDump defines the helper rendering the values of the generated Go code
+// as the indented XML, which the String methods of the structs call.
+const goXsdDump = `
+// XsdDump renders the value as the indented XML for debugging, the error of
+// encoding it is rendered as an XML comment.
+func XsdDump(v interface{}) string {
+	output, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "<!-- " + err.Error() + " -->"
+	}
+	return string(output)
+}
+`
+
+// genStringers appends the String methods of the structs declared in the
+// given files of the Go code to the files. The structs based on the support
+// types keep the String methods of them, and the structs which have the
+// String method or field are skipped.
+func (d *goTypeDecls) genStringers(paths []string, files map[string]string) {
+	for _, path := range paths {
+		for _, name := range d.declared[path] {
+			base := d.structName(name)
+			if base == "" || d.support[base] || d.hasMember(name, base, "String") {
+				continue
+			}
+			files[path] += fmt.Sprintf("\n// String returns the %[1]s as the indented XML for debugging.\nfunc (v %[1]s) String() string {\n\treturn XsdDump(&v)\n}\n", name)
+		}
+	}
+}
+
+// hasMember reports whether the type by given name declares the method, or
+// the struct it's based on has the field by given member name.
+func (d *goTypeDecls) hasMember(name, base, member string) bool {
+	if d.methods[name][member] {
+		return true
+	}
+	if typ, ok := d.types[base].(*ast.StructType); ok {
+		for _, field := range typ.Fields.List {
+			for _, fieldName := range goFieldNames(field) {
+				if fieldName == member {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// globalElements returns the global elements declared in the proto tree by
+// the names.
+func (gen *CodeGenerator) globalElements() map[string]*Element {
+	elements := map[string]*Element{}
+	for _, ele := range gen.ProtoTree {
+		if element, ok := ele.(*Element); ok {
+			elements[element.Name] = element
+		}
+	}
+	return elements
+}
+
+// substitutionHeads returns the heads of the substitution groups which the
+// global element could be substituted for, from the nearest one. The heads
+// not declared in the proto tree are ignored.
+func substitutionHeads(element *Element, elements map[string]*Element) (heads []string) {
+	seen := map[string]bool{element.Name: true}
+	for name := element.SubstitutionGroup; name != "" && !seen[name]; {
+		head, ok := elements[name]
+		if !ok {
+			break
+		}
+		heads, seen[name] = append(heads, name), true
+		name = head.SubstitutionGroup
+	}
+	return
+}
+
+// substitutionMembers returns the global elements in the substitution group
+// of the head element by given name, including the members of the nested
+// substitution groups, in the order of declaration.
+func (gen *CodeGenerator) substitutionMembers(head string) (members []*Element) {
+	elements := gen.globalElements()
+	for _, ele := range gen.ProtoTree {
+		element, ok := ele.(*Element)
+		if !ok || element.SubstitutionGroup == "" {
+			continue
+		}
+		for _, name := range substitutionHeads(element, elements) {
+			if name == head {
+				members = append(members, element)
+				break
+			}
+		}
+	}
+	return
+}
+
+// substitutionType returns the type of the global element, the element
+// declared without the type has the type of the nearest head of the
+// substitution groups declared with the type.
+func (gen *CodeGenerator) substitutionType(element *Element) string {
+	if trimNSPrefix(element.Type) != element.Name {
+		return element.Type
+	}
+	elements := gen.globalElements()
+	for _, head := range substitutionHeads(element, elements) {
+		if trimNSPrefix(elements[head].Type) != head {
+			return elements[head].Type
+		}
+	}
+	return element.Type
+}
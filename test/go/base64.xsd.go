@@ -4,7 +4,6 @@ package schema
 
 import (
 	"encoding/xml"
-	"time"
 )
 
 // MyType1 ...
@@ -24,11 +23,11 @@ type MyType3 struct {
 
 // MyType4 ...
 type MyType4 struct {
-	XMLName   xml.Name  `xml:"myType4"`
-	Title     string    `xml:"title"`
-	Blob      []byte    `xml:"blob"`
-	Timestamp time.Time `xml:"timestamp"`
+	XMLName   xml.Name    `xml:"myType4"`
+	Title     string      `xml:"title"`
+	Blob      []byte      `xml:"blob"`
+	Timestamp XsdDateTime `xml:"timestamp"`
 }
 
 // MyType5 ...
-type MyType5 time.Time
+type MyType5 XsdGDay
@@ -10,9 +10,9 @@ package xgen
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -34,26 +34,107 @@ func ToSnakeCase(input string) string {
 	return strings.ToLower(output)
 }
 
-// GetFileList get a list of file by given path.
+// DefaultSchemaExtensions defines the extensions of the files discovered as
+// the schema definitions by default.
+var DefaultSchemaExtensions = []string{".xsd", ".wsdl"}
+
+// vcsDirs defines the directories of the version control systems, which are
+// skipped in the file discovery.
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".bzr": true, "CVS": true, "_darcs": true}
+
+// FileListOptions holds the options for discovering the schema files in a
+// directory. The files are filtered by the Extensions, the extensions are
+// matched case-insensitively and DefaultSchemaExtensions are used if not
+// specified. The MaxDepth limits the depth of the sub-directories to walk
+// into, the files in the directory are in the depth 1, and there is no limit
+// if it's not positive.
+type FileListOptions struct {
+	Extensions []string
+	MaxDepth   int
+}
+
+// GetFileList get a list of schema files by given path with the default
+// options. The path is returned if it's a file.
 func GetFileList(path string) (files []string, err error) {
+	return GetFileListWithOptions(path, FileListOptions{})
+}
+
+// GetFileListWithOptions get a list of schema files by given path and
+// options. The path is returned if it's a file, otherwise the files with the
+// schema extensions in the directory are returned in lexical order, the
+// hidden files and directories and the directories of the version control
+// systems are skipped, and every file is returned only once.
+func GetFileListWithOptions(path string, opts FileListOptions) (files []string, err error) {
 	var fi os.FileInfo
-	fi, err = os.Stat(path)
-	if err != nil {
+	if fi, err = os.Stat(path); err != nil {
 		return
 	}
-	if fi.IsDir() {
-		err = filepath.Walk(path, func(fp string, info os.FileInfo, err error) error {
-			files = append(files, fp)
-			return nil
-		})
+	if !fi.IsDir() {
+		files = append(files, path)
+		return
+	}
+	extensions := opts.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultSchemaExtensions
+	}
+	root := filepath.Clean(path)
+	seen := map[string]bool{}
+	err = filepath.Walk(root, func(fp string, info os.FileInfo, err error) error {
 		if err != nil {
-			return
+			return err
 		}
-	}
-	files = append(files, path)
+		if fp == root {
+			return nil
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if strings.HasPrefix(name, ".") || vcsDirs[name] {
+				return filepath.SkipDir
+			}
+			if rel, err := filepath.Rel(root, fp); err == nil && opts.MaxDepth > 0 && len(strings.Split(rel, string(filepath.Separator))) >= opts.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || !hasExtension(name, extensions) {
+			return nil
+		}
+		key := fp
+		if resolved, err := filepath.EvalSymlinks(fp); err == nil {
+			key = resolved
+		}
+		if !seen[key] {
+			seen[key] = true
+			files = append(files, fp)
+		}
+		return nil
+	})
 	return
 }
 
+// hasExtension returns whether the file name has any of the extensions.
+func hasExtension(name string, extensions []string) bool {
+	for _, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFile writes the generated file by given path, the file is kept in the
+// outputs instead of writing to the file system if the outputs are specified.
+func (gen *CodeGenerator) writeFile(path string, data []byte) error {
+	if gen.Outputs != nil {
+		gen.Outputs[filepath.ToSlash(path)] = data
+		return nil
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
 // PrepareOutputDir provide a method to create the output directory by given
 // path.
 func PrepareOutputDir(path string) error {
@@ -69,75 +150,308 @@ func PrepareOutputDir(path string) error {
 	return nil
 }
 
-// BuildInTypes defines the correspondence between Go, TypeScript, C, Java,
-// Rust languages and data types in XSD.
+// BuildInTypes defines the correspondence between the data types in XSD and
+// the types of each language, the columns are in the order of
+// buildInTypeLang.
 // https://www.w3.org/TR/xmlschema-2/#datatype
 var BuildInTypes = map[string][]string{
-	"anyType":            {"string", "string", "char", "String", "String", "String"},
-	"ENTITIES":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array"},
-	"ENTITY":             {"string", "string", "char", "String", "String", "String"},
-	"ID":                 {"string", "string", "char", "String", "String", "String"},
-	"IDREF":              {"string", "string", "char", "String", "String", "String"},
-	"IDREFS":             {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array"},
-	"NCName":             {"string", "string", "char", "String", "String", "String"},
-	"NMTOKEN":            {"string", "string", "char", "String", "String", "String"},
-	"NMTOKENS":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array"},
-	"NOTATION":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array"},
-	"Name":               {"string", "string", "char", "String", "String", "String"},
-	"QName":              {"xml.Name", "any", "char", "String", "String", "String"},
-	"anyURI":             {"string", "string", "char", "QName", "String", "String"},
-	"base64Binary":       {"[]byte", "Uint8Array", "char[]", "List<Byte>", "String", "Array"},
-	"boolean":            {"bool", "boolean", "bool", "Boolean", "bool", "Boolean"},
-	"byte":               {"byte", "any", "char[]", "Byte", "u8", "String"},
-	"date":               {"time.Time", "string", "char", "Byte", "u8", "Date"},
-	"dateTime":           {"time.Time", "string", "char", "Byte", "u8", "DateTime"},
-	"decimal":            {"float64", "number", "float", "Float", "f64", "Float"},
-	"double":             {"float64", "number", "float", "Float", "f64", "Float"},
-	"duration":           {"string", "string", "char", "String", "String", "String"},
-	"float":              {"float", "number", "float", "Float", "f64", "Float"},
-	"gDay":               {"time.Time", "string", "char", "String", "String", "String"},
-	"gMonth":             {"time.Time", "string", "char", "String", "String", "String"},
-	"gMonthDay":          {"time.Time", "string", "char", "String", "String", "String"},
-	"gYear":              {"time.Time", "string", "char", "String", "String", "String"},
-	"gYearMonth":         {"time.Time", "string", "char", "String", "String", "String"},
-	"hexBinary":          {"[]byte", "Uint8Array", "char[]", "List<Byte>", "String", "Array"},
-	"int":                {"int", "number", "int", "Integer", "i32", "Integer"},
-	"integer":            {"int", "number", "int", "Integer", "i32", "Integer"},
-	"language":           {"string", "string", "char", "String", "String", "String"},
-	"long":               {"int64", "number", "int", "Long", "i64", "Integer"},
-	"negativeInteger":    {"int", "number", "int", "Integer", "i32", "Integer"},
-	"nonNegativeInteger": {"int", "number", "int", "Integer", "u32", "Integer"},
-	"normalizedString":   {"string", "string", "char", "String", "String", "String"},
-	"nonPositiveInteger": {"int", "number", "int", "Integer", "i32", "Integer"},
-	"positiveInteger":    {"int", "number", "int", "Integer", "u32", "Integer"},
-	"short":              {"int16", "number", "int", "Integer", "i16", "Integer"},
-	"string":             {"string", "string", "char", "String", "String", "String"},
-	"time":               {"time.Time", "string", "char", "String", "String", "Time"},
-	"token":              {"string", "string", "char", "String", "String", "String"},
-	"unsignedByte":       {"byte", "any", "char", "Byte", "u8", "String"},
-	"unsignedInt":        {"uint32", "number", "unsigned int", "Integer", "u32", "Integer"},
-	"unsignedLong":       {"uint64", "number", "unsigned int", "Long", "u64", "Bignum"},
-	"unsignedShort":      {"uint16", "number", "unsigned int", "Short", "u16", "Integer"},
-	"xml:lang":           {"string", "string", "char", "String", "String", "String"},
-	"xml:space":          {"string", "string", "char", "String", "String", "String"},
-	"xml:base":           {"string", "string", "char", "String", "String", "String"},
-	"xml:id":             {"string", "string", "char", "String", "String", "String"},
+	"anyType":            {"string", "string", "char", "String", "String", "String", "Any", "object", "Any", "String", "mixed", "String", "term()", "Text", "string", "[]const u8", "obj", "NSString *", "GPathResult", "any", "Any", "XML::Node", "XmlNode", "EzXML.Node", "Object", "any", "google.protobuf.Any", "string", "Any", "any", "XML", "string", "AnyPointer", "_", "xs:anyType", "xs:anyType", "xs:anyType"},
+	"ENTITIES":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array", "List[str]", "List<string>", "List<String>", "[String]", "array", "List<String>", "[String.t()]", "[Text]", "string list", "[]const []const u8", "string list", "NSArray<NSString *> *", "List<String>", "string[]", "ArrayRef[Str]", "Array(String)", "seq[string]", "Vector{String}", "List(Of String)", "array", "repeated string", "array", "[String]", "array", "TEXT[]", "[string]", "List(Text)", "[...string]", "xs:ENTITIES", "xs:ENTITIES", "xs:ENTITIES"},
+	"ENTITY":             {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:ENTITY", "xs:ENTITY", "xs:ENTITY"},
+	"ID":                 {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "ID", "string", "TEXT", "string", "Text", "string", "xs:ID", "xs:ID", "xs:ID"},
+	"IDREF":              {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "ID", "string", "TEXT", "string", "Text", "string", "xs:IDREF", "xs:IDREF", "xs:IDREF"},
+	"IDREFS":             {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array", "List[str]", "List<string>", "List<String>", "[String]", "array", "List<String>", "[String.t()]", "[Text]", "string list", "[]const []const u8", "string list", "NSArray<NSString *> *", "List<String>", "string[]", "ArrayRef[Str]", "Array(String)", "seq[string]", "Vector{String}", "List(Of String)", "array", "repeated string", "array", "[String]", "array", "TEXT[]", "[string]", "List(Text)", "[...string]", "xs:IDREFS", "xs:IDREFS", "xs:IDREFS"},
+	"NCName":             {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:NCName", "xs:NCName", "xs:NCName"},
+	"NMTOKEN":            {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:NMTOKEN", "xs:NMTOKEN", "xs:NMTOKEN"},
+	"NMTOKENS":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array", "List[str]", "List<string>", "List<String>", "[String]", "array", "List<String>", "[String.t()]", "[Text]", "string list", "[]const []const u8", "string list", "NSArray<NSString *> *", "List<String>", "string[]", "ArrayRef[Str]", "Array(String)", "seq[string]", "Vector{String}", "List(Of String)", "array", "repeated string", "array", "[String]", "array", "TEXT[]", "[string]", "List(Text)", "[...string]", "xs:NMTOKENS", "xs:NMTOKENS", "xs:NMTOKENS"},
+	"NOTATION":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>", "Array", "List[str]", "List<string>", "List<String>", "[String]", "array", "List<String>", "[String.t()]", "[Text]", "string list", "[]const []const u8", "string list", "NSArray<NSString *> *", "List<String>", "string[]", "ArrayRef[Str]", "Array(String)", "seq[string]", "Vector{String}", "List(Of String)", "array", "repeated string", "array", "[String]", "array", "TEXT[]", "[string]", "List(Text)", "[...string]", "xs:NOTATION", "xs:NOTATION", "xs:NOTATION"},
+	"Name":               {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:Name", "xs:Name", "xs:Name"},
+	"QName":              {"xml.Name", "any", "char", "String", "String", "String", "str", "XmlQualifiedName", "QName", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "XmlQualifiedName", "NSString *", "String", "string", "Str", "String", "string", "String", "XmlQualifiedName", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:QName", "xs:QName", "xs:QName"},
+	"anyURI":             {"string", "string", "char", "QName", "String", "String", "str", "string", "String", "URL", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "uri", "string", "string", "String", "uri", "TEXT", "string", "Text", "string", "xs:anyURI", "xs:anyURI", "xs:anyURI"},
+	"base64Binary":       {"[]byte", "Uint8Array", "char[]", "List<Byte>", "String", "Array", "bytes", "byte[]", "ByteArray", "Data", "string", "String", "String.t()", "Text", "string", "[]const u8", "byte[]", "NSData *", "byte[]", "string", "Str", "String", "string", "String", "Byte()", "base64", "bytes", "bytes", "String", "base64", "BYTEA", "[ubyte]", "Data", "string", "xs:base64Binary", "xs:base64Binary", "xs:base64Binary"},
+	"boolean":            {"bool", "boolean", "bool", "Boolean", "bool", "Boolean", "bool", "bool", "Boolean", "Bool", "bool", "bool", "boolean()", "Bool", "bool", "bool", "bool", "BOOL", "Boolean", "boolean", "Bool", "Bool", "bool", "Bool", "Boolean", "boolean", "bool", "boolean", "Boolean", "boolean", "BOOLEAN", "bool", "Bool", "bool", "xs:boolean", "xs:boolean", "xs:boolean"},
+	"byte":               {"byte", "any", "char[]", "Byte", "u8", "String", "int", "sbyte", "Byte", "Int8", "int", "int", "integer()", "Int8", "int", "i8", "sbyte", "NSInteger", "Byte", "integer", "Int", "Int8", "int8", "Int8", "SByte", "int8", "int32", "int", "Int", "int8", "SMALLINT", "byte", "Int8", "int8", "xs:byte", "xs:byte", "xs:byte"},
+	"date":               {"XsdDate", "string", "char", "Byte", "u8", "Date", "date", "DateTime", "LocalDate", "Date", "string", "DateTime", "Date.t()", "Text", "string", "[]const u8", "DateTime", "NSDate *", "String", "string", "Str", "String", "string", "String", "Date", "date", "string", "date", "Date", "date", "DATE", "string", "Text", "string", "xs:date", "xs:date", "xs:date"},
+	"dateTime":           {"XsdDateTime", "string", "char", "Byte", "u8", "DateTime", "datetime", "DateTime", "LocalDateTime", "Date", "\\DateTimeImmutable", "DateTime", "NaiveDateTime.t()", "Text", "string", "[]const u8", "DateTime", "NSDate *", "String", "string", "Str", "String", "string", "String", "Date", "date-time", "google.protobuf.Timestamp", "timestamp-millis", "DateTime", "date-time", "TIMESTAMP WITH TIME ZONE", "string", "Text", "string", "xs:dateTime", "xs:dateTime", "xs:dateTime"},
+	"dateTimeStamp":      {"XsdDateTime", "string", "char", "Byte", "u8", "DateTime", "datetime", "DateTime", "LocalDateTime", "Date", "\\DateTimeImmutable", "DateTime", "NaiveDateTime.t()", "Text", "string", "[]const u8", "DateTime", "NSDate *", "String", "string", "Str", "String", "string", "String", "Date", "date-time", "google.protobuf.Timestamp", "timestamp-millis", "DateTime", "date-time", "TIMESTAMP WITH TIME ZONE", "string", "Text", "string", "xs:dateTimeStamp", "xs:dateTimeStamp", "xs:dateTimeStamp"},
+	"dayTimeDuration":    {"string", "string", "char", "String", "String", "String", "str", "string", "Duration", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "duration", "string", "string", "String", "duration", "INTERVAL", "string", "Text", "string", "xs:dayTimeDuration", "xs:dayTimeDuration", "xs:dayTimeDuration"},
+	"decimal":            {"float64", "number", "float", "Float", "f64", "Float", "Decimal", "decimal", "BigDecimal", "Decimal", "string", "double", "Decimal.t()", "Scientific", "float", "f64", "decimal", "NSDecimalNumber *", "BigDecimal", "number", "Num", "BigDecimal", "float64", "Float64", "Decimal", "number", "string", "string", "Decimal", "number", "NUMERIC", "string", "Text", "number", "xs:decimal", "xs:decimal", "xs:decimal"},
+	"double":             {"float64", "number", "float", "Float", "f64", "Float", "float", "double", "Double", "Double", "float", "double", "float()", "Double", "float", "f64", "float", "double", "Double", "number", "Num", "Float64", "float64", "Float64", "Double", "number", "double", "double", "Float", "number", "DOUBLE PRECISION", "double", "Float64", "number", "xs:double", "xs:double", "xs:double"},
+	"duration":           {"string", "string", "char", "String", "String", "String", "str", "string", "Duration", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "duration", "string", "string", "String", "duration", "INTERVAL", "string", "Text", "string", "xs:duration", "xs:duration", "xs:duration"},
+	"float":              {"float", "number", "float", "Float", "f64", "Float", "float", "float", "Float", "Float", "float", "double", "float()", "Float", "float", "f32", "float32", "float", "Float", "number", "Num", "Float32", "float32", "Float32", "Single", "number", "float", "float", "Float", "number", "REAL", "float", "Float32", "number", "xs:float", "xs:float", "xs:float"},
+	"gDay":               {"XsdGDay", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:gDay", "xs:gDay", "xs:gDay"},
+	"gMonth":             {"XsdGMonth", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:gMonth", "xs:gMonth", "xs:gMonth"},
+	"gMonthDay":          {"XsdGMonthDay", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:gMonthDay", "xs:gMonthDay", "xs:gMonthDay"},
+	"gYear":              {"XsdGYear", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:gYear", "xs:gYear", "xs:gYear"},
+	"gYearMonth":         {"XsdGYearMonth", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:gYearMonth", "xs:gYearMonth", "xs:gYearMonth"},
+	"hexBinary":          {"[]byte", "Uint8Array", "char[]", "List<Byte>", "String", "Array", "bytes", "byte[]", "ByteArray", "Data", "string", "String", "String.t()", "Text", "string", "[]const u8", "byte[]", "NSString *", "String", "string", "Str", "String", "string", "String", "Byte()", "base16", "bytes", "bytes", "String", "base16", "BYTEA", "[ubyte]", "Data", "string", "xs:hexBinary", "xs:hexBinary", "xs:hexBinary"},
+	"int":                {"int", "number", "int", "Integer", "i32", "Integer", "int", "int", "Int", "Int32", "int", "int", "integer()", "Int32", "int", "i32", "int", "NSInteger", "Integer", "integer", "Int", "Int32", "int32", "Int32", "Integer", "int32", "int32", "int", "Int", "int32", "INTEGER", "int", "Int32", "int32", "xs:int", "xs:int", "xs:int"},
+	"integer":            {"int", "number", "int", "Integer", "i32", "Integer", "int", "long", "BigInteger", "Int", "int", "BigInt", "integer()", "Integer", "int", "i64", "bigint", "NSInteger", "BigInteger", "integer", "Int", "BigInt", "int64", "BigInt", "Long", "integer", "int64", "long", "Long", "integer", "NUMERIC", "long", "Int64", "int", "xs:integer", "xs:integer", "xs:integer"},
+	"language":           {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:language", "xs:language", "xs:language"},
+	"long":               {"int64", "number", "int", "Long", "i64", "Integer", "int", "long", "Long", "Int64", "int", "int", "integer()", "Int64", "int64", "i64", "int64", "NSInteger", "Long", "integer", "Int", "Int64", "int64", "Int64", "Long", "int64", "int64", "long", "Long", "int64", "BIGINT", "long", "Int64", "int64", "xs:long", "xs:long", "xs:long"},
+	"negativeInteger":    {"int", "number", "int", "Integer", "i32", "Integer", "int", "long", "BigInteger", "Int", "int", "BigInt", "integer()", "Integer", "int", "i64", "bigint", "NSInteger", "BigInteger", "integer", "Int", "BigInt", "int64", "BigInt", "Long", "negativeInteger", "int64", "long", "Long", "negativeInteger", "NUMERIC", "long", "Int64", "int & <=-1", "xs:negativeInteger", "xs:negativeInteger", "xs:negativeInteger"},
+	"nonNegativeInteger": {"int", "number", "int", "Integer", "u32", "Integer", "int", "long", "BigInteger", "Int", "int", "BigInt", "integer()", "Integer", "int", "i64", "bigint", "NSInteger", "BigInteger", "integer", "Int", "BigInt", "int64", "BigInt", "Long", "nonNegativeInteger", "int64", "long", "Long", "nonNegativeInteger", "NUMERIC", "long", "Int64", "uint", "xs:nonNegativeInteger", "xs:nonNegativeInteger", "xs:nonNegativeInteger"},
+	"normalizedString":   {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:normalizedString", "xs:normalizedString", "xs:normalizedString"},
+	"nonPositiveInteger": {"int", "number", "int", "Integer", "i32", "Integer", "int", "long", "BigInteger", "Int", "int", "BigInt", "integer()", "Integer", "int", "i64", "bigint", "NSInteger", "BigInteger", "integer", "Int", "BigInt", "int64", "BigInt", "Long", "nonPositiveInteger", "int64", "long", "Long", "nonPositiveInteger", "NUMERIC", "long", "Int64", "int & <=0", "xs:nonPositiveInteger", "xs:nonPositiveInteger", "xs:nonPositiveInteger"},
+	"positiveInteger":    {"int", "number", "int", "Integer", "u32", "Integer", "int", "long", "BigInteger", "Int", "int", "BigInt", "integer()", "Integer", "int", "i64", "bigint", "NSInteger", "BigInteger", "integer", "Int", "BigInt", "int64", "BigInt", "Long", "positiveInteger", "int64", "long", "Long", "positiveInteger", "NUMERIC", "long", "Int64", "int & >=1", "xs:positiveInteger", "xs:positiveInteger", "xs:positiveInteger"},
+	"short":              {"int16", "number", "int", "Integer", "i16", "Integer", "int", "short", "Short", "Int16", "int", "int", "integer()", "Int16", "int", "i16", "int16", "NSInteger", "Short", "integer", "Int", "Int16", "int16", "Int16", "Short", "int16", "int32", "int", "Int", "int16", "SMALLINT", "short", "Int16", "int16", "xs:short", "xs:short", "xs:short"},
+	"string":             {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:string", "xs:string", "xs:string"},
+	"time":               {"XsdTime", "string", "char", "String", "String", "Time", "time", "DateTime", "LocalTime", "String", "string", "String", "Time.t()", "Text", "string", "[]const u8", "DateTime", "NSString *", "String", "string", "Str", "String", "string", "String", "Date", "time", "string", "time-millis", "Time", "time", "TIME", "string", "Text", "string", "xs:time", "xs:time", "xs:time"},
+	"token":              {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xs:token", "xs:token", "xs:token"},
+	"unsignedByte":       {"byte", "any", "char", "Byte", "u8", "String", "int", "byte", "Short", "UInt8", "int", "int", "integer()", "Word8", "int", "u8", "byte", "NSUInteger", "Short", "integer", "Int", "UInt8", "uint8", "UInt8", "Byte", "uint8", "int32", "int", "Int", "uint8", "SMALLINT", "ubyte", "UInt8", "uint8", "xs:unsignedByte", "xs:unsignedByte", "xs:unsignedByte"},
+	"unsignedInt":        {"uint32", "number", "unsigned int", "Integer", "u32", "Integer", "int", "uint", "Long", "UInt32", "int", "int", "integer()", "Word32", "int", "u32", "uint32", "NSUInteger", "Long", "integer", "Int", "UInt32", "uint32", "UInt32", "UInteger", "uint32", "uint32", "long", "Long", "uint32", "BIGINT", "uint", "UInt32", "uint32", "xs:unsignedInt", "xs:unsignedInt", "xs:unsignedInt"},
+	"unsignedLong":       {"uint64", "number", "unsigned int", "Long", "u64", "Bignum", "int", "ulong", "BigInteger", "UInt64", "int", "int", "integer()", "Word64", "int64", "u64", "uint64", "NSUInteger", "BigInteger", "integer", "Int", "UInt64", "uint64", "UInt64", "ULong", "uint64", "uint64", "long", "Long", "uint64", "NUMERIC", "ulong", "UInt64", "uint64", "xs:unsignedLong", "xs:unsignedLong", "xs:unsignedLong"},
+	"unsignedShort":      {"uint16", "number", "unsigned int", "Short", "u16", "Integer", "int", "ushort", "Int", "UInt16", "int", "int", "integer()", "Word16", "int", "u16", "uint16", "NSUInteger", "Integer", "integer", "Int", "UInt16", "uint16", "UInt16", "UShort", "uint16", "int32", "int", "Int", "uint16", "INTEGER", "ushort", "UInt16", "uint16", "xs:unsignedShort", "xs:unsignedShort", "xs:unsignedShort"},
+	"xml:lang":           {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xml:lang", "xml:lang", "xml:lang"},
+	"xml:space":          {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xml:space", "xml:space", "xml:space"},
+	"xml:base":           {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xml:base", "xml:base", "xml:base"},
+	"xml:id":             {"string", "string", "char", "String", "String", "String", "str", "string", "String", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "string", "string", "string", "String", "string", "TEXT", "string", "Text", "string", "xml:id", "xml:id", "xml:id"},
+	"yearMonthDuration":  {"string", "string", "char", "String", "String", "String", "str", "string", "Duration", "String", "string", "String", "String.t()", "Text", "string", "[]const u8", "string", "NSString *", "String", "string", "Str", "String", "string", "String", "String", "duration", "string", "string", "String", "duration", "INTERVAL", "string", "Text", "string", "xs:yearMonthDuration", "xs:yearMonthDuration", "xs:yearMonthDuration"},
+}
+
+// goBigNumberTypes defines the arbitrary-precision types in Go which the
+// unbounded integers and decimals of XSD map to with the big numbers.
+var goBigNumberTypes = map[string]string{
+	"decimal":            "XsdDecimal",
+	"integer":            "XsdInteger",
+	"negativeInteger":    "XsdInteger",
+	"nonNegativeInteger": "XsdInteger",
+	"nonPositiveInteger": "XsdInteger",
+	"positiveInteger":    "XsdInteger",
+}
+
+// SupportLang defines the languages supported by the generators.
+var SupportLang = map[string]bool{
+	"Go":          true,
+	"C":           true,
+	"Java":        true,
+	"Rust":        true,
+	"TypeScript":  true,
+	"Ruby":        true,
+	"Python":      true,
+	"CSharp":      true,
+	"Kotlin":      true,
+	"Swift":       true,
+	"PHP":         true,
+	"Dart":        true,
+	"Elixir":      true,
+	"Haskell":     true,
+	"OCaml":       true,
+	"Zig":         true,
+	"FSharp":      true,
+	"ObjectiveC":  true,
+	"Groovy":      true,
+	"Lua":         true,
+	"Perl":        true,
+	"Crystal":     true,
+	"Nim":         true,
+	"Julia":       true,
+	"VisualBasic": true,
+	"JSONSchema":  true,
+	"Protobuf":    true,
+	"Avro":        true,
+	"GraphQL":     true,
+	"OpenAPI":     true,
+	"SQL":         true,
+	"FlatBuffers": true,
+	"CapnProto":   true,
+	"CUE":         true,
+	"Markdown":    true,
+	"DOT":         true,
+	"XML":         true,
+}
+
+// buildInTypeLang defines the column index of the languages in
+// BuildInTypes.
+var buildInTypeLang = map[string]int{
+	"Go":          0,
+	"TypeScript":  1,
+	"C":           2,
+	"Java":        3,
+	"Rust":        4,
+	"Ruby":        5,
+	"Python":      6,
+	"CSharp":      7,
+	"Kotlin":      8,
+	"Swift":       9,
+	"PHP":         10,
+	"Dart":        11,
+	"Elixir":      12,
+	"Haskell":     13,
+	"OCaml":       14,
+	"Zig":         15,
+	"FSharp":      16,
+	"ObjectiveC":  17,
+	"Groovy":      18,
+	"Lua":         19,
+	"Perl":        20,
+	"Crystal":     21,
+	"Nim":         22,
+	"Julia":       23,
+	"VisualBasic": 24,
+	"JSONSchema":  25,
+	"Protobuf":    26,
+	"Avro":        27,
+	"GraphQL":     28,
+	"OpenAPI":     29,
+	"SQL":         30,
+	"FlatBuffers": 31,
+	"CapnProto":   32,
+	"CUE":         33,
+	"Markdown":    34,
+	"DOT":         35,
+	"XML":         36,
+}
+
+// buildInTypeSets defines the types which will be used as is by the language
+// generators.
+var buildInTypeSets = map[string]map[string]bool{
+	"Go":          goBuildinType,
+	"TypeScript":  typeScriptBuildInType,
+	"C":           cBuildInType,
+	"Java":        javaBuildInType,
+	"Rust":        rustBuildinType,
+	"Ruby":        rubyBuildinType,
+	"Python":      pythonBuildInType,
+	"CSharp":      csharpBuildInType,
+	"Kotlin":      kotlinBuildInType,
+	"Swift":       swiftBuildInType,
+	"PHP":         phpBuildInType,
+	"Dart":        dartBuildInType,
+	"Elixir":      elixirBuildInType,
+	"Haskell":     haskellBuildInType,
+	"OCaml":       ocamlBuildInType,
+	"Zig":         zigBuildInType,
+	"FSharp":      fsharpBuildInType,
+	"ObjectiveC":  objcBuildInType,
+	"Groovy":      groovyBuildInType,
+	"Lua":         luaBuildInType,
+	"Perl":        perlBuildInType,
+	"Crystal":     crystalBuildInType,
+	"Nim":         nimBuildInType,
+	"Julia":       juliaBuildInType,
+	"VisualBasic": visualBasicBuildInType,
+	"JSONSchema":  jsonSchemaBuildInType,
+	"Protobuf":    protobufBuildInType,
+	"Avro":        avroBuildInType,
+	"GraphQL":     graphqlBuildInType,
+	"OpenAPI":     jsonSchemaBuildInType,
+	"SQL":         sqlBuildInType,
+	"FlatBuffers": flatBuffersBuildInType,
+	"CapnProto":   capnProtoBuildInType,
+	"CUE":         cueBuildInType,
+	"Markdown":    markdownBuildInType,
+	"DOT":         dotBuildInType,
+	"XML":         xmlBuildInType,
+}
+
+// langTypeNames defines the functions convert the names of the declarations
+// to the type names in each language.
+var langTypeNames = map[string]func(name string) string{
+	"Go":          genGoFieldName,
+	"TypeScript":  genTypeScriptFieldName,
+	"C":           genCFieldName,
+	"Java":        genJavaFieldName,
+	"Rust":        genRustStructName,
+	"Ruby":        genRubyFieldName,
+	"Python":      genPythonFieldName,
+	"CSharp":      genCSharpFieldName,
+	"Kotlin":      genKotlinFieldName,
+	"Swift":       genSwiftFieldName,
+	"PHP":         genPHPFieldName,
+	"Dart":        genDartFieldName,
+	"Elixir":      genElixirFieldName,
+	"Haskell":     genHaskellFieldName,
+	"OCaml":       genOCamlFieldName,
+	"Zig":         genZigFieldName,
+	"FSharp":      genFSharpFieldName,
+	"ObjectiveC":  genObjectiveCFieldName,
+	"Groovy":      genGroovyFieldName,
+	"Lua":         genLuaFieldName,
+	"Perl":        genPerlFieldName,
+	"Crystal":     genCrystalFieldName,
+	"Nim":         genNimFieldName,
+	"Julia":       genJuliaFieldName,
+	"VisualBasic": genVisualBasicFieldName,
+	"JSONSchema":  genJSONSchemaFieldName,
+	"Protobuf":    genProtobufFieldName,
+	"Avro":        genAvroFieldName,
+	"GraphQL":     genGraphQLFieldName,
+	"OpenAPI":     genJSONSchemaFieldName,
+	"SQL":         genSQLFieldName,
+	"FlatBuffers": genFlatBuffersFieldName,
+	"CapnProto":   genCapnProtoFieldName,
+	"CUE":         genCUEFieldName,
+	"Markdown":    genMarkdownFieldName,
+	"DOT":         genDOTFieldName,
+	"XML":         genXMLFieldName,
 }
 
 func getBuildInTypeByLang(value, lang string) (buildType string, ok bool) {
-	var supportLang = map[string]int{
-		"Go":         0,
-		"TypeScript": 1,
-		"C":          2,
-		"Java":       3,
-		"Rust":       4,
-		"Ruby":       5,
-	}
 	var buildInTypes []string
 	if buildInTypes, ok = BuildInTypes[value]; !ok {
 		return
 	}
-	buildType = buildInTypes[supportLang[lang]]
+	buildType = buildInTypes[buildInTypeLang[lang]]
+	return
+}
+
+// isBuildInType reports whether the type by given name is a built-in type of
+// the language.
+func isBuildInType(name, lang string) bool {
+	idx, ok := buildInTypeLang[lang]
+	if !ok {
+		return false
+	}
+	if lang == "Go" && (name == "XsdInteger" || name == "XsdDecimal") {
+		return true
+	}
+	for _, buildInTypes := range BuildInTypes {
+		if idx < len(buildInTypes) && buildInTypes[idx] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBuildInTypes overrides the BuildInTypes by given JSON file, the file
+// maps the XSD data types to the types of each language, for example:
+//
+//	{
+//	    "decimal": { "Go": "float64", "Java": "java.math.BigDecimal" },
+//	    "date": { "TypeScript": "Date" }
+//	}
+//
+// Languages not specified keep the default types, and the types which are
+// not defined in XSD are added with the default types of anyType. The
+// overridden types are used as is in the generated code, use the type mapping
+// for the types which require imports.
+func LoadBuildInTypes(path string) (err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+	overrides := map[string]map[string]string{}
+	if err = json.Unmarshal(data, &overrides); err != nil {
+		return
+	}
+	for value, types := range overrides {
+		buildInTypes, ok := BuildInTypes[value]
+		if !ok {
+			buildInTypes = BuildInTypes["anyType"]
+		}
+		buildInTypes = append([]string{}, buildInTypes...)
+		for lang, buildType := range types {
+			idx, ok := buildInTypeLang[lang]
+			if !ok {
+				err = fmt.Errorf("unsupport language %s for type %s in %s", lang, value, path)
+				return
+			}
+			buildInTypes[idx] = buildType
+			buildInTypeSets[lang][buildType] = true
+		}
+		BuildInTypes[value] = buildInTypes
+	}
 	return
 }
 
@@ -161,6 +475,38 @@ func getBasefromSimpleType(name string, XSDSchema []interface{}) string {
 	return name
 }
 
+// getRestrictionFromSimpleType returns the facets of the named simple type in
+// the given proto tree, an empty restriction will be returned if not found.
+func getRestrictionFromSimpleType(name string, XSDSchema []interface{}) Restriction {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*SimpleType); ok && !v.List && !v.Union && v.Name == name {
+			return v.Restriction
+		}
+	}
+	return Restriction{}
+}
+
+// isEnumSimpleType reports whether the named simple type in the given proto
+// tree restricts the values by the enumeration, the references to it keep the
+// name of the type for the generated types with the typed constants.
+func isEnumSimpleType(name string, XSDSchema []interface{}) bool {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*SimpleType); ok && !v.List && !v.Union && v.Name == name {
+			return len(v.Restriction.Enum) > 0
+		}
+	}
+	return false
+}
+
+// valueConstraint returns the value of the element or attribute when it's
+// absent by given default and fixed values, the fixed value takes precedence.
+func valueConstraint(defaultValue, fixed string) (string, bool) {
+	if fixed != "" {
+		return fixed, true
+	}
+	return defaultValue, defaultValue != ""
+}
+
 func getNSPrefix(str string) (ns string) {
 	split := strings.Split(str, ":")
 	if len(split) == 2 {
@@ -228,29 +574,38 @@ func isValidURL(toTest string) bool {
 	return true
 }
 
-func fetchSchema(URL string) ([]byte, error) {
-	var body []byte
-	var client http.Client
-	var err error
-	resp, err := client.Get(URL)
-	if err != nil {
-		return body, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		body, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return body, err
-		}
-	}
-	return body, err
+// commentPrefix defines the prefix of the line comments in the languages
+// which don't use "//".
+var commentPrefix = map[string]string{
+	"Ruby":        "#",
+	"Python":      "#",
+	"Elixir":      "#",
+	"Haskell":     "--",
+	"OCaml":       "(*",
+	"Lua":         "--",
+	"Perl":        "#",
+	"Crystal":     "#",
+	"Nim":         "#",
+	"Julia":       "#",
+	"VisualBasic": "'",
+	"GraphQL":     "#",
+	"SQL":         "--",
+	"CapnProto":   "#",
 }
 
-func genFieldComment(name, doc, prefix string) string {
+func genFieldComment(name, doc, source, location, prefix string) string {
 	docReplacer := strings.NewReplacer("\n", fmt.Sprintf("\r\n%s ", prefix), "\t", "")
+	var comment string
 	if doc == "" {
-		return fmt.Sprintf("\r\n%s %s ...\r\n", prefix, name)
+		comment = fmt.Sprintf("\r\n%s %s ...\r\n", prefix, name)
+	} else {
+		comment = fmt.Sprintf("\r\n%s %s is %s\r\n", prefix, name, docReplacer.Replace(doc))
+	}
+	if location != "" {
+		comment += fmt.Sprintf("%s\r\n%s source: %s\r\n", prefix, prefix, location)
+	}
+	if source != "" {
+		comment += fmt.Sprintf("%s\r\n%s\t%s\r\n", prefix, prefix, strings.Replace(source, "\n", fmt.Sprintf("\r\n%s\t", prefix), -1))
 	}
-	return fmt.Sprintf("\r\n%s %s is %s\r\n", prefix, name, docReplacer.Replace(doc))
+	return comment
 }
@@ -0,0 +1,63 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// VersioningNamespace is the namespace of the vc:minVersion and vc:maxVersion
+// attributes, which include the schema elements conditionally by the version
+// of XSD supported by the processor.
+const VersioningNamespace = "http://www.w3.org/2007/XMLSchema-versioning"
+
+// opaqueElements defines the schema elements of XSD 1.1 which the content is
+// skipped in parsing, the wildcards and the anonymous types in them aren't
+// the content of the enclosing declarations.
+var opaqueElements = map[string]bool{
+	"assert":             true,
+	"assertion":          true,
+	"alternative":        true,
+	"openContent":        true,
+	"defaultOpenContent": true,
+}
+
+// xsdVersion returns the version of XSD supported in parsing.
+func (opt *Options) xsdVersion() float64 {
+	if opt.xsd11 {
+		return 1.1
+	}
+	return 1.0
+}
+
+// excluded reports whether the schema element is excluded by the
+// vc:minVersion and vc:maxVersion attributes, the element is included if the
+// version is at least the minVersion and less than the maxVersion.
+func (opt *Options) excluded(ele xml.StartElement) bool {
+	if opt.payload != nil || ele.Name.Local == "schema" {
+		return false
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Space != VersioningNamespace {
+			continue
+		}
+		version, err := strconv.ParseFloat(attr.Value, 64)
+		if err != nil {
+			continue
+		}
+		if attr.Name.Local == "minVersion" && opt.xsdVersion() < version {
+			return true
+		}
+		if attr.Name.Local == "maxVersion" && opt.xsdVersion() >= version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// isWrapper reports whether the given proto tree node is a global element or
+// attribute, the thin wrapper type for it will be skipped if SkipWrappers is
+// enabled. References to the global elements and attributes are resolved to
+// their types, so the wrapper types are not required by other types.
+func (gen *CodeGenerator) isWrapper(ele interface{}) bool {
+	if !gen.SkipWrappers {
+		return false
+	}
+	switch ele.(type) {
+	case *Element, *Attribute:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAll handles parsing event on the all start elements. The all element
+// allows the elements contained in it to appear in any order within the
+// containing element.
+func (opt *Options) OnAll(ele xml.StartElement, protoTree []interface{}) (err error) {
+	switch v := opt.contentOwner().(type) {
+	case *ComplexType:
+		v.Unordered = true
+	case *Group:
+		v.Unordered = true
+	}
+	return
+}
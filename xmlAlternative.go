@@ -0,0 +1,48 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAlternative handles parsing event on the alternative start elements. The
+// alternative element of XSD 1.1 selects the type of the element by an XPath
+// 2.0 expression in the test attribute, the alternatives with the anonymous
+// types are kept without the type.
+func (opt *Options) OnAlternative(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if !opt.xsd11 || len(opt.openElements) == 0 {
+		return
+	}
+	var alternative Alternative
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "test" {
+			alternative.Test = attr.Value
+		}
+		if attr.Name.Local == "type" {
+			if alternative.Type, err = opt.GetValueType(attr.Value, protoTree); err != nil {
+				return
+			}
+		}
+	}
+	e := opt.openElements[len(opt.openElements)-1]
+	e.Alternatives = append(e.Alternatives, alternative)
+	// the local elements have been copied into the enclosing type or group.
+	var elements []Element
+	if !opt.ComplexType.Empty() {
+		elements = opt.ComplexType.Peek().(*ComplexType).Elements
+	} else if opt.InGroup > 0 && !opt.Group.Empty() {
+		elements = opt.Group.Peek().(*Group).Elements
+	}
+	for i := len(elements) - 1; i >= 0; i-- {
+		if elements[i].Name == e.Name {
+			elements[i].Alternatives = e.Alternatives
+			break
+		}
+	}
+	return
+}
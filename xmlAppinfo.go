@@ -0,0 +1,31 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAppinfo handles parsing event on the appinfo start elements. The appinfo
+// element specifies information to be used by applications within an
+// annotation element, the code generation directives are read from it, and
+// the rest of the content is kept as the payload of the declaration.
+func (opt *Options) OnAppinfo(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.InAppinfo = true
+	opt.payload = newAppinfoPayload()
+	return
+}
+
+// EndAppinfo handles parsing event on the appinfo end elements.
+func (opt *Options) EndAppinfo(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.InAppinfo = false
+	opt.InDirective = ""
+	if opt.payload != nil {
+		opt.captureAppinfo()
+	}
+	return
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAssert handles parsing event on the assert start elements. The assert
+// element of XSD 1.1 constrains the complex type by an XPath 2.0 expression
+// in the test attribute, which is kept in the Assertions of the type.
+func (opt *Options) OnAssert(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if !opt.xsd11 || opt.ComplexType.Peek() == nil {
+		return
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "test" {
+			opt.ComplexType.Peek().(*ComplexType).Assertions = append(opt.ComplexType.Peek().(*ComplexType).Assertions, attr.Value)
+		}
+	}
+	return
+}
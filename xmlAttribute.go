@@ -16,9 +16,13 @@ func (opt *Options) OnAttribute(ele xml.StartElement, protoTree []interface{}) (
 	attribute := Attribute{
 		Optional: true,
 	}
+	qualified := opt.attrQualified
 	for _, attr := range ele.Attr {
+		if attr.Name.Local == "form" {
+			qualified = attr.Value == "qualified"
+		}
 		if attr.Name.Local == "ref" {
-			attribute.Name = attr.Value
+			attribute.Name, attribute.Namespace = attr.Value, opt.parseNS(attr.Value)
 			attribute.Type, err = opt.GetValueType(attr.Value, protoTree)
 			if err != nil {
 				return
@@ -32,6 +36,16 @@ func (opt *Options) OnAttribute(ele xml.StartElement, protoTree []interface{}) (
 			if err != nil {
 				return
 			}
+			attribute.Restriction = getRestrictionFromSimpleType(trimNSPrefix(attr.Value), protoTree)
+			if isEnumSimpleType(trimNSPrefix(attr.Value), protoTree) {
+				attribute.Type = trimNSPrefix(attr.Value)
+			}
+		}
+		if attr.Name.Local == "default" {
+			attribute.Default = attr.Value
+		}
+		if attr.Name.Local == "fixed" {
+			attribute.Fixed = attr.Value
 		}
 		if attr.Name.Local == "use" {
 			if attr.Value == "required" {
@@ -39,8 +53,19 @@ func (opt *Options) OnAttribute(ele xml.StartElement, protoTree []interface{}) (
 			}
 		}
 	}
+	if qualified && attribute.Namespace == "" && (opt.ComplexType.Len() > 0 || opt.AttributeGroup.Len() > 0) {
+		// the local attributes are in the target namespace if qualified.
+		attribute.Namespace = opt.TargetNamespace
+	}
+	if attribute.Namespace != "" {
+		attribute.Prefix = opt.declaredPrefix(attribute.Namespace)
+	}
 	if opt.ComplexType.Len() > 0 {
 		opt.ComplexType.Peek().(*ComplexType).Attributes = append(opt.ComplexType.Peek().(*ComplexType).Attributes, attribute)
+		if attribute.Type == "" {
+			// the attribute may be declared with an anonymous simple type.
+			opt.Attribute.Push(&attribute)
+		}
 		return
 	}
 
@@ -59,6 +84,9 @@ func (opt *Options) EndAttribute(ele xml.EndElement, protoTree []interface{}) (e
 	}
 	if opt.ComplexType.Len() == 0 {
 		opt.ProtoTree = append(opt.ProtoTree, opt.Attribute.Pop())
+		return
 	}
+	attributes := opt.ComplexType.Peek().(*ComplexType).Attributes
+	attributes[len(attributes)-1] = *opt.Attribute.Pop().(*Attribute)
 	return
 }
@@ -18,6 +18,10 @@ func (opt *Options) OnCharData(ele string, protoTree []interface{}) (err error)
 		return
 	}
 	ele = strings.TrimSpace(ele)
+	if opt.InDirective != "" {
+		opt.onDirectiveCharData(ele)
+		return
+	}
 	if opt.InAttributeGroup {
 		if opt.AttributeGroup.Peek() != nil {
 			opt.AttributeGroup.Peek().(*AttributeGroup).Doc = ele
@@ -0,0 +1,120 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// choiceScope is the choice being parsed in the complex type or group, the
+// depth counts the choices nested in it.
+type choiceScope struct {
+	owner  interface{}
+	choice Choice
+	depth  int
+}
+
+// OnChoice handles parsing event on the choice start elements. The choice
+// element allows only one of the elements contained in it to be present
+// within the containing element.
+func (opt *Options) OnChoice(ele xml.StartElement, protoTree []interface{}) (err error) {
+	owner := opt.contentOwner()
+	if owner == nil {
+		return
+	}
+	if opt.Choice.Len() > 0 && opt.Choice.Peek().(*choiceScope).owner == owner {
+		opt.Choice.Peek().(*choiceScope).depth++
+		return
+	}
+	choice := Choice{}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "minOccurs" {
+			if attr.Value == "0" {
+				choice.Optional = true
+			}
+		}
+		if attr.Name.Local == "maxOccurs" {
+			var maxOccurs int
+			if maxOccurs, err = strconv.Atoi(attr.Value); attr.Value != "unbounded" && err != nil {
+				return
+			}
+			if attr.Value == "unbounded" || maxOccurs > 1 {
+				choice.Plural, err = true, nil
+			}
+		}
+	}
+	switch v := owner.(type) {
+	case *ComplexType:
+		choice.Name = choiceName(len(v.Choices))
+		v.Choices = append(v.Choices, choice)
+	case *Group:
+		choice.Name = choiceName(len(v.Choices))
+		v.Choices = append(v.Choices, choice)
+	}
+	opt.Choice.Push(&choiceScope{owner: owner, choice: choice, depth: 1})
+	return
+}
+
+// EndChoice handles parsing event on the choice end elements.
+func (opt *Options) EndChoice(ele xml.EndElement, protoTree []interface{}) (err error) {
+	if opt.Choice.Len() > 0 && opt.Choice.Peek().(*choiceScope).owner == opt.contentOwner() {
+		if opt.Choice.Peek().(*choiceScope).depth--; opt.Choice.Peek().(*choiceScope).depth == 0 {
+			opt.Choice.Pop()
+		}
+	}
+	return
+}
+
+// contentOwner returns the complex type or group which contains the elements
+// being parsed.
+func (opt *Options) contentOwner() interface{} {
+	if opt.ComplexType.Len() > 0 {
+		return opt.ComplexType.Peek()
+	}
+	if opt.InGroup > 0 && opt.Group.Len() > 0 {
+		return opt.Group.Peek()
+	}
+	return nil
+}
+
+// markChoice marks the element in the complex type or group as the
+// alternative of the choice being parsed in it. The alternatives are
+// optional, and repeatable if the choice is.
+func (opt *Options) markChoice(e *Element, owner interface{}) {
+	if opt.Choice.Len() == 0 || opt.Choice.Peek().(*choiceScope).owner != owner {
+		return
+	}
+	choice := opt.Choice.Peek().(*choiceScope).choice
+	e.Choice, e.Optional = choice.Name, true
+	if choice.Plural {
+		e.Plural = true
+	}
+}
+
+// choiceName returns the name of the choice by given number of the choices
+// before it in the complex type or group.
+func choiceName(index int) string {
+	if index == 0 {
+		return "Choice"
+	}
+	return fmt.Sprintf("Choice%d", index+1)
+}
+
+// choiceAlternatives returns the elements which are the alternatives of the
+// choice.
+func choiceAlternatives(choice Choice, elements []Element) (alternatives []Element) {
+	for _, element := range elements {
+		if element.Choice == choice.Name {
+			alternatives = append(alternatives, element)
+		}
+	}
+	return
+}
@@ -0,0 +1,26 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnComplexContent handles parsing event on the complexContent start
+// elements. The mixed attribute of the complexContent element overrides the
+// one of the complex type being parsed.
+func (opt *Options) OnComplexContent(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if opt.ComplexType.Len() == 0 {
+		return
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "mixed" {
+			opt.ComplexType.Peek().(*ComplexType).Mixed = attr.Value == "true" || attr.Value == "1"
+		}
+	}
+	return
+}
@@ -15,31 +15,71 @@ import "encoding/xml"
 func (opt *Options) OnComplexType(ele xml.StartElement, protoTree []interface{}) (err error) {
 	if opt.ComplexType.Len() > 0 {
 		e := opt.Element.Pop().(*Element)
-		opt.ComplexType.Push(&ComplexType{
-			Name: e.Name,
-		})
+		parent := opt.ComplexType.Peek().(*ComplexType)
+		c := ComplexType{Name: anonymousTypeName(parent.Name, e.Name), Anonymous: true}
+		parent.Elements = setElementType(parent.Elements, e.Name, c.Name)
+		opt.ComplexType.Push(&c)
 	}
 
 	if opt.ComplexType.Len() == 0 {
 		c := ComplexType{}
-		opt.CurrentEle = opt.InElement
+		if opt.InGroup == 0 {
+			// the group in parsing is closed by the current element.
+			opt.CurrentEle = opt.InElement
+		}
 		for _, attr := range ele.Attr {
 			if attr.Name.Local == "name" {
 				c.Name = attr.Value
 			}
+			if attr.Name.Local == "abstract" {
+				c.Abstract = attr.Value == "true" || attr.Value == "1"
+			}
 		}
 		if c.Name == "" {
 			e := opt.Element.Pop().(*Element)
 			c.Name = e.Name
+			if opt.InGroup > 0 && opt.Group.Len() > 0 {
+				group := opt.Group.Peek().(*Group)
+				c.Name, c.Anonymous = anonymousTypeName(group.Name, e.Name), true
+				group.Elements = setElementType(group.Elements, e.Name, c.Name)
+			}
 		}
 		opt.ComplexType.Push(&c)
 	}
+
+	opt.ComplexType.Peek().(*ComplexType).OpenContent = opt.openContent
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "mixed" {
+			opt.ComplexType.Peek().(*ComplexType).Mixed = attr.Value == "true" || attr.Value == "1"
+		}
+	}
 	return
 }
 
 // EndComplexType handles parsing event on the complex end elements.
 func (opt *Options) EndComplexType(ele xml.EndElement, protoTree []interface{}) (err error) {
 	opt.ProtoTree = append(opt.ProtoTree, opt.ComplexType.Pop())
-	opt.CurrentEle = ""
+	if opt.InGroup == 0 {
+		opt.CurrentEle = ""
+	}
 	return
 }
+
+// anonymousTypeName returns the name of the anonymous complex type declared in
+// the local element by given name of the enclosing type and the element, such
+// as order_Item for the item element of the order.
+func anonymousTypeName(parent, element string) string {
+	return parent + "_" + MakeFirstUpperCase(element)
+}
+
+// setElementType sets the type of the last element with the given name in
+// the elements to the anonymous type declared in it.
+func setElementType(elements []Element, name, typeName string) []Element {
+	for i := len(elements) - 1; i >= 0; i-- {
+		if elements[i].Name == name {
+			elements[i].Type = typeName
+			break
+		}
+	}
+	return elements
+}
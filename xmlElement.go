@@ -15,10 +15,14 @@ import (
 
 // OnElement handles parsing event on the element start elements.
 func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (err error) {
-	e := Element{}
+	e := Element{MinOccurs: 1, MaxOccurs: 1}
+	qualified := opt.elemQualified
 	for _, attr := range ele.Attr {
+		if attr.Name.Local == "form" {
+			qualified = attr.Value == "qualified"
+		}
 		if attr.Name.Local == "ref" {
-			e.Name = attr.Value
+			e.Name, e.Namespace = attr.Value, opt.parseNS(attr.Value)
 			e.Type, err = opt.GetValueType(attr.Value, protoTree)
 			if err != nil {
 				return
@@ -33,14 +37,42 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 			if err != nil {
 				return
 			}
+			e.Restriction = getRestrictionFromSimpleType(trimNSPrefix(attr.Value), protoTree)
+			if isEnumSimpleType(trimNSPrefix(attr.Value), protoTree) {
+				e.Type = trimNSPrefix(attr.Value)
+			}
+		}
+		if attr.Name.Local == "default" {
+			e.Default = attr.Value
+		}
+		if attr.Name.Local == "fixed" {
+			e.Fixed = attr.Value
+		}
+		if attr.Name.Local == "abstract" {
+			e.Abstract = attr.Value == "true" || attr.Value == "1"
+		}
+		if attr.Name.Local == "nillable" {
+			e.Nillable = attr.Value == "true" || attr.Value == "1"
+		}
+		if attr.Name.Local == "substitutionGroup" {
+			e.SubstitutionGroup = trimNSPrefix(attr.Value)
+		}
+		if attr.Name.Local == "minOccurs" {
+			if e.MinOccurs, err = strconv.Atoi(attr.Value); err != nil {
+				return
+			}
+			if e.MinOccurs == 0 {
+				e.Optional = true
+			}
 		}
 		if attr.Name.Local == "maxOccurs" {
-			var maxOccurs int
-			if maxOccurs, err = strconv.Atoi(attr.Value); attr.Value != "unbounded" && err != nil {
+			if attr.Value == "unbounded" {
+				e.MaxOccurs = Unbounded
+			} else if e.MaxOccurs, err = strconv.Atoi(attr.Value); err != nil {
 				return
 			}
-			if attr.Value == "unbounded" || maxOccurs > 1 {
-				e.Plural, err = true, nil
+			if e.MaxOccurs == Unbounded || e.MaxOccurs > 1 {
+				e.Plural = true
 			}
 		}
 		if attr.Name.Local == "unbounded" {
@@ -50,6 +82,14 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 		}
 	}
 
+	if qualified && e.Namespace == "" && (opt.ComplexType.Len() > 0 || opt.InGroup > 0) {
+		// the local elements are in the target namespace if qualified.
+		e.Namespace = opt.TargetNamespace
+	}
+	if e.Namespace != "" {
+		e.Prefix = opt.declaredPrefix(e.Namespace)
+	}
+	opt.openElements = append(opt.openElements, &e)
 	if e.Type == "" {
 		e.Type, err = opt.GetValueType(e.Name, protoTree)
 		if err != nil {
@@ -58,6 +98,7 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 		opt.Element.Push(&e)
 	}
 	if opt.ComplexType.Len() > 0 {
+		opt.markChoice(&e, opt.ComplexType.Peek())
 		if !inElements(&e, opt.ComplexType.Peek().(*ComplexType).Elements) {
 			opt.ComplexType.Peek().(*ComplexType).Elements = append(opt.ComplexType.Peek().(*ComplexType).Elements, e)
 		}
@@ -66,6 +107,7 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 
 	if opt.InGroup > 0 {
 		if opt.Group.Len() > 0 {
+			opt.markChoice(&e, opt.Group.Peek())
 			opt.Group.Peek().(*Group).Elements = append(opt.Group.Peek().(*Group).Elements, e)
 		}
 		return
@@ -77,9 +119,20 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 
 // EndElement handles parsing event on the element end elements.
 func (opt *Options) EndElement(ele xml.EndElement, protoTree []interface{}) (err error) {
-	if opt.Element.Len() > 0 && opt.ComplexType.Len() == 0 {
+	if opt.Element.Len() > 0 && opt.ComplexType.Len() == 0 && opt.InGroup == 0 {
 		opt.ProtoTree = append(opt.ProtoTree, opt.Element.Pop())
 	}
+	if len(opt.openElements) == 0 {
+		return
+	}
+	// the element without type which isn't consumed by an anonymous complex
+	// type, such as the element declared with an anonymous simple type, should
+	// not be left in the stack.
+	e := opt.openElements[len(opt.openElements)-1]
+	opt.openElements = opt.openElements[:len(opt.openElements)-1]
+	for opt.Element.Len() > 0 && opt.Element.Peek() == e {
+		opt.Element.Pop()
+	}
 	return
 }
 
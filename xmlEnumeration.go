@@ -14,8 +14,8 @@ import "encoding/xml"
 func (opt *Options) OnEnumeration(ele xml.StartElement, protoTree []interface{}) (err error) {
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "value" {
-			if opt.SimpleType.Peek() != nil {
-				opt.SimpleType.Peek().(*SimpleType).Restriction.Enum = append(opt.SimpleType.Peek().(*SimpleType).Restriction.Enum, attr.Value)
+			if opt.facetRestriction() != nil {
+				opt.facetRestriction().Enum = append(opt.facetRestriction().Enum, attr.Value)
 			}
 		}
 	}
@@ -26,16 +26,14 @@ func (opt *Options) OnEnumeration(ele xml.StartElement, protoTree []interface{})
 // Enumeration defines a list of acceptable values.
 func (opt *Options) EndEnumeration(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.Attribute.Len() > 0 && opt.SimpleType.Peek() != nil {
-		if opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.SimpleType.Peek().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Attribute.Peek().(*Attribute).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Peek().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
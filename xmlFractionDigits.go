@@ -8,7 +8,22 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// OnFractionDigits handles parsing event on the fractionDigits start elements.
+func (opt *Options) OnFractionDigits(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" && opt.facetRestriction() != nil {
+			if opt.facetRestriction().Precision, err = strconv.Atoi(attr.Value); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
 
 // EndFractionDigits handles parsing event on the fractionDigits end elements.
 // Enumeration Defines a list of acceptable values. FractionDigits specifies
@@ -16,10 +31,9 @@ import "encoding/xml"
 // than zero.
 func (opt *Options) EndFractionDigits(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
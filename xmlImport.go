@@ -8,12 +8,53 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"fmt"
+)
 
-// OnImport handles parsing event on the import start elements. The list
-// element defines a simple type element as a list of values of a specified
-// data type.
+// OnImport handles parsing event on the import start elements. The import
+// element references the schema of the other namespace at the location
+// relative to the importing schema, which is parsed once for generating the
+// code and resolving the types of the namespace, and the circular import is
+// skipped.
 func (opt *Options) OnImport(ele xml.StartElement, protoTree []interface{}) (err error) {
 	opt.prepareNSSchemaLocationMap(ele)
+	if opt.Extract {
+		return
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "schemaLocation" {
+			location := resolveLocation(opt.baseURI(ele), attr.Value)
+			if opt.isCircular(location) {
+				continue
+			}
+			if err = opt.importSchema(location); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// importSchema parses the imported schema by given location if it hasn't been
+// parsed, the schema couldn't be resolved is skipped.
+func (opt *Options) importSchema(location string) (err error) {
+	var path string
+	if path, err = opt.resolveSchema(location); err != nil || path == "" {
+		return
+	}
+	if _, ok := opt.ParseFileMap[path]; ok {
+		return
+	}
+	var baseURI string
+	if isValidURL(location) {
+		baseURI = location
+	}
+	parser := opt.subParser(path, baseURI, false)
+	if err = parser.Parse(); err != nil {
+		return fmt.Errorf("import %s: %v", location, err)
+	}
+	opt.Cycles = append(opt.Cycles, parser.Cycles...)
 	return
 }
@@ -8,19 +8,81 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"fmt"
+)
 
-// OnInclude handles parsing event on the include start elements. The list
-// element defines a simple type element as a list of values of a specified
-// data type.
+// OnInclude handles parsing event on the include start elements. The include
+// element adds the declarations of the schema at the location relative to
+// the including schema into the same namespace, every schema is included
+// once even if it's included by the included schemas again, and the circular
+// include is skipped. The schema without the targetNamespace is included once
+// for each namespace including it.
 func (opt *Options) OnInclude(ele xml.StartElement, protoTree []interface{}) (err error) {
-	for _, ele := range ele.Attr {
-		if ele.Name.Local == "schemaLocation" {
-			if _, ok := opt.IncludeMap[ele.Value]; ok {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "schemaLocation" {
+			location := resolveLocation(opt.baseURI(ele), attr.Value)
+			if opt.isCircular(location) {
 				continue
 			}
-			opt.IncludeMap[ele.Value] = true
+			key := location
+			if opt.chameleons[location] {
+				key = chameleonKey(location, opt.TargetNamespace)
+			}
+			if _, ok := opt.IncludeMap[key]; ok {
+				continue
+			}
+			opt.IncludeMap[key] = true
+			if err = opt.includeSchema(location); err != nil {
+				return
+			}
 		}
 	}
 	return
 }
+
+// includeSchema parses the included schema by given location, and merges the
+// declarations of it and the schemas included by it into the declarations
+// for resolving the types. The schema which has been parsed is not parsed
+// again except the chameleon schema, which is parsed again in the target
+// namespace of the including schema without generating the code, and the
+// schema couldn't be resolved is skipped.
+func (opt *Options) includeSchema(location string) (err error) {
+	if protoTree, ok := opt.ParseFileMap[location]; ok && !opt.chameleons[location] {
+		opt.included = append(opt.included, protoTree...)
+		return
+	}
+	var path string
+	if path, err = opt.resolveSchema(location); err != nil || path == "" {
+		return
+	}
+	protoTree, parsed := opt.ParseFileMap[path]
+	if parsed && !opt.chameleons[location] {
+		opt.included = append(opt.included, protoTree...)
+		return
+	}
+	var baseURI string
+	if isValidURL(location) {
+		baseURI = location
+	}
+	parser := opt.subParser(path, baseURI, opt.Extract || parsed)
+	parser.chameleon = opt.TargetNamespace
+	if err = parser.Parse(); err != nil {
+		return fmt.Errorf("include %s: %v", location, err)
+	}
+	if parser.chameleon != "" {
+		opt.chameleons[location] = true
+		opt.IncludeMap[chameleonKey(location, opt.TargetNamespace)] = true
+	}
+	opt.included = append(opt.included, parser.ProtoTree...)
+	opt.included = append(opt.included, parser.included...)
+	opt.Cycles = append(opt.Cycles, parser.Cycles...)
+	return
+}
+
+// chameleonKey returns the key of the chameleon schema included in the
+// namespace by given location and namespace.
+func chameleonKey(location, ns string) string {
+	return location + "#" + ns
+}
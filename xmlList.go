@@ -23,6 +23,7 @@ func (opt *Options) OnList(ele xml.StartElement, protoTree []interface{}) (err e
 			if opt.SimpleType.Peek().(*SimpleType).Base, err = opt.GetValueType(attr.Value, protoTree); err != nil {
 				return
 			}
+			opt.SimpleType.Peek().(*SimpleType).Restriction.Base = trimNSPrefix(attr.Value)
 		}
 	}
 	return
@@ -8,17 +8,33 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// OnMaxInclusive handles parsing event on the maxInclusive start elements.
+func (opt *Options) OnMaxInclusive(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" && opt.facetRestriction() != nil {
+			// the non-numeric bounds of the date and time types are ignored.
+			if value, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+				restriction := opt.facetRestriction()
+				restriction.Max, restriction.HasMax, restriction.MaxExclusive = value, true, false
+			}
+		}
+	}
+	return
+}
 
 // EndMaxInclusive handles parsing event on the maxInclusive end elements.
 // MaxInclusive specifies the upper bounds for numeric values (the value must
 // be less than or equal to this value).
 func (opt *Options) EndMaxInclusive(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
@@ -8,17 +8,33 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// OnMaxLength handles parsing event on the maxLength start elements.
+func (opt *Options) OnMaxLength(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" {
+			if opt.facetRestriction() != nil {
+				if opt.facetRestriction().MaxLength, err = strconv.Atoi(attr.Value); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
 
 // EndMaxLength handles parsing event on the maxLength end elements. MaxLength
 // specifies the maximum number of characters or list items allowed. Must be
 // equal to or greater than zero.
 func (opt *Options) EndMaxLength(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
@@ -8,17 +8,33 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// OnMinExclusive handles parsing event on the minExclusive start elements.
+func (opt *Options) OnMinExclusive(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" && opt.facetRestriction() != nil {
+			// the non-numeric bounds of the date and time types are ignored.
+			if value, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+				restriction := opt.facetRestriction()
+				restriction.Min, restriction.HasMin, restriction.MinExclusive = value, true, true
+			}
+		}
+	}
+	return
+}
 
 // EndMinExclusive handles parsing event on the minExclusive end elements.
 // MinExclusive specifies the lower bounds for numeric values (the value must
 // be greater than this value).
 func (opt *Options) EndMinExclusive(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
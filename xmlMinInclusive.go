@@ -8,17 +8,33 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// OnMinInclusive handles parsing event on the minInclusive start elements.
+func (opt *Options) OnMinInclusive(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" && opt.facetRestriction() != nil {
+			// the non-numeric bounds of the date and time types are ignored.
+			if value, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+				restriction := opt.facetRestriction()
+				restriction.Min, restriction.HasMin, restriction.MinExclusive = value, true, false
+			}
+		}
+	}
+	return
+}
 
 // EndMinInclusive handles parsing event on the minInclusive end elements.
 // MinInclusive specifies the lower bounds for numeric values (the value must
 // be greater than or equal to this value).
 func (opt *Options) EndMinInclusive(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
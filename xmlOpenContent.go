@@ -0,0 +1,48 @@
+// Copyright 2020 - 2021 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnOpenContent handles parsing event on the openContent start elements. The
+// openContent element of XSD 1.1 allows the elements matched by its wildcard
+// to appear in the content of the complex type, the mode of it is kept in
+// the OpenContent of the type.
+func (opt *Options) OnOpenContent(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if !opt.xsd11 || opt.ComplexType.Peek() == nil {
+		return
+	}
+	opt.ComplexType.Peek().(*ComplexType).OpenContent = openContentMode(ele)
+	return
+}
+
+// OnDefaultOpenContent handles parsing event on the defaultOpenContent start
+// elements. The defaultOpenContent element of XSD 1.1 applies the open
+// content to every complex type declared in the schema.
+func (opt *Options) OnDefaultOpenContent(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if opt.xsd11 {
+		opt.openContent = openContentMode(ele)
+	}
+	return
+}
+
+// openContentMode returns the mode of the open content, which is empty if
+// the mode is none.
+func openContentMode(ele xml.StartElement) string {
+	mode := "interleave"
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "mode" {
+			mode = attr.Value
+		}
+	}
+	if mode == "none" {
+		return ""
+	}
+	return mode
+}
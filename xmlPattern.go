@@ -8,23 +8,50 @@
 
 package xgen
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// OnPattern handles parsing event on the pattern start elements.
+func (opt *Options) OnPattern(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" {
+			if restriction := opt.facetRestriction(); restriction != nil {
+				// XSD regular expressions which can't be compiled by Go are
+				// ignored rather than abort the whole parsing process.
+				expr := attr.Value
+				if restriction.Pattern != nil {
+					// multiple patterns in the same restriction are ORed
+					// together.
+					expr = fmt.Sprintf("(?:%s)|(?:%s)", restriction.Pattern.String(), attr.Value)
+				}
+				var pattern *regexp.Regexp
+				if pattern, err = regexp.Compile(expr); err != nil {
+					err = nil
+					continue
+				}
+				restriction.Pattern = pattern
+			}
+		}
+	}
+	return
+}
 
 // EndPattern handles parsing event on the pattern end elements. Pattern
 // defines the exact sequence of characters that are acceptable.
 func (opt *Options) EndPattern(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.Attribute.Len() > 0 && opt.SimpleType.Peek() != nil {
-		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree)
+		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Attribute.Peek().(*Attribute).Restriction), opt.ProtoTree)
 		if err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
-		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
 	return
 }
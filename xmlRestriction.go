@@ -26,6 +26,7 @@ func (opt *Options) OnRestriction(ele xml.StartElement, protoTree []interface{})
 				if err != nil {
 					return
 				}
+				opt.SimpleType.Peek().(*SimpleType).Restriction.Base = trimNSPrefix(attr.Value)
 				if opt.SimpleType.Peek().(*SimpleType).Name == "" {
 					opt.SimpleType.Peek().(*SimpleType).Name = attr.Value
 				}
@@ -37,17 +38,57 @@ func (opt *Options) OnRestriction(ele xml.StartElement, protoTree []interface{})
 
 // EndRestriction handles parsing event on the restriction end elements.
 func (opt *Options) EndRestriction(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.facets = nil
 	if opt.Attribute.Len() > 0 && opt.SimpleType.Peek() != nil {
-		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree)
+		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Attribute.Peek().(*Attribute).Restriction), opt.ProtoTree)
 		if err != nil {
 			return
 		}
-		opt.CurrentEle = ""
 	}
-	if !opt.Element.Empty() {
-		if !opt.ComplexType.Empty() && len(opt.ComplexType.Peek().(*ComplexType).Elements) > 0 {
-			opt.ComplexType.Peek().(*ComplexType).Elements[len(opt.ComplexType.Peek().(*ComplexType).Elements)-1] = *opt.Element.Peek().(*Element)
+	opt.syncElement()
+	return
+}
+
+// syncElement updates the copy of the element in parsing in the enclosing
+// complex type or group, since the type and facets of the element declared
+// with an anonymous simple type are resolved after it has been added.
+func (opt *Options) syncElement() {
+	if opt.Element.Empty() {
+		return
+	}
+	e := opt.Element.Peek().(*Element)
+	var elements []Element
+	if !opt.ComplexType.Empty() {
+		elements = opt.ComplexType.Peek().(*ComplexType).Elements
+	} else if opt.InGroup > 0 && !opt.Group.Empty() {
+		elements = opt.Group.Peek().(*Group).Elements
+	}
+	for i := len(elements) - 1; i >= 0; i-- {
+		if elements[i].Name == e.Name {
+			elements[i] = *e
+			return
 		}
 	}
-	return
+}
+
+// facetRestriction returns the restriction which the facets of current
+// restriction element should be captured into. The anonymous simple type
+// declared in an element or attribute is popped at the end of its first
+// facet, so the remaining facets are captured into the restriction of the
+// element or attribute directly.
+func (opt *Options) facetRestriction() *Restriction {
+	if opt.SimpleType.Peek() != nil {
+		return &opt.SimpleType.Peek().(*SimpleType).Restriction
+	}
+	return opt.facets
+}
+
+// popSimpleType pops the anonymous simple type declared in an element or
+// attribute and returns its base type. The facets of the simple type are kept
+// in the given restriction of the element or attribute.
+func (opt *Options) popSimpleType(restriction *Restriction) string {
+	simpleType := opt.SimpleType.Pop().(*SimpleType)
+	*restriction = simpleType.Restriction
+	opt.facets = restriction
+	return simpleType.Base
 }
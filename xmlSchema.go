@@ -11,8 +11,29 @@ package xgen
 import "encoding/xml"
 
 // OnSchema handles parsing event on the schema start elements. Schema is the
-// root element of every XML Schema.
+// root element of every XML Schema. The included schema without the
+// targetNamespace adopts the target namespace of the including schema, which
+// is known as the chameleon include.
 func (opt *Options) OnSchema(ele xml.StartElement, protoTree []interface{}) (err error) {
 	opt.prepareLocalNameNSMap(ele)
+	opt.BaseURI = opt.baseURI(ele)
+	if opt.chameleon != "" {
+		opt.TargetNamespace = opt.chameleon
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "targetNamespace" {
+			opt.TargetNamespace, opt.chameleon = attr.Value, ""
+		}
+		if attr.Name.Local == "elementFormDefault" {
+			opt.elemQualified = attr.Value == "qualified"
+		}
+		if attr.Name.Local == "attributeFormDefault" {
+			opt.attrQualified = attr.Value == "qualified"
+		}
+		if attr.Name.Space == VersioningNamespace && attr.Name.Local == "minVersion" && attr.Value == "1.1" {
+			// the schema requires XSD 1.1.
+			opt.xsd11 = true
+		}
+	}
 	return
 }
@@ -15,10 +15,18 @@ import "encoding/xml"
 // information about the values of attributes or text-only elements.
 func (opt *Options) OnSimpleType(ele xml.StartElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() == 0 {
-		opt.SimpleType.Push(&SimpleType{})
+		simpleType := SimpleType{Anonymous: true}
+		for _, attr := range ele.Attr {
+			if attr.Name.Local == "name" {
+				simpleType.Anonymous = false
+			}
+		}
+		opt.SimpleType.Push(&simpleType)
 	}
-	if opt.CurrentEle == "attributeGroup" {
-		// return
+	if opt.SimpleType.Peek().(*SimpleType).Anonymous {
+		// the anonymous simple type is popped into the element or attribute
+		// declaring it, the enclosing declaration is still in parsing.
+		return
 	}
 	opt.CurrentEle = opt.InElement
 	for _, attr := range ele.Attr {
@@ -31,13 +39,21 @@ func (opt *Options) OnSimpleType(ele xml.StartElement, protoTree []interface{})
 
 // EndSimpleType handles parsing event on the simpleType end elements.
 func (opt *Options) EndSimpleType(ele xml.EndElement, protoTree []interface{}) (err error) {
+	if opt.SimpleType.Len() > 0 && opt.SimpleType.Peek().(*SimpleType).Anonymous && opt.SimpleType.Peek().(*SimpleType).List && !opt.InUnion {
+		opt.declareAnonymousList()
+		return
+	}
 	if opt.SimpleType.Len() > 0 && opt.Attribute.Len() > 0 {
-		opt.Attribute.Peek().(*Attribute).Type = opt.SimpleType.Pop().(*SimpleType).Base
+		opt.Attribute.Peek().(*Attribute).Type = opt.popSimpleType(&opt.Attribute.Peek().(*Attribute).Restriction)
 		return
 	}
-	if ele.Name.Local == opt.CurrentEle && opt.ComplexType.Len() == 1 {
-		opt.ProtoTree = append(opt.ProtoTree, opt.ComplexType.Pop())
-		opt.CurrentEle = ""
+	if opt.SimpleType.Len() > 0 && opt.SimpleType.Peek().(*SimpleType).Anonymous && !opt.InUnion && opt.Element.Len() > 0 {
+		// the anonymous simple type without any facet.
+		if opt.Element.Peek().(*Element).Type, err = opt.GetValueType(opt.popSimpleType(&opt.Element.Peek().(*Element).Restriction), opt.ProtoTree); err != nil {
+			return
+		}
+		opt.syncElement()
+		return
 	}
 
 	if ele.Name.Local == opt.CurrentEle && !opt.InUnion {
@@ -46,3 +62,37 @@ func (opt *Options) EndSimpleType(ele xml.EndElement, protoTree []interface{}) (
 	}
 	return
 }
+
+// declareAnonymousList declares the anonymous list type of the element or
+// attribute in parsing as the list type named after them, since the list
+// can't be represented by the type of the items.
+func (opt *Options) declareAnonymousList() {
+	simpleType := opt.SimpleType.Pop().(*SimpleType)
+	if opt.Attribute.Len() > 0 {
+		attribute := opt.Attribute.Peek().(*Attribute)
+		simpleType.Name = opt.anonymousListName(attribute.Name)
+		attribute.Type = simpleType.Name
+	} else if opt.Element.Len() > 0 {
+		element := opt.Element.Peek().(*Element)
+		simpleType.Name = opt.anonymousListName(element.Name)
+		element.Type = simpleType.Name
+		opt.syncElement()
+	} else {
+		return
+	}
+	opt.ProtoTree = append(opt.ProtoTree, simpleType)
+}
+
+// anonymousListName returns the name of the anonymous list type by given
+// name of the element or attribute declaring it.
+func (opt *Options) anonymousListName(name string) string {
+	switch {
+	case opt.ComplexType.Len() > 0:
+		return anonymousTypeName(opt.ComplexType.Peek().(*ComplexType).Name, name)
+	case opt.InGroup > 0 && opt.Group.Len() > 0:
+		return anonymousTypeName(opt.Group.Peek().(*Group).Name, name)
+	case opt.AttributeGroup.Len() > 0:
+		return anonymousTypeName(opt.AttributeGroup.Peek().(*AttributeGroup).Name, name)
+	}
+	return anonymousTypeName(name, "list")
+}
@@ -22,15 +22,15 @@ func (opt *Options) OnUnion(ele xml.StartElement, protoTree []interface{}) (err
 		return
 	}
 	opt.SimpleType.Peek().(*SimpleType).Union = true
-	opt.SimpleType.Peek().(*SimpleType).MemberTypes = make(map[string]string)
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "memberTypes" {
-			memberTypes := strings.Split(attr.Value, " ")
+			memberTypes := strings.Fields(attr.Value)
 			for _, memberType := range memberTypes {
-				opt.SimpleType.Peek().(*SimpleType).MemberTypes[trimNSPrefix(memberType)], err = opt.GetValueType(memberType, protoTree)
-				if err != nil {
+				member := MemberType{Name: trimNSPrefix(memberType)}
+				if member.Type, err = opt.GetValueType(memberType, protoTree); err != nil {
 					return
 				}
+				opt.SimpleType.Peek().(*SimpleType).MemberTypes = append(opt.SimpleType.Peek().(*SimpleType).MemberTypes, member)
 			}
 			continue
 		}